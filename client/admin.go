@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ProjectStats is one project's row in the admin overview. ActiveAgents
+// have heartbeated recently; InProgressTasks are the running share of
+// OpenTasks.
+type ProjectStats struct {
+	Project            string     `json:"project"`
+	Agents             int        `json:"agents"`
+	ActiveAgents       int        `json:"active_agents"`
+	ActiveSessions     int        `json:"active_sessions"`
+	OpenEpics          int        `json:"open_epics"`
+	OpenTasks          int        `json:"open_tasks"`
+	InProgressTasks    int        `json:"in_progress_tasks"`
+	ActiveReservations int        `json:"active_reservations"`
+	Messages           int        `json:"messages"`
+	LastActivity       *time.Time `json:"last_activity,omitempty"`
+	Archived           bool       `json:"archived,omitempty"`
+}
+
+// Overview aggregates activity across every project on a server.
+type Overview struct {
+	Projects    []ProjectStats `json:"projects"`
+	DBSizeBytes int64          `json:"db_size_bytes"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// Overview returns per-project counts for every project on the server,
+// archived ones only if includeArchived. It is an admin call: clients
+// using a project API key get a 403 error.
+func (c *Client) Overview(ctx context.Context, includeArchived bool) (Overview, error) {
+	endpoint := "/api/admin/overview"
+	if includeArchived {
+		endpoint += "?include_archived=true"
+	}
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return Overview{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Overview{}, apiError(resp, "admin overview")
+	}
+	var out Overview
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Overview{}, err
+	}
+	return out, nil
+}
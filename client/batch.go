@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// BatchResult partitions a batch lookup: Found holds the entities in request
+// order, Missing the IDs that don't exist in the project.
+type BatchResult[T any] struct {
+	Found   []T      `json:"found"`
+	Missing []string `json:"missing"`
+}
+
+// batchGet fetches many entities of one kind in a single round trip via
+// POST /api/{entities}/batch-get.
+func batchGet[T any](ctx context.Context, c *Client, entities string, ids []string) (BatchResult[T], error) {
+	resp, err := c.postJSON(ctx, "/api/"+entities+"/batch-get", map[string]any{
+		"project": c.Project,
+		"ids":     ids,
+	})
+	if err != nil {
+		return BatchResult[T]{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return BatchResult[T]{}, apiError(resp, "batch get "+entities)
+	}
+	var out BatchResult[T]
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return BatchResult[T]{}, err
+	}
+	return out, nil
+}
+
+// BatchGetSpecs retrieves many specs by ID in one request
+func (c *Client) BatchGetSpecs(ctx context.Context, ids []string) (BatchResult[Spec], error) {
+	return batchGet[Spec](ctx, c, "specs", ids)
+}
+
+// BatchGetEpics retrieves many epics by ID in one request
+func (c *Client) BatchGetEpics(ctx context.Context, ids []string) (BatchResult[Epic], error) {
+	return batchGet[Epic](ctx, c, "epics", ids)
+}
+
+// BatchGetStories retrieves many stories by ID in one request
+func (c *Client) BatchGetStories(ctx context.Context, ids []string) (BatchResult[Story], error) {
+	return batchGet[Story](ctx, c, "stories", ids)
+}
+
+// BatchGetTasks retrieves many tasks by ID in one request
+func (c *Client) BatchGetTasks(ctx context.Context, ids []string) (BatchResult[Task], error) {
+	return batchGet[Task](ctx, c, "tasks", ids)
+}
+
+// BatchGetInsights retrieves many insights by ID in one request
+func (c *Client) BatchGetInsights(ctx context.Context, ids []string) (BatchResult[Insight], error) {
+	return batchGet[Insight](ctx, c, "insights", ids)
+}
+
+// BatchGetSessions retrieves many sessions by ID in one request
+func (c *Client) BatchGetSessions(ctx context.Context, ids []string) (BatchResult[Session], error) {
+	return batchGet[Session](ctx, c, "sessions", ids)
+}
+
+// BatchGetCUJs retrieves many critical user journeys by ID in one request
+func (c *Client) BatchGetCUJs(ctx context.Context, ids []string) (BatchResult[CriticalUserJourney], error) {
+	return batchGet[CriticalUserJourney](ctx, c, "cujs", ids)
+}
+
+// Batch queues create, update and delete calls to send in one request to
+// POST /api/batch. The server runs them in order. By default the batch is
+// atomic: the operations share one transaction and the first failure
+// rolls them all back. BestEffort runs each on its own instead.
+//
+// Entity kinds are the path segments: "specs", "epics", "stories",
+// "tasks", "insights", "sessions", "cujs" and "goals". A payload without a
+// project gets the client's.
+type Batch struct {
+	c    *Client
+	mode string
+	ops  []batchOp
+	err  error
+}
+
+type batchOp struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Batch states a BatchOpResult can report.
+const (
+	BatchOpOK         = "ok"
+	BatchOpFailed     = "failed"
+	BatchOpRolledBack = "rolled_back"
+	BatchOpSkipped    = "skipped"
+)
+
+// BatchOpResult is one operation's outcome, in queue order. Status and
+// Body are the response it got; a rolled-back operation keeps them even
+// though its change was undone, and a skipped one never ran.
+type BatchOpResult struct {
+	Index  int             `json:"index"`
+	ID     string          `json:"id,omitempty"` // set by bulk status updates
+	State  string          `json:"state"`
+	Status int             `json:"status,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Decode unmarshals the operation's response body into v.
+func (r BatchOpResult) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Err returns the operation's failure as an *APIError, or nil if it
+// didn't fail.
+func (r BatchOpResult) Err() error {
+	if r.State != BatchOpFailed {
+		return nil
+	}
+	return decodeAPIError(fmt.Sprintf("batch op %d", r.Index), r.Status, r.Body)
+}
+
+// BatchResponse reports a submitted batch. Committed is true when an atomic
+// batch's changes were kept, or when any operation of a best-effort batch
+// succeeded.
+type BatchResponse struct {
+	Mode      string          `json:"mode"`
+	Committed bool            `json:"committed"`
+	Failed    int             `json:"failed"`
+	Results   []BatchOpResult `json:"results"`
+}
+
+// Batch starts an empty atomic batch.
+func (c *Client) Batch() *Batch {
+	return &Batch{c: c, mode: "atomic"}
+}
+
+// BestEffort makes the batch run each operation on its own, so a failure
+// doesn't undo or stop the others.
+func (b *Batch) BestEffort() *Batch {
+	b.mode = "best_effort"
+	return b
+}
+
+// Len is the number of queued operations.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Create queues a POST of v to /api/{kind}.
+func (b *Batch) Create(kind string, v any) *Batch {
+	return b.add(http.MethodPost, "/api/"+kind, v)
+}
+
+// Update queues a PUT of v to /api/{kind}/{id}.
+func (b *Batch) Update(kind, id string, v any) *Batch {
+	return b.add(http.MethodPut, "/api/"+kind+"/"+url.PathEscape(id), v)
+}
+
+// Delete queues a DELETE of /api/{kind}/{id}.
+func (b *Batch) Delete(kind, id string) *Batch {
+	path := "/api/" + kind + "/" + url.PathEscape(id)
+	if b.c.Project != "" {
+		path += "?project=" + url.QueryEscape(b.c.Project)
+	}
+	return b.add(http.MethodDelete, path, nil)
+}
+
+func (b *Batch) add(method, path string, v any) *Batch {
+	op := batchOp{Method: method, Path: path}
+	if v != nil && b.err == nil {
+		op.Body, b.err = b.withProject(v)
+	}
+	b.ops = append(b.ops, op)
+	return b
+}
+
+// withProject marshals v, filling in the client's project if v is an
+// object without one.
+func (b *Batch) withProject(v any) (json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil || b.c.Project == "" {
+		return raw, err
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil {
+		return raw, nil
+	}
+	if p, ok := obj["project"]; ok && string(p) != `""` {
+		return raw, nil
+	}
+	obj["project"], _ = json.Marshal(b.c.Project)
+	return json.Marshal(obj)
+}
+
+// Submit sends the queued operations. An error means the batch as a whole
+// was rejected or couldn't be sent; per-operation failures are reported
+// in the response.
+func (b *Batch) Submit(ctx context.Context) (BatchResponse, error) {
+	if b.err != nil {
+		return BatchResponse{}, b.err
+	}
+	resp, err := b.c.postJSON(ctx, "/api/batch", map[string]any{
+		"mode": b.mode,
+		"ops":  b.ops,
+	})
+	if err != nil {
+		return BatchResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return BatchResponse{}, apiError(resp, "batch")
+	}
+	var out BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return BatchResponse{}, err
+	}
+	return out, nil
+}
+
+// StatusUpdate moves one entity to Status. Version is the entity's current
+// version, as for an update; zero applies the change at whatever version
+// is stored.
+type StatusUpdate struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+	Status  string `json:"status"`
+}
+
+// BulkUpdateStoryStatus applies many story status changes in one
+// transaction: if any fails (a stale version, a disallowed transition) none
+// are kept. The response reports each update in order, like a batch.
+func (c *Client) BulkUpdateStoryStatus(ctx context.Context, updates []StatusUpdate) (BatchResponse, error) {
+	return c.bulkStatus(ctx, "stories", "atomic", updates)
+}
+
+// BulkUpdateTaskStatus is BulkUpdateStoryStatus for tasks.
+func (c *Client) BulkUpdateTaskStatus(ctx context.Context, updates []StatusUpdate) (BatchResponse, error) {
+	return c.bulkStatus(ctx, "tasks", "atomic", updates)
+}
+
+// DryRunStoryStatus evaluates story status changes without keeping any:
+// every update is tried in order, and each result says whether it would
+// apply (or why not) after the ones before it.
+func (c *Client) DryRunStoryStatus(ctx context.Context, updates []StatusUpdate) (BatchResponse, error) {
+	return c.bulkStatus(ctx, "stories", "dry_run", updates)
+}
+
+// DryRunTaskStatus is DryRunStoryStatus for tasks.
+func (c *Client) DryRunTaskStatus(ctx context.Context, updates []StatusUpdate) (BatchResponse, error) {
+	return c.bulkStatus(ctx, "tasks", "dry_run", updates)
+}
+
+func (c *Client) bulkStatus(ctx context.Context, entities, mode string, updates []StatusUpdate) (BatchResponse, error) {
+	resp, err := c.postJSON(ctx, "/api/"+entities+"/bulk-status", map[string]any{
+		"project": c.Project,
+		"mode":    mode,
+		"items":   updates,
+	})
+	if err != nil {
+		return BatchResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return BatchResponse{}, apiError(resp, "bulk status "+entities)
+	}
+	var out BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return BatchResponse{}, err
+	}
+	return out, nil
+}
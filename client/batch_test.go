@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientBatchGetTasks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tasks/batch-get" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Project string   `json:"project"`
+			IDs     []string `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Project != "proj-a" || len(req.IDs) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"found":   []Task{{ID: req.IDs[0], Title: "found"}},
+			"missing": []string{req.IDs[1]},
+		})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := c.BatchGetTasks(ctx, []string{"task-1", "task-2"})
+	if err != nil {
+		t.Fatalf("batch get: %v", err)
+	}
+	if len(res.Found) != 1 || res.Found[0].ID != "task-1" {
+		t.Fatalf("found = %+v, want task-1", res.Found)
+	}
+	if len(res.Missing) != 1 || res.Missing[0] != "task-2" {
+		t.Fatalf("missing = %v, want [task-2]", res.Missing)
+	}
+}
+
+func TestClientBatchSubmit(t *testing.T) {
+	var got struct {
+		Mode string `json:"mode"`
+		Ops  []struct {
+			Method string          `json:"method"`
+			Path   string          `json:"path"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"ops"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/batch" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"mode":"best_effort","committed":true,"failed":1,"results":[
+			{"index":0,"state":"ok","status":201,"body":{"id":"task-1","title":"A"}},
+			{"index":1,"state":"failed","status":404,"body":{"error":"not found","code":"not_found"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := c.Batch().BestEffort().
+		Create("tasks", Task{Title: "A"}).
+		Delete("tasks", "task-9").
+		Submit(ctx)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if got.Mode != "best_effort" || len(got.Ops) != 2 {
+		t.Fatalf("request = %+v", got)
+	}
+	var sent Task
+	if err := json.Unmarshal(got.Ops[0].Body, &sent); err != nil || sent.Project != "proj-a" {
+		t.Fatalf("create body = %s, want project filled in", got.Ops[0].Body)
+	}
+	if got.Ops[1].Method != http.MethodDelete || got.Ops[1].Path != "/api/tasks/task-9?project=proj-a" {
+		t.Fatalf("delete op = %+v", got.Ops[1])
+	}
+
+	var task Task
+	if err := res.Results[0].Decode(&task); err != nil || task.ID != "task-1" {
+		t.Fatalf("decode result 0: %+v, %v", task, err)
+	}
+	if res.Results[0].Err() != nil {
+		t.Fatal("ok result reported an error")
+	}
+	if err := res.Results[1].Err(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("result 1 err = %v, want ErrNotFound", err)
+	}
+}
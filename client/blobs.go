@@ -0,0 +1,92 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Blob is a stored payload too large for a message body.
+type Blob struct {
+	ID          string    `json:"id"`
+	Project     string    `json:"project"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	ContentType string    `json:"content_type,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Attachment refers a message to a blob. Only BlobID and Name are read on
+// send; the server fills in Size and ContentType.
+type Attachment struct {
+	BlobID      string `json:"blob_id"`
+	Name        string `json:"name,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// UploadBlob stores data in the client project. Uploading the same bytes
+// again returns the existing blob.
+func (c *Client) UploadBlob(ctx context.Context, data []byte, contentType string) (Blob, error) {
+	endpoint := "/api/blobs"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return Blob{}, err
+	}
+	c.applyHeaders(req)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return Blob{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return Blob{}, apiError(resp, "upload blob")
+	}
+	var out Blob
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Blob{}, err
+	}
+	return out, nil
+}
+
+// DownloadBlob returns a blob's bytes and content type.
+func (c *Client) DownloadBlob(ctx context.Context, id string) ([]byte, string, error) {
+	endpoint := "/api/blobs/" + url.PathEscape(id)
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", apiError(resp, "download blob")
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// SendLargeMessage sends msg with payload uploaded as a blob and attached
+// under name, for payloads over the server's message body limit.
+func (c *Client) SendLargeMessage(ctx context.Context, msg Message, name string, payload []byte, contentType string) (SendResponse, error) {
+	blob, err := c.UploadBlob(ctx, payload, contentType)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	msg.Attachments = append(msg.Attachments, Attachment{BlobID: blob.ID, Name: name})
+	return c.SendMessage(ctx, msg)
+}
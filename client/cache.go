@@ -0,0 +1,151 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CachedResponse is a GET response body stored alongside its ETag.
+type CachedResponse struct {
+	ETag        string `json:"etag"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// Cache stores GET responses for ETag revalidation. Keys combine the request
+// URL and the client's project so clients sharing a cache stay isolated.
+type Cache interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, resp CachedResponse)
+}
+
+// WithCache enables conditional GETs: cached ETags are sent as If-None-Match
+// and the cached body is served when the server answers 304 Not Modified.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.Cache = cache
+	}
+}
+
+// MemoryCache is an in-process Cache safe for concurrent use.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]CachedResponse
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]CachedResponse)}
+}
+
+func (m *MemoryCache) Get(key string) (CachedResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resp, ok := m.entries[key]
+	return resp, ok
+}
+
+func (m *MemoryCache) Set(key string, resp CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = resp
+}
+
+// DiskCache persists entries as one JSON file per key under Dir, so cached
+// bodies survive agent restarts. Write failures are ignored; a missing entry
+// only costs a full response.
+type DiskCache struct {
+	Dir string
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{Dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *DiskCache) Get(key string) (CachedResponse, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return CachedResponse{}, false
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return CachedResponse{}, false
+	}
+	return resp, true
+}
+
+func (d *DiskCache) Set(key string, resp CachedResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(d.Dir, "entry-*")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (c *Client) cacheKey(req *http.Request) string {
+	return req.URL.String() + "|" + c.Project
+}
+
+// doCached performs a GET through the response cache. A 304 is rewritten to
+// a 200 carrying the cached body, so callers never see revalidation.
+func (c *Client) doCached(req *http.Request) (*http.Response, error) {
+	key := c.cacheKey(req)
+	cached, hit := c.Cache.Get(key)
+	if hit && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hit:
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		if cached.ContentType != "" {
+			resp.Header.Set("Content-Type", cached.ContentType)
+		}
+		resp.ContentLength = int64(len(cached.Body))
+		resp.Body = io.NopCloser(bytes.NewReader(cached.Body))
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.Cache.Set(key, CachedResponse{
+			ETag:        resp.Header.Get("ETag"),
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        body,
+		})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
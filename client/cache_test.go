@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func etagServer(t *testing.T, notModified *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"agents": []map[string]any{{"agent_id": "a1", "project": "proj-a"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientCacheServesBodyOn304(t *testing.T) {
+	var notModified atomic.Int32
+	srv := etagServer(t, &notModified)
+
+	c := New(srv.URL, WithProject("proj-a"), WithCache(NewMemoryCache()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		agents, err := c.ListAgents(ctx, "")
+		if err != nil {
+			t.Fatalf("list %d: %v", i, err)
+		}
+		if len(agents) != 1 || agents[0].ID != "a1" {
+			t.Fatalf("list %d: unexpected agents %+v", i, agents)
+		}
+	}
+	if got := notModified.Load(); got != 2 {
+		t.Fatalf("expected 2 revalidated responses, got %d", got)
+	}
+}
+
+func TestDiskCacheRoundTrip(t *testing.T) {
+	var notModified atomic.Int32
+	srv := etagServer(t, &notModified)
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cache, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("disk cache: %v", err)
+	}
+	if _, err := New(srv.URL, WithProject("proj-a"), WithCache(cache)).ListAgents(ctx, ""); err != nil {
+		t.Fatalf("first list: %v", err)
+	}
+
+	// A fresh client over the same directory revalidates from disk.
+	cache2, _ := NewDiskCache(dir)
+	agents, err := New(srv.URL, WithProject("proj-a"), WithCache(cache2)).ListAgents(ctx, "")
+	if err != nil {
+		t.Fatalf("second list: %v", err)
+	}
+	if len(agents) != 1 || notModified.Load() != 1 {
+		t.Fatalf("expected cached body via 304, got %d agents, %d 304s", len(agents), notModified.Load())
+	}
+}
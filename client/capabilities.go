@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Capabilities describes the features and limits of a server deployment.
+type Capabilities struct {
+	Version     string             `json:"version"`
+	Features    map[string]bool    `json:"features"`
+	Flags       map[string]bool    `json:"flags"`
+	Limits      CapabilityLimits   `json:"limits"`
+	EventSchema EventSchemaSupport `json:"event_schema"`
+}
+
+// EventSchemaSupport is the event payload schema version a server renders
+// events at, and the versions it down-converts to for pinned subscribers.
+// Both are zero on servers before versioning, which send version 1.
+type EventSchemaSupport struct {
+	Current   int   `json:"current"`
+	Supported []int `json:"supported"`
+}
+
+// CapabilityLimits are the server-enforced request limits.
+type CapabilityLimits struct {
+	MaxBodyBytes           int `json:"max_body_bytes"`
+	MaxInboxWaitSeconds    int `json:"max_inbox_wait_seconds"`
+	BroadcastPerMinute     int `json:"broadcast_per_minute"`
+	LiveDeliveryPerMinute  int `json:"live_delivery_per_minute"`
+	RequestsPerMinute      int `json:"requests_per_minute"` // per API key or local IP; 0 means unlimited
+	SessionContextThreads  int `json:"session_context_max_threads"`
+	SessionContextMessages int `json:"session_context_max_messages"`
+}
+
+// Has reports whether the server advertises feature as enabled. Unknown
+// features are treated as disabled.
+func (c Capabilities) Has(feature string) bool {
+	return c.Features[feature]
+}
+
+// FlagOn reports whether the per-project feature flag is enabled for the
+// client's project. Unknown flags are treated as off.
+func (c Capabilities) FlagOn(flag string) bool {
+	return c.Flags[flag]
+}
+
+// Capabilities fetches the server's feature set, the client project's
+// feature flags, and limits. Servers that
+// predate the endpoint answer 404, which is returned as an error.
+func (c *Client) Capabilities(ctx context.Context) (Capabilities, error) {
+	endpoint := "/api/capabilities"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Capabilities{}, apiError(resp, "capabilities")
+	}
+	var out Capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Capabilities{}, err
+	}
+	return out, nil
+}
+
+// EntitySchema fetches the JSON Schema describing entity ("spec", "task",
+// "goal", ...), for validating payloads or building forms.
+func (c *Client) EntitySchema(ctx context.Context, entity string) (json.RawMessage, error) {
+	resp, err := c.get(ctx, "/api/schemas/"+url.PathEscape(entity))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "entity schema")
+	}
+	var out json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -16,6 +16,12 @@ type Client struct {
 	HTTP    *http.Client
 	APIKey  string
 	Project string
+	Cache   Cache
+	// Events, when set, lets WatchEntity and WaitForTask react to pushed
+	// domain events instead of only polling.
+	Events *WSClient
+	// Retry, when MaxAttempts > 1, retries requests that fail transiently.
+	Retry RetryPolicy
 }
 
 type Option func(*Client)
@@ -32,6 +38,14 @@ func WithProject(project string) Option {
 	}
 }
 
+// WithEventStream has watchers use ws's domain events to notice changes
+// promptly. ws should already be connected.
+func WithEventStream(ws *WSClient) Option {
+	return func(c *Client) {
+		c.Events = ws
+	}
+}
+
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(c *Client) {
 		if httpClient != nil {
@@ -57,24 +71,52 @@ type ListAgentsResponse struct {
 }
 
 type Message struct {
-	ID          string   `json:"id,omitempty"`
-	ThreadID    string   `json:"thread_id,omitempty"`
-	Project     string   `json:"project,omitempty"`
-	From        string   `json:"from"`
-	To          []string `json:"to"`
-	CC          []string `json:"cc,omitempty"`
-	BCC         []string `json:"bcc,omitempty"`
-	Subject     string   `json:"subject,omitempty"`
-	Body        string   `json:"body"`
-	Importance  string   `json:"importance,omitempty"`
-	AckRequired bool     `json:"ack_required,omitempty"`
-	CreatedAt   string   `json:"created_at,omitempty"`
-	Cursor      uint64   `json:"cursor,omitempty"`
+	ID          string              `json:"id,omitempty"`
+	ThreadID    string              `json:"thread_id,omitempty"`
+	EntityType  string              `json:"entity_type,omitempty"` // with EntityID, send into the entity's thread
+	EntityID    string              `json:"entity_id,omitempty"`
+	Project     string              `json:"project,omitempty"`
+	From        string              `json:"from"`
+	To          []string            `json:"to"`
+	CC          []string            `json:"cc,omitempty"`
+	BCC         []string            `json:"bcc,omitempty"`
+	Subject     string              `json:"subject,omitempty"`
+	InReplyTo   string              `json:"in_reply_to,omitempty"`
+	Groups      map[string][]string `json:"groups,omitempty"`
+	Route       *Route              `json:"route,omitempty"` // send to agents by capability instead of naming them
+	Body        string              `json:"body"`
+	Attachments []Attachment        `json:"attachments,omitempty"`
+	Importance  string              `json:"importance,omitempty"`
+	AckRequired bool                `json:"ack_required,omitempty"`
+	AckPolicy   string              `json:"ack_policy,omitempty"` // "all", "any" or a count: acks needed for message.quorum_reached
+	CreatedAt   string              `json:"created_at,omitempty"`
+	Cursor      uint64              `json:"cursor,omitempty"`
+}
+
+// ReplyOptions controls a reply built by Reply.
+type ReplyOptions struct {
+	ReplyAll    bool   `json:"reply_all,omitempty"`
+	Quote       bool   `json:"quote,omitempty"`
+	Importance  string `json:"importance,omitempty"`
+	AckRequired bool   `json:"ack_required,omitempty"`
+}
+
+// Route addresses a message by capability. On a send set Capability, an
+// expression like "go & review | rust & review", and FanOut to reach every
+// eligible agent instead of the least loaded one; the server fills in
+// Candidates and Selected.
+type Route struct {
+	Capability string   `json:"capability"`
+	FanOut     bool     `json:"fan_out,omitempty"`
+	Candidates []string `json:"candidates,omitempty"`
+	Selected   []string `json:"selected,omitempty"`
 }
 
 type SendResponse struct {
 	MessageID string `json:"message_id"`
+	ThreadID  string `json:"thread_id,omitempty"`
 	Cursor    uint64 `json:"cursor"`
+	Route     *Route `json:"route,omitempty"`
 }
 
 type InboxResponse struct {
@@ -89,6 +131,7 @@ type ThreadSummary struct {
 	LastFrom     string `json:"last_from"`
 	LastBody     string `json:"last_body"`
 	LastAt       string `json:"last_at"`
+	Unread       int    `json:"unread"`
 }
 
 type ListThreadsResponse struct {
@@ -151,10 +194,27 @@ type ReservationsResponse struct {
 	Reservations []Reservation `json:"reservations"`
 }
 
+// ReservationTakeover is a request to take over a reservation whose holder
+// appears stuck. Status is pending, declined, transferred or lapsed; once
+// transferred, NewReservationID is the requester's reservation.
+type ReservationTakeover struct {
+	ID               string     `json:"id"`
+	Project          string     `json:"project"`
+	ReservationID    string     `json:"reservation_id"`
+	Holder           string     `json:"holder"`
+	Requester        string     `json:"requester"`
+	Reason           string     `json:"reason,omitempty"`
+	Status           string     `json:"status"`
+	RequestedAt      time.Time  `json:"requested_at"`
+	Deadline         time.Time  `json:"deadline"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+	NewReservationID string     `json:"new_reservation_id,omitempty"`
+}
+
 func New(baseURL string, opts ...Option) *Client {
 	c := &Client{
 		BaseURL: strings.TrimRight(baseURL, "/"),
-		HTTP:    &http.Client{Timeout: 10 * time.Second},
+		HTTP:    &http.Client{Timeout: 10 * time.Second, Transport: NewCompressionTransport(nil)},
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -172,7 +232,7 @@ func (c *Client) RegisterAgent(ctx context.Context, agent Agent) (Agent, error)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return Agent{}, fmt.Errorf("register failed: %d", resp.StatusCode)
+		return Agent{}, apiError(resp, "register")
 	}
 	var out Agent
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -188,7 +248,7 @@ func (c *Client) Heartbeat(ctx context.Context, agentID string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("heartbeat failed: %d", resp.StatusCode)
+		return apiError(resp, "heartbeat")
 	}
 	return nil
 }
@@ -210,7 +270,7 @@ func (c *Client) ListAgents(ctx context.Context, project string) ([]Agent, error
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list agents failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list agents")
 	}
 	var out ListAgentsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -239,7 +299,7 @@ func (c *Client) DiscoverAgents(ctx context.Context, capabilities []string) ([]A
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("discover agents failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "discover agents")
 	}
 	var out ListAgentsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -258,7 +318,7 @@ func (c *Client) SendMessage(ctx context.Context, msg Message) (SendResponse, er
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return SendResponse{}, fmt.Errorf("send failed: %d", resp.StatusCode)
+		return SendResponse{}, apiError(resp, "send")
 	}
 	var out SendResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -280,7 +340,46 @@ func (c *Client) InboxSince(ctx context.Context, agent string, cursor uint64) (I
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return InboxResponse{}, fmt.Errorf("inbox failed: %d", resp.StatusCode)
+		return InboxResponse{}, apiError(resp, "inbox")
+	}
+	var out InboxResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return InboxResponse{}, err
+	}
+	return out, nil
+}
+
+// WaitForMessages long-polls the inbox: it blocks until messages past cursor
+// arrive or wait elapses (server caps it at 60s), returning an empty response
+// on timeout. It is the fallback for environments where WebSockets are
+// blocked; loop on it, passing back the returned cursor.
+func (c *Client) WaitForMessages(ctx context.Context, agent string, cursor uint64, wait time.Duration) (InboxResponse, error) {
+	values := url.Values{}
+	values.Set("since_cursor", fmt.Sprintf("%d", cursor))
+	values.Set("wait", wait.String())
+	if c.Project != "" {
+		values.Set("project", c.Project)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.BaseURL+fmt.Sprintf("/api/inbox/%s?%s", url.PathEscape(agent), values.Encode()), nil)
+	if err != nil {
+		return InboxResponse{}, err
+	}
+	c.applyHeaders(req)
+
+	// The default client timeout is shorter than a typical wait; stretch it
+	// so the request outlives the server-side poll.
+	httpClient := *c.HTTP
+	if httpClient.Timeout != 0 && httpClient.Timeout < wait+5*time.Second {
+		httpClient.Timeout = wait + 5*time.Second
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return InboxResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return InboxResponse{}, apiError(resp, "wait for messages")
 	}
 	var out InboxResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -308,11 +407,72 @@ func (c *Client) messageAction(ctx context.Context, messageID, action string) er
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%s failed: %d", action, resp.StatusCode)
+		return apiError(resp, action)
+	}
+	return nil
+}
+
+// Reply sends body from agent in reply to messageID. The server addresses
+// it, threads it, prefixes the subject with "Re:" and sets in_reply_to.
+func (c *Client) Reply(ctx context.Context, messageID, from, body string, opts ReplyOptions) (SendResponse, error) {
+	payload := struct {
+		ReplyOptions
+		Project string `json:"project,omitempty"`
+		From    string `json:"from"`
+		Body    string `json:"body"`
+	}{opts, c.Project, from, body}
+	return c.composeAction(ctx, messageID, "reply", payload)
+}
+
+// Forward sends a copy of messageID from agent to the given recipients,
+// with an optional note above the forwarded content.
+func (c *Client) Forward(ctx context.Context, messageID, from string, to []string, note string) (SendResponse, error) {
+	payload := map[string]any{
+		"project": c.Project,
+		"from":    from,
+		"to":      to,
+		"body":    note,
+	}
+	return c.composeAction(ctx, messageID, "forward", payload)
+}
+
+// Snooze hides messageID from agent's inbox until the given time, when it
+// reappears as unread.
+func (c *Client) Snooze(ctx context.Context, messageID, agent string, until time.Time) error {
+	endpoint := "/api/messages/" + url.PathEscape(messageID) + "/snooze"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.postJSON(ctx, endpoint, map[string]string{
+		"agent": agent,
+		"until": until.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp, "snooze")
 	}
 	return nil
 }
 
+func (c *Client) composeAction(ctx context.Context, messageID, action string, payload any) (SendResponse, error) {
+	resp, err := c.postJSON(ctx, "/api/messages/"+url.PathEscape(messageID)+"/"+action, payload)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SendResponse{}, apiError(resp, action)
+	}
+	var out SendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return SendResponse{}, err
+	}
+	return out, nil
+}
+
 func (c *Client) ListThreads(ctx context.Context, agent string, cursor uint64) (ListThreadsResponse, error) {
 	values := url.Values{}
 	values.Set("agent", agent)
@@ -327,7 +487,7 @@ func (c *Client) ListThreads(ctx context.Context, agent string, cursor uint64) (
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return ListThreadsResponse{}, fmt.Errorf("list threads failed: %d", resp.StatusCode)
+		return ListThreadsResponse{}, apiError(resp, "list threads")
 	}
 	var out ListThreadsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -349,7 +509,7 @@ func (c *Client) ThreadMessages(ctx context.Context, threadID string, cursor uin
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return ThreadMessagesResponse{}, fmt.Errorf("thread messages failed: %d", resp.StatusCode)
+		return ThreadMessagesResponse{}, apiError(resp, "thread messages")
 	}
 	var out ThreadMessagesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -359,6 +519,30 @@ func (c *Client) ThreadMessages(ctx context.Context, threadID string, cursor uin
 }
 
 // InboxCounts returns the total and unread message counts for an agent
+// MarkThreadRead marks every message in threadID that agent received as
+// read, returning how many were newly read.
+func (c *Client) MarkThreadRead(ctx context.Context, threadID, agent string) (int, error) {
+	endpoint := "/api/threads/" + url.PathEscape(threadID) + "/read"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.postJSON(ctx, endpoint, map[string]string{"agent": agent})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, apiError(resp, "mark thread read")
+	}
+	var out struct {
+		Read int `json:"read"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, err
+	}
+	return out.Read, nil
+}
+
 func (c *Client) InboxCounts(ctx context.Context, agent string) (InboxCounts, error) {
 	values := url.Values{}
 	if c.Project != "" {
@@ -374,7 +558,7 @@ func (c *Client) InboxCounts(ctx context.Context, agent string) (InboxCounts, er
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return InboxCounts{}, fmt.Errorf("inbox counts failed: %d", resp.StatusCode)
+		return InboxCounts{}, apiError(resp, "inbox counts")
 	}
 	var out InboxCounts
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -402,7 +586,7 @@ func (c *Client) StaleAcks(ctx context.Context, agent string, ttlSeconds, limit
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return StaleAcksResponse{}, fmt.Errorf("stale acks failed: %d", resp.StatusCode)
+		return StaleAcksResponse{}, apiError(resp, "stale acks")
 	}
 	var out StaleAcksResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -411,7 +595,8 @@ func (c *Client) StaleAcks(ctx context.Context, agent string, ttlSeconds, limit
 	return out, nil
 }
 
-// Reserve creates a new file reservation
+// Reserve creates a new file reservation. If active reservations overlap
+// it, the error is a *ReservationConflictError listing them.
 func (c *Client) Reserve(ctx context.Context, r Reservation) (Reservation, error) {
 	if r.Project == "" {
 		r.Project = c.Project
@@ -421,8 +606,8 @@ func (c *Client) Reserve(ctx context.Context, r Reservation) (Reservation, error
 		return Reservation{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return Reservation{}, fmt.Errorf("reserve failed: %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return Reservation{}, reservationError(resp)
 	}
 	var out Reservation
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -439,17 +624,67 @@ func (c *Client) ReleaseReservation(ctx context.Context, id string) error {
 		return err
 	}
 	c.applyHeaders(req)
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("release failed: %d", resp.StatusCode)
+		return apiError(resp, "release")
 	}
 	return nil
 }
 
+// RequestTakeover asks the holder of reservation id to hand it to agent.
+// The holder has timeout (server default 2m, max 1h) to answer; after that
+// the reservation is transferred.
+func (c *Client) RequestTakeover(ctx context.Context, id, agent, reason string, timeout time.Duration) (ReservationTakeover, error) {
+	resp, err := c.postJSON(ctx, "/api/reservations/"+url.PathEscape(id)+"/request-takeover", map[string]any{
+		"agent_id":        agent,
+		"reason":          reason,
+		"timeout_seconds": int(timeout / time.Second),
+	})
+	if err != nil {
+		return ReservationTakeover{}, err
+	}
+	return decodeTakeover(resp, http.StatusCreated, "request takeover")
+}
+
+// RespondTakeover answers, as agent, the pending takeover of a reservation
+// agent holds: accept hands it over now, decline keeps it. An empty agent
+// means the API key's.
+func (c *Client) RespondTakeover(ctx context.Context, id, agent string, accept bool) (ReservationTakeover, error) {
+	resp, err := c.postJSON(ctx, "/api/reservations/"+url.PathEscape(id)+"/takeover", map[string]any{
+		"agent_id": agent,
+		"accept":   accept,
+	})
+	if err != nil {
+		return ReservationTakeover{}, err
+	}
+	return decodeTakeover(resp, http.StatusOK, "respond takeover")
+}
+
+// Takeover returns the latest takeover request for a reservation.
+func (c *Client) Takeover(ctx context.Context, id string) (ReservationTakeover, error) {
+	resp, err := c.get(ctx, "/api/reservations/"+url.PathEscape(id)+"/takeover")
+	if err != nil {
+		return ReservationTakeover{}, err
+	}
+	return decodeTakeover(resp, http.StatusOK, "get takeover")
+}
+
+func decodeTakeover(resp *http.Response, want int, op string) (ReservationTakeover, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		return ReservationTakeover{}, apiError(resp, op)
+	}
+	var out ReservationTakeover
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return ReservationTakeover{}, err
+	}
+	return out, nil
+}
+
 // ActiveReservations returns all active reservations for a project
 func (c *Client) ActiveReservations(ctx context.Context, project string) ([]Reservation, error) {
 	if project == "" {
@@ -462,7 +697,7 @@ func (c *Client) ActiveReservations(ctx context.Context, project string) ([]Rese
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list reservations failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list reservations")
 	}
 	var out ReservationsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -480,7 +715,7 @@ func (c *Client) AgentReservations(ctx context.Context, agentID string) ([]Reser
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("agent reservations failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "agent reservations")
 	}
 	var out ReservationsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -500,7 +735,7 @@ func (c *Client) postJSON(ctx context.Context, path string, payload any) (*http.
 	}
 	c.applyHeaders(req)
 	req.Header.Set("Content-Type", "application/json")
-	return c.HTTP.Do(req)
+	return c.do(req)
 }
 
 func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
@@ -509,11 +744,41 @@ func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
 		return nil, err
 	}
 	c.applyHeaders(req)
-	return c.HTTP.Do(req)
+	if c.Cache != nil {
+		return c.doCached(req)
+	}
+	return c.do(req)
 }
 
 func (c *Client) applyHeaders(req *http.Request) {
 	if c.APIKey != "" {
 		req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	}
+	if t, ok := req.Context().Value(traceKey{}).(trace); ok {
+		if t.correlationID != "" {
+			req.Header.Set("X-Correlation-ID", t.correlationID)
+		}
+		if t.causationID != "" {
+			req.Header.Set("X-Causation-ID", t.causationID)
+		}
+	}
+	if key, ok := req.Context().Value(idempotencyKey{}).(string); ok && key != "" &&
+		(req.Method == http.MethodPost || req.Method == http.MethodPatch) {
+		req.Header.Set("Idempotency-Key", key)
+	}
+}
+
+type traceKey struct{}
+
+type trace struct {
+	correlationID string
+	causationID   string
+}
+
+// WithTrace tags requests made with the returned context: correlationID
+// groups them into one logical operation and causationID names the event
+// they react to (either may be empty). The server stamps both, with its
+// own request ID, on the events those requests cause.
+func WithTrace(ctx context.Context, correlationID, causationID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace{correlationID: correlationID, causationID: causationID})
 }
@@ -61,6 +61,28 @@ func TestClientAppliesBearerAndProject(t *testing.T) {
 	}
 }
 
+func TestClientSendsTraceHeaders(t *testing.T) {
+	got := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"message_id": "m1", "cursor": 1})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx = WithTrace(ctx, "op-7", "evt-3")
+	if _, err := c.SendMessage(ctx, Message{From: "a", To: []string{"b"}, Body: "hi"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	h := <-got
+	if h.Get("X-Correlation-ID") != "op-7" || h.Get("X-Causation-ID") != "evt-3" {
+		t.Fatalf("expected trace headers, got %v", h)
+	}
+}
+
 func TestClientListAgents(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/agents" {
@@ -207,3 +229,84 @@ func TestClientThreadMessages(t *testing.T) {
 		t.Fatalf("expected 2 messages, got %d", len(resp.Messages))
 	}
 }
+
+func TestClientWaitForMessages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/inbox/agent-b" || r.URL.Query().Get("wait") != "20s" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("since_cursor") != "4" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"messages": []map[string]any{{"id": "m5", "from": "a", "to": []string{"agent-b"}, "body": "hi", "cursor": 5}},
+			"cursor":   5,
+		})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	out, err := c.WaitForMessages(context.Background(), "agent-b", 4, 20*time.Second)
+	if err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	if out.Cursor != 5 || len(out.Messages) != 1 {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+}
+
+func TestClientCapabilities(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/capabilities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"version":  "1.2.3",
+			"features": map[string]bool{"long_poll": true, "fts": false},
+			"limits":   map[string]int{"max_body_bytes": 1024},
+		})
+	}))
+	defer srv.Close()
+
+	caps, err := New(srv.URL).Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("capabilities failed: %v", err)
+	}
+	if caps.Version != "1.2.3" || !caps.Has("long_poll") || caps.Has("fts") || caps.Has("grpc") {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+	if caps.Limits.MaxBodyBytes != 1024 {
+		t.Fatalf("expected max_body_bytes 1024, got %d", caps.Limits.MaxBodyBytes)
+	}
+}
+
+func TestClientReply(t *testing.T) {
+	var payload map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/messages/m1/reply" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"message_id": "m2", "cursor": 2})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	out, err := c.Reply(context.Background(), "m1", "bob", "yes", ReplyOptions{ReplyAll: true})
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if out.MessageID != "m2" {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+	if payload["from"] != "bob" || payload["project"] != "proj-a" || payload["reply_all"] != true {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionTransport asks for zstd or gzip responses and decodes them,
+// so callers always read plain bodies. The standard transport's own gzip
+// handling steps aside once Accept-Encoding is set explicitly.
+type compressionTransport struct {
+	base http.RoundTripper
+}
+
+// NewCompressionTransport wraps base (http.DefaultTransport if nil) to
+// negotiate compressed responses. New uses it for the default HTTP client;
+// custom clients passed to WithHTTPClient can opt in with it too.
+func NewCompressionTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &compressionTransport{base: base}
+}
+
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "zstd, gzip")
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	var body io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		body = &gzipBody{src: resp.Body}
+	case "zstd":
+		dec, err := zstd.NewReader(resp.Body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		body = &zstdBody{dec: dec, src: resp.Body}
+	default:
+		return resp, nil
+	}
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody opens its reader on first Read, so an empty body (a HEAD, or a
+// 304) doesn't fail on the missing gzip header.
+type gzipBody struct {
+	src io.ReadCloser
+	zr  *gzip.Reader
+	err error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.zr == nil && b.err == nil {
+		b.zr, b.err = gzip.NewReader(b.src)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.zr.Read(p)
+}
+
+func (b *gzipBody) Close() error { return b.src.Close() }
+
+type zstdBody struct {
+	dec *zstd.Decoder
+	src io.ReadCloser
+}
+
+func (b *zstdBody) Read(p []byte) (int, error) { return b.dec.Read(p) }
+
+func (b *zstdBody) Close() error {
+	b.dec.Close()
+	return b.src.Close()
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestClientDecodesZstdResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "zstd, gzip" {
+			t.Errorf("Accept-Encoding = %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "zstd")
+		enc, _ := zstd.NewWriter(w)
+		enc.Write([]byte(`{"projects":[{"name":"alpha"}]}`))
+		enc.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	projects, err := New(srv.URL).ListProjects(context.Background(), false)
+	if err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Name != "alpha" {
+		t.Fatalf("projects = %+v", projects)
+	}
+}
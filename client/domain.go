@@ -8,8 +8,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -52,6 +54,36 @@ const (
 	TaskStatusDone    TaskStatus = "done"
 )
 
+// BlockedReason says why a blocked task is blocked.
+type BlockedReason string
+
+const (
+	BlockedReasonDependency  BlockedReason = "dependency"
+	BlockedReasonReservation BlockedReason = "reservation"
+	BlockedReasonExternal    BlockedReason = "external"
+	BlockedReasonNeedsInput  BlockedReason = "needs_input"
+	BlockedReasonOther       BlockedReason = "other"
+)
+
+// UnblockCondition is what a blocked task waits for; set exactly one
+// field. The server unblocks the task and emits task.unblocked once it
+// clears.
+type UnblockCondition struct {
+	TaskID      string `json:"task_id,omitempty"`      // until this task is done
+	PathPattern string `json:"path_pattern,omitempty"` // until no other agent reserves it
+	ExternalRef string `json:"external_ref,omitempty"` // until UnblockExternal reports it
+}
+
+// Priority ranks stories and tasks; tasks inherit their story's priority
+// on creation when none is given.
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
 // SessionStatus represents the status of an agent session
 type SessionStatus string
 
@@ -61,9 +93,20 @@ const (
 	SessionStatusError   SessionStatus = "error"
 )
 
+// InsightStatus tracks an insight through triage
+type InsightStatus string
+
+const (
+	InsightStatusNew       InsightStatus = "new"
+	InsightStatusTriaged   InsightStatus = "triaged"
+	InsightStatusActioned  InsightStatus = "actioned"
+	InsightStatusDismissed InsightStatus = "dismissed"
+)
+
 // Spec represents a product specification (PRD)
 type Spec struct {
 	ID        string     `json:"id"`
+	ShortID   string     `json:"short_id,omitempty"`
 	Project   string     `json:"project"`
 	Title     string     `json:"title"`
 	Vision    string     `json:"vision,omitempty"`
@@ -73,11 +116,17 @@ type Spec struct {
 	Version   int64      `json:"version,omitempty"` // For optimistic locking
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+	// LastReviewedAt is when the spec was last validated or reviewed.
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+	// Stale is set by the server on validated specs that have gone
+	// unreviewed for longer than the project's staleness window.
+	Stale bool `json:"stale,omitempty"`
 }
 
 // Epic represents a large feature or initiative
 type Epic struct {
 	ID          string     `json:"id"`
+	ShortID     string     `json:"short_id,omitempty"`
 	Project     string     `json:"project"`
 	SpecID      string     `json:"spec_id,omitempty"`
 	Title       string     `json:"title"`
@@ -91,11 +140,14 @@ type Epic struct {
 // Story represents a user story within an epic
 type Story struct {
 	ID                 string      `json:"id"`
+	ShortID            string      `json:"short_id,omitempty"`
 	Project            string      `json:"project"`
 	EpicID             string      `json:"epic_id"`
 	Title              string      `json:"title"`
 	AcceptanceCriteria []string    `json:"acceptance_criteria,omitempty"`
 	Status             StoryStatus `json:"status"`
+	Priority           Priority    `json:"priority,omitempty"`
+	Rank               string      `json:"rank,omitempty"` // order within the epic; set with RankStory
 	Version            int64       `json:"version,omitempty"`
 	CreatedAt          time.Time   `json:"created_at"`
 	UpdatedAt          time.Time   `json:"updated_at"`
@@ -103,32 +155,66 @@ type Story struct {
 
 // Task represents an execution unit assigned to an agent
 type Task struct {
-	ID        string     `json:"id"`
-	Project   string     `json:"project"`
-	StoryID   string     `json:"story_id,omitempty"`
-	Title     string     `json:"title"`
-	Agent     string     `json:"agent,omitempty"`
-	SessionID string     `json:"session_id,omitempty"`
-	Status    TaskStatus `json:"status"`
-	Version   int64      `json:"version,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID            string     `json:"id"`
+	ShortID       string     `json:"short_id,omitempty"`
+	Project       string     `json:"project"`
+	StoryID       string     `json:"story_id,omitempty"`
+	Title         string     `json:"title"`
+	Agent         string     `json:"agent,omitempty"`
+	SessionID     string     `json:"session_id,omitempty"`
+	Status        TaskStatus `json:"status"`
+	Priority      Priority   `json:"priority,omitempty"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	Capabilities  []string   `json:"capabilities,omitempty"`
+	ExpectedPaths []string   `json:"expected_paths,omitempty"`
+	// BlockedReason, BlockedDetail and UnblockWhen describe a blocked
+	// task; the server clears them when it leaves blocked.
+	BlockedReason BlockedReason     `json:"blocked_reason,omitempty"`
+	BlockedDetail string            `json:"blocked_detail,omitempty"`
+	UnblockWhen   *UnblockCondition `json:"unblock_when,omitempty"`
+	Version       int64             `json:"version,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	// CommentCount is set on tasks from ListTasks.
+	CommentCount int `json:"comment_count,omitempty"`
 }
 
-// Insight represents a research insight from Pollard
-type Insight struct {
+// TaskComment is a progress note recorded against a task.
+type TaskComment struct {
 	ID        string    `json:"id"`
 	Project   string    `json:"project"`
-	SpecID    string    `json:"spec_id,omitempty"`
-	Source    string    `json:"source"`
-	Category  string    `json:"category"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body,omitempty"`
-	URL       string    `json:"url,omitempty"`
-	Score     float64   `json:"score"`
+	TaskID    string    `json:"task_id"`
+	Author    string    `json:"author,omitempty"`
+	Body      string    `json:"body"`
+	Cursor    uint64    `json:"cursor"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// TaskCommentPage is one page of a task's comments, oldest first. Pass
+// Cursor to ListTaskComments for the next page.
+type TaskCommentPage struct {
+	Comments []TaskComment `json:"comments"`
+	Cursor   uint64        `json:"cursor"`
+}
+
+// Insight represents a research insight from Pollard
+type Insight struct {
+	ID          string        `json:"id"`
+	Project     string        `json:"project"`
+	SpecID      string        `json:"spec_id,omitempty"`
+	Source      string        `json:"source"`
+	Category    string        `json:"category"`
+	Title       string        `json:"title"`
+	Body        string        `json:"body,omitempty"`
+	URL         string        `json:"url,omitempty"`
+	Score       float64       `json:"score"`
+	Status      InsightStatus `json:"status,omitempty"`
+	Version     int64         `json:"version,omitempty"`
+	ContentHash string        `json:"content_hash,omitempty"` // set by the server, for deduplication
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
 // Session represents an agent session (tmux session)
 type Session struct {
 	ID        string        `json:"id"`
@@ -137,17 +223,27 @@ type Session struct {
 	Agent     string        `json:"agent"`
 	TaskID    string        `json:"task_id,omitempty"`
 	Status    SessionStatus `json:"status"`
+	Version   int64         `json:"version,omitempty"`
 	StartedAt time.Time     `json:"started_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
+	// HeartbeatAt is set by HeartbeatSession; once it is, the server stops
+	// the session when heartbeats lapse.
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
 }
 
 // DomainEvent wraps a domain entity change for event sourcing
 type DomainEvent struct {
-	Type      string    `json:"type"`
-	Project   string    `json:"project"`
-	EntityID  string    `json:"entity_id"`
-	Data      any       `json:"data,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	SchemaVersion int       `json:"schema_version,omitempty"` // 0 at version 1, and from servers before versioning
+	Type          string    `json:"type"`
+	EventID       string    `json:"event_id,omitempty"`
+	Project       string    `json:"project"`
+	EntityType    string    `json:"entity_type,omitempty"` // schema version 2 on
+	EntityID      string    `json:"entity_id"`
+	Agent         string    `json:"agent,omitempty"`      // message events: the recipient
+	MessageID     string    `json:"message_id,omitempty"` // message events
+	Cursor        uint64    `json:"cursor,omitempty"`
+	Data          any       `json:"data,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // CUJStatus represents the status of a Critical User Journey
@@ -206,6 +302,42 @@ type CUJFeatureLink struct {
 // ErrConflict is returned when optimistic locking fails
 var ErrConflict = fmt.Errorf("concurrent modification conflict")
 
+// ConflictError is returned by updates that lost the optimistic-lock race
+// when the server sent back the entity as it now stands. It matches
+// ErrConflict under errors.Is; use errors.As with the entity's type, e.g.
+// *ConflictError[Task], to merge onto Current and retry.
+type ConflictError[T any] struct {
+	Message string
+	Version int64
+	Current T
+}
+
+func (e *ConflictError[T]) Error() string {
+	return fmt.Sprintf("%s (now at version %d)", e.Message, e.Version)
+}
+
+func (e *ConflictError[T]) Is(target error) bool { return target == ErrConflict }
+
+// conflictError reads a 409 from an update. Bodies without the current
+// entity (duplicate titles, invalid transitions, older servers) yield
+// plain ErrConflict.
+func conflictError[T any](resp *http.Response) error {
+	var body struct {
+		Error   string          `json:"error"`
+		Code    string          `json:"code"`
+		Version int64           `json:"version"`
+		Current json.RawMessage `json:"current"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Code != "version_conflict" || len(body.Current) == 0 {
+		return ErrConflict
+	}
+	out := &ConflictError[T]{Message: body.Error, Version: body.Version}
+	if err := json.Unmarshal(body.Current, &out.Current); err != nil {
+		return ErrConflict
+	}
+	return out
+}
+
 // --- Spec Operations ---
 
 // CreateSpec creates a new specification
@@ -213,7 +345,7 @@ func (c *Client) CreateSpec(ctx context.Context, spec Spec) (Spec, error) {
 	if spec.Project == "" {
 		spec.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/specs", spec)
+	resp, err := c.postCreate(ctx, "/api/specs", spec)
 	if err != nil {
 		return Spec{}, err
 	}
@@ -222,7 +354,7 @@ func (c *Client) CreateSpec(ctx context.Context, spec Spec) (Spec, error) {
 		return Spec{}, ErrConflict
 	}
 	if resp.StatusCode != http.StatusCreated {
-		return Spec{}, fmt.Errorf("create spec failed: %d", resp.StatusCode)
+		return Spec{}, apiError(resp, "create spec")
 	}
 	var out Spec
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -231,7 +363,7 @@ func (c *Client) CreateSpec(ctx context.Context, spec Spec) (Spec, error) {
 	return out, nil
 }
 
-// GetSpec retrieves a specification by ID
+// GetSpec retrieves a specification by ID or short ID ("SPEC-3")
 func (c *Client) GetSpec(ctx context.Context, id string) (Spec, error) {
 	endpoint := "/api/specs/" + url.PathEscape(id)
 	if c.Project != "" {
@@ -242,11 +374,8 @@ func (c *Client) GetSpec(ctx context.Context, id string) (Spec, error) {
 		return Spec{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return Spec{}, fmt.Errorf("spec not found: %s", id)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return Spec{}, fmt.Errorf("get spec failed: %d", resp.StatusCode)
+		return Spec{}, apiError(resp, "get spec")
 	}
 	var out Spec
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -255,8 +384,40 @@ func (c *Client) GetSpec(ctx context.Context, id string) (Spec, error) {
 	return out, nil
 }
 
+// ListOption adjusts the query of a List call.
+type ListOption func(url.Values)
+
+// IncludeArchived makes a List call return archived and finished entities
+// (archived specs and CUJs, done epics, stories and tasks, dismissed
+// insights), which are left out by default.
+func IncludeArchived() ListOption {
+	return func(v url.Values) { v.Set("include_archived", "true") }
+}
+
+// TaskFilter narrows ListTasks with the server's filter syntax, e.g.
+// "status:in(pending,blocked) updated_after:2024-06-01 story:xyz".
+func TaskFilter(expr string) ListOption {
+	return func(v url.Values) { v.Set("filter", expr) }
+}
+
+// SortTasks orders ListTasks by comma-separated fields, each descending
+// with a leading "-": "-updated_at,priority".
+func SortTasks(fields string) ListOption {
+	return func(v url.Values) { v.Set("sort", fields) }
+}
+
+// Limit caps how many entities a List call returns.
+func Limit(n int) ListOption {
+	return func(v url.Values) { v.Set("limit", strconv.Itoa(n)) }
+}
+
+// OnlyStale makes ListSpecs return only stale specs.
+func OnlyStale() ListOption {
+	return func(v url.Values) { v.Set("stale", "true") }
+}
+
 // ListSpecs lists specifications with optional filters
-func (c *Client) ListSpecs(ctx context.Context, status string) ([]Spec, error) {
+func (c *Client) ListSpecs(ctx context.Context, status string, opts ...ListOption) ([]Spec, error) {
 	values := url.Values{}
 	if c.Project != "" {
 		values.Set("project", c.Project)
@@ -264,6 +425,9 @@ func (c *Client) ListSpecs(ctx context.Context, status string) ([]Spec, error) {
 	if status != "" {
 		values.Set("status", status)
 	}
+	for _, opt := range opts {
+		opt(values)
+	}
 	endpoint := "/api/specs"
 	if len(values) > 0 {
 		endpoint += "?" + values.Encode()
@@ -274,7 +438,7 @@ func (c *Client) ListSpecs(ctx context.Context, status string) ([]Spec, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list specs failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list specs")
 	}
 	var out []Spec
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -294,10 +458,10 @@ func (c *Client) UpdateSpec(ctx context.Context, spec Spec) (Spec, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusConflict {
-		return Spec{}, ErrConflict
+		return Spec{}, conflictError[Spec](resp)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return Spec{}, fmt.Errorf("update spec failed: %d", resp.StatusCode)
+		return Spec{}, apiError(resp, "update spec")
 	}
 	var out Spec
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -318,11 +482,113 @@ func (c *Client) DeleteSpec(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete spec failed: %d", resp.StatusCode)
+		return apiError(resp, "delete spec")
 	}
 	return nil
 }
 
+// PublishedSpec is an immutable snapshot of a validated spec and its CUJs.
+type PublishedSpec struct {
+	Project     string                `json:"project"`
+	SpecID      string                `json:"spec_id"`
+	Number      int                   `json:"number"`
+	Spec        Spec                  `json:"spec"`
+	CUJs        []CriticalUserJourney `json:"cujs"`
+	PublishedBy string                `json:"published_by,omitempty"`
+	PublishedAt time.Time             `json:"published_at"`
+	Permalink   string                `json:"permalink"`
+}
+
+// ReviewSpec marks a validated spec reviewed, restarting its staleness
+// window.
+func (c *Client) ReviewSpec(ctx context.Context, id string) (Spec, error) {
+	endpoint := "/api/specs/" + url.PathEscape(id) + "/review"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.postJSON(ctx, endpoint, map[string]string{})
+	if err != nil {
+		return Spec{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Spec{}, apiError(resp, "review spec")
+	}
+	var out Spec
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Spec{}, err
+	}
+	return out, nil
+}
+
+// PublishSpec freezes a validated spec as its next published version.
+func (c *Client) PublishSpec(ctx context.Context, id string) (PublishedSpec, error) {
+	endpoint := "/api/specs/" + url.PathEscape(id) + "/publish"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.postJSON(ctx, endpoint, map[string]string{})
+	if err != nil {
+		return PublishedSpec{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return PublishedSpec{}, fmt.Errorf("spec %s is not validated", id)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return PublishedSpec{}, apiError(resp, "publish spec")
+	}
+	var out PublishedSpec
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return PublishedSpec{}, err
+	}
+	return out, nil
+}
+
+// GetPublishedSpec fetches published version number of a spec.
+func (c *Client) GetPublishedSpec(ctx context.Context, id string, number int) (PublishedSpec, error) {
+	endpoint := fmt.Sprintf("/api/specs/%s/published/%d", url.PathEscape(id), number)
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return PublishedSpec{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return PublishedSpec{}, apiError(resp, "get published spec")
+	}
+	var out PublishedSpec
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return PublishedSpec{}, err
+	}
+	return out, nil
+}
+
+// ExportSpecMarkdown renders a spec with its epics, stories (with
+// acceptance criteria) and CUJs as one Markdown document.
+func (c *Client) ExportSpecMarkdown(ctx context.Context, id string) (string, error) {
+	values := url.Values{}
+	values.Set("format", "markdown")
+	if c.Project != "" {
+		values.Set("project", c.Project)
+	}
+	resp, err := c.get(ctx, "/api/specs/"+url.PathEscape(id)+"/export?"+values.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", apiError(resp, "export spec")
+	}
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
 // --- Epic Operations ---
 
 // CreateEpic creates a new epic
@@ -330,7 +596,7 @@ func (c *Client) CreateEpic(ctx context.Context, epic Epic) (Epic, error) {
 	if epic.Project == "" {
 		epic.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/epics", epic)
+	resp, err := c.postCreate(ctx, "/api/epics", epic)
 	if err != nil {
 		return Epic{}, err
 	}
@@ -339,7 +605,7 @@ func (c *Client) CreateEpic(ctx context.Context, epic Epic) (Epic, error) {
 		return Epic{}, ErrConflict
 	}
 	if resp.StatusCode != http.StatusCreated {
-		return Epic{}, fmt.Errorf("create epic failed: %d", resp.StatusCode)
+		return Epic{}, apiError(resp, "create epic")
 	}
 	var out Epic
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -359,11 +625,8 @@ func (c *Client) GetEpic(ctx context.Context, id string) (Epic, error) {
 		return Epic{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return Epic{}, fmt.Errorf("epic not found: %s", id)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return Epic{}, fmt.Errorf("get epic failed: %d", resp.StatusCode)
+		return Epic{}, apiError(resp, "get epic")
 	}
 	var out Epic
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -373,7 +636,7 @@ func (c *Client) GetEpic(ctx context.Context, id string) (Epic, error) {
 }
 
 // ListEpics lists epics with optional spec filter
-func (c *Client) ListEpics(ctx context.Context, specID string) ([]Epic, error) {
+func (c *Client) ListEpics(ctx context.Context, specID string, opts ...ListOption) ([]Epic, error) {
 	values := url.Values{}
 	if c.Project != "" {
 		values.Set("project", c.Project)
@@ -381,6 +644,9 @@ func (c *Client) ListEpics(ctx context.Context, specID string) ([]Epic, error) {
 	if specID != "" {
 		values.Set("spec", specID)
 	}
+	for _, opt := range opts {
+		opt(values)
+	}
 	endpoint := "/api/epics"
 	if len(values) > 0 {
 		endpoint += "?" + values.Encode()
@@ -391,7 +657,7 @@ func (c *Client) ListEpics(ctx context.Context, specID string) ([]Epic, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list epics failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list epics")
 	}
 	var out []Epic
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -411,10 +677,10 @@ func (c *Client) UpdateEpic(ctx context.Context, epic Epic) (Epic, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusConflict {
-		return Epic{}, ErrConflict
+		return Epic{}, conflictError[Epic](resp)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return Epic{}, fmt.Errorf("update epic failed: %d", resp.StatusCode)
+		return Epic{}, apiError(resp, "update epic")
 	}
 	var out Epic
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -435,7 +701,7 @@ func (c *Client) DeleteEpic(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete epic failed: %d", resp.StatusCode)
+		return apiError(resp, "delete epic")
 	}
 	return nil
 }
@@ -447,7 +713,7 @@ func (c *Client) CreateStory(ctx context.Context, story Story) (Story, error) {
 	if story.Project == "" {
 		story.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/stories", story)
+	resp, err := c.postCreate(ctx, "/api/stories", story)
 	if err != nil {
 		return Story{}, err
 	}
@@ -456,7 +722,7 @@ func (c *Client) CreateStory(ctx context.Context, story Story) (Story, error) {
 		return Story{}, ErrConflict
 	}
 	if resp.StatusCode != http.StatusCreated {
-		return Story{}, fmt.Errorf("create story failed: %d", resp.StatusCode)
+		return Story{}, apiError(resp, "create story")
 	}
 	var out Story
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -476,11 +742,8 @@ func (c *Client) GetStory(ctx context.Context, id string) (Story, error) {
 		return Story{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return Story{}, fmt.Errorf("story not found: %s", id)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return Story{}, fmt.Errorf("get story failed: %d", resp.StatusCode)
+		return Story{}, apiError(resp, "get story")
 	}
 	var out Story
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -490,7 +753,7 @@ func (c *Client) GetStory(ctx context.Context, id string) (Story, error) {
 }
 
 // ListStories lists stories with optional epic filter
-func (c *Client) ListStories(ctx context.Context, epicID string) ([]Story, error) {
+func (c *Client) ListStories(ctx context.Context, epicID string, opts ...ListOption) ([]Story, error) {
 	values := url.Values{}
 	if c.Project != "" {
 		values.Set("project", c.Project)
@@ -498,6 +761,9 @@ func (c *Client) ListStories(ctx context.Context, epicID string) ([]Story, error
 	if epicID != "" {
 		values.Set("epic", epicID)
 	}
+	for _, opt := range opts {
+		opt(values)
+	}
 	endpoint := "/api/stories"
 	if len(values) > 0 {
 		endpoint += "?" + values.Encode()
@@ -508,7 +774,7 @@ func (c *Client) ListStories(ctx context.Context, epicID string) ([]Story, error
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list stories failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list stories")
 	}
 	var out []Story
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -528,10 +794,10 @@ func (c *Client) UpdateStory(ctx context.Context, story Story) (Story, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusConflict {
-		return Story{}, ErrConflict
+		return Story{}, conflictError[Story](resp)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return Story{}, fmt.Errorf("update story failed: %d", resp.StatusCode)
+		return Story{}, apiError(resp, "update story")
 	}
 	var out Story
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -552,11 +818,33 @@ func (c *Client) DeleteStory(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete story failed: %d", resp.StatusCode)
+		return apiError(resp, "delete story")
 	}
 	return nil
 }
 
+// RankStory moves a story within its epic to just after story after and
+// just before story before. Either may be empty, but not both.
+func (c *Client) RankStory(ctx context.Context, id, after, before string) (Story, error) {
+	resp, err := c.putJSON(ctx, "/api/stories/"+url.PathEscape(id)+"/rank", map[string]string{
+		"project": c.Project,
+		"after":   after,
+		"before":  before,
+	})
+	if err != nil {
+		return Story{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Story{}, apiError(resp, "rank story")
+	}
+	var out Story
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Story{}, err
+	}
+	return out, nil
+}
+
 // --- Task Operations ---
 
 // CreateTask creates a new task
@@ -564,7 +852,7 @@ func (c *Client) CreateTask(ctx context.Context, task Task) (Task, error) {
 	if task.Project == "" {
 		task.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/tasks", task)
+	resp, err := c.postCreate(ctx, "/api/tasks", task)
 	if err != nil {
 		return Task{}, err
 	}
@@ -573,7 +861,7 @@ func (c *Client) CreateTask(ctx context.Context, task Task) (Task, error) {
 		return Task{}, ErrConflict
 	}
 	if resp.StatusCode != http.StatusCreated {
-		return Task{}, fmt.Errorf("create task failed: %d", resp.StatusCode)
+		return Task{}, apiError(resp, "create task")
 	}
 	var out Task
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -582,7 +870,7 @@ func (c *Client) CreateTask(ctx context.Context, task Task) (Task, error) {
 	return out, nil
 }
 
-// GetTask retrieves a task by ID
+// GetTask retrieves a task by ID or short ID ("TASK-12")
 func (c *Client) GetTask(ctx context.Context, id string) (Task, error) {
 	endpoint := "/api/tasks/" + url.PathEscape(id)
 	if c.Project != "" {
@@ -593,11 +881,8 @@ func (c *Client) GetTask(ctx context.Context, id string) (Task, error) {
 		return Task{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return Task{}, fmt.Errorf("task not found: %s", id)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return Task{}, fmt.Errorf("get task failed: %d", resp.StatusCode)
+		return Task{}, apiError(resp, "get task")
 	}
 	var out Task
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -607,7 +892,7 @@ func (c *Client) GetTask(ctx context.Context, id string) (Task, error) {
 }
 
 // ListTasks lists tasks with optional filters
-func (c *Client) ListTasks(ctx context.Context, status, agent string) ([]Task, error) {
+func (c *Client) ListTasks(ctx context.Context, status, agent string, opts ...ListOption) ([]Task, error) {
 	values := url.Values{}
 	if c.Project != "" {
 		values.Set("project", c.Project)
@@ -618,6 +903,9 @@ func (c *Client) ListTasks(ctx context.Context, status, agent string) ([]Task, e
 	if agent != "" {
 		values.Set("agent", agent)
 	}
+	for _, opt := range opts {
+		opt(values)
+	}
 	endpoint := "/api/tasks"
 	if len(values) > 0 {
 		endpoint += "?" + values.Encode()
@@ -628,7 +916,7 @@ func (c *Client) ListTasks(ctx context.Context, status, agent string) ([]Task, e
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list tasks failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list tasks")
 	}
 	var out []Task
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -639,19 +927,36 @@ func (c *Client) ListTasks(ctx context.Context, status, agent string) ([]Task, e
 
 // UpdateTask updates a task
 func (c *Client) UpdateTask(ctx context.Context, task Task) (Task, error) {
+	return c.updateTask(ctx, task, "")
+}
+
+// CompleteTask marks task done. If it was its story's last open task, the
+// server moves the story on in the same transaction: to advanceStory
+// ("review" or "done"), not at all for "none", or as the project's
+// story_auto_advance flag says for "".
+func (c *Client) CompleteTask(ctx context.Context, task Task, advanceStory string) (Task, error) {
+	task.Status = TaskStatusDone
+	query := ""
+	if advanceStory != "" {
+		query = "?advance_story=" + url.QueryEscape(advanceStory)
+	}
+	return c.updateTask(ctx, task, query)
+}
+
+func (c *Client) updateTask(ctx context.Context, task Task, query string) (Task, error) {
 	if task.Project == "" {
 		task.Project = c.Project
 	}
-	resp, err := c.putJSON(ctx, "/api/tasks/"+url.PathEscape(task.ID), task)
+	resp, err := c.putJSON(ctx, "/api/tasks/"+url.PathEscape(task.ID)+query, task)
 	if err != nil {
 		return Task{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusConflict {
-		return Task{}, ErrConflict
+		return Task{}, conflictError[Task](resp)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return Task{}, fmt.Errorf("update task failed: %d", resp.StatusCode)
+		return Task{}, apiError(resp, "update task")
 	}
 	var out Task
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -672,7 +977,7 @@ func (c *Client) AssignTask(ctx context.Context, taskID, agent string) (Task, er
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return Task{}, fmt.Errorf("assign task failed: %d", resp.StatusCode)
+		return Task{}, apiError(resp, "assign task")
 	}
 	var out Task
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -681,6 +986,201 @@ func (c *Client) AssignTask(ctx context.Context, taskID, agent string) (Task, er
 	return out, nil
 }
 
+// ClaimOptions shapes a ClaimTasks call. Agent is who gets the tasks; nil
+// Capabilities uses the agent's registered ones. Limit <= 0 claims one
+// (the server caps it at 50). Steal also takes pending tasks assigned to
+// other agents. A non-zero Lease puts the claimed tasks under one shared
+// lease (see ClaimTaskBatch); tasks still running when it lapses go back
+// to pending.
+type ClaimOptions struct {
+	Agent        string
+	Capabilities []string
+	Limit        int
+	Steal        bool
+	Lease        time.Duration
+}
+
+// TaskLease is a shared lease over the tasks of one batch claim.
+type TaskLease struct {
+	ID        string    `json:"id"`
+	Project   string    `json:"project"`
+	Agent     string    `json:"agent"`
+	TaskIDs   []string  `json:"task_ids"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TaskBatch is the result of ClaimTaskBatch. Lease is nil when nothing was
+// claimed or no lease was asked for.
+type TaskBatch struct {
+	Tasks []Task     `json:"tasks"`
+	Lease *TaskLease `json:"lease,omitempty"`
+}
+
+// ClaimTasks atomically assigns the oldest pending tasks the agent can do
+// (all their required capabilities) to it and starts them. Unlike
+// ListTasks + AssignTask, two agents claiming at once never get the same
+// task. An empty result means there was nothing to claim.
+func (c *Client) ClaimTasks(ctx context.Context, opts ClaimOptions) ([]Task, error) {
+	batch, err := c.ClaimTaskBatch(ctx, opts)
+	return batch.Tasks, err
+}
+
+// ClaimTaskBatch is ClaimTasks for batch workers: it claims up to
+// opts.Limit tasks in one transaction, returning fewer when fewer are
+// available, together with their shared lease when opts.Lease is set.
+func (c *Client) ClaimTaskBatch(ctx context.Context, opts ClaimOptions) (TaskBatch, error) {
+	body := map[string]any{
+		"project":      c.Project,
+		"agent":        opts.Agent,
+		"capabilities": opts.Capabilities,
+		"limit":        opts.Limit,
+		"steal":        opts.Steal,
+	}
+	if opts.Lease > 0 {
+		body["lease_seconds"] = leaseSeconds(opts.Lease)
+	}
+	resp, err := c.postJSON(ctx, "/api/tasks/claim", body)
+	if err != nil {
+		return TaskBatch{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return TaskBatch{}, apiError(resp, "claim tasks")
+	}
+	var out TaskBatch
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TaskBatch{}, err
+	}
+	return out, nil
+}
+
+// ErrLeaseExpired is returned when renewing or releasing a task lease that
+// has already lapsed or been released.
+var ErrLeaseExpired = fmt.Errorf("task lease expired")
+
+// RenewTaskLease extends a batch claim lease to ttl from now. It returns
+// ErrLeaseExpired once the lease has lapsed: its tasks may have been
+// claimed by someone else.
+func (c *Client) RenewTaskLease(ctx context.Context, leaseID string, ttl time.Duration) (TaskLease, error) {
+	resp, err := c.postJSON(ctx, "/api/tasks/leases/"+url.PathEscape(leaseID)+"/renew", map[string]any{
+		"project":       c.Project,
+		"lease_seconds": leaseSeconds(ttl),
+	})
+	if err != nil {
+		return TaskLease{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return TaskLease{}, ErrLeaseExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return TaskLease{}, apiError(resp, "renew task lease")
+	}
+	var out TaskLease
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TaskLease{}, err
+	}
+	return out, nil
+}
+
+// ReleaseTaskLease ends a batch claim lease early and returns the tasks
+// that were still running, now pending again.
+func (c *Client) ReleaseTaskLease(ctx context.Context, leaseID string) ([]Task, error) {
+	q := url.Values{}
+	q.Set("project", c.Project)
+	resp, err := c.delete(ctx, "/api/tasks/leases/"+url.PathEscape(leaseID)+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrLeaseExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "release task lease")
+	}
+	var out struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
+
+// leaseSeconds rounds a lease length up to whole seconds.
+func leaseSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// TaskPathOverlap is one pair of overlapping expected paths.
+type TaskPathOverlap struct {
+	Path      string `json:"path"`
+	OtherPath string `json:"other_path"`
+}
+
+// TaskConflict is two pending or running tasks whose expected paths
+// overlap. Concurrent is set when both are already running.
+type TaskConflict struct {
+	TaskID      string            `json:"task_id"`
+	TaskTitle   string            `json:"task_title"`
+	TaskStatus  TaskStatus        `json:"task_status"`
+	OtherID     string            `json:"other_task_id"`
+	OtherTitle  string            `json:"other_task_title"`
+	OtherStatus TaskStatus        `json:"other_task_status"`
+	Concurrent  bool              `json:"concurrent"`
+	Overlaps    []TaskPathOverlap `json:"overlaps"`
+}
+
+// TaskConflicts lists pending and running tasks whose expected paths
+// overlap. With a non-empty taskID (ID or short ID), only that task's
+// conflicts are returned.
+func (c *Client) TaskConflicts(ctx context.Context, taskID string) ([]TaskConflict, error) {
+	q := url.Values{}
+	q.Set("project", c.Project)
+	if taskID != "" {
+		q.Set("task", taskID)
+	}
+	resp, err := c.get(ctx, "/api/tasks/conflicts?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "task conflicts")
+	}
+	var out struct {
+		Conflicts []TaskConflict `json:"conflicts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Conflicts, nil
+}
+
+// UnblockExternal reports externalRef resolved and returns the tasks that
+// were waiting on it, now back to pending or running.
+func (c *Client) UnblockExternal(ctx context.Context, externalRef string) ([]Task, error) {
+	resp, err := c.postJSON(ctx, "/api/tasks/unblock", map[string]string{
+		"project":      c.Project,
+		"external_ref": externalRef,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "unblock tasks")
+	}
+	var out struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
+
 // DeleteTask deletes a task
 func (c *Client) DeleteTask(ctx context.Context, id string) error {
 	endpoint := "/api/tasks/" + url.PathEscape(id)
@@ -693,7 +1193,73 @@ func (c *Client) DeleteTask(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete task failed: %d", resp.StatusCode)
+		return apiError(resp, "delete task")
+	}
+	return nil
+}
+
+// AddTaskComment records body against a task (ID or short ID). An empty
+// author defaults to the calling key's agent.
+func (c *Client) AddTaskComment(ctx context.Context, taskID, author, body string) (TaskComment, error) {
+	resp, err := c.postJSON(ctx, "/api/tasks/"+url.PathEscape(taskID)+"/comments", map[string]string{
+		"project": c.Project,
+		"author":  author,
+		"body":    body,
+	})
+	if err != nil {
+		return TaskComment{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return TaskComment{}, apiError(resp, "add task comment")
+	}
+	var out TaskComment
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TaskComment{}, err
+	}
+	return out, nil
+}
+
+// ListTaskComments returns up to limit of a task's comments after the
+// cursor after (0 for the first page). A limit of 0 takes the server's
+// default.
+func (c *Client) ListTaskComments(ctx context.Context, taskID string, after uint64, limit int) (TaskCommentPage, error) {
+	q := url.Values{}
+	if c.Project != "" {
+		q.Set("project", c.Project)
+	}
+	q.Set("after", strconv.FormatUint(after, 10))
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	resp, err := c.get(ctx, "/api/tasks/"+url.PathEscape(taskID)+"/comments?"+q.Encode())
+	if err != nil {
+		return TaskCommentPage{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return TaskCommentPage{}, apiError(resp, "list task comments")
+	}
+	var out TaskCommentPage
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TaskCommentPage{}, err
+	}
+	return out, nil
+}
+
+// DeleteTaskComment deletes one of a task's comments.
+func (c *Client) DeleteTaskComment(ctx context.Context, taskID, commentID string) error {
+	endpoint := "/api/tasks/" + url.PathEscape(taskID) + "/comments/" + url.PathEscape(commentID)
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.delete(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp, "delete task comment")
 	}
 	return nil
 }
@@ -705,13 +1271,14 @@ func (c *Client) CreateInsight(ctx context.Context, insight Insight) (Insight, e
 	if insight.Project == "" {
 		insight.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/insights", insight)
+	resp, err := c.postCreate(ctx, "/api/insights", insight)
 	if err != nil {
 		return Insight{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusCreated {
-		return Insight{}, fmt.Errorf("create insight failed: %d", resp.StatusCode)
+	// 200 when the project deduplicates insights and this one already exists.
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return Insight{}, apiError(resp, "create insight")
 	}
 	var out Insight
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -731,11 +1298,8 @@ func (c *Client) GetInsight(ctx context.Context, id string) (Insight, error) {
 		return Insight{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return Insight{}, fmt.Errorf("insight not found: %s", id)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return Insight{}, fmt.Errorf("get insight failed: %d", resp.StatusCode)
+		return Insight{}, apiError(resp, "get insight")
 	}
 	var out Insight
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -745,7 +1309,7 @@ func (c *Client) GetInsight(ctx context.Context, id string) (Insight, error) {
 }
 
 // ListInsights lists insights with optional filters
-func (c *Client) ListInsights(ctx context.Context, specID, category string) ([]Insight, error) {
+func (c *Client) ListInsights(ctx context.Context, specID, category string, opts ...ListOption) ([]Insight, error) {
 	values := url.Values{}
 	if c.Project != "" {
 		values.Set("project", c.Project)
@@ -756,6 +1320,9 @@ func (c *Client) ListInsights(ctx context.Context, specID, category string) ([]I
 	if category != "" {
 		values.Set("category", category)
 	}
+	for _, opt := range opts {
+		opt(values)
+	}
 	endpoint := "/api/insights"
 	if len(values) > 0 {
 		endpoint += "?" + values.Encode()
@@ -766,7 +1333,7 @@ func (c *Client) ListInsights(ctx context.Context, specID, category string) ([]I
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list insights failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list insights")
 	}
 	var out []Insight
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -775,6 +1342,54 @@ func (c *Client) ListInsights(ctx context.Context, specID, category string) ([]I
 	return out, nil
 }
 
+// UpdateInsight updates an insight (optimistic locking via version)
+func (c *Client) UpdateInsight(ctx context.Context, insight Insight) (Insight, error) {
+	if insight.Project == "" {
+		insight.Project = c.Project
+	}
+	resp, err := c.putJSON(ctx, "/api/insights/"+url.PathEscape(insight.ID), insight)
+	if err != nil {
+		return Insight{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return Insight{}, conflictError[Insight](resp)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Insight{}, apiError(resp, "update insight")
+	}
+	var out Insight
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Insight{}, err
+	}
+	return out, nil
+}
+
+// SetInsightStatus moves an insight through triage (new, triaged, actioned,
+// dismissed). ErrConflict means the transition isn't allowed from the
+// insight's current status.
+func (c *Client) SetInsightStatus(ctx context.Context, id string, status InsightStatus) (Insight, error) {
+	resp, err := c.patchJSON(ctx, "/api/insights/"+url.PathEscape(id), map[string]any{
+		"project": c.Project,
+		"status":  status,
+	})
+	if err != nil {
+		return Insight{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return Insight{}, ErrConflict
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Insight{}, apiError(resp, "set insight status")
+	}
+	var out Insight
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Insight{}, err
+	}
+	return out, nil
+}
+
 // LinkInsightToSpec links an insight to a specification
 func (c *Client) LinkInsightToSpec(ctx context.Context, insightID, specID string) error {
 	endpoint := "/api/insights/" + url.PathEscape(insightID) + "/link"
@@ -787,11 +1402,32 @@ func (c *Client) LinkInsightToSpec(ctx context.Context, insightID, specID string
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("link insight failed: %d", resp.StatusCode)
+		return apiError(resp, "link insight")
 	}
 	return nil
 }
 
+// MergeInsights folds duplicates into the insight id and deletes them,
+// returning the merged insight. The duplicates' IDs keep resolving to it.
+func (c *Client) MergeInsights(ctx context.Context, id string, duplicates []string) (Insight, error) {
+	resp, err := c.postJSON(ctx, "/api/insights/"+url.PathEscape(id)+"/merge", map[string]any{
+		"project":    c.Project,
+		"duplicates": duplicates,
+	})
+	if err != nil {
+		return Insight{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Insight{}, apiError(resp, "merge insights")
+	}
+	var out Insight
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Insight{}, err
+	}
+	return out, nil
+}
+
 // DeleteInsight deletes an insight
 func (c *Client) DeleteInsight(ctx context.Context, id string) error {
 	endpoint := "/api/insights/" + url.PathEscape(id)
@@ -804,7 +1440,7 @@ func (c *Client) DeleteInsight(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete insight failed: %d", resp.StatusCode)
+		return apiError(resp, "delete insight")
 	}
 	return nil
 }
@@ -816,13 +1452,13 @@ func (c *Client) CreateSession(ctx context.Context, session Session) (Session, e
 	if session.Project == "" {
 		session.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/sessions", session)
+	resp, err := c.postCreate(ctx, "/api/sessions", session)
 	if err != nil {
 		return Session{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusCreated {
-		return Session{}, fmt.Errorf("create session failed: %d", resp.StatusCode)
+		return Session{}, apiError(resp, "create session")
 	}
 	var out Session
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -842,11 +1478,32 @@ func (c *Client) GetSession(ctx context.Context, id string) (Session, error) {
 		return Session{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return Session{}, fmt.Errorf("session not found: %s", id)
+	if resp.StatusCode != http.StatusOK {
+		return Session{}, apiError(resp, "get session")
+	}
+	var out Session
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Session{}, err
 	}
+	return out, nil
+}
+
+// HeartbeatSession records that session id is alive. After its first
+// heartbeat, a session that stops sending them is marked idle (or error,
+// if bound to a task), its tasks are released and session.stopped is
+// broadcast.
+func (c *Client) HeartbeatSession(ctx context.Context, id string) (Session, error) {
+	endpoint := "/api/sessions/" + url.PathEscape(id) + "/heartbeat"
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.postJSON(ctx, endpoint, struct{}{})
+	if err != nil {
+		return Session{}, err
+	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return Session{}, fmt.Errorf("get session failed: %d", resp.StatusCode)
+		return Session{}, apiError(resp, "heartbeat session")
 	}
 	var out Session
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -874,7 +1531,7 @@ func (c *Client) ListSessions(ctx context.Context, status string) ([]Session, er
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list sessions failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list sessions")
 	}
 	var out []Session
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -893,8 +1550,11 @@ func (c *Client) UpdateSession(ctx context.Context, session Session) (Session, e
 		return Session{}, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return Session{}, conflictError[Session](resp)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return Session{}, fmt.Errorf("update session failed: %d", resp.StatusCode)
+		return Session{}, apiError(resp, "update session")
 	}
 	var out Session
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -915,11 +1575,67 @@ func (c *Client) DeleteSession(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete session failed: %d", resp.StatusCode)
+		return apiError(resp, "delete session")
 	}
 	return nil
 }
 
+// SessionStats summarises one agent's sessions over a window, or one day
+// or week of it when PeriodStart is set. AvgDurationSeconds only counts
+// ended sessions; Errored counts those whose last status was error.
+type SessionStats struct {
+	Agent              string     `json:"agent"`
+	PeriodStart        *time.Time `json:"period_start,omitempty"`
+	Sessions           int        `json:"sessions"`
+	Ended              int        `json:"ended"`
+	Errored            int        `json:"errored"`
+	ErrorRate          float64    `json:"error_rate"`
+	AvgDurationSeconds float64    `json:"avg_duration_seconds"`
+	TasksCompleted     int        `json:"tasks_completed"`
+	TasksPerSession    float64    `json:"tasks_per_session"`
+}
+
+// SessionAnalyticsOptions selects the window of SessionAnalytics. Zero
+// times default to the last 30 days; Bucket is "", "day" or "week".
+type SessionAnalyticsOptions struct {
+	Since  time.Time
+	Until  time.Time
+	Bucket string
+}
+
+// SessionAnalytics summarises the sessions started in a window per agent,
+// ordered by agent then period.
+func (c *Client) SessionAnalytics(ctx context.Context, opts SessionAnalyticsOptions) ([]SessionStats, error) {
+	values := url.Values{}
+	if c.Project != "" {
+		values.Set("project", c.Project)
+	}
+	if !opts.Since.IsZero() {
+		values.Set("since", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		values.Set("until", opts.Until.UTC().Format(time.RFC3339))
+	}
+	if opts.Bucket != "" {
+		values.Set("bucket", opts.Bucket)
+	}
+	resp, err := c.get(ctx, "/api/reports/sessions?"+values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "session analytics")
+	}
+	var out struct {
+		Stats []SessionStats `json:"stats"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Stats, nil
+}
+
 // --- CUJ Operations ---
 
 // CreateCUJ creates a new Critical User Journey
@@ -927,7 +1643,7 @@ func (c *Client) CreateCUJ(ctx context.Context, cuj CriticalUserJourney) (Critic
 	if cuj.Project == "" {
 		cuj.Project = c.Project
 	}
-	resp, err := c.postJSON(ctx, "/api/cujs", cuj)
+	resp, err := c.postCreate(ctx, "/api/cujs", cuj)
 	if err != nil {
 		return CriticalUserJourney{}, err
 	}
@@ -936,7 +1652,7 @@ func (c *Client) CreateCUJ(ctx context.Context, cuj CriticalUserJourney) (Critic
 		return CriticalUserJourney{}, ErrConflict
 	}
 	if resp.StatusCode != http.StatusCreated {
-		return CriticalUserJourney{}, fmt.Errorf("create cuj failed: %d", resp.StatusCode)
+		return CriticalUserJourney{}, apiError(resp, "create cuj")
 	}
 	var out CriticalUserJourney
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -956,11 +1672,8 @@ func (c *Client) GetCUJ(ctx context.Context, id string) (CriticalUserJourney, er
 		return CriticalUserJourney{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return CriticalUserJourney{}, fmt.Errorf("cuj not found: %s", id)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return CriticalUserJourney{}, fmt.Errorf("get cuj failed: %d", resp.StatusCode)
+		return CriticalUserJourney{}, apiError(resp, "get cuj")
 	}
 	var out CriticalUserJourney
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -970,7 +1683,7 @@ func (c *Client) GetCUJ(ctx context.Context, id string) (CriticalUserJourney, er
 }
 
 // ListCUJs lists CUJs with optional spec filter
-func (c *Client) ListCUJs(ctx context.Context, specID string) ([]CriticalUserJourney, error) {
+func (c *Client) ListCUJs(ctx context.Context, specID string, opts ...ListOption) ([]CriticalUserJourney, error) {
 	values := url.Values{}
 	if c.Project != "" {
 		values.Set("project", c.Project)
@@ -978,6 +1691,9 @@ func (c *Client) ListCUJs(ctx context.Context, specID string) ([]CriticalUserJou
 	if specID != "" {
 		values.Set("spec", specID)
 	}
+	for _, opt := range opts {
+		opt(values)
+	}
 	endpoint := "/api/cujs"
 	if len(values) > 0 {
 		endpoint += "?" + values.Encode()
@@ -988,7 +1704,7 @@ func (c *Client) ListCUJs(ctx context.Context, specID string) ([]CriticalUserJou
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list cujs failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "list cujs")
 	}
 	var out []CriticalUserJourney
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -1008,10 +1724,10 @@ func (c *Client) UpdateCUJ(ctx context.Context, cuj CriticalUserJourney) (Critic
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusConflict {
-		return CriticalUserJourney{}, ErrConflict
+		return CriticalUserJourney{}, conflictError[CriticalUserJourney](resp)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return CriticalUserJourney{}, fmt.Errorf("update cuj failed: %d", resp.StatusCode)
+		return CriticalUserJourney{}, apiError(resp, "update cuj")
 	}
 	var out CriticalUserJourney
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -1032,7 +1748,7 @@ func (c *Client) DeleteCUJ(ctx context.Context, id string) error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete cuj failed: %d", resp.StatusCode)
+		return apiError(resp, "delete cuj")
 	}
 	return nil
 }
@@ -1049,7 +1765,7 @@ func (c *Client) LinkCUJToFeature(ctx context.Context, cujID, featureID string)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("link cuj failed: %d", resp.StatusCode)
+		return apiError(resp, "link cuj")
 	}
 	return nil
 }
@@ -1066,7 +1782,7 @@ func (c *Client) UnlinkCUJFromFeature(ctx context.Context, cujID, featureID stri
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unlink cuj failed: %d", resp.StatusCode)
+		return apiError(resp, "unlink cuj")
 	}
 	return nil
 }
@@ -1083,7 +1799,7 @@ func (c *Client) GetCUJFeatureLinks(ctx context.Context, cujID string) ([]CUJFea
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get cuj links failed: %d", resp.StatusCode)
+		return nil, apiError(resp, "get cuj links")
 	}
 	var out []CUJFeatureLink
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -1095,17 +1811,25 @@ func (c *Client) GetCUJFeatureLinks(ctx context.Context, cujID string) ([]CUJFea
 // --- HTTP helpers ---
 
 func (c *Client) putJSON(ctx context.Context, path string, payload any) (*http.Response, error) {
+	return c.sendJSON(ctx, http.MethodPut, path, payload)
+}
+
+func (c *Client) patchJSON(ctx context.Context, path string, payload any) (*http.Response, error) {
+	return c.sendJSON(ctx, http.MethodPatch, path, payload)
+}
+
+func (c *Client) sendJSON(ctx context.Context, method, path string, payload any) (*http.Response, error) {
 	buf, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.BaseURL+path, bytes.NewReader(buf))
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(buf))
 	if err != nil {
 		return nil, err
 	}
 	c.applyHeaders(req)
 	req.Header.Set("Content-Type", "application/json")
-	return c.HTTP.Do(req)
+	return c.do(req)
 }
 
 func (c *Client) delete(ctx context.Context, path string) (*http.Response, error) {
@@ -1114,5 +1838,5 @@ func (c *Client) delete(ctx context.Context, path string) (*http.Response, error
 		return nil, err
 	}
 	c.applyHeaders(req)
-	return c.HTTP.Do(req)
+	return c.do(req)
 }
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -352,6 +353,68 @@ func TestClientAssignTask(t *testing.T) {
 	}
 }
 
+func TestClientClaimTasks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tasks/claim" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Project      string   `json:"project"`
+			Agent        string   `json:"agent"`
+			Capabilities []string `json:"capabilities"`
+			Limit        int      `json:"limit"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Project != "proj-a" || req.Limit != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"tasks": []Task{
+			{ID: "task-1", Agent: req.Agent, Status: TaskStatusRunning, Capabilities: req.Capabilities},
+		}})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tasks, err := c.ClaimTasks(ctx, ClaimOptions{Agent: "worker", Capabilities: []string{"go"}, Limit: 2})
+	if err != nil {
+		t.Fatalf("claim tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Agent != "worker" || tasks[0].Capabilities[0] != "go" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+}
+
+func TestClientTaskConflicts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tasks/conflicts" || r.URL.Query().Get("project") != "proj-a" || r.URL.Query().Get("task") != "TASK-2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"conflicts": []TaskConflict{{
+			TaskID: "task-2", OtherID: "task-1", Overlaps: []TaskPathOverlap{{Path: "a/*.go", OtherPath: "a/b.go"}},
+		}}})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conflicts, err := c.TaskConflicts(ctx, "TASK-2")
+	if err != nil {
+		t.Fatalf("task conflicts: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].OtherID != "task-1" || conflicts[0].Overlaps[0].OtherPath != "a/b.go" {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+}
+
 func TestClientCreateInsight(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/insights" {
@@ -440,3 +503,33 @@ func TestClientConflictError(t *testing.T) {
 		t.Fatalf("expected ErrConflict, got %v", err)
 	}
 }
+
+func TestClientConflictErrorCarriesCurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error":   "entity was modified concurrently",
+			"code":    "version_conflict",
+			"version": 4,
+			"current": Task{ID: "task-1", Title: "Theirs", Status: "running", Version: 4},
+		})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := c.UpdateTask(ctx, Task{ID: "task-1", Title: "Mine", Version: 3})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	var conflict *ConflictError[Task]
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected *ConflictError[Task], got %T", err)
+	}
+	if conflict.Version != 4 || conflict.Current.Title != "Theirs" {
+		t.Fatalf("expected current task at version 4, got %+v", conflict)
+	}
+}
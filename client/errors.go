@@ -0,0 +1,157 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors an *APIError matches under errors.Is by status code.
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrForbidden      = errors.New("forbidden")
+	ErrNotFound       = errors.New("not found")
+)
+
+// APIError is a non-success response from the server. Code is the
+// server's machine-readable error code ("project_mismatch",
+// "invalid_json", ...) and is empty when the body wasn't a JSON error;
+// Details holds any code-specific payload, undecoded.
+type APIError struct {
+	Op      string
+	Status  int
+	Code    string
+	Message string
+	Details json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s failed: %d", e.Op, e.Status)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" && e.Message != e.Code {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is matches the sentinel for e's status code, so callers can write
+// errors.Is(err, client.ErrNotFound) without inspecting Status.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidRequest:
+		return e.Status == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	}
+	return false
+}
+
+// maxErrorBody caps how much of an error response apiError reads.
+const maxErrorBody = 64 << 10
+
+// apiError builds the *APIError for resp, an unexpected response to op.
+// It reads the body; older servers that answer with an empty body or
+// {"error": "..."} still yield a usable error.
+func apiError(resp *http.Response, op string) error {
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		raw = nil
+	}
+	return decodeAPIError(op, resp.StatusCode, raw)
+}
+
+// decodeAPIError builds the *APIError for an error response body.
+func decodeAPIError(op string, status int, raw []byte) *APIError {
+	out := &APIError{Op: op, Status: status}
+	if len(raw) == 0 {
+		return out
+	}
+	var body struct {
+		Error   string          `json:"error"`
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+	}
+	if json.Unmarshal(raw, &body) != nil {
+		return out
+	}
+	out.Code = body.Code
+	out.Message = body.Message
+	if out.Message == "" {
+		out.Message = body.Error
+	}
+	out.Details = body.Details
+	return out
+}
+
+// ErrReservationConflict is matched by a *ReservationConflictError.
+var ErrReservationConflict = errors.New("reservation conflict")
+
+// ReservationConflict is an active reservation that overlaps a requested
+// pattern.
+type ReservationConflict struct {
+	ReservationID string    `json:"reservation_id"`
+	AgentID       string    `json:"agent_id"`
+	HeldBy        string    `json:"held_by"`
+	Pattern       string    `json:"pattern"`
+	Exclusive     bool      `json:"exclusive"`
+	Reason        string    `json:"reason,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// ReservationConflictError is returned by Reserve when active reservations
+// overlap the pattern. Wait for them to lapse (see FreeAt), narrow the
+// pattern, or, if none of them is Exclusive, reserve it shared instead.
+type ReservationConflictError struct {
+	Message   string
+	Conflicts []ReservationConflict
+}
+
+func (e *ReservationConflictError) Error() string {
+	return "reserve failed: " + e.Message
+}
+
+func (e *ReservationConflictError) Is(target error) bool { return target == ErrReservationConflict }
+
+// FreeAt is when the last conflicting reservation expires, unless its
+// holder renews or releases it first.
+func (e *ReservationConflictError) FreeAt() time.Time {
+	var at time.Time
+	for _, c := range e.Conflicts {
+		if c.ExpiresAt.After(at) {
+			at = c.ExpiresAt
+		}
+	}
+	return at
+}
+
+// reservationError reads an error response to Reserve: a
+// *ReservationConflictError for a reservation_conflict 409, otherwise the
+// *APIError.
+func reservationError(resp *http.Response) error {
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		raw = nil
+	}
+	if resp.StatusCode == http.StatusConflict {
+		var body struct {
+			Code      string                `json:"code"`
+			Message   string                `json:"message"`
+			Conflicts []ReservationConflict `json:"conflicts"`
+		}
+		if json.Unmarshal(raw, &body) == nil && body.Code == "reservation_conflict" {
+			return &ReservationConflictError{Message: body.Message, Conflicts: body.Conflicts}
+		}
+	}
+	return decodeAPIError("reserve", resp.StatusCode, raw)
+}
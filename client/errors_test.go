@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/specs":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"project does not match the API key's project","code":"project_mismatch","message":"project does not match the API key's project"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-b"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := c.CreateSpec(ctx, Spec{Title: "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("create spec err = %v, want *APIError", err)
+	}
+	if apiErr.Status != http.StatusForbidden || apiErr.Code != "project_mismatch" || apiErr.Message == "" {
+		t.Fatalf("api error = %+v", apiErr)
+	}
+	if !errors.Is(err, ErrForbidden) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("errors.Is(%v) mismatched sentinels", err)
+	}
+
+	// An empty body (older servers) still yields a typed error.
+	_, err = c.GetSpec(ctx, "missing")
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Code != "" {
+		t.Fatalf("get spec err = %v, want bare not found", err)
+	}
+}
+
+func TestReserveConflictError(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var req Reservation
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Exclusive {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"error":   "reservation_conflict",
+				"code":    "reservation_conflict",
+				"message": "reservation conflict with bob (src/*.go)",
+				"conflicts": []map[string]any{
+					{"reservation_id": "r1", "agent_id": "a-bob", "held_by": "bob", "pattern": "src/*.go", "reason": "refactor", "expires_at": expires},
+				},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Reservation{ID: "r2", AgentID: req.AgentID, PathPattern: req.PathPattern})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := c.Reserve(ctx, Reservation{AgentID: "alice", PathPattern: "src/main.go", Exclusive: true})
+	var conflict *ReservationConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrReservationConflict) {
+		t.Fatalf("reserve err = %v, want *ReservationConflictError", err)
+	}
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].HeldBy != "bob" || conflict.Conflicts[0].Exclusive || !conflict.FreeAt().Equal(expires) {
+		t.Fatalf("conflict = %+v", conflict)
+	}
+
+	res, err := c.Reserve(ctx, Reservation{AgentID: "alice", PathPattern: "src/main.go"})
+	if err != nil || res.ID != "r2" {
+		t.Fatalf("shared reserve = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// EntityRef is an entity found by ID alone: its type ("spec", "task",
+// "session", ...), summary fields, and URL, the path to fetch it.
+type EntityRef struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	ShortID   string    `json:"short_id,omitempty"`
+	Project   string    `json:"project"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Lookup resolves a UUID or short ID ("TASK-348") of unknown type to the
+// entities it names in the client project. IDs are only unique per entity
+// type, so there may be several; none is an empty result, not an error.
+func (c *Client) Lookup(ctx context.Context, id string) ([]EntityRef, error) {
+	endpoint := "/api/lookup/" + url.PathEscape(id)
+	if c.Project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.Project)
+	}
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return []EntityRef{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "lookup")
+	}
+	var out struct {
+		Matches []EntityRef `json:"matches"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Matches, nil
+}
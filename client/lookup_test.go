@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientLookup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/lookup/TASK-7" || r.URL.Query().Get("project") != "proj-a" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "TASK-7",
+			"matches": []EntityRef{{Type: "task", ID: "uuid-7", ShortID: "TASK-7", Project: "proj-a", URL: "/api/tasks/uuid-7?project=proj-a"}},
+		})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	refs, err := c.Lookup(ctx, "TASK-7")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Type != "task" || refs[0].ID != "uuid-7" {
+		t.Fatalf("refs = %+v", refs)
+	}
+
+	refs, err = c.Lookup(ctx, "nope")
+	if err != nil || len(refs) != 0 {
+		t.Fatalf("missing lookup = %+v, %v; want empty", refs, err)
+	}
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Project is a registered project. Specs, tasks and other entities can
+// only be created under a registered project that isn't archived.
+type Project struct {
+	Name        string     `json:"name"`
+	DisplayName string     `json:"display_name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	// SpecStaleDays is how many days a validated spec may go unreviewed
+	// before it is stale; 0 means the server default (90).
+	SpecStaleDays int `json:"spec_stale_days,omitempty"`
+	// ShortIDSequences overrides the project's short ID prefix and starting
+	// number per entity type ("spec", "epic", "story", "task").
+	ShortIDSequences map[string]ShortIDSequence `json:"short_id_sequences,omitempty"`
+}
+
+// ShortIDSequence numbers one entity type's short IDs: Prefix "PAY" and
+// Start 100 issue PAY-100, PAY-101, ... Zero fields use the defaults (the
+// type's own prefix, and 1). A number is never issued twice under a
+// prefix, so a Start at or below one already issued has no effect.
+type ShortIDSequence struct {
+	Prefix string `json:"prefix,omitempty"`
+	Start  int64  `json:"start,omitempty"`
+}
+
+// ProjectOptions are the optional fields of CreateProject. Template is
+// "basic" (the default: a draft starter spec and CUJ, with the
+// strict_transitions and story_auto_advance flags on) or "empty".
+type ProjectOptions struct {
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Template    string `json:"template,omitempty"`
+	// ShortIDSequences is set before the template's starter spec is
+	// created, so it is numbered under it.
+	ShortIDSequences map[string]ShortIDSequence `json:"short_id_sequences,omitempty"`
+	SpecStaleDays    int                        `json:"spec_stale_days,omitempty"`
+	// Flags sets project feature flags on top of the template's; false
+	// turns a default off.
+	Flags map[string]bool `json:"flags,omitempty"`
+}
+
+// ErrProjectExists is returned by CreateProject for a name already taken.
+var ErrProjectExists = errors.New("project already exists")
+
+// CreateProject registers project name and bootstraps it from
+// opts.Template.
+func (c *Client) CreateProject(ctx context.Context, name string, opts ProjectOptions) (Project, error) {
+	resp, err := c.postJSON(ctx, "/api/projects", struct {
+		Name string `json:"name"`
+		ProjectOptions
+	}{name, opts})
+	if err != nil {
+		return Project{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return Project{}, ErrProjectExists
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return Project{}, apiError(resp, "create project")
+	}
+	var out struct {
+		Metadata Project `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Project{}, err
+	}
+	return out.Metadata, nil
+}
+
+// GetProject returns a registered project.
+func (c *Client) GetProject(ctx context.Context, name string) (Project, error) {
+	resp, err := c.get(ctx, "/api/projects/"+url.PathEscape(name))
+	if err != nil {
+		return Project{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Project{}, apiError(resp, "get project")
+	}
+	var out Project
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Project{}, err
+	}
+	return out, nil
+}
+
+// ListProjects returns registered projects, with archived ones if
+// includeArchived. API-key clients only see their own.
+func (c *Client) ListProjects(ctx context.Context, includeArchived bool) ([]Project, error) {
+	endpoint := "/api/projects"
+	if includeArchived {
+		endpoint += "?include_archived=true"
+	}
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "list projects")
+	}
+	var out struct {
+		Projects []Project `json:"projects"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Projects, nil
+}
+
+// UpdateProject replaces a project's display name, description and spec
+// staleness window, and its short ID sequences unless p.ShortIDSequences
+// is nil. An invalid sequence (a bad prefix, or two types sharing one)
+// fails with code "invalid_short_id_sequence".
+func (c *Client) UpdateProject(ctx context.Context, p Project) (Project, error) {
+	body := map[string]any{
+		"display_name":    p.DisplayName,
+		"description":     p.Description,
+		"spec_stale_days": p.SpecStaleDays,
+	}
+	if p.ShortIDSequences != nil {
+		body["short_id_sequences"] = p.ShortIDSequences
+	}
+	resp, err := c.patchJSON(ctx, "/api/projects/"+url.PathEscape(p.Name), body)
+	if err != nil {
+		return Project{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Project{}, apiError(resp, "update project")
+	}
+	var out Project
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Project{}, err
+	}
+	return out, nil
+}
+
+// DeleteProject unregisters an empty project. One that still has agents,
+// messages or entities fails with code "project_not_empty".
+func (c *Client) DeleteProject(ctx context.Context, name string) error {
+	resp, err := c.delete(ctx, "/api/projects/"+url.PathEscape(name))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp, "delete project")
+	}
+	return nil
+}
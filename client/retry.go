@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryPolicy controls how requests are retried after a transport error or
+// a 429, 502, 503 or 504 response. Delays double from BaseDelay up to
+// MaxDelay, with jitter; a Retry-After header is honored up to MaxDelay.
+//
+// GET, PUT and DELETE are always retried. POST and PATCH are retried only
+// when they carry an idempotency key: Create* calls get one automatically,
+// other calls through WithIdempotencyKey. The server replays the first
+// response to a repeated key, so a retried create never creates twice.
+type RetryPolicy struct {
+	// MaxAttempts counts the first try; 0 or 1 disables retries.
+	MaxAttempts int
+	// BaseDelay defaults to 100ms, MaxDelay to 5s.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// WithRetry retries failed requests according to p.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = p
+	}
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey sends key as the Idempotency-Key header on POST and
+// PATCH requests made with the returned context, which makes them safe to
+// retry. Use one key per logical operation.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// NewIdempotencyKey returns a random key for WithIdempotencyKey.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	_, _ = crand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// postCreate POSTs a Create* request. With retries enabled it runs under an
+// idempotency key, the caller's or a fresh one, so retrying can't create
+// the entity twice.
+func (c *Client) postCreate(ctx context.Context, path string, payload any) (*http.Response, error) {
+	if _, ok := ctx.Value(idempotencyKey{}).(string); !ok && c.Retry.MaxAttempts > 1 {
+		ctx = WithIdempotencyKey(ctx, NewIdempotencyKey())
+	}
+	return c.postJSON(ctx, path, payload)
+}
+
+// do sends req, retrying it under c.Retry when that is safe.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if retrySafe(req) {
+		attempts = max(c.Retry.MaxAttempts, 1)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.HTTP.Do(req)
+		if attempt >= attempts || !retryable(req, resp, err) {
+			recordEventCursor(req, resp)
+			return resp, err
+		}
+		delay := c.Retry.delay(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func retrySafe(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay is how long to wait before retry number attempt.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	base, ceiling := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if ceiling <= 0 {
+		ceiling = defaultRetryMaxDelay
+	}
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, ceiling)
+		}
+	}
+	d := min(base<<(attempt-1), ceiling)
+	if d <= 0 {
+		d = ceiling
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// rewind returns a copy of req with a fresh body for resending.
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryReusesIdempotencyKeyOnCreate(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		n := len(keys)
+		mu.Unlock()
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var task Task
+		_ = json.NewDecoder(r.Body).Decode(&task)
+		task.ID = "t1"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(task)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj"), WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, err := c.CreateTask(ctx, Task{Title: "Write docs"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if task.ID != "t1" || task.Title != "Write docs" {
+		t.Fatalf("task = %+v", task)
+	}
+	if len(keys) != 3 || keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Fatalf("idempotency keys across attempts = %q, want one key three times", keys)
+	}
+}
+
+func TestRetrySkipsPostsWithoutKey(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithRetry(RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendMessage(ctx, Message{From: "a", To: []string{"b"}, Body: "hi"}); err == nil {
+		t.Fatal("expected send to fail")
+	}
+	if calls != 1 {
+		t.Fatalf("send was tried %d times, want once (POST without a key isn't retry-safe)", calls)
+	}
+	if _, err := c.ListAgents(ctx, ""); err == nil {
+		t.Fatal("expected list to fail")
+	}
+	if calls != 5 {
+		t.Fatalf("calls = %d, want 1 send + 4 list attempts", calls)
+	}
+}
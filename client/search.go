@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Search result kinds.
+const (
+	SearchKindSpec    = "spec"
+	SearchKindStory   = "story"
+	SearchKindInsight = "insight"
+	SearchKindMessage = "message"
+)
+
+// SearchResult is one entity matching a full-text search. Snippet is the
+// best-matching excerpt with matched terms in [brackets]; a higher Score is
+// a better match.
+type SearchResult struct {
+	Kind    string  `json:"kind"`
+	ID      string  `json:"id"`
+	Project string  `json:"project"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// SearchOptions narrows a search. Empty Kinds searches every kind; Limit
+// <= 0 uses the server default (20, max 100).
+type SearchOptions struct {
+	Kinds []string
+	Limit int
+}
+
+// Search runs a full-text search over the client project's specs, stories,
+// insights and messages, best match first. Terms are ANDed; a trailing *
+// matches a prefix.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	values := url.Values{}
+	values.Set("q", query)
+	if c.Project != "" {
+		values.Set("project", c.Project)
+	}
+	if len(opts.Kinds) > 0 {
+		values.Set("kind", strings.Join(opts.Kinds, ","))
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	resp, err := c.get(ctx, "/api/search?"+values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, "search")
+	}
+	var out struct {
+		Results []SearchResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientSearch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/search" || q.Get("q") != "retry budget" || q.Get("project") != "proj-a" ||
+			q.Get("kind") != "spec,insight" || q.Get("limit") != "5" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"query":   q.Get("q"),
+			"results": []SearchResult{{Kind: SearchKindSpec, ID: "spec-1", Project: "proj-a", Title: "Retries", Snippet: "[retry] [budget]", Score: 2.5}},
+		})
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithProject("proj-a"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	results, err := c.Search(ctx, "retry budget", SearchOptions{Kinds: []string{SearchKindSpec, SearchKindInsight}, Limit: 5})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 1 || results[0].Kind != SearchKindSpec || results[0].ID != "spec-1" {
+		t.Fatalf("results = %+v", results)
+	}
+}
@@ -0,0 +1,431 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+// Defaults for Subscribe.
+const (
+	DefaultSubscribePingInterval = 30 * time.Second
+	DefaultSubscribeMinBackoff   = 500 * time.Millisecond
+	DefaultSubscribeMaxBackoff   = 30 * time.Second
+
+	// EventSchemaVersion is the newest event payload schema this client
+	// understands. Subscribe pins it unless told otherwise, so a server that
+	// moves on keeps down-converting to it.
+	EventSchemaVersion = 2
+
+	defaultSubscribeBuffer = 64
+	subscribeDialTimeout   = 10 * time.Second
+	subscribeReadLimit     = 1 << 20
+)
+
+// SubscribeOptions configures Subscribe.
+type SubscribeOptions struct {
+	// Agent is the agent whose stream to follow. Required.
+	Agent string
+	// Events and EntityIDs narrow the stream like a WebSocket subscribe
+	// frame: event type patterns such as "task.*", and entity IDs.
+	Events    []string
+	EntityIDs []string
+	// Cursor resumes after this cursor: the agent's messages and the
+	// project's domain events past it are delivered before live events.
+	// 0 starts with live events only.
+	Cursor uint64
+	// From starts at this cursor instead, delivering its event too: pass
+	// an EventCursor's value to see your own writes exactly once, however
+	// their broadcast raced the subscription. Set Cursor or From, not both.
+	From uint64
+	// PingInterval is how often the connection is pinged; a ping not
+	// answered within the interval drops the connection.
+	PingInterval time.Duration
+	// MinBackoff and MaxBackoff bound the jittered, doubling delay between
+	// reconnect attempts.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Buffer is the channel capacity (default 64). A full channel stalls
+	// the stream rather than dropping events.
+	Buffer int
+	// SchemaVersion pins the event payload schema (default
+	// EventSchemaVersion); see Capabilities for the versions a server
+	// supports.
+	SchemaVersion int
+}
+
+// Subscribe follows opts.Agent's WebSocket stream on /ws/agents/ and
+// delivers its events on the returned channel until ctx ends, when the
+// channel is closed. A dropped connection is redialed with backoff, and
+// the events missed meanwhile are fetched over HTTP and delivered, in
+// cursor order, before live events resume. Only the first dial's error is
+// returned; later failures are retried.
+func (c *Client) Subscribe(ctx context.Context, opts SubscribeOptions) (<-chan DomainEvent, error) {
+	if strings.TrimSpace(opts.Agent) == "" {
+		return nil, fmt.Errorf("subscribe: agent is required")
+	}
+	for _, p := range opts.Events {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("subscribe: invalid event pattern %q: %w", p, err)
+		}
+	}
+	if opts.From > 0 {
+		if opts.Cursor > 0 {
+			return nil, fmt.Errorf("subscribe: set Cursor or From, not both")
+		}
+		opts.Cursor = opts.From - 1
+	}
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = DefaultSubscribePingInterval
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultSubscribeMinBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultSubscribeMaxBackoff
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultSubscribeBuffer
+	}
+	if opts.SchemaVersion <= 0 {
+		opts.SchemaVersion = EventSchemaVersion
+	}
+	s := &subscriber{
+		c:      c,
+		opts:   opts,
+		out:    make(chan DomainEvent, opts.Buffer),
+		cursor: opts.Cursor,
+	}
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go s.run(ctx, conn)
+	return s.out, nil
+}
+
+// subscriber is one Subscribe stream. Only its run goroutine touches it.
+type subscriber struct {
+	c    *Client
+	opts SubscribeOptions
+	out  chan DomainEvent
+	// cursor is the highest cursor delivered; replayed is the highest
+	// cursor covered by the last catch-up, below which live events are
+	// duplicates.
+	cursor   uint64
+	replayed uint64
+}
+
+func (s *subscriber) run(ctx context.Context, conn *websocket.Conn) {
+	defer close(s.out)
+	backoff := RetryPolicy{BaseDelay: s.opts.MinBackoff, MaxDelay: s.opts.MaxBackoff}
+	for attempt := 0; ; {
+		if conn != nil {
+			if err := s.catchUp(ctx); err == nil {
+				s.serve(ctx, conn)
+				attempt = 0
+			} else {
+				conn.Close(websocket.StatusGoingAway, "catch-up failed")
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		attempt++
+		timer := time.NewTimer(backoff.delay(attempt, nil))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		conn, _ = s.dial(ctx)
+	}
+}
+
+func (s *subscriber) dial(ctx context.Context) (*websocket.Conn, error) {
+	u, err := url.Parse(s.c.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/ws/agents/" + url.PathEscape(s.opts.Agent)
+	query := url.Values{"schema_version": {strconv.Itoa(s.opts.SchemaVersion)}}
+	if s.c.Project != "" {
+		query.Set("project", s.c.Project)
+	}
+	u.RawQuery = query.Encode()
+	dialOpts := &websocket.DialOptions{HTTPHeader: http.Header{}}
+	if s.c.APIKey != "" {
+		dialOpts.HTTPHeader.Set("Authorization", "Bearer "+s.c.APIKey)
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, subscribeDialTimeout)
+	defer cancel()
+	conn, _, err := websocket.Dial(dctx, u.String(), dialOpts)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe: dial: %w", err)
+	}
+	conn.SetReadLimit(subscribeReadLimit)
+	if len(s.opts.Events) > 0 || len(s.opts.EntityIDs) > 0 {
+		frame := map[string]any{"type": "subscribe", "events": s.opts.Events, "entity_ids": s.opts.EntityIDs}
+		if err := wsjson.Write(dctx, conn, frame); err != nil {
+			conn.Close(websocket.StatusInternalError, "subscribe failed")
+			return nil, fmt.Errorf("subscribe: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// serve delivers conn's events until it drops or ctx ends.
+func (s *subscriber) serve(ctx context.Context, conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	go s.heartbeat(ctx, conn)
+	for {
+		var ev DomainEvent
+		if err := wsjson.Read(ctx, conn, &ev); err != nil {
+			return
+		}
+		if ev.Cursor > 0 && ev.Cursor <= s.replayed {
+			continue
+		}
+		if !s.deliver(ctx, ev) {
+			return
+		}
+	}
+}
+
+// heartbeat pings conn every PingInterval and closes it when a ping goes
+// unanswered, which ends serve's read.
+func (s *subscriber) heartbeat(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(s.opts.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pctx, cancel := context.WithTimeout(ctx, s.opts.PingInterval)
+		err := conn.Ping(pctx)
+		cancel()
+		if err != nil {
+			conn.Close(websocket.StatusGoingAway, "ping timeout")
+			return
+		}
+	}
+}
+
+// deliver sends ev to the channel if it passes the subscription's filter.
+// Control frames are dropped. It reports false once ctx has ended.
+func (s *subscriber) deliver(ctx context.Context, ev DomainEvent) bool {
+	if ev.Type == "subscribed" || ev.Type == "error" || !s.matches(ev) {
+		return true
+	}
+	select {
+	case s.out <- ev:
+	case <-ctx.Done():
+		return false
+	}
+	s.cursor = max(s.cursor, ev.Cursor)
+	return true
+}
+
+// matches applies Events and EntityIDs the way the server's subscribe
+// frame does, for replayed events and ones sent before the frame landed.
+func (s *subscriber) matches(ev DomainEvent) bool {
+	if len(s.opts.Events) > 0 {
+		typ := strings.ReplaceAll(ev.Type, ".", "/")
+		if !slices.ContainsFunc(s.opts.Events, func(p string) bool {
+			ok, _ := path.Match(strings.ReplaceAll(p, ".", "/"), typ)
+			return ok
+		}) {
+			return false
+		}
+	}
+	return len(s.opts.EntityIDs) == 0 || slices.Contains(s.opts.EntityIDs, ev.EntityID)
+}
+
+// catchUp delivers the messages and domain events past the last delivered
+// cursor, in cursor order. It does nothing before the first cursor.
+func (s *subscriber) catchUp(ctx context.Context) error {
+	from := s.cursor
+	if from == 0 {
+		return nil
+	}
+	missed, err := s.missedMessages(ctx, from)
+	if err != nil {
+		return err
+	}
+	events, err := s.missedDomainEvents(ctx, from)
+	if err != nil {
+		return err
+	}
+	missed = append(missed, events...)
+	slices.SortFunc(missed, func(a, b DomainEvent) int {
+		switch {
+		case a.Cursor < b.Cursor:
+			return -1
+		case a.Cursor > b.Cursor:
+			return 1
+		}
+		return 0
+	})
+	for _, ev := range missed {
+		if !s.deliver(ctx, ev) {
+			return ctx.Err()
+		}
+	}
+	s.replayed = from
+	if n := len(missed); n > 0 {
+		s.replayed = missed[n-1].Cursor
+	}
+	return nil
+}
+
+func (s *subscriber) missedMessages(ctx context.Context, cursor uint64) ([]DomainEvent, error) {
+	var out []DomainEvent
+	for {
+		resp, err := s.c.InboxSince(ctx, s.opts.Agent, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range resp.Messages {
+			out = append(out, DomainEvent{
+				Type:      "message.created",
+				Project:   m.Project,
+				Agent:     s.opts.Agent,
+				MessageID: m.ID,
+				Cursor:    m.Cursor,
+			})
+		}
+		if len(resp.Messages) == 0 || resp.Cursor <= cursor {
+			return out, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+// missedDomainEvents pages through the project's persisted domain events.
+// Servers without the domain API (404) have none to replay.
+func (s *subscriber) missedDomainEvents(ctx context.Context, cursor uint64) ([]DomainEvent, error) {
+	if s.c.Project == "" {
+		return nil, nil
+	}
+	var out []DomainEvent
+	for {
+		values := url.Values{}
+		values.Set("project", s.c.Project)
+		values.Set("cursor", strconv.FormatUint(cursor, 10))
+		values.Set("schema_version", strconv.Itoa(s.opts.SchemaVersion))
+		resp, err := s.c.get(ctx, "/api/events?"+values.Encode())
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return out, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := apiError(resp, "list events")
+			resp.Body.Close()
+			return nil, err
+		}
+		var page struct {
+			Events []struct {
+				SchemaVersion int             `json:"schema_version"`
+				Cursor        uint64          `json:"cursor"`
+				ID            string          `json:"event_id"`
+				Type          string          `json:"type"`
+				Project       string          `json:"project"`
+				EntityType    string          `json:"entity_type"`
+				EntityID      string          `json:"entity_id"`
+				Data          json.RawMessage `json:"data"`
+				CreatedAt     time.Time       `json:"created_at"`
+			} `json:"events"`
+			Cursor uint64 `json:"cursor"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range page.Events {
+			ev := DomainEvent{
+				SchemaVersion: e.SchemaVersion,
+				Type:          e.Type,
+				EventID:       e.ID,
+				Project:       e.Project,
+				EntityID:      e.EntityID,
+				Cursor:        e.Cursor,
+				CreatedAt:     e.CreatedAt,
+			}
+			if e.SchemaVersion >= 2 {
+				ev.EntityType = e.EntityType // as live events carry it
+			}
+			if len(e.Data) > 0 {
+				_ = json.Unmarshal(e.Data, &ev.Data)
+			}
+			out = append(out, ev)
+		}
+		if len(page.Events) == 0 || page.Cursor <= cursor {
+			return out, nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+type eventCursorKey struct{}
+
+// EventCursor collects the event log position of writes: the highest
+// X-Event-Cursor the server returned for requests made with a context from
+// WithEventCursor. Safe for concurrent use.
+type EventCursor struct {
+	v atomic.Uint64
+}
+
+// Load returns the highest cursor recorded, or 0 if no write appended an
+// event (or the server predates the header).
+func (e *EventCursor) Load() uint64 { return e.v.Load() }
+
+// WithEventCursor records, in into, the cursor of the events appended by
+// writes made with the returned context. Follow up with
+// SubscribeOptions.From set to into.Load() to read your own writes.
+func WithEventCursor(ctx context.Context, into *EventCursor) context.Context {
+	return context.WithValue(ctx, eventCursorKey{}, into)
+}
+
+func recordEventCursor(req *http.Request, resp *http.Response) {
+	into, _ := req.Context().Value(eventCursorKey{}).(*EventCursor)
+	if into == nil || resp == nil {
+		return
+	}
+	cursor, err := strconv.ParseUint(resp.Header.Get("X-Event-Cursor"), 10, 64)
+	if err != nil {
+		return
+	}
+	for {
+		cur := into.v.Load()
+		if cursor <= cur || into.v.CompareAndSwap(cur, cursor) {
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+func TestSubscribeReconnectsAndResumesFromCursor(t *testing.T) {
+	var dials atomic.Int32
+	var mu sync.Mutex
+	var inboxSince []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws/agents/bob":
+			conn, err := websocket.Accept(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close(websocket.StatusNormalClosure, "")
+			ctx := r.Context()
+			if dials.Add(1) == 1 {
+				// First connection: one event, then drop.
+				wsjson.Write(ctx, conn, map[string]any{"type": "message.created", "message_id": "m5", "cursor": 5})
+				return
+			}
+			// Reconnect: the replayed message again (a duplicate), then a new event.
+			wsjson.Write(ctx, conn, map[string]any{"type": "message.created", "message_id": "m6", "cursor": 6})
+			wsjson.Write(ctx, conn, map[string]any{"type": "task.created", "entity_id": "t1", "cursor": 7})
+			conn.Read(ctx)
+		case "/api/inbox/bob":
+			since := r.URL.Query().Get("since_cursor")
+			mu.Lock()
+			inboxSince = append(inboxSince, since)
+			mu.Unlock()
+			resp := InboxResponse{Cursor: 6}
+			if since == "5" {
+				resp.Messages = []Message{{ID: "m6", Cursor: 6}}
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := New(srv.URL)
+	events, err := c.Subscribe(ctx, SubscribeOptions{Agent: "bob", MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	var got []string
+	for ev := range events {
+		got = append(got, ev.Type+"@"+ev.MessageID+ev.EntityID)
+		if len(got) == 3 {
+			break
+		}
+	}
+	want := []string{"message.created@m5", "message.created@m6", "task.created@t1"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+	mu.Lock()
+	if len(inboxSince) == 0 || inboxSince[0] != "5" {
+		t.Errorf("catch-up fetched inbox since %v, want 5 first", inboxSince)
+	}
+	mu.Unlock()
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestSubscribeRequiresAgent(t *testing.T) {
+	if _, err := New("http://unused").Subscribe(context.Background(), SubscribeOptions{}); err == nil {
+		t.Fatal("expected an error without an agent")
+	}
+}
+
+func TestSubscribeFromOwnWriteDeliversItOnce(t *testing.T) {
+	var mu sync.Mutex
+	var eventsSince []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tasks":
+			w.Header().Set("X-Event-Cursor", "7")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Task{ID: "t1", Project: "proj", Title: "mine"})
+		case "/ws/agents/bob":
+			conn, err := websocket.Accept(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close(websocket.StatusNormalClosure, "")
+			// The write's own broadcast lands after the socket opened...
+			wsjson.Write(r.Context(), conn, map[string]any{"type": "task.created", "entity_id": "t1", "cursor": 7})
+			wsjson.Write(r.Context(), conn, map[string]any{"type": "task.updated", "entity_id": "t2", "cursor": 8})
+			conn.Read(r.Context())
+		case "/api/events":
+			// ...and is replayed from the log too.
+			mu.Lock()
+			eventsSince = append(eventsSince, r.URL.Query().Get("cursor"))
+			mu.Unlock()
+			resp := map[string]any{"cursor": 6, "events": []any{}}
+			if r.URL.Query().Get("cursor") == "6" {
+				resp = map[string]any{"cursor": 7, "events": []any{
+					map[string]any{"type": "task.created", "entity_id": "t1", "cursor": 7},
+				}}
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/api/inbox/bob":
+			json.NewEncoder(w).Encode(InboxResponse{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := New(srv.URL, WithProject("proj"))
+	var written EventCursor
+	if _, err := c.CreateTask(WithEventCursor(ctx, &written), Task{Project: "proj", Title: "mine"}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if written.Load() != 7 {
+		t.Fatalf("event cursor = %d, want 7", written.Load())
+	}
+	if _, err := c.Subscribe(ctx, SubscribeOptions{Agent: "bob", Cursor: 1, From: 7}); err == nil {
+		t.Fatal("expected an error with both Cursor and From")
+	}
+	events, err := c.Subscribe(ctx, SubscribeOptions{Agent: "bob", From: written.Load()})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	var got []string
+	for ev := range events {
+		got = append(got, ev.Type+"@"+ev.EntityID)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != "task.created@t1" || got[1] != "task.updated@t2" {
+		t.Fatalf("events = %v, want the own write once, then t2", got)
+	}
+	mu.Lock()
+	if len(eventsSince) == 0 || eventsSince[0] != "6" {
+		t.Errorf("replayed events since %v, want 6 first", eventsSince)
+	}
+	mu.Unlock()
+	cancel()
+	for range events {
+	}
+}
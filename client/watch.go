@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Default intervals for WatchEntity. With an event stream, polling is only
+// a safety net for events missed while reconnecting.
+const (
+	DefaultWatchPollInterval   = 2 * time.Second
+	DefaultWatchSafetyInterval = 30 * time.Second
+)
+
+// WatchOptions configures WatchEntity.
+type WatchOptions struct {
+	// PollInterval overrides how often the entity is re-fetched:
+	// DefaultWatchPollInterval without an event stream,
+	// DefaultWatchSafetyInterval with one.
+	PollInterval time.Duration
+}
+
+// WatchEntity delivers id's current state on the returned channel, then
+// every changed state, until ctx ends and the channel is closed. get fetches
+// the entity (c.GetTask, c.GetSpec, ...). When c.Events is set, a domain
+// event for id triggers an immediate re-fetch; otherwise the entity is
+// polled. Fetch errors are retried at the next poll. A slow reader skips
+// intermediate states rather than stalling the watcher.
+func WatchEntity[T any](ctx context.Context, c *Client, id string, get func(context.Context, string) (T, error), opts WatchOptions) <-chan T {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultWatchPollInterval
+		if c.Events != nil {
+			interval = DefaultWatchSafetyInterval
+		}
+	}
+
+	poke := make(chan struct{}, 1)
+	if c.Events != nil {
+		stop := c.Events.watch(func(ev DomainEvent) {
+			if ev.EntityID != id {
+				return
+			}
+			select {
+			case poke <- struct{}{}:
+			default:
+			}
+		})
+		context.AfterFunc(ctx, stop)
+	}
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last T
+		var pending *T // latest state not yet taken by the reader
+		seen := false
+		for {
+			if v, err := get(ctx, id); err == nil && (!seen || !reflect.DeepEqual(v, last)) {
+				last, seen = v, true
+				pending = &v
+			}
+			for {
+				var send chan T
+				var next T
+				if pending != nil {
+					send, next = out, *pending
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case send <- next:
+					pending = nil
+					continue
+				case <-poke:
+				case <-ticker.C:
+				}
+				break
+			}
+		}
+	}()
+	return out
+}
+
+// WaitForTask blocks until task id reaches one of statuses and returns it,
+// or returns ctx's error. It watches the task with WatchEntity, so it uses
+// the client's event stream when one is set and polls otherwise.
+func (c *Client) WaitForTask(ctx context.Context, id string, statuses ...TaskStatus) (Task, error) {
+	if len(statuses) == 0 {
+		return Task{}, fmt.Errorf("wait for task: no target statuses")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for task := range WatchEntity(ctx, c, id, c.GetTask, WatchOptions{}) {
+		if slices.Contains(statuses, task.Status) {
+			return task, nil
+		}
+	}
+	return Task{}, ctx.Err()
+}
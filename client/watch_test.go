@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// taskServer serves GET /api/tasks/task-1 with whatever status is stored.
+func taskServer(t *testing.T, status *atomic.Value, fetches *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tasks/task-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Task{ID: "task-1", Status: status.Load().(TaskStatus)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWatchEntityPollsForChanges(t *testing.T) {
+	var status atomic.Value
+	status.Store(TaskStatusPending)
+	var fetches atomic.Int32
+	c := New(taskServer(t, &status, &fetches).URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates := WatchEntity(ctx, c, "task-1", c.GetTask, WatchOptions{PollInterval: 10 * time.Millisecond})
+
+	if first := <-updates; first.Status != TaskStatusPending {
+		t.Fatalf("first update = %+v", first)
+	}
+	status.Store(TaskStatusRunning)
+	if next := <-updates; next.Status != TaskStatusRunning {
+		t.Fatalf("next update = %+v, want running", next)
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestWaitForTaskUsesEventStream(t *testing.T) {
+	var status atomic.Value
+	status.Store(TaskStatusRunning)
+	var fetches atomic.Int32
+	events := NewWSClient("http://unused")
+	c := New(taskServer(t, &status, &fetches).URL, WithEventStream(events))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan Task, 1)
+	go func() {
+		task, err := c.WaitForTask(ctx, "task-1", TaskStatusDone, TaskStatusBlocked)
+		if err != nil {
+			t.Errorf("wait: %v", err)
+		}
+		done <- task
+	}()
+
+	// Without an event, the 30s safety poll means nothing further happens.
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	status.Store(TaskStatusDone)
+	events.dispatchEvent(DomainEvent{Type: EventTypes.TaskCompleted, EntityID: "other-task"})
+	events.dispatchEvent(DomainEvent{Type: EventTypes.TaskCompleted, EntityID: "task-1"})
+
+	select {
+	case task := <-done:
+		if task.Status != TaskStatusDone {
+			t.Fatalf("task = %+v", task)
+		}
+	case <-ctx.Done():
+		t.Fatal("WaitForTask didn't react to the task's event")
+	}
+	// The watcher unregisters once WaitForTask's context is released.
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		events.mu.RLock()
+		n := len(events.watchers)
+		events.mu.RUnlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d watchers left registered", n)
+		}
+	}
+}
+
+func TestWaitForTaskContextEnds(t *testing.T) {
+	var status atomic.Value
+	status.Store(TaskStatusPending)
+	var fetches atomic.Int32
+	c := New(taskServer(t, &status, &fetches).URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitForTask(ctx, "task-1", TaskStatusDone); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+}
@@ -25,6 +25,8 @@ type WSClient struct {
 	agentID   string
 	conn      *websocket.Conn
 	handlers  []EventHandler
+	watchers  map[uint64]EventHandler // removable handlers; see watch
+	nextWatch uint64
 	mu        sync.RWMutex
 	done      chan struct{}
 	reconnect bool
@@ -202,10 +204,30 @@ func (c *WSClient) readLoop(ctx context.Context) {
 	}
 }
 
+// watch registers handler until the returned func is called.
+func (c *WSClient) watch(handler EventHandler) (stop func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watchers == nil {
+		c.watchers = make(map[uint64]EventHandler)
+	}
+	c.nextWatch++
+	id := c.nextWatch
+	c.watchers[id] = handler
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.watchers, id)
+	}
+}
+
 func (c *WSClient) dispatchEvent(event DomainEvent) {
 	c.mu.RLock()
-	handlers := make([]EventHandler, len(c.handlers))
+	handlers := make([]EventHandler, len(c.handlers), len(c.handlers)+len(c.watchers))
 	copy(handlers, c.handlers)
+	for _, h := range c.watchers {
+		handlers = append(handlers, h)
+	}
 	c.mu.RUnlock()
 
 	for _, h := range handlers {
@@ -292,9 +314,12 @@ var EventTypes = struct {
 	StoryUpdated string
 
 	// Task events
-	TaskCreated   string
-	TaskAssigned  string
-	TaskCompleted string
+	TaskCreated       string
+	TaskAssigned      string
+	TaskCompleted     string
+	TaskStatusChanged string
+	TaskLeaseExpired  string
+	TaskUnblocked     string
 
 	// Insight events
 	InsightCreated string
@@ -309,23 +334,26 @@ var EventTypes = struct {
 	CUJValidated string
 	CUJUpdated   string
 }{
-	SpecCreated:    "spec.created",
-	SpecUpdated:    "spec.updated",
-	SpecArchived:   "spec.archived",
-	EpicCreated:    "epic.created",
-	EpicUpdated:    "epic.updated",
-	StoryCreated:   "story.created",
-	StoryUpdated:   "story.updated",
-	TaskCreated:    "task.created",
-	TaskAssigned:   "task.assigned",
-	TaskCompleted:  "task.completed",
-	InsightCreated: "insight.created",
-	InsightLinked:  "insight.linked",
-	SessionStarted: "session.started",
-	SessionStopped: "session.stopped",
-	CUJCreated:     "cuj.created",
-	CUJValidated:   "cuj.validated",
-	CUJUpdated:     "cuj.updated",
+	SpecCreated:       "spec.created",
+	SpecUpdated:       "spec.updated",
+	SpecArchived:      "spec.archived",
+	EpicCreated:       "epic.created",
+	EpicUpdated:       "epic.updated",
+	StoryCreated:      "story.created",
+	StoryUpdated:      "story.updated",
+	TaskCreated:       "task.created",
+	TaskAssigned:      "task.assigned",
+	TaskCompleted:     "task.completed",
+	TaskStatusChanged: "task.status_changed",
+	TaskLeaseExpired:  "task.lease_expired",
+	TaskUnblocked:     "task.unblocked",
+	InsightCreated:    "insight.created",
+	InsightLinked:     "insight.linked",
+	SessionStarted:    "session.started",
+	SessionStopped:    "session.stopped",
+	CUJCreated:        "cuj.created",
+	CUJValidated:      "cuj.validated",
+	CUJUpdated:        "cuj.updated",
 }
 
 // DomainEventData provides type-safe access to event data
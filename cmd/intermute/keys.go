@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+)
+
+func keysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Inspect and revoke API keys on a running server",
+	}
+	cmd.AddCommand(keysListCmd())
+	cmd.AddCommand(keysRevokeCmd())
+	return cmd
+}
+
+func keysListCmd() *cobra.Command {
+	var (
+		server  string
+		project string
+		asJSON  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API keys by project",
+		Long: `Fetches GET /api/admin/keys and prints each key's ID, project and bound
+agent. Keys are shown by ID (a fingerprint of the key), never in full;
+pass the ID to "keys revoke". Admin endpoints answer localhost only.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q := url.Values{}
+			if project != "" {
+				q.Set("project", project)
+			}
+			var out struct {
+				Keys []auth.KeyInfo `json:"keys"`
+			}
+			if err := adminRequest(http.MethodGet, server, "/api/admin/keys?"+q.Encode(), &out); err != nil {
+				return fmt.Errorf("list keys: %w", err)
+			}
+			if asJSON {
+				return printJSON(out.Keys)
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tPROJECT\tAGENT")
+			for _, k := range out.Keys {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", k.ID, k.Project, orDash(k.Agent))
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "server", defaultServerURL(), "Intermute base URL ($INTERMUTE_URL if set)")
+	cmd.Flags().StringVar(&project, "project", "", "Only this project's keys")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print machine-readable JSON")
+
+	return cmd
+}
+
+func keysRevokeCmd() *cobra.Command {
+	var (
+		server string
+		asJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API key by ID",
+		Long: `Calls DELETE /api/admin/keys/{id}: the key is removed from the server's
+keys file and rejected from the next request on, without a restart.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var info auth.KeyInfo
+			if err := adminRequest(http.MethodDelete, server, "/api/admin/keys/"+url.PathEscape(args[0]), &info); err != nil {
+				return fmt.Errorf("revoke key: %w", err)
+			}
+			if asJSON {
+				return printJSON(info)
+			}
+			fmt.Printf("Revoked key %s (project %s", info.ID, info.Project)
+			if info.Agent != "" {
+				fmt.Printf(", agent %s", info.Agent)
+			}
+			fmt.Println(")")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "server", defaultServerURL(), "Intermute base URL ($INTERMUTE_URL if set)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print machine-readable JSON")
+
+	return cmd
+}
+
+// adminRequest calls an admin endpoint and decodes its JSON response into
+// out. Non-2xx responses become errors carrying the server's message.
+func adminRequest(method, server, path string, out any) error {
+	req, err := http.NewRequest(method, strings.TrimRight(server, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -3,13 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -18,13 +22,19 @@ import (
 
 	"github.com/mistakeknot/intermute/internal/auth"
 	"github.com/mistakeknot/intermute/internal/cli"
+	"github.com/mistakeknot/intermute/internal/core"
+	grpcapi "github.com/mistakeknot/intermute/internal/grpc"
 	httpapi "github.com/mistakeknot/intermute/internal/http"
 	"github.com/mistakeknot/intermute/internal/livetransport"
 	"github.com/mistakeknot/intermute/internal/server"
+	"github.com/mistakeknot/intermute/internal/storage/postgres"
 	"github.com/mistakeknot/intermute/internal/storage/sqlite"
 	"github.com/mistakeknot/intermute/internal/ws"
 )
 
+// version is stamped at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
 func main() {
 	root := &cobra.Command{
 		Use:   "intermute",
@@ -33,13 +43,36 @@ func main() {
 
 	root.AddCommand(serveCmd())
 	root.AddCommand(initCmd())
+	root.AddCommand(quickstartCmd())
+	root.AddCommand(seedCmd())
 	root.AddCommand(inboxCmd())
+	root.AddCommand(projectCmd())
+	root.AddCommand(keysCmd())
+	root.AddCommand(threadCmd())
+	root.AddCommand(pingCmd())
+	root.AddCommand(statusCmd())
+	root.AddCommand(exportCmd())
+	root.AddCommand(importCmd())
+	root.AddCommand(verifyBackupCmd())
+	root.AddCommand(rebuildIndexesCmd())
 
 	if err := root.Execute(); err != nil {
+		var exit exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// envInt is $name as an integer, or def when it's unset or not a number.
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name))); err == nil {
+		return v
+	}
+	return def
+}
+
 func serveCmd() *cobra.Command {
 	var (
 		port            int
@@ -48,6 +81,22 @@ func serveCmd() *cobra.Command {
 		socketPath      string
 		coordDualWrite  bool
 		intercoreDBPath string
+		storyThreads    bool
+		dbWarnMB        int64
+		slowQueryMS     int
+		dbCriticalMB    int64
+		archiveDir      string
+		maxMessageKB    int
+		maxBlobMB       int64
+		compressMin     int
+		rateLimit       int
+		rateBurst       int
+		dbDriver        string
+		releaseStale    bool
+		devClock        bool
+		grpcPort        int
+		requireProjects bool
+		retentionEvery  time.Duration
 	)
 
 	cmd := &cobra.Command{
@@ -58,10 +107,20 @@ func serveCmd() *cobra.Command {
   - Domain APIs (specs, epics, stories, tasks, insights, sessions)
   - WebSocket support for real-time updates`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			store, err := sqlite.New(dbPath)
+			postgresDB, err := checkDBDriver(dbDriver)
+			if err != nil {
+				return err
+			}
+			var store *sqlite.Store
+			if postgresDB {
+				store, err = postgres.Open(dbPath)
+			} else {
+				store, err = sqlite.New(dbPath)
+			}
 			if err != nil {
 				return fmt.Errorf("store init: %w", err)
 			}
+			store.SetSlowQueryThreshold(time.Duration(slowQueryMS) * time.Millisecond)
 
 			// Optional dual-write bridge to Intercore coordination_locks.
 			if coordDualWrite {
@@ -82,6 +141,13 @@ func serveCmd() *cobra.Command {
 				}
 			}
 
+			if archiveDir == "" {
+				archiveDir = "archives"
+				if !postgresDB {
+					archiveDir = filepath.Join(filepath.Dir(dbPath), "archives")
+				}
+			}
+
 			// Wrap store with circuit breaker + retry resilience
 			resilient := sqlite.NewResilient(store)
 
@@ -100,18 +166,93 @@ func serveCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("auth init: %w", err)
 			}
+			// Projects with keys are registered, so --require-projects
+			// doesn't lock their keys out of creating entities.
+			for _, k := range keyring.Keys() {
+				if k.Project == "" {
+					continue
+				}
+				if _, err := store.CreateProject(context.Background(), core.Project{Name: k.Project}); err != nil && !errors.Is(err, core.ErrAlreadyExists) {
+					log.Printf("warning: register project %q: %v", k.Project, err)
+				}
+			}
 
 			hub := ws.NewHub()
-
-			// Start reservation sweeper (60s interval, 5min heartbeat grace)
-			sweeper := sqlite.NewSweeper(store, hub, 60*time.Second, 5*time.Minute)
-			sweeper.Start(context.Background())
+			// Breaker trips and recoveries go to the hub's system topic.
+			resilient.SetSystemNotifier(hub)
+			// gRPC subscriptions receive the same events as WebSocket clients.
+			events := grpcapi.NewEventBus()
+			bus := httpapi.Broadcasters{hub, events}
+			// With a shared PostgreSQL database, events also reach the
+			// clients of the other servers using it, and theirs reach ours.
+			var notifier *postgres.Notifier
+			if postgresDB {
+				notifier, err = postgres.NewNotifier(dbPath)
+				if err != nil {
+					return fmt.Errorf("event fan-out: %w", err)
+				}
+				notifier.Start(context.Background())
+				bus = httpapi.Broadcasters{hub, events, notifier}
+			}
+			keys := cli.NewFileKeyProvisioner(keysPath, keyring)
 
 			svc := httpapi.NewDomainService(resilient).
-				WithBroadcaster(hub).
+				WithBroadcaster(bus).
 				WithLiveDelivery(livetransport.NewInjector(nil)).
-				WithPinger(store)
-			router := httpapi.NewDomainRouter(svc, hub.Handler(), auth.Middleware(keyring, store.AgentForToken))
+				WithPinger(store).
+				WithKeyProvisioner(keys).
+				WithKeyManager(keys).
+				WithVersion(version).
+				WithStoryThreads(storyThreads).
+				WithStorageLimits(httpapi.StorageLimits{WarnBytes: dbWarnMB << 20, CriticalBytes: dbCriticalMB << 20}).
+				WithArchiveDir(archiveDir).
+				WithGRPC(grpcPort > 0).
+				WithRequireProjects(requireProjects).
+				WithSystemNotifier(hub).
+				WithMessageLimits(httpapi.MessageLimits{MaxBody: maxMessageKB << 10, MaxBlobSize: maxBlobMB << 20}).
+				WithCompression(compressMin).
+				WithRequestRateLimit(rateLimit, rateBurst)
+
+			// Start reservation sweeper (60s interval, 5min heartbeat grace).
+			// Snoozed messages it re-delivers wake inbox long-polls too.
+			sweeper := sqlite.NewSweeper(store, append(bus, svc.InboxNotifier()), 60*time.Second, 5*time.Minute)
+			var listener *postgres.Listener
+			if postgresDB {
+				listener, err = postgres.NewListener(dbPath, httpapi.Broadcasters{hub, events, svc.InboxNotifier()})
+				if err != nil {
+					return fmt.Errorf("event fan-out: %w", err)
+				}
+				listener.Start(context.Background())
+			}
+			sweeper.SetReleaseStale(releaseStale)
+			sweeper.SetSystemNotifier(hub)
+			sweeper.Start(context.Background())
+			svc.WithMetricsSources(resilient, sweeper)
+			if devClock {
+				svc.WithDevClock(sweeper)
+				log.Printf("dev clock enabled: /api/admin/clock can move server time forward")
+			}
+
+			// Deliver scheduled reports as they come due (checked every minute)
+			reports := httpapi.NewReportScheduler(svc, time.Minute)
+			reports.Start(context.Background())
+
+			// Alert when the database crosses a size threshold (checked every 10 minutes)
+			var storageMonitor *httpapi.StorageMonitor
+			if dbWarnMB > 0 || dbCriticalMB > 0 {
+				storageMonitor = httpapi.NewStorageMonitor(svc, 10*time.Minute)
+				storageMonitor.Start(context.Background())
+			}
+
+			// Apply per-project retention policies
+			var retention *httpapi.RetentionPurger
+			if retentionEvery > 0 {
+				retention = httpapi.NewRetentionPurger(svc, retentionEvery)
+				retention.Start(context.Background())
+			}
+
+			authMW := auth.Middleware(keyring, store.AgentForToken)
+			router := httpapi.NewDomainRouter(svc, hub.Handler(), authMW)
 
 			addr := fmt.Sprintf("%s:%d", host, port)
 			srv, err := server.New(server.Config{Addr: addr, SocketPath: socketPath, Handler: router})
@@ -119,6 +260,22 @@ func serveCmd() *cobra.Command {
 				return fmt.Errorf("server init: %w", err)
 			}
 
+			var grpcSrv *grpcapi.Server
+			if grpcPort > 0 {
+				grpcAddr := fmt.Sprintf("%s:%d", host, grpcPort)
+				ln, err := net.Listen("tcp", grpcAddr)
+				if err != nil {
+					return fmt.Errorf("grpc listen: %w", err)
+				}
+				grpcSrv = grpcapi.New(router, authMW, events)
+				go func() {
+					if err := grpcSrv.Serve(ln); err != nil {
+						log.Printf("grpc server: %v", err)
+					}
+				}()
+				log.Printf("intermute gRPC server on %s", grpcAddr)
+			}
+
 			// Handle shutdown signals
 			quit := make(chan os.Signal, 1)
 			signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -127,13 +284,28 @@ func serveCmd() *cobra.Command {
 				<-quit
 				log.Println("shutting down...")
 
-				// 1. Stop sweeper
+				// 1. Stop sweeper, report scheduler, storage monitor, retention purger
+				// and event fan-out
 				sweeper.Stop()
 				log.Println("sweeper stopped")
+				reports.Stop()
+				log.Println("report scheduler stopped")
+				if storageMonitor != nil {
+					storageMonitor.Stop()
+				}
+				if retention != nil {
+					retention.Stop()
+				}
+				if listener != nil {
+					listener.Stop()
+				}
 
-				// 2. Drain in-flight HTTP requests
+				// 2. Drain in-flight HTTP and gRPC requests
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
+				if grpcSrv != nil {
+					_ = grpcSrv.Shutdown(ctx)
+				}
 				_ = srv.Shutdown(ctx)
 
 				// 3. Close coordination bridge (if enabled)
@@ -143,7 +315,10 @@ func serveCmd() *cobra.Command {
 					}
 				}
 
-				// 4. Checkpoint WAL and close database
+				// 4. Publish queued events, then checkpoint WAL and close database
+				if notifier != nil {
+					notifier.Stop()
+				}
 				if err := store.Close(); err != nil {
 					log.Printf("store close: %v", err)
 				}
@@ -163,14 +338,42 @@ func serveCmd() *cobra.Command {
 
 	cmd.Flags().IntVar(&port, "port", 7338, "HTTP server port")
 	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "HTTP server bind address")
-	cmd.Flags().StringVar(&dbPath, "db", "intermute.db", "SQLite database path")
+	cmd.Flags().StringVar(&dbDriver, "db-driver", "sqlite", "Storage backend: sqlite, or postgres to share one database between several servers")
+	cmd.Flags().StringVar(&dbPath, "db", "intermute.db", "SQLite database path, or PostgreSQL connection URL with --db-driver postgres")
+	cmd.Flags().IntVar(&grpcPort, "grpc-port", 0, "Also serve the gRPC API on this port (0 disables)")
 	cmd.Flags().StringVar(&socketPath, "socket", "", "Unix domain socket path (e.g. /var/run/intermute.sock)")
 	cmd.Flags().BoolVar(&coordDualWrite, "coordination-dual-write", false, "Mirror reservations to Intercore coordination_locks table")
+	cmd.Flags().BoolVar(&storyThreads, "story-threads", false, "Post task assignments, blocks and completions into their story's thread")
 	cmd.Flags().StringVar(&intercoreDBPath, "intercore-db", "", "Path to intercore.db (auto-discovered if empty)")
+	cmd.Flags().Int64Var(&dbWarnMB, "db-size-warn-mb", 0, "Alert when the database grows past this many MiB (0 disables)")
+	cmd.Flags().IntVar(&slowQueryMS, "slow-query-ms", 100, "Log SQL queries slower than this many milliseconds, with their route")
+	cmd.Flags().Int64Var(&dbCriticalMB, "db-size-critical-mb", 0, "Critical alert when the database grows past this many MiB (0 disables)")
+	cmd.Flags().BoolVar(&releaseStale, "release-stale-reservations", false, "Release reservations as soon as their agent misses heartbeats for 5 minutes, whatever their TTL")
+	cmd.Flags().BoolVar(&devClock, "dev-clock", false, "Expose /api/admin/clock so tests can fast-forward server time (never in production)")
+	cmd.Flags().StringVar(&archiveDir, "archive-dir", "", "Directory for exported project archives (default: archives/ next to the database)")
+	cmd.Flags().DurationVar(&retentionEvery, "retention-interval", time.Hour, "How often to apply per-project message and event retention policies (0 disables)")
+	cmd.Flags().IntVar(&maxMessageKB, "max-message-kb", httpapi.DefaultMaxMessageBody>>10, "Reject message bodies larger than this many KiB; send larger payloads as blobs")
+	cmd.Flags().Int64Var(&maxBlobMB, "max-blob-mb", httpapi.DefaultMaxBlobSize>>20, "Reject blob uploads larger than this many MiB")
+	cmd.Flags().IntVar(&compressMin, "compress-min-bytes", httpapi.DefaultCompressMinBytes, "Compress responses (zstd or gzip, per Accept-Encoding) of at least this many bytes; 0 disables")
+	cmd.Flags().IntVar(&rateLimit, "rate-limit", envInt("INTERMUTE_RATE_LIMIT", httpapi.DefaultRequestsPerMinute), "Requests per minute allowed per API key, or per remote IP for unauthenticated localhost callers; 0 disables (env INTERMUTE_RATE_LIMIT)")
+	cmd.Flags().IntVar(&rateBurst, "rate-limit-burst", envInt("INTERMUTE_RATE_LIMIT_BURST", httpapi.DefaultRequestBurst), "Requests a caller may make at once before --rate-limit applies (env INTERMUTE_RATE_LIMIT_BURST)")
+	cmd.Flags().BoolVar(&requireProjects, "require-projects", true, "Only create specs, tasks and other entities under projects registered via POST /api/projects")
 
 	return cmd
 }
 
+// checkDBDriver rejects storage backends this build doesn't have and
+// reports whether driver names PostgreSQL.
+func checkDBDriver(driver string) (postgres bool, err error) {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return false, nil
+	case "postgres", "postgresql":
+		return true, nil
+	}
+	return false, fmt.Errorf("--db-driver %q: unknown storage backend (supported: sqlite, postgres)", driver)
+}
+
 func initCmd() *cobra.Command {
 	var (
 		project  string
@@ -2,6 +2,9 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -26,3 +29,48 @@ func TestInitCommandCreatesKey(t *testing.T) {
 		t.Fatalf("expected project section to be written")
 	}
 }
+
+func TestCheckDBDriver(t *testing.T) {
+	for driver, want := range map[string]bool{"sqlite": false, "sqlite3": false, "postgres": true, "postgresql": true} {
+		if pg, err := checkDBDriver(driver); err != nil || pg != want {
+			t.Fatalf("%s: postgres = %v, %v; want %v", driver, pg, err, want)
+		}
+	}
+	for _, driver := range []string{"mysql", ""} {
+		if _, err := checkDBDriver(driver); err == nil {
+			t.Fatalf("%q: expected an error", driver)
+		}
+	}
+}
+
+func TestPingExitCodes(t *testing.T) {
+	ready := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/readyz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(`{"status":"x"}`))
+	}))
+
+	ping := func() error {
+		cmd := pingCmd()
+		cmd.SetArgs([]string{"--server", srv.URL, "-q"})
+		return cmd.Execute()
+	}
+	if err := ping(); err != nil {
+		t.Fatalf("ready server: %v", err)
+	}
+	ready = false
+	var exit exitError
+	if err := ping(); !errors.As(err, &exit) || exit.code != exitNotReady {
+		t.Fatalf("not-ready server: got %v", err)
+	}
+	srv.Close()
+	if err := ping(); !errors.As(err, &exit) || exit.code != exitUnreachable {
+		t.Fatalf("closed server: got %v", err)
+	}
+}
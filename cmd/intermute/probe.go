@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Health probes for container healthchecks and shell scripts. Both exit
+// 0 when the server is ready, 1 when it answers but isn't ready, and 2
+// when it can't be reached.
+
+const (
+	exitNotReady    = 1
+	exitUnreachable = 2
+)
+
+// exitError makes main exit with code instead of the default 1. The
+// command has already reported the failure.
+type exitError struct{ code int }
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+
+// defaultServerURL is $INTERMUTE_URL, or the local default port.
+func defaultServerURL() string {
+	if u := strings.TrimSpace(os.Getenv("INTERMUTE_URL")); u != "" {
+		return u
+	}
+	return "http://127.0.0.1:7338"
+}
+
+// readiness is the outcome of probing /readyz.
+type readiness struct {
+	Ready  bool   `json:"ready"`
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"` // transport error: server unreachable
+}
+
+func (r readiness) exitCode() int {
+	switch {
+	case r.Error != "":
+		return exitUnreachable
+	case !r.Ready:
+		return exitNotReady
+	}
+	return 0
+}
+
+func probeReady(client *http.Client, server string) readiness {
+	resp, err := client.Get(strings.TrimRight(server, "/") + "/readyz")
+	if err != nil {
+		return readiness{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	out := readiness{Ready: resp.StatusCode == http.StatusOK, Status: body.Status, Reason: body.Reason}
+	if !out.Ready && out.Reason == "" {
+		out.Reason = fmt.Sprintf("status %d", resp.StatusCode)
+	}
+	return out
+}
+
+func pingCmd() *cobra.Command {
+	var (
+		server  string
+		timeout time.Duration
+		quiet   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "Check that a server is ready (exit 0 ready, 1 not ready, 2 unreachable)",
+		Long: `Probes GET /readyz once: the database must answer and the storage circuit
+breaker must not be open. Suitable as a Docker HEALTHCHECK:
+
+  HEALTHCHECK CMD intermute ping --server http://127.0.0.1:7338 -q`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := probeReady(&http.Client{Timeout: timeout}, server)
+			if !quiet {
+				switch {
+				case r.Error != "":
+					fmt.Printf("unreachable: %s\n", r.Error)
+				case r.Ready:
+					fmt.Println("ready")
+				default:
+					fmt.Printf("not ready: %s\n", r.Reason)
+				}
+			}
+			if code := r.exitCode(); code != 0 {
+				return exitError{code}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "server", defaultServerURL(), "Intermute base URL ($INTERMUTE_URL if set)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 3*time.Second, "Give up after this long")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print nothing; only set the exit code")
+
+	return cmd
+}
+
+// serverStatus is what `intermute status --json` prints.
+type serverStatus struct {
+	Server       string          `json:"server"`
+	Readiness    readiness       `json:"readiness"`
+	Capabilities json.RawMessage `json:"capabilities,omitempty"`
+}
+
+func statusCmd() *cobra.Command {
+	var (
+		server  string
+		timeout time.Duration
+		asJSON  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show server readiness, version and features (exit codes as for ping)",
+		Long: `Probes GET /readyz and GET /api/capabilities and prints the server's
+readiness, version and enabled features. With --json, prints one JSON
+object ({server, readiness, capabilities}) for scripts. Exits 0 when ready,
+1 when not ready, 2 when unreachable.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := &http.Client{Timeout: timeout}
+			st := serverStatus{Server: server, Readiness: probeReady(client, server)}
+			if st.Readiness.Error == "" {
+				resp, err := client.Get(strings.TrimRight(server, "/") + "/api/capabilities")
+				if err == nil {
+					data, _ := io.ReadAll(resp.Body)
+					resp.Body.Close()
+					if resp.StatusCode == http.StatusOK && json.Valid(data) {
+						st.Capabilities = data
+					}
+				}
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(st); err != nil {
+					return err
+				}
+			} else {
+				printStatus(st)
+			}
+			if code := st.Readiness.exitCode(); code != 0 {
+				return exitError{code}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "server", defaultServerURL(), "Intermute base URL ($INTERMUTE_URL if set)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 3*time.Second, "Give up on each request after this long")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print machine-readable JSON")
+
+	return cmd
+}
+
+func printStatus(st serverStatus) {
+	fmt.Printf("server:  %s\n", st.Server)
+	switch {
+	case st.Readiness.Error != "":
+		fmt.Printf("status:  unreachable (%s)\n", st.Readiness.Error)
+		return
+	case st.Readiness.Ready:
+		fmt.Println("status:  ready")
+	default:
+		fmt.Printf("status:  not ready (%s)\n", st.Readiness.Reason)
+	}
+	var caps struct {
+		Version  string          `json:"version"`
+		Features map[string]bool `json:"features"`
+	}
+	if json.Unmarshal(st.Capabilities, &caps) != nil {
+		return
+	}
+	fmt.Printf("version: %s\n", caps.Version)
+	var on []string
+	for name, enabled := range caps.Features {
+		if enabled {
+			on = append(on, name)
+		}
+	}
+	sort.Strings(on)
+	fmt.Printf("features: %s\n", strings.Join(on, ", "))
+}
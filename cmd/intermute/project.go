@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	httpapi "github.com/mistakeknot/intermute/internal/http"
+)
+
+func projectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "project",
+		Aliases: []string{"projects"},
+		Short:   "Manage projects",
+	}
+	cmd.AddCommand(projectCreateCmd())
+	cmd.AddCommand(projectListCmd())
+	return cmd
+}
+
+func projectCreateCmd() *cobra.Command {
+	var (
+		baseURL       string
+		template      string
+		withDevKey    bool
+		specStaleDays int
+		flagValues    map[string]string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Bootstrap a new project on a running server",
+		Long: `Bootstraps a project in one step via POST /api/projects:
+  - with --with-dev-key, mints an API key for the project (server keys file)
+  - with --template basic (default), creates a starter spec and CUJ skeleton
+    and turns on the strict_transitions and story_auto_advance flags
+  - with --template empty, only registers the key
+  - --flag name=true|false sets project feature flags on top of the
+    template's; --spec-stale-days sets the spec review window
+
+Must be run against a server reachable over localhost when minting keys.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := map[string]bool{}
+			for name, v := range flagValues {
+				on, err := strconv.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("--flag %s: want true or false, got %q", name, v)
+				}
+				flags[name] = on
+			}
+			body, err := json.Marshal(map[string]any{
+				"name":            args[0],
+				"template":        template,
+				"with_dev_key":    withDevKey,
+				"spec_stale_days": specStaleDays,
+				"flags":           flags,
+			})
+			if err != nil {
+				return err
+			}
+			resp, err := http.Post(strings.TrimRight(baseURL, "/")+"/api/projects", "application/json", bytes.NewReader(body))
+			if err != nil {
+				return fmt.Errorf("create project: %w", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				msg, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("create project: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+			}
+
+			var out httpapi.CreateProjectResponse
+			if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+
+			fmt.Printf("Created project %q (template %s)\n", out.Project, out.Template)
+			if out.Spec != nil {
+				fmt.Printf("  spec: %s (%s)\n", displayID(out.Spec.ShortID, out.Spec.ID), out.Spec.Title)
+			}
+			for _, cuj := range out.CUJs {
+				fmt.Printf("  cuj:  %s (%s)\n", cuj.ID, cuj.Title)
+			}
+			for _, name := range slices.Sorted(maps.Keys(out.Flags)) {
+				fmt.Printf("  flag: %s=%t\n", name, out.Flags[name])
+			}
+			if out.Key != "" {
+				fmt.Printf("\nKey: %s\n", out.Key)
+				fmt.Printf("  export INTERMUTE_API_KEY=%s\n", out.Key)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&template, "template", httpapi.ProjectTemplateBasic, "Starter template (basic, empty)")
+	cmd.Flags().BoolVar(&withDevKey, "with-dev-key", false, "Mint an API key for the project")
+	cmd.Flags().IntVar(&specStaleDays, "spec-stale-days", 0, "Days a validated spec may go unreviewed (0 = server default)")
+	cmd.Flags().StringToStringVar(&flagValues, "flag", nil, "Project feature flag as name=true|false (repeatable)")
+	cmd.Flags().StringVar(&baseURL, "url", "http://127.0.0.1:7338", "Intermute base URL")
+
+	return cmd
+}
+
+func projectListCmd() *cobra.Command {
+	var (
+		server          string
+		includeArchived bool
+		asJSON          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects with their activity",
+		Long: `Fetches GET /api/admin/overview and prints each project's active and
+total agents, active sessions, open epics, in-progress and open tasks,
+active reservations, messages and last activity.
+Archived projects are shown only with --include-archived.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "/api/admin/overview"
+			if includeArchived {
+				path += "?include_archived=true"
+			}
+			var overview core.AdminOverview
+			if err := adminRequest(http.MethodGet, server, path, &overview); err != nil {
+				return fmt.Errorf("list projects: %w", err)
+			}
+			if asJSON {
+				return printJSON(overview.Projects)
+			}
+			// Agents are active/total; tasks are in progress/open.
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "PROJECT\tAGENTS\tSESSIONS\tOPEN EPICS\tTASKS\tRESERVATIONS\tMESSAGES\tLAST ACTIVITY")
+			for _, p := range overview.Projects {
+				name := p.Project
+				if p.Archived {
+					name += " (archived)"
+				}
+				last := "-"
+				if p.LastActivity != nil {
+					last = p.LastActivity.Local().Format(time.DateTime)
+				}
+				fmt.Fprintf(tw, "%s\t%d/%d\t%d\t%d\t%d/%d\t%d\t%d\t%s\n", name, p.ActiveAgents, p.Agents, p.ActiveSessions,
+					p.OpenEpics, p.InProgressTasks, p.OpenTasks, p.ActiveReservations, p.Messages, last)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "server", defaultServerURL(), "Intermute base URL ($INTERMUTE_URL if set)")
+	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, "Also list archived projects")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print machine-readable JSON")
+
+	return cmd
+}
+
+// displayID shows an entity's short ID alongside its UUID when it has one.
+func displayID(shortID, id string) string {
+	if shortID == "" {
+		return id
+	}
+	return shortID + " " + id
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mistakeknot/intermute/internal/cli"
+	httpapi "github.com/mistakeknot/intermute/internal/http"
+)
+
+func quickstartCmd() *cobra.Command {
+	var (
+		dir     string
+		project string
+		agent   string
+		port    int
+		baseURL string
+		noSeed  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "quickstart",
+		Short: "Scaffold keys, agent config and service files, and seed a demo project",
+		Long: `Goes one step further than "intermute init". In --dir it writes:
+  - intermute.keys.yaml with a key for --project
+  - agent.env, an example agent configuration using that key
+  - intermute.service (systemd) and com.mistakeknot.intermute.plist (launchd)
+  - docker-compose.yml, a sample compose entry
+Existing files other than the keys file are left alone.
+
+Unless --no-seed is given, it then registers --project on the server at
+--url with a starter spec, CUJ and task. If no server is running yet, start
+one and rerun "intermute project create".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, files, err := cli.WriteQuickstart(dir, cli.QuickstartOptions{
+				Project: project,
+				Agent:   agent,
+				Port:    port,
+				Binary:  quickstartBinary(),
+			})
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				if f.Skipped {
+					fmt.Printf("  skipped %s (exists)\n", f.Path)
+				} else {
+					fmt.Printf("  wrote   %s\n", f.Path)
+				}
+			}
+			fmt.Printf("\nKey for project %q: %s\n", project, key)
+
+			if noSeed {
+				return nil
+			}
+			if err := seedDemoProject(baseURL, project, agent); err != nil {
+				fmt.Printf("\nDemo project not seeded: %v\n", err)
+				fmt.Printf("Start a server (for example: INTERMUTE_KEYS_FILE=%s intermute serve), then run:\n",
+					filepath.Join(dir, "intermute.keys.yaml"))
+				fmt.Printf("  intermute project create %s\n", project)
+				return nil
+			}
+			fmt.Printf("\nSeeded project %q on %s\n", project, baseURL)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "intermute-quickstart", "Directory to write the files into")
+	cmd.Flags().StringVar(&project, "project", "demo", "Demo project name")
+	cmd.Flags().StringVar(&agent, "agent", "demo-agent", "Agent name for the example agent config")
+	cmd.Flags().IntVar(&port, "port", 7338, "Server port the generated files use")
+	cmd.Flags().StringVar(&baseURL, "url", "http://127.0.0.1:7338", "Intermute base URL to seed the demo project on")
+	cmd.Flags().BoolVar(&noSeed, "no-seed", false, "Only write files; don't register the demo project")
+
+	return cmd
+}
+
+// quickstartBinary is the path service units should run: this binary,
+// unless it is a throwaway "go run" build.
+func quickstartBinary() string {
+	exe, err := os.Executable()
+	if err != nil || strings.Contains(exe, "go-build") {
+		return "/usr/local/bin/intermute"
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		return resolved
+	}
+	return exe
+}
+
+// seedDemoProject bootstraps project with the basic template and adds a
+// first task for agent to pick up.
+func seedDemoProject(baseURL, project, agent string) error {
+	base := strings.TrimRight(baseURL, "/")
+	created, err := postJSON(base+"/api/projects", map[string]any{"name": project, "template": httpapi.ProjectTemplateBasic})
+	if err != nil {
+		return err
+	}
+	var out httpapi.CreateProjectResponse
+	if err := json.Unmarshal(created, &out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if out.Spec != nil {
+		fmt.Printf("  spec: %s (%s)\n", displayID(out.Spec.ShortID, out.Spec.ID), out.Spec.Title)
+	}
+
+	task, err := postJSON(base+"/api/tasks", map[string]any{
+		"project": project,
+		"title":   "Say hello to intermute",
+		"agent":   agent,
+		"status":  "pending",
+	})
+	if err != nil {
+		return err
+	}
+	var t struct {
+		ID      string `json:"id"`
+		ShortID string `json:"short_id"`
+	}
+	_ = json.Unmarshal(task, &t)
+	fmt.Printf("  task: %s assigned to %s\n", displayID(t.ShortID, t.ID), agent)
+	return nil
+}
+
+// postJSON POSTs body as JSON and returns the response body, failing on
+// anything but 2xx.
+func postJSON(url string, body any) ([]byte, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("POST %s: status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+func rebuildIndexesCmd() *cobra.Command {
+	var (
+		dbPath string
+		asJSON bool
+		force  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rebuild-indexes",
+		Short: "Regenerate inbox_index, message_recipients and thread_index from the events table",
+		Long: `Rebuilds the tables derived from the events log, in one transaction, after
+they've been damaged or lost (a botched manual migration, say). Every
+message.created event is delivered again and message.unsnoozed events move
+inbox rows to their wake cursor. Messages whose event retention has already
+trimmed are rebuilt from the messages table, at their inbox rows' cursors.
+Read, ack and snooze state is kept for recipient rows that still exist, and
+per-thread unread counts are recounted.
+
+A message with neither its event nor an inbox row left can't be placed in
+any inbox. The rebuild then refuses to run unless --force, which leaves such
+messages out. Back up the database first.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := sqlite.New(dbPath)
+			if err != nil {
+				return fmt.Errorf("store init: %w", err)
+			}
+			defer store.Close()
+
+			out, err := store.RebuildIndexes(context.Background(), force)
+			if errors.Is(err, sqlite.ErrUnindexedMessages) {
+				return fmt.Errorf("%d messages have no message.created event or inbox row and would drop out of inboxes; rerun with --force to rebuild without them", out.Unindexed)
+			}
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(out)
+			}
+			fmt.Printf("Rebuilt indexes in %s from %d events and %d messages without one\n\n", dbPath, out.Replayed, out.Recovered)
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "TABLE\tBEFORE\tAFTER")
+			tables := make([]string, 0, len(out.After))
+			for t := range out.After {
+				tables = append(tables, t)
+			}
+			slices.Sort(tables)
+			for _, table := range tables {
+				fmt.Fprintf(tw, "%s\t%d\t%d\n", table, out.Before[table], out.After[table])
+			}
+			tw.Flush()
+			if out.Unindexed > 0 {
+				fmt.Printf("\n%d messages had no message.created event or inbox row left and weren't indexed\n", out.Unindexed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "intermute.db", "SQLite database path")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print machine-readable JSON")
+	cmd.Flags().BoolVar(&force, "force", false, "Rebuild even if some messages can't be indexed and will drop out of inboxes")
+
+	return cmd
+}
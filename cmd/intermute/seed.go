@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mistakeknot/intermute/internal/cli"
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+func seedCmd() *cobra.Command {
+	var (
+		file   string
+		dbPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Load projects, agents, specs, epics, stories, tasks and messages from a fixtures file",
+		Long: `Writes a declarative YAML data set straight into the database, so demos
+and integration environments start from a known state:
+
+  projects:
+    - name: demo
+      agents: [{name: planner, capabilities: [go]}]
+      specs:
+        - title: Checkout
+          epics:
+            - title: Payments
+              stories:
+                - title: Pay by card
+                  tasks: [{title: Wire up the API, agent: planner}]
+      messages:
+        - {from: planner, to: [reviewer], thread_id: kickoff, body: Hello}
+
+Entities without an "id" get one derived from their project and titles, so
+IDs are the same on every run and reseeding skips what already exists.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fx, err := cli.LoadFixtures(file)
+			if err != nil {
+				return err
+			}
+			store, err := sqlite.New(dbPath)
+			if err != nil {
+				return fmt.Errorf("store init: %w", err)
+			}
+			defer store.Close()
+
+			report, err := cli.Seed(context.Background(), store, fx)
+			if err != nil {
+				return err
+			}
+			kinds := make([]string, 0, len(report.Created)+len(report.Existed))
+			for k := range report.Created {
+				kinds = append(kinds, k)
+			}
+			for k := range report.Existed {
+				if _, ok := report.Created[k]; !ok {
+					kinds = append(kinds, k)
+				}
+			}
+			sort.Strings(kinds)
+			fmt.Printf("Seeded %s from %s\n", dbPath, file)
+			for _, k := range kinds {
+				fmt.Printf("  %-9s %d created, %d existing\n", k, report.Created[k], report.Existed[k])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Fixtures YAML file (required)")
+	cmd.Flags().StringVar(&dbPath, "db", "intermute.db", "SQLite database path")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
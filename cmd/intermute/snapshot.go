@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func exportCmd() *cobra.Command {
+	var (
+		baseURL string
+		project string
+		out     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Dump a project to a snapshot file",
+		Long: `Writes a JSON snapshot of a project's specs, epics, stories, tasks, CUJs,
+insights, goals, their links and its messages, via
+GET /api/projects/{project}/export. Restore it with "intermute import".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(project) == "" {
+				return fmt.Errorf("--project is required")
+			}
+			resp, err := http.Get(strings.TrimRight(baseURL, "/") + "/api/projects/" + url.PathEscape(project) + "/export")
+			if err != nil {
+				return fmt.Errorf("export project: %w", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				msg, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("export project: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+			}
+
+			if out == "" || out == "-" {
+				_, err = io.Copy(os.Stdout, resp.Body)
+				return err
+			}
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, resp.Body); err != nil {
+				f.Close()
+				return fmt.Errorf("write snapshot: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Exported project %q to %s\n", project, out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&project, "project", "", "Project to export (required)")
+	cmd.Flags().StringVar(&out, "out", "", "Snapshot file to write (default: stdout)")
+	cmd.Flags().StringVar(&baseURL, "url", "http://127.0.0.1:7338", "Intermute base URL")
+
+	return cmd
+}
+
+func importCmd() *cobra.Command {
+	var (
+		baseURL string
+		project string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <snapshot.json>",
+		Short: "Restore a project from a snapshot file",
+		Long: `Restores a snapshot written by "intermute export" via
+POST /api/projects/{project}/import. The target project must not have any
+domain entities or messages yet; --project defaults to the snapshot's own.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			if project == "" {
+				var head struct {
+					Project string `json:"project"`
+				}
+				if err := json.Unmarshal(data, &head); err != nil {
+					return fmt.Errorf("read snapshot: %w", err)
+				}
+				project = head.Project
+			}
+			if strings.TrimSpace(project) == "" {
+				return fmt.Errorf("--project is required")
+			}
+
+			resp, err := http.Post(strings.TrimRight(baseURL, "/")+"/api/projects/"+url.PathEscape(project)+"/import",
+				"application/json", bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("import project: %w", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				msg, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("import project: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+			}
+
+			var result core.ProjectImport
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+			fmt.Printf("Imported project %q\n", result.Project)
+			tables := make([]string, 0, len(result.Rows))
+			for t, n := range result.Rows {
+				if n > 0 {
+					tables = append(tables, t)
+				}
+			}
+			sort.Strings(tables)
+			for _, t := range tables {
+				fmt.Printf("  %-24s %d\n", t, result.Rows[t])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&project, "project", "", "Project to import into (default: the snapshot's project)")
+	cmd.Flags().StringVar(&baseURL, "url", "http://127.0.0.1:7338", "Intermute base URL")
+
+	return cmd
+}
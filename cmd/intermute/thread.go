@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	httpapi "github.com/mistakeknot/intermute/internal/http"
+)
+
+func threadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "thread",
+		Short: "Work with message threads",
+	}
+	cmd.AddCommand(threadExportCmd())
+	return cmd
+}
+
+func threadExportCmd() *cobra.Command {
+	var (
+		baseURL string
+		project string
+		format  string
+		output  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export <thread-id>",
+		Short: "Export a thread as a readable transcript",
+		Long: `Fetches GET /api/threads/{id}/export and writes the transcript to stdout,
+or to --output. Each message is shown with its sender, recipients, subject
+and timestamp. BCC recipients are never included.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q := url.Values{}
+			q.Set("format", format)
+			if project != "" {
+				q.Set("project", project)
+			}
+			exportURL := strings.TrimRight(baseURL, "/") + "/api/threads/" + url.PathEscape(args[0]) + "/export?" + q.Encode()
+			resp, err := http.Get(exportURL)
+			if err != nil {
+				return fmt.Errorf("export thread: %w", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				msg, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("export thread: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+			}
+
+			out := io.Writer(os.Stdout)
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("create output: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if _, err := io.Copy(out, resp.Body); err != nil {
+				return fmt.Errorf("write transcript: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&project, "project", "", "Project name")
+	cmd.Flags().StringVar(&format, "format", httpapi.ThreadExportMarkdown, "Export format (markdown)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().StringVar(&baseURL, "url", "http://127.0.0.1:7338", "Intermute base URL")
+
+	return cmd
+}
@@ -0,0 +1,297 @@
+// Package anomaly flags pathological coordination patterns from the stream
+// of task, reservation and message activity seen by the HTTP layer.
+//
+// State is in-process and bounded: counters age out of their windows,
+// per-entity state idle for longer than IdleTTL is evicted, and only the
+// most recent anomalies are retained for the report endpoint.
+package anomaly
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Config holds detector thresholds. Zero values take the defaults.
+type Config struct {
+	// TaskFailures is how many times one agent may start and then drop the
+	// same task within TaskWindow before it is flagged.
+	TaskFailures int
+	TaskWindow   time.Duration
+	// ReservationAcquires is how many reservations of one path pattern
+	// within ReservationWindow count as thrash.
+	ReservationAcquires int
+	ReservationWindow   time.Duration
+	// ThreadMessages is how many messages a thread may accumulate with no
+	// task or story status change in its project.
+	ThreadMessages int
+	// Retain is how many anomalies Recent keeps.
+	Retain int
+	// IdleTTL is how long a task, thread or status may go unobserved
+	// before the detector forgets it.
+	IdleTTL time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.TaskFailures <= 0 {
+		c.TaskFailures = 3
+	}
+	if c.TaskWindow <= 0 {
+		c.TaskWindow = time.Hour
+	}
+	if c.ReservationAcquires <= 0 {
+		c.ReservationAcquires = 10
+	}
+	if c.ReservationWindow <= 0 {
+		c.ReservationWindow = 10 * time.Minute
+	}
+	if c.ThreadMessages <= 0 {
+		c.ThreadMessages = 200
+	}
+	if c.Retain <= 0 {
+		c.Retain = 100
+	}
+	if c.IdleTTL <= 0 {
+		c.IdleTTL = 24 * time.Hour
+	}
+	return c
+}
+
+// sweepEvery bounds how often an observation pays for a full eviction pass.
+const sweepEvery = time.Minute
+
+type taskState struct {
+	status   core.TaskStatus
+	agent    string
+	failures []time.Time
+	flagged  bool
+	seen     time.Time
+}
+
+type threadState struct {
+	messages int
+	flagged  bool
+	seen     time.Time
+}
+
+type statusState struct {
+	status string
+	seen   time.Time
+}
+
+// Detector is safe for concurrent use.
+type Detector struct {
+	mu           sync.Mutex
+	cfg          Config
+	tasks        map[string]*taskState   // project/taskID
+	reservations map[string][]time.Time  // project/pattern
+	threads      map[string]*threadState // project/threadID
+	statuses     map[string]*statusState // project/entityID
+	recent       []core.Anomaly
+	swept        time.Time
+	// now is injectable for tests; defaults to time.Now.
+	now func() time.Time
+}
+
+func NewDetector(cfg Config) *Detector {
+	return &Detector{
+		cfg:          cfg.withDefaults(),
+		tasks:        make(map[string]*taskState),
+		reservations: make(map[string][]time.Time),
+		threads:      make(map[string]*threadState),
+		statuses:     make(map[string]*statusState),
+		now:          time.Now,
+	}
+}
+
+// ObserveTask records a task's new status. A transition out of running back
+// to pending or blocked by the same agent counts as a failed attempt.
+func (d *Detector) ObserveTask(t core.Task) []core.Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now().UTC()
+	d.sweep(now)
+	key := t.Project + "/" + t.ID
+	st, ok := d.tasks[key]
+	if !ok {
+		st = &taskState{}
+		d.tasks[key] = st
+	}
+	prev, prevAgent := st.status, st.agent
+	st.status, st.agent, st.seen = t.Status, t.Agent, now
+
+	if ok && prev != t.Status {
+		d.resetThreads(t.Project)
+	}
+	if t.Status == core.TaskStatusDone {
+		delete(d.tasks, key)
+		return nil
+	}
+	failed := prev == core.TaskStatusRunning &&
+		(t.Status == core.TaskStatusPending || t.Status == core.TaskStatusBlocked) &&
+		(t.Agent == "" || t.Agent == prevAgent)
+	if !failed {
+		return nil
+	}
+	st.failures = append(pruneBefore(st.failures, now.Add(-d.cfg.TaskWindow)), now)
+	if st.flagged || len(st.failures) < d.cfg.TaskFailures {
+		return nil
+	}
+	st.flagged = true
+	return d.record(core.Anomaly{
+		Kind:    core.AnomalyTaskFlapping,
+		Project: t.Project,
+		Subject: t.ID,
+		Agent:   prevAgent,
+		Count:   len(st.failures),
+		Detail: fmt.Sprintf("agent %s started and dropped task %q %d times in %s",
+			prevAgent, t.Title, len(st.failures), d.cfg.TaskWindow),
+		DetectedAt: now,
+	})
+}
+
+// ObserveStatus records the status of a non-task entity (e.g. a story).
+// A change from the last seen status resets the project's chatty-thread
+// counters.
+func (d *Detector) ObserveStatus(project, entityID, status string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now().UTC()
+	d.sweep(now)
+	key := project + "/" + entityID
+	if prev, ok := d.statuses[key]; ok && prev.status != status {
+		d.resetThreads(project)
+	}
+	d.statuses[key] = &statusState{status: status, seen: now}
+}
+
+// ObserveReservation records a reservation of pattern in project.
+func (d *Detector) ObserveReservation(project, pattern, agent string) []core.Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now().UTC()
+	d.sweep(now)
+	key := project + "/" + pattern
+	times := append(pruneBefore(d.reservations[key], now.Add(-d.cfg.ReservationWindow)), now)
+	d.reservations[key] = times
+	if len(times) != d.cfg.ReservationAcquires {
+		return nil
+	}
+	return d.record(core.Anomaly{
+		Kind:       core.AnomalyReservationThrash,
+		Project:    project,
+		Subject:    pattern,
+		Agent:      agent,
+		Count:      len(times),
+		Detail:     fmt.Sprintf("%q reserved %d times in %s", pattern, len(times), d.cfg.ReservationWindow),
+		DetectedAt: now,
+	})
+}
+
+// ObserveMessage counts a message on threadID. Unthreaded messages are
+// ignored.
+func (d *Detector) ObserveMessage(project, threadID string) []core.Anomaly {
+	if threadID == "" {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now().UTC()
+	d.sweep(now)
+	key := project + "/" + threadID
+	st, ok := d.threads[key]
+	if !ok {
+		st = &threadState{}
+		d.threads[key] = st
+	}
+	st.messages++
+	st.seen = now
+	if st.flagged || st.messages < d.cfg.ThreadMessages {
+		return nil
+	}
+	st.flagged = true
+	return d.record(core.Anomaly{
+		Kind:       core.AnomalyChattyThread,
+		Project:    project,
+		Subject:    threadID,
+		Count:      st.messages,
+		Detail:     fmt.Sprintf("thread has %d messages with no task or story status change", st.messages),
+		DetectedAt: now,
+	})
+}
+
+// Recent returns retained anomalies, newest first, optionally filtered to
+// one project.
+func (d *Detector) Recent(project string) []core.Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := []core.Anomaly{}
+	for i := len(d.recent) - 1; i >= 0; i-- {
+		if project == "" || d.recent[i].Project == project {
+			out = append(out, d.recent[i])
+		}
+	}
+	return out
+}
+
+// record must be called with d.mu held.
+func (d *Detector) record(a core.Anomaly) []core.Anomaly {
+	d.recent = append(d.recent, a)
+	if len(d.recent) > d.cfg.Retain {
+		d.recent = d.recent[len(d.recent)-d.cfg.Retain:]
+	}
+	return []core.Anomaly{a}
+}
+
+// sweep evicts tasks, threads and statuses idle for longer than IdleTTL and
+// reservation patterns with no acquire left in their window. It runs at
+// most once per sweepEvery and must be called with d.mu held.
+func (d *Detector) sweep(now time.Time) {
+	if now.Sub(d.swept) < sweepEvery {
+		return
+	}
+	d.swept = now
+	idle := now.Add(-d.cfg.IdleTTL)
+	for key, st := range d.tasks {
+		if st.seen.Before(idle) {
+			delete(d.tasks, key)
+		}
+	}
+	for key, st := range d.threads {
+		if st.seen.Before(idle) {
+			delete(d.threads, key)
+		}
+	}
+	for key, st := range d.statuses {
+		if st.seen.Before(idle) {
+			delete(d.statuses, key)
+		}
+	}
+	cutoff := now.Add(-d.cfg.ReservationWindow)
+	for key, times := range d.reservations {
+		if len(pruneBefore(times, cutoff)) == 0 {
+			delete(d.reservations, key)
+		}
+	}
+}
+
+// resetThreads must be called with d.mu held.
+func (d *Detector) resetThreads(project string) {
+	prefix := project + "/"
+	for key := range d.threads {
+		if strings.HasPrefix(key, prefix) {
+			delete(d.threads, key)
+		}
+	}
+}
+
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
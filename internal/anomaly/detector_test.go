@@ -0,0 +1,114 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func newTestDetector(cfg Config) (*Detector, *time.Time) {
+	d := NewDetector(cfg)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestTaskFlapping(t *testing.T) {
+	d, _ := newTestDetector(Config{TaskFailures: 2})
+	task := core.Task{ID: "t1", Project: "p", Agent: "a", Title: "flaky"}
+
+	var found []core.Anomaly
+	for i := 0; i < 2; i++ {
+		task.Status = core.TaskStatusRunning
+		d.ObserveTask(task)
+		task.Status = core.TaskStatusBlocked
+		found = append(found, d.ObserveTask(task)...)
+	}
+	if len(found) != 1 || found[0].Kind != core.AnomalyTaskFlapping || found[0].Agent != "a" {
+		t.Fatalf("expected one task_flapping anomaly for agent a, got %+v", found)
+	}
+
+	// Already flagged: further failures don't re-alert.
+	task.Status = core.TaskStatusRunning
+	d.ObserveTask(task)
+	task.Status = core.TaskStatusPending
+	if again := d.ObserveTask(task); len(again) != 0 {
+		t.Fatalf("expected no repeat alert, got %+v", again)
+	}
+}
+
+func TestTaskFailuresAgeOut(t *testing.T) {
+	d, now := newTestDetector(Config{TaskFailures: 2, TaskWindow: time.Minute})
+	task := core.Task{ID: "t1", Project: "p", Agent: "a"}
+	for i := 0; i < 2; i++ {
+		task.Status = core.TaskStatusRunning
+		d.ObserveTask(task)
+		task.Status = core.TaskStatusBlocked
+		if found := d.ObserveTask(task); len(found) != 0 {
+			t.Fatalf("failures outside the window should not alert: %+v", found)
+		}
+		*now = now.Add(2 * time.Minute)
+	}
+}
+
+func TestReservationThrash(t *testing.T) {
+	d, _ := newTestDetector(Config{ReservationAcquires: 3})
+	var found []core.Anomaly
+	for i := 0; i < 4; i++ {
+		found = append(found, d.ObserveReservation("p", "src/*.go", "a")...)
+	}
+	if len(found) != 1 || found[0].Subject != "src/*.go" || found[0].Count != 3 {
+		t.Fatalf("expected one thrash anomaly at count 3, got %+v", found)
+	}
+}
+
+func TestChattyThreadResetsOnStatusChange(t *testing.T) {
+	d, _ := newTestDetector(Config{ThreadMessages: 3})
+	d.ObserveStatus("p", "story/s1", "todo")
+	d.ObserveMessage("p", "th")
+	d.ObserveMessage("p", "th")
+	d.ObserveStatus("p", "story/s1", "in_progress")
+	if found := d.ObserveMessage("p", "th"); len(found) != 0 {
+		t.Fatalf("status change should reset the thread counter, got %+v", found)
+	}
+	d.ObserveMessage("p", "th")
+	found := d.ObserveMessage("p", "th")
+	if len(found) != 1 || found[0].Kind != core.AnomalyChattyThread {
+		t.Fatalf("expected chatty_thread anomaly, got %+v", found)
+	}
+	if d.ObserveMessage("p", "") != nil {
+		t.Fatal("unthreaded messages should be ignored")
+	}
+
+	if got := d.Recent("p"); len(got) != 1 {
+		t.Fatalf("expected 1 recent anomaly for p, got %d", len(got))
+	}
+	if got := d.Recent("other"); len(got) != 0 {
+		t.Fatalf("expected no anomalies for other project, got %d", len(got))
+	}
+}
+
+func TestIdleStateIsEvicted(t *testing.T) {
+	d, now := newTestDetector(Config{IdleTTL: time.Hour, ReservationWindow: time.Minute})
+	d.ObserveTask(core.Task{ID: "t1", Project: "p", Status: core.TaskStatusRunning})
+	d.ObserveStatus("p", "story/s1", "todo")
+	d.ObserveMessage("p", "th")
+	d.ObserveReservation("p", "src/*.go", "a")
+
+	*now = now.Add(2 * time.Hour)
+	d.ObserveTask(core.Task{ID: "t2", Project: "p", Status: core.TaskStatusPending})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.tasks["p/t1"]; ok {
+		t.Fatal("idle task should be evicted")
+	}
+	if _, ok := d.tasks["p/t2"]; !ok {
+		t.Fatal("freshly observed task should be kept")
+	}
+	if len(d.statuses) != 0 || len(d.threads) != 0 || len(d.reservations) != 0 {
+		t.Fatalf("expected idle state evicted, got statuses=%d threads=%d reservations=%d",
+			len(d.statuses), len(d.threads), len(d.reservations))
+	}
+}
@@ -1,11 +1,15 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,11 +25,17 @@ type keysFile struct {
 
 type projectKeys struct {
 	Keys []string `yaml:"keys"`
+	// AgentKeys maps a key to the agent it is issued to. Such keys also
+	// authorize the project, and requests made with them act as that agent.
+	AgentKeys map[string]string `yaml:"agent_keys"`
 }
 
 type Keyring struct {
 	AllowLocalhostWithoutAuth bool
-	keyToProject              map[string]string
+
+	mu           sync.RWMutex
+	keyToProject map[string]string
+	keyToAgent   map[string]string
 }
 
 func ResolveKeysPath() string {
@@ -65,6 +75,7 @@ func LoadKeyring(path string) (*Keyring, error) {
 	ring := &Keyring{
 		AllowLocalhostWithoutAuth: true,
 		keyToProject:              make(map[string]string),
+		keyToAgent:                make(map[string]string),
 	}
 	if cfg.DefaultPolicy.AllowLocalhostWithoutAuth != nil {
 		ring.AllowLocalhostWithoutAuth = *cfg.DefaultPolicy.AllowLocalhostWithoutAuth
@@ -80,12 +91,17 @@ func LoadKeyring(path string) (*Keyring, error) {
 			}
 			ring.keyToProject[key] = project
 		}
+		for key, agent := range keys.AgentKeys {
+			if err := ring.AddAgentKey(key, project, agent); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return ring, nil
 }
 
 func defaultKeyring() *Keyring {
-	return &Keyring{AllowLocalhostWithoutAuth: true, keyToProject: make(map[string]string)}
+	return &Keyring{AllowLocalhostWithoutAuth: true, keyToProject: make(map[string]string), keyToAgent: make(map[string]string)}
 }
 
 func NewKeyring(allowLocalhost bool, keyToProject map[string]string) *Keyring {
@@ -93,13 +109,113 @@ func NewKeyring(allowLocalhost bool, keyToProject map[string]string) *Keyring {
 	for k, v := range keyToProject {
 		clone[k] = v
 	}
-	return &Keyring{AllowLocalhostWithoutAuth: allowLocalhost, keyToProject: clone}
+	return &Keyring{AllowLocalhostWithoutAuth: allowLocalhost, keyToProject: clone, keyToAgent: make(map[string]string)}
 }
 
 func (k *Keyring) ProjectForKey(key string) (string, bool) {
 	if k == nil {
 		return "", false
 	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
 	project, ok := k.keyToProject[key]
 	return project, ok
 }
+
+// AddKey registers a key for a project on a running keyring so keys
+// provisioned after startup are honored without a restart.
+func (k *Keyring) AddKey(key, project string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return fmt.Errorf("empty key")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if existing, ok := k.keyToProject[key]; ok && existing != project {
+		return fmt.Errorf("key reused across projects: %q", key)
+	}
+	k.keyToProject[key] = project
+	return nil
+}
+
+// AddAgentKey registers a key for a project that is bound to one agent:
+// requests made with it act as agentID.
+func (k *Keyring) AddAgentKey(key, project, agentID string) error {
+	key = strings.TrimSpace(key)
+	agentID = strings.TrimSpace(agentID)
+	if key == "" || agentID == "" {
+		return fmt.Errorf("agent key needs a key and an agent")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if existing, ok := k.keyToProject[key]; ok && existing != project {
+		return fmt.Errorf("key reused across projects: %q", key)
+	}
+	if k.keyToProject == nil {
+		k.keyToProject = make(map[string]string)
+	}
+	if k.keyToAgent == nil {
+		k.keyToAgent = make(map[string]string)
+	}
+	if existing, ok := k.keyToAgent[key]; ok && existing != agentID {
+		return fmt.Errorf("key reused across agents: %q", key)
+	}
+	k.keyToProject[key] = project
+	k.keyToAgent[key] = agentID
+	return nil
+}
+
+// AgentForKey returns the agent a key is bound to, if any.
+func (k *Keyring) AgentForKey(key string) (string, bool) {
+	if k == nil {
+		return "", false
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	agent, ok := k.keyToAgent[key]
+	return agent, ok
+}
+
+// KeyInfo describes a registered key without revealing it.
+type KeyInfo struct {
+	// ID is the key's fingerprint (see KeyID), stable across restarts.
+	ID      string `json:"id"`
+	Project string `json:"project"`
+	Agent   string `json:"agent,omitempty"`
+}
+
+// KeyID fingerprints a key: the first 12 hex digits of its SHA-256. It
+// names a key in listings and revocations without exposing it.
+func KeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Keys lists the registered keys by project, then ID.
+func (k *Keyring) Keys() []KeyInfo {
+	if k == nil {
+		return nil
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	out := make([]KeyInfo, 0, len(k.keyToProject))
+	for key, project := range k.keyToProject {
+		out = append(out, KeyInfo{ID: KeyID(key), Project: project, Agent: k.keyToAgent[key]})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Project != out[j].Project {
+			return out[i].Project < out[j].Project
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// RemoveKey unregisters a key so requests made with it are rejected from
+// now on.
+func (k *Keyring) RemoveKey(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.keyToProject, key)
+	delete(k.keyToAgent, key)
+}
@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
 )
 
 type Mode string
@@ -20,15 +22,26 @@ type Info struct {
 	Project   string
 	AgentID   string
 	Localhost bool
+	KeyID     string // fingerprint of the API key used (see KeyID), empty for localhost
 }
 
 type contextKey struct{}
 
+// FromContext returns the caller's auth info. Info.AgentID is the acting
+// agent: bound by an agent key, or self-declared via X-Agent-ID (verified
+// against X-Agent-Token when one is sent).
 func FromContext(ctx context.Context) (Info, bool) {
 	v, ok := ctx.Value(contextKey{}).(Info)
 	return v, ok
 }
 
+// withInfo stores info on ctx and records its agent as the actor for
+// stores (see core.WithActor).
+func withInfo(ctx context.Context, info Info) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, info)
+	return core.WithActor(ctx, info.AgentID)
+}
+
 // TokenLookup resolves a registration token to its bound agent ID.
 // Returns ("", error) if the token is not found.
 type TokenLookup func(ctx context.Context, token string) (agentID string, err error)
@@ -55,16 +68,30 @@ func Middleware(ring *Keyring, lookupToken ...TokenLookup) func(http.Handler) ht
 			}
 
 			if ring.AllowLocalhostWithoutAuth && isLocalRequest(r) {
-				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, Info{Mode: ModeLocalhost, AgentID: agentID, Localhost: true})))
+				next.ServeHTTP(w, r.WithContext(withInfo(r.Context(), Info{Mode: ModeLocalhost, AgentID: agentID, Localhost: true})))
+				return
+			}
+			key, ok := bearerKey(r)
+			if !ok {
+				writeUnauthorized(w)
 				return
 			}
-			project, ok := authorize(r, ring)
+			project, ok := ring.ProjectForKey(key)
 			if !ok {
 				writeUnauthorized(w)
 				return
 			}
-			info := Info{Mode: ModeAPIKey, Project: project, AgentID: agentID, Localhost: false}
-			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, info)))
+			// An agent-bound key fixes the caller's identity; a conflicting
+			// X-Agent-ID is an impersonation attempt.
+			if bound, ok := ring.AgentForKey(key); ok {
+				if agentID != "" && agentID != bound {
+					writeForbidden(w, "agent identity mismatch")
+					return
+				}
+				agentID = bound
+			}
+			info := Info{Mode: ModeAPIKey, Project: project, AgentID: agentID, Localhost: false, KeyID: KeyID(key)}
+			next.ServeHTTP(w, r.WithContext(withInfo(r.Context(), info)))
 		})
 	}
 }
@@ -93,10 +120,11 @@ func (e *identityError) Error() string { return e.msg }
 func writeForbidden(w http.ResponseWriter, msg string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusForbidden)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg, "code": "forbidden", "message": msg})
 }
 
-func authorize(r *http.Request, ring *Keyring) (string, bool) {
+// bearerKey extracts the API key from an "Authorization: Bearer" header.
+func bearerKey(r *http.Request) (string, bool) {
 	header := strings.TrimSpace(r.Header.Get("Authorization"))
 	if header == "" {
 		return "", false
@@ -106,16 +134,13 @@ func authorize(r *http.Request, ring *Keyring) (string, bool) {
 		return "", false
 	}
 	key := strings.TrimSpace(parts[1])
-	if key == "" {
-		return "", false
-	}
-	return ring.ProjectForKey(key)
+	return key, key != ""
 }
 
 func writeUnauthorized(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized", "code": "unauthorized", "message": "unauthorized"})
 }
 
 func isLocalRequest(r *http.Request) bool {
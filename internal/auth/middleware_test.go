@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
 )
 
 func TestLocalhostBypass(t *testing.T) {
@@ -207,3 +209,63 @@ func TestLoadKeyringCreatesDevKeyWhenMissing(t *testing.T) {
 		t.Fatalf("expected 1 dev key, got %d", len(ring.keyToProject))
 	}
 }
+
+func TestAgentBoundKeySetsIdentity(t *testing.T) {
+	ring := &Keyring{keyToProject: map[string]string{}}
+	if err := ring.AddAgentKey("agent-secret", "proj-a", "agent-A"); err != nil {
+		t.Fatalf("add agent key: %v", err)
+	}
+	mw := Middleware(ring)
+
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := FromContext(r.Context())
+		if !ok || info.Project != "proj-a" || info.AgentID != "agent-A" {
+			t.Fatalf("expected bound agent identity, got %+v", info)
+		}
+		if actor := core.ActorFromContext(r.Context()); actor != "agent-A" {
+			t.Fatalf("expected actor agent-A, got %q", actor)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/agents", nil)
+	req.RemoteAddr = "203.0.113.10:9999"
+	req.Header.Set("Authorization", "Bearer agent-secret")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	// Claiming a different agent with a bound key is rejected.
+	req = httptest.NewRequest(http.MethodGet, "/api/agents", nil)
+	req.RemoteAddr = "203.0.113.10:9999"
+	req.Header.Set("Authorization", "Bearer agent-secret")
+	req.Header.Set("X-Agent-ID", "agent-B")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for mismatched agent, got %d", rr.Code)
+	}
+}
+
+func TestLoadKeyringAgentKeys(t *testing.T) {
+	keysPath := filepath.Join(t.TempDir(), "intermute.keys.yaml")
+	data := "projects:\n  proj-a:\n    keys: [shared]\n    agent_keys:\n      bound: agent-A\n"
+	if err := os.WriteFile(keysPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	ring, err := LoadKeyring(keysPath)
+	if err != nil {
+		t.Fatalf("load keyring: %v", err)
+	}
+	if project, ok := ring.ProjectForKey("bound"); !ok || project != "proj-a" {
+		t.Fatalf("expected bound key to authorize proj-a, got %q", project)
+	}
+	if agent, ok := ring.AgentForKey("bound"); !ok || agent != "agent-A" {
+		t.Fatalf("expected agent-A, got %q", agent)
+	}
+	if _, ok := ring.AgentForKey("shared"); ok {
+		t.Fatalf("shared key should not be agent-bound")
+	}
+}
@@ -19,7 +19,8 @@ type keysFile struct {
 }
 
 type projectKeys struct {
-	Keys []string `yaml:"keys"`
+	Keys      []string          `yaml:"keys"`
+	AgentKeys map[string]string `yaml:"agent_keys,omitempty"`
 }
 
 func InitKeysFile(path, project string) (string, error) {
@@ -51,14 +52,21 @@ func InitKeysFile(path, project string) (string, error) {
 		cfg.DefaultPolicy.AllowLocalhostWithoutAuth = &val
 	}
 
+	if err := writeKeysFile(path, cfg); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func writeKeysFile(path string, cfg keysFile) error {
 	data, err := yaml.Marshal(&cfg)
 	if err != nil {
-		return "", fmt.Errorf("marshal keys file: %w", err)
+		return fmt.Errorf("marshal keys file: %w", err)
 	}
 	if err := os.WriteFile(path, data, 0600); err != nil {
-		return "", fmt.Errorf("write keys file: %w", err)
+		return fmt.Errorf("write keys file: %w", err)
 	}
-	return key, nil
+	return nil
 }
 
 func loadKeysFile(path string) (keysFile, error) {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/mistakeknot/intermute/internal/auth"
 	"gopkg.in/yaml.v3"
 )
 
@@ -40,3 +41,24 @@ func TestInitKeysFileCreatesProjectKey(t *testing.T) {
 		t.Fatalf("expected autarch key %q, got %+v", key, keys)
 	}
 }
+
+func TestFileKeyProvisionerRegistersOnKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	ring := auth.NewKeyring(true, nil)
+	p := NewFileKeyProvisioner(path, ring)
+
+	key, err := p.ProvisionKey("newproj")
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if project, ok := ring.ProjectForKey(key); !ok || project != "newproj" {
+		t.Fatalf("expected key registered for newproj, got %q ok=%v", project, ok)
+	}
+	loaded, err := auth.LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("load keyring: %v", err)
+	}
+	if project, ok := loaded.ProjectForKey(key); !ok || project != "newproj" {
+		t.Fatalf("expected key persisted for newproj, got %q ok=%v", project, ok)
+	}
+}
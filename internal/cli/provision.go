@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// FileKeyProvisioner appends project keys to a keys file and registers them
+// on the live keyring, so a running server accepts them immediately.
+type FileKeyProvisioner struct {
+	Path string
+	Ring *auth.Keyring
+
+	mu sync.Mutex // serializes read-modify-write of the keys file
+}
+
+func NewFileKeyProvisioner(path string, ring *auth.Keyring) *FileKeyProvisioner {
+	return &FileKeyProvisioner{Path: path, Ring: ring}
+}
+
+func (p *FileKeyProvisioner) ProvisionKey(project string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key, err := InitKeysFile(p.Path, project)
+	if err != nil {
+		return "", err
+	}
+	if p.Ring != nil {
+		if err := p.Ring.AddKey(key, project); err != nil {
+			return "", fmt.Errorf("register key: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// ListKeys describes the keys in the keys file, by project then ID.
+func (p *FileKeyProvisioner) ListKeys() ([]auth.KeyInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cfg, err := loadKeysFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	out := []auth.KeyInfo{}
+	for project, pk := range cfg.Projects {
+		for _, key := range pk.Keys {
+			out = append(out, auth.KeyInfo{ID: auth.KeyID(key), Project: project})
+		}
+		for key, agent := range pk.AgentKeys {
+			out = append(out, auth.KeyInfo{ID: auth.KeyID(key), Project: project, Agent: agent})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Project != out[j].Project {
+			return out[i].Project < out[j].Project
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// RevokeKey removes the key with the given ID (see auth.KeyID) from the
+// keys file and the live keyring, so it stops working immediately. An
+// unknown ID returns core.ErrNotFound.
+func (p *FileKeyProvisioner) RevokeKey(id string) (auth.KeyInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cfg, err := loadKeysFile(p.Path)
+	if err != nil {
+		return auth.KeyInfo{}, err
+	}
+	for project, pk := range cfg.Projects {
+		var revoked string
+		info := auth.KeyInfo{ID: id, Project: project}
+		for i, key := range pk.Keys {
+			if auth.KeyID(key) == id {
+				revoked = key
+				pk.Keys = append(pk.Keys[:i:i], pk.Keys[i+1:]...)
+				break
+			}
+		}
+		if revoked == "" {
+			for key, agent := range pk.AgentKeys {
+				if auth.KeyID(key) == id {
+					revoked, info.Agent = key, agent
+					delete(pk.AgentKeys, key)
+					break
+				}
+			}
+		}
+		if revoked == "" {
+			continue
+		}
+		cfg.Projects[project] = pk
+		if err := writeKeysFile(p.Path, cfg); err != nil {
+			return auth.KeyInfo{}, err
+		}
+		if p.Ring != nil {
+			p.Ring.RemoveKey(revoked)
+		}
+		return info, nil
+	}
+	return auth.KeyInfo{}, core.ErrNotFound
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestFileKeyProvisionerRevokeKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte("projects:\n  alpha:\n    agent_keys:\n      agent-key: alice\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ring, err := auth.LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("load keyring: %v", err)
+	}
+	p := NewFileKeyProvisioner(path, ring)
+	key, err := p.ProvisionKey("alpha")
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+
+	keys, err := p.ListKeys()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("keys = %+v, want the provisioned key and the agent key", keys)
+	}
+
+	info, err := p.RevokeKey(auth.KeyID(key))
+	if err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if info.Project != "alpha" {
+		t.Errorf("revoked = %+v", info)
+	}
+	if _, ok := ring.ProjectForKey(key); ok {
+		t.Error("revoked key still on the live keyring")
+	}
+	reloaded, err := auth.LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := reloaded.ProjectForKey(key); ok {
+		t.Error("revoked key still in the keys file")
+	}
+	if agent, _ := reloaded.AgentForKey("agent-key"); agent != "alice" {
+		t.Errorf("agent key lost on rewrite: agent = %q", agent)
+	}
+
+	if _, err := p.RevokeKey(auth.KeyID(key)); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("second revoke err = %v, want ErrNotFound", err)
+	}
+}
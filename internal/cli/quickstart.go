@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// QuickstartOptions fills in the files WriteQuickstart emits.
+type QuickstartOptions struct {
+	Project string // demo project the key is minted for
+	Agent   string // agent name used in the example agent config
+	Port    int
+	Binary  string // absolute path of the intermute binary, for service units
+}
+
+// QuickstartFile is one file WriteQuickstart considered. Skipped files
+// already existed and were left alone.
+type QuickstartFile struct {
+	Path    string
+	Skipped bool
+}
+
+// quickstartKeysFile is the keys file name inside the quickstart directory.
+const quickstartKeysFile = "intermute.keys.yaml"
+
+type quickstartTemplate struct {
+	name string
+	mode os.FileMode
+	body string
+}
+
+var quickstartTemplates = []quickstartTemplate{
+	{"agent.env", 0600, `# Example agent configuration: source this before starting an agent.
+#   set -a; . ./agent.env; set +a
+INTERMUTE_URL=http://127.0.0.1:{{.Port}}
+INTERMUTE_PROJECT={{.Project}}
+INTERMUTE_API_KEY={{.Key}}
+INTERMUTE_AGENT_NAME={{.Agent}}
+`},
+	{"intermute.service", 0644, `# systemd unit. Install with:
+#   sudo cp intermute.service /etc/systemd/system/
+#   sudo systemctl daemon-reload && sudo systemctl enable --now intermute
+[Unit]
+Description=intermute agent coordination server
+After=network.target
+
+[Service]
+Environment=INTERMUTE_KEYS_FILE={{.Dir}}/intermute.keys.yaml
+ExecStart={{.Binary}} serve --port {{.Port}} --db {{.Dir}}/intermute.db
+WorkingDirectory={{.Dir}}
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`},
+	{"com.mistakeknot.intermute.plist", 0644, `<?xml version="1.0" encoding="UTF-8"?>
+<!-- launchd agent. Install with:
+  cp com.mistakeknot.intermute.plist ~/Library/LaunchAgents/
+  launchctl load ~/Library/LaunchAgents/com.mistakeknot.intermute.plist -->
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>com.mistakeknot.intermute</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>{{.Binary}}</string>
+    <string>serve</string>
+    <string>--port</string>
+    <string>{{.Port}}</string>
+    <string>--db</string>
+    <string>{{.Dir}}/intermute.db</string>
+  </array>
+  <key>EnvironmentVariables</key>
+  <dict>
+    <key>INTERMUTE_KEYS_FILE</key>
+    <string>{{.Dir}}/intermute.keys.yaml</string>
+  </dict>
+  <key>WorkingDirectory</key>
+  <string>{{.Dir}}</string>
+  <key>RunAtLoad</key>
+  <true/>
+  <key>KeepAlive</key>
+  <true/>
+</dict>
+</plist>
+`},
+	{"docker-compose.yml", 0644, `# Sample docker-compose entry. Requests from outside the container don't
+# come from localhost, so agents need the key in agent.env.
+services:
+  intermute:
+    image: golang:1.24
+    command: go run github.com/mistakeknot/intermute/cmd/intermute@latest serve --host 0.0.0.0 --port {{.Port}} --db /data/intermute.db
+    environment:
+      INTERMUTE_KEYS_FILE: /data/intermute.keys.yaml
+    ports:
+      - "127.0.0.1:{{.Port}}:{{.Port}}"
+    volumes:
+      - ./:/data
+    restart: unless-stopped
+`},
+}
+
+// WriteQuickstart mints a key for opts.Project in dir's keys file and
+// writes an example agent config, a systemd unit, a launchd plist and a
+// docker-compose entry next to it, all pointing at that keys file and a
+// database in dir. Files that already exist are skipped, so rerunning it
+// never clobbers local edits. Returns the key and what was written.
+func WriteQuickstart(dir string, opts QuickstartOptions) (string, []QuickstartFile, error) {
+	if strings.TrimSpace(opts.Project) == "" {
+		return "", nil, errors.New("project required")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil {
+		return "", nil, fmt.Errorf("create quickstart dir: %w", err)
+	}
+
+	keysPath := filepath.Join(abs, quickstartKeysFile)
+	key, err := InitKeysFile(keysPath, opts.Project)
+	if err != nil {
+		return "", nil, err
+	}
+	files := []QuickstartFile{{Path: keysPath}}
+
+	data := struct {
+		QuickstartOptions
+		Key string
+		Dir string
+	}{opts, key, abs}
+	for _, tmpl := range quickstartTemplates {
+		path := filepath.Join(abs, tmpl.name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, QuickstartFile{Path: path, Skipped: true})
+			continue
+		}
+		var buf bytes.Buffer
+		if err := template.Must(template.New(tmpl.name).Parse(tmpl.body)).Execute(&buf, data); err != nil {
+			return "", nil, fmt.Errorf("render %s: %w", tmpl.name, err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), tmpl.mode); err != nil {
+			return "", nil, fmt.Errorf("write %s: %w", tmpl.name, err)
+		}
+		files = append(files, QuickstartFile{Path: path})
+	}
+	return key, files, nil
+}
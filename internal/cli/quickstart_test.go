@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteQuickstart(t *testing.T) {
+	dir := t.TempDir()
+	opts := QuickstartOptions{Project: "demo", Agent: "planner", Port: 7400, Binary: "/opt/bin/intermute"}
+	key, files, err := WriteQuickstart(dir, opts)
+	if err != nil {
+		t.Fatalf("quickstart: %v", err)
+	}
+	if key == "" || len(files) != 1+len(quickstartTemplates) {
+		t.Fatalf("unexpected result: key %q, %d files", key, len(files))
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "agent.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"INTERMUTE_API_KEY=" + key, "INTERMUTE_PROJECT=demo", "INTERMUTE_AGENT_NAME=planner", ":7400"} {
+		if !strings.Contains(string(env), want) {
+			t.Errorf("agent.env missing %q:\n%s", want, env)
+		}
+	}
+	unit, err := os.ReadFile(filepath.Join(dir, "intermute.service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(unit), "ExecStart=/opt/bin/intermute serve --port 7400 --db "+dir+"/intermute.db") {
+		t.Errorf("unexpected unit:\n%s", unit)
+	}
+
+	// A rerun mints another key but leaves edited files alone.
+	if err := os.WriteFile(filepath.Join(dir, "agent.env"), []byte("edited"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, files, err = WriteQuickstart(dir, opts)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	for _, f := range files[1:] {
+		if !f.Skipped {
+			t.Errorf("expected %s to be skipped", f.Path)
+		}
+	}
+	if env, _ := os.ReadFile(filepath.Join(dir, "agent.env")); string(env) != "edited" {
+		t.Errorf("agent.env overwritten: %q", env)
+	}
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Fixtures is a declarative data set for `intermute seed`. Specs nest their
+// epics, epics their stories and stories their tasks, so fixtures never
+// spell out parent IDs.
+//
+// Every entity may give its own ID. Those that don't get one derived from
+// the project and their titles (messages: their position), so seeding the
+// same file always produces the same IDs, and seeding it twice skips what
+// the first run created.
+type Fixtures struct {
+	Projects []ProjectFixture `yaml:"projects"`
+}
+
+type ProjectFixture struct {
+	Name        string           `yaml:"name"`
+	DisplayName string           `yaml:"display_name"`
+	Description string           `yaml:"description"`
+	Agents      []AgentFixture   `yaml:"agents"`
+	Specs       []SpecFixture    `yaml:"specs"`
+	Tasks       []TaskFixture    `yaml:"tasks"` // tasks outside any story
+	Messages    []MessageFixture `yaml:"messages"`
+}
+
+type AgentFixture struct {
+	ID           string   `yaml:"id"`
+	Name         string   `yaml:"name"`
+	Capabilities []string `yaml:"capabilities"`
+}
+
+type SpecFixture struct {
+	ID      string        `yaml:"id"`
+	Title   string        `yaml:"title"`
+	Vision  string        `yaml:"vision"`
+	Users   string        `yaml:"users"`
+	Problem string        `yaml:"problem"`
+	Status  string        `yaml:"status"`
+	Epics   []EpicFixture `yaml:"epics"`
+}
+
+type EpicFixture struct {
+	ID          string         `yaml:"id"`
+	Title       string         `yaml:"title"`
+	Description string         `yaml:"description"`
+	Status      string         `yaml:"status"`
+	Stories     []StoryFixture `yaml:"stories"`
+}
+
+type StoryFixture struct {
+	ID                 string        `yaml:"id"`
+	Title              string        `yaml:"title"`
+	AcceptanceCriteria []string      `yaml:"acceptance_criteria"`
+	Status             string        `yaml:"status"`
+	Priority           string        `yaml:"priority"`
+	Tasks              []TaskFixture `yaml:"tasks"`
+}
+
+type TaskFixture struct {
+	ID            string   `yaml:"id"`
+	Title         string   `yaml:"title"`
+	Agent         string   `yaml:"agent"`
+	Status        string   `yaml:"status"`
+	Priority      string   `yaml:"priority"`
+	Capabilities  []string `yaml:"capabilities"`
+	ExpectedPaths []string `yaml:"expected_paths"`
+}
+
+type MessageFixture struct {
+	ID          string   `yaml:"id"`
+	ThreadID    string   `yaml:"thread_id"`
+	From        string   `yaml:"from"`
+	To          []string `yaml:"to"`
+	CC          []string `yaml:"cc"`
+	Subject     string   `yaml:"subject"`
+	Topic       string   `yaml:"topic"`
+	Body        string   `yaml:"body"`
+	AckRequired bool     `yaml:"ack_required"`
+}
+
+// LoadFixtures reads a fixtures file. Unknown keys are errors, so a typo
+// doesn't silently drop data.
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("read fixtures: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var fx Fixtures
+	if err := dec.Decode(&fx); err != nil {
+		return Fixtures{}, fmt.Errorf("parse fixtures: %w", err)
+	}
+	for i, p := range fx.Projects {
+		if strings.TrimSpace(p.Name) == "" {
+			return Fixtures{}, fmt.Errorf("parse fixtures: project %d has no name", i+1)
+		}
+	}
+	return fx, nil
+}
+
+// SeedReport counts, per entity kind, what Seed created and what already
+// existed.
+type SeedReport struct {
+	Created map[string]int
+	Existed map[string]int
+}
+
+func (r SeedReport) add(kind string, created bool) {
+	if created {
+		r.Created[kind]++
+	} else {
+		r.Existed[kind]++
+	}
+}
+
+// seedID is id if set, else a stable UUID derived from the project, the
+// entity kind and key.
+func seedID(id, project, kind, key string) string {
+	if id != "" {
+		return id
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("intermute-seed:"+project+"/"+kind+"/"+key)).String()
+}
+
+// exists reports whether get finds the entity. Stores report a miss as
+// core.ErrNotFound or a wrapped sql.ErrNoRows.
+func exists[T any](get func(context.Context, string, string) (T, error), ctx context.Context, project, id string) (bool, error) {
+	_, err := get(ctx, project, id)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, core.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Seed writes fx into store, parents before children. Entities and
+// messages whose ID is already taken are left as they are; agents are
+// re-registered, which updates them in place.
+func Seed(ctx context.Context, store storage.DomainStore, fx Fixtures) (SeedReport, error) {
+	report := SeedReport{Created: map[string]int{}, Existed: map[string]int{}}
+	for _, p := range fx.Projects {
+		if err := seedProject(ctx, store, p, report); err != nil {
+			return report, fmt.Errorf("project %s: %w", p.Name, err)
+		}
+	}
+	return report, nil
+}
+
+func seedProject(ctx context.Context, store storage.DomainStore, p ProjectFixture, report SeedReport) error {
+	project := p.Name
+	_, err := store.CreateProject(ctx, core.Project{Name: project, DisplayName: p.DisplayName, Description: p.Description})
+	if err != nil && !errors.Is(err, core.ErrAlreadyExists) {
+		return fmt.Errorf("register: %w", err)
+	}
+	report.add("projects", err == nil)
+
+	for _, a := range p.Agents {
+		if a.Name == "" && a.ID == "" {
+			return errors.New("agent needs a name or id")
+		}
+		name := a.Name
+		if name == "" {
+			name = a.ID
+		}
+		if _, err := store.RegisterAgent(ctx, core.Agent{
+			ID:           seedID(a.ID, project, "agent", name),
+			Name:         name,
+			Project:      project,
+			Capabilities: a.Capabilities,
+		}); err != nil {
+			return fmt.Errorf("agent %s: %w", name, err)
+		}
+		report.add("agents", true)
+	}
+
+	for _, sf := range p.Specs {
+		spec := core.Spec{
+			ID:      seedID(sf.ID, project, "spec", sf.Title),
+			Project: project,
+			Title:   sf.Title,
+			Vision:  sf.Vision,
+			Users:   sf.Users,
+			Problem: sf.Problem,
+			Status:  core.SpecStatus(orDefault(sf.Status, string(core.SpecStatusDraft))),
+		}
+		found, err := exists(store.GetSpec, ctx, project, spec.ID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if _, err := store.CreateSpec(ctx, spec); err != nil {
+				return fmt.Errorf("spec %q: %w", sf.Title, err)
+			}
+		}
+		report.add("specs", !found)
+
+		for _, ef := range sf.Epics {
+			epic := core.Epic{
+				ID:          seedID(ef.ID, project, "epic", spec.ID+"/"+ef.Title),
+				Project:     project,
+				SpecID:      spec.ID,
+				Title:       ef.Title,
+				Description: ef.Description,
+				Status:      core.EpicStatus(orDefault(ef.Status, string(core.EpicStatusOpen))),
+			}
+			found, err := exists(store.GetEpic, ctx, project, epic.ID)
+			if err != nil {
+				return err
+			}
+			if !found {
+				if _, err := store.CreateEpic(ctx, epic); err != nil {
+					return fmt.Errorf("epic %q: %w", ef.Title, err)
+				}
+			}
+			report.add("epics", !found)
+
+			for _, stf := range ef.Stories {
+				story := core.Story{
+					ID:                 seedID(stf.ID, project, "story", epic.ID+"/"+stf.Title),
+					Project:            project,
+					EpicID:             epic.ID,
+					Title:              stf.Title,
+					AcceptanceCriteria: stf.AcceptanceCriteria,
+					Status:             core.StoryStatus(orDefault(stf.Status, string(core.StoryStatusTodo))),
+					Priority:           core.Priority(stf.Priority),
+				}
+				found, err := exists(store.GetStory, ctx, project, story.ID)
+				if err != nil {
+					return err
+				}
+				if !found {
+					if _, err := store.CreateStory(ctx, story); err != nil {
+						return fmt.Errorf("story %q: %w", stf.Title, err)
+					}
+				}
+				report.add("stories", !found)
+
+				for _, tf := range stf.Tasks {
+					if err := seedTask(ctx, store, project, story.ID, tf, report); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	for _, tf := range p.Tasks {
+		if err := seedTask(ctx, store, project, "", tf, report); err != nil {
+			return err
+		}
+	}
+
+	for i, mf := range p.Messages {
+		if mf.From == "" || len(mf.To) == 0 {
+			return fmt.Errorf("message %d needs from and to", i+1)
+		}
+		id := seedID(mf.ID, project, "message", strconv.Itoa(i))
+		found, err := exists(store.GetMessage, ctx, project, id)
+		if err != nil {
+			return err
+		}
+		if found {
+			report.add("messages", false)
+			continue
+		}
+		if _, err := store.AppendEvent(ctx, core.Event{
+			ID:      "seed-" + id,
+			Type:    core.EventMessageCreated,
+			Project: project,
+			Message: core.Message{
+				ID:          id,
+				ThreadID:    mf.ThreadID,
+				Project:     project,
+				From:        mf.From,
+				To:          mf.To,
+				CC:          mf.CC,
+				Subject:     mf.Subject,
+				Topic:       strings.ToLower(mf.Topic),
+				Body:        mf.Body,
+				AckRequired: mf.AckRequired,
+			},
+		}); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+		report.add("messages", true)
+	}
+	return nil
+}
+
+func seedTask(ctx context.Context, store storage.DomainStore, project, storyID string, tf TaskFixture, report SeedReport) error {
+	task := core.Task{
+		ID:            seedID(tf.ID, project, "task", storyID+"/"+tf.Title),
+		Project:       project,
+		StoryID:       storyID,
+		Title:         tf.Title,
+		Agent:         tf.Agent,
+		Status:        core.TaskStatus(orDefault(tf.Status, string(core.TaskStatusPending))),
+		Priority:      core.Priority(tf.Priority),
+		Capabilities:  tf.Capabilities,
+		ExpectedPaths: tf.ExpectedPaths,
+	}
+	found, err := exists(store.GetTask, ctx, project, task.ID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if _, err := store.CreateTask(ctx, task); err != nil {
+			return fmt.Errorf("task %q: %w", tf.Title, err)
+		}
+	}
+	report.add("tasks", !found)
+	return nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+const testFixtures = `
+projects:
+  - name: demo
+    agents:
+      - name: planner
+        capabilities: [go]
+    specs:
+      - id: spec-checkout
+        title: Checkout
+        epics:
+          - title: Payments
+            stories:
+              - title: Pay by card
+                tasks:
+                  - title: Wire up the API
+                    agent: planner
+    tasks:
+      - title: Triage backlog
+    messages:
+      - from: planner
+        to: [reviewer]
+        thread_id: kickoff
+        body: Hello
+`
+
+func TestSeedIsDeterministicAndIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	if err := os.WriteFile(path, []byte(testFixtures), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fx, err := LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	st := storagetest.NewSQLite(t)
+	ctx := context.Background()
+
+	report, err := Seed(ctx, st, fx)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	for kind, want := range map[string]int{"agents": 1, "specs": 1, "epics": 1, "stories": 1, "tasks": 2, "messages": 1} {
+		if report.Created[kind] != want {
+			t.Errorf("created %s = %d, want %d", kind, report.Created[kind], want)
+		}
+	}
+
+	if _, err := st.GetSpec(ctx, "demo", "spec-checkout"); err != nil {
+		t.Fatalf("explicit spec id: %v", err)
+	}
+	storyID := seedID("", "demo", "story", seedID("", "demo", "epic", "spec-checkout/Payments")+"/Pay by card")
+	taskID := seedID("", "demo", "task", storyID+"/Wire up the API")
+	task, err := st.GetTask(ctx, "demo", taskID)
+	if err != nil {
+		t.Fatalf("derived task id: %v", err)
+	}
+	if task.StoryID != storyID || task.Agent != "planner" || task.Status != "pending" {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	inbox, err := st.InboxSince(ctx, "demo", "reviewer", 0, 10)
+	if err != nil || len(inbox) != 1 || inbox[0].Body != "Hello" {
+		t.Fatalf("inbox = %+v, %v", inbox, err)
+	}
+
+	report, err = Seed(ctx, st, fx)
+	if err != nil {
+		t.Fatalf("reseed: %v", err)
+	}
+	if report.Created["tasks"] != 0 || report.Existed["tasks"] != 2 || report.Existed["messages"] != 1 {
+		t.Fatalf("reseed should skip existing entities: %+v", report)
+	}
+}
+
+func TestLoadFixturesRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	if err := os.WriteFile(path, []byte("projects:\n  - name: demo\n    spex: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixtures(path); err == nil {
+		t.Fatal("expected an error for an unknown key")
+	}
+}
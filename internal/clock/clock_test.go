@@ -0,0 +1,27 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdvanceAndReset(t *testing.T) {
+	t.Cleanup(Reset)
+
+	before := time.Now()
+	if got := Advance(time.Hour); got != time.Hour {
+		t.Fatalf("offset = %v, want 1h", got)
+	}
+	Advance(30 * time.Minute)
+	if Offset() != 90*time.Minute {
+		t.Fatalf("offset = %v, want 1h30m", Offset())
+	}
+	if d := Now().Sub(before); d < 90*time.Minute || d > 91*time.Minute {
+		t.Fatalf("Now is %v ahead, want ~1h30m", d)
+	}
+
+	Reset()
+	if Offset() != 0 || Since(before) > time.Minute {
+		t.Fatalf("expected reset to wall clock, offset %v", Offset())
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AckPolicy is how many of a message's recipients must acknowledge it before
+// its quorum is reached: "all", "any", or a count N for N of them. The zero
+// value means the message has no policy.
+type AckPolicy string
+
+const (
+	AckPolicyAll AckPolicy = "all"
+	AckPolicyAny AckPolicy = "any"
+)
+
+// ErrInvalidAckPolicy is returned for a policy that is not all, any or a
+// count between 1 and the number of recipients.
+var ErrInvalidAckPolicy = errors.New("invalid ack policy")
+
+// ParseAckPolicy parses s, case-insensitively, for a message with the given
+// number of recipients. An empty s is the zero policy.
+func ParseAckPolicy(s string, recipients int) (AckPolicy, error) {
+	p := AckPolicy(strings.ToLower(strings.TrimSpace(s)))
+	switch p {
+	case "", AckPolicyAll, AckPolicyAny:
+		return p, nil
+	}
+	n, err := strconv.Atoi(string(p))
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAckPolicy, s)
+	}
+	if n > recipients {
+		return "", fmt.Errorf("%w: %d acks needed from %d recipients", ErrInvalidAckPolicy, n, recipients)
+	}
+	return AckPolicy(strconv.Itoa(n)), nil
+}
+
+// Required returns how many acks satisfy p on a message with the given
+// number of recipients, or 0 for the zero policy.
+func (p AckPolicy) Required(recipients int) int {
+	switch p {
+	case "":
+		return 0
+	case AckPolicyAll:
+		return recipients
+	case AckPolicyAny:
+		return min(1, recipients)
+	}
+	n, _ := strconv.Atoi(string(p))
+	return min(n, recipients)
+}
+
+// AckProgress is how far a message with an ack policy is from its quorum.
+// Acked lists the recipients that have acknowledged it, earliest first;
+// ReachedAt is when the quorum was first met.
+type AckProgress struct {
+	MessageID  string     `json:"message_id"`
+	Policy     AckPolicy  `json:"policy"`
+	Required   int        `json:"required"`
+	Recipients int        `json:"recipients"`
+	Acked      []string   `json:"acked"`
+	ReachedAt  *time.Time `json:"reached_at,omitempty"`
+}
+
+// Reached reports whether enough recipients have acknowledged the message.
+func (p AckProgress) Reached() bool {
+	return p.Policy != "" && len(p.Acked) >= p.Required
+}
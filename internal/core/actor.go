@@ -0,0 +1,22 @@
+package core
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx recording agentID as the agent performing
+// the current operation. Stores read it back to attribute the changes they
+// make, so the actor does not have to be passed to every method.
+func WithActor(ctx context.Context, agentID string) context.Context {
+	if agentID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, actorKey{}, agentID)
+}
+
+// ActorFromContext returns the agent performing the current operation, or
+// "" when it is unknown (anonymous callers, background jobs).
+func ActorFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(actorKey{}).(string)
+	return v
+}
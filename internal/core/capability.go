@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Capability is a canonical agent capability in a project's registry.
+// Aliases are alternate spellings ("golang" for "go") that normalize to
+// Name when agents register.
+type Capability struct {
+	Project     string
+	Name        string
+	Description string
+	Aliases     []string
+	UpdatedAt   time.Time
+}
+
+// FoldCapability is the comparison form of a capability string: trimmed and
+// lowercased, so "Go" and " go" are the same capability.
+func FoldCapability(c string) string {
+	return strings.ToLower(strings.TrimSpace(c))
+}
+
+// CapabilityRegistry maps every folded name and alias in a project's
+// registry to its canonical name. An empty registry accepts anything.
+type CapabilityRegistry map[string]string
+
+// NewCapabilityRegistry indexes caps by name and alias.
+func NewCapabilityRegistry(caps []Capability) CapabilityRegistry {
+	reg := make(CapabilityRegistry, len(caps))
+	for _, c := range caps {
+		reg[FoldCapability(c.Name)] = c.Name
+		for _, alias := range c.Aliases {
+			reg[FoldCapability(alias)] = c.Name
+		}
+	}
+	return reg
+}
+
+// Canonical returns the canonical name for c, if the registry knows it.
+func (r CapabilityRegistry) Canonical(c string) (string, bool) {
+	name, ok := r[FoldCapability(c)]
+	return name, ok
+}
+
+// Normalize maps caps to canonical names, dropping blanks and duplicates,
+// and returns any the registry doesn't recognize. With an empty registry
+// caps are only trimmed and deduplicated.
+func (r CapabilityRegistry) Normalize(caps []string) (normalized, unknown []string) {
+	seen := make(map[string]bool, len(caps))
+	for _, c := range caps {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if len(r) > 0 {
+			name, ok := r.Canonical(c)
+			if !ok {
+				unknown = append(unknown, c)
+				continue
+			}
+			c = name
+		}
+		if !seen[c] {
+			seen[c] = true
+			normalized = append(normalized, c)
+		}
+	}
+	return normalized, unknown
+}
+
+// CapabilityExpr is a capability routing expression in disjunctive form:
+// an agent matches when it has every capability of at least one clause.
+// It is written with "&" for all-of and "|" for any-of, "&" binding
+// tighter, so "go & review | rust & review" matches Go or Rust reviewers.
+type CapabilityExpr [][]string
+
+// ErrInvalidCapabilityExpr is returned for an expression with an empty term.
+var ErrInvalidCapabilityExpr = errors.New("invalid capability expression")
+
+// ParseCapabilityExpr parses s. Every term must be non-empty.
+func ParseCapabilityExpr(s string) (CapabilityExpr, error) {
+	var expr CapabilityExpr
+	for _, clause := range strings.Split(s, "|") {
+		var all []string
+		for _, term := range strings.Split(clause, "&") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidCapabilityExpr, s)
+			}
+			all = append(all, term)
+		}
+		expr = append(expr, all)
+	}
+	return expr, nil
+}
+
+// Normalize maps every term to its canonical name and returns any the
+// registry doesn't recognize, as CapabilityRegistry.Normalize does.
+func (e CapabilityExpr) Normalize(reg CapabilityRegistry) (normalized CapabilityExpr, unknown []string) {
+	for _, clause := range e {
+		all, missing := reg.Normalize(clause)
+		unknown = append(unknown, missing...)
+		normalized = append(normalized, all)
+	}
+	return normalized, unknown
+}
+
+// Matches reports whether caps satisfy the expression. Capabilities are
+// compared folded, so "Go" satisfies "go".
+func (e CapabilityExpr) Matches(caps []string) bool {
+	have := make(map[string]bool, len(caps))
+	for _, c := range caps {
+		have[FoldCapability(c)] = true
+	}
+	for _, clause := range e {
+		if !slices.ContainsFunc(clause, func(c string) bool { return !have[FoldCapability(c)] }) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the expression in its canonical spacing.
+func (e CapabilityExpr) String() string {
+	clauses := make([]string, len(e))
+	for i, clause := range e {
+		clauses[i] = strings.Join(clause, " & ")
+	}
+	return strings.Join(clauses, " | ")
+}
@@ -1,8 +1,13 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // ErrConcurrentModification is returned when an optimistic locking conflict occurs
@@ -11,6 +16,27 @@ var ErrConcurrentModification = errors.New("concurrent modification: entity was
 // ErrNotFound is returned when a requested entity does not exist
 var ErrNotFound = errors.New("not found")
 
+// ErrAlreadyExists is returned when creating an entity whose key is taken
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrDuplicateTitle is returned when a write would give an entity the title
+// of another in its scope while its project enforces unique titles (see
+// FlagUniqueTitles).
+var ErrDuplicateTitle = errors.New("duplicate title")
+
+// ErrDuplicateInsight is returned when creating an insight whose content
+// matches an existing one while its project deduplicates insights (see
+// FlagDedupInsights).
+var ErrDuplicateInsight = errors.New("duplicate insight")
+
+// ErrInvalidRank is returned when a story is ranked next to a story that
+// is missing, outside its epic, or in the wrong order.
+var ErrInvalidRank = errors.New("invalid rank position")
+
+// ErrTakeoverPending is returned when a reservation already has a takeover
+// request waiting for its holder.
+var ErrTakeoverPending = errors.New("takeover already pending")
+
 // ErrInvalidSessionID is returned when a provided session_id is not a valid UUID.
 var ErrInvalidSessionID = errors.New("invalid session_id: must be a valid UUID")
 
@@ -92,9 +118,13 @@ const (
 	EventPeerWindowPoke EventType = "peer.window_poke"
 
 	// Spec events
-	EventSpecCreated  EventType = "spec.created"
-	EventSpecUpdated  EventType = "spec.updated"
-	EventSpecArchived EventType = "spec.archived"
+	EventSpecCreated   EventType = "spec.created"
+	EventSpecUpdated   EventType = "spec.updated"
+	EventSpecArchived  EventType = "spec.archived"
+	EventSpecPublished EventType = "spec.published"
+	// EventSpecStale is sent once when a validated spec goes unreviewed
+	// for longer than its project's staleness window, with the spec.
+	EventSpecStale EventType = "spec.stale"
 
 	// Epic events
 	EventEpicCreated EventType = "epic.created"
@@ -103,15 +133,34 @@ const (
 	// Story events
 	EventStoryCreated EventType = "story.created"
 	EventStoryUpdated EventType = "story.updated"
+	EventStoryAtRisk  EventType = "story.at_risk"
 
 	// Task events
 	EventTaskCreated   EventType = "task.created"
 	EventTaskAssigned  EventType = "task.assigned"
 	EventTaskCompleted EventType = "task.completed"
+	// EventTaskStatusChanged accompanies any PUT that changes a task's
+	// status, with {task, from, to}.
+	EventTaskStatusChanged EventType = "task.status_changed"
+	// EventTaskLeaseExpired is sent for each task a lapsed or released
+	// claim lease returns to pending.
+	EventTaskLeaseExpired EventType = "task.lease_expired"
+	// EventTaskUnblocked is sent when a blocked task's unblock condition
+	// clears and the server puts it back to work.
+	EventTaskUnblocked EventType = "task.unblocked"
+	// EventTaskCommented is sent for each comment added to a task, with
+	// the comment.
+	EventTaskCommented EventType = "task.commented"
 
 	// Insight events
 	EventInsightCreated EventType = "insight.created"
 	EventInsightLinked  EventType = "insight.linked"
+	EventInsightRouted  EventType = "insight.routed"
+	EventInsightUpdated EventType = "insight.updated"
+	// EventInsightMerged carries the surviving insight and the merged IDs.
+	EventInsightMerged EventType = "insight.merged"
+	// EventInsightStatusChanged carries the insight plus its from/to status.
+	EventInsightStatusChanged EventType = "insight.status_changed"
 
 	// Session events
 	EventSessionStarted EventType = "session.started"
@@ -119,6 +168,10 @@ const (
 
 	// Reservation events
 	EventReservationExpired EventType = "reservation.expired"
+	// Takeover events carry the ReservationTakeover as "takeover".
+	EventReservationTakeoverRequested EventType = "reservation.takeover_requested"
+	EventReservationTakeoverDeclined  EventType = "reservation.takeover_declined"
+	EventReservationTransferred       EventType = "reservation.transferred"
 )
 
 const (
@@ -140,6 +193,7 @@ const (
 // Spec represents a product specification (PRD)
 type Spec struct {
 	ID        string     `json:"id"`
+	ShortID   string     `json:"short_id,omitempty"`
 	Project   string     `json:"project"`
 	Title     string     `json:"title"`
 	Vision    string     `json:"vision,omitempty"`
@@ -149,6 +203,47 @@ type Spec struct {
 	Version   int64      `json:"version,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+	// LastReviewedAt is when the spec was last validated or marked
+	// reviewed; nil for specs validated before it was tracked.
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+	// Stale is computed on read, never stored: see Spec.IsStale.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// DefaultSpecStaleDays is the staleness window of projects that don't set
+// one.
+const DefaultSpecStaleDays = 90
+
+// SpecStaleWindow is how long a validated spec may go unreviewed before
+// it is stale: days, or DefaultSpecStaleDays if days is 0.
+func SpecStaleWindow(days int) time.Duration {
+	if days <= 0 {
+		days = DefaultSpecStaleDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ReviewedAt is when the spec was last reviewed, falling back to its last
+// update for specs validated before reviews were tracked.
+func (s Spec) ReviewedAt() time.Time {
+	if s.LastReviewedAt != nil {
+		return *s.LastReviewedAt
+	}
+	return s.UpdatedAt
+}
+
+// IsStale reports whether s is validated and hasn't been reviewed within
+// window of now. Specs in any other status are never stale.
+func (s Spec) IsStale(now time.Time, window time.Duration) bool {
+	return s.Status == SpecStatusValidated && now.Sub(s.ReviewedAt()) > window
+}
+
+// StaleSpec is a spec the sweeper found stale, with the spec.stale event
+// recorded for it.
+type StaleSpec struct {
+	Spec    Spec
+	EventID string
+	Cursor  uint64
 }
 
 // EpicStatus represents the status of an epic
@@ -163,6 +258,7 @@ const (
 // Epic represents a large feature or initiative
 type Epic struct {
 	ID          string     `json:"id"`
+	ShortID     string     `json:"short_id,omitempty"`
 	Project     string     `json:"project"`
 	SpecID      string     `json:"spec_id,omitempty"`
 	Title       string     `json:"title"`
@@ -173,6 +269,25 @@ type Epic struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+// Priority ranks stories and tasks. Tasks inherit their story's priority
+// on creation unless one is given explicitly.
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
+// ValidPriority reports whether p is a known priority.
+func ValidPriority(p Priority) bool {
+	switch p {
+	case PriorityHigh, PriorityMedium, PriorityLow:
+		return true
+	}
+	return false
+}
+
 // StoryStatus represents the status of a story
 type StoryStatus string
 
@@ -186,16 +301,32 @@ const (
 // Story represents a user story within an epic
 type Story struct {
 	ID                 string      `json:"id"`
+	ShortID            string      `json:"short_id,omitempty"`
 	Project            string      `json:"project"`
 	EpicID             string      `json:"epic_id"`
 	Title              string      `json:"title"`
 	AcceptanceCriteria []string    `json:"acceptance_criteria,omitempty"`
 	Status             StoryStatus `json:"status"`
+	Priority           Priority    `json:"priority,omitempty"`
+	Rank               string      `json:"rank,omitempty"` // order within the epic; compare as strings
 	Version            int64       `json:"version,omitempty"`
 	CreatedAt          time.Time   `json:"created_at"`
 	UpdatedAt          time.Time   `json:"updated_at"`
 }
 
+// StoryThreadID is the message thread associated with a story. When story
+// threads are enabled, task assignments, blocks and completions under the
+// story are posted there.
+func StoryThreadID(storyID string) string {
+	return EntityThreadID("story", storyID)
+}
+
+// EntityThreadID is the canonical message thread for an entity, where
+// messages addressed to the entity rather than a thread are posted.
+func EntityThreadID(entityType, entityID string) string {
+	return entityType + ":" + entityID
+}
+
 // TaskStatus represents the status of a task
 type TaskStatus string
 
@@ -206,34 +337,190 @@ const (
 	TaskStatusDone    TaskStatus = "done"
 )
 
-// Task represents an execution unit assigned to an agent
+// BlockedReason says why a blocked task is blocked.
+type BlockedReason string
+
+const (
+	BlockedReasonDependency  BlockedReason = "dependency"  // waiting on another task
+	BlockedReasonReservation BlockedReason = "reservation" // waiting on files another agent holds
+	BlockedReasonExternal    BlockedReason = "external"    // waiting on something outside intermute
+	BlockedReasonNeedsInput  BlockedReason = "needs_input" // waiting on a human decision
+	BlockedReasonOther       BlockedReason = "other"
+)
+
+// ValidBlockedReason reports whether r is a known blocked reason.
+func ValidBlockedReason(r BlockedReason) bool {
+	switch r {
+	case BlockedReasonDependency, BlockedReasonReservation, BlockedReasonExternal, BlockedReasonNeedsInput, BlockedReasonOther:
+		return true
+	}
+	return false
+}
+
+// UnblockCondition is what a blocked task waits for; exactly one field is
+// set. The server unblocks the task once TaskID is done (or deleted), once
+// no other agent holds a reservation overlapping PathPattern, or
+// when ExternalRef is reported resolved.
+type UnblockCondition struct {
+	TaskID      string `json:"task_id,omitempty"`
+	PathPattern string `json:"path_pattern,omitempty"`
+	ExternalRef string `json:"external_ref,omitempty"`
+}
+
+// Task represents an execution unit assigned to an agent. Capabilities are
+// what the assignee needs: claims only hand the task to agents with all of
+// them.
 type Task struct {
-	ID        string     `json:"id"`
-	Project   string     `json:"project"`
-	StoryID   string     `json:"story_id,omitempty"`
-	Title     string     `json:"title"`
-	Agent     string     `json:"agent,omitempty"`
-	SessionID string     `json:"session_id,omitempty"`
-	Status    TaskStatus `json:"status"`
-	Version   int64      `json:"version,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID            string     `json:"id"`
+	ShortID       string     `json:"short_id,omitempty"`
+	Project       string     `json:"project"`
+	StoryID       string     `json:"story_id,omitempty"`
+	Title         string     `json:"title"`
+	Agent         string     `json:"agent,omitempty"`
+	SessionID     string     `json:"session_id,omitempty"`
+	Status        TaskStatus `json:"status"`
+	Priority      Priority   `json:"priority,omitempty"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	Capabilities  []string   `json:"capabilities,omitempty"`
+	ExpectedPaths []string   `json:"expected_paths,omitempty"`
+	// BlockedReason, BlockedDetail (free text) and UnblockWhen describe a
+	// blocked task; they are cleared when it leaves blocked.
+	BlockedReason BlockedReason     `json:"blocked_reason,omitempty"`
+	BlockedDetail string            `json:"blocked_detail,omitempty"`
+	UnblockWhen   *UnblockCondition `json:"unblock_when,omitempty"`
+	Version       int64             `json:"version,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	// CommentCount is filled in by task listings only.
+	CommentCount int `json:"comment_count,omitempty"`
 }
 
-// Insight represents a research insight from Pollard
-type Insight struct {
+// TaskComment is a progress note an agent records against a task. Cursor
+// orders a task's comments and pages through them.
+type TaskComment struct {
 	ID        string    `json:"id"`
 	Project   string    `json:"project"`
-	SpecID    string    `json:"spec_id,omitempty"`
-	Source    string    `json:"source"`
-	Category  string    `json:"category"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body,omitempty"`
-	URL       string    `json:"url,omitempty"`
-	Score     float64   `json:"score"`
+	TaskID    string    `json:"task_id"`
+	Author    string    `json:"author,omitempty"`
+	Body      string    `json:"body"`
+	Cursor    uint64    `json:"cursor"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// TaskClaim asks for the oldest pending, unassigned tasks in Project that
+// Agent can do: tasks whose required capabilities are all in Capabilities
+// (compared with FoldCapability). Steal also takes pending tasks assigned
+// to other agents but not yet started. Limit caps how many are claimed.
+// With LeaseID set, the claimed tasks share a lease that expires at
+// LeaseExpiresAt (see TaskLease).
+type TaskClaim struct {
+	Project        string
+	Agent          string
+	Capabilities   []string
+	Steal          bool
+	Limit          int
+	LeaseID        string
+	LeaseExpiresAt time.Time
+}
+
+// TaskLease is the shared hold a batch claim has on its tasks. Until it
+// expires (renewals push it back), the tasks stay with Agent; after that,
+// the ones still running return to pending for another worker. TaskIDs
+// are the tasks still under the lease.
+type TaskLease struct {
+	ID        string    `json:"id"`
+	Project   string    `json:"project"`
+	Agent     string    `json:"agent"`
+	TaskIDs   []string  `json:"task_ids"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TaskPathOverlap is one pair of overlapping expected paths, Path from the
+// task and OtherPath from the other task.
+type TaskPathOverlap struct {
+	Path      string `json:"path"`
+	OtherPath string `json:"other_path"`
+}
+
+// TaskConflict is two pending or running tasks whose expected paths
+// overlap, so running them at the same time would likely collide.
+// Concurrent is set when both are already running.
+type TaskConflict struct {
+	TaskID      string            `json:"task_id"`
+	TaskTitle   string            `json:"task_title"`
+	TaskStatus  TaskStatus        `json:"task_status"`
+	OtherID     string            `json:"other_task_id"`
+	OtherTitle  string            `json:"other_task_title"`
+	OtherStatus TaskStatus        `json:"other_task_status"`
+	Concurrent  bool              `json:"concurrent"`
+	Overlaps    []TaskPathOverlap `json:"overlaps"`
+}
+
+// InsightStatus tracks an insight through triage
+type InsightStatus string
+
+const (
+	InsightStatusNew       InsightStatus = "new"
+	InsightStatusTriaged   InsightStatus = "triaged"
+	InsightStatusActioned  InsightStatus = "actioned"
+	InsightStatusDismissed InsightStatus = "dismissed"
+)
+
+// insightTransitions lists the statuses each status may move to. Actioned
+// is terminal; a dismissed insight can be reopened for triage.
+var insightTransitions = map[InsightStatus][]InsightStatus{
+	InsightStatusNew:       {InsightStatusTriaged, InsightStatusActioned, InsightStatusDismissed},
+	InsightStatusTriaged:   {InsightStatusActioned, InsightStatusDismissed},
+	InsightStatusDismissed: {InsightStatusTriaged},
+}
+
+// ValidInsightStatus returns true if s is a recognized insight status.
+func ValidInsightStatus(s InsightStatus) bool {
+	switch s {
+	case InsightStatusNew, InsightStatusTriaged, InsightStatusActioned, InsightStatusDismissed:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether an insight may move from s to next.
+// Staying in the same status is always allowed.
+func (s InsightStatus) CanTransitionTo(next InsightStatus) bool {
+	return s == next || slices.Contains(insightTransitions[s], next)
+}
+
+// Insight represents a research insight from Pollard
+type Insight struct {
+	ID          string        `json:"id"`
+	Project     string        `json:"project"`
+	SpecID      string        `json:"spec_id,omitempty"`
+	Source      string        `json:"source"`
+	Category    string        `json:"category"`
+	Title       string        `json:"title"`
+	Body        string        `json:"body,omitempty"`
+	URL         string        `json:"url,omitempty"`
+	Score       float64       `json:"score"`
+	Status      InsightStatus `json:"status"`
+	Version     int64         `json:"version,omitempty"`
+	ContentHash string        `json:"content_hash,omitempty"` // InsightContentHash(Source, Title), set by the store
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// InsightContentHash identifies an insight's content for deduplication.
+// Source and title are compared case-insensitively with punctuation and
+// runs of whitespace ignored, so "Flaky test: TestFoo" and "flaky test
+// testfoo" from the same source match.
+func InsightContentHash(source, title string) string {
+	norm := func(s string) string {
+		return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}), " ")
+	}
+	sum := sha256.Sum256([]byte(norm(source) + "\n" + norm(title)))
+	return hex.EncodeToString(sum[:])
+}
+
 // SessionStatus represents the status of an agent session
 type SessionStatus string
 
@@ -251,8 +538,37 @@ type Session struct {
 	Agent     string        `json:"agent"`
 	TaskID    string        `json:"task_id,omitempty"`
 	Status    SessionStatus `json:"status"`
+	Version   int64         `json:"version,omitempty"`
 	StartedAt time.Time     `json:"started_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
+	// HeartbeatAt is the session's last heartbeat. Once a session has sent
+	// one, the sweeper stops it when they lapse; sessions that never do are
+	// left alone.
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+}
+
+// StoppedSession is a session the sweeper stopped after its heartbeats
+// lapsed: idle, or error if it was bound to a task. Tasks lists the tasks
+// it released, and EventID and Cursor the session.stopped event recorded.
+type StoppedSession struct {
+	Session Session
+	Tasks   []string
+	EventID string
+	Cursor  uint64
+}
+
+// SessionRecord is one session's history as the session analytics report
+// sees it, whether or not the session still exists. EndedAt is set once
+// it has been stopped; Status is the last one known (empty if it was
+// stopped before stopped events carried it), and TasksDone counts its
+// finished tasks.
+type SessionRecord struct {
+	ID        string
+	Agent     string
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Status    SessionStatus
+	TasksDone int
 }
 
 // DomainEvent wraps a domain entity change for event sourcing
@@ -326,3 +642,629 @@ type CUJFeatureLink struct {
 	Project   string    `json:"project"`
 	LinkedAt  time.Time `json:"linked_at"`
 }
+
+// Goal events
+const (
+	EventGoalCreated  EventType = "goal.created"
+	EventGoalUpdated  EventType = "goal.updated"
+	EventGoalArchived EventType = "goal.archived"
+	EventGoalDeleted  EventType = "goal.deleted"
+	EventGoalLinked   EventType = "goal.linked"
+)
+
+// GoalStatus represents the status of a goal
+type GoalStatus string
+
+const (
+	GoalStatusActive    GoalStatus = "active"
+	GoalStatusAchieved  GoalStatus = "achieved"
+	GoalStatusAbandoned GoalStatus = "abandoned"
+)
+
+// Goal represents an objective (OKR) that specs and epics contribute to
+type Goal struct {
+	ID          string      `json:"id"`
+	Project     string      `json:"project"`
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Period      string      `json:"period,omitempty"` // e.g. "2026-Q3"
+	KeyResults  []KeyResult `json:"key_results,omitempty"`
+	Status      GoalStatus  `json:"status"`
+	Version     int64       `json:"version,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// KeyResult is a measurable outcome for a Goal
+type KeyResult struct {
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+	Current     float64 `json:"current"`
+	Unit        string  `json:"unit,omitempty"`
+}
+
+// Entity types a goal can be linked to
+const (
+	GoalLinkSpec = "spec"
+	GoalLinkEpic = "epic"
+)
+
+// ValidGoalLinkType returns true if s is an entity type that can be linked to a goal.
+func ValidGoalLinkType(s string) bool {
+	return s == GoalLinkSpec || s == GoalLinkEpic
+}
+
+// GoalLink represents a many-to-many link between goals and specs/epics
+type GoalLink struct {
+	GoalID     string    `json:"goal_id"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Project    string    `json:"project"`
+	LinkedAt   time.Time `json:"linked_at"`
+}
+
+// GoalRollup summarizes progress on a goal, derived from the statuses of
+// its linked specs and epics.
+type GoalRollup struct {
+	Goal          Goal            `json:"goal"`
+	Links         []GoalLinkState `json:"links"`
+	Total         int             `json:"total"`
+	Done          int             `json:"done"`
+	InProgress    int             `json:"in_progress"`
+	Progress      float64         `json:"progress"`                  // done / total, 0..1
+	KeyResultsPct float64         `json:"key_results_pct,omitempty"` // mean of current/target across key results
+}
+
+// GoalLinkState is a linked entity with its current status.
+type GoalLinkState struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	Missing    bool   `json:"missing,omitempty"` // linked entity no longer exists
+}
+
+// ProjectStats is one project's row in the admin overview. ActiveAgents
+// counts agents heartbeating within SessionStaleThreshold; OpenTasks is
+// every task not done, of which InProgressTasks are running.
+type ProjectStats struct {
+	Project            string     `json:"project"`
+	Agents             int        `json:"agents"`
+	ActiveAgents       int        `json:"active_agents"`
+	ActiveSessions     int        `json:"active_sessions"`
+	OpenEpics          int        `json:"open_epics"`
+	OpenTasks          int        `json:"open_tasks"`
+	InProgressTasks    int        `json:"in_progress_tasks"`
+	ActiveReservations int        `json:"active_reservations"`
+	Messages           int        `json:"messages"`
+	LastActivity       *time.Time `json:"last_activity,omitempty"`
+	Archived           bool       `json:"archived,omitempty"`
+}
+
+// ProjectArchive records an archived project. ArchivePath is set when the
+// project's rows were moved out to a cold archive file; Rows is how many.
+type ProjectArchive struct {
+	Project     string    `json:"project"`
+	ArchivedAt  time.Time `json:"archived_at"`
+	ArchivePath string    `json:"archive_path,omitempty"`
+	Rows        int64     `json:"rows"`
+}
+
+// RetentionPolicy bounds how much message and event history a project
+// keeps. Rows older than MaxAgeDays, or beyond the newest MaxRows of their
+// table, are purged; zero disables that bound.
+type RetentionPolicy struct {
+	Project    string    `json:"project"`
+	MaxAgeDays int       `json:"max_age_days,omitempty"`
+	MaxRows    int       `json:"max_rows,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RetentionRun reports what one purge removed from a project. InboxRows and
+// Threads count the inbox_index and thread_index rows left without a
+// message, which the purge compacts away.
+type RetentionRun struct {
+	Project         string    `json:"project"`
+	MessagesDeleted int64     `json:"messages_deleted"`
+	EventsDeleted   int64     `json:"events_deleted"`
+	InboxRows       int64     `json:"inbox_rows_compacted"`
+	Threads         int64     `json:"threads_compacted"`
+	RanAt           time.Time `json:"ran_at"`
+}
+
+// GCRun reports what a garbage collection pass removed across all
+// projects: recipient, inbox and thread index rows whose message or thread
+// no longer exists, and thread unread counts it corrected.
+type GCRun struct {
+	Recipients      int64     `json:"recipients"`
+	InboxRows       int64     `json:"inbox_rows"`
+	Threads         int64     `json:"threads"`
+	UnreadRecounted int64     `json:"unread_recounted"`
+	RanAt           time.Time `json:"ran_at"`
+}
+
+// Reclaimed is the number of rows the pass deleted.
+func (r GCRun) Reclaimed() int64 {
+	return r.Recipients + r.InboxRows + r.Threads
+}
+
+// IdempotentResponse is the stored response to a POST or PATCH that
+// carried an Idempotency-Key header. A retry with the same key and request gets it
+// replayed instead of running again; RequestHash tells the two apart.
+type IdempotentResponse struct {
+	Project     string
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	// Header holds the response headers to replay, such as Location and
+	// X-Event-Cursor. Responses stored before it existed carry only
+	// ContentType.
+	Header    map[string][]string
+	Body      []byte
+	CreatedAt time.Time
+}
+
+// IdempotencyKeyTTL is how long idempotency keys are remembered.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// Project is a registered project. Entities can only be created under a
+// registered project that isn't archived. Archived mirrors the project's
+// archive record; archive and reactivate it through the admin API.
+type Project struct {
+	Name        string     `json:"name"`
+	DisplayName string     `json:"display_name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	// SpecStaleDays is how many days a validated spec may go unreviewed
+	// before it is stale; 0 means DefaultSpecStaleDays.
+	SpecStaleDays int `json:"spec_stale_days,omitempty"`
+	// ShortIDSequences overrides how the project's short IDs are numbered,
+	// per entity type; types left out use the defaults.
+	ShortIDSequences ShortIDSequences `json:"short_id_sequences,omitempty"`
+}
+
+// ErrProjectNotEmpty is returned when deleting a project that still has
+// rows.
+var ErrProjectNotEmpty = errors.New("project not empty")
+
+// ProjectSnapshotVersion is the snapshot format this server writes and
+// accepts.
+const ProjectSnapshotVersion = 1
+
+// ErrInvalidSnapshot is returned when importing a snapshot this server
+// can't restore: a different format version, or an unknown table or column.
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// ProjectSnapshot is a portable dump of one project's specs, epics, stories,
+// tasks, CUJs, insights, goals, their links and its messages. Tables holds
+// each table's rows as column → value maps, so a snapshot round-trips every
+// column without the format tracking the schema.
+type ProjectSnapshot struct {
+	Version    int                         `json:"version"`
+	Project    string                      `json:"project"`
+	ExportedAt time.Time                   `json:"exported_at"`
+	Tables     map[string][]map[string]any `json:"tables"`
+}
+
+// ProjectImport reports a restored snapshot: the project it went into and
+// how many rows each table received.
+type ProjectImport struct {
+	Project string           `json:"project"`
+	Rows    map[string]int64 `json:"rows"`
+}
+
+// AdminOverview aggregates activity across every project on the server.
+type AdminOverview struct {
+	Projects    []ProjectStats `json:"projects"`
+	DBSizeBytes int64          `json:"db_size_bytes"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// TableSize is the on-disk footprint of one table, including its indexes.
+type TableSize struct {
+	Table string `json:"table"`
+	Bytes int64  `json:"bytes"`
+}
+
+// PruneSuggestion estimates what a retention policy would reclaim. The
+// estimate prorates the table's size by the share of rows the policy would
+// delete.
+type PruneSuggestion struct {
+	Policy         string `json:"policy"`
+	Description    string `json:"description"`
+	Table          string `json:"table"`
+	Rows           int64  `json:"rows"`
+	EstimatedBytes int64  `json:"estimated_bytes"`
+}
+
+// StorageReport breaks the database size down by table and ranks pruning
+// suggestions by estimated reclaimed space, largest first.
+type StorageReport struct {
+	DBSizeBytes int64             `json:"db_size_bytes"`
+	Tables      []TableSize       `json:"tables"`
+	Suggestions []PruneSuggestion `json:"suggestions"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// EventStorageThreshold is emitted when the database grows past a
+// configured size threshold.
+const EventStorageThreshold EventType = "storage.size_threshold"
+
+// System events go to operators on the hub's system topic rather than to
+// any project.
+const (
+	// EventSystemCircuitOpen is sent when a storage circuit breaker trips,
+	// with {breaker}.
+	EventSystemCircuitOpen EventType = "system.circuit_open"
+	// EventSystemCircuitClosed is sent when a tripped breaker's probe
+	// succeeds, with {breaker}.
+	EventSystemCircuitClosed EventType = "system.circuit_closed"
+	// EventSystemSweepFailed is sent when a step of a sweeper pass fails,
+	// with {step, error}.
+	EventSystemSweepFailed EventType = "system.sweep_failed"
+	// EventSystemStorageThreshold accompanies EventStorageThreshold, with
+	// the same data.
+	EventSystemStorageThreshold EventType = "system.storage_threshold"
+	// EventSystemRetentionFailed is sent when a scheduled retention purge
+	// fails, with {error}.
+	EventSystemRetentionFailed EventType = "system.retention_failed"
+)
+
+// SystemEvent is an operational event: something about the server itself
+// rather than any project's data.
+type SystemEvent struct {
+	Type EventType      `json:"type"`
+	At   time.Time      `json:"at"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// FeatureFlag toggles an optional behavior. A flag with an empty Project is
+// the server-wide default; a project's own flag overrides it. Unknown flags
+// are off.
+type FeatureFlag struct {
+	Project     string    `json:"project"`
+	Name        string    `json:"name"`
+	Enabled     bool      `json:"enabled"`
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Feature flags checked by the server.
+const (
+	// FlagStoryThreads mirrors task changes into story threads for a
+	// project (see StoryThreadID), in addition to the server-wide option.
+	FlagStoryThreads = "story_threads"
+
+	// FlagUniqueTitles rejects a spec, epic, story or task whose title
+	// duplicates another in the same scope (see TitleScope). It can also be
+	// set per entity type, as "unique_titles.task" and so on.
+	FlagUniqueTitles = "unique_titles"
+
+	// FlagStrictTransitions rejects status changes AllowedTransitions
+	// doesn't list. It can also be set per entity type, as
+	// "strict_transitions.task" and so on.
+	FlagStrictTransitions = "strict_transitions"
+
+	// FlagDedupInsights makes creating an insight whose source and title
+	// match an existing one (see InsightContentHash) return that insight
+	// instead of a new one.
+	FlagDedupInsights = "dedup_insights"
+
+	// FlagStoryAutoAdvance moves a story to review when its last open task
+	// is marked done, in the same transaction as the task update.
+	FlagStoryAutoAdvance = "story_auto_advance"
+)
+
+// LabeledCount is a count keyed by project and, optionally, a status.
+type LabeledCount struct {
+	Project string `json:"project"`
+	Status  string `json:"status,omitempty"`
+	Count   int    `json:"count"`
+}
+
+// DomainMetrics is a point-in-time snapshot of coordination health used by
+// the metrics exporter.
+type DomainMetrics struct {
+	TasksByStatus      []LabeledCount `json:"tasks_by_status"`
+	StaleAgents        []LabeledCount `json:"stale_agents"`
+	ActiveReservations []LabeledCount `json:"active_reservations"`
+	UnackedMessages    []LabeledCount `json:"unacked_messages"`
+}
+
+// EventCoordinationAnomaly is emitted when the anomaly detector flags a
+// pathological coordination pattern.
+const EventCoordinationAnomaly EventType = "coordination.anomaly"
+
+// AnomalyKind classifies a detected coordination anomaly.
+type AnomalyKind string
+
+const (
+	// AnomalyTaskFlapping: an agent repeatedly starts a task and drops it
+	// back to pending/blocked.
+	AnomalyTaskFlapping AnomalyKind = "task_flapping"
+	// AnomalyReservationThrash: one path is reserved over and over in a
+	// short window.
+	AnomalyReservationThrash AnomalyKind = "reservation_thrash"
+	// AnomalyChattyThread: a thread keeps growing while no task or story in
+	// the project changes status.
+	AnomalyChattyThread AnomalyKind = "chatty_thread"
+)
+
+// Anomaly is a single flagged coordination pattern.
+type Anomaly struct {
+	Kind       AnomalyKind `json:"kind"`
+	Project    string      `json:"project"`
+	Subject    string      `json:"subject"` // task ID, path pattern or thread ID
+	Agent      string      `json:"agent,omitempty"`
+	Count      int         `json:"count"`
+	Detail     string      `json:"detail"`
+	DetectedAt time.Time   `json:"detected_at"`
+}
+
+// PublishedSpec is an immutable snapshot of a validated spec and its CUJs.
+// Published versions are numbered per spec from 1 and never change after
+// creation, so they can be referenced by permalink.
+type PublishedSpec struct {
+	Project     string                `json:"project"`
+	SpecID      string                `json:"spec_id"`
+	Number      int                   `json:"number"`
+	Spec        Spec                  `json:"spec"`
+	CUJs        []CriticalUserJourney `json:"cujs"`
+	PublishedBy string                `json:"published_by,omitempty"`
+	PublishedAt time.Time             `json:"published_at"`
+}
+
+// InsightRule routes newly created insights. All non-empty conditions must
+// match (category and source compare case-insensitively; MinScore is
+// inclusive). Matching rules link the insight to SpecID and/or notify
+// NotifyAgent.
+type InsightRule struct {
+	ID          string    `json:"id"`
+	Project     string    `json:"project"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	MinScore    *float64  `json:"min_score,omitempty"`
+	SpecID      string    `json:"spec_id,omitempty"`
+	NotifyAgent string    `json:"notify_agent,omitempty"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Matches reports whether the rule's conditions hold for insight.
+func (r InsightRule) Matches(insight Insight) bool {
+	if r.Category != "" && !strings.EqualFold(r.Category, insight.Category) {
+		return false
+	}
+	if r.Source != "" && !strings.EqualFold(r.Source, insight.Source) {
+		return false
+	}
+	if r.MinScore != nil && insight.Score < *r.MinScore {
+		return false
+	}
+	return true
+}
+
+// HookActionType is what a lifecycle hook does when it fires.
+type HookActionType string
+
+const (
+	// HookActionCreateTask creates a pending task, under the story when the
+	// hook fired on a story or on a task with one.
+	HookActionCreateTask HookActionType = "create_task"
+	// HookActionNotify sends Agent a message from the system sender.
+	HookActionNotify HookActionType = "notify"
+)
+
+// HookAction is one step of a lifecycle hook. Title and Message are
+// templates: {{id}}, {{short_id}}, {{title}}, {{type}}, {{from}}, {{to}}
+// and {{project}} are replaced with the entity that moved.
+type HookAction struct {
+	Type    HookActionType `json:"type"`
+	Title   string         `json:"title,omitempty"`
+	Agent   string         `json:"agent,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+// HookEntityTypes are the entity types lifecycle hooks can watch.
+var HookEntityTypes = []string{"spec", "epic", "story", "task"}
+
+// LifecycleHook runs Actions, in order, whenever an entity of EntityType
+// moves to ToStatus from FromStatus (from any status when empty).
+type LifecycleHook struct {
+	ID         string       `json:"id"`
+	Project    string       `json:"project"`
+	Name       string       `json:"name"`
+	EntityType string       `json:"entity_type"`
+	FromStatus string       `json:"from_status,omitempty"`
+	ToStatus   string       `json:"to_status"`
+	Actions    []HookAction `json:"actions"`
+	Enabled    bool         `json:"enabled"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// Matches reports whether the hook fires for an entityType moving from
+// one status to another.
+func (h LifecycleHook) Matches(entityType, from, to string) bool {
+	return h.EntityType == entityType && h.ToStatus == to && (h.FromStatus == "" || h.FromStatus == from)
+}
+
+// HookActionResult is the outcome of one action of a hook run: the task
+// or message it created, or why it failed.
+type HookActionResult struct {
+	Type      HookActionType `json:"type"`
+	TaskID    string         `json:"task_id,omitempty"`
+	MessageID string         `json:"message_id,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// HookRun is the audit record of a lifecycle hook firing. OK is false if
+// any action failed.
+type HookRun struct {
+	ID         string             `json:"id"`
+	Project    string             `json:"project"`
+	HookID     string             `json:"hook_id"`
+	HookName   string             `json:"hook_name"`
+	EntityType string             `json:"entity_type"`
+	EntityID   string             `json:"entity_id"`
+	FromStatus string             `json:"from_status"`
+	ToStatus   string             `json:"to_status"`
+	Results    []HookActionResult `json:"results"`
+	OK         bool               `json:"ok"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// ReportKind selects what a scheduled report summarises.
+type ReportKind string
+
+const (
+	// ReportKindStandup lists task movements since the previous run.
+	ReportKindStandup ReportKind = "standup"
+	// ReportKindSpecProgress summarises story and task completion per spec.
+	ReportKindSpecProgress ReportKind = "spec_progress"
+)
+
+// ReportCadence is how often a scheduled report is delivered.
+type ReportCadence string
+
+const (
+	ReportCadenceDaily  ReportCadence = "daily"
+	ReportCadenceWeekly ReportCadence = "weekly"
+)
+
+// ReportSchedule delivers a server-rendered report as a message to
+// Recipients (agent IDs or "@group" names) at Hour UTC every day, or on
+// Weekday for weekly reports. NextRunAt is when it is next due.
+type ReportSchedule struct {
+	ID         string        `json:"id"`
+	Project    string        `json:"project"`
+	Name       string        `json:"name"`
+	Kind       ReportKind    `json:"kind"`
+	Cadence    ReportCadence `json:"cadence"`
+	Hour       int           `json:"hour"`
+	Weekday    time.Weekday  `json:"weekday"`
+	Recipients []string      `json:"recipients"`
+	Enabled    bool          `json:"enabled"`
+	LastRunAt  *time.Time    `json:"last_run_at,omitempty"`
+	NextRunAt  time.Time     `json:"next_run_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// NextRunAfter returns the first scheduled time strictly after t.
+func (r ReportSchedule) NextRunAfter(t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day(), r.Hour, 0, 0, 0, time.UTC)
+	if r.Cadence == ReportCadenceWeekly {
+		next = next.AddDate(0, 0, (int(r.Weekday)-int(next.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SearchKind is the type of entity a search result refers to.
+type SearchKind string
+
+const (
+	SearchKindSpec    SearchKind = "spec"
+	SearchKindStory   SearchKind = "story"
+	SearchKindInsight SearchKind = "insight"
+	SearchKindMessage SearchKind = "message"
+)
+
+// ValidSearchKind reports whether k is a searchable entity kind.
+func ValidSearchKind(k SearchKind) bool {
+	switch k {
+	case SearchKindSpec, SearchKindStory, SearchKindInsight, SearchKindMessage:
+		return true
+	}
+	return false
+}
+
+// SearchQuery is a full-text search. An empty Project searches every
+// project and empty Kinds every kind; Limit <= 0 means the default.
+type SearchQuery struct {
+	Project string
+	Query   string
+	Kinds   []SearchKind
+	Limit   int
+}
+
+// SearchResult is one matching entity. Snippet is the best-matching excerpt
+// with matched terms in [brackets]; higher Score is a better match.
+type SearchResult struct {
+	Kind    SearchKind `json:"kind"`
+	ID      string     `json:"id"`
+	Project string     `json:"project"`
+	Title   string     `json:"title"`
+	Snippet string     `json:"snippet"`
+	Score   float64    `json:"score"`
+}
+
+// TitleScope is where a title must be unique when a project enforces
+// unique titles: among specs in the project, epics of a spec, stories of an
+// epic, or tasks of a story (ParentID). ExcludeID skips the entity being
+// updated.
+type TitleScope struct {
+	EntityType string // "spec", "epic", "story" or "task"
+	Project    string
+	ParentID   string
+	Title      string
+	ExcludeID  string
+}
+
+// shortIDPrefixes are the entity types that get short IDs: per-project
+// sequential references like "TASK-348", issued alongside the UUID.
+var shortIDPrefixes = map[string]string{
+	"spec":  "SPEC",
+	"epic":  "EPIC",
+	"story": "STORY",
+	"task":  "TASK",
+}
+
+// ShortIDPrefix returns entityType's short-ID prefix, or "" if it has none.
+func ShortIDPrefix(entityType string) string {
+	return shortIDPrefixes[entityType]
+}
+
+// ParseShortID returns ref in canonical form ("task-7" becomes "TASK-7")
+// if it has a short ID's shape, PREFIX-N, or "" if it doesn't. Projects
+// can choose their own prefixes (ShortIDSequences), and ones issued under
+// a prefix since changed still resolve, so any valid prefix is accepted.
+func ParseShortID(ref string) string {
+	prefix, n, ok := strings.Cut(ref, "-")
+	prefix = strings.ToUpper(prefix)
+	if !ok || !validShortIDPrefix(prefix) || n == "" || n[0] == '0' {
+		return ""
+	}
+	for _, c := range n {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return prefix + "-" + n
+}
+
+// EntityRef is an entity found by ID alone (GET /api/lookup): its type and
+// enough summary fields to tell what it is without fetching it.
+type EntityRef struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	ShortID   string    `json:"short_id,omitempty"`
+	Project   string    `json:"project"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,6 +1,12 @@
 package core
 
-import "testing"
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestValidTransport(t *testing.T) {
 	cases := map[TransportMode]bool{
@@ -27,3 +33,164 @@ func TestTransportOrDefault(t *testing.T) {
 		t.Errorf("TransportOrDefault(%q) = %q, want %q", TransportLive, got, TransportLive)
 	}
 }
+
+func TestReportScheduleNextRunAfter(t *testing.T) {
+	// 2026-10-14 is a Wednesday.
+	now := time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		sched ReportSchedule
+		want  time.Time
+	}{
+		{"daily later today", ReportSchedule{Cadence: ReportCadenceDaily, Hour: 17}, time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC)},
+		{"daily tomorrow", ReportSchedule{Cadence: ReportCadenceDaily, Hour: 9}, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)},
+		{"weekly this week", ReportSchedule{Cadence: ReportCadenceWeekly, Weekday: time.Friday, Hour: 9}, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)},
+		{"weekly next week", ReportSchedule{Cadence: ReportCadenceWeekly, Weekday: time.Monday, Hour: 9}, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"weekly same day passed", ReportSchedule{Cadence: ReportCadenceWeekly, Weekday: time.Wednesday, Hour: 9}, time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		if got := tc.sched.NextRunAfter(now); !got.Equal(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCapabilityRegistryNormalize(t *testing.T) {
+	reg := NewCapabilityRegistry([]Capability{{Name: "go", Aliases: []string{"golang"}}})
+	got, unknown := reg.Normalize([]string{" Go ", "GOLANG", "", "rust"})
+	if len(got) != 1 || got[0] != "go" {
+		t.Fatalf("expected [go], got %v", got)
+	}
+	if len(unknown) != 1 || unknown[0] != "rust" {
+		t.Fatalf("expected [rust] unknown, got %v", unknown)
+	}
+
+	got, unknown = CapabilityRegistry(nil).Normalize([]string{"Go", "Go", " x "})
+	if len(got) != 2 || got[0] != "Go" || got[1] != "x" || len(unknown) != 0 {
+		t.Fatalf("empty registry should only trim and dedupe, got %v %v", got, unknown)
+	}
+}
+
+func TestCapabilityExpr(t *testing.T) {
+	expr, err := ParseCapabilityExpr("golang & review | rust&review")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	reg := NewCapabilityRegistry([]Capability{{Name: "go", Aliases: []string{"golang"}}, {Name: "rust"}, {Name: "review"}})
+	expr, unknown := expr.Normalize(reg)
+	if len(unknown) != 0 || expr.String() != "go & review | rust & review" {
+		t.Fatalf("normalized = %q, unknown %v", expr, unknown)
+	}
+	for caps, want := range map[string]bool{
+		"go,review":   true,
+		"Rust,Review": true,
+		"go":          false,
+		"review,js":   false,
+	} {
+		if got := expr.Matches(strings.Split(caps, ",")); got != want {
+			t.Errorf("Matches(%s) = %v, want %v", caps, got, want)
+		}
+	}
+	for _, bad := range []string{"", "go &", "| review"} {
+		if _, err := ParseCapabilityExpr(bad); !errors.Is(err, ErrInvalidCapabilityExpr) {
+			t.Errorf("ParseCapabilityExpr(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func TestInsightTransitions(t *testing.T) {
+	tests := []struct {
+		from, to InsightStatus
+		ok       bool
+	}{
+		{InsightStatusNew, InsightStatusTriaged, true},
+		{InsightStatusNew, InsightStatusDismissed, true},
+		{InsightStatusTriaged, InsightStatusActioned, true},
+		{InsightStatusDismissed, InsightStatusTriaged, true},
+		{InsightStatusActioned, InsightStatusActioned, true},
+		{InsightStatusActioned, InsightStatusTriaged, false},
+		{InsightStatusTriaged, InsightStatusNew, false},
+		{InsightStatusDismissed, InsightStatusActioned, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.ok {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.ok)
+		}
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	tests := []struct {
+		entity, from, to string
+		ok               bool
+	}{
+		{"task", "pending", "running", true},
+		{"task", "running", "done", true},
+		{"task", "blocked", "running", true},
+		{"task", "done", "done", true},
+		{"task", "done", "pending", false},
+		{"task", "blocked", "done", false},
+		{"task", "pending", "finished", false},
+		{"task", "", "done", true},
+		{"story", "todo", "done", false},
+		{"story", "review", "done", true},
+		{"spec", "archived", "draft", true},
+		{"spec", "validated", "draft", false},
+		{"goal", "achieved", "abandoned", false},
+		{"session", "idle", "error", true},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.entity, tt.from, tt.to); got != tt.ok {
+			t.Errorf("%s %s -> %s = %v, want %v", tt.entity, tt.from, tt.to, got, tt.ok)
+		}
+	}
+}
+
+func TestAckPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		required int
+	}{
+		{"", 0},
+		{"ALL", 3},
+		{"any", 1},
+		{" 2 ", 2},
+	} {
+		p, err := ParseAckPolicy(tc.in, 3)
+		if err != nil {
+			t.Fatalf("ParseAckPolicy(%q): %v", tc.in, err)
+		}
+		if got := p.Required(3); got != tc.required {
+			t.Errorf("ParseAckPolicy(%q).Required(3) = %d, want %d", tc.in, got, tc.required)
+		}
+	}
+	for _, bad := range []string{"0", "4", "-1", "most"} {
+		if _, err := ParseAckPolicy(bad, 3); !errors.Is(err, ErrInvalidAckPolicy) {
+			t.Errorf("ParseAckPolicy(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func TestParseTaskFilter(t *testing.T) {
+	var q TaskQuery
+	err := ParseTaskFilter(&q, "status:in(pending,blocked) status:running updated_after:2024-06-01 story:xyz priority:high")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(q.Statuses, []TaskStatus{TaskStatusPending, TaskStatusBlocked, TaskStatusRunning}) ||
+		!slices.Equal(q.StoryIDs, []string{"xyz"}) || !slices.Equal(q.Priorities, []Priority{PriorityHigh}) ||
+		!q.UpdatedAfter.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("parsed = %+v", q)
+	}
+	if err := ParseTaskSort(&q, "-priority, due_at"); err != nil || len(q.Sort) != 2 || !q.Sort[0].Desc || q.Sort[1].Field != TaskSortDueAt {
+		t.Fatalf("sort = %+v, %v", q.Sort, err)
+	}
+	for _, bad := range []string{"status:finished", "owner:me", "status", "due_before:tomorrow", "priority:in(urgent)"} {
+		if err := ParseTaskFilter(&TaskQuery{}, bad); !errors.Is(err, ErrInvalidTaskQuery) {
+			t.Errorf("ParseTaskFilter(%q) err = %v", bad, err)
+		}
+	}
+	if err := ParseTaskSort(&TaskQuery{}, "agent"); !errors.Is(err, ErrInvalidTaskQuery) {
+		t.Errorf("ParseTaskSort(agent) err = %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+)
+
+// Event payload schema versions
+//
+// Events pushed to subscribers (WebSocket, gRPC) and listed by /api/events
+// carry schema_version, the shape of their envelope. A subscriber can pin
+// an older version and the server down-converts each event to it, so an
+// envelope change only reaches consumers once they opt in.
+//
+//	1  the envelope before versioning: no schema_version, and pushed
+//	   events have no entity_type (the /api/events log always had it)
+//	2  schema_version on every event, and entity_type on pushed ones
+
+const (
+	// EventSchemaVersion is the version events are rendered at unless a
+	// subscriber pins another.
+	EventSchemaVersion = 2
+	// MinEventSchemaVersion is the oldest version still down-converted to.
+	MinEventSchemaVersion = 1
+)
+
+// ErrUnsupportedSchemaVersion is returned for a pinned version outside
+// MinEventSchemaVersion..EventSchemaVersion.
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+
+// eventDowngrades[v] rewrites an event envelope from version v to v-1.
+var eventDowngrades = map[int]func(map[string]any){
+	2: func(m map[string]any) {
+		delete(m, "schema_version")
+		delete(m, "entity_type")
+	},
+}
+
+// EventSchemaVersions lists the versions a subscriber may pin, oldest
+// first.
+func EventSchemaVersions() []int {
+	out := make([]int, 0, EventSchemaVersion-MinEventSchemaVersion+1)
+	for v := MinEventSchemaVersion; v <= EventSchemaVersion; v++ {
+		out = append(out, v)
+	}
+	return out
+}
+
+// ParseEventSchemaVersion reads a pinned version; empty means the current
+// one.
+func ParseEventSchemaVersion(s string) (int, error) {
+	if s == "" {
+		return EventSchemaVersion, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < MinEventSchemaVersion || v > EventSchemaVersion {
+		return 0, fmt.Errorf("%w: %q (supported: %d to %d)", ErrUnsupportedSchemaVersion, s, MinEventSchemaVersion, EventSchemaVersion)
+	}
+	return v, nil
+}
+
+// EventAtVersion renders a broadcast event at schema version v: the
+// current envelope, down-converted one version at a time. The event itself
+// is left alone, since one broadcast is shared by every subscriber; events
+// that aren't a map[string]any are returned as they are.
+func EventAtVersion(event any, v int) any {
+	m, ok := event.(map[string]any)
+	if !ok {
+		return event
+	}
+	out := maps.Clone(m)
+	out["schema_version"] = EventSchemaVersion
+	if typ, _ := out["type"].(string); typ != "" {
+		if _, ok := out["entity_type"]; !ok {
+			out["entity_type"] = EventType(typ).EntityType()
+		}
+	}
+	for cur := EventSchemaVersion; cur > v && cur > MinEventSchemaVersion; cur-- {
+		eventDowngrades[cur](out)
+	}
+	return out
+}
@@ -2,7 +2,10 @@ package core
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
 )
 
 type EventType string
@@ -12,11 +15,40 @@ const (
 	EventMessageAck     EventType = "message.ack"
 	EventMessageRead    EventType = "message.read"
 	EventAgentHeartbeat EventType = "agent.heartbeat"
+	// EventMessageUnsnoozed re-delivers a snoozed message to one recipient
+	// at a fresh cursor when its snooze ends.
+	EventMessageUnsnoozed EventType = "message.unsnoozed"
+	// EventMessageQuorumReached is emitted once, when enough recipients
+	// have acked a message to satisfy its AckPolicy.
+	EventMessageQuorumReached EventType = "message.quorum_reached"
 )
 
+// EntityType is the domain entity an event type is about: the part before
+// the first dot, so "task.assigned" is a "task" event.
+func (t EventType) EntityType() string {
+	entity, _, _ := strings.Cut(string(t), ".")
+	return entity
+}
+
+// Attachment is a blob a message refers to instead of carrying it in the
+// body. Size and ContentType are copied from the blob at send time.
 type Attachment struct {
-	Name string
-	Path string
+	BlobID      string `json:"blob_id"`
+	Name        string `json:"name,omitempty"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// Blob is a stored payload too large for a message body, such as a diff or
+// log. Blobs are content-addressed per project: storing the same bytes
+// twice returns the first blob.
+type Blob struct {
+	ID          string    `json:"id"`
+	Project     string    `json:"project"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	ContentType string    `json:"content_type,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Message struct {
@@ -25,29 +57,111 @@ type Message struct {
 	Project     string
 	From        string
 	To          []string
-	CC          []string // Carbon copy recipients
-	BCC         []string // Blind carbon copy recipients
-	Subject     string   // Message subject line
-	Topic       string   // Topic for cross-cutting discovery (lowercased at write time)
+	CC          []string            // Carbon copy recipients
+	BCC         []string            // Blind carbon copy recipients
+	Subject     string              // Message subject line
+	Topic       string              // Topic for cross-cutting discovery (lowercased at write time)
+	InReplyTo   string              // ID of the message this replies to or forwards
+	Groups      map[string][]string // Contact groups addressed, with the members they expanded to at send time
+	Route       *MessageRoute       // How a capability-routed send chose its recipients
 	Body        string
 	Metadata    map[string]string
 	Attachments []Attachment
 	Importance  string
 	Transport   TransportMode
 	AckRequired bool
+	AckPolicy   AckPolicy // How many recipients must ack before message.quorum_reached
 	Status      string
 	CreatedAt   time.Time
 	Cursor      uint64
 }
 
+// MessageRoute records how a send addressed to a capability expression
+// instead of named agents was resolved: the expression, whether it fanned
+// out to every match, the eligible agents considered and those chosen.
+type MessageRoute struct {
+	Capability string   `json:"capability"`
+	FanOut     bool     `json:"fan_out,omitempty"`
+	Candidates []string `json:"candidates"`
+	Selected   []string `json:"selected"`
+}
+
+// ContactGroup is a per-project named set of agents that can be addressed
+// as "@name" in a message's To, CC or BCC. A Team group is addressed as a
+// whole instead: "@name" is kept as the recipient (or reservation holder)
+// and membership is resolved when the inbox is read or a conflict checked,
+// so any current member can act for the team.
+type ContactGroup struct {
+	Project     string
+	Name        string
+	Description string
+	Members     []string
+	Team        bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Recipients returns every addressee across To, CC and BCC, in that order,
+// with duplicates removed.
+func (m Message) Recipients() []string {
+	seen := make(map[string]bool, len(m.To)+len(m.CC)+len(m.BCC))
+	var out []string
+	for _, field := range [][]string{m.To, m.CC, m.BCC} {
+		for _, agent := range field {
+			if agent == "" || seen[agent] {
+				continue
+			}
+			seen[agent] = true
+			out = append(out, agent)
+		}
+	}
+	return out
+}
+
+// VisibleTo returns the message as viewer may see it. BCC recipients are
+// disclosed in full to the sender, only as themselves to a BCC'd recipient,
+// and not at all to anyone else.
+func (m Message) VisibleTo(viewer string) Message {
+	if len(m.BCC) == 0 || (viewer != "" && viewer == m.From) {
+		return m
+	}
+	var bcc []string
+	for _, agent := range m.BCC {
+		if viewer != "" && agent == viewer {
+			bcc = []string{viewer}
+			break
+		}
+	}
+	m.BCC = bcc
+	return m
+}
+
 type Event struct {
 	ID        string
 	Type      EventType
 	Agent     string
+	Actor     string // Agent that caused the event; see ActorFromContext
+	Trace     Trace  // Request that caused the event; see TraceFromContext
 	Project   string
 	Message   Message
 	CreatedAt time.Time
 	Cursor    uint64
+
+	// Domain events are persisted with the entity they describe and their
+	// JSON payload so a single entity's history can be replayed.
+	EntityType string
+	EntityID   string
+	Data       string
+}
+
+// EventFilter narrows a read of the persisted event log. Zero fields match
+// everything; Limit <= 0 means the storage default.
+type EventFilter struct {
+	Project    string
+	EntityType string
+	EntityID   string
+	After      uint64
+	Limit      int
 }
 
 type Agent struct {
@@ -89,6 +203,23 @@ type StaleAck struct {
 	AgeSeconds int        // Seconds since message was created
 }
 
+// SnoozedMessage is a message one recipient has hidden from their inbox
+// until a chosen time.
+type SnoozedMessage struct {
+	Message Message
+	Agent   string
+	Until   time.Time
+}
+
+// SnoozeWake records a snoozed message re-delivered to its recipient.
+type SnoozeWake struct {
+	Project   string
+	Agent     string
+	MessageID string
+	EventID   string
+	Cursor    uint64
+}
+
 // WindowIdentity maps a tmux window UUID to a stable agent identity.
 // Survives session restarts — the agent_id stays the same so reservations,
 // inbox, and contacts are preserved.
@@ -120,7 +251,34 @@ type Reservation struct {
 
 // IsActive returns true if the reservation is still active
 func (r *Reservation) IsActive() bool {
-	return r.ReleasedAt == nil && time.Now().Before(r.ExpiresAt)
+	return r.ReleasedAt == nil && clock.Now().Before(r.ExpiresAt)
+}
+
+// TakeoverStatus is where a reservation takeover request stands.
+type TakeoverStatus string
+
+const (
+	TakeoverPending     TakeoverStatus = "pending"     // waiting for the holder
+	TakeoverDeclined    TakeoverStatus = "declined"    // the holder kept the reservation
+	TakeoverTransferred TakeoverStatus = "transferred" // the requester holds it now
+	TakeoverLapsed      TakeoverStatus = "lapsed"      // the reservation ended before the deadline
+)
+
+// ReservationTakeover is a blocked agent's request to take over a
+// reservation from a holder that appears stuck. Unless the holder answers
+// by Deadline, the reservation moves to Requester as NewReservationID.
+type ReservationTakeover struct {
+	ID               string         `json:"id"`
+	Project          string         `json:"project"`
+	ReservationID    string         `json:"reservation_id"`
+	Holder           string         `json:"holder"`
+	Requester        string         `json:"requester"`
+	Reason           string         `json:"reason,omitempty"`
+	Status           TakeoverStatus `json:"status"`
+	RequestedAt      time.Time      `json:"requested_at"`
+	Deadline         time.Time      `json:"deadline"`
+	ResolvedAt       *time.Time     `json:"resolved_at,omitempty"`
+	NewReservationID string         `json:"new_reservation_id,omitempty"`
 }
 
 // ConflictDetail describes a single conflicting reservation.
@@ -129,6 +287,7 @@ type ConflictDetail struct {
 	AgentID       string    `json:"agent_id"`
 	AgentName     string    `json:"held_by"`
 	Pattern       string    `json:"pattern"`
+	Exclusive     bool      `json:"exclusive"`
 	Reason        string    `json:"reason,omitempty"`
 	ExpiresAt     time.Time `json:"expires_at"`
 }
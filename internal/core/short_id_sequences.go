@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Short ID sequences
+//
+// A project can give each entity type with short IDs its own prefix and
+// starting number ("PAY-100" rather than "TASK-1"). The next number issued
+// is one past the last issued under the prefix, or Start if that's higher,
+// so lowering Start never reuses a number. Changing a prefix leaves the
+// short IDs already issued alone, and the new prefix counts on from its own
+// last number (Start for a fresh one).
+
+// ShortIDSequence configures one entity type's short IDs in a project.
+// Zero fields use the defaults: the type's prefix (TASK, ...) and 1.
+type ShortIDSequence struct {
+	Prefix string `json:"prefix,omitempty"`
+	Start  int64  `json:"start,omitempty"`
+}
+
+// ShortIDSequences maps entity types ("spec", "epic", "story", "task") to
+// their sequence configuration.
+type ShortIDSequences map[string]ShortIDSequence
+
+// ErrInvalidShortIDSequence is returned for a sequence configuration that
+// can't be used.
+var ErrInvalidShortIDSequence = errors.New("invalid short id sequence")
+
+// maxShortIDPrefix is the longest prefix a project may choose.
+const maxShortIDPrefix = 10
+
+// validShortIDPrefix reports whether prefix is an upper-case letter
+// followed by up to nine upper-case letters or digits.
+func validShortIDPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > maxShortIDPrefix || prefix[0] < 'A' || prefix[0] > 'Z' {
+		return false
+	}
+	for _, c := range prefix[1:] {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// For returns entityType's effective sequence, defaults filled in.
+func (seqs ShortIDSequences) For(entityType string) ShortIDSequence {
+	seq := seqs[entityType]
+	if seq.Prefix == "" {
+		seq.Prefix = ShortIDPrefix(entityType)
+	}
+	if seq.Start <= 0 {
+		seq.Start = 1
+	}
+	return seq
+}
+
+// Normalize validates seqs and returns them in canonical form: prefixes
+// upper-cased and entries that only restate the defaults dropped. Every
+// entity type must be one with short IDs, and the project's effective
+// prefixes must be distinct so a short ID names one type.
+func (seqs ShortIDSequences) Normalize() (ShortIDSequences, error) {
+	out := ShortIDSequences{}
+	for entityType, seq := range seqs {
+		def := ShortIDPrefix(entityType)
+		if def == "" {
+			return nil, fmt.Errorf("%w: %q has no short ids", ErrInvalidShortIDSequence, entityType)
+		}
+		seq.Prefix = strings.ToUpper(strings.TrimSpace(seq.Prefix))
+		if seq.Prefix != "" && !validShortIDPrefix(seq.Prefix) {
+			return nil, fmt.Errorf("%w: %s prefix %q must be a letter followed by up to %d letters or digits", ErrInvalidShortIDSequence, entityType, seq.Prefix, maxShortIDPrefix-1)
+		}
+		if seq.Start < 0 {
+			return nil, fmt.Errorf("%w: %s start must not be negative", ErrInvalidShortIDSequence, entityType)
+		}
+		if seq.Prefix == def {
+			seq.Prefix = ""
+		}
+		if seq.Start == 1 {
+			seq.Start = 0
+		}
+		if seq != (ShortIDSequence{}) {
+			out[entityType] = seq
+		}
+	}
+	types := make([]string, 0, len(shortIDPrefixes))
+	for entityType := range shortIDPrefixes {
+		types = append(types, entityType)
+	}
+	slices.Sort(types)
+	seen := map[string]string{}
+	for _, entityType := range types {
+		prefix := out.For(entityType).Prefix
+		if other, ok := seen[prefix]; ok {
+			return nil, fmt.Errorf("%w: %s and %s would both use prefix %s", ErrInvalidShortIDSequence, other, entityType, prefix)
+		}
+		seen[prefix] = entityType
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTaskQuery is returned for a task filter or sort that doesn't
+// parse; the wrapped message says which term.
+var ErrInvalidTaskQuery = errors.New("invalid task query")
+
+// TaskQuery narrows and orders a task listing. Each list field matches
+// any of its values (ExcludeStatuses none of them), fields are ANDed, and
+// zero fields and times don't filter. Time bounds are exclusive. An empty
+// Sort is newest update first; Limit <= 0 returns every match.
+type TaskQuery struct {
+	Project         string
+	Statuses        []TaskStatus
+	ExcludeStatuses []TaskStatus
+	Agents          []string
+	StoryIDs        []string
+	Priorities      []Priority
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	UpdatedAfter    time.Time
+	UpdatedBefore   time.Time
+	DueAfter        time.Time
+	DueBefore       time.Time
+	Sort            []TaskSort
+	Limit           int
+}
+
+// TaskSort orders a task listing by one field.
+type TaskSort struct {
+	Field TaskSortField
+	Desc  bool
+}
+
+// TaskSortField is a field tasks can be sorted by. Priority sorts high
+// first, and tasks without a due date sort after those with one.
+type TaskSortField string
+
+const (
+	TaskSortCreatedAt TaskSortField = "created_at"
+	TaskSortUpdatedAt TaskSortField = "updated_at"
+	TaskSortDueAt     TaskSortField = "due_at"
+	TaskSortPriority  TaskSortField = "priority"
+	TaskSortTitle     TaskSortField = "title"
+)
+
+func validTaskSortField(f TaskSortField) bool {
+	switch f {
+	case TaskSortCreatedAt, TaskSortUpdatedAt, TaskSortDueAt, TaskSortPriority, TaskSortTitle:
+		return true
+	}
+	return false
+}
+
+// ParseTaskFilter adds the terms of filter to q. Terms are separated by
+// spaces and written key:value or key:in(a,b,c):
+//
+//	status:in(pending,blocked) updated_after:2024-06-01 story:xyz
+//
+// status, agent, story and priority take values and may repeat, adding
+// to the values matched; created_, updated_ and due_ with after or before
+// take a date (2006-01-02, UTC midnight) or an RFC 3339 time.
+func ParseTaskFilter(q *TaskQuery, filter string) error {
+	for _, term := range strings.Fields(filter) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			return fmt.Errorf("%w: %q is not key:value", ErrInvalidTaskQuery, term)
+		}
+		switch key {
+		case "status":
+			for _, v := range filterValues(value) {
+				if !KnownStatus("task", v) {
+					return fmt.Errorf("%w: unknown status %q", ErrInvalidTaskQuery, v)
+				}
+				q.Statuses = append(q.Statuses, TaskStatus(v))
+			}
+		case "agent":
+			q.Agents = append(q.Agents, filterValues(value)...)
+		case "story":
+			q.StoryIDs = append(q.StoryIDs, filterValues(value)...)
+		case "priority":
+			for _, v := range filterValues(value) {
+				if !ValidPriority(Priority(v)) {
+					return fmt.Errorf("%w: unknown priority %q", ErrInvalidTaskQuery, v)
+				}
+				q.Priorities = append(q.Priorities, Priority(v))
+			}
+		case "created_after", "created_before", "updated_after", "updated_before", "due_after", "due_before":
+			t, err := parseFilterTime(value)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %q is not a date or RFC 3339 time", ErrInvalidTaskQuery, key, value)
+			}
+			*q.timeBound(key) = t
+		default:
+			return fmt.Errorf("%w: unknown filter %q", ErrInvalidTaskQuery, key)
+		}
+	}
+	return nil
+}
+
+// ParseTaskSort sets q's order from a comma-separated list of fields, each
+// descending with a leading "-": "-updated_at,priority".
+func ParseTaskSort(q *TaskQuery, sort string) error {
+	for _, f := range strings.Split(sort, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		s := TaskSort{Field: TaskSortField(strings.TrimPrefix(f, "-")), Desc: strings.HasPrefix(f, "-")}
+		if !validTaskSortField(s.Field) {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidTaskQuery, s.Field)
+		}
+		q.Sort = append(q.Sort, s)
+	}
+	return nil
+}
+
+func (q *TaskQuery) timeBound(key string) *time.Time {
+	switch key {
+	case "created_after":
+		return &q.CreatedAfter
+	case "created_before":
+		return &q.CreatedBefore
+	case "updated_after":
+		return &q.UpdatedAfter
+	case "updated_before":
+		return &q.UpdatedBefore
+	case "due_after":
+		return &q.DueAfter
+	default:
+		return &q.DueBefore
+	}
+}
+
+// filterValues splits "in(a,b)" into its values; anything else is a
+// single value.
+func filterValues(value string) []string {
+	inner, ok := strings.CutPrefix(value, "in(")
+	if !ok || !strings.HasSuffix(inner, ")") {
+		return []string{value}
+	}
+	var out []string
+	for _, v := range strings.Split(strings.TrimSuffix(inner, ")"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,32 @@
+package core
+
+import "context"
+
+// Trace identifies the request behind an operation so the events it
+// causes can be stitched into causal chains downstream. RequestID is the
+// originating HTTP request; CorrelationID groups every request of one
+// logical operation (it defaults to the first RequestID); CausationID is
+// the event the request was reacting to, when the caller says so.
+type Trace struct {
+	RequestID     string
+	CorrelationID string
+	CausationID   string
+}
+
+type traceKey struct{}
+
+// WithTrace returns a copy of ctx carrying t. Like the actor, stores read
+// it back to stamp the events they persist.
+func WithTrace(ctx context.Context, t Trace) context.Context {
+	if t == (Trace{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace of the current operation, or the
+// zero Trace for background jobs.
+func TraceFromContext(ctx context.Context) Trace {
+	v, _ := ctx.Value(traceKey{}).(Trace)
+	return v
+}
@@ -0,0 +1,66 @@
+package core
+
+import "slices"
+
+// AllowedTransitions lists, per entity type, the statuses each status may
+// move to. Statuses missing from a table are terminal or unknown. Projects
+// opt in to enforcement with FlagStrictTransitions; insights always follow
+// their own triage rules (see InsightStatus.CanTransitionTo).
+var AllowedTransitions = map[string]map[string][]string{
+	"spec": {
+		string(SpecStatusDraft):     {string(SpecStatusResearch), string(SpecStatusValidated), string(SpecStatusArchived)},
+		string(SpecStatusResearch):  {string(SpecStatusDraft), string(SpecStatusValidated), string(SpecStatusArchived)},
+		string(SpecStatusValidated): {string(SpecStatusResearch), string(SpecStatusArchived)},
+		string(SpecStatusArchived):  {string(SpecStatusDraft)},
+	},
+	"epic": {
+		string(EpicStatusOpen):       {string(EpicStatusInProgress), string(EpicStatusDone)},
+		string(EpicStatusInProgress): {string(EpicStatusOpen), string(EpicStatusDone)},
+		string(EpicStatusDone):       {string(EpicStatusInProgress)},
+	},
+	"story": {
+		string(StoryStatusTodo):       {string(StoryStatusInProgress)},
+		string(StoryStatusInProgress): {string(StoryStatusTodo), string(StoryStatusReview), string(StoryStatusDone)},
+		string(StoryStatusReview):     {string(StoryStatusInProgress), string(StoryStatusDone)},
+		string(StoryStatusDone):       {string(StoryStatusInProgress)},
+	},
+	// Done tasks are final: follow-up work is a new task.
+	"task": {
+		string(TaskStatusPending): {string(TaskStatusRunning), string(TaskStatusBlocked), string(TaskStatusDone)},
+		string(TaskStatusRunning): {string(TaskStatusPending), string(TaskStatusBlocked), string(TaskStatusDone)},
+		string(TaskStatusBlocked): {string(TaskStatusPending), string(TaskStatusRunning)},
+	},
+	"cuj": {
+		string(CUJStatusDraft):     {string(CUJStatusValidated), string(CUJStatusArchived)},
+		string(CUJStatusValidated): {string(CUJStatusDraft), string(CUJStatusArchived)},
+		string(CUJStatusArchived):  {string(CUJStatusDraft)},
+	},
+	"goal": {
+		string(GoalStatusActive):    {string(GoalStatusAchieved), string(GoalStatusAbandoned)},
+		string(GoalStatusAchieved):  {string(GoalStatusActive)},
+		string(GoalStatusAbandoned): {string(GoalStatusActive)},
+	},
+}
+
+// CanTransition reports whether an entity of entityType may move from one
+// status to another. Staying put is always allowed, as is leaving an
+// empty status (rows written before statuses were required) and any move
+// for entity types without a table.
+func CanTransition(entityType, from, to string) bool {
+	table, ok := AllowedTransitions[entityType]
+	if !ok || from == "" || from == to {
+		return true
+	}
+	return slices.Contains(table[from], to)
+}
+
+// KnownStatus reports whether status appears in entityType's transition
+// table, as a source or a target.
+func KnownStatus(entityType, status string) bool {
+	for from, targets := range AllowedTransitions[entityType] {
+		if from == status || slices.Contains(targets, status) {
+			return true
+		}
+	}
+	return false
+}
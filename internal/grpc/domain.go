@@ -0,0 +1,246 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/grpc/pb"
+)
+
+type domainServer struct {
+	pb.UnimplementedDomainServer
+	rest *restClient
+}
+
+// Specs
+
+func (d *domainServer) CreateSpec(ctx context.Context, req *pb.Spec) (*pb.Spec, error) {
+	out := &pb.Spec{}
+	return out, d.create(ctx, "specs", specFromPB(req), out)
+}
+
+func (d *domainServer) GetSpec(ctx context.Context, req *pb.GetRequest) (*pb.Spec, error) {
+	out := &pb.Spec{}
+	return out, d.get(ctx, "specs", req, out)
+}
+
+func (d *domainServer) ListSpecs(ctx context.Context, req *pb.ListSpecsRequest) (*pb.ListSpecsResponse, error) {
+	q := projectQuery(req.GetProject())
+	setIf(q, "status", req.GetStatus())
+	out := &pb.ListSpecsResponse{}
+	return out, d.rest.list(ctx, "/api/specs", q, "specs", out)
+}
+
+func (d *domainServer) UpdateSpec(ctx context.Context, req *pb.Spec) (*pb.Spec, error) {
+	out := &pb.Spec{}
+	return out, d.update(ctx, "specs", req.GetId(), specFromPB(req), out)
+}
+
+func (d *domainServer) DeleteSpec(ctx context.Context, req *pb.DeleteRequest) (*emptypb.Empty, error) {
+	return d.delete(ctx, "specs", req)
+}
+
+// Epics
+
+func (d *domainServer) CreateEpic(ctx context.Context, req *pb.Epic) (*pb.Epic, error) {
+	out := &pb.Epic{}
+	return out, d.create(ctx, "epics", epicFromPB(req), out)
+}
+
+func (d *domainServer) GetEpic(ctx context.Context, req *pb.GetRequest) (*pb.Epic, error) {
+	out := &pb.Epic{}
+	return out, d.get(ctx, "epics", req, out)
+}
+
+func (d *domainServer) ListEpics(ctx context.Context, req *pb.ListEpicsRequest) (*pb.ListEpicsResponse, error) {
+	q := projectQuery(req.GetProject())
+	setIf(q, "spec", req.GetSpecId())
+	out := &pb.ListEpicsResponse{}
+	return out, d.rest.list(ctx, "/api/epics", q, "epics", out)
+}
+
+func (d *domainServer) UpdateEpic(ctx context.Context, req *pb.Epic) (*pb.Epic, error) {
+	out := &pb.Epic{}
+	return out, d.update(ctx, "epics", req.GetId(), epicFromPB(req), out)
+}
+
+func (d *domainServer) DeleteEpic(ctx context.Context, req *pb.DeleteRequest) (*emptypb.Empty, error) {
+	return d.delete(ctx, "epics", req)
+}
+
+// Stories
+
+func (d *domainServer) CreateStory(ctx context.Context, req *pb.Story) (*pb.Story, error) {
+	out := &pb.Story{}
+	return out, d.create(ctx, "stories", storyFromPB(req), out)
+}
+
+func (d *domainServer) GetStory(ctx context.Context, req *pb.GetRequest) (*pb.Story, error) {
+	out := &pb.Story{}
+	return out, d.get(ctx, "stories", req, out)
+}
+
+func (d *domainServer) ListStories(ctx context.Context, req *pb.ListStoriesRequest) (*pb.ListStoriesResponse, error) {
+	q := projectQuery(req.GetProject())
+	setIf(q, "epic", req.GetEpicId())
+	out := &pb.ListStoriesResponse{}
+	return out, d.rest.list(ctx, "/api/stories", q, "stories", out)
+}
+
+func (d *domainServer) UpdateStory(ctx context.Context, req *pb.Story) (*pb.Story, error) {
+	out := &pb.Story{}
+	return out, d.update(ctx, "stories", req.GetId(), storyFromPB(req), out)
+}
+
+func (d *domainServer) DeleteStory(ctx context.Context, req *pb.DeleteRequest) (*emptypb.Empty, error) {
+	return d.delete(ctx, "stories", req)
+}
+
+// Tasks
+
+func (d *domainServer) CreateTask(ctx context.Context, req *pb.Task) (*pb.Task, error) {
+	out := &pb.Task{}
+	return out, d.create(ctx, "tasks", taskFromPB(req), out)
+}
+
+func (d *domainServer) GetTask(ctx context.Context, req *pb.GetRequest) (*pb.Task, error) {
+	out := &pb.Task{}
+	return out, d.get(ctx, "tasks", req, out)
+}
+
+func (d *domainServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	q := projectQuery(req.GetProject())
+	setIf(q, "status", req.GetStatus())
+	setIf(q, "agent", req.GetAgent())
+	out := &pb.ListTasksResponse{}
+	return out, d.rest.list(ctx, "/api/tasks", q, "tasks", out)
+}
+
+func (d *domainServer) UpdateTask(ctx context.Context, req *pb.Task) (*pb.Task, error) {
+	out := &pb.Task{}
+	return out, d.update(ctx, "tasks", req.GetId(), taskFromPB(req), out)
+}
+
+func (d *domainServer) DeleteTask(ctx context.Context, req *pb.DeleteRequest) (*emptypb.Empty, error) {
+	return d.delete(ctx, "tasks", req)
+}
+
+// REST plumbing shared by every entity. The returned errors are already
+// gRPC statuses; callers return out alongside them, which gRPC ignores
+// when err is set.
+
+func (d *domainServer) create(ctx context.Context, collection string, body any, out proto.Message) error {
+	return d.rest.call(ctx, http.MethodPost, "/api/"+collection, nil, body, out)
+}
+
+func (d *domainServer) get(ctx context.Context, collection string, req *pb.GetRequest, out proto.Message) error {
+	return d.rest.call(ctx, http.MethodGet, entityPath(collection, req.GetId()), projectQuery(req.GetProject()), nil, out)
+}
+
+func (d *domainServer) update(ctx context.Context, collection, id string, body any, out proto.Message) error {
+	return d.rest.call(ctx, http.MethodPut, entityPath(collection, id), nil, body, out)
+}
+
+func (d *domainServer) delete(ctx context.Context, collection string, req *pb.DeleteRequest) (*emptypb.Empty, error) {
+	if err := d.rest.call(ctx, http.MethodDelete, entityPath(collection, req.GetId()), projectQuery(req.GetProject()), nil, nil); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func entityPath(collection, id string) string {
+	return "/api/" + collection + "/" + url.PathEscape(id)
+}
+
+func setIf(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+// Request bodies are the core types the REST handlers decode. Timestamps
+// are server-assigned and left out.
+
+func specFromPB(s *pb.Spec) core.Spec {
+	return core.Spec{
+		ID:      s.GetId(),
+		Project: s.GetProject(),
+		Title:   s.GetTitle(),
+		Vision:  s.GetVision(),
+		Users:   s.GetUsers(),
+		Problem: s.GetProblem(),
+		Status:  core.SpecStatus(s.GetStatus()),
+		Version: s.GetVersion(),
+	}
+}
+
+func epicFromPB(e *pb.Epic) core.Epic {
+	return core.Epic{
+		ID:          e.GetId(),
+		Project:     e.GetProject(),
+		SpecID:      e.GetSpecId(),
+		Title:       e.GetTitle(),
+		Description: e.GetDescription(),
+		Status:      core.EpicStatus(e.GetStatus()),
+		Version:     e.GetVersion(),
+	}
+}
+
+func storyFromPB(s *pb.Story) core.Story {
+	return core.Story{
+		ID:                 s.GetId(),
+		Project:            s.GetProject(),
+		EpicID:             s.GetEpicId(),
+		Title:              s.GetTitle(),
+		AcceptanceCriteria: s.GetAcceptanceCriteria(),
+		Status:             core.StoryStatus(s.GetStatus()),
+		Priority:           core.Priority(s.GetPriority()),
+		Version:            s.GetVersion(),
+	}
+}
+
+func taskFromPB(t *pb.Task) core.Task {
+	return core.Task{
+		ID:            t.GetId(),
+		Project:       t.GetProject(),
+		StoryID:       t.GetStoryId(),
+		Title:         t.GetTitle(),
+		Agent:         t.GetAgent(),
+		SessionID:     t.GetSessionId(),
+		Status:        core.TaskStatus(t.GetStatus()),
+		Priority:      core.Priority(t.GetPriority()),
+		DueAt:         optionalTime(t.GetDueAt()),
+		Capabilities:  t.GetCapabilities(),
+		ExpectedPaths: t.GetExpectedPaths(),
+		BlockedReason: core.BlockedReason(t.GetBlockedReason()),
+		BlockedDetail: t.GetBlockedDetail(),
+		UnblockWhen:   unblockFromPB(t.GetUnblockWhen()),
+		Version:       t.GetVersion(),
+	}
+}
+
+func unblockFromPB(c *pb.UnblockCondition) *core.UnblockCondition {
+	if c == nil {
+		return nil
+	}
+	return &core.UnblockCondition{
+		TaskID:      c.GetTaskId(),
+		PathPattern: c.GetPathPattern(),
+		ExternalRef: c.GetExternalRef(),
+	}
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
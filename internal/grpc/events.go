@@ -0,0 +1,179 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/grpc/pb"
+	"github.com/mistakeknot/intermute/internal/ws"
+)
+
+// subscriberBuffer is how many events a subscription may fall behind by
+// before it is cut off, like a WebSocket whose writes time out.
+const subscriberBuffer = 256
+
+type subscriber struct {
+	project string
+	agent   string
+	filter  ws.Filter
+	events  chan any
+	lagged  chan struct{} // closed when the buffer overflowed
+	once    sync.Once
+}
+
+// EventBus fans broadcasts out to Events.Subscribe streams. Wire it into
+// the HTTP service next to the WebSocket hub (see httpapi.Broadcasters).
+type EventBus struct {
+	mu     sync.Mutex
+	subs   map[*subscriber]struct{}
+	closed chan struct{}
+	stop   sync.Once
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[*subscriber]struct{}), closed: make(chan struct{})}
+}
+
+// Broadcast queues event for every subscription it is addressed to, with
+// the WebSocket hub's targeting: an empty project reaches every project, an
+// empty agent every subscription in the project, and a named agent only
+// that agent's subscriptions.
+func (b *EventBus) Broadcast(project, agent string, event any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		if project != "" && project != sub.project {
+			continue
+		}
+		if agent != "" && agent != sub.agent {
+			continue
+		}
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.once.Do(func() { close(sub.lagged) })
+		}
+	}
+}
+
+func (b *EventBus) add(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = struct{}{}
+}
+
+func (b *EventBus) remove(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, sub)
+}
+
+func (b *EventBus) close() {
+	b.stop.Do(func() { close(b.closed) })
+}
+
+type eventsServer struct {
+	pb.UnimplementedEventsServer
+	rest *restClient
+	bus  *EventBus
+}
+
+// Subscribe streams events until the client cancels, the server stops or
+// the client falls too far behind.
+func (e *eventsServer) Subscribe(req *pb.SubscribeRequest, stream pb.Events_SubscribeServer) error {
+	project, err := e.subscriptionProject(stream, req.GetProject(), req.GetAgent())
+	if err != nil {
+		return err
+	}
+	filter, err := ws.NewFilter(req.GetEvents(), req.GetEntityIds())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid event pattern: %v", err)
+	}
+	sub := &subscriber{
+		project: project,
+		agent:   req.GetAgent(),
+		filter:  filter,
+		events:  make(chan any, subscriberBuffer),
+		lagged:  make(chan struct{}),
+	}
+	e.bus.add(sub)
+	defer e.bus.remove(sub)
+	// Tell the client the subscription is live: events broadcast after it
+	// sees the headers are not missed.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-e.bus.closed:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case <-sub.lagged:
+			return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+		case event := <-sub.events:
+			msg, err := toPBEvent(core.EventAtVersion(event, core.EventSchemaVersion))
+			if err != nil {
+				continue
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// subscriptionProject authenticates a subscription the way the WebSocket
+// route does (through the auth middleware) and returns its project: an
+// API key pins the caller to the key's project, and a known agent may
+// only subscribe as itself.
+func (e *eventsServer) subscriptionProject(stream pb.Events_SubscribeServer, requested, agent string) (string, error) {
+	info, err := e.rest.authenticate(stream.Context())
+	if err != nil {
+		return "", err
+	}
+	if info.AgentID != "" && info.AgentID != agent {
+		return "", status.Error(codes.PermissionDenied, "agent identity mismatch")
+	}
+	if info.Mode != auth.ModeAPIKey {
+		return requested, nil
+	}
+	if requested != "" && requested != info.Project {
+		return "", status.Error(codes.PermissionDenied, "project not allowed for this key")
+	}
+	return info.Project, nil
+}
+
+// toPBEvent converts a broadcast event, normally a map[string]any, to its
+// wire form.
+func toPBEvent(event any) (*pb.Event, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	payload := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, payload); err != nil {
+		return nil, err
+	}
+	str := func(key string) string { return payload.GetFields()[key].GetStringValue() }
+	return &pb.Event{
+		Type:     str("type"),
+		EventId:  str("event_id"),
+		Project:  str("project"),
+		EntityId: str("entity_id"),
+		Payload:  payload,
+	}, nil
+}
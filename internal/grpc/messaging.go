@@ -0,0 +1,161 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/mistakeknot/intermute/internal/grpc/pb"
+)
+
+// streamInboxWait is how long each long-poll behind StreamInbox waits for
+// new messages before asking again.
+const streamInboxWait = "30s"
+
+type messagingServer struct {
+	pb.UnimplementedMessagingServer
+	rest *restClient
+}
+
+func (m *messagingServer) RegisterAgent(ctx context.Context, req *pb.RegisterAgentRequest) (*pb.RegisterAgentResponse, error) {
+	body := map[string]any{
+		"name":         req.GetName(),
+		"session_id":   req.GetSessionId(),
+		"project":      req.GetProject(),
+		"capabilities": req.GetCapabilities(),
+		"metadata":     req.GetMetadata(),
+		"status":       req.GetStatus(),
+	}
+	out := &pb.RegisterAgentResponse{}
+	if err := m.rest.call(ctx, http.MethodPost, "/api/agents", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *messagingServer) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	body := map[string]any{"focus_state": req.GetFocusState()}
+	out := &pb.HeartbeatResponse{}
+	if err := m.rest.call(ctx, http.MethodPost, "/api/agents/"+url.PathEscape(req.GetAgentId())+"/heartbeat", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *messagingServer) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	q := projectQuery(req.GetProject())
+	if caps := req.GetCapabilities(); len(caps) > 0 {
+		q.Set("capability", strings.Join(caps, ","))
+	}
+	out := &pb.ListAgentsResponse{}
+	if err := m.rest.call(ctx, http.MethodGet, "/api/agents", q, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *messagingServer) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
+	body := map[string]any{
+		"id":                 req.GetId(),
+		"thread_id":          req.GetThreadId(),
+		"project":            req.GetProject(),
+		"from":               req.GetFrom(),
+		"to":                 req.GetTo(),
+		"cc":                 req.GetCc(),
+		"bcc":                req.GetBcc(),
+		"subject":            req.GetSubject(),
+		"topic":              req.GetTopic(),
+		"in_reply_to":        req.GetInReplyTo(),
+		"body":               req.GetBody(),
+		"importance":         req.GetImportance(),
+		"transport":          req.GetTransport(),
+		"target_window_uuid": req.GetTargetWindowUuid(),
+		"ack_required":       req.GetAckRequired(),
+	}
+	out := &pb.SendMessageResponse{}
+	if err := m.rest.call(ctx, http.MethodPost, "/api/messages", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *messagingServer) Inbox(ctx context.Context, req *pb.InboxRequest) (*pb.InboxResponse, error) {
+	q := inboxQuery(req.GetProject(), req.GetSinceCursor())
+	if req.GetLimit() > 0 {
+		q.Set("limit", strconv.Itoa(int(req.GetLimit())))
+	}
+	if req.GetWaitSeconds() > 0 {
+		q.Set("wait", strconv.Itoa(int(req.GetWaitSeconds())))
+	}
+	return m.inbox(ctx, req.GetAgent(), q)
+}
+
+// StreamInbox chains inbox long-polls, sending each message once, until
+// the client goes away.
+func (m *messagingServer) StreamInbox(req *pb.StreamInboxRequest, stream pb.Messaging_StreamInboxServer) error {
+	ctx := stream.Context()
+	cursor := req.GetSinceCursor()
+	for {
+		q := inboxQuery(req.GetProject(), cursor)
+		q.Set("wait", streamInboxWait)
+		page, err := m.inbox(ctx, req.GetAgent(), q)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, msg := range page.GetMessages() {
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+		cursor = page.GetCursor()
+	}
+}
+
+func (m *messagingServer) inbox(ctx context.Context, agent string, q url.Values) (*pb.InboxResponse, error) {
+	out := &pb.InboxResponse{}
+	if err := m.rest.call(ctx, http.MethodGet, "/api/inbox/"+url.PathEscape(agent), q, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *messagingServer) MarkRead(ctx context.Context, req *pb.MessageActionRequest) (*emptypb.Empty, error) {
+	return m.messageAction(ctx, req, "read")
+}
+
+func (m *messagingServer) Ack(ctx context.Context, req *pb.MessageActionRequest) (*emptypb.Empty, error) {
+	return m.messageAction(ctx, req, "ack")
+}
+
+func (m *messagingServer) messageAction(ctx context.Context, req *pb.MessageActionRequest, action string) (*emptypb.Empty, error) {
+	path := "/api/messages/" + url.PathEscape(req.GetMessageId()) + "/" + action
+	body := map[string]any{"agent": req.GetAgent()}
+	if err := m.rest.call(ctx, http.MethodPost, path, projectQuery(req.GetProject()), body, nil); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// projectQuery is ?project=project, or no query for an empty project.
+func projectQuery(project string) url.Values {
+	q := url.Values{}
+	if project != "" {
+		q.Set("project", project)
+	}
+	return q
+}
+
+func inboxQuery(project string, cursor uint64) url.Values {
+	q := projectQuery(project)
+	if cursor > 0 {
+		q.Set("since_cursor", strconv.FormatUint(cursor, 10))
+	}
+	return q
+}
@@ -0,0 +1,2807 @@
+// gRPC surface of the intermute API. Every unary RPC maps onto one REST
+// endpoint and behaves exactly like it: same validation, auth, contact
+// policy and broadcasts. Field names match the REST JSON fields.
+//
+// Authenticate with the same credentials as over HTTP, sent as metadata:
+// "authorization: Bearer <key>", plus optional "x-agent-id" and
+// "x-agent-token". Calls from localhost need none.
+//
+// Regenerate the Go code with `go generate ./internal/grpc`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: intermute/v1/intermute.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RegisterAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Project       string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterAgentRequest) Reset() {
+	*x = RegisterAgentRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterAgentRequest) ProtoMessage() {}
+
+func (x *RegisterAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterAgentRequest.ProtoReflect.Descriptor instead.
+func (*RegisterAgentRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterAgentRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RegisterAgentRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *RegisterAgentRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *RegisterAgentRequest) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *RegisterAgentRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *RegisterAgentRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type RegisterAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Token         string                 `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
+	Cursor        uint64                 `protobuf:"varint,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterAgentResponse) Reset() {
+	*x = RegisterAgentResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterAgentResponse) ProtoMessage() {}
+
+func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterAgentResponse.ProtoReflect.Descriptor instead.
+func (*RegisterAgentResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterAgentResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RegisterAgentResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *RegisterAgentResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RegisterAgentResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RegisterAgentResponse) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	FocusState    string                 `protobuf:"bytes,2,opt,name=focus_state,json=focusState,proto3" json:"focus_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HeartbeatRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetFocusState() string {
+	if x != nil {
+		return x.FocusState
+	}
+	return ""
+}
+
+type HeartbeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HeartbeatResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ListAgentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,2,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsRequest) Reset() {
+	*x = ListAgentsRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsRequest) ProtoMessage() {}
+
+func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListAgentsRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *ListAgentsRequest) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type Agent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Project       string                 `protobuf:"bytes,4,opt,name=project,proto3" json:"project,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Agent) Reset() {
+	*x = Agent{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Agent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Agent) ProtoMessage() {}
+
+func (x *Agent) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
+func (*Agent) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Agent) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Agent) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Agent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Agent) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Agent) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *Agent) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Agent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Agent) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Agent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListAgentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*Agent               `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsResponse) Reset() {
+	*x = ListAgentsResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsResponse) ProtoMessage() {}
+
+func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListAgentsResponse) GetAgents() []*Agent {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+type SendMessageRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ThreadId         string                 `protobuf:"bytes,2,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	Project          string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	From             string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To               []string               `protobuf:"bytes,5,rep,name=to,proto3" json:"to,omitempty"`
+	Cc               []string               `protobuf:"bytes,6,rep,name=cc,proto3" json:"cc,omitempty"`
+	Bcc              []string               `protobuf:"bytes,7,rep,name=bcc,proto3" json:"bcc,omitempty"`
+	Subject          string                 `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`
+	Topic            string                 `protobuf:"bytes,9,opt,name=topic,proto3" json:"topic,omitempty"`
+	InReplyTo        string                 `protobuf:"bytes,10,opt,name=in_reply_to,json=inReplyTo,proto3" json:"in_reply_to,omitempty"`
+	Body             string                 `protobuf:"bytes,11,opt,name=body,proto3" json:"body,omitempty"`
+	Importance       string                 `protobuf:"bytes,12,opt,name=importance,proto3" json:"importance,omitempty"`
+	Transport        string                 `protobuf:"bytes,13,opt,name=transport,proto3" json:"transport,omitempty"`
+	TargetWindowUuid string                 `protobuf:"bytes,14,opt,name=target_window_uuid,json=targetWindowUuid,proto3" json:"target_window_uuid,omitempty"`
+	AckRequired      bool                   `protobuf:"varint,15,opt,name=ack_required,json=ackRequired,proto3" json:"ack_required,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SendMessageRequest) Reset() {
+	*x = SendMessageRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageRequest) ProtoMessage() {}
+
+func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SendMessageRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTo() []string {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetCc() []string {
+	if x != nil {
+		return x.Cc
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetBcc() []string {
+	if x != nil {
+		return x.Bcc
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetInReplyTo() string {
+	if x != nil {
+		return x.InReplyTo
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetImportance() string {
+	if x != nil {
+		return x.Importance
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetTargetWindowUuid() string {
+	if x != nil {
+		return x.TargetWindowUuid
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetAckRequired() bool {
+	if x != nil {
+		return x.AckRequired
+	}
+	return false
+}
+
+type SendMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Cursor        uint64                 `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Denied        []string               `protobuf:"bytes,3,rep,name=denied,proto3" json:"denied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessageResponse) Reset() {
+	*x = SendMessageResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessageResponse) ProtoMessage() {}
+
+func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendMessageResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SendMessageResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *SendMessageResponse) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *SendMessageResponse) GetDenied() []string {
+	if x != nil {
+		return x.Denied
+	}
+	return nil
+}
+
+type Message struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ThreadId      string                 `protobuf:"bytes,2,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`
+	Project       string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	From          string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To            []string               `protobuf:"bytes,5,rep,name=to,proto3" json:"to,omitempty"`
+	Cc            []string               `protobuf:"bytes,6,rep,name=cc,proto3" json:"cc,omitempty"`
+	Bcc           []string               `protobuf:"bytes,7,rep,name=bcc,proto3" json:"bcc,omitempty"`
+	Subject       string                 `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`
+	Topic         string                 `protobuf:"bytes,9,opt,name=topic,proto3" json:"topic,omitempty"`
+	InReplyTo     string                 `protobuf:"bytes,10,opt,name=in_reply_to,json=inReplyTo,proto3" json:"in_reply_to,omitempty"`
+	Body          string                 `protobuf:"bytes,11,opt,name=body,proto3" json:"body,omitempty"`
+	Importance    string                 `protobuf:"bytes,12,opt,name=importance,proto3" json:"importance,omitempty"`
+	AckRequired   bool                   `protobuf:"varint,13,opt,name=ack_required,json=ackRequired,proto3" json:"ack_required,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Cursor        uint64                 `protobuf:"varint,15,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Message) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Message) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *Message) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Message) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Message) GetTo() []string {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *Message) GetCc() []string {
+	if x != nil {
+		return x.Cc
+	}
+	return nil
+}
+
+func (x *Message) GetBcc() []string {
+	if x != nil {
+		return x.Bcc
+	}
+	return nil
+}
+
+func (x *Message) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Message) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Message) GetInReplyTo() string {
+	if x != nil {
+		return x.InReplyTo
+	}
+	return ""
+}
+
+func (x *Message) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Message) GetImportance() string {
+	if x != nil {
+		return x.Importance
+	}
+	return ""
+}
+
+func (x *Message) GetAckRequired() bool {
+	if x != nil {
+		return x.AckRequired
+	}
+	return false
+}
+
+func (x *Message) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Message) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+type InboxRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Project     string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Agent       string                 `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	SinceCursor uint64                 `protobuf:"varint,3,opt,name=since_cursor,json=sinceCursor,proto3" json:"since_cursor,omitempty"`
+	Limit       int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Long-poll for up to this many seconds when nothing is waiting (max 60).
+	WaitSeconds   int32 `protobuf:"varint,5,opt,name=wait_seconds,json=waitSeconds,proto3" json:"wait_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboxRequest) Reset() {
+	*x = InboxRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboxRequest) ProtoMessage() {}
+
+func (x *InboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboxRequest.ProtoReflect.Descriptor instead.
+func (*InboxRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *InboxRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *InboxRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *InboxRequest) GetSinceCursor() uint64 {
+	if x != nil {
+		return x.SinceCursor
+	}
+	return 0
+}
+
+func (x *InboxRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *InboxRequest) GetWaitSeconds() int32 {
+	if x != nil {
+		return x.WaitSeconds
+	}
+	return 0
+}
+
+type InboxResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	Cursor        uint64                 `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboxResponse) Reset() {
+	*x = InboxResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboxResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboxResponse) ProtoMessage() {}
+
+func (x *InboxResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboxResponse.ProtoReflect.Descriptor instead.
+func (*InboxResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *InboxResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *InboxResponse) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+type StreamInboxRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Agent         string                 `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	SinceCursor   uint64                 `protobuf:"varint,3,opt,name=since_cursor,json=sinceCursor,proto3" json:"since_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamInboxRequest) Reset() {
+	*x = StreamInboxRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamInboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamInboxRequest) ProtoMessage() {}
+
+func (x *StreamInboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamInboxRequest.ProtoReflect.Descriptor instead.
+func (*StreamInboxRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StreamInboxRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *StreamInboxRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *StreamInboxRequest) GetSinceCursor() uint64 {
+	if x != nil {
+		return x.SinceCursor
+	}
+	return 0
+}
+
+type MessageActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Agent         string                 `protobuf:"bytes,3,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageActionRequest) Reset() {
+	*x = MessageActionRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageActionRequest) ProtoMessage() {}
+
+func (x *MessageActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageActionRequest.ProtoReflect.Descriptor instead.
+func (*MessageActionRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MessageActionRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *MessageActionRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *MessageActionRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *GetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *DeleteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Spec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShortId       string                 `protobuf:"bytes,2,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`
+	Project       string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Vision        string                 `protobuf:"bytes,5,opt,name=vision,proto3" json:"vision,omitempty"`
+	Users         string                 `protobuf:"bytes,6,opt,name=users,proto3" json:"users,omitempty"`
+	Problem       string                 `protobuf:"bytes,7,opt,name=problem,proto3" json:"problem,omitempty"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	Version       int64                  `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Spec) Reset() {
+	*x = Spec{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Spec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Spec) ProtoMessage() {}
+
+func (x *Spec) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Spec.ProtoReflect.Descriptor instead.
+func (*Spec) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Spec) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Spec) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *Spec) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Spec) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Spec) GetVision() string {
+	if x != nil {
+		return x.Vision
+	}
+	return ""
+}
+
+func (x *Spec) GetUsers() string {
+	if x != nil {
+		return x.Users
+	}
+	return ""
+}
+
+func (x *Spec) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *Spec) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Spec) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Spec) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Spec) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListSpecsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSpecsRequest) Reset() {
+	*x = ListSpecsRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSpecsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSpecsRequest) ProtoMessage() {}
+
+func (x *ListSpecsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSpecsRequest.ProtoReflect.Descriptor instead.
+func (*ListSpecsRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListSpecsRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *ListSpecsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListSpecsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Specs         []*Spec                `protobuf:"bytes,1,rep,name=specs,proto3" json:"specs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSpecsResponse) Reset() {
+	*x = ListSpecsResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSpecsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSpecsResponse) ProtoMessage() {}
+
+func (x *ListSpecsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSpecsResponse.ProtoReflect.Descriptor instead.
+func (*ListSpecsResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListSpecsResponse) GetSpecs() []*Spec {
+	if x != nil {
+		return x.Specs
+	}
+	return nil
+}
+
+type Epic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShortId       string                 `protobuf:"bytes,2,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`
+	Project       string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	SpecId        string                 `protobuf:"bytes,4,opt,name=spec_id,json=specId,proto3" json:"spec_id,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Version       int64                  `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Epic) Reset() {
+	*x = Epic{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Epic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Epic) ProtoMessage() {}
+
+func (x *Epic) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Epic.ProtoReflect.Descriptor instead.
+func (*Epic) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Epic) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Epic) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *Epic) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Epic) GetSpecId() string {
+	if x != nil {
+		return x.SpecId
+	}
+	return ""
+}
+
+func (x *Epic) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Epic) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Epic) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Epic) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Epic) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Epic) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListEpicsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	SpecId        string                 `protobuf:"bytes,2,opt,name=spec_id,json=specId,proto3" json:"spec_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEpicsRequest) Reset() {
+	*x = ListEpicsRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEpicsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEpicsRequest) ProtoMessage() {}
+
+func (x *ListEpicsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEpicsRequest.ProtoReflect.Descriptor instead.
+func (*ListEpicsRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListEpicsRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *ListEpicsRequest) GetSpecId() string {
+	if x != nil {
+		return x.SpecId
+	}
+	return ""
+}
+
+type ListEpicsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Epics         []*Epic                `protobuf:"bytes,1,rep,name=epics,proto3" json:"epics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEpicsResponse) Reset() {
+	*x = ListEpicsResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEpicsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEpicsResponse) ProtoMessage() {}
+
+func (x *ListEpicsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEpicsResponse.ProtoReflect.Descriptor instead.
+func (*ListEpicsResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListEpicsResponse) GetEpics() []*Epic {
+	if x != nil {
+		return x.Epics
+	}
+	return nil
+}
+
+type Story struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShortId            string                 `protobuf:"bytes,2,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`
+	Project            string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	EpicId             string                 `protobuf:"bytes,4,opt,name=epic_id,json=epicId,proto3" json:"epic_id,omitempty"`
+	Title              string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	AcceptanceCriteria []string               `protobuf:"bytes,6,rep,name=acceptance_criteria,json=acceptanceCriteria,proto3" json:"acceptance_criteria,omitempty"`
+	Status             string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Priority           string                 `protobuf:"bytes,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	Version            int64                  `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt          *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt          *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Order within the epic; ListStories sorts by it. Set with PUT
+	// /api/stories/{id}/rank.
+	Rank          string `protobuf:"bytes,12,opt,name=rank,proto3" json:"rank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Story) Reset() {
+	*x = Story{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Story) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Story) ProtoMessage() {}
+
+func (x *Story) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Story.ProtoReflect.Descriptor instead.
+func (*Story) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Story) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Story) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *Story) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Story) GetEpicId() string {
+	if x != nil {
+		return x.EpicId
+	}
+	return ""
+}
+
+func (x *Story) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Story) GetAcceptanceCriteria() []string {
+	if x != nil {
+		return x.AcceptanceCriteria
+	}
+	return nil
+}
+
+func (x *Story) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Story) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Story) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Story) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Story) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Story) GetRank() string {
+	if x != nil {
+		return x.Rank
+	}
+	return ""
+}
+
+type ListStoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	EpicId        string                 `protobuf:"bytes,2,opt,name=epic_id,json=epicId,proto3" json:"epic_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStoriesRequest) Reset() {
+	*x = ListStoriesRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStoriesRequest) ProtoMessage() {}
+
+func (x *ListStoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStoriesRequest.ProtoReflect.Descriptor instead.
+func (*ListStoriesRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListStoriesRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *ListStoriesRequest) GetEpicId() string {
+	if x != nil {
+		return x.EpicId
+	}
+	return ""
+}
+
+type ListStoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stories       []*Story               `protobuf:"bytes,1,rep,name=stories,proto3" json:"stories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStoriesResponse) Reset() {
+	*x = ListStoriesResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStoriesResponse) ProtoMessage() {}
+
+func (x *ListStoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStoriesResponse.ProtoReflect.Descriptor instead.
+func (*ListStoriesResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListStoriesResponse) GetStories() []*Story {
+	if x != nil {
+		return x.Stories
+	}
+	return nil
+}
+
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShortId       string                 `protobuf:"bytes,2,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`
+	Project       string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	StoryId       string                 `protobuf:"bytes,4,opt,name=story_id,json=storyId,proto3" json:"story_id,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Agent         string                 `protobuf:"bytes,6,opt,name=agent,proto3" json:"agent,omitempty"`
+	SessionId     string                 `protobuf:"bytes,7,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	Priority      string                 `protobuf:"bytes,9,opt,name=priority,proto3" json:"priority,omitempty"`
+	DueAt         *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,11,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	ExpectedPaths []string               `protobuf:"bytes,12,rep,name=expected_paths,json=expectedPaths,proto3" json:"expected_paths,omitempty"`
+	Version       int64                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	BlockedReason string                 `protobuf:"bytes,16,opt,name=blocked_reason,json=blockedReason,proto3" json:"blocked_reason,omitempty"`
+	BlockedDetail string                 `protobuf:"bytes,17,opt,name=blocked_detail,json=blockedDetail,proto3" json:"blocked_detail,omitempty"`
+	UnblockWhen   *UnblockCondition      `protobuf:"bytes,18,opt,name=unblock_when,json=unblockWhen,proto3" json:"unblock_when,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *Task) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Task) GetStoryId() string {
+	if x != nil {
+		return x.StoryId
+	}
+	return ""
+}
+
+func (x *Task) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Task) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *Task) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Task) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Task) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Task) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *Task) GetExpectedPaths() []string {
+	if x != nil {
+		return x.ExpectedPaths
+	}
+	return nil
+}
+
+func (x *Task) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetBlockedReason() string {
+	if x != nil {
+		return x.BlockedReason
+	}
+	return ""
+}
+
+func (x *Task) GetBlockedDetail() string {
+	if x != nil {
+		return x.BlockedDetail
+	}
+	return ""
+}
+
+func (x *Task) GetUnblockWhen() *UnblockCondition {
+	if x != nil {
+		return x.UnblockWhen
+	}
+	return nil
+}
+
+// UnblockCondition is what a blocked task waits for; exactly one field is
+// set.
+type UnblockCondition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	PathPattern   string                 `protobuf:"bytes,2,opt,name=path_pattern,json=pathPattern,proto3" json:"path_pattern,omitempty"`
+	ExternalRef   string                 `protobuf:"bytes,3,opt,name=external_ref,json=externalRef,proto3" json:"external_ref,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnblockCondition) Reset() {
+	*x = UnblockCondition{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnblockCondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnblockCondition) ProtoMessage() {}
+
+func (x *UnblockCondition) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnblockCondition.ProtoReflect.Descriptor instead.
+func (*UnblockCondition) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UnblockCondition) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *UnblockCondition) GetPathPattern() string {
+	if x != nil {
+		return x.PathPattern
+	}
+	return ""
+}
+
+func (x *UnblockCondition) GetExternalRef() string {
+	if x != nil {
+		return x.ExternalRef
+	}
+	return ""
+}
+
+type ListTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Agent         string                 `protobuf:"bytes,3,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListTasksRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+type ListTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Project string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	// Receive events addressed to this agent as well as project-wide ones.
+	// Empty receives project-wide events only.
+	Agent string `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	// Globs over the event type, as in the WebSocket subscribe frame
+	// ("task.*"; "*" does not cross a "."). Empty receives every type.
+	Events []string `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	// Only events about these entities. Empty receives every entity.
+	EntityIds     []string `protobuf:"bytes,4,rep,name=entity_ids,json=entityIds,proto3" json:"entity_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SubscribeRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetEntityIds() []string {
+	if x != nil {
+		return x.EntityIds
+	}
+	return nil
+}
+
+type Event struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Type     string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	EventId  string                 `protobuf:"bytes,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Project  string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	EntityId string                 `protobuf:"bytes,4,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	// The full event as the WebSocket would deliver it.
+	Payload       *structpb.Struct `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_intermute_v1_intermute_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_intermute_v1_intermute_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_intermute_v1_intermute_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *Event) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Event) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+var File_intermute_v1_intermute_proto protoreflect.FileDescriptor
+
+const file_intermute_v1_intermute_proto_rawDesc = "" +
+	"\n" +
+	"\x1cintermute/v1/intermute.proto\x12\fintermute.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xaa\x02\n" +
+	"\x14RegisterAgentRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\"\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\x12L\n" +
+	"\bmetadata\x18\x05 \x03(\v20.intermute.v1.RegisterAgentRequest.MetadataEntryR\bmetadata\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x01\n" +
+	"\x15RegisterAgentResponse\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
+	"\x05token\x18\x04 \x01(\tR\x05token\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\x04R\x06cursor\"N\n" +
+	"\x10HeartbeatRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vfocus_state\x18\x02 \x01(\tR\n" +
+	"focusState\".\n" +
+	"\x11HeartbeatResponse\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"Q\n" +
+	"\x11ListAgentsRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\"\n" +
+	"\fcapabilities\x18\x02 \x03(\tR\fcapabilities\"\x9b\x03\n" +
+	"\x05Agent\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aproject\x18\x04 \x01(\tR\aproject\x12\"\n" +
+	"\fcapabilities\x18\x05 \x03(\tR\fcapabilities\x12=\n" +
+	"\bmetadata\x18\x06 \x03(\v2!.intermute.v1.Agent.MetadataEntryR\bmetadata\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x127\n" +
+	"\tlast_seen\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"A\n" +
+	"\x12ListAgentsResponse\x12+\n" +
+	"\x06agents\x18\x01 \x03(\v2\x13.intermute.v1.AgentR\x06agents\"\x94\x03\n" +
+	"\x12SendMessageRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tthread_id\x18\x02 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x03(\tR\x02to\x12\x0e\n" +
+	"\x02cc\x18\x06 \x03(\tR\x02cc\x12\x10\n" +
+	"\x03bcc\x18\a \x03(\tR\x03bcc\x12\x18\n" +
+	"\asubject\x18\b \x01(\tR\asubject\x12\x14\n" +
+	"\x05topic\x18\t \x01(\tR\x05topic\x12\x1e\n" +
+	"\vin_reply_to\x18\n" +
+	" \x01(\tR\tinReplyTo\x12\x12\n" +
+	"\x04body\x18\v \x01(\tR\x04body\x12\x1e\n" +
+	"\n" +
+	"importance\x18\f \x01(\tR\n" +
+	"importance\x12\x1c\n" +
+	"\ttransport\x18\r \x01(\tR\ttransport\x12,\n" +
+	"\x12target_window_uuid\x18\x0e \x01(\tR\x10targetWindowUuid\x12!\n" +
+	"\fack_required\x18\x0f \x01(\bR\vackRequired\"d\n" +
+	"\x13SendMessageResponse\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\x04R\x06cursor\x12\x16\n" +
+	"\x06denied\x18\x03 \x03(\tR\x06denied\"\x90\x03\n" +
+	"\aMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tthread_id\x18\x02 \x01(\tR\bthreadId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x03(\tR\x02to\x12\x0e\n" +
+	"\x02cc\x18\x06 \x03(\tR\x02cc\x12\x10\n" +
+	"\x03bcc\x18\a \x03(\tR\x03bcc\x12\x18\n" +
+	"\asubject\x18\b \x01(\tR\asubject\x12\x14\n" +
+	"\x05topic\x18\t \x01(\tR\x05topic\x12\x1e\n" +
+	"\vin_reply_to\x18\n" +
+	" \x01(\tR\tinReplyTo\x12\x12\n" +
+	"\x04body\x18\v \x01(\tR\x04body\x12\x1e\n" +
+	"\n" +
+	"importance\x18\f \x01(\tR\n" +
+	"importance\x12!\n" +
+	"\fack_required\x18\r \x01(\bR\vackRequired\x129\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06cursor\x18\x0f \x01(\x04R\x06cursor\"\x9a\x01\n" +
+	"\fInboxRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\tR\x05agent\x12!\n" +
+	"\fsince_cursor\x18\x03 \x01(\x04R\vsinceCursor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12!\n" +
+	"\fwait_seconds\x18\x05 \x01(\x05R\vwaitSeconds\"Z\n" +
+	"\rInboxResponse\x121\n" +
+	"\bmessages\x18\x01 \x03(\v2\x15.intermute.v1.MessageR\bmessages\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\x04R\x06cursor\"g\n" +
+	"\x12StreamInboxRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\tR\x05agent\x12!\n" +
+	"\fsince_cursor\x18\x03 \x01(\x04R\vsinceCursor\"e\n" +
+	"\x14MessageActionRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05agent\x18\x03 \x01(\tR\x05agent\"6\n" +
+	"\n" +
+	"GetRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"9\n" +
+	"\rDeleteRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xd1\x02\n" +
+	"\x04Spec\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bshort_id\x18\x02 \x01(\tR\ashortId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x16\n" +
+	"\x06vision\x18\x05 \x01(\tR\x06vision\x12\x14\n" +
+	"\x05users\x18\x06 \x01(\tR\x05users\x12\x18\n" +
+	"\aproblem\x18\a \x01(\tR\aproblem\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\t \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"D\n" +
+	"\x10ListSpecsRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"=\n" +
+	"\x11ListSpecsResponse\x12(\n" +
+	"\x05specs\x18\x01 \x03(\v2\x12.intermute.v1.SpecR\x05specs\"\xc4\x02\n" +
+	"\x04Epic\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bshort_id\x18\x02 \x01(\tR\ashortId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x17\n" +
+	"\aspec_id\x18\x04 \x01(\tR\x06specId\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\b \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"E\n" +
+	"\x10ListEpicsRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x17\n" +
+	"\aspec_id\x18\x02 \x01(\tR\x06specId\"=\n" +
+	"\x11ListEpicsResponse\x12(\n" +
+	"\x05epics\x18\x01 \x03(\v2\x12.intermute.v1.EpicR\x05epics\"\x84\x03\n" +
+	"\x05Story\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bshort_id\x18\x02 \x01(\tR\ashortId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x17\n" +
+	"\aepic_id\x18\x04 \x01(\tR\x06epicId\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12/\n" +
+	"\x13acceptance_criteria\x18\x06 \x03(\tR\x12acceptanceCriteria\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\tR\bpriority\x12\x18\n" +
+	"\aversion\x18\t \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04rank\x18\f \x01(\tR\x04rank\"G\n" +
+	"\x12ListStoriesRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x17\n" +
+	"\aepic_id\x18\x02 \x01(\tR\x06epicId\"D\n" +
+	"\x13ListStoriesResponse\x12-\n" +
+	"\astories\x18\x01 \x03(\v2\x13.intermute.v1.StoryR\astories\"\x84\x05\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bshort_id\x18\x02 \x01(\tR\ashortId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x19\n" +
+	"\bstory_id\x18\x04 \x01(\tR\astoryId\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12\x14\n" +
+	"\x05agent\x18\x06 \x01(\tR\x05agent\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\a \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\t \x01(\tR\bpriority\x121\n" +
+	"\x06due_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x12\"\n" +
+	"\fcapabilities\x18\v \x03(\tR\fcapabilities\x12%\n" +
+	"\x0eexpected_paths\x18\f \x03(\tR\rexpectedPaths\x12\x18\n" +
+	"\aversion\x18\r \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12%\n" +
+	"\x0eblocked_reason\x18\x10 \x01(\tR\rblockedReason\x12%\n" +
+	"\x0eblocked_detail\x18\x11 \x01(\tR\rblockedDetail\x12A\n" +
+	"\funblock_when\x18\x12 \x01(\v2\x1e.intermute.v1.UnblockConditionR\vunblockWhen\"q\n" +
+	"\x10UnblockCondition\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12!\n" +
+	"\fpath_pattern\x18\x02 \x01(\tR\vpathPattern\x12!\n" +
+	"\fexternal_ref\x18\x03 \x01(\tR\vexternalRef\"Z\n" +
+	"\x10ListTasksRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05agent\x18\x03 \x01(\tR\x05agent\"=\n" +
+	"\x11ListTasksResponse\x12(\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x12.intermute.v1.TaskR\x05tasks\"y\n" +
+	"\x10SubscribeRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\tR\x05agent\x12\x16\n" +
+	"\x06events\x18\x03 \x03(\tR\x06events\x12\x1d\n" +
+	"\n" +
+	"entity_ids\x18\x04 \x03(\tR\tentityIds\"\xa0\x01\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\tR\aeventId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x1b\n" +
+	"\tentity_id\x18\x04 \x01(\tR\bentityId\x121\n" +
+	"\apayload\x18\x05 \x01(\v2\x17.google.protobuf.StructR\apayload2\xef\x04\n" +
+	"\tMessaging\x12X\n" +
+	"\rRegisterAgent\x12\".intermute.v1.RegisterAgentRequest\x1a#.intermute.v1.RegisterAgentResponse\x12L\n" +
+	"\tHeartbeat\x12\x1e.intermute.v1.HeartbeatRequest\x1a\x1f.intermute.v1.HeartbeatResponse\x12O\n" +
+	"\n" +
+	"ListAgents\x12\x1f.intermute.v1.ListAgentsRequest\x1a .intermute.v1.ListAgentsResponse\x12R\n" +
+	"\vSendMessage\x12 .intermute.v1.SendMessageRequest\x1a!.intermute.v1.SendMessageResponse\x12@\n" +
+	"\x05Inbox\x12\x1a.intermute.v1.InboxRequest\x1a\x1b.intermute.v1.InboxResponse\x12H\n" +
+	"\vStreamInbox\x12 .intermute.v1.StreamInboxRequest\x1a\x15.intermute.v1.Message0\x01\x12F\n" +
+	"\bMarkRead\x12\".intermute.v1.MessageActionRequest\x1a\x16.google.protobuf.Empty\x12A\n" +
+	"\x03Ack\x12\".intermute.v1.MessageActionRequest\x1a\x16.google.protobuf.Empty2\xef\t\n" +
+	"\x06Domain\x124\n" +
+	"\n" +
+	"CreateSpec\x12\x12.intermute.v1.Spec\x1a\x12.intermute.v1.Spec\x127\n" +
+	"\aGetSpec\x12\x18.intermute.v1.GetRequest\x1a\x12.intermute.v1.Spec\x12L\n" +
+	"\tListSpecs\x12\x1e.intermute.v1.ListSpecsRequest\x1a\x1f.intermute.v1.ListSpecsResponse\x124\n" +
+	"\n" +
+	"UpdateSpec\x12\x12.intermute.v1.Spec\x1a\x12.intermute.v1.Spec\x12A\n" +
+	"\n" +
+	"DeleteSpec\x12\x1b.intermute.v1.DeleteRequest\x1a\x16.google.protobuf.Empty\x124\n" +
+	"\n" +
+	"CreateEpic\x12\x12.intermute.v1.Epic\x1a\x12.intermute.v1.Epic\x127\n" +
+	"\aGetEpic\x12\x18.intermute.v1.GetRequest\x1a\x12.intermute.v1.Epic\x12L\n" +
+	"\tListEpics\x12\x1e.intermute.v1.ListEpicsRequest\x1a\x1f.intermute.v1.ListEpicsResponse\x124\n" +
+	"\n" +
+	"UpdateEpic\x12\x12.intermute.v1.Epic\x1a\x12.intermute.v1.Epic\x12A\n" +
+	"\n" +
+	"DeleteEpic\x12\x1b.intermute.v1.DeleteRequest\x1a\x16.google.protobuf.Empty\x127\n" +
+	"\vCreateStory\x12\x13.intermute.v1.Story\x1a\x13.intermute.v1.Story\x129\n" +
+	"\bGetStory\x12\x18.intermute.v1.GetRequest\x1a\x13.intermute.v1.Story\x12R\n" +
+	"\vListStories\x12 .intermute.v1.ListStoriesRequest\x1a!.intermute.v1.ListStoriesResponse\x127\n" +
+	"\vUpdateStory\x12\x13.intermute.v1.Story\x1a\x13.intermute.v1.Story\x12B\n" +
+	"\vDeleteStory\x12\x1b.intermute.v1.DeleteRequest\x1a\x16.google.protobuf.Empty\x124\n" +
+	"\n" +
+	"CreateTask\x12\x12.intermute.v1.Task\x1a\x12.intermute.v1.Task\x127\n" +
+	"\aGetTask\x12\x18.intermute.v1.GetRequest\x1a\x12.intermute.v1.Task\x12L\n" +
+	"\tListTasks\x12\x1e.intermute.v1.ListTasksRequest\x1a\x1f.intermute.v1.ListTasksResponse\x124\n" +
+	"\n" +
+	"UpdateTask\x12\x12.intermute.v1.Task\x1a\x12.intermute.v1.Task\x12A\n" +
+	"\n" +
+	"DeleteTask\x12\x1b.intermute.v1.DeleteRequest\x1a\x16.google.protobuf.Empty2L\n" +
+	"\x06Events\x12B\n" +
+	"\tSubscribe\x12\x1e.intermute.v1.SubscribeRequest\x1a\x13.intermute.v1.Event0\x01B6Z4github.com/mistakeknot/intermute/internal/grpc/pb;pbb\x06proto3"
+
+var (
+	file_intermute_v1_intermute_proto_rawDescOnce sync.Once
+	file_intermute_v1_intermute_proto_rawDescData []byte
+)
+
+func file_intermute_v1_intermute_proto_rawDescGZIP() []byte {
+	file_intermute_v1_intermute_proto_rawDescOnce.Do(func() {
+		file_intermute_v1_intermute_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_intermute_v1_intermute_proto_rawDesc), len(file_intermute_v1_intermute_proto_rawDesc)))
+	})
+	return file_intermute_v1_intermute_proto_rawDescData
+}
+
+var file_intermute_v1_intermute_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_intermute_v1_intermute_proto_goTypes = []any{
+	(*RegisterAgentRequest)(nil),  // 0: intermute.v1.RegisterAgentRequest
+	(*RegisterAgentResponse)(nil), // 1: intermute.v1.RegisterAgentResponse
+	(*HeartbeatRequest)(nil),      // 2: intermute.v1.HeartbeatRequest
+	(*HeartbeatResponse)(nil),     // 3: intermute.v1.HeartbeatResponse
+	(*ListAgentsRequest)(nil),     // 4: intermute.v1.ListAgentsRequest
+	(*Agent)(nil),                 // 5: intermute.v1.Agent
+	(*ListAgentsResponse)(nil),    // 6: intermute.v1.ListAgentsResponse
+	(*SendMessageRequest)(nil),    // 7: intermute.v1.SendMessageRequest
+	(*SendMessageResponse)(nil),   // 8: intermute.v1.SendMessageResponse
+	(*Message)(nil),               // 9: intermute.v1.Message
+	(*InboxRequest)(nil),          // 10: intermute.v1.InboxRequest
+	(*InboxResponse)(nil),         // 11: intermute.v1.InboxResponse
+	(*StreamInboxRequest)(nil),    // 12: intermute.v1.StreamInboxRequest
+	(*MessageActionRequest)(nil),  // 13: intermute.v1.MessageActionRequest
+	(*GetRequest)(nil),            // 14: intermute.v1.GetRequest
+	(*DeleteRequest)(nil),         // 15: intermute.v1.DeleteRequest
+	(*Spec)(nil),                  // 16: intermute.v1.Spec
+	(*ListSpecsRequest)(nil),      // 17: intermute.v1.ListSpecsRequest
+	(*ListSpecsResponse)(nil),     // 18: intermute.v1.ListSpecsResponse
+	(*Epic)(nil),                  // 19: intermute.v1.Epic
+	(*ListEpicsRequest)(nil),      // 20: intermute.v1.ListEpicsRequest
+	(*ListEpicsResponse)(nil),     // 21: intermute.v1.ListEpicsResponse
+	(*Story)(nil),                 // 22: intermute.v1.Story
+	(*ListStoriesRequest)(nil),    // 23: intermute.v1.ListStoriesRequest
+	(*ListStoriesResponse)(nil),   // 24: intermute.v1.ListStoriesResponse
+	(*Task)(nil),                  // 25: intermute.v1.Task
+	(*UnblockCondition)(nil),      // 26: intermute.v1.UnblockCondition
+	(*ListTasksRequest)(nil),      // 27: intermute.v1.ListTasksRequest
+	(*ListTasksResponse)(nil),     // 28: intermute.v1.ListTasksResponse
+	(*SubscribeRequest)(nil),      // 29: intermute.v1.SubscribeRequest
+	(*Event)(nil),                 // 30: intermute.v1.Event
+	nil,                           // 31: intermute.v1.RegisterAgentRequest.MetadataEntry
+	nil,                           // 32: intermute.v1.Agent.MetadataEntry
+	(*timestamppb.Timestamp)(nil), // 33: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 34: google.protobuf.Struct
+	(*emptypb.Empty)(nil),         // 35: google.protobuf.Empty
+}
+var file_intermute_v1_intermute_proto_depIdxs = []int32{
+	31, // 0: intermute.v1.RegisterAgentRequest.metadata:type_name -> intermute.v1.RegisterAgentRequest.MetadataEntry
+	32, // 1: intermute.v1.Agent.metadata:type_name -> intermute.v1.Agent.MetadataEntry
+	33, // 2: intermute.v1.Agent.last_seen:type_name -> google.protobuf.Timestamp
+	33, // 3: intermute.v1.Agent.created_at:type_name -> google.protobuf.Timestamp
+	5,  // 4: intermute.v1.ListAgentsResponse.agents:type_name -> intermute.v1.Agent
+	33, // 5: intermute.v1.Message.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 6: intermute.v1.InboxResponse.messages:type_name -> intermute.v1.Message
+	33, // 7: intermute.v1.Spec.created_at:type_name -> google.protobuf.Timestamp
+	33, // 8: intermute.v1.Spec.updated_at:type_name -> google.protobuf.Timestamp
+	16, // 9: intermute.v1.ListSpecsResponse.specs:type_name -> intermute.v1.Spec
+	33, // 10: intermute.v1.Epic.created_at:type_name -> google.protobuf.Timestamp
+	33, // 11: intermute.v1.Epic.updated_at:type_name -> google.protobuf.Timestamp
+	19, // 12: intermute.v1.ListEpicsResponse.epics:type_name -> intermute.v1.Epic
+	33, // 13: intermute.v1.Story.created_at:type_name -> google.protobuf.Timestamp
+	33, // 14: intermute.v1.Story.updated_at:type_name -> google.protobuf.Timestamp
+	22, // 15: intermute.v1.ListStoriesResponse.stories:type_name -> intermute.v1.Story
+	33, // 16: intermute.v1.Task.due_at:type_name -> google.protobuf.Timestamp
+	33, // 17: intermute.v1.Task.created_at:type_name -> google.protobuf.Timestamp
+	33, // 18: intermute.v1.Task.updated_at:type_name -> google.protobuf.Timestamp
+	26, // 19: intermute.v1.Task.unblock_when:type_name -> intermute.v1.UnblockCondition
+	25, // 20: intermute.v1.ListTasksResponse.tasks:type_name -> intermute.v1.Task
+	34, // 21: intermute.v1.Event.payload:type_name -> google.protobuf.Struct
+	0,  // 22: intermute.v1.Messaging.RegisterAgent:input_type -> intermute.v1.RegisterAgentRequest
+	2,  // 23: intermute.v1.Messaging.Heartbeat:input_type -> intermute.v1.HeartbeatRequest
+	4,  // 24: intermute.v1.Messaging.ListAgents:input_type -> intermute.v1.ListAgentsRequest
+	7,  // 25: intermute.v1.Messaging.SendMessage:input_type -> intermute.v1.SendMessageRequest
+	10, // 26: intermute.v1.Messaging.Inbox:input_type -> intermute.v1.InboxRequest
+	12, // 27: intermute.v1.Messaging.StreamInbox:input_type -> intermute.v1.StreamInboxRequest
+	13, // 28: intermute.v1.Messaging.MarkRead:input_type -> intermute.v1.MessageActionRequest
+	13, // 29: intermute.v1.Messaging.Ack:input_type -> intermute.v1.MessageActionRequest
+	16, // 30: intermute.v1.Domain.CreateSpec:input_type -> intermute.v1.Spec
+	14, // 31: intermute.v1.Domain.GetSpec:input_type -> intermute.v1.GetRequest
+	17, // 32: intermute.v1.Domain.ListSpecs:input_type -> intermute.v1.ListSpecsRequest
+	16, // 33: intermute.v1.Domain.UpdateSpec:input_type -> intermute.v1.Spec
+	15, // 34: intermute.v1.Domain.DeleteSpec:input_type -> intermute.v1.DeleteRequest
+	19, // 35: intermute.v1.Domain.CreateEpic:input_type -> intermute.v1.Epic
+	14, // 36: intermute.v1.Domain.GetEpic:input_type -> intermute.v1.GetRequest
+	20, // 37: intermute.v1.Domain.ListEpics:input_type -> intermute.v1.ListEpicsRequest
+	19, // 38: intermute.v1.Domain.UpdateEpic:input_type -> intermute.v1.Epic
+	15, // 39: intermute.v1.Domain.DeleteEpic:input_type -> intermute.v1.DeleteRequest
+	22, // 40: intermute.v1.Domain.CreateStory:input_type -> intermute.v1.Story
+	14, // 41: intermute.v1.Domain.GetStory:input_type -> intermute.v1.GetRequest
+	23, // 42: intermute.v1.Domain.ListStories:input_type -> intermute.v1.ListStoriesRequest
+	22, // 43: intermute.v1.Domain.UpdateStory:input_type -> intermute.v1.Story
+	15, // 44: intermute.v1.Domain.DeleteStory:input_type -> intermute.v1.DeleteRequest
+	25, // 45: intermute.v1.Domain.CreateTask:input_type -> intermute.v1.Task
+	14, // 46: intermute.v1.Domain.GetTask:input_type -> intermute.v1.GetRequest
+	27, // 47: intermute.v1.Domain.ListTasks:input_type -> intermute.v1.ListTasksRequest
+	25, // 48: intermute.v1.Domain.UpdateTask:input_type -> intermute.v1.Task
+	15, // 49: intermute.v1.Domain.DeleteTask:input_type -> intermute.v1.DeleteRequest
+	29, // 50: intermute.v1.Events.Subscribe:input_type -> intermute.v1.SubscribeRequest
+	1,  // 51: intermute.v1.Messaging.RegisterAgent:output_type -> intermute.v1.RegisterAgentResponse
+	3,  // 52: intermute.v1.Messaging.Heartbeat:output_type -> intermute.v1.HeartbeatResponse
+	6,  // 53: intermute.v1.Messaging.ListAgents:output_type -> intermute.v1.ListAgentsResponse
+	8,  // 54: intermute.v1.Messaging.SendMessage:output_type -> intermute.v1.SendMessageResponse
+	11, // 55: intermute.v1.Messaging.Inbox:output_type -> intermute.v1.InboxResponse
+	9,  // 56: intermute.v1.Messaging.StreamInbox:output_type -> intermute.v1.Message
+	35, // 57: intermute.v1.Messaging.MarkRead:output_type -> google.protobuf.Empty
+	35, // 58: intermute.v1.Messaging.Ack:output_type -> google.protobuf.Empty
+	16, // 59: intermute.v1.Domain.CreateSpec:output_type -> intermute.v1.Spec
+	16, // 60: intermute.v1.Domain.GetSpec:output_type -> intermute.v1.Spec
+	18, // 61: intermute.v1.Domain.ListSpecs:output_type -> intermute.v1.ListSpecsResponse
+	16, // 62: intermute.v1.Domain.UpdateSpec:output_type -> intermute.v1.Spec
+	35, // 63: intermute.v1.Domain.DeleteSpec:output_type -> google.protobuf.Empty
+	19, // 64: intermute.v1.Domain.CreateEpic:output_type -> intermute.v1.Epic
+	19, // 65: intermute.v1.Domain.GetEpic:output_type -> intermute.v1.Epic
+	21, // 66: intermute.v1.Domain.ListEpics:output_type -> intermute.v1.ListEpicsResponse
+	19, // 67: intermute.v1.Domain.UpdateEpic:output_type -> intermute.v1.Epic
+	35, // 68: intermute.v1.Domain.DeleteEpic:output_type -> google.protobuf.Empty
+	22, // 69: intermute.v1.Domain.CreateStory:output_type -> intermute.v1.Story
+	22, // 70: intermute.v1.Domain.GetStory:output_type -> intermute.v1.Story
+	24, // 71: intermute.v1.Domain.ListStories:output_type -> intermute.v1.ListStoriesResponse
+	22, // 72: intermute.v1.Domain.UpdateStory:output_type -> intermute.v1.Story
+	35, // 73: intermute.v1.Domain.DeleteStory:output_type -> google.protobuf.Empty
+	25, // 74: intermute.v1.Domain.CreateTask:output_type -> intermute.v1.Task
+	25, // 75: intermute.v1.Domain.GetTask:output_type -> intermute.v1.Task
+	28, // 76: intermute.v1.Domain.ListTasks:output_type -> intermute.v1.ListTasksResponse
+	25, // 77: intermute.v1.Domain.UpdateTask:output_type -> intermute.v1.Task
+	35, // 78: intermute.v1.Domain.DeleteTask:output_type -> google.protobuf.Empty
+	30, // 79: intermute.v1.Events.Subscribe:output_type -> intermute.v1.Event
+	51, // [51:80] is the sub-list for method output_type
+	22, // [22:51] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_intermute_v1_intermute_proto_init() }
+func file_intermute_v1_intermute_proto_init() {
+	if File_intermute_v1_intermute_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_intermute_v1_intermute_proto_rawDesc), len(file_intermute_v1_intermute_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   33,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_intermute_v1_intermute_proto_goTypes,
+		DependencyIndexes: file_intermute_v1_intermute_proto_depIdxs,
+		MessageInfos:      file_intermute_v1_intermute_proto_msgTypes,
+	}.Build()
+	File_intermute_v1_intermute_proto = out.File
+	file_intermute_v1_intermute_proto_goTypes = nil
+	file_intermute_v1_intermute_proto_depIdxs = nil
+}
@@ -0,0 +1,1365 @@
+// gRPC surface of the intermute API. Every unary RPC maps onto one REST
+// endpoint and behaves exactly like it: same validation, auth, contact
+// policy and broadcasts. Field names match the REST JSON fields.
+//
+// Authenticate with the same credentials as over HTTP, sent as metadata:
+// "authorization: Bearer <key>", plus optional "x-agent-id" and
+// "x-agent-token". Calls from localhost need none.
+//
+// Regenerate the Go code with `go generate ./internal/grpc`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: intermute/v1/intermute.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Messaging_RegisterAgent_FullMethodName = "/intermute.v1.Messaging/RegisterAgent"
+	Messaging_Heartbeat_FullMethodName     = "/intermute.v1.Messaging/Heartbeat"
+	Messaging_ListAgents_FullMethodName    = "/intermute.v1.Messaging/ListAgents"
+	Messaging_SendMessage_FullMethodName   = "/intermute.v1.Messaging/SendMessage"
+	Messaging_Inbox_FullMethodName         = "/intermute.v1.Messaging/Inbox"
+	Messaging_StreamInbox_FullMethodName   = "/intermute.v1.Messaging/StreamInbox"
+	Messaging_MarkRead_FullMethodName      = "/intermute.v1.Messaging/MarkRead"
+	Messaging_Ack_FullMethodName           = "/intermute.v1.Messaging/Ack"
+)
+
+// MessagingClient is the client API for Messaging service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Messaging covers agent registration and the message inbox.
+type MessagingClient interface {
+	// POST /api/agents
+	RegisterAgent(ctx context.Context, in *RegisterAgentRequest, opts ...grpc.CallOption) (*RegisterAgentResponse, error)
+	// POST /api/agents/{agent_id}/heartbeat
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// GET /api/agents
+	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error)
+	// POST /api/messages
+	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
+	// GET /api/inbox/{agent}
+	Inbox(ctx context.Context, in *InboxRequest, opts ...grpc.CallOption) (*InboxResponse, error)
+	// Streams the agent's inbox from since_cursor on: first the backlog, then
+	// each new message as it arrives, until the client cancels.
+	StreamInbox(ctx context.Context, in *StreamInboxRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
+	// POST /api/messages/{message_id}/read
+	MarkRead(ctx context.Context, in *MessageActionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// POST /api/messages/{message_id}/ack
+	Ack(ctx context.Context, in *MessageActionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type messagingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMessagingClient(cc grpc.ClientConnInterface) MessagingClient {
+	return &messagingClient{cc}
+}
+
+func (c *messagingClient) RegisterAgent(ctx context.Context, in *RegisterAgentRequest, opts ...grpc.CallOption) (*RegisterAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterAgentResponse)
+	err := c.cc.Invoke(ctx, Messaging_RegisterAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, Messaging_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAgentsResponse)
+	err := c.cc.Invoke(ctx, Messaging_ListAgents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendMessageResponse)
+	err := c.cc.Invoke(ctx, Messaging_SendMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) Inbox(ctx context.Context, in *InboxRequest, opts ...grpc.CallOption) (*InboxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InboxResponse)
+	err := c.cc.Invoke(ctx, Messaging_Inbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) StreamInbox(ctx context.Context, in *StreamInboxRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Messaging_ServiceDesc.Streams[0], Messaging_StreamInbox_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamInboxRequest, Message]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Messaging_StreamInboxClient = grpc.ServerStreamingClient[Message]
+
+func (c *messagingClient) MarkRead(ctx context.Context, in *MessageActionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Messaging_MarkRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) Ack(ctx context.Context, in *MessageActionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Messaging_Ack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MessagingServer is the server API for Messaging service.
+// All implementations must embed UnimplementedMessagingServer
+// for forward compatibility.
+//
+// Messaging covers agent registration and the message inbox.
+type MessagingServer interface {
+	// POST /api/agents
+	RegisterAgent(context.Context, *RegisterAgentRequest) (*RegisterAgentResponse, error)
+	// POST /api/agents/{agent_id}/heartbeat
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// GET /api/agents
+	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error)
+	// POST /api/messages
+	SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
+	// GET /api/inbox/{agent}
+	Inbox(context.Context, *InboxRequest) (*InboxResponse, error)
+	// Streams the agent's inbox from since_cursor on: first the backlog, then
+	// each new message as it arrives, until the client cancels.
+	StreamInbox(*StreamInboxRequest, grpc.ServerStreamingServer[Message]) error
+	// POST /api/messages/{message_id}/read
+	MarkRead(context.Context, *MessageActionRequest) (*emptypb.Empty, error)
+	// POST /api/messages/{message_id}/ack
+	Ack(context.Context, *MessageActionRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedMessagingServer()
+}
+
+// UnimplementedMessagingServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMessagingServer struct{}
+
+func (UnimplementedMessagingServer) RegisterAgent(context.Context, *RegisterAgentRequest) (*RegisterAgentResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RegisterAgent not implemented")
+}
+func (UnimplementedMessagingServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedMessagingServer) ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (UnimplementedMessagingServer) SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendMessage not implemented")
+}
+func (UnimplementedMessagingServer) Inbox(context.Context, *InboxRequest) (*InboxResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Inbox not implemented")
+}
+func (UnimplementedMessagingServer) StreamInbox(*StreamInboxRequest, grpc.ServerStreamingServer[Message]) error {
+	return status.Error(codes.Unimplemented, "method StreamInbox not implemented")
+}
+func (UnimplementedMessagingServer) MarkRead(context.Context, *MessageActionRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method MarkRead not implemented")
+}
+func (UnimplementedMessagingServer) Ack(context.Context, *MessageActionRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ack not implemented")
+}
+func (UnimplementedMessagingServer) mustEmbedUnimplementedMessagingServer() {}
+func (UnimplementedMessagingServer) testEmbeddedByValue()                   {}
+
+// UnsafeMessagingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MessagingServer will
+// result in compilation errors.
+type UnsafeMessagingServer interface {
+	mustEmbedUnimplementedMessagingServer()
+}
+
+func RegisterMessagingServer(s grpc.ServiceRegistrar, srv MessagingServer) {
+	// If the following call panics, it indicates UnimplementedMessagingServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Messaging_ServiceDesc, srv)
+}
+
+func _Messaging_RegisterAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).RegisterAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_RegisterAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).RegisterAgent(ctx, req.(*RegisterAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_ListAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).ListAgents(ctx, req.(*ListAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_SendMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).SendMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_SendMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).SendMessage(ctx, req.(*SendMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_Inbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).Inbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_Inbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).Inbox(ctx, req.(*InboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_StreamInbox_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamInboxRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MessagingServer).StreamInbox(m, &grpc.GenericServerStream[StreamInboxRequest, Message]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Messaging_StreamInboxServer = grpc.ServerStreamingServer[Message]
+
+func _Messaging_MarkRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).MarkRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_MarkRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).MarkRead(ctx, req.(*MessageActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_Ack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).Ack(ctx, req.(*MessageActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Messaging_ServiceDesc is the grpc.ServiceDesc for Messaging service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Messaging_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "intermute.v1.Messaging",
+	HandlerType: (*MessagingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterAgent",
+			Handler:    _Messaging_RegisterAgent_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Messaging_Heartbeat_Handler,
+		},
+		{
+			MethodName: "ListAgents",
+			Handler:    _Messaging_ListAgents_Handler,
+		},
+		{
+			MethodName: "SendMessage",
+			Handler:    _Messaging_SendMessage_Handler,
+		},
+		{
+			MethodName: "Inbox",
+			Handler:    _Messaging_Inbox_Handler,
+		},
+		{
+			MethodName: "MarkRead",
+			Handler:    _Messaging_MarkRead_Handler,
+		},
+		{
+			MethodName: "Ack",
+			Handler:    _Messaging_Ack_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamInbox",
+			Handler:       _Messaging_StreamInbox_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "intermute/v1/intermute.proto",
+}
+
+const (
+	Domain_CreateSpec_FullMethodName  = "/intermute.v1.Domain/CreateSpec"
+	Domain_GetSpec_FullMethodName     = "/intermute.v1.Domain/GetSpec"
+	Domain_ListSpecs_FullMethodName   = "/intermute.v1.Domain/ListSpecs"
+	Domain_UpdateSpec_FullMethodName  = "/intermute.v1.Domain/UpdateSpec"
+	Domain_DeleteSpec_FullMethodName  = "/intermute.v1.Domain/DeleteSpec"
+	Domain_CreateEpic_FullMethodName  = "/intermute.v1.Domain/CreateEpic"
+	Domain_GetEpic_FullMethodName     = "/intermute.v1.Domain/GetEpic"
+	Domain_ListEpics_FullMethodName   = "/intermute.v1.Domain/ListEpics"
+	Domain_UpdateEpic_FullMethodName  = "/intermute.v1.Domain/UpdateEpic"
+	Domain_DeleteEpic_FullMethodName  = "/intermute.v1.Domain/DeleteEpic"
+	Domain_CreateStory_FullMethodName = "/intermute.v1.Domain/CreateStory"
+	Domain_GetStory_FullMethodName    = "/intermute.v1.Domain/GetStory"
+	Domain_ListStories_FullMethodName = "/intermute.v1.Domain/ListStories"
+	Domain_UpdateStory_FullMethodName = "/intermute.v1.Domain/UpdateStory"
+	Domain_DeleteStory_FullMethodName = "/intermute.v1.Domain/DeleteStory"
+	Domain_CreateTask_FullMethodName  = "/intermute.v1.Domain/CreateTask"
+	Domain_GetTask_FullMethodName     = "/intermute.v1.Domain/GetTask"
+	Domain_ListTasks_FullMethodName   = "/intermute.v1.Domain/ListTasks"
+	Domain_UpdateTask_FullMethodName  = "/intermute.v1.Domain/UpdateTask"
+	Domain_DeleteTask_FullMethodName  = "/intermute.v1.Domain/DeleteTask"
+)
+
+// DomainClient is the client API for Domain service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Domain is CRUD over specs, epics, stories and tasks (/api/specs,
+// /api/epics, /api/stories, /api/tasks). Get, update and delete accept
+// short IDs as the REST API does.
+type DomainClient interface {
+	CreateSpec(ctx context.Context, in *Spec, opts ...grpc.CallOption) (*Spec, error)
+	GetSpec(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Spec, error)
+	ListSpecs(ctx context.Context, in *ListSpecsRequest, opts ...grpc.CallOption) (*ListSpecsResponse, error)
+	UpdateSpec(ctx context.Context, in *Spec, opts ...grpc.CallOption) (*Spec, error)
+	DeleteSpec(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateEpic(ctx context.Context, in *Epic, opts ...grpc.CallOption) (*Epic, error)
+	GetEpic(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Epic, error)
+	ListEpics(ctx context.Context, in *ListEpicsRequest, opts ...grpc.CallOption) (*ListEpicsResponse, error)
+	UpdateEpic(ctx context.Context, in *Epic, opts ...grpc.CallOption) (*Epic, error)
+	DeleteEpic(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateStory(ctx context.Context, in *Story, opts ...grpc.CallOption) (*Story, error)
+	GetStory(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Story, error)
+	ListStories(ctx context.Context, in *ListStoriesRequest, opts ...grpc.CallOption) (*ListStoriesResponse, error)
+	UpdateStory(ctx context.Context, in *Story, opts ...grpc.CallOption) (*Story, error)
+	DeleteStory(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateTask(ctx context.Context, in *Task, opts ...grpc.CallOption) (*Task, error)
+	GetTask(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Task, error)
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error)
+	UpdateTask(ctx context.Context, in *Task, opts ...grpc.CallOption) (*Task, error)
+	DeleteTask(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type domainClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDomainClient(cc grpc.ClientConnInterface) DomainClient {
+	return &domainClient{cc}
+}
+
+func (c *domainClient) CreateSpec(ctx context.Context, in *Spec, opts ...grpc.CallOption) (*Spec, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Spec)
+	err := c.cc.Invoke(ctx, Domain_CreateSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) GetSpec(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Spec, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Spec)
+	err := c.cc.Invoke(ctx, Domain_GetSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) ListSpecs(ctx context.Context, in *ListSpecsRequest, opts ...grpc.CallOption) (*ListSpecsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSpecsResponse)
+	err := c.cc.Invoke(ctx, Domain_ListSpecs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) UpdateSpec(ctx context.Context, in *Spec, opts ...grpc.CallOption) (*Spec, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Spec)
+	err := c.cc.Invoke(ctx, Domain_UpdateSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) DeleteSpec(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Domain_DeleteSpec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) CreateEpic(ctx context.Context, in *Epic, opts ...grpc.CallOption) (*Epic, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Epic)
+	err := c.cc.Invoke(ctx, Domain_CreateEpic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) GetEpic(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Epic, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Epic)
+	err := c.cc.Invoke(ctx, Domain_GetEpic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) ListEpics(ctx context.Context, in *ListEpicsRequest, opts ...grpc.CallOption) (*ListEpicsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEpicsResponse)
+	err := c.cc.Invoke(ctx, Domain_ListEpics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) UpdateEpic(ctx context.Context, in *Epic, opts ...grpc.CallOption) (*Epic, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Epic)
+	err := c.cc.Invoke(ctx, Domain_UpdateEpic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) DeleteEpic(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Domain_DeleteEpic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) CreateStory(ctx context.Context, in *Story, opts ...grpc.CallOption) (*Story, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Story)
+	err := c.cc.Invoke(ctx, Domain_CreateStory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) GetStory(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Story, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Story)
+	err := c.cc.Invoke(ctx, Domain_GetStory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) ListStories(ctx context.Context, in *ListStoriesRequest, opts ...grpc.CallOption) (*ListStoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStoriesResponse)
+	err := c.cc.Invoke(ctx, Domain_ListStories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) UpdateStory(ctx context.Context, in *Story, opts ...grpc.CallOption) (*Story, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Story)
+	err := c.cc.Invoke(ctx, Domain_UpdateStory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) DeleteStory(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Domain_DeleteStory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) CreateTask(ctx context.Context, in *Task, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Domain_CreateTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) GetTask(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Domain_GetTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponse)
+	err := c.cc.Invoke(ctx, Domain_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) UpdateTask(ctx context.Context, in *Task, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Domain_UpdateTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *domainClient) DeleteTask(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Domain_DeleteTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DomainServer is the server API for Domain service.
+// All implementations must embed UnimplementedDomainServer
+// for forward compatibility.
+//
+// Domain is CRUD over specs, epics, stories and tasks (/api/specs,
+// /api/epics, /api/stories, /api/tasks). Get, update and delete accept
+// short IDs as the REST API does.
+type DomainServer interface {
+	CreateSpec(context.Context, *Spec) (*Spec, error)
+	GetSpec(context.Context, *GetRequest) (*Spec, error)
+	ListSpecs(context.Context, *ListSpecsRequest) (*ListSpecsResponse, error)
+	UpdateSpec(context.Context, *Spec) (*Spec, error)
+	DeleteSpec(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	CreateEpic(context.Context, *Epic) (*Epic, error)
+	GetEpic(context.Context, *GetRequest) (*Epic, error)
+	ListEpics(context.Context, *ListEpicsRequest) (*ListEpicsResponse, error)
+	UpdateEpic(context.Context, *Epic) (*Epic, error)
+	DeleteEpic(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	CreateStory(context.Context, *Story) (*Story, error)
+	GetStory(context.Context, *GetRequest) (*Story, error)
+	ListStories(context.Context, *ListStoriesRequest) (*ListStoriesResponse, error)
+	UpdateStory(context.Context, *Story) (*Story, error)
+	DeleteStory(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	CreateTask(context.Context, *Task) (*Task, error)
+	GetTask(context.Context, *GetRequest) (*Task, error)
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
+	UpdateTask(context.Context, *Task) (*Task, error)
+	DeleteTask(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedDomainServer()
+}
+
+// UnimplementedDomainServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDomainServer struct{}
+
+func (UnimplementedDomainServer) CreateSpec(context.Context, *Spec) (*Spec, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateSpec not implemented")
+}
+func (UnimplementedDomainServer) GetSpec(context.Context, *GetRequest) (*Spec, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSpec not implemented")
+}
+func (UnimplementedDomainServer) ListSpecs(context.Context, *ListSpecsRequest) (*ListSpecsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSpecs not implemented")
+}
+func (UnimplementedDomainServer) UpdateSpec(context.Context, *Spec) (*Spec, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateSpec not implemented")
+}
+func (UnimplementedDomainServer) DeleteSpec(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteSpec not implemented")
+}
+func (UnimplementedDomainServer) CreateEpic(context.Context, *Epic) (*Epic, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateEpic not implemented")
+}
+func (UnimplementedDomainServer) GetEpic(context.Context, *GetRequest) (*Epic, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEpic not implemented")
+}
+func (UnimplementedDomainServer) ListEpics(context.Context, *ListEpicsRequest) (*ListEpicsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListEpics not implemented")
+}
+func (UnimplementedDomainServer) UpdateEpic(context.Context, *Epic) (*Epic, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateEpic not implemented")
+}
+func (UnimplementedDomainServer) DeleteEpic(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteEpic not implemented")
+}
+func (UnimplementedDomainServer) CreateStory(context.Context, *Story) (*Story, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateStory not implemented")
+}
+func (UnimplementedDomainServer) GetStory(context.Context, *GetRequest) (*Story, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStory not implemented")
+}
+func (UnimplementedDomainServer) ListStories(context.Context, *ListStoriesRequest) (*ListStoriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListStories not implemented")
+}
+func (UnimplementedDomainServer) UpdateStory(context.Context, *Story) (*Story, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateStory not implemented")
+}
+func (UnimplementedDomainServer) DeleteStory(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteStory not implemented")
+}
+func (UnimplementedDomainServer) CreateTask(context.Context, *Task) (*Task, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTask not implemented")
+}
+func (UnimplementedDomainServer) GetTask(context.Context, *GetRequest) (*Task, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedDomainServer) ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedDomainServer) UpdateTask(context.Context, *Task) (*Task, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateTask not implemented")
+}
+func (UnimplementedDomainServer) DeleteTask(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTask not implemented")
+}
+func (UnimplementedDomainServer) mustEmbedUnimplementedDomainServer() {}
+func (UnimplementedDomainServer) testEmbeddedByValue()                {}
+
+// UnsafeDomainServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DomainServer will
+// result in compilation errors.
+type UnsafeDomainServer interface {
+	mustEmbedUnimplementedDomainServer()
+}
+
+func RegisterDomainServer(s grpc.ServiceRegistrar, srv DomainServer) {
+	// If the following call panics, it indicates UnimplementedDomainServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Domain_ServiceDesc, srv)
+}
+
+func _Domain_CreateSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Spec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).CreateSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_CreateSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).CreateSpec(ctx, req.(*Spec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_GetSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).GetSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_GetSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).GetSpec(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_ListSpecs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSpecsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).ListSpecs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_ListSpecs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).ListSpecs(ctx, req.(*ListSpecsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_UpdateSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Spec)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).UpdateSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_UpdateSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).UpdateSpec(ctx, req.(*Spec))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_DeleteSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).DeleteSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_DeleteSpec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).DeleteSpec(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_CreateEpic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Epic)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).CreateEpic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_CreateEpic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).CreateEpic(ctx, req.(*Epic))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_GetEpic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).GetEpic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_GetEpic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).GetEpic(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_ListEpics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEpicsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).ListEpics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_ListEpics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).ListEpics(ctx, req.(*ListEpicsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_UpdateEpic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Epic)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).UpdateEpic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_UpdateEpic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).UpdateEpic(ctx, req.(*Epic))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_DeleteEpic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).DeleteEpic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_DeleteEpic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).DeleteEpic(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_CreateStory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Story)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).CreateStory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_CreateStory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).CreateStory(ctx, req.(*Story))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_GetStory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).GetStory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_GetStory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).GetStory(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_ListStories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).ListStories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_ListStories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).ListStories(ctx, req.(*ListStoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_UpdateStory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Story)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).UpdateStory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_UpdateStory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).UpdateStory(ctx, req.(*Story))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_DeleteStory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).DeleteStory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_DeleteStory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).DeleteStory(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Task)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).CreateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_CreateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).CreateTask(ctx, req.(*Task))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).GetTask(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_UpdateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Task)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).UpdateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_UpdateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).UpdateTask(ctx, req.(*Task))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Domain_DeleteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DomainServer).DeleteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Domain_DeleteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DomainServer).DeleteTask(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Domain_ServiceDesc is the grpc.ServiceDesc for Domain service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Domain_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "intermute.v1.Domain",
+	HandlerType: (*DomainServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSpec",
+			Handler:    _Domain_CreateSpec_Handler,
+		},
+		{
+			MethodName: "GetSpec",
+			Handler:    _Domain_GetSpec_Handler,
+		},
+		{
+			MethodName: "ListSpecs",
+			Handler:    _Domain_ListSpecs_Handler,
+		},
+		{
+			MethodName: "UpdateSpec",
+			Handler:    _Domain_UpdateSpec_Handler,
+		},
+		{
+			MethodName: "DeleteSpec",
+			Handler:    _Domain_DeleteSpec_Handler,
+		},
+		{
+			MethodName: "CreateEpic",
+			Handler:    _Domain_CreateEpic_Handler,
+		},
+		{
+			MethodName: "GetEpic",
+			Handler:    _Domain_GetEpic_Handler,
+		},
+		{
+			MethodName: "ListEpics",
+			Handler:    _Domain_ListEpics_Handler,
+		},
+		{
+			MethodName: "UpdateEpic",
+			Handler:    _Domain_UpdateEpic_Handler,
+		},
+		{
+			MethodName: "DeleteEpic",
+			Handler:    _Domain_DeleteEpic_Handler,
+		},
+		{
+			MethodName: "CreateStory",
+			Handler:    _Domain_CreateStory_Handler,
+		},
+		{
+			MethodName: "GetStory",
+			Handler:    _Domain_GetStory_Handler,
+		},
+		{
+			MethodName: "ListStories",
+			Handler:    _Domain_ListStories_Handler,
+		},
+		{
+			MethodName: "UpdateStory",
+			Handler:    _Domain_UpdateStory_Handler,
+		},
+		{
+			MethodName: "DeleteStory",
+			Handler:    _Domain_DeleteStory_Handler,
+		},
+		{
+			MethodName: "CreateTask",
+			Handler:    _Domain_CreateTask_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _Domain_GetTask_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _Domain_ListTasks_Handler,
+		},
+		{
+			MethodName: "UpdateTask",
+			Handler:    _Domain_UpdateTask_Handler,
+		},
+		{
+			MethodName: "DeleteTask",
+			Handler:    _Domain_DeleteTask_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "intermute/v1/intermute.proto",
+}
+
+const (
+	Events_Subscribe_FullMethodName = "/intermute.v1.Events/Subscribe"
+)
+
+// EventsClient is the client API for Events service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Events streams the events WebSocket clients receive.
+type EventsClient interface {
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type eventsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventsClient(cc grpc.ClientConnInterface) EventsClient {
+	return &eventsClient{cc}
+}
+
+func (c *eventsClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Events_ServiceDesc.Streams[0], Events_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Events_SubscribeClient = grpc.ServerStreamingClient[Event]
+
+// EventsServer is the server API for Events service.
+// All implementations must embed UnimplementedEventsServer
+// for forward compatibility.
+//
+// Events streams the events WebSocket clients receive.
+type EventsServer interface {
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedEventsServer()
+}
+
+// UnimplementedEventsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEventsServer struct{}
+
+func (UnimplementedEventsServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedEventsServer) mustEmbedUnimplementedEventsServer() {}
+func (UnimplementedEventsServer) testEmbeddedByValue()                {}
+
+// UnsafeEventsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventsServer will
+// result in compilation errors.
+type UnsafeEventsServer interface {
+	mustEmbedUnimplementedEventsServer()
+}
+
+func RegisterEventsServer(s grpc.ServiceRegistrar, srv EventsServer) {
+	// If the following call panics, it indicates UnimplementedEventsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Events_ServiceDesc, srv)
+}
+
+func _Events_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Events_SubscribeServer = grpc.ServerStreamingServer[Event]
+
+// Events_ServiceDesc is the grpc.ServiceDesc for Events service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Events_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "intermute.v1.Events",
+	HandlerType: (*EventsServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Events_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "intermute/v1/intermute.proto",
+}
@@ -0,0 +1,208 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+)
+
+// forwardedMetadata are the metadata keys copied onto the REST request as
+// headers: the credentials the auth middleware reads and the tracing IDs.
+var forwardedMetadata = []string{
+	"authorization", "x-agent-id", "x-agent-token",
+	"x-request-id", "x-correlation-id", "x-causation-id",
+}
+
+// restClient runs REST requests through the HTTP router in-process.
+// authMW is the router's auth middleware, for calls that only need the
+// caller's identity.
+type restClient struct {
+	handler http.Handler
+	authMW  func(http.Handler) http.Handler
+}
+
+// call sends method path?query with body (JSON-encoded unless nil) as the
+// gRPC caller and decodes the response into out, if given. REST error
+// statuses come back as gRPC status errors.
+func (c *restClient) call(ctx context.Context, method, path string, query url.Values, body any, out proto.Message) error {
+	data, err := c.do(ctx, method, path, query, body)
+	if err != nil || out == nil || len(data) == 0 {
+		return err
+	}
+	return decode(data, out)
+}
+
+// list GETs a REST list endpoint, which answers with a bare JSON array,
+// and decodes it into field of out.
+func (c *restClient) list(ctx context.Context, path string, query url.Values, field string, out proto.Message) error {
+	data, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	wrapped, err := json.Marshal(map[string]json.RawMessage{field: data})
+	if err != nil {
+		return status.Errorf(codes.Internal, "decode response: %v", err)
+	}
+	return decode(wrapped, out)
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, query url.Values, body any) ([]byte, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := newRequest(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	rec := newRecorder()
+	c.handler.ServeHTTP(rec, req)
+	if rec.status/100 != 2 {
+		return nil, restError(rec.status, rec.body.Bytes())
+	}
+	return rec.body.Bytes(), nil
+}
+
+// authenticate runs the gRPC caller's credentials through the auth
+// middleware and returns who they are.
+func (c *restClient) authenticate(ctx context.Context) (auth.Info, error) {
+	req, err := newRequest(ctx, http.MethodGet, "/", http.NoBody)
+	if err != nil {
+		return auth.Info{}, err
+	}
+	var info auth.Info
+	var h http.Handler = http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		info, _ = auth.FromContext(r.Context())
+	})
+	if c.authMW != nil {
+		h = c.authMW(h)
+	}
+	rec := newRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.status/100 != 2 {
+		return auth.Info{}, restError(rec.status, rec.body.Bytes())
+	}
+	return info, nil
+}
+
+// newRequest builds a REST request on behalf of the gRPC caller in ctx.
+func newRequest(ctx context.Context, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range forwardedMetadata {
+			if v := md.Get(key); len(v) > 0 {
+				req.Header.Set(key, v[0])
+			}
+		}
+	}
+	// The auth middleware lets localhost through without a key, so the
+	// REST request must come from wherever the gRPC call came from.
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+	return req, nil
+}
+
+func decode(data []byte, out proto.Message) error {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, out); err != nil {
+		return status.Errorf(codes.Internal, "decode response: %v", err)
+	}
+	return nil
+}
+
+// restError converts a REST error response to a gRPC status. The message
+// is the body's "message" (or, from older handlers, "error") field when it
+// has one.
+func restError(code int, body []byte) error {
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	msg := http.StatusText(code)
+	if json.Unmarshal(body, &payload) == nil && payload.Message != "" {
+		msg = payload.Message
+	} else if payload.Error != "" {
+		msg = payload.Error
+	} else if text := strings.TrimSpace(string(body)); text != "" && !json.Valid(body) {
+		msg = text
+	}
+	return status.Error(grpcCode(code), msg)
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// recorder is a minimal in-memory http.ResponseWriter.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) WriteHeader(status int) {
+	if r.wrote {
+		return
+	}
+	r.status = status
+	r.wrote = true
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wrote = true
+	return r.body.Write(p)
+}
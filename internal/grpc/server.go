@@ -0,0 +1,63 @@
+// Package grpcapi serves the intermute API over gRPC, for orchestrators
+// written against gRPC rather than REST.
+//
+// Unary RPCs are dispatched in-process through the HTTP router, so every
+// call gets exactly the validation, auth, contact policy and broadcasts of
+// its REST endpoint without a network hop or a second implementation to
+// keep in sync. Event subscriptions are fed by an EventBus, which the
+// server broadcasts to next to the WebSocket hub.
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+
+	"github.com/mistakeknot/intermute/internal/grpc/pb"
+)
+
+//go:generate protoc -I proto --go_out=../.. --go_opt=module=github.com/mistakeknot/intermute --go-grpc_out=../.. --go-grpc_opt=module=github.com/mistakeknot/intermute intermute/v1/intermute.proto
+
+// Server is the gRPC server.
+type Server struct {
+	events *EventBus
+	grpc   *grpc.Server
+}
+
+// New returns a server whose RPCs are served by api, the HTTP router, and
+// whose subscriptions stream events from events. authMW is the middleware
+// the router was built with (nil for none); it authenticates subscriptions.
+func New(api http.Handler, authMW func(http.Handler) http.Handler, events *EventBus) *Server {
+	rest := &restClient{handler: api, authMW: authMW}
+	s := &Server{events: events, grpc: grpc.NewServer()}
+	pb.RegisterMessagingServer(s.grpc, &messagingServer{rest: rest})
+	pb.RegisterDomainServer(s.grpc, &domainServer{rest: rest})
+	pb.RegisterEventsServer(s.grpc, &eventsServer{rest: rest, bus: events})
+	return s
+}
+
+// Serve accepts connections on ln until Shutdown is called.
+func (s *Server) Serve(ln net.Listener) error {
+	return s.grpc.Serve(ln)
+}
+
+// Shutdown ends every event subscription and waits for in-flight calls to
+// finish. Calls still running when ctx is done, such as inbox streams, are
+// cut off.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.events.close()
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,249 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/grpc/pb"
+	httpapi "github.com/mistakeknot/intermute/internal/http"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+// newTestConn serves the gRPC API over an in-memory store on a loopback
+// port and returns a client connection to it.
+func newTestConn(t *testing.T, ring *auth.Keyring) *grpc.ClientConn {
+	t.Helper()
+	st := storagetest.NewSQLite(t)
+	events := NewEventBus()
+	svc := httpapi.NewDomainService(st).WithBroadcaster(events)
+	authMW := auth.Middleware(ring)
+	srv := New(httpapi.NewDomainRouter(svc, nil, authMW), authMW, events)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func requireCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("expected %s, got %s (%v)", want, got, err)
+	}
+}
+
+func TestDomainCRUD(t *testing.T) {
+	conn := newTestConn(t, nil)
+	domain := pb.NewDomainClient(conn)
+	ctx := testContext(t)
+
+	spec, err := domain.CreateSpec(ctx, &pb.Spec{Project: "proj", Title: "Checkout", Status: "draft"})
+	if err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	if spec.GetId() == "" || spec.GetCreatedAt() == nil {
+		t.Fatalf("expected server-assigned id and timestamps, got %+v", spec)
+	}
+
+	got, err := domain.GetSpec(ctx, &pb.GetRequest{Project: "proj", Id: spec.GetId()})
+	if err != nil {
+		t.Fatalf("get spec: %v", err)
+	}
+	if got.GetTitle() != "Checkout" {
+		t.Fatalf("expected title Checkout, got %q", got.GetTitle())
+	}
+
+	epic, err := domain.CreateEpic(ctx, &pb.Epic{Project: "proj", SpecId: spec.GetId(), Title: "Payments", Status: "open"})
+	if err != nil {
+		t.Fatalf("create epic: %v", err)
+	}
+	story, err := domain.CreateStory(ctx, &pb.Story{Project: "proj", EpicId: epic.GetId(), Title: "Card form", Status: "todo"})
+	if err != nil {
+		t.Fatalf("create story: %v", err)
+	}
+	task, err := domain.CreateTask(ctx, &pb.Task{Project: "proj", StoryId: story.GetId(), Title: "Validate", Status: "pending", Agent: "alice"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	tasks, err := domain.ListTasks(ctx, &pb.ListTasksRequest{Project: "proj", Agent: "alice"})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	if len(tasks.GetTasks()) != 1 || tasks.GetTasks()[0].GetId() != task.GetId() {
+		t.Fatalf("expected the one task, got %+v", tasks.GetTasks())
+	}
+
+	task.Status = "running"
+	updated, err := domain.UpdateTask(ctx, task)
+	if err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	if updated.GetStatus() != "running" {
+		t.Fatalf("expected running, got %q", updated.GetStatus())
+	}
+
+	if _, err := domain.DeleteSpec(ctx, &pb.DeleteRequest{Project: "proj", Id: spec.GetId()}); err != nil {
+		t.Fatalf("delete spec: %v", err)
+	}
+	specs, err := domain.ListSpecs(ctx, &pb.ListSpecsRequest{Project: "proj"})
+	if err != nil {
+		t.Fatalf("list specs: %v", err)
+	}
+	if len(specs.GetSpecs()) != 0 {
+		t.Fatalf("expected no specs after delete, got %d", len(specs.GetSpecs()))
+	}
+
+	_, err = domain.GetEpic(ctx, &pb.GetRequest{Project: "proj", Id: "missing"})
+	requireCode(t, err, codes.NotFound)
+}
+
+func TestSendMessageAndStreamInbox(t *testing.T) {
+	conn := newTestConn(t, nil)
+	messaging := pb.NewMessagingClient(conn)
+	ctx := testContext(t)
+
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := messaging.RegisterAgent(ctx, &pb.RegisterAgentRequest{Name: name, Project: "proj"}); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	first, err := messaging.SendMessage(ctx, &pb.SendMessageRequest{Project: "proj", From: "alice", To: []string{"bob"}, Body: "one"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if first.GetMessageId() == "" || first.GetCursor() == 0 {
+		t.Fatalf("expected message id and cursor, got %+v", first)
+	}
+
+	stream, err := messaging.StreamInbox(ctx, &pb.StreamInboxRequest{Project: "proj", Agent: "bob"})
+	if err != nil {
+		t.Fatalf("stream inbox: %v", err)
+	}
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv backlog: %v", err)
+	}
+	if msg.GetId() != first.GetMessageId() || msg.GetBody() != "one" {
+		t.Fatalf("expected backlog message, got %+v", msg)
+	}
+
+	if _, err := messaging.SendMessage(ctx, &pb.SendMessageRequest{Project: "proj", From: "alice", To: []string{"bob"}, Body: "two"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	msg, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("recv live: %v", err)
+	}
+	if msg.GetBody() != "two" || msg.GetCursor() <= first.GetCursor() {
+		t.Fatalf("expected the new message, got %+v", msg)
+	}
+
+	if _, err := messaging.Ack(ctx, &pb.MessageActionRequest{Project: "proj", MessageId: msg.GetId(), Agent: "bob"}); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+
+	_, err = messaging.SendMessage(ctx, &pb.SendMessageRequest{Project: "proj", From: "alice", Body: "nobody"})
+	requireCode(t, err, codes.InvalidArgument)
+}
+
+func TestSubscribeFiltersEvents(t *testing.T) {
+	conn := newTestConn(t, nil)
+	domain := pb.NewDomainClient(conn)
+	ctx := testContext(t)
+
+	stream, err := pb.NewEventsClient(conn).Subscribe(ctx, &pb.SubscribeRequest{Project: "proj", Events: []string{"task.*"}})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	// Headers arrive once the subscription is registered, so nothing
+	// broadcast after this can be missed.
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+
+	if _, err := domain.CreateSpec(ctx, &pb.Spec{Project: "proj", Title: "Ignored", Status: "draft"}); err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	task, err := domain.CreateTask(ctx, &pb.Task{Project: "proj", Title: "Watched", Status: "pending"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if ev.GetType() != "task.created" || ev.GetEntityId() != task.GetId() {
+		t.Fatalf("expected task.created for %s, got %s for %s", task.GetId(), ev.GetType(), ev.GetEntityId())
+	}
+	if title := ev.GetPayload().GetFields()["data"].GetStructValue().GetFields()["title"].GetStringValue(); title != "Watched" {
+		t.Fatalf("expected payload data with the task, got %v", ev.GetPayload())
+	}
+
+	bad, err := pb.NewEventsClient(conn).Subscribe(ctx, &pb.SubscribeRequest{Project: "proj", Events: []string{"["}})
+	if err == nil {
+		_, err = bad.Recv()
+	}
+	requireCode(t, err, codes.InvalidArgument)
+}
+
+func TestAPIKeyMetadata(t *testing.T) {
+	ring := auth.NewKeyring(false, map[string]string{"secret": "proj"})
+	conn := newTestConn(t, ring)
+	domain := pb.NewDomainClient(conn)
+	ctx := testContext(t)
+
+	_, err := domain.ListSpecs(ctx, &pb.ListSpecsRequest{Project: "proj"})
+	requireCode(t, err, codes.Unauthenticated)
+
+	keyed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+	if _, err := domain.ListSpecs(keyed, &pb.ListSpecsRequest{Project: "proj"}); err != nil {
+		t.Fatalf("list with key: %v", err)
+	}
+	_, err = domain.CreateSpec(keyed, &pb.Spec{Project: "other", Title: "Nope"})
+	requireCode(t, err, codes.PermissionDenied)
+
+	events := pb.NewEventsClient(conn)
+	stream, err := events.Subscribe(ctx, &pb.SubscribeRequest{Project: "proj"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	requireCode(t, err, codes.Unauthenticated)
+
+	stream, err = events.Subscribe(keyed, &pb.SubscribeRequest{Project: "other"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	requireCode(t, err, codes.PermissionDenied)
+}
@@ -8,14 +8,11 @@ import (
 	"testing"
 
 	"github.com/mistakeknot/intermute/internal/auth"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestAPIKeyProjectEnforcement(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	ring := auth.NewKeyring(true, map[string]string{"secret": "proj-a"})
 	h := NewRouter(svc, nil, auth.Middleware(ring))
@@ -52,10 +49,7 @@ func TestAPIKeyProjectEnforcement(t *testing.T) {
 }
 
 func TestPresenceAPIKeyProjectEnforcement(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	ring := auth.NewKeyring(true, map[string]string{"secret-a": "proj-a", "secret-b": "proj-b"})
 	h := NewRouter(svc, nil, auth.Middleware(ring))
@@ -115,10 +109,7 @@ func TestPresenceAPIKeyProjectEnforcement(t *testing.T) {
 }
 
 func TestHeartbeatAuthEnforcement(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	ring := auth.NewKeyring(true, map[string]string{"secret-a": "proj-a", "secret-b": "proj-b"})
 	h := NewRouter(svc, nil, auth.Middleware(ring))
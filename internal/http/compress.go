@@ -0,0 +1,315 @@
+package httpapi
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultCompressMinBytes is the smallest response body that is
+// compressed; below it the encoding overhead isn't worth the CPU.
+const DefaultCompressMinBytes = 1 << 10
+
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+// compressor negotiates gzip or zstd response compression from
+// Accept-Encoding and counts what it saves for /metrics.
+type compressor struct {
+	minBytes int // 0 disables compression
+
+	mu       sync.Mutex
+	count    map[string]uint64 // compressed responses by encoding
+	bytesIn  map[string]uint64
+	bytesOut map[string]uint64
+	skipped  uint64 // negotiated but under minBytes
+}
+
+func newCompressor(minBytes int) *compressor {
+	return &compressor{
+		minBytes: minBytes,
+		count:    map[string]uint64{},
+		bytesIn:  map[string]uint64{},
+		bytesOut: map[string]uint64{},
+	}
+}
+
+// WithCompression sets the smallest response body that is compressed for
+// clients sending Accept-Encoding: zstd or gzip. 0 disables compression.
+func (s *Service) WithCompression(minBytes int) *Service {
+	s.compress.minBytes = max(minBytes, 0)
+	return s
+}
+
+func (s *DomainService) WithCompression(minBytes int) *DomainService {
+	s.Service.WithCompression(minBytes)
+	return s
+}
+
+// wrap compresses next's responses. Bodies are held back until minBytes
+// have been written, so small responses go out unencoded; already-encoded
+// and non-text responses pass through untouched.
+func (c *compressor) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.minBytes <= 0 || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, c: c, encoding: encoding, status: http.StatusOK}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks zstd or gzip, whichever Accept-Encoding ranks
+// higher (zstd on a tie), or "" if neither is acceptable.
+func negotiateEncoding(header string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		switch name {
+		case encodingZstd, encodingGzip:
+			q[name] = weight
+		case "*":
+			for _, enc := range []string{encodingZstd, encodingGzip} {
+				if _, ok := q[enc]; !ok {
+					q[enc] = weight
+				}
+			}
+		}
+	}
+	best := ""
+	for _, enc := range []string{encodingZstd, encodingGzip} {
+		if w, ok := q[enc]; ok && w > 0 && (best == "" || w > q[best]) {
+			best = enc
+		}
+	}
+	return best
+}
+
+// compressible reports whether a response of this type is worth
+// compressing: text and structured data, not already-packed media.
+func compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	ct = strings.TrimSpace(ct)
+	return strings.HasPrefix(ct, "text/") ||
+		strings.HasSuffix(ct, "json") ||
+		strings.HasSuffix(ct, "xml") ||
+		ct == "application/javascript" ||
+		ct == "application/x-ndjson"
+}
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+	zstdWriters = sync.Pool{New: func() any {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return enc
+	}}
+)
+
+// compressWriter buffers a response until it reaches minBytes, then
+// commits to compressing it; a response that ends first is sent as is.
+type compressWriter struct {
+	http.ResponseWriter
+	c        *compressor
+	encoding string
+
+	status      int
+	wroteHeader bool // the handler called WriteHeader or Write
+	sent        bool // headers went out on the underlying writer
+	passthrough bool
+	buf         []byte
+	enc         io.WriteCloser
+	in          uint64
+	out         countingWriter
+}
+
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += uint64(n)
+	return n, err
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	h := cw.Header()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" || (h.Get("Content-Type") != "" && !compressible(h.Get("Content-Type"))) {
+		cw.passthrough = true
+		cw.send()
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case cw.passthrough:
+		return cw.ResponseWriter.Write(b)
+	case cw.enc != nil:
+		cw.in += uint64(len(b))
+		return cw.enc.Write(b)
+	}
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.c.minBytes {
+		if err := cw.startCompressing(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends what has been written so far, compressed if compression has
+// started and unencoded otherwise.
+func (cw *compressWriter) Flush() {
+	switch {
+	case cw.enc != nil:
+		if f, ok := cw.enc.(interface{ Flush() error }); ok {
+			_ = f.Flush()
+		}
+	case !cw.passthrough:
+		cw.passthrough = true
+		cw.send()
+		if len(cw.buf) > 0 {
+			_, _ = cw.ResponseWriter.Write(cw.buf)
+			cw.buf = nil
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) send() {
+	if cw.sent {
+		return
+	}
+	cw.sent = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressWriter) startCompressing() error {
+	h := cw.Header()
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+		if !compressible(h.Get("Content-Type")) {
+			cw.passthrough = true
+			cw.send()
+			_, err := cw.ResponseWriter.Write(cw.buf)
+			cw.buf = nil
+			return err
+		}
+	}
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	// The ETag names the unencoded body; the encoded bytes differ.
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	cw.send()
+	cw.out.w = cw.ResponseWriter
+	switch cw.encoding {
+	case encodingZstd:
+		enc := zstdWriters.Get().(*zstd.Encoder)
+		enc.Reset(&cw.out)
+		cw.enc = enc
+	default:
+		gz := gzipWriters.Get().(*gzip.Writer)
+		gz.Reset(&cw.out)
+		cw.enc = gz
+	}
+	buf := cw.buf
+	cw.buf = nil
+	cw.in += uint64(len(buf))
+	_, err := cw.enc.Write(buf)
+	return err
+}
+
+// finish flushes whatever the handler left: the encoder's tail, or a body
+// that never reached minBytes.
+func (cw *compressWriter) finish() {
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		switch enc := cw.enc.(type) {
+		case *zstd.Encoder:
+			zstdWriters.Put(enc)
+		case *gzip.Writer:
+			gzipWriters.Put(enc)
+		}
+		cw.c.record(cw.encoding, cw.in, cw.out.n)
+		return
+	}
+	if cw.passthrough || !cw.wroteHeader {
+		return
+	}
+	cw.send()
+	if len(cw.buf) > 0 {
+		_, _ = cw.ResponseWriter.Write(cw.buf)
+		cw.c.mu.Lock()
+		cw.c.skipped++
+		cw.c.mu.Unlock()
+	}
+}
+
+func (c *compressor) record(encoding string, in, out uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count[encoding]++
+	c.bytesIn[encoding] += in
+	c.bytesOut[encoding] += out
+}
+
+func (c *compressor) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counters := []struct {
+		name, help string
+		values     map[string]uint64
+	}{
+		{"intermute_http_compressed_responses_total", "HTTP responses sent compressed, by encoding.", c.count},
+		{"intermute_http_compression_in_bytes_total", "Response bytes before compression, by encoding.", c.bytesIn},
+		{"intermute_http_compression_out_bytes_total", "Response bytes after compression, by encoding.", c.bytesOut},
+	}
+	for _, m := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s counter\n", m.name)
+		for _, enc := range []string{encodingGzip, encodingZstd} {
+			fmt.Fprintf(w, "%s{encoding=%q} %d\n", m.name, enc, m.values[enc])
+		}
+	}
+	fmt.Fprintln(w, "# HELP intermute_http_compression_skipped_total Responses that accepted compression but were under the size threshold.")
+	fmt.Fprintln(w, "# TYPE intermute_http_compression_skipped_total counter")
+	fmt.Fprintf(w, "intermute_http_compression_skipped_total %d\n", c.skipped)
+}
@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                      "",
+		"gzip":                  "gzip",
+		"gzip, zstd":            "zstd",
+		"zstd;q=0.5, gzip":      "gzip",
+		"zstd;q=0, gzip;q=0":    "",
+		"*":                     "zstd",
+		"gzip;q=0.8, *;q=0.1":   "gzip",
+		"deflate, identity, br": "",
+		"ZSTD":                  "zstd",
+	}
+	for header, want := range cases {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := `{"items":"` + strings.Repeat("insight body ", 500) + `"}`
+	c := newCompressor(DefaultCompressMinBytes)
+	h := c.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"abc"`)
+		if r.URL.Query().Get("small") != "" {
+			io.WriteString(w, `{"ok":true}`)
+			return
+		}
+		io.WriteString(w, large)
+	}))
+
+	serve := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Header().Get("ETag") != `W/"abc"` {
+		t.Errorf("ETag = %q, want weakened", rec.Header().Get("ETag"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != large {
+		t.Error("gzip body doesn't round-trip")
+	}
+
+	rec = serve("/", "zstd, gzip")
+	if rec.Header().Get("Content-Encoding") != "zstd" {
+		t.Fatalf("Content-Encoding = %q, want zstd", rec.Header().Get("Content-Encoding"))
+	}
+	dec, err := zstd.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(dec)
+	dec.Close()
+	if string(body) != large {
+		t.Error("zstd body doesn't round-trip")
+	}
+
+	rec = serve("/?small=1", "gzip")
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != `{"ok":true}` {
+		t.Errorf("small response was encoded: %q %q", rec.Header().Get("Content-Encoding"), rec.Body.String())
+	}
+	if rec = serve("/", ""); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != large {
+		t.Error("response encoded without Accept-Encoding")
+	}
+	if !strings.Contains(rec.Header().Get("Vary"), "Accept-Encoding") {
+		t.Error("missing Vary: Accept-Encoding")
+	}
+
+	if c.count["gzip"] != 1 || c.count["zstd"] != 1 || c.skipped != 1 {
+		t.Errorf("metrics: count=%v skipped=%d", c.count, c.skipped)
+	}
+	if c.bytesOut["gzip"] >= c.bytesIn["gzip"] {
+		t.Errorf("gzip didn't shrink the body: in %d, out %d", c.bytesIn["gzip"], c.bytesOut["gzip"])
+	}
+}
@@ -41,5 +41,5 @@ func dispatchByMethod(w http.ResponseWriter, r *http.Request, h methodHandlers)
 			return
 		}
 	}
-	w.WriteHeader(http.StatusMethodNotAllowed)
+	writeMethodNotAllowed(w)
 }
@@ -16,7 +16,7 @@ import (
 
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/livetransport"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestE2ELiveTransportRoundTrip(t *testing.T) {
@@ -101,10 +101,7 @@ func newE2ETestServer(t *testing.T, socketName string) (srv *httptest.Server) {
 		}
 	}()
 
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st).WithLiveDelivery(livetransport.NewInjector(e2eTmuxRunner{socketName: socketName}))
 	return httptest.NewServer(NewRouter(svc, nil, nil))
 }
@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// errorResponse is the body of every error response. Code is a stable,
+// machine-readable identifier clients switch on (project_mismatch,
+// invalid_json, not_found, ...); Message is for people; Details carries
+// structure specific to the code. Error repeats Message for clients that
+// predate it.
+type errorResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+func newErrorResponse(msg, code string, details any) errorResponse {
+	return errorResponse{Error: msg, Code: code, Message: msg, Details: details}
+}
+
+// writeJSONError writes an errorResponse with status.
+func writeJSONError(w http.ResponseWriter, status int, msg, code string) {
+	writeJSONErrorDetails(w, status, msg, code, nil)
+}
+
+// writeJSONErrorDetails is writeJSONError with a details payload.
+func writeJSONErrorDetails(w http.ResponseWriter, status int, msg, code string, details any) {
+	writeErrorBody(w, status, newErrorResponse(msg, code, details))
+}
+
+// writeJSONErrorFields writes an errorResponse for a code that responses
+// used to report in "error" with its fields at the top level. Both are
+// kept for older clients: error holds the code, and fields are repeated
+// next to details.
+func writeJSONErrorFields(w http.ResponseWriter, status int, msg, code string, fields map[string]any) {
+	body := map[string]any{
+		"error":   code,
+		"code":    code,
+		"message": msg,
+		"details": fields,
+	}
+	for k, v := range fields {
+		body[k] = v
+	}
+	writeErrorBody(w, status, body)
+}
+
+// writeErrorBody writes body, an errorResponse or a struct embedding one,
+// with status.
+func writeErrorBody(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeInvalidJSON rejects a request body that failed to decode; one over
+// the size limit is a 413.
+func writeInvalidJSON(w http.ResponseWriter, err error) {
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large", "body_too_large")
+		return
+	}
+	msg := "invalid request body"
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	writeJSONError(w, http.StatusBadRequest, msg, "invalid_json")
+}
+
+// writeProjectMismatch rejects a request for a project other than the one
+// its API key is scoped to.
+func writeProjectMismatch(w http.ResponseWriter) {
+	writeJSONError(w, http.StatusForbidden, "project does not match the API key's project", "project_mismatch")
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSONError(w, http.StatusNotFound, "not found", "not_found")
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed", "method_not_allowed")
+}
+
+// writeInternalError reports a storage or other server-side failure
+// without leaking its details.
+func writeInternalError(w http.ResponseWriter) {
+	writeJSONError(w, http.StatusInternalServerError, "internal error", "internal_error")
+}
@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestErrorResponsesCarryCodes(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	ring := auth.NewKeyring(true, map[string]string{"secret": "proj-a"})
+	h := NewDomainRouter(NewDomainService(st), nil, auth.Middleware(ring))
+
+	do := func(path, body string) (int, errorResponse) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.RemoteAddr = "203.0.113.10:9999"
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Fatalf("%s: content-type = %q", path, ct)
+		}
+		var out errorResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+			t.Fatalf("%s: decode %q: %v", path, rr.Body.String(), err)
+		}
+		return rr.Code, out
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		status     int
+		code       string
+	}{
+		{"/api/specs", `{"project":"proj-b","title":"x"}`, http.StatusForbidden, "project_mismatch"},
+		{"/api/specs", `{"project":`, http.StatusBadRequest, "invalid_json"},
+		{"/api/messages", `{"project":"proj-b","from":"a","to":["b"]}`, http.StatusForbidden, "project_mismatch"},
+		{"/api/messages", `not json`, http.StatusBadRequest, "invalid_json"},
+		{"/api/reservations", `{"project":"proj-a"}`, http.StatusBadRequest, "missing_field"},
+	} {
+		status, body := do(tc.path, tc.body)
+		if status != tc.status || body.Code != tc.code {
+			t.Fatalf("POST %s %s = %d %+v, want %d %s", tc.path, tc.body, status, body, tc.status, tc.code)
+		}
+		if body.Message == "" || body.Error != body.Message {
+			t.Fatalf("POST %s: message %q, error %q", tc.path, body.Message, body.Error)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// withETag buffers successful GET responses, tags them with a content hash
+// and answers a matching If-None-Match with 304 Not Modified. Polling
+// clients revalidate cheaply instead of re-downloading unchanged bodies.
+func withETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &etagRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag,
+// accepting weak validators and comma-separated lists.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+type etagRecorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func (e *etagRecorder) Header() http.Header { return e.header }
+
+func (e *etagRecorder) WriteHeader(status int) {
+	if e.wroteHeader {
+		return
+	}
+	e.status = status
+	e.wroteHeader = true
+}
+
+func (e *etagRecorder) Write(b []byte) (int, error) {
+	e.wroteHeader = true
+	return e.body.Write(b)
+}
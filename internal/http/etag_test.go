@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetResponsesCarryETag(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": "p", "title": "Spec"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/specs?project=p")
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag on GET response")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, env.srv.URL+"/api/specs?project=p", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireStatus(t, resp, http.StatusNotModified)
+	resp.Body.Close()
+
+	// A write changes the body and therefore the tag.
+	resp = env.post(t, "/api/specs", map[string]any{"project": "p", "title": "Another"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if resp.Header.Get("ETag") == etag {
+		t.Fatal("expected ETag to change after a write")
+	}
+}
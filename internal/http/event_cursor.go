@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// Read-your-writes
+//
+// A write that appends events answers with X-Event-Cursor, the cursor of
+// the last event it appended. Broadcasts race subscriptions, so a writer
+// that starts following the stream just after a write may miss its own
+// event live; resuming from the cursor before X-Event-Cursor (the Go
+// client's SubscribeOptions.From) replays it from the log instead, and the
+// stream's cursor dedupe delivers it exactly once.
+
+const headerEventCursor = "X-Event-Cursor"
+
+type eventCursorKey struct{}
+
+// eventCursor is the highest cursor a request has appended.
+type eventCursor struct {
+	v atomic.Uint64
+}
+
+// noteEventCursor records that the request behind ctx appended the event
+// at cursor. Outside a write request it does nothing.
+func noteEventCursor(ctx context.Context, cursor uint64) {
+	c, _ := ctx.Value(eventCursorKey{}).(*eventCursor)
+	if c == nil {
+		return
+	}
+	for {
+		cur := c.v.Load()
+		if cursor <= cur || c.v.CompareAndSwap(cur, cursor) {
+			return
+		}
+	}
+}
+
+// notedEventCursor returns the highest cursor the request behind ctx has noted so far,
+// or 0.
+func notedEventCursor(ctx context.Context) uint64 {
+	c, _ := ctx.Value(eventCursorKey{}).(*eventCursor)
+	if c == nil {
+		return 0
+	}
+	return c.v.Load()
+}
+
+// eventCursorCheckpoint returns a func that forgets the cursors ctx's
+// request notes after this call, for events whose transaction was rolled
+// back: their cursors will be handed out again.
+func eventCursorCheckpoint(ctx context.Context) (rollback func()) {
+	c, _ := ctx.Value(eventCursorKey{}).(*eventCursor)
+	if c == nil {
+		return func() {}
+	}
+	saved := c.v.Load()
+	return func() { c.v.Store(saved) }
+}
+
+// withEventCursor stamps X-Event-Cursor on the responses of writes that
+// append events. Requests made inside one, such as a batch's operations,
+// note their cursors on the outer request.
+func withEventCursor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Context().Value(eventCursorKey{}) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		c := &eventCursor{}
+		ctx := context.WithValue(r.Context(), eventCursorKey{}, c)
+		next.ServeHTTP(&eventCursorWriter{ResponseWriter: w, cursor: c}, r.WithContext(ctx))
+	})
+}
+
+// eventCursorWriter sets X-Event-Cursor as the response's headers go out.
+type eventCursorWriter struct {
+	http.ResponseWriter
+	cursor  *eventCursor
+	stamped bool
+}
+
+func (w *eventCursorWriter) stamp() {
+	if w.stamped {
+		return
+	}
+	w.stamped = true
+	if v := w.cursor.v.Load(); v > 0 {
+		w.Header().Set(headerEventCursor, strconv.FormatUint(v, 10))
+	}
+}
+
+func (w *eventCursorWriter) WriteHeader(status int) {
+	w.stamp()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *eventCursorWriter) Write(b []byte) (int, error) {
+	w.stamp()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *eventCursorWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestWritesReturnEventCursor(t *testing.T) {
+	env := newTestEnv(t)
+	eventCursor := func(resp *http.Response) uint64 {
+		t.Helper()
+		v := resp.Header.Get(headerEventCursor)
+		if v == "" {
+			return 0
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			t.Fatalf("%s = %q", headerEventCursor, v)
+		}
+		return n
+	}
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "Mine", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+	created := eventCursor(resp)
+	resp = env.get(t, "/api/events?project=proj&entity_type=task&entity_id="+task.ID)
+	requireStatus(t, resp, http.StatusOK)
+	if log := decodeJSON[listEventsResponse](t, resp); len(log.Events) != 1 || log.Events[0].Cursor != created {
+		t.Fatalf("task events = %+v, want one at the returned cursor %d", log.Events, created)
+	}
+	resp = env.get(t, "/api/tasks/"+task.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if got := eventCursor(resp); got != 0 {
+		t.Fatalf("read returned event cursor %d", got)
+	}
+
+	// A batch returns its last event's cursor, and a rolled-back one none.
+	ops := []map[string]any{
+		{"method": "POST", "path": "/api/tasks", "body": map[string]any{"project": "proj", "title": "A", "status": "pending"}},
+		{"method": "POST", "path": "/api/tasks", "body": map[string]any{"project": "proj", "title": "B", "status": "pending"}},
+	}
+	resp = env.post(t, "/api/batch", map[string]any{"ops": ops})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	batch := eventCursor(resp)
+	resp = env.get(t, "/api/events?project=proj&entity_type=task")
+	requireStatus(t, resp, http.StatusOK)
+	if log := decodeJSON[listEventsResponse](t, resp); log.Cursor != batch {
+		t.Fatalf("batch event cursor = %d, want the log's last, %d", batch, log.Cursor)
+	}
+	ops = append(ops, map[string]any{"method": "POST", "path": "/api/tasks", "body": map[string]any{"project": "proj", "title": "Bad", "priority": "urgent"}})
+	resp = env.post(t, "/api/batch", map[string]any{"ops": ops})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if got := eventCursor(resp); got != 0 {
+		t.Fatalf("rolled-back batch returned event cursor %d", got)
+	}
+}
@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Ack quorums. A send with ack_policy ("all", "any" or a count N) asks for
+// that many recipients to ack it. Every ack re-checks the message's progress
+// and the ack that completes it emits message.quorum_reached, once.
+
+// applyAckPolicy parses req's ack policy against msg's recipients and sets
+// it on msg, which then requires acks. It writes the 400 and returns false
+// for an invalid policy, or one on a live send, which is never stored to be
+// acked.
+func applyAckPolicy(w http.ResponseWriter, req sendMessageRequest, msg *core.Message) bool {
+	if req.AckPolicy == "" {
+		return true
+	}
+	if msg.Transport == core.TransportLive {
+		writeJSONError(w, http.StatusBadRequest, "ack_policy needs a stored message; live sends are not stored", "invalid_ack_policy")
+		return false
+	}
+	policy, err := core.ParseAckPolicy(req.AckPolicy, len(msg.Recipients()))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "ack_policy must be all, any or a count of recipients: "+err.Error(), "invalid_ack_policy")
+		return false
+	}
+	msg.AckPolicy = policy
+	msg.AckRequired = true
+	return true
+}
+
+// checkAckQuorum returns msgID's ack progress after agentID's ack, and
+// emits message.quorum_reached if that ack completed it. The zero progress
+// means the message has no policy.
+func (s *Service) checkAckQuorum(ctx context.Context, project, msgID, agentID string) (core.AckProgress, error) {
+	progress, reached, err := s.store.AckQuorum(ctx, project, msgID)
+	if err != nil || !reached {
+		return progress, err
+	}
+	data, _ := json.Marshal(progress)
+	eventID := uuid.NewString()
+	cursor, err := s.store.AppendEvent(ctx, core.Event{
+		ID:      eventID,
+		Type:    core.EventMessageQuorumReached,
+		Agent:   agentID,
+		Project: project,
+		Message: core.Message{ID: msgID, Project: project},
+		Data:    string(data),
+	})
+	if err != nil {
+		log.Printf("WARN: record quorum for message %s: %v", msgID, err)
+	}
+	noteEventCursor(ctx, cursor)
+	if s.bus != nil {
+		event := map[string]any{
+			"type":       string(core.EventMessageQuorumReached),
+			"event_id":   eventID,
+			"project":    project,
+			"message_id": msgID,
+			"agent":      agentID,
+			"quorum":     progress,
+		}
+		addTrace(event, core.TraceFromContext(ctx))
+		s.bus.Broadcast(project, "", event)
+	}
+	return progress, nil
+}
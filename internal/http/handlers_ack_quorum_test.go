@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestAckQuorum(t *testing.T) {
+	env, bus := newRecordingEnv(t)
+	var reviewers []string
+	for _, name := range []string{"r1", "r2", "r3"} {
+		resp := env.post(t, "/api/agents", map[string]any{"name": name, "project": "proj"})
+		requireStatus(t, resp, http.StatusOK)
+		reviewers = append(reviewers, decodeJSON[registerAgentResponse](t, resp).AgentID)
+	}
+	send := func(policy string) *http.Response {
+		t.Helper()
+		return env.post(t, "/api/messages", map[string]any{
+			"project": "proj", "from": "lead", "to": reviewers, "body": "unblock deploy?", "ack_policy": policy,
+		})
+	}
+	ack := func(msgID, agent string) map[string]core.AckProgress {
+		t.Helper()
+		resp := env.post(t, "/api/messages/"+msgID+"/ack?project=proj", map[string]any{"agent": agent})
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[map[string]core.AckProgress](t, resp)
+	}
+
+	resp := send("4")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = send("most")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = send("2")
+	requireStatus(t, resp, http.StatusOK)
+	msgID := decodeJSON[sendMessageResponse](t, resp).MessageID
+
+	got := ack(msgID, reviewers[0])["quorum"]
+	if got.Required != 2 || got.Recipients != 3 || len(got.Acked) != 1 || got.ReachedAt != nil {
+		t.Fatalf("after one ack: %+v", got)
+	}
+	if n := len(bus.ofType(core.EventMessageQuorumReached)); n != 0 {
+		t.Fatalf("quorum reached after one ack (%d events)", n)
+	}
+	got = ack(msgID, reviewers[2])["quorum"]
+	if len(got.Acked) != 2 || got.ReachedAt == nil {
+		t.Fatalf("after two acks: %+v", got)
+	}
+	ack(msgID, reviewers[1])
+	events := bus.ofType(core.EventMessageQuorumReached)
+	if len(events) != 1 || events[0]["message_id"] != msgID || events[0]["agent"] != reviewers[2] {
+		t.Fatalf("quorum events = %+v, want one completed by %s", events, reviewers[2])
+	}
+
+	resp = env.get(t, "/api/inbox/"+reviewers[0]+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	msgs := decodeJSON[inboxResponse](t, resp).Messages
+	if len(msgs) != 1 || msgs[0].AckPolicy != "2" || !msgs[0].AckRequired {
+		t.Fatalf("inbox = %+v, want the message with its ack policy", msgs)
+	}
+}
@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestDomainEventsCarryActor(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	ring := auth.NewKeyring(false, map[string]string{"shared": "proj"})
+	if err := ring.AddAgentKey("bound", "proj", "planner"); err != nil {
+		t.Fatalf("add agent key: %v", err)
+	}
+	bus := &recordingBus{}
+	svc := NewDomainService(st).WithBroadcaster(bus)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, auth.Middleware(ring)))
+	t.Cleanup(srv.Close)
+
+	createSpec := func(key, agent string) int {
+		body, _ := json.Marshal(map[string]any{"project": "proj", "title": "Spec by " + key})
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/specs", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key)
+		if agent != "" {
+			req.Header.Set("X-Agent-ID", agent)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := createSpec("bound", ""); code != http.StatusCreated {
+		t.Fatalf("bound key: expected 201, got %d", code)
+	}
+	if code := createSpec("shared", "reviewer"); code != http.StatusCreated {
+		t.Fatalf("shared key: expected 201, got %d", code)
+	}
+	if code := createSpec("bound", "reviewer"); code != http.StatusForbidden {
+		t.Fatalf("impersonation: expected 403, got %d", code)
+	}
+
+	events := bus.ofType(core.EventSpecCreated)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 spec.created events, got %d", len(events))
+	}
+	if events[0]["actor"] != "planner" || events[1]["actor"] != "reviewer" {
+		t.Fatalf("expected actors planner and reviewer, got %v and %v", events[0]["actor"], events[1]["actor"])
+	}
+}
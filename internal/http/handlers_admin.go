@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// requireAdmin rejects project-scoped API-key callers. Admin endpoints span
+// every project, so they are reserved for localhost (operator) access.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey {
+		writeJSONError(w, http.StatusForbidden, "admin endpoints are not available to project API keys", "admin_only")
+		return false
+	}
+	return true
+}
+
+// handleAdminOverview returns per-project agent, session, task, reservation
+// and message counts with last activity, plus the database size. Archived
+// projects are left out unless ?include_archived=true.
+func (s *DomainService) handleAdminOverview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	overview, err := s.domainStore.AdminOverview(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if r.URL.Query().Get("include_archived") != "true" {
+		overview.Projects = slices.DeleteFunc(overview.Projects, func(p core.ProjectStats) bool { return p.Archived })
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(overview)
+}
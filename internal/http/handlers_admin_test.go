@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestAdminOverview(t *testing.T) {
+	env := newTestEnv(t)
+
+	registerAgent(t, env, "alpha-1", "alpha")
+	registerAgent(t, env, "alpha-2", "alpha")
+	registerAgent(t, env, "beta-1", "beta")
+	for _, status := range []string{"pending", "running", "done"} {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "alpha", "title": "t-" + status, "status": status})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+	resp := env.post(t, "/api/epics", map[string]any{"project": "alpha", "title": "e"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.post(t, "/api/sessions", map[string]any{"project": "beta", "name": "s", "agent": "beta-1", "status": "running"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	sendTestMessage(t, env, "beta", "beta-1", []string{"x"}, "hello")
+
+	resp = env.get(t, "/api/admin/overview")
+	requireStatus(t, resp, http.StatusOK)
+	overview := decodeJSON[core.AdminOverview](t, resp)
+	if overview.DBSizeBytes <= 0 {
+		t.Fatalf("expected positive db size, got %d", overview.DBSizeBytes)
+	}
+	byProject := map[string]core.ProjectStats{}
+	for _, p := range overview.Projects {
+		byProject[p.Project] = p
+	}
+	alpha, beta := byProject["alpha"], byProject["beta"]
+	if alpha.Agents != 2 || alpha.ActiveAgents != 2 || alpha.OpenTasks != 2 || alpha.InProgressTasks != 1 ||
+		alpha.OpenEpics != 1 || alpha.ActiveSessions != 0 {
+		t.Fatalf("unexpected alpha stats: %+v", alpha)
+	}
+	if beta.Agents != 1 || beta.ActiveSessions != 1 || beta.Messages != 1 {
+		t.Fatalf("unexpected beta stats: %+v", beta)
+	}
+	if alpha.LastActivity == nil || beta.LastActivity == nil {
+		t.Fatalf("expected last activity for both projects: %+v %+v", alpha, beta)
+	}
+}
+
+func TestAdminOverviewRejectsAPIKeys(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	ring := auth.NewKeyring(false, map[string]string{"secret": "alpha"})
+	srv := httptest.NewServer(NewDomainRouter(NewDomainService(st), nil, auth.Middleware(ring)))
+	t.Cleanup(srv.Close)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/admin/overview", strings.NewReader(""))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireStatus(t, resp, http.StatusForbidden)
+	resp.Body.Close()
+}
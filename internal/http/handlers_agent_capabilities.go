@@ -0,0 +1,206 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Capability registry handlers. A project with no registry accepts any
+// capability strings; once one exists, agent registrations are normalized
+// to canonical names and unknown capabilities are rejected.
+
+type setCapabilityRequest struct {
+	Project     string   `json:"project"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+type apiCapability struct {
+	Project     string   `json:"project"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases"`
+	Agents      []string `json:"agents"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+}
+
+// unregisteredCapability is a capability string agents advertise that the
+// registry doesn't resolve.
+type unregisteredCapability struct {
+	Name   string   `json:"name"`
+	Agents []string `json:"agents"`
+}
+
+type listCapabilitiesResponse struct {
+	Capabilities []apiCapability          `json:"capabilities"`
+	Unregistered []unregisteredCapability `json:"unregistered"`
+}
+
+// validCapabilityName rejects names that couldn't round-trip through the
+// comma-separated ?capability= filter or a URL path.
+func validCapabilityName(name string) bool {
+	return strings.TrimSpace(name) != "" && !strings.ContainsAny(name, ",/")
+}
+
+func (s *Service) handleAgentCapabilities(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get: s.listAgentCapabilities,
+	})
+}
+
+func (s *Service) handleAgentCapabilityByName(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/agent-capabilities/"), "/")
+	if !validCapabilityName(name) {
+		writeNotFound(w)
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		put:    func(w http.ResponseWriter, r *http.Request) { s.setAgentCapability(w, r, name) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteAgentCapability(w, r, name) },
+	})
+}
+
+// capabilityRegistry loads a project's registry; it is empty when the
+// project hasn't defined one.
+func (s *Service) capabilityRegistry(ctx context.Context, project string) (core.CapabilityRegistry, error) {
+	caps, err := s.store.ListCapabilities(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewCapabilityRegistry(caps), nil
+}
+
+// listAgentCapabilities shows planners which capabilities a project
+// recognizes and which registered agents have each, plus any strings agents
+// advertise that the registry doesn't resolve.
+func (s *Service) listAgentCapabilities(w http.ResponseWriter, r *http.Request) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	caps, err := s.store.ListCapabilities(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	agents, err := s.store.ListAgents(r.Context(), project, nil)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	reg := core.NewCapabilityRegistry(caps)
+	holders := map[string][]string{}
+	unknown := map[string][]string{}
+	for _, a := range agents {
+		for _, c := range a.Capabilities {
+			if name, ok := reg.Canonical(c); ok {
+				holders[name] = append(holders[name], a.ID)
+			} else {
+				unknown[c] = append(unknown[c], a.ID)
+			}
+		}
+	}
+
+	resp := listCapabilitiesResponse{
+		Capabilities: make([]apiCapability, 0, len(caps)),
+		Unregistered: make([]unregisteredCapability, 0, len(unknown)),
+	}
+	for _, c := range caps {
+		resp.Capabilities = append(resp.Capabilities, apiCapability{
+			Project:     c.Project,
+			Name:        c.Name,
+			Description: c.Description,
+			Aliases:     c.Aliases,
+			Agents:      dedupeAgents(holders[c.Name]),
+			UpdatedAt:   c.UpdatedAt.Format(time.RFC3339Nano),
+		})
+	}
+	for _, name := range slices.Sorted(maps.Keys(unknown)) {
+		resp.Unregistered = append(resp.Unregistered, unregisteredCapability{Name: name, Agents: dedupeAgents(unknown[name])})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// setAgentCapability creates or replaces a capability. Neither its name nor
+// its aliases may already resolve to a different capability.
+func (s *Service) setAgentCapability(w http.ResponseWriter, r *http.Request, name string) {
+	limitBody(w, r)
+	var req setCapabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	project, ok := groupProject(w, r, req.Project)
+	if !ok {
+		return
+	}
+	aliases, _ := core.CapabilityRegistry(nil).Normalize(req.Aliases)
+	for _, alias := range aliases {
+		if !validCapabilityName(alias) {
+			writeJSONError(w, http.StatusBadRequest, "invalid alias: "+alias, "invalid_request")
+			return
+		}
+	}
+
+	reg, err := s.capabilityRegistry(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	for _, spelling := range append([]string{name}, aliases...) {
+		if owner, ok := reg.Canonical(spelling); ok && owner != name {
+			writeJSONError(w, http.StatusConflict, spelling+" already resolves to "+owner, "capability_conflict")
+			return
+		}
+	}
+
+	if aliases == nil {
+		aliases = []string{}
+	}
+	saved, err := s.store.SetCapability(r.Context(), core.Capability{
+		Project:     project,
+		Name:        name,
+		Description: req.Description,
+		Aliases:     aliases,
+	})
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiCapability{
+		Project:     saved.Project,
+		Name:        saved.Name,
+		Description: saved.Description,
+		Aliases:     saved.Aliases,
+		Agents:      []string{},
+		UpdatedAt:   saved.UpdatedAt.Format(time.RFC3339Nano),
+	})
+}
+
+func (s *Service) deleteAgentCapability(w http.ResponseWriter, r *http.Request, name string) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteCapability(r.Context(), project, name); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCapabilityRegistryNormalizesRegistration(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	// Without a registry any capability strings are accepted as-is.
+	resp := env.post(t, "/api/agents", map[string]any{"name": "early", "project": project, "capabilities": []string{"golang"}})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.put(t, "/api/agent-capabilities/go", map[string]any{"project": project, "aliases": []string{"golang", "Go"}})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.put(t, "/api/agent-capabilities/review", map[string]any{"project": project, "aliases": []string{"GOLANG"}})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/agents", map[string]any{"name": "gopher", "project": project, "capabilities": []string{"GoLang", "go"}})
+	requireStatus(t, resp, http.StatusOK)
+	gopher := decodeJSON[registerAgentResponse](t, resp)
+
+	resp = env.post(t, "/api/agents", map[string]any{"name": "rusty", "project": project, "capabilities": []string{"rust"}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	// Filtering by an alias finds agents registered under the canonical name.
+	resp = env.get(t, "/api/agents?project="+project+"&capability=golang")
+	requireStatus(t, resp, http.StatusOK)
+	list := decodeJSON[listAgentsResponse](t, resp)
+	if len(list.Agents) != 1 || list.Agents[0].AgentID != gopher.AgentID {
+		t.Fatalf("expected only gopher, got %+v", list.Agents)
+	}
+	if caps := list.Agents[0].Capabilities; len(caps) != 1 || caps[0] != "go" {
+		t.Fatalf("expected capabilities normalized to [go], got %v", caps)
+	}
+
+	resp = env.get(t, "/api/agent-capabilities?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	listing := decodeJSON[listCapabilitiesResponse](t, resp)
+	if len(listing.Capabilities) != 1 || listing.Capabilities[0].Name != "go" {
+		t.Fatalf("unexpected registry: %+v", listing.Capabilities)
+	}
+	// The early agent's "golang" now resolves through the alias.
+	if got := listing.Capabilities[0].Agents; len(got) != 2 {
+		t.Fatalf("expected both agents under go, got %v", got)
+	}
+	if len(listing.Unregistered) != 0 {
+		t.Fatalf("expected no unregistered capabilities, got %+v", listing.Unregistered)
+	}
+
+	resp = env.delete(t, "/api/agent-capabilities/go?project="+project)
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = env.delete(t, "/api/agent-capabilities/go?project="+project)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/names"
 )
@@ -78,15 +79,24 @@ func (s *Service) handleListAgents(w http.ResponseWriter, r *http.Request) {
 		if project == "" {
 			project = info.Project
 		} else if project != info.Project {
-			w.WriteHeader(http.StatusForbidden)
+			writeProjectMismatch(w)
 			return
 		}
 	}
 
 	var capabilities []string
 	if capParam := r.URL.Query().Get("capability"); capParam != "" {
+		reg, err := s.capabilityRegistry(r.Context(), project)
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
 		for _, c := range strings.Split(capParam, ",") {
 			if c = strings.TrimSpace(c); c != "" {
+				// Filter by the canonical name so aliases match too.
+				if name, ok := reg.Canonical(c); ok {
+					c = name
+				}
 				capabilities = append(capabilities, c)
 			}
 		}
@@ -94,7 +104,7 @@ func (s *Service) handleListAgents(w http.ResponseWriter, r *http.Request) {
 
 	agents, err := s.store.ListAgents(r.Context(), project, capabilities)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -132,14 +142,14 @@ func (s *Service) handleListAgentPresence(w http.ResponseWriter, r *http.Request
 		if project == "" {
 			project = info.Project
 		} else if project != info.Project {
-			w.WriteHeader(http.StatusForbidden)
+			writeProjectMismatch(w)
 			return
 		}
 	}
 
 	agents, err := s.store.ListAgents(r.Context(), project, nil)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -209,7 +219,7 @@ func parsePresenceFiles(raw string) []string {
 func (s *Service) handleRegisterAgent(w http.ResponseWriter, r *http.Request) {
 	var req registerAgentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	if strings.TrimSpace(req.Name) == "" {
@@ -218,21 +228,32 @@ func (s *Service) handleRegisterAgent(w http.ResponseWriter, r *http.Request) {
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey {
 		if strings.TrimSpace(req.Project) == "" {
-			w.WriteHeader(http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "project is required", "missing_field")
 			return
 		}
 		if req.Project != info.Project {
-			w.WriteHeader(http.StatusForbidden)
+			writeProjectMismatch(w)
 			return
 		}
 	}
 
-	now := time.Now().UTC()
+	reg, err := s.capabilityRegistry(r.Context(), strings.TrimSpace(req.Project))
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	capabilities, unknown := reg.Normalize(req.Capabilities)
+	if len(unknown) > 0 {
+		writeJSONError(w, http.StatusBadRequest, "unknown capabilities: "+strings.Join(unknown, ", "), "unknown_capability")
+		return
+	}
+
+	now := clock.Now().UTC()
 	agent, err := s.store.RegisterAgent(r.Context(), core.Agent{
 		Name:         req.Name,
 		SessionID:    strings.TrimSpace(req.SessionID),
 		Project:      strings.TrimSpace(req.Project),
-		Capabilities: req.Capabilities,
+		Capabilities: capabilities,
 		Metadata:     req.Metadata,
 		Status:       req.Status,
 		CreatedAt:    now,
@@ -240,24 +261,14 @@ func (s *Service) handleRegisterAgent(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		if errors.Is(err, core.ErrActiveSessionConflict) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "session_id is in use by an active agent",
-				"code":  "active_session_conflict",
-			})
+			writeJSONError(w, http.StatusConflict, "session_id is in use by an active agent", "active_session_conflict")
 			return
 		}
 		if errors.Is(err, core.ErrInvalidSessionID) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": err.Error(),
-				"code":  "invalid_session_id",
-			})
+			writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_session_id")
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -278,7 +289,7 @@ func (s *Service) handleAgentSubpath(w http.ResponseWriter, r *http.Request) {
 	// Parse: "<agent-id>/<action>"
 	parts := strings.SplitN(path, "/", 2)
 	if len(parts) != 2 || parts[0] == "" {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	agentID := parts[0]
@@ -292,13 +303,13 @@ func (s *Service) handleAgentSubpath(w http.ResponseWriter, r *http.Request) {
 	case "policy":
 		s.handleAgentPolicy(w, r, agentID)
 	default:
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 	}
 }
 
 func (s *Service) handleAgentHeartbeat(w http.ResponseWriter, r *http.Request, agentID string) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	limitBody(w, r)
@@ -307,18 +318,16 @@ func (s *Service) handleAgentHeartbeat(w http.ResponseWriter, r *http.Request, a
 		FocusState string `json:"focus_state"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	if !core.ValidFocusState(req.FocusState) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid focus_state"})
+		writeJSONError(w, http.StatusBadRequest, "invalid focus_state", "invalid_request")
 		return
 	}
 	if req.FocusState != "" {
 		if err := s.store.SetAgentFocusState(r.Context(), agentID, req.FocusState); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeInternalError(w)
 			return
 		}
 	}
@@ -332,7 +341,7 @@ func (s *Service) handleAgentHeartbeat(w http.ResponseWriter, r *http.Request, a
 
 	agent, err := s.store.Heartbeat(r.Context(), project, agentID)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -345,28 +354,27 @@ type updateMetadataRequest struct {
 
 func (s *Service) handleAgentMetadata(w http.ResponseWriter, r *http.Request, agentID string) {
 	if r.Method != http.MethodPatch {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	var req updateMetadataRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	if len(req.Metadata) == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "metadata map required"})
+		writeJSONError(w, http.StatusBadRequest, "metadata map required", "missing_field")
 		return
 	}
 
 	agent, err := s.store.UpdateAgentMetadata(r.Context(), agentID, req.Metadata)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			w.WriteHeader(http.StatusNotFound)
+			writeNotFound(w)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -400,53 +408,41 @@ func (s *Service) handleAgentPolicy(w http.ResponseWriter, r *http.Request, agen
 		limitBody(w, r)
 		var req setPolicyRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeInvalidJSON(w, err)
 			return
 		}
 		if req.Policy == "" && req.LiveContactPolicy == "" {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "policy or live_contact_policy required",
-			})
+			writeJSONError(w, http.StatusBadRequest, "policy or live_contact_policy required", "missing_field")
 			return
 		}
 		if req.Policy != "" && !core.ValidContactPolicy(req.Policy) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "invalid policy: must be open, auto, contacts_only, or block_all",
-			})
+			writeJSONError(w, http.StatusBadRequest, "invalid policy: must be open, auto, contacts_only, or block_all", "invalid_request")
 			return
 		}
 		if req.LiveContactPolicy != "" && !core.ValidContactPolicy(req.LiveContactPolicy) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "invalid live_contact_policy: must be open, auto, contacts_only, or block_all",
-			})
+			writeJSONError(w, http.StatusBadRequest, "invalid live_contact_policy: must be open, auto, contacts_only, or block_all", "invalid_request")
 			return
 		}
 		if req.Policy != "" {
 			if err := s.store.SetContactPolicy(r.Context(), agentID, core.ContactPolicy(req.Policy)); err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
+				writeInternalError(w)
 				return
 			}
 		}
 		if req.LiveContactPolicy != "" {
 			if err := s.store.SetLiveContactPolicy(r.Context(), agentID, core.ContactPolicy(req.LiveContactPolicy)); err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
+				writeInternalError(w)
 				return
 			}
 		}
 		policy, err := s.store.GetContactPolicy(r.Context(), agentID)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeInternalError(w)
 			return
 		}
 		livePolicy, err := s.store.GetLiveContactPolicy(r.Context(), agentID)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeInternalError(w)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -461,12 +457,12 @@ func (s *Service) handleAgentPolicy(w http.ResponseWriter, r *http.Request, agen
 		get: func(w http.ResponseWriter, r *http.Request) {
 			policy, err := s.store.GetContactPolicy(r.Context(), agentID)
 			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
+				writeInternalError(w)
 				return
 			}
 			livePolicy, err := s.store.GetLiveContactPolicy(r.Context(), agentID)
 			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
+				writeInternalError(w)
 				return
 			}
 			w.Header().Set("Content-Type", "application/json")
@@ -11,7 +11,7 @@ import (
 
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/storage"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestRegisterAgent(t *testing.T) {
@@ -679,10 +679,7 @@ func TestPatchAgentMetadataNotFound(t *testing.T) {
 }
 
 func TestHeartbeatAcceptsFocusState(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -719,10 +716,7 @@ func TestHeartbeatAcceptsFocusState(t *testing.T) {
 }
 
 func TestHeartbeatRejectsInvalidFocusState(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -751,10 +745,7 @@ func TestHeartbeatRejectsInvalidFocusState(t *testing.T) {
 }
 
 func TestPolicyEndpointAcceptsLiveContactPolicy(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
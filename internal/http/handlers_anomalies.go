@@ -0,0 +1,26 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+)
+
+// handleAnomalies reports recently detected coordination anomalies
+// (task flapping, reservation thrash, chatty threads), newest first.
+func (s *DomainService) handleAnomalies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"anomalies": s.anomalies.Recent(project),
+	})
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestReservationThrashReported(t *testing.T) {
+	env := newTestEnv(t)
+
+	for i := 0; i < 10; i++ {
+		resp := env.post(t, "/api/reservations", map[string]any{
+			"agent_id": "agent-a", "project": "p", "path_pattern": "pkg/hot.go", "exclusive": false,
+		})
+		requireStatus(t, resp, http.StatusCreated)
+		res := decodeJSON[map[string]any](t, resp)
+		del := env.delete(t, "/api/reservations/"+res["id"].(string)+"?agent=agent-a")
+		del.Body.Close()
+	}
+
+	resp := env.get(t, "/api/anomalies?project=p")
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[struct {
+		Anomalies []core.Anomaly `json:"anomalies"`
+	}](t, resp)
+	if len(out.Anomalies) != 1 || out.Anomalies[0].Kind != core.AnomalyReservationThrash {
+		t.Fatalf("expected reservation_thrash anomaly, got %+v", out.Anomalies)
+	}
+}
+
+func TestTaskFlappingSeenThroughClaimAndBulkStatus(t *testing.T) {
+	env := newTestEnv(t)
+	task, _ := env.store.CreateTask(context.Background(), core.Task{Project: "p", Title: "flaky", Status: core.TaskStatusPending})
+
+	claim := func() {
+		resp := env.post(t, "/api/tasks/claim", map[string]any{"project": "p", "agent": "worker", "steal": true})
+		requireStatus(t, resp, http.StatusOK)
+		if got := decodeJSON[claimTasksResponse](t, resp); len(got.Tasks) != 1 {
+			t.Fatalf("claimed %+v, want the task", got.Tasks)
+		}
+	}
+	drop := func(mode string) {
+		resp := env.post(t, "/api/tasks/bulk-status", map[string]any{
+			"project": "p", "mode": mode, "status": "pending", "ids": []string{task.ID},
+		})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	anomalies := func() []core.Anomaly {
+		resp := env.get(t, "/api/anomalies?project=p")
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[struct {
+			Anomalies []core.Anomaly `json:"anomalies"`
+		}](t, resp).Anomalies
+	}
+
+	for range 2 {
+		claim()
+		drop(batchModeAtomic)
+	}
+	// A dry run rolls back, so it must not count as a third drop.
+	claim()
+	drop(bulkModeDryRun)
+	if got := anomalies(); len(got) != 0 {
+		t.Fatalf("dry run counted toward flapping: %+v", got)
+	}
+	drop(batchModeAtomic)
+	got := anomalies()
+	if len(got) != 1 || got[0].Kind != core.AnomalyTaskFlapping || got[0].Agent != "worker" {
+		t.Fatalf("expected task_flapping for worker, got %+v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Project archival handlers. Archiving hides a finished project from
+// /api/admin/overview; with "export": true its rows are also moved into a
+// cold SQLite file under the archive directory, and reactivating imports
+// them back.
+
+type archiveProjectRequest struct {
+	Export bool `json:"export"`
+}
+
+// WithArchiveDir sets where exported project archives are written.
+// Optional — without it, archive requests with "export": true get 501.
+func (s *DomainService) WithArchiveDir(dir string) *DomainService {
+	s.archiveDir = dir
+	return s
+}
+
+// archiveFileName makes a file name for project's cold archive: the
+// project with anything unsafe in a path replaced, plus the archive time.
+func archiveFileName(project string, at time.Time) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, project)
+	return safe + "-" + at.UTC().Format("20060102T150405Z") + ".db"
+}
+
+func (s *DomainService) handleAdminArchives(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	archives, err := s.domainStore.ListProjectArchives(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"archives": archives})
+}
+
+// handleAdminProjectByName serves /api/admin/projects/{project}/archive,
+// /api/admin/projects/{project}/reactivate and
+// /api/admin/projects/{project}/retention.
+func (s *DomainService) handleAdminProjectByName(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/projects/"), "/")
+	project, action, ok := strings.Cut(path, "/")
+	if !ok || project == "" {
+		writeNotFound(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	switch action {
+	case "archive":
+		dispatchByMethod(w, r, methodHandlers{
+			post: func(w http.ResponseWriter, r *http.Request) { s.archiveProject(w, r, project) },
+		})
+	case "reactivate":
+		dispatchByMethod(w, r, methodHandlers{
+			post: func(w http.ResponseWriter, r *http.Request) { s.reactivateProject(w, r, project) },
+		})
+	case "retention":
+		dispatchByMethod(w, r, methodHandlers{
+			get:    func(w http.ResponseWriter, r *http.Request) { s.getRetentionPolicy(w, r, project) },
+			put:    func(w http.ResponseWriter, r *http.Request) { s.putRetentionPolicy(w, r, project) },
+			delete: func(w http.ResponseWriter, r *http.Request) { s.deleteRetentionPolicy(w, r, project) },
+		})
+	default:
+		writeNotFound(w)
+	}
+}
+
+func (s *DomainService) archiveProject(w http.ResponseWriter, r *http.Request, project string) {
+	limitBody(w, r)
+	var req archiveProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeInvalidJSON(w, err)
+		return
+	}
+	var coldPath string
+	if req.Export {
+		if s.archiveDir == "" {
+			writeJSONError(w, http.StatusNotImplemented, "archive directory not configured", "archive_unavailable")
+			return
+		}
+		coldPath = filepath.Join(s.archiveDir, archiveFileName(project, time.Now()))
+	}
+	archive, err := s.domainStore.ArchiveProject(r.Context(), project, coldPath)
+	if err != nil {
+		if errors.Is(err, core.ErrAlreadyExists) {
+			writeJSONError(w, http.StatusConflict, "project already archived", "project_archived")
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(archive)
+}
+
+func (s *DomainService) reactivateProject(w http.ResponseWriter, r *http.Request, project string) {
+	archive, err := s.domainStore.ReactivateProject(r.Context(), project)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeJSONError(w, http.StatusNotFound, "project not archived", "not_archived")
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(archive)
+}
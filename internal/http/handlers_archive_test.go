@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+	"github.com/mistakeknot/intermute/internal/ws"
+)
+
+func newArchiveTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	st := storagetest.NewSQLite(t)
+	hub := ws.NewHub()
+	svc := NewDomainService(st).WithBroadcaster(hub).WithArchiveDir(t.TempDir())
+	srv := httptest.NewServer(NewDomainRouter(svc, hub.Handler(), nil))
+	t.Cleanup(srv.Close)
+	return &testEnv{srv: srv, hub: hub, store: st}
+}
+
+func overviewProjects(t *testing.T, env *testEnv, path string) map[string]core.ProjectStats {
+	t.Helper()
+	resp := env.get(t, path)
+	requireStatus(t, resp, http.StatusOK)
+	out := map[string]core.ProjectStats{}
+	for _, p := range decodeJSON[core.AdminOverview](t, resp).Projects {
+		out[p.Project] = p
+	}
+	return out
+}
+
+func TestArchiveProjectExportAndReactivate(t *testing.T) {
+	env := newArchiveTestEnv(t)
+
+	registerAgent(t, env, "old-1", "old")
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "old", "title": "leftover", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "live", "title": "current", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/admin/projects/old/archive", map[string]any{"export": true})
+	requireStatus(t, resp, http.StatusOK)
+	archive := decodeJSON[core.ProjectArchive](t, resp)
+	if archive.ArchivePath == "" || archive.Rows == 0 {
+		t.Fatalf("archive = %+v, want exported rows", archive)
+	}
+	if _, err := os.Stat(archive.ArchivePath); err != nil {
+		t.Fatalf("archive file: %v", err)
+	}
+
+	resp = env.get(t, "/api/tasks/"+task.ID+"?project=old")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	projects := overviewProjects(t, env, "/api/admin/overview")
+	if _, ok := projects["old"]; ok {
+		t.Fatal("archived project listed by default")
+	}
+	if _, ok := projects["live"]; !ok {
+		t.Fatal("live project missing from overview")
+	}
+	if p, ok := overviewProjects(t, env, "/api/admin/overview?include_archived=true")["old"]; !ok || !p.Archived {
+		t.Fatalf("include_archived: old = %+v, %v", p, ok)
+	}
+
+	resp = env.post(t, "/api/admin/projects/old/archive", nil)
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/admin/projects/old/reactivate", nil)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if _, err := os.Stat(archive.ArchivePath); !os.IsNotExist(err) {
+		t.Fatalf("archive file still present after reactivation: %v", err)
+	}
+	resp = env.get(t, "/api/tasks/"+task.ID+"?project=old")
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.Task](t, resp); got.Title != "leftover" {
+		t.Fatalf("restored task = %+v", got)
+	}
+	if p := overviewProjects(t, env, "/api/admin/overview")["old"]; p.Agents != 1 || p.Archived {
+		t.Fatalf("reactivated project stats = %+v", p)
+	}
+
+	resp = env.post(t, "/api/admin/projects/old/reactivate", nil)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestArchiveProjectHideOnly(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "old", "title": "kept", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+
+	// No archive directory configured: export is unavailable, hiding isn't.
+	resp = env.post(t, "/api/admin/projects/old/archive", map[string]any{"export": true})
+	requireStatus(t, resp, http.StatusNotImplemented)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/admin/projects/old/archive", nil)
+	requireStatus(t, resp, http.StatusOK)
+	if a := decodeJSON[core.ProjectArchive](t, resp); a.ArchivePath != "" || a.Rows != 0 {
+		t.Fatalf("hide-only archive = %+v", a)
+	}
+	resp = env.get(t, "/api/tasks/"+task.ID+"?project=old")
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/admin/archives")
+	requireStatus(t, resp, http.StatusOK)
+	listed := decodeJSON[map[string][]core.ProjectArchive](t, resp)["archives"]
+	if len(listed) != 1 || listed[0].Project != "old" {
+		t.Fatalf("archives = %+v", listed)
+	}
+}
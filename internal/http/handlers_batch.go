@@ -0,0 +1,303 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mistakeknot/intermute/internal/anomaly"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Batch handler. POST /api/batch runs a list of create, update and delete
+// calls against the entity endpoints, in order, each exactly as if it had
+// been sent on its own. In atomic mode (the default) they share one
+// transaction: the first failure rolls every operation back and the
+// events they would have broadcast are dropped. In best_effort mode each
+// operation stands alone and a failure doesn't stop the rest.
+
+// maxBatchOps caps one batch; an atomic batch holds the database for its
+// whole run.
+const maxBatchOps = 100
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+)
+
+// Operation outcomes in a batch response.
+const (
+	batchOpOK         = "ok"
+	batchOpFailed     = "failed"
+	batchOpRolledBack = "rolled_back"
+	batchOpSkipped    = "skipped"
+)
+
+// batchCollections are the endpoints a batch may write to, with anything
+// under them (/api/tasks/{id}, /api/tasks/{id}/assign, ...).
+var batchCollections = []string{
+	"/api/specs", "/api/epics", "/api/stories", "/api/tasks",
+	"/api/insights", "/api/sessions", "/api/cujs", "/api/goals",
+}
+
+type batchOp struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchRequest struct {
+	Mode string    `json:"mode"`
+	Ops  []batchOp `json:"ops"`
+}
+
+// batchOpResult is one operation's outcome. Status and Body are the
+// response the operation got; a rolled-back operation keeps them, though
+// none of its changes were kept. ID is set by bulk status updates.
+type batchOpResult struct {
+	Index  int             `json:"index"`
+	ID     string          `json:"id,omitempty"`
+	State  string          `json:"state"`
+	Status int             `json:"status,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchResponse struct {
+	Mode      string          `json:"mode"`
+	Committed bool            `json:"committed"`
+	Failed    int             `json:"failed"`
+	Results   []batchOpResult `json:"results"`
+}
+
+// validBatchOp returns why op can't run in a batch, or "" if it can.
+func validBatchOp(op batchOp) string {
+	switch op.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return "method must be POST, PUT, PATCH or DELETE"
+	}
+	p, _, _ := strings.Cut(op.Path, "?")
+	// A path that isn't clean ("/api/tasks/../projects") would be redirected
+	// somewhere the prefix check never saw.
+	if path.Clean(p) != p {
+		return "path must be clean: no ., .. or repeated or trailing slashes"
+	}
+	for _, c := range batchCollections {
+		if p == c || strings.HasPrefix(p, c+"/") {
+			return ""
+		}
+	}
+	return "path must be under one of " + strings.Join(batchCollections, ", ")
+}
+
+func (s *DomainService) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	limitBody(w, r)
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = batchModeAtomic
+	}
+	if req.Mode != batchModeAtomic && req.Mode != batchModeBestEffort {
+		writeJSONError(w, http.StatusBadRequest, "mode must be atomic or best_effort", "invalid_batch")
+		return
+	}
+	if len(req.Ops) == 0 || len(req.Ops) > maxBatchOps {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("a batch needs 1 to %d ops", maxBatchOps), "invalid_batch")
+		return
+	}
+	for i, op := range req.Ops {
+		if msg := validBatchOp(op); msg != "" {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("ops[%d]: %s", i, msg), "invalid_batch")
+			return
+		}
+	}
+
+	resp, err := s.runOps(r, req.Mode, len(req.Ops), func(h http.Handler, i int) batchOpResult {
+		return runBatchOp(h, r, i, req.Ops[i])
+	})
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// runOps runs n operations in order under mode, calling run with the
+// handler the i'th should be served through. An error means an atomic
+// run's transaction failed.
+func (s *DomainService) runOps(r *http.Request, mode string, n int, run func(h http.Handler, i int) batchOpResult) (batchResponse, error) {
+	resp := batchResponse{Mode: mode}
+	if mode == batchModeBestEffort {
+		h := NewDomainRouter(s, nil, nil)
+		for i := range n {
+			res := run(h, i)
+			if res.State == batchOpFailed {
+				resp.Failed++
+			}
+			resp.Results = append(resp.Results, res)
+		}
+		resp.Committed = resp.Failed < n
+		return resp, nil
+	}
+	held := &heldBroadcasts{}
+	rollback := eventCursorCheckpoint(r.Context())
+	err := s.domainStore.RunInTx(r.Context(), func(tx storage.DomainStore) bool {
+		held.reset()
+		h := NewDomainRouter(s.boundTo(tx, held), nil, nil)
+		resp.Results, resp.Failed = resp.Results[:0], 0
+		for i := range n {
+			res := run(h, i)
+			resp.Results = append(resp.Results, res)
+			if res.State != batchOpFailed {
+				continue
+			}
+			resp.Failed = 1
+			for j := range resp.Results[:i] {
+				resp.Results[j].State = batchOpRolledBack
+			}
+			for j := i + 1; j < n; j++ {
+				resp.Results = append(resp.Results, batchOpResult{Index: j, State: batchOpSkipped})
+			}
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		rollback()
+		return batchResponse{}, err
+	}
+	resp.Committed = resp.Failed == 0
+	if resp.Committed {
+		held.release(s.Service)
+	} else {
+		rollback()
+	}
+	return resp, nil
+}
+
+// runBatchOp serves op through h as a request of its own, carrying r's
+// caller and trace.
+func runBatchOp(h http.Handler, r *http.Request, index int, op batchOp) batchOpResult {
+	res := batchOpResult{Index: index, State: batchOpOK}
+	sub, err := http.NewRequestWithContext(r.Context(), op.Method, op.Path, bytes.NewReader(op.Body))
+	if err != nil {
+		res.State = batchOpFailed
+		res.Status = http.StatusBadRequest
+		res.Body, _ = json.Marshal(newErrorResponse("invalid path: "+err.Error(), "invalid_batch", nil))
+		return res
+	}
+	sub.Header.Set("Content-Type", "application/json")
+	t := core.TraceFromContext(r.Context())
+	sub.Header.Set(headerRequestID, t.RequestID)
+	sub.Header.Set(headerCorrelationID, t.CorrelationID)
+	sub.Header.Set(headerCausationID, t.CausationID)
+
+	rec := &etagRecorder{header: make(http.Header), status: http.StatusOK}
+	h.ServeHTTP(rec, sub)
+	res.Status = rec.status
+	if body := bytes.TrimSpace(rec.body.Bytes()); len(body) > 0 {
+		if json.Valid(body) {
+			res.Body = body
+		} else {
+			res.Body, _ = json.Marshal(string(body))
+		}
+	}
+	// Anything but a 2xx, a redirect included, didn't do what was asked.
+	if rec.status < http.StatusOK || rec.status >= http.StatusMultipleChoices {
+		res.State = batchOpFailed
+	}
+	return res
+}
+
+// boundTo returns a copy of s that reads and writes through store and
+// holds its broadcasts and anomaly observations in held, for running an
+// atomic batch inside a transaction.
+func (s *DomainService) boundTo(store storage.DomainStore, held *heldBroadcasts) *DomainService {
+	svc := *s.Service
+	svc.store = store
+	svc.bus = held
+	svc.held = held
+	return &DomainService{
+		Service:         &svc,
+		domainStore:     store,
+		pinger:          s.pinger,
+		keys:            s.keys,
+		version:         s.version,
+		breaker:         s.breaker,
+		sweeper:         s.sweeper,
+		storyThreads:    s.storyThreads,
+		storage:         s.storage,
+		archiveDir:      s.archiveDir,
+		devClock:        s.devClock,
+		clockSweeper:    s.clockSweeper,
+		grpc:            s.grpc,
+		requireProjects: s.requireProjects,
+		system:          s.system,
+		idemInFlight:    map[string]struct{}{},
+	}
+}
+
+// heldBroadcasts holds an atomic batch's broadcasts and anomaly
+// observations until it commits, so a rolled-back or dry run neither
+// announces nor counts anything.
+type heldBroadcasts struct {
+	mu     sync.Mutex
+	events []heldBroadcast
+}
+
+// heldBroadcast is either a broadcast or, when observe is set, an anomaly
+// observation.
+type heldBroadcast struct {
+	project, agent string
+	event          any
+	observe        func(*anomaly.Detector) []core.Anomaly
+}
+
+func (h *heldBroadcasts) Broadcast(project, agent string, event any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, heldBroadcast{project: project, agent: agent, event: event})
+}
+
+func (h *heldBroadcasts) hold(observe func(*anomaly.Detector) []core.Anomaly) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, heldBroadcast{observe: observe})
+}
+
+// reset drops everything held, for a transaction starting over.
+func (h *heldBroadcasts) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = nil
+}
+
+// release feeds the held observations to s's detector and sends the held
+// broadcasts to s's bus, in order.
+func (h *heldBroadcasts) release(s *Service) {
+	h.mu.Lock()
+	events := slices.Clone(h.events)
+	h.events = nil
+	h.mu.Unlock()
+	for _, e := range events {
+		if e.observe != nil {
+			s.reportAnomalies(e.observe(s.anomalies))
+		} else if s.bus != nil {
+			s.bus.Broadcast(e.project, e.agent, e.event)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// maxBatchGetIDs caps one batch-get so a single request can't turn into an
+// unbounded scan.
+const maxBatchGetIDs = 200
+
+type batchGetRequest struct {
+	Project string   `json:"project"`
+	IDs     []string `json:"ids"`
+}
+
+// batchGetResponse partitions the requested IDs: Found holds the entities in
+// request order, Missing the IDs that don't exist in the project.
+type batchGetResponse[T any] struct {
+	Found   []T      `json:"found"`
+	Missing []string `json:"missing"`
+}
+
+// batchGet serves POST /api/{entities}/batch-get, resolving every ID with
+// get in one round trip. Duplicate IDs are resolved once.
+func batchGet[T any](get func(ctx context.Context, project, id string) (T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w)
+			return
+		}
+		limitBody(w, r)
+		var req batchGetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeInvalidJSON(w, err)
+			return
+		}
+		if len(req.IDs) == 0 {
+			writeJSONError(w, http.StatusBadRequest, "ids required", "invalid_request")
+			return
+		}
+		if len(req.IDs) > maxBatchGetIDs {
+			writeJSONError(w, http.StatusBadRequest, "too many ids", "invalid_request")
+			return
+		}
+		project, ok := groupProject(w, r, req.Project)
+		if !ok {
+			return
+		}
+
+		resp := batchGetResponse[T]{Found: []T{}, Missing: []string{}}
+		seen := make(map[string]bool, len(req.IDs))
+		for _, id := range req.IDs {
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			// Like the single-entity GETs, any lookup failure reads as missing.
+			entity, err := get(r.Context(), project, id)
+			if err != nil {
+				resp.Missing = append(resp.Missing, id)
+				continue
+			}
+			resp.Found = append(resp.Found, entity)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
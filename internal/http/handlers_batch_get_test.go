@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestBatchGetTasks(t *testing.T) {
+	env := newTestEnv(t)
+
+	var ids []string
+	for _, title := range []string{"a", "b", "c"} {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": title, "status": "pending"})
+		requireStatus(t, resp, http.StatusCreated)
+		ids = append(ids, decodeJSON[core.Task](t, resp).ID)
+	}
+	other := env.post(t, "/api/tasks", map[string]any{"project": "other", "title": "x", "status": "pending"})
+	requireStatus(t, other, http.StatusCreated)
+	otherID := decodeJSON[core.Task](t, other).ID
+
+	resp := env.post(t, "/api/tasks/batch-get", map[string]any{
+		"project": "proj",
+		"ids":     []string{ids[2], "nope", ids[0], ids[2], otherID},
+	})
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[batchGetResponse[core.Task]](t, resp)
+
+	if len(got.Found) != 2 || got.Found[0].ID != ids[2] || got.Found[1].ID != ids[0] {
+		t.Fatalf("found = %+v, want %s then %s", got.Found, ids[2], ids[0])
+	}
+	if len(got.Missing) != 2 || got.Missing[0] != "nope" || got.Missing[1] != otherID {
+		t.Fatalf("missing = %v, want [nope %s]", got.Missing, otherID)
+	}
+}
+
+func TestBatchGetValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/specs/batch-get", map[string]any{"project": "proj", "ids": []string{}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	tooMany := make([]string, maxBatchGetIDs+1)
+	for i := range tooMany {
+		tooMany[i] = "id"
+	}
+	resp = env.post(t, "/api/specs/batch-get", map[string]any{"project": "proj", "ids": tooMany})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/specs/batch-get")
+	requireStatus(t, resp, http.StatusMethodNotAllowed)
+	resp.Body.Close()
+}
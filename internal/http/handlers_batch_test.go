@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestBatchAtomicRollsBackOnFailure(t *testing.T) {
+	env := newTestEnv(t)
+	ops := []map[string]any{
+		{"method": "POST", "path": "/api/tasks", "body": map[string]any{"project": "proj", "title": "First", "status": "pending"}},
+		{"method": "POST", "path": "/api/tasks", "body": map[string]any{"project": "proj", "title": "Bad", "priority": "urgent"}},
+		{"method": "POST", "path": "/api/tasks", "body": map[string]any{"project": "proj", "title": "Third", "status": "pending"}},
+	}
+
+	resp := env.post(t, "/api/batch", map[string]any{"ops": ops})
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[batchResponse](t, resp)
+	if out.Committed || out.Mode != batchModeAtomic || len(out.Results) != 3 {
+		t.Fatalf("atomic batch = %+v, want 3 results, not committed", out)
+	}
+	want := []string{batchOpRolledBack, batchOpFailed, batchOpSkipped}
+	for i, r := range out.Results {
+		if r.State != want[i] {
+			t.Fatalf("results[%d].state = %q, want %q", i, r.State, want[i])
+		}
+	}
+	if out.Results[1].Status != http.StatusBadRequest {
+		t.Fatalf("failed op status = %d, want 400", out.Results[1].Status)
+	}
+	resp = env.get(t, "/api/tasks?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if tasks := decodeJSON[[]core.Task](t, resp); len(tasks) != 0 {
+		t.Fatalf("rolled-back batch left %d tasks", len(tasks))
+	}
+
+	resp = env.post(t, "/api/batch", map[string]any{"mode": "best_effort", "ops": ops})
+	requireStatus(t, resp, http.StatusOK)
+	out = decodeJSON[batchResponse](t, resp)
+	if !out.Committed || out.Failed != 1 {
+		t.Fatalf("best-effort batch = %+v, want committed with 1 failure", out)
+	}
+	resp = env.get(t, "/api/tasks?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if tasks := decodeJSON[[]core.Task](t, resp); len(tasks) != 2 {
+		t.Fatalf("best-effort batch left %d tasks, want 2", len(tasks))
+	}
+
+	for _, p := range []string{"/api/agents", "/api/tasks/../projects", "/api/tasks/", "/api/tasks//x"} {
+		resp = env.post(t, "/api/batch", map[string]any{"ops": []map[string]any{
+			{"method": "POST", "path": p, "body": map[string]any{}},
+		}})
+		requireStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	}
+}
+
+func TestBatchAtomicCommitsInOrder(t *testing.T) {
+	env := newTestEnv(t)
+	resp := env.post(t, "/api/specs", core.Spec{Project: "proj", Title: "Spec", Status: core.SpecStatusDraft})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+
+	resp = env.post(t, "/api/batch", map[string]any{"ops": []map[string]any{
+		{"method": "POST", "path": "/api/epics", "body": map[string]any{"project": "proj", "spec_id": spec.ID, "title": "Epic"}},
+		{"method": "DELETE", "path": "/api/specs/" + spec.ID + "?project=proj"},
+	}})
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[batchResponse](t, resp)
+	if !out.Committed || out.Failed != 0 {
+		t.Fatalf("batch = %+v, want committed", out)
+	}
+	if out.Results[0].Status != http.StatusCreated || out.Results[1].Status != http.StatusNoContent {
+		t.Fatalf("statuses = %d, %d", out.Results[0].Status, out.Results[1].Status)
+	}
+	resp = env.get(t, "/api/specs/"+spec.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -0,0 +1,206 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Message size limits and blobs
+//
+// Message bodies are stored inline and read by every inbox query, so they
+// are capped (MessageLimits.MaxBody). A larger payload, such as a big diff,
+// is uploaded once to POST /api/blobs and the message lists it under
+// attachments by blob ID; recipients fetch the bytes from
+// GET /api/blobs/{id} when they want them.
+
+const (
+	// DefaultMaxMessageBody is the message body cap when none is configured.
+	DefaultMaxMessageBody = 256 << 10
+	// DefaultMaxBlobSize is the blob size cap when none is configured.
+	DefaultMaxBlobSize = 32 << 20
+	// maxAttachments caps the blobs one message can refer to.
+	maxAttachments = 20
+)
+
+// MessageLimits bounds what a send may carry. Zero fields take the
+// defaults.
+type MessageLimits struct {
+	MaxBody     int   // bytes of message body
+	MaxBlobSize int64 // bytes per uploaded blob
+}
+
+func (l MessageLimits) maxBody() int {
+	if l.MaxBody > 0 {
+		return l.MaxBody
+	}
+	return DefaultMaxMessageBody
+}
+
+func (l MessageLimits) maxBlobSize() int64 {
+	if l.MaxBlobSize > 0 {
+		return l.MaxBlobSize
+	}
+	return DefaultMaxBlobSize
+}
+
+// WithMessageLimits sets the message body and blob size caps.
+func (s *Service) WithMessageLimits(limits MessageLimits) *Service {
+	s.limits = limits
+	return s
+}
+
+// WithMessageLimits sets the message body and blob size caps.
+func (s *DomainService) WithMessageLimits(limits MessageLimits) *DomainService {
+	s.Service.WithMessageLimits(limits)
+	return s
+}
+
+// limitSendBody caps a send request: the largest allowed message body
+// plus room for the rest of the envelope, so an oversized body is reported
+// as message_too_large rather than cut off mid-JSON.
+func (s *Service) limitSendBody(w http.ResponseWriter, r *http.Request) {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(s.limits.maxBody())+maxRequestBody)
+	}
+}
+
+// checkMessageBody rejects a body over the configured cap with 413
+// message_too_large.
+func (s *Service) checkMessageBody(w http.ResponseWriter, body string) bool {
+	limit := s.limits.maxBody()
+	if len(body) <= limit {
+		return true
+	}
+	writeJSONErrorFields(w, http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("message body is %d bytes, over the %d byte limit; upload it to /api/blobs and attach it", len(body), limit),
+		"message_too_large", map[string]any{"size": len(body), "limit": limit})
+	return false
+}
+
+// resolveAttachments looks up the blobs a send refers to, filling in their
+// size and content type. An unknown blob is a 400 unknown_blob.
+func (s *Service) resolveAttachments(ctx context.Context, w http.ResponseWriter, project string, refs []core.Attachment) ([]core.Attachment, bool) {
+	if len(refs) > maxAttachments {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("a message can have at most %d attachments", maxAttachments), "invalid_request")
+		return nil, false
+	}
+	var out []core.Attachment
+	for _, ref := range refs {
+		blob, err := s.store.BlobInfo(ctx, project, ref.BlobID)
+		if errors.Is(err, core.ErrNotFound) {
+			writeJSONError(w, http.StatusBadRequest, "unknown blob "+ref.BlobID, "unknown_blob")
+			return nil, false
+		}
+		if err != nil {
+			writeInternalError(w)
+			return nil, false
+		}
+		out = append(out, core.Attachment{BlobID: blob.ID, Name: ref.Name, Size: blob.Size, ContentType: blob.ContentType})
+	}
+	return out, true
+}
+
+// handleBlobs stores the raw request body as a blob. The project comes from
+// the API key or ?project=, and the request's Content-Type is kept.
+func (s *Service) handleBlobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	limit := s.limits.maxBlobSize()
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			writeJSONErrorFields(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("blob is over the %d byte limit", limit), "blob_too_large", map[string]any{"limit": limit})
+			return
+		}
+		writeJSONError(w, http.StatusBadRequest, "read blob: "+err.Error(), "invalid_request")
+		return
+	}
+	if len(data) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "blob is empty", "missing_field")
+		return
+	}
+	blob, err := s.store.PutBlob(r.Context(), core.Blob{Project: project, ContentType: r.Header.Get("Content-Type")}, data)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(blob)
+}
+
+// inlineBlobTypes are the media types a blob is served as. Anything else,
+// HTML and SVG included, goes out as application/octet-stream so an
+// uploaded blob can't run script in a browser on the API's origin.
+var inlineBlobTypes = map[string]bool{
+	"text/plain":       true,
+	"text/csv":         true,
+	"text/markdown":    true,
+	"text/x-diff":      true,
+	"text/x-patch":     true,
+	"application/json": true,
+	"application/pdf":  true,
+	"application/zip":  true,
+	"application/gzip": true,
+	"image/png":        true,
+	"image/jpeg":       true,
+	"image/gif":        true,
+	"image/webp":       true,
+}
+
+// blobServeType is the Content-Type to serve a blob stored as contentType.
+func blobServeType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !inlineBlobTypes[mediaType] {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+// handleBlobByID serves a blob's bytes with its stored content type, if
+// that is one of inlineBlobTypes.
+func (s *Service) handleBlobByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/blobs/"), "/")
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	blob, data, err := s.store.GetBlob(r.Context(), project, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", blobServeType(blob.ContentType))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("X-Blob-SHA256", blob.SHA256)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestMessageBodyLimitAndBlobAttachments(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	big := strings.Repeat("x", DefaultMaxMessageBody+1)
+	resp := env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "alice", "to": []string{"bob"}, "body": big,
+	})
+	requireStatus(t, resp, http.StatusRequestEntityTooLarge)
+	if body := decodeJSON[map[string]any](t, resp); body["code"] != "message_too_large" {
+		t.Fatalf("expected message_too_large, got %v", body)
+	}
+
+	upload := func() core.Blob {
+		t.Helper()
+		resp, err := http.Post(env.srv.URL+"/api/blobs?project="+project, "text/x-diff", bytes.NewReader([]byte(big)))
+		if err != nil {
+			t.Fatalf("POST blob: %v", err)
+		}
+		requireStatus(t, resp, http.StatusCreated)
+		return decodeJSON[core.Blob](t, resp)
+	}
+	blob := upload()
+	if blob.Size != int64(len(big)) || blob.SHA256 == "" {
+		t.Fatalf("unexpected blob: %+v", blob)
+	}
+	if again := upload(); again.ID != blob.ID {
+		t.Errorf("same bytes should return the same blob, got %s and %s", blob.ID, again.ID)
+	}
+
+	resp = env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "alice", "to": []string{"bob"}, "body": "see diff",
+		"attachments": []map[string]any{{"blob_id": "missing"}},
+	})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "alice", "to": []string{"bob"}, "body": "see diff",
+		"attachments": []map[string]any{{"blob_id": blob.ID, "name": "change.diff"}},
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/inbox/bob?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	msgs := decodeJSON[inboxResponse](t, resp).Messages
+	if len(msgs) != 1 || len(msgs[0].Attachments) != 1 {
+		t.Fatalf("expected one message with one attachment, got %+v", msgs)
+	}
+	att := msgs[0].Attachments[0]
+	if att.BlobID != blob.ID || att.Name != "change.diff" || att.Size != blob.Size || att.ContentType != "text/x-diff" {
+		t.Errorf("unexpected attachment: %+v", att)
+	}
+
+	resp = env.get(t, "/api/blobs/"+blob.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != big || resp.Header.Get("Content-Type") != "text/x-diff" {
+		t.Errorf("blob round trip failed: %d bytes, content type %q", len(data), resp.Header.Get("Content-Type"))
+	}
+
+	resp = env.get(t, "/api/blobs/"+blob.ID+"?project=other")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestBlobServedWithoutActiveContent(t *testing.T) {
+	env := newTestEnv(t)
+	resp, err := http.Post(env.srv.URL+"/api/blobs?project=proj", "text/html; charset=utf-8",
+		bytes.NewReader([]byte("<script>alert(1)</script>")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireStatus(t, resp, http.StatusCreated)
+	blob := decodeJSON[core.Blob](t, resp)
+
+	resp = env.get(t, "/api/blobs/"+blob.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("HTML blob served as %q, want application/octet-stream", got)
+	}
+	if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+}
@@ -0,0 +1,141 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Bulk status handlers. POST /api/stories/bulk-status and
+// /api/tasks/bulk-status move many entities to new statuses at once. Each
+// item is applied as a PUT of the stored entity with only its status and
+// version changed, so transition checks, events and hooks behave exactly
+// as they would one at a time. Like a batch, items share one transaction
+// unless mode is best_effort. A dry_run evaluates every item in a
+// transaction it always rolls back, so callers see each item's outcome
+// (transition check, version) before closing out a sprint for real.
+
+// bulkModeDryRun is the bulk-status mode that applies nothing.
+const bulkModeDryRun = "dry_run"
+
+// bulkStatusItem moves one entity to Status. A zero Version applies the
+// change at whatever version is stored.
+type bulkStatusItem struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+	Status  string `json:"status"`
+}
+
+// bulkStatusRequest lists its items, or moves IDs to Status, which is also
+// the default for items that leave theirs out.
+type bulkStatusRequest struct {
+	Project string           `json:"project"`
+	Mode    string           `json:"mode"`
+	Status  string           `json:"status,omitempty"`
+	IDs     []string         `json:"ids,omitempty"`
+	Items   []bulkStatusItem `json:"items"`
+}
+
+// handleBulkStatus serves bulk-status for the entities under collection,
+// e.g. "/api/tasks".
+func (s *DomainService) handleBulkStatus(collection string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w)
+			return
+		}
+		limitBody(w, r)
+		var req bulkStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeInvalidJSON(w, err)
+			return
+		}
+		if req.Mode == "" {
+			req.Mode = batchModeAtomic
+		}
+		if req.Mode != batchModeAtomic && req.Mode != batchModeBestEffort && req.Mode != bulkModeDryRun {
+			writeJSONError(w, http.StatusBadRequest, "mode must be atomic, best_effort or dry_run", "invalid_batch")
+			return
+		}
+		for _, id := range req.IDs {
+			req.Items = append(req.Items, bulkStatusItem{ID: id})
+		}
+		if len(req.Items) == 0 || len(req.Items) > maxBatchOps {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("bulk status needs 1 to %d items", maxBatchOps), "invalid_batch")
+			return
+		}
+		for i := range req.Items {
+			item := &req.Items[i]
+			if item.Status == "" {
+				item.Status = req.Status
+			}
+			if item.ID == "" || item.Status == "" {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("items[%d]: id and status are required", i), "missing_field")
+				return
+			}
+		}
+
+		run := s.runOps
+		if req.Mode == bulkModeDryRun {
+			run = s.evaluateOps
+		}
+		resp, err := run(r, req.Mode, len(req.Items), func(h http.Handler, i int) batchOpResult {
+			res := s.applyBulkStatus(h, r, collection, req.Project, i, req.Items[i])
+			res.ID = req.Items[i].ID
+			return res
+		})
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// applyBulkStatus reads item's entity through h and PUTs it back with the
+// new status and the caller's version, if one was given.
+func (s *DomainService) applyBulkStatus(h http.Handler, r *http.Request, collection, project string, i int, item bulkStatusItem) batchOpResult {
+	path := collection + "/" + url.PathEscape(item.ID)
+	got := runBatchOp(h, r, i, batchOp{Method: http.MethodGet, Path: path + "?project=" + url.QueryEscape(project)})
+	if got.State == batchOpFailed {
+		return got
+	}
+	var entity map[string]json.RawMessage
+	if err := json.Unmarshal(got.Body, &entity); err != nil {
+		return batchOpResult{Index: i, State: batchOpFailed, Status: http.StatusInternalServerError}
+	}
+	entity["status"], _ = json.Marshal(item.Status)
+	if item.Version != 0 {
+		entity["version"], _ = json.Marshal(item.Version)
+	}
+	body, _ := json.Marshal(entity)
+	return runBatchOp(h, r, i, batchOp{Method: http.MethodPut, Path: path, Body: body})
+}
+
+// evaluateOps runs all n operations in one transaction and rolls it back.
+// Unlike an atomic run it doesn't stop at a failure: each result says
+// whether that operation would apply after the ones before it.
+func (s *DomainService) evaluateOps(r *http.Request, mode string, n int, run func(h http.Handler, i int) batchOpResult) (batchResponse, error) {
+	resp := batchResponse{Mode: mode}
+	defer eventCursorCheckpoint(r.Context())()
+	err := s.domainStore.RunInTx(r.Context(), func(tx storage.DomainStore) bool {
+		h := NewDomainRouter(s.boundTo(tx, &heldBroadcasts{}), nil, nil)
+		resp.Results, resp.Failed = resp.Results[:0], 0
+		for i := range n {
+			res := run(h, i)
+			if res.State == batchOpFailed {
+				resp.Failed++
+			}
+			resp.Results = append(resp.Results, res)
+		}
+		return false
+	})
+	if err != nil {
+		return batchResponse{}, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestBulkStatusIsAllOrNothing(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	a, _ := env.store.CreateStory(ctx, core.Story{Project: "proj", EpicID: "e1", Title: "A", Status: core.StoryStatusReview})
+	b, _ := env.store.CreateStory(ctx, core.Story{Project: "proj", EpicID: "e1", Title: "B", Status: core.StoryStatusReview})
+
+	resp := env.post(t, "/api/stories/bulk-status", map[string]any{"project": "proj", "items": []map[string]any{
+		{"id": a.ID, "version": a.Version, "status": "done"},
+		{"id": b.ID, "version": b.Version + 1, "status": "done"},
+	}})
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[batchResponse](t, resp)
+	if out.Committed || out.Results[0].State != batchOpRolledBack || out.Results[1].Status != http.StatusConflict {
+		t.Fatalf("stale version = %+v, want rollback on 409", out)
+	}
+	if got, _ := env.store.GetStory(ctx, "proj", a.ID); got.Status != core.StoryStatusReview {
+		t.Fatalf("story A = %s after rollback, want review", got.Status)
+	}
+
+	resp = env.post(t, "/api/stories/bulk-status", map[string]any{"project": "proj", "items": []map[string]any{
+		{"id": a.ID, "version": a.Version, "status": "done"},
+		{"id": b.ID, "version": b.Version, "status": "done"},
+	}})
+	requireStatus(t, resp, http.StatusOK)
+	out = decodeJSON[batchResponse](t, resp)
+	if !out.Committed || out.Results[1].ID != b.ID {
+		t.Fatalf("bulk status = %+v, want committed", out)
+	}
+	for _, id := range []string{a.ID, b.ID} {
+		got, _ := env.store.GetStory(ctx, "proj", id)
+		if got.Status != core.StoryStatusDone || got.Title == "" {
+			t.Fatalf("story %s = %+v, want done with fields kept", id, got)
+		}
+	}
+}
+
+func TestBulkStatusDryRunEvaluatesEveryItem(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	resp := env.put(t, "/api/admin/flags/strict_transitions.task", map[string]any{"project": "proj", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	pending, _ := env.store.CreateTask(ctx, core.Task{Project: "proj", Title: "A", Status: core.TaskStatusPending})
+	blocked, _ := env.store.CreateTask(ctx, core.Task{Project: "proj", Title: "B", Status: core.TaskStatusBlocked})
+	running, _ := env.store.CreateTask(ctx, core.Task{Project: "proj", Title: "C", Status: core.TaskStatusRunning})
+
+	resp = env.post(t, "/api/tasks/bulk-status", map[string]any{
+		"project": "proj", "mode": "dry_run", "status": "done",
+		"ids": []string{pending.ID, blocked.ID, running.ID},
+	})
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[batchResponse](t, resp)
+	if out.Committed || out.Failed != 1 || len(out.Results) != 3 {
+		t.Fatalf("dry run = %+v, want every item evaluated and one failure", out)
+	}
+	if out.Results[0].State != batchOpOK || out.Results[1].Status != http.StatusUnprocessableEntity || out.Results[2].State != batchOpOK {
+		t.Fatalf("dry run results = %+v, want blocked -> done refused", out.Results)
+	}
+	if got, _ := env.store.GetTask(ctx, "proj", pending.ID); got.Status != core.TaskStatusPending {
+		t.Fatalf("task after dry run = %s, want pending", got.Status)
+	}
+
+	resp = env.post(t, "/api/tasks/bulk-status", map[string]any{
+		"project": "proj", "status": "done", "ids": []string{pending.ID, running.ID},
+	})
+	requireStatus(t, resp, http.StatusOK)
+	if out := decodeJSON[batchResponse](t, resp); !out.Committed {
+		t.Fatalf("bulk status by ids = %+v, want committed at the stored versions", out)
+	}
+	for _, id := range []string{pending.ID, running.ID} {
+		if got, _ := env.store.GetTask(ctx, "proj", id); got.Status != core.TaskStatusDone {
+			t.Fatalf("task %s = %s, want done", id, got.Status)
+		}
+	}
+}
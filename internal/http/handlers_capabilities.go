@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// capabilitiesResponse describes what this deployment supports so clients
+// can adapt at runtime instead of probing endpoints and failing on 404s.
+type capabilitiesResponse struct {
+	Version     string                  `json:"version"`
+	Features    map[string]bool         `json:"features"`
+	Flags       map[string]bool         `json:"flags"`
+	Limits      capabilitiesLimit       `json:"limits"`
+	EventSchema capabilitiesEventSchema `json:"event_schema"`
+}
+
+// capabilitiesEventSchema is the event payload schema version events are
+// rendered at and the versions a subscriber may pin instead.
+type capabilitiesEventSchema struct {
+	Current   int   `json:"current"`
+	Supported []int `json:"supported"`
+}
+
+type capabilitiesLimit struct {
+	MaxBodyBytes           int `json:"max_body_bytes"`
+	MaxInboxWaitSeconds    int `json:"max_inbox_wait_seconds"`
+	BroadcastPerMinute     int `json:"broadcast_per_minute"`
+	LiveDeliveryPerMinute  int `json:"live_delivery_per_minute"`
+	RequestsPerMinute      int `json:"requests_per_minute"` // per caller; 0 means unlimited
+	SessionContextThreads  int `json:"session_context_max_threads"`
+	SessionContextMessages int `json:"session_context_max_messages"`
+}
+
+// WithVersion sets the server version reported by /api/capabilities.
+func (s *DomainService) WithVersion(v string) *DomainService {
+	s.version = v
+	return s
+}
+
+// WithGRPC records that the gRPC API is served alongside this one, for
+// /api/capabilities.
+func (s *DomainService) WithGRPC(enabled bool) *DomainService {
+	s.grpc = enabled
+	return s
+}
+
+// features reports which optional subsystems are enabled on this server.
+// Keys are stable; clients should treat a missing key as false.
+func (s *DomainService) features() map[string]bool {
+	return map[string]bool{
+		"websocket":        s.bus != nil,
+		"long_poll":        true,
+		"etag":             true,
+		"domain":           true,
+		"goals":            true,
+		"session_context":  true,
+		"metrics":          true,
+		"key_provisioning": s.keys != nil,
+		"story_threads":    s.storyThreads,
+		"webhooks":         false,
+		"fts":              true,
+		"grpc":             s.grpc,
+		"ha":               false,
+	}
+}
+
+func (s *DomainService) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	version := s.version
+	if version == "" {
+		version = "dev"
+	}
+	resp := capabilitiesResponse{
+		Version:  version,
+		Features: s.features(),
+		Flags:    s.requestFlags(r),
+		Limits: capabilitiesLimit{
+			MaxBodyBytes:           maxRequestBody,
+			MaxInboxWaitSeconds:    int(maxInboxWait.Seconds()),
+			BroadcastPerMinute:     broadcastRateLimit,
+			LiveDeliveryPerMinute:  liveRateLimit,
+			RequestsPerMinute:      s.requests.limit(),
+			SessionContextThreads:  maxContextThreads,
+			SessionContextMessages: maxContextMessages,
+		},
+		EventSchema: capabilitiesEventSchema{
+			Current:   core.EventSchemaVersion,
+			Supported: core.EventSchemaVersions(),
+		},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
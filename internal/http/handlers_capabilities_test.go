@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestCapabilities(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.get(t, "/api/capabilities")
+	requireStatus(t, resp, http.StatusOK)
+	caps := decodeJSON[capabilitiesResponse](t, resp)
+	if caps.Version != "dev" {
+		t.Fatalf("expected default version dev, got %q", caps.Version)
+	}
+	if !caps.Features["websocket"] || !caps.Features["long_poll"] {
+		t.Fatalf("expected websocket and long_poll enabled, got %v", caps.Features)
+	}
+	if caps.Features["key_provisioning"] {
+		t.Fatal("key_provisioning should be off without a provisioner")
+	}
+	if caps.Limits.MaxBodyBytes != maxRequestBody || caps.Limits.BroadcastPerMinute != broadcastRateLimit {
+		t.Fatalf("unexpected limits: %+v", caps.Limits)
+	}
+	if caps.EventSchema.Current != core.EventSchemaVersion || len(caps.EventSchema.Supported) != core.EventSchemaVersion-core.MinEventSchemaVersion+1 {
+		t.Fatalf("event_schema = %+v", caps.EventSchema)
+	}
+
+	resp = env.post(t, "/api/capabilities", map[string]any{})
+	requireStatus(t, resp, http.StatusMethodNotAllowed)
+	resp.Body.Close()
+}
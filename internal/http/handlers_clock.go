@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+)
+
+// Dev clock. Servers started with `serve --dev-clock` expose
+// /api/admin/clock so integration tests can move the server's clock
+// forward instead of sleeping through TTLs and heartbeat grace periods.
+// Without the flag the endpoint doesn't exist.
+
+// SweepRunner runs one reservation/snooze sweep on demand. Implemented by
+// *sqlite.Sweeper.
+type SweepRunner interface {
+	RunOnce(ctx context.Context)
+}
+
+// WithDevClock enables /api/admin/clock. After each advance, sweeper (if
+// non-nil) runs a pass so expirations land before the response.
+func (s *DomainService) WithDevClock(sweeper SweepRunner) *DomainService {
+	s.devClock = true
+	s.clockSweeper = sweeper
+	return s
+}
+
+type advanceClockRequest struct {
+	Advance string `json:"advance"`
+}
+
+type clockResponse struct {
+	Now    time.Time `json:"now"`
+	Offset string    `json:"offset"`
+}
+
+func (s *DomainService) handleAdminClock(w http.ResponseWriter, r *http.Request) {
+	if !s.devClock {
+		writeNotFound(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get:    s.getClock,
+		post:   s.advanceClock,
+		delete: s.resetClock,
+	})
+}
+
+func (s *DomainService) getClock(w http.ResponseWriter, r *http.Request) {
+	writeClock(w)
+}
+
+// advanceClock moves the clock forward by a Go duration ("90s", "2h").
+// The clock never goes backwards; DELETE returns it to wall time.
+func (s *DomainService) advanceClock(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req advanceClockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	d, err := time.ParseDuration(req.Advance)
+	if err != nil || d <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "advance must be a positive duration", "invalid_duration")
+		return
+	}
+	clock.Advance(d)
+	if s.clockSweeper != nil {
+		s.clockSweeper.RunOnce(r.Context())
+	}
+	writeClock(w)
+}
+
+func (s *DomainService) resetClock(w http.ResponseWriter, r *http.Request) {
+	clock.Reset()
+	writeClock(w)
+}
+
+func writeClock(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(clockResponse{Now: clock.Now().UTC(), Offset: clock.Offset().String()})
+}
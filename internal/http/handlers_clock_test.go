@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestDevClockDisabledByDefault(t *testing.T) {
+	env := newTestEnv(t)
+	resp := env.get(t, "/api/admin/clock")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestDevClockExpiresReservations(t *testing.T) {
+	t.Cleanup(clock.Reset)
+	st := storagetest.NewSQLite(t)
+	sweeper := sqlite.NewSweeper(st, nil, time.Hour, 5*time.Minute)
+	svc := NewDomainService(st).WithDevClock(sweeper)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+	env := &testEnv{srv: srv, store: st}
+
+	resp := env.post(t, "/api/reservations", map[string]any{
+		"agent_id": "agent-a", "project": "proj", "path_pattern": "cmd/*.go", "ttl_minutes": 10,
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/admin/clock", map[string]any{"advance": "-1h"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/admin/clock", map[string]any{"advance": "1h"})
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[clockResponse](t, resp); got.Offset != "1h0m0s" || time.Until(got.Now) < 59*time.Minute {
+		t.Fatalf("unexpected clock: %+v", got)
+	}
+	if n := sweeper.SweptTotal(); n != 1 {
+		t.Fatalf("expected the advance to sweep 1 reservation, swept %d", n)
+	}
+	resp = env.get(t, "/api/reservations?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if res := decodeJSON[reservationsResponse](t, resp).Reservations; len(res) != 0 {
+		t.Fatalf("expected no active reservations, got %d", len(res))
+	}
+
+	resp = env.delete(t, "/api/admin/clock")
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[clockResponse](t, resp); got.Offset != "0s" {
+		t.Fatalf("expected reset offset, got %q", got.Offset)
+	}
+}
@@ -1,11 +1,18 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/anomaly"
 	"github.com/mistakeknot/intermute/internal/auth"
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/livetransport"
@@ -17,12 +24,32 @@ type DomainService struct {
 	*Service
 	domainStore storage.DomainStore
 	pinger      Pinger
+	keys        KeyProvisioner
+	keyAdmin    KeyManager
+	version     string
+	breaker     BreakerStater
+	sweeper     SweepCounter
+
+	storyThreads bool
+	storage      StorageLimits
+	archiveDir   string
+	devClock     bool
+	clockSweeper SweepRunner
+	grpc         bool
+
+	requireProjects bool
+
+	system SystemNotifier
+
+	idemMu       sync.Mutex
+	idemInFlight map[string]struct{} // idempotency keys whose request is running
 }
 
 func NewDomainService(store storage.DomainStore) *DomainService {
 	return &DomainService{
-		Service:     NewService(store),
-		domainStore: store,
+		Service:      NewService(store),
+		domainStore:  store,
+		idemInFlight: map[string]struct{}{},
 	}
 }
 
@@ -44,6 +71,34 @@ func (s *DomainService) WithPinger(p Pinger) *DomainService {
 	return s
 }
 
+// WithKeyProvisioner lets POST /api/projects mint API keys for new
+// projects. Optional — without it, with_dev_key requests are rejected.
+func (s *DomainService) WithKeyProvisioner(p KeyProvisioner) *DomainService {
+	s.keys = p
+	return s
+}
+
+// WithKeyManager enables /api/admin/keys for listing and revoking API
+// keys. Optional — without it, those endpoints return 501.
+func (s *DomainService) WithKeyManager(m KeyManager) *DomainService {
+	s.keyAdmin = m
+	return s
+}
+
+// WithSystemNotifier sends operational events (storage threshold
+// crossings, failed retention purges) to n. Optional.
+func (s *DomainService) WithSystemNotifier(n SystemNotifier) *DomainService {
+	s.system = n
+	return s
+}
+
+// notifySystem sends an operational event to the system notifier, if any.
+func (s *DomainService) notifySystem(typ core.EventType, data map[string]any) {
+	if s.system != nil {
+		s.system.NotifySystem(core.SystemEvent{Type: typ, At: time.Now().UTC(), Data: data})
+	}
+}
+
 // Spec handlers
 
 func (s *DomainService) handleSpecs(w http.ResponseWriter, r *http.Request) {
@@ -54,10 +109,33 @@ func (s *DomainService) handleSpecs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DomainService) handleSpecByID(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/specs/")
-	id = strings.Trim(id, "/")
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	path := strings.TrimPrefix(r.URL.Path, "/api/specs/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
+		return
+	}
+	id := s.resolveEntityID(r, "spec", parts[0])
+
+	// Handle /api/specs/{id}/publish, /api/specs/{id}/published[/{n}],
+	// /api/specs/{id}/diff, /api/specs/{id}/export and /api/specs/{id}/review
+	if len(parts) >= 2 {
+		switch {
+		case parts[1] == "export" && len(parts) == 2:
+			s.exportSpec(w, r, id)
+		case parts[1] == "publish" && len(parts) == 2:
+			s.publishSpec(w, r, id)
+		case parts[1] == "diff" && len(parts) == 2:
+			s.diffSpec(w, r, id)
+		case parts[1] == "review" && len(parts) == 2:
+			s.reviewSpec(w, r, id)
+		case parts[1] == "published" && len(parts) == 2:
+			s.listPublishedSpecs(w, r, id)
+		case parts[1] == "published" && len(parts) == 3:
+			s.getPublishedSpec(w, r, id, parts[2])
+		default:
+			writeNotFound(w)
+		}
 		return
 	}
 
@@ -71,20 +149,44 @@ func (s *DomainService) handleSpecByID(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) createSpec(w http.ResponseWriter, r *http.Request) {
 	var spec core.Spec
 	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && spec.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, spec.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, spec.ID, func() (core.Spec, error) {
+		return s.domainStore.GetSpec(r.Context(), spec.Project, spec.ID)
+	}, func(current core.Spec) {
+		spec.Version = current.Version
+		s.saveSpec(w, r, spec)
+	}); handled {
+		return
+	}
+	scope := core.TitleScope{EntityType: "spec", Project: spec.Project, Title: spec.Title}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	created, err := s.domainStore.CreateSpec(r.Context(), spec)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetSpec(r.Context(), spec.Project, spec.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(spec.Project, core.EventSpecCreated, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), spec.Project, core.EventSpecCreated, created.ID, created)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -98,11 +200,16 @@ func (s *DomainService) getSpec(w http.ResponseWriter, r *http.Request, id strin
 	}
 	spec, err := s.domainStore.GetSpec(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
+		return
+	}
+	specs := []core.Spec{spec}
+	if err := s.markStale(r.Context(), specs); err != nil {
+		writeInternalError(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(spec)
+	json.NewEncoder(w).Encode(specs[0])
 }
 
 func (s *DomainService) listSpecs(w http.ResponseWriter, r *http.Request) {
@@ -114,9 +221,17 @@ func (s *DomainService) listSpecs(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
 	specs, err := s.domainStore.ListSpecs(r.Context(), project, status)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
+		return
+	}
+	specs = withoutArchived(r, "spec", specs, func(s core.Spec) string { return string(s.Status) })
+	if err := s.markStale(r.Context(), specs); err != nil {
+		writeInternalError(w)
 		return
 	}
+	if stale := r.URL.Query().Get("stale"); stale != "" {
+		specs = slices.DeleteFunc(specs, func(s core.Spec) bool { return s.Stale != (stale == "true") })
+	}
 	if specs == nil {
 		specs = []core.Spec{}
 	}
@@ -127,25 +242,53 @@ func (s *DomainService) listSpecs(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) updateSpec(w http.ResponseWriter, r *http.Request, id string) {
 	var spec core.Spec
 	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	spec.ID = id
+	s.saveSpec(w, r, spec)
+}
+
+// saveSpec writes spec over the stored spec with its ID, at spec.Version: a PUT,
+// or a create that replaces an existing spec with ?on_conflict=replace.
+func (s *DomainService) saveSpec(w http.ResponseWriter, r *http.Request, spec core.Spec) {
+	id := spec.ID
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && spec.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	prev, prevErr := s.domainStore.GetSpec(r.Context(), spec.Project, id)
+	if !s.checkTransition(w, r, spec.Project, "spec", string(spec.Status), func() (string, error) {
+		return string(prev.Status), prevErr
+	}) {
+		return
+	}
+	scope := core.TitleScope{EntityType: "spec", Project: spec.Project, Title: spec.Title, ExcludeID: id}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	updated, err := s.domainStore.UpdateSpec(r.Context(), spec)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrentModification) {
-			w.WriteHeader(http.StatusConflict)
+			current, getErr := s.domainStore.GetSpec(r.Context(), spec.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(spec.Project, core.EventSpecUpdated, updated.ID, updated)
+	s.broadcastDomainEvent(r.Context(), spec.Project, core.EventSpecUpdated, updated.ID, updated)
+	if prevErr == nil && prev.Status != updated.Status {
+		s.runLifecycleHooks(r.Context(), hookSubject{
+			Project: updated.Project, EntityType: "spec", ID: updated.ID, ShortID: updated.ShortID, Title: updated.Title,
+			From: string(prev.Status), To: string(updated.Status), StoryID: "",
+		})
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
@@ -157,10 +300,10 @@ func (s *DomainService) deleteSpec(w http.ResponseWriter, r *http.Request, id st
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.DeleteSpec(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(project, core.EventSpecArchived, id, nil)
+	s.broadcastDomainEvent(r.Context(), project, core.EventSpecArchived, id, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -177,9 +320,10 @@ func (s *DomainService) handleEpicByID(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/api/epics/")
 	id = strings.Trim(id, "/")
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
 		return
 	}
+	id = s.resolveEntityID(r, "epic", id)
 
 	dispatchByMethod(w, r, methodHandlers{
 		get:    func(w http.ResponseWriter, r *http.Request) { s.getEpic(w, r, id) },
@@ -191,20 +335,44 @@ func (s *DomainService) handleEpicByID(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) createEpic(w http.ResponseWriter, r *http.Request) {
 	var epic core.Epic
 	if err := json.NewDecoder(r.Body).Decode(&epic); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && epic.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, epic.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, epic.ID, func() (core.Epic, error) {
+		return s.domainStore.GetEpic(r.Context(), epic.Project, epic.ID)
+	}, func(current core.Epic) {
+		epic.Version = current.Version
+		s.saveEpic(w, r, epic)
+	}); handled {
+		return
+	}
+	scope := core.TitleScope{EntityType: "epic", Project: epic.Project, ParentID: epic.SpecID, Title: epic.Title}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	created, err := s.domainStore.CreateEpic(r.Context(), epic)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetEpic(r.Context(), epic.Project, epic.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(epic.Project, core.EventEpicCreated, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), epic.Project, core.EventEpicCreated, created.ID, created)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -218,7 +386,7 @@ func (s *DomainService) getEpic(w http.ResponseWriter, r *http.Request, id strin
 	}
 	epic, err := s.domainStore.GetEpic(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -234,9 +402,10 @@ func (s *DomainService) listEpics(w http.ResponseWriter, r *http.Request) {
 	specID := r.URL.Query().Get("spec")
 	epics, err := s.domainStore.ListEpics(r.Context(), project, specID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	epics = withoutArchived(r, "epic", epics, func(e core.Epic) string { return string(e.Status) })
 	if epics == nil {
 		epics = []core.Epic{}
 	}
@@ -247,25 +416,53 @@ func (s *DomainService) listEpics(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) updateEpic(w http.ResponseWriter, r *http.Request, id string) {
 	var epic core.Epic
 	if err := json.NewDecoder(r.Body).Decode(&epic); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	epic.ID = id
+	s.saveEpic(w, r, epic)
+}
+
+// saveEpic writes epic over the stored epic with its ID, at epic.Version: a PUT,
+// or a create that replaces an existing epic with ?on_conflict=replace.
+func (s *DomainService) saveEpic(w http.ResponseWriter, r *http.Request, epic core.Epic) {
+	id := epic.ID
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && epic.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	prev, prevErr := s.domainStore.GetEpic(r.Context(), epic.Project, id)
+	if !s.checkTransition(w, r, epic.Project, "epic", string(epic.Status), func() (string, error) {
+		return string(prev.Status), prevErr
+	}) {
+		return
+	}
+	scope := core.TitleScope{EntityType: "epic", Project: epic.Project, ParentID: epic.SpecID, Title: epic.Title, ExcludeID: id}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	updated, err := s.domainStore.UpdateEpic(r.Context(), epic)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrentModification) {
-			w.WriteHeader(http.StatusConflict)
+			current, getErr := s.domainStore.GetEpic(r.Context(), epic.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(epic.Project, core.EventEpicUpdated, updated.ID, updated)
+	s.broadcastDomainEvent(r.Context(), epic.Project, core.EventEpicUpdated, updated.ID, updated)
+	if prevErr == nil && prev.Status != updated.Status {
+		s.runLifecycleHooks(r.Context(), hookSubject{
+			Project: updated.Project, EntityType: "epic", ID: updated.ID, ShortID: updated.ShortID, Title: updated.Title,
+			From: string(prev.Status), To: string(updated.Status), StoryID: "",
+		})
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
@@ -277,7 +474,7 @@ func (s *DomainService) deleteEpic(w http.ResponseWriter, r *http.Request, id st
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.DeleteEpic(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -293,10 +490,16 @@ func (s *DomainService) handleStories(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DomainService) handleStoryByID(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/stories/")
-	id = strings.Trim(id, "/")
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	path := strings.TrimPrefix(r.URL.Path, "/api/stories/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
+		return
+	}
+	id := s.resolveEntityID(r, "story", parts[0])
+
+	if len(parts) == 2 && parts[1] == "rank" {
+		s.rankStory(w, r, id)
 		return
 	}
 
@@ -310,20 +513,52 @@ func (s *DomainService) handleStoryByID(w http.ResponseWriter, r *http.Request)
 func (s *DomainService) createStory(w http.ResponseWriter, r *http.Request) {
 	var story core.Story
 	if err := json.NewDecoder(r.Body).Decode(&story); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
+		return
+	}
+	if story.Priority != "" && !core.ValidPriority(story.Priority) {
+		writeJSONError(w, http.StatusBadRequest, "invalid priority", "invalid_priority")
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && story.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, story.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, story.ID, func() (core.Story, error) {
+		return s.domainStore.GetStory(r.Context(), story.Project, story.ID)
+	}, func(current core.Story) {
+		story.Version = current.Version
+		s.saveStory(w, r, story)
+	}); handled {
+		return
+	}
+	scope := core.TitleScope{EntityType: "story", Project: story.Project, ParentID: story.EpicID, Title: story.Title}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	created, err := s.domainStore.CreateStory(r.Context(), story)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetStory(r.Context(), story.Project, story.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(story.Project, core.EventStoryCreated, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), story.Project, core.EventStoryCreated, created.ID, created)
+	s.observe(func(d *anomaly.Detector) []core.Anomaly {
+		d.ObserveStatus(created.Project, "story/"+created.ID, string(created.Status))
+		return nil
+	})
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -337,7 +572,7 @@ func (s *DomainService) getStory(w http.ResponseWriter, r *http.Request, id stri
 	}
 	story, err := s.domainStore.GetStory(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -353,9 +588,10 @@ func (s *DomainService) listStories(w http.ResponseWriter, r *http.Request) {
 	epicID := r.URL.Query().Get("epic")
 	stories, err := s.domainStore.ListStories(r.Context(), project, epicID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	stories = withoutArchived(r, "story", stories, func(s core.Story) string { return string(s.Status) })
 	if stories == nil {
 		stories = []core.Story{}
 	}
@@ -366,25 +602,61 @@ func (s *DomainService) listStories(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) updateStory(w http.ResponseWriter, r *http.Request, id string) {
 	var story core.Story
 	if err := json.NewDecoder(r.Body).Decode(&story); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	story.ID = id
+	s.saveStory(w, r, story)
+}
+
+// saveStory writes story over the stored story with its ID, at story.Version: a PUT,
+// or a create that replaces an existing story with ?on_conflict=replace.
+func (s *DomainService) saveStory(w http.ResponseWriter, r *http.Request, story core.Story) {
+	id := story.ID
+	if story.Priority != "" && !core.ValidPriority(story.Priority) {
+		writeJSONError(w, http.StatusBadRequest, "invalid priority", "invalid_priority")
+		return
+	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && story.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	prev, prevErr := s.domainStore.GetStory(r.Context(), story.Project, id)
+	if !s.checkTransition(w, r, story.Project, "story", string(story.Status), func() (string, error) {
+		return string(prev.Status), prevErr
+	}) {
+		return
+	}
+	scope := core.TitleScope{EntityType: "story", Project: story.Project, ParentID: story.EpicID, Title: story.Title, ExcludeID: id}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	updated, err := s.domainStore.UpdateStory(r.Context(), story)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrentModification) {
-			w.WriteHeader(http.StatusConflict)
+			current, getErr := s.domainStore.GetStory(r.Context(), story.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(story.Project, core.EventStoryUpdated, updated.ID, updated)
+	s.broadcastDomainEvent(r.Context(), story.Project, core.EventStoryUpdated, updated.ID, updated)
+	if prevErr == nil && prev.Status != updated.Status {
+		s.runLifecycleHooks(r.Context(), hookSubject{
+			Project: updated.Project, EntityType: "story", ID: updated.ID, ShortID: updated.ShortID, Title: updated.Title,
+			From: string(prev.Status), To: string(updated.Status), StoryID: updated.ID,
+		})
+	}
+	s.observe(func(d *anomaly.Detector) []core.Anomaly {
+		d.ObserveStatus(updated.Project, "story/"+updated.ID, string(updated.Status))
+		return nil
+	})
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
@@ -396,7 +668,7 @@ func (s *DomainService) deleteStory(w http.ResponseWriter, r *http.Request, id s
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.DeleteStory(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -415,15 +687,19 @@ func (s *DomainService) handleTaskByID(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/tasks/")
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	if len(parts) == 0 || parts[0] == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
 		return
 	}
-	id := parts[0]
+	id := s.resolveEntityID(r, "task", parts[0])
 
 	if len(parts) == 2 && parts[1] == "assign" {
 		s.assignTask(w, r, id)
 		return
 	}
+	if (len(parts) == 2 || len(parts) == 3) && parts[1] == "comments" {
+		s.handleTaskComments(w, r, id, parts[2:])
+		return
+	}
 
 	dispatchByMethod(w, r, methodHandlers{
 		get:    func(w http.ResponseWriter, r *http.Request) { s.getTask(w, r, id) },
@@ -435,20 +711,59 @@ func (s *DomainService) handleTaskByID(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) createTask(w http.ResponseWriter, r *http.Request) {
 	var task core.Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
+		return
+	}
+	if task.Priority != "" && !core.ValidPriority(task.Priority) {
+		writeJSONError(w, http.StatusBadRequest, "invalid priority", "invalid_priority")
+		return
+	}
+	if !validExpectedPaths(w, task.ExpectedPaths) || !validTaskBlock(w, task) {
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && task.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, task.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, task.ID, func() (core.Task, error) {
+		return s.domainStore.GetTask(r.Context(), task.Project, task.ID)
+	}, func(current core.Task) {
+		task.Version = current.Version
+		s.saveTask(w, r, task)
+	}); handled {
+		return
+	}
+	// Tasks inherit their story's priority unless the caller set one.
+	if task.Priority == "" && task.StoryID != "" {
+		if story, err := s.domainStore.GetStory(r.Context(), task.Project, task.StoryID); err == nil {
+			task.Priority = story.Priority
+		}
+	}
+	scope := core.TitleScope{EntityType: "task", Project: task.Project, ParentID: task.StoryID, Title: task.Title}
+	if !s.checkTitle(w, r, scope) {
 		return
 	}
 	created, err := s.domainStore.CreateTask(r.Context(), task)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetTask(r.Context(), task.Project, task.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(task.Project, core.EventTaskCreated, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), task.Project, core.EventTaskCreated, created.ID, created)
+	s.observe(func(d *anomaly.Detector) []core.Anomaly { return d.ObserveTask(created) })
+	created = s.afterTaskChange(r.Context(), created)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -462,7 +777,7 @@ func (s *DomainService) getTask(w http.ResponseWriter, r *http.Request, id strin
 	}
 	task, err := s.domainStore.GetTask(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -475,11 +790,13 @@ func (s *DomainService) listTasks(w http.ResponseWriter, r *http.Request) {
 	if project == "" {
 		project = r.URL.Query().Get("project")
 	}
-	status := r.URL.Query().Get("status")
-	agent := r.URL.Query().Get("agent")
-	tasks, err := s.domainStore.ListTasks(r.Context(), project, status, agent)
+	query, ok := taskQuery(w, r, project)
+	if !ok {
+		return
+	}
+	tasks, err := s.domainStore.QueryTasks(r.Context(), query)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	if tasks == nil {
@@ -492,41 +809,97 @@ func (s *DomainService) listTasks(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) updateTask(w http.ResponseWriter, r *http.Request, id string) {
 	var task core.Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	task.ID = id
+	s.saveTask(w, r, task)
+}
+
+// saveTask writes task over the stored task with its ID, at task.Version: a PUT,
+// or a create that replaces an existing task with ?on_conflict=replace.
+func (s *DomainService) saveTask(w http.ResponseWriter, r *http.Request, task core.Task) {
+	id := task.ID
+	if task.Priority != "" && !core.ValidPriority(task.Priority) {
+		writeJSONError(w, http.StatusBadRequest, "invalid priority", "invalid_priority")
+		return
+	}
+	if !validExpectedPaths(w, task.ExpectedPaths) || !validTaskBlock(w, task) {
+		return
+	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && task.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
 		return
 	}
-	updated, err := s.domainStore.UpdateTask(r.Context(), task)
+	prev, prevErr := s.domainStore.GetTask(r.Context(), task.Project, id)
+	if !s.checkTransition(w, r, task.Project, "task", string(task.Status), func() (string, error) {
+		return string(prev.Status), prevErr
+	}) {
+		return
+	}
+	advanceTo, ok := s.storyAdvanceTarget(w, r, task.Project)
+	if !ok {
+		return
+	}
+	scope := core.TitleScope{EntityType: "task", Project: task.Project, ParentID: task.StoryID, Title: task.Title, ExcludeID: id}
+	if !s.checkTitle(w, r, scope) {
+		return
+	}
+	var before *core.Task
+	if prevErr == nil {
+		before = &prev
+	}
+	updated, advanced, err := s.updateTaskAdvancingStory(r, task, before, advanceTo)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrentModification) {
-			w.WriteHeader(http.StatusConflict)
+			current, getErr := s.domainStore.GetTask(r.Context(), task.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateTitle) {
+			s.writeDuplicateTitle(w, r, scope)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	s.mirrorTaskChange(r.Context(), before, updated)
+	if before != nil && before.Status != updated.Status {
+		s.broadcastDomainEvent(r.Context(), task.Project, core.EventTaskStatusChanged, updated.ID,
+			taskStatusChange{Task: updated, From: before.Status, To: updated.Status})
+	}
 	if updated.Status == core.TaskStatusDone {
-		s.broadcastDomainEvent(task.Project, core.EventTaskCompleted, updated.ID, updated)
+		s.broadcastDomainEvent(r.Context(), task.Project, core.EventTaskCompleted, updated.ID, updated)
+	}
+	if advanced != nil {
+		s.announceStoryAdvance(r.Context(), advanced)
+	}
+	if updated.Status == core.TaskStatusBlocked {
+		s.flagStoryAtRisk(r.Context(), updated)
 	}
+	s.observe(func(d *anomaly.Detector) []core.Anomaly { return d.ObserveTask(updated) })
+	if before != nil && before.Status != updated.Status {
+		s.runLifecycleHooks(r.Context(), hookSubject{
+			Project: updated.Project, EntityType: "task", ID: updated.ID, ShortID: updated.ShortID, Title: updated.Title,
+			From: string(before.Status), To: string(updated.Status), StoryID: updated.StoryID,
+		})
+	}
+	updated = s.afterTaskChange(r.Context(), updated)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
 
 func (s *DomainService) assignTask(w http.ResponseWriter, r *http.Request, id string) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	var req struct {
 		Agent string `json:"agent"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -536,7 +909,12 @@ func (s *DomainService) assignTask(w http.ResponseWriter, r *http.Request, id st
 	}
 	task, err := s.domainStore.GetTask(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
+		return
+	}
+	if !s.checkTransition(w, r, project, "task", string(core.TaskStatusRunning), func() (string, error) {
+		return string(task.Status), nil
+	}) {
 		return
 	}
 	task.Agent = req.Agent
@@ -544,17 +922,35 @@ func (s *DomainService) assignTask(w http.ResponseWriter, r *http.Request, id st
 	updated, err := s.domainStore.UpdateTask(r.Context(), task)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrentModification) {
-			w.WriteHeader(http.StatusConflict)
+			current, getErr := s.domainStore.GetTask(r.Context(), project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(project, core.EventTaskAssigned, updated.ID, updated)
+	s.broadcastDomainEvent(r.Context(), project, core.EventTaskAssigned, updated.ID, updated)
+	s.mirrorTaskEvent(r.Context(), taskAssigned, updated)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
 
+// flagStoryAtRisk bubbles a blocked task up to its story: when the story is
+// high priority, planners get a story.at_risk event naming the blocker.
+func (s *DomainService) flagStoryAtRisk(ctx context.Context, task core.Task) {
+	if task.StoryID == "" {
+		return
+	}
+	story, err := s.domainStore.GetStory(ctx, task.Project, task.StoryID)
+	if err != nil || story.Priority != core.PriorityHigh {
+		return
+	}
+	s.broadcastDomainEvent(ctx, task.Project, core.EventStoryAtRisk, story.ID, map[string]any{
+		"story":        story,
+		"blocked_task": task,
+	})
+}
+
 func (s *DomainService) deleteTask(w http.ResponseWriter, r *http.Request, id string) {
 	info, _ := auth.FromContext(r.Context())
 	project := info.Project
@@ -562,7 +958,7 @@ func (s *DomainService) deleteTask(w http.ResponseWriter, r *http.Request, id st
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.DeleteTask(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -581,7 +977,7 @@ func (s *DomainService) handleInsightByID(w http.ResponseWriter, r *http.Request
 	path := strings.TrimPrefix(r.URL.Path, "/api/insights/")
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	if len(parts) == 0 || parts[0] == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
 		return
 	}
 	id := parts[0]
@@ -590,9 +986,15 @@ func (s *DomainService) handleInsightByID(w http.ResponseWriter, r *http.Request
 		s.linkInsight(w, r, id)
 		return
 	}
+	if len(parts) == 2 && parts[1] == "merge" {
+		s.mergeInsights(w, r, id)
+		return
+	}
 
 	dispatchByMethod(w, r, methodHandlers{
 		get:    func(w http.ResponseWriter, r *http.Request) { s.getInsight(w, r, id) },
+		put:    func(w http.ResponseWriter, r *http.Request) { s.updateInsight(w, r, id) },
+		patch:  func(w http.ResponseWriter, r *http.Request) { s.patchInsight(w, r, id) },
 		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteInsight(w, r, id) },
 	})
 }
@@ -600,20 +1002,54 @@ func (s *DomainService) handleInsightByID(w http.ResponseWriter, r *http.Request
 func (s *DomainService) createInsight(w http.ResponseWriter, r *http.Request) {
 	var insight core.Insight
 	if err := json.NewDecoder(r.Body).Decode(&insight); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
+		return
+	}
+	if insight.Status != "" && !core.ValidInsightStatus(insight.Status) {
+		writeJSONError(w, http.StatusBadRequest, "invalid status: "+string(insight.Status), "invalid_request")
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && insight.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, insight.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, insight.ID, func() (core.Insight, error) {
+		return s.domainStore.GetInsight(r.Context(), insight.Project, insight.ID)
+	}, func(current core.Insight) {
+		insight.ID, insight.Version = current.ID, current.Version
+		s.saveInsight(r.Context(), w, current, insight)
+	}); handled {
+		return
+	}
+	existing, found, ok := s.findDuplicateInsight(w, r, insight)
+	if !ok {
+		return
+	}
+	if found {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(existing)
 		return
 	}
 	created, err := s.domainStore.CreateInsight(r.Context(), insight)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetInsight(r.Context(), insight.Project, insight.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		if errors.Is(err, core.ErrDuplicateInsight) {
+			s.writeDuplicateInsight(w, r, insight)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(insight.Project, core.EventInsightCreated, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), insight.Project, core.EventInsightCreated, created.ID, created)
+	created = s.applyInsightRules(r.Context(), created)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -627,7 +1063,7 @@ func (s *DomainService) getInsight(w http.ResponseWriter, r *http.Request, id st
 	}
 	insight, err := s.domainStore.GetInsight(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -644,9 +1080,13 @@ func (s *DomainService) listInsights(w http.ResponseWriter, r *http.Request) {
 	category := r.URL.Query().Get("category")
 	insights, err := s.domainStore.ListInsights(r.Context(), project, specID, category)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	if status := core.InsightStatus(r.URL.Query().Get("status")); status != "" {
+		insights = slices.DeleteFunc(insights, func(i core.Insight) bool { return i.Status != status })
+	}
+	insights = withoutArchived(r, "insight", insights, func(i core.Insight) string { return string(i.Status) })
 	if insights == nil {
 		insights = []core.Insight{}
 	}
@@ -656,14 +1096,14 @@ func (s *DomainService) listInsights(w http.ResponseWriter, r *http.Request) {
 
 func (s *DomainService) linkInsight(w http.ResponseWriter, r *http.Request, id string) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	var req struct {
 		SpecID string `json:"spec_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -672,10 +1112,10 @@ func (s *DomainService) linkInsight(w http.ResponseWriter, r *http.Request, id s
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.LinkInsightToSpec(r.Context(), project, id, req.SpecID); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(project, core.EventInsightLinked, id, map[string]string{"spec_id": req.SpecID})
+	s.broadcastDomainEvent(r.Context(), project, core.EventInsightLinked, id, map[string]string{"spec_id": req.SpecID})
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -686,7 +1126,7 @@ func (s *DomainService) deleteInsight(w http.ResponseWriter, r *http.Request, id
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.DeleteInsight(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -702,10 +1142,25 @@ func (s *DomainService) handleSessions(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DomainService) handleSessionByID(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
-	id = strings.Trim(id, "/")
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	path := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
+		return
+	}
+	id := parts[0]
+
+	// Handle /api/sessions/{id}/context
+	if len(parts) >= 2 {
+		switch parts[1] {
+		case "context":
+			s.getSessionContext(w, r, id)
+			return
+		case "heartbeat":
+			s.heartbeatSession(w, r, id)
+			return
+		}
+		writeNotFound(w)
 		return
 	}
 
@@ -719,20 +1174,23 @@ func (s *DomainService) handleSessionByID(w http.ResponseWriter, r *http.Request
 func (s *DomainService) createSession(w http.ResponseWriter, r *http.Request) {
 	var session core.Session
 	if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && session.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, session.Project) {
 		return
 	}
 	created, err := s.domainStore.CreateSession(r.Context(), session)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(session.Project, core.EventSessionStarted, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), session.Project, core.EventSessionStarted, created.ID, created)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -746,7 +1204,7 @@ func (s *DomainService) getSession(w http.ResponseWriter, r *http.Request, id st
 	}
 	session, err := s.domainStore.GetSession(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -762,7 +1220,7 @@ func (s *DomainService) listSessions(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
 	sessions, err := s.domainStore.ListSessions(r.Context(), project, status)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	if sessions == nil {
@@ -775,49 +1233,126 @@ func (s *DomainService) listSessions(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) updateSession(w http.ResponseWriter, r *http.Request, id string) {
 	var session core.Session
 	if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	session.ID = id
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && session.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
 		return
 	}
 	updated, err := s.domainStore.UpdateSession(r.Context(), session)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrConcurrentModification) {
+			current, getErr := s.domainStore.GetSession(r.Context(), session.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
 
+// heartbeatSession records that session id is alive. From its first
+// heartbeat on, the sweeper stops the session if they lapse.
+func (s *DomainService) heartbeatSession(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	session, err := s.domainStore.HeartbeatSession(r.Context(), project, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
 func (s *DomainService) deleteSession(w http.ResponseWriter, r *http.Request, id string) {
 	info, _ := auth.FromContext(r.Context())
 	project := info.Project
 	if project == "" {
 		project = r.URL.Query().Get("project")
 	}
+	// session.stopped carries the session's last state so its history
+	// (see SessionHistory) outlives it.
+	var last any
+	if session, err := s.domainStore.GetSession(r.Context(), project, id); err == nil {
+		last = session
+	}
 	if err := s.domainStore.DeleteSession(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(project, core.EventSessionStopped, id, nil)
+	s.broadcastDomainEvent(r.Context(), project, core.EventSessionStopped, id, last)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Helper to broadcast domain events
-func (s *DomainService) broadcastDomainEvent(project string, eventType core.EventType, entityID string, data any) {
+// Helper to persist and broadcast domain events. Each gets a fresh event_id
+// for consumers to dedupe on; the acting agent, when known, is included as
+// "actor". The event is appended to the log (see /api/events) before it is
+// broadcast, and its cursor rides along; a failed append is logged but
+// doesn't hold back the broadcast.
+func (s *DomainService) broadcastDomainEvent(ctx context.Context, project string, eventType core.EventType, entityID string, data any) {
+	eventID := uuid.NewString()
+	actor := core.ActorFromContext(ctx)
+	cursor, err := s.persistDomainEvent(ctx, core.Event{
+		ID:         eventID,
+		Type:       eventType,
+		Actor:      actor,
+		Project:    project,
+		EntityType: eventType.EntityType(),
+		EntityID:   entityID,
+	}, data)
+	if err != nil {
+		log.Printf("WARN: persist %s event for %s: %v", eventType, entityID, err)
+	}
 	if s.bus == nil {
 		return
 	}
-	s.bus.Broadcast(project, "", map[string]any{
+	event := map[string]any{
 		"type":      string(eventType),
+		"event_id":  eventID,
 		"project":   project,
 		"entity_id": entityID,
 		"data":      data,
-	})
+	}
+	if actor != "" {
+		event["actor"] = actor
+	}
+	if cursor > 0 {
+		event["cursor"] = cursor
+	}
+	addTrace(event, core.TraceFromContext(ctx))
+	s.bus.Broadcast(project, "", event)
+}
+
+func (s *DomainService) persistDomainEvent(ctx context.Context, ev core.Event, data any) (uint64, error) {
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return 0, err
+		}
+		ev.Data = string(b)
+	}
+	cursor, err := s.domainStore.AppendEvent(ctx, ev)
+	if err == nil {
+		noteEventCursor(ctx, cursor)
+	}
+	return cursor, err
 }
 
 // CUJ (Critical User Journey) handlers
@@ -833,7 +1368,7 @@ func (s *DomainService) handleCUJByID(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/cujs/")
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	if len(parts) == 0 || parts[0] == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
 		return
 	}
 	id := parts[0]
@@ -863,20 +1398,36 @@ func (s *DomainService) handleCUJByID(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) createCUJ(w http.ResponseWriter, r *http.Request) {
 	var cuj core.CriticalUserJourney
 	if err := json.NewDecoder(r.Body).Decode(&cuj); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && cuj.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, cuj.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, cuj.ID, func() (core.CriticalUserJourney, error) {
+		return s.domainStore.GetCUJ(r.Context(), cuj.Project, cuj.ID)
+	}, func(current core.CriticalUserJourney) {
+		cuj.Version = current.Version
+		s.saveCUJ(w, r, cuj)
+	}); handled {
 		return
 	}
 	created, err := s.domainStore.CreateCUJ(r.Context(), cuj)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetCUJ(r.Context(), cuj.Project, cuj.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(cuj.Project, core.EventCUJCreated, created.ID, created)
+	s.broadcastDomainEvent(r.Context(), cuj.Project, core.EventCUJCreated, created.ID, created)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -890,7 +1441,7 @@ func (s *DomainService) getCUJ(w http.ResponseWriter, r *http.Request, id string
 	}
 	cuj, err := s.domainStore.GetCUJ(r.Context(), project, id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -906,9 +1457,10 @@ func (s *DomainService) listCUJs(w http.ResponseWriter, r *http.Request) {
 	specID := r.URL.Query().Get("spec")
 	cujs, err := s.domainStore.ListCUJs(r.Context(), project, specID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	cujs = withoutArchived(r, "cuj", cujs, func(c core.CriticalUserJourney) string { return string(c.Status) })
 	if cujs == nil {
 		cujs = []core.CriticalUserJourney{}
 	}
@@ -919,13 +1471,26 @@ func (s *DomainService) listCUJs(w http.ResponseWriter, r *http.Request) {
 func (s *DomainService) updateCUJ(w http.ResponseWriter, r *http.Request, id string) {
 	var cuj core.CriticalUserJourney
 	if err := json.NewDecoder(r.Body).Decode(&cuj); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	cuj.ID = id
+	s.saveCUJ(w, r, cuj)
+}
+
+// saveCUJ writes cuj over the stored CUJ with its ID, at cuj.Version: a PUT,
+// or a create that replaces an existing CUJ with ?on_conflict=replace.
+func (s *DomainService) saveCUJ(w http.ResponseWriter, r *http.Request, cuj core.CriticalUserJourney) {
+	id := cuj.ID
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey && cuj.Project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.checkTransition(w, r, cuj.Project, "cuj", string(cuj.Status), func() (string, error) {
+		current, err := s.domainStore.GetCUJ(r.Context(), cuj.Project, id)
+		return string(current.Status), err
+	}) {
 		return
 	}
 
@@ -938,13 +1503,14 @@ func (s *DomainService) updateCUJ(w http.ResponseWriter, r *http.Request, id str
 	updated, err := s.domainStore.UpdateCUJ(r.Context(), cuj)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrentModification) {
-			w.WriteHeader(http.StatusConflict)
+			current, getErr := s.domainStore.GetCUJ(r.Context(), cuj.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(cuj.Project, eventType, updated.ID, updated)
+	s.broadcastDomainEvent(r.Context(), cuj.Project, eventType, updated.ID, updated)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
@@ -956,23 +1522,23 @@ func (s *DomainService) deleteCUJ(w http.ResponseWriter, r *http.Request, id str
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.DeleteCUJ(r.Context(), project, id); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
-	s.broadcastDomainEvent(project, core.EventCUJArchived, id, nil)
+	s.broadcastDomainEvent(r.Context(), project, core.EventCUJArchived, id, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *DomainService) linkCUJToFeature(w http.ResponseWriter, r *http.Request, cujID string) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	var req struct {
 		FeatureID string `json:"feature_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -981,7 +1547,7 @@ func (s *DomainService) linkCUJToFeature(w http.ResponseWriter, r *http.Request,
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.LinkCUJToFeature(r.Context(), project, cujID, req.FeatureID); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -989,14 +1555,14 @@ func (s *DomainService) linkCUJToFeature(w http.ResponseWriter, r *http.Request,
 
 func (s *DomainService) unlinkCUJFromFeature(w http.ResponseWriter, r *http.Request, cujID string) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	var req struct {
 		FeatureID string `json:"feature_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -1005,7 +1571,7 @@ func (s *DomainService) unlinkCUJFromFeature(w http.ResponseWriter, r *http.Requ
 		project = r.URL.Query().Get("project")
 	}
 	if err := s.domainStore.UnlinkCUJFromFeature(r.Context(), project, cujID, req.FeatureID); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -1013,7 +1579,7 @@ func (s *DomainService) unlinkCUJFromFeature(w http.ResponseWriter, r *http.Requ
 
 func (s *DomainService) getCUJFeatureLinks(w http.ResponseWriter, r *http.Request, cujID string) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -1023,7 +1589,7 @@ func (s *DomainService) getCUJFeatureLinks(w http.ResponseWriter, r *http.Reques
 	}
 	links, err := s.domainStore.GetCUJFeatureLinks(r.Context(), project, cujID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	if links == nil {
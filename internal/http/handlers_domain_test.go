@@ -96,7 +96,16 @@ func TestSpecHTTP(t *testing.T) {
 			"version": version - 1,
 		})
 		requireStatus(t, resp, http.StatusConflict)
-		resp.Body.Close()
+		// The body carries the current spec so the caller can merge
+		// without another GET.
+		conflict := decodeJSON[map[string]any](t, resp)
+		if conflict["code"] != "version_conflict" || int64(conflict["version"].(float64)) != version {
+			t.Fatalf("expected version_conflict at %d, got %v", version, conflict)
+		}
+		current, _ := conflict["current"].(map[string]any)
+		if current["title"] != "Updated Spec" {
+			t.Fatalf("expected current spec, got %v", conflict["current"])
+		}
 	})
 
 	t.Run("delete", func(t *testing.T) {
@@ -480,6 +489,7 @@ func TestSessionHTTP(t *testing.T) {
 			"name":    "tmux-main",
 			"agent":   "agent-a",
 			"status":  "idle",
+			"version": 1,
 		})
 		requireStatus(t, resp, http.StatusOK)
 		session := decodeJSON[map[string]any](t, resp)
@@ -488,6 +498,18 @@ func TestSessionHTTP(t *testing.T) {
 		}
 	})
 
+	t.Run("heartbeat", func(t *testing.T) {
+		resp := env.post(t, "/api/sessions/"+sessionID+"/heartbeat?project="+project, nil)
+		requireStatus(t, resp, http.StatusOK)
+		session := decodeJSON[map[string]any](t, resp)
+		if session["heartbeat_at"] == nil || session["status"] != "idle" || session["version"] != float64(2) {
+			t.Fatalf("heartbeat = %v, want heartbeat_at set and status/version unchanged", session)
+		}
+		resp = env.post(t, "/api/sessions/missing/heartbeat?project="+project, nil)
+		requireStatus(t, resp, http.StatusNotFound)
+		resp.Body.Close()
+	})
+
 	t.Run("delete", func(t *testing.T) {
 		resp := env.delete(t, "/api/sessions/"+sessionID+"?project="+project)
 		requireStatus(t, resp, http.StatusNoContent)
@@ -513,11 +535,11 @@ func TestCUJHTTP(t *testing.T) {
 
 	t.Run("create", func(t *testing.T) {
 		resp := env.post(t, "/api/cujs", map[string]any{
-			"project":    project,
-			"spec_id":    specID,
-			"title":      "Onboarding Flow",
-			"persona":    "new-user",
-			"priority":   "high",
+			"project":     project,
+			"spec_id":     specID,
+			"title":       "Onboarding Flow",
+			"persona":     "new-user",
+			"priority":    "high",
 			"entry_point": "landing page",
 			"exit_point":  "dashboard",
 			"steps": []map[string]any{
@@ -631,3 +653,40 @@ func TestDomainMethodNotAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestInsightUpdateHTTP(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj-test"
+
+	resp := env.post(t, "/api/insights", map[string]any{
+		"project":  project,
+		"source":   "pollard",
+		"category": "competitor",
+		"title":    "Original",
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	created := decodeJSON[map[string]any](t, resp)
+	id := created["id"].(string)
+
+	update := func(title string, version any) *http.Response {
+		return env.put(t, "/api/insights/"+id, map[string]any{
+			"project":  project,
+			"source":   "pollard",
+			"category": "competitor",
+			"title":    title,
+			"version":  version,
+		})
+	}
+
+	resp = update("Revised", created["version"])
+	requireStatus(t, resp, http.StatusOK)
+	updated := decodeJSON[map[string]any](t, resp)
+	if updated["title"] != "Revised" || updated["version"] != float64(2) {
+		t.Fatalf("expected Revised v2, got %v v%v", updated["title"], updated["version"])
+	}
+
+	// Stale version loses
+	resp = update("Stale", created["version"])
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+}
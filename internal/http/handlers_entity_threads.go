@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// entityThreadLabels are the entities a message can be addressed to with
+// entity_type and entity_id, with the subject prefix used when the send
+// has no subject.
+var entityThreadLabels = map[string]string{
+	"spec":  "Spec:",
+	"epic":  "Epic:",
+	"story": "Story:",
+	"task":  "Task:",
+}
+
+// handleDomainSendMessage is handleSendMessage plus entity addressing: a
+// send naming entity_type and entity_id is posted in that entity's
+// canonical thread (core.EntityThreadID), so every conversation about a
+// work item lands in one place.
+func (s *DomainService) handleDomainSendMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	s.limitSendBody(w, r)
+	req, ok := parseSendRequest(w, r)
+	if !ok {
+		return
+	}
+	if !s.resolveEntityThread(r.Context(), w, &req) {
+		return
+	}
+	s.sendMessage(w, r.Context(), req)
+}
+
+// resolveEntityThread points an entity-addressed send at the entity's
+// thread. entity_id may be a UUID or short ID; the thread is keyed by the
+// UUID. A thread_id naming a different thread is rejected.
+func (s *DomainService) resolveEntityThread(ctx context.Context, w http.ResponseWriter, req *sendMessageRequest) bool {
+	if req.EntityType == "" && req.EntityID == "" {
+		return true
+	}
+	if req.EntityType == "" || req.EntityID == "" {
+		writeJSONError(w, http.StatusBadRequest, "entity_type and entity_id are required together", "missing_field")
+		return false
+	}
+	label, ok := entityThreadLabels[req.EntityType]
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "entity_type must be one of spec, epic, story, task", "invalid_request")
+		return false
+	}
+	matches, err := s.domainStore.LookupEntity(ctx, strings.TrimSpace(req.Project), req.EntityID)
+	if err != nil {
+		writeInternalError(w)
+		return false
+	}
+	i := slices.IndexFunc(matches, func(m core.EntityRef) bool { return m.Type == req.EntityType })
+	if i < 0 {
+		writeJSONError(w, http.StatusNotFound, "no "+req.EntityType+" with this id", "not_found")
+		return false
+	}
+	ref := matches[i]
+	threadID := core.EntityThreadID(ref.Type, ref.ID)
+	if req.ThreadID != "" && req.ThreadID != threadID {
+		writeJSONErrorFields(w, http.StatusBadRequest, "thread_id differs from the entity's thread", "invalid_request", map[string]any{
+			"thread_id": threadID,
+		})
+		return false
+	}
+	req.ThreadID = threadID
+	if strings.TrimSpace(req.Subject) == "" {
+		req.Subject = prefixSubject(label, ref.Title)
+	}
+	return true
+}
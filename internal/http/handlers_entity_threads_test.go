@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestSendToEntityThread(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": project, "title": "Card form"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+
+	send := func(body map[string]any) *http.Response {
+		t.Helper()
+		body["project"] = project
+		body["from"] = "alice"
+		body["to"] = []string{"bob"}
+		return env.post(t, "/api/messages", body)
+	}
+
+	resp = send(map[string]any{"entity_type": "task", "entity_id": task.ID, "body": "starting"})
+	requireStatus(t, resp, http.StatusOK)
+	first := decodeJSON[sendMessageResponse](t, resp)
+	want := core.EntityThreadID("task", task.ID)
+	if first.ThreadID != want {
+		t.Fatalf("thread_id = %q, want %q", first.ThreadID, want)
+	}
+
+	// The short ID resolves to the same thread.
+	resp = send(map[string]any{"entity_type": "task", "entity_id": task.ShortID, "subject": "Review", "body": "done"})
+	requireStatus(t, resp, http.StatusOK)
+	if second := decodeJSON[sendMessageResponse](t, resp); second.ThreadID != want {
+		t.Fatalf("short ID thread_id = %q, want %q", second.ThreadID, want)
+	}
+
+	resp = env.get(t, "/api/threads/"+want+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	thread := decodeJSON[struct {
+		Messages []apiMessage `json:"messages"`
+	}](t, resp)
+	if len(thread.Messages) != 2 {
+		t.Fatalf("expected 2 messages in the task thread, got %d", len(thread.Messages))
+	}
+	if thread.Messages[0].Subject != "Task: Card form" || thread.Messages[1].Subject != "Review" {
+		t.Errorf("unexpected subjects: %q, %q", thread.Messages[0].Subject, thread.Messages[1].Subject)
+	}
+
+	for name, tc := range map[string]struct {
+		body   map[string]any
+		status int
+	}{
+		"unknown entity":   {map[string]any{"entity_type": "task", "entity_id": "missing", "body": "x"}, http.StatusNotFound},
+		"wrong type":       {map[string]any{"entity_type": "story", "entity_id": task.ID, "body": "x"}, http.StatusNotFound},
+		"unsupported type": {map[string]any{"entity_type": "goal", "entity_id": task.ID, "body": "x"}, http.StatusBadRequest},
+		"missing id":       {map[string]any{"entity_type": "task", "body": "x"}, http.StatusBadRequest},
+		"other thread":     {map[string]any{"entity_type": "task", "entity_id": task.ID, "thread_id": "t1", "body": "x"}, http.StatusBadRequest},
+	} {
+		resp := send(tc.body)
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: status %d, want %d", name, resp.StatusCode, tc.status)
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,146 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Domain event log handlers. Every domain event broadcast is also persisted;
+// these let a consumer replay one entity's history (?entity_type=task
+// &entity_id=...) without scanning the whole log, and compare counts with
+// what it has applied. ?schema_version= pins the envelope's version (see
+// core.EventAtVersion); version 1 leaves out schema_version.
+
+type apiDomainEvent struct {
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	Cursor        uint64          `json:"cursor"`
+	ID            string          `json:"event_id"`
+	Type          string          `json:"type"`
+	Project       string          `json:"project"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      string          `json:"entity_id"`
+	Actor         string          `json:"actor,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	CausationID   string          `json:"causation_id,omitempty"`
+	Data          json.RawMessage `json:"data,omitempty"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+type listEventsResponse struct {
+	Events []apiDomainEvent `json:"events"`
+	Cursor uint64           `json:"cursor"`
+}
+
+type countEventsResponse struct {
+	EntityType string `json:"entity_type,omitempty"`
+	EntityID   string `json:"entity_id,omitempty"`
+	Count      int    `json:"count"`
+}
+
+// eventFilter reads the shared ?entity_type=&entity_id=&cursor=&limit=
+// query. entity_id without entity_type is rejected: IDs are only unique per
+// entity type.
+func eventFilter(w http.ResponseWriter, r *http.Request) (core.EventFilter, bool) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return core.EventFilter{}, false
+	}
+	q := r.URL.Query()
+	f := core.EventFilter{
+		Project:    project,
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+	}
+	if f.EntityID != "" && f.EntityType == "" {
+		writeJSONError(w, http.StatusBadRequest, "entity_id requires entity_type", "invalid_request")
+		return core.EventFilter{}, false
+	}
+	if v := q.Get("cursor"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid cursor: "+err.Error(), "invalid_request")
+			return core.EventFilter{}, false
+		}
+		f.After = parsed
+	}
+	if v := q.Get("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			f.Limit = parsed
+		}
+	}
+	return f, true
+}
+
+func (s *DomainService) handleEvents(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get: s.listEvents,
+	})
+}
+
+func (s *DomainService) handleEventCount(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get: s.countEvents,
+	})
+}
+
+func (s *DomainService) listEvents(w http.ResponseWriter, r *http.Request) {
+	f, ok := eventFilter(w, r)
+	if !ok {
+		return
+	}
+	version, err := core.ParseEventSchemaVersion(r.URL.Query().Get("schema_version"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_schema_version")
+		return
+	}
+	events, err := s.domainStore.ListDomainEvents(r.Context(), f)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	resp := listEventsResponse{Events: make([]apiDomainEvent, 0, len(events)), Cursor: f.After}
+	for _, ev := range events {
+		out := apiDomainEvent{
+			Cursor:        ev.Cursor,
+			ID:            ev.ID,
+			Type:          string(ev.Type),
+			Project:       ev.Project,
+			EntityType:    ev.EntityType,
+			EntityID:      ev.EntityID,
+			Actor:         ev.Actor,
+			RequestID:     ev.Trace.RequestID,
+			CorrelationID: ev.Trace.CorrelationID,
+			CausationID:   ev.Trace.CausationID,
+			CreatedAt:     ev.CreatedAt.Format(time.RFC3339Nano),
+		}
+		if version >= 2 {
+			out.SchemaVersion = version
+		}
+		if ev.Data != "" {
+			out.Data = json.RawMessage(ev.Data)
+		}
+		resp.Events = append(resp.Events, out)
+		resp.Cursor = ev.Cursor
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *DomainService) countEvents(w http.ResponseWriter, r *http.Request) {
+	f, ok := eventFilter(w, r)
+	if !ok {
+		return
+	}
+	n, err := s.domainStore.CountDomainEvents(r.Context(), f)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(countEventsResponse{EntityType: f.EntityType, EntityID: f.EntityID, Count: n})
+}
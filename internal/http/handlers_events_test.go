@@ -0,0 +1,95 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestDomainEventLogFilters(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": "proj", "title": "first", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	first := decodeJSON[core.Spec](t, resp)
+	resp = env.post(t, "/api/specs", map[string]any{"project": "proj", "title": "second", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.post(t, "/api/specs", map[string]any{"project": "other", "title": "elsewhere", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	first.Title = "first, revised"
+	resp = env.put(t, "/api/specs/"+first.ID, first)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/events?project=proj&entity_type=spec&entity_id="+first.ID)
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[listEventsResponse](t, resp)
+	if len(got.Events) != 2 {
+		t.Fatalf("events = %+v, want created then updated", got.Events)
+	}
+	if got.Events[0].Type != string(core.EventSpecCreated) || got.Events[1].Type != string(core.EventSpecUpdated) {
+		t.Fatalf("types = %s, %s", got.Events[0].Type, got.Events[1].Type)
+	}
+	if got.Cursor != got.Events[1].Cursor || got.Events[0].Cursor >= got.Events[1].Cursor {
+		t.Fatalf("cursors = %d, %d (resume %d)", got.Events[0].Cursor, got.Events[1].Cursor, got.Cursor)
+	}
+	if got.Events[0].SchemaVersion != core.EventSchemaVersion {
+		t.Fatalf("schema_version = %d, want %d", got.Events[0].SchemaVersion, core.EventSchemaVersion)
+	}
+	resp = env.get(t, "/api/events?project=proj&schema_version=1&entity_type=spec&entity_id="+first.ID)
+	requireStatus(t, resp, http.StatusOK)
+	if v1 := decodeJSON[listEventsResponse](t, resp); len(v1.Events) != 2 || v1.Events[0].SchemaVersion != 0 {
+		t.Fatalf("version 1 events = %+v, want no schema_version", v1.Events)
+	}
+	var replayed core.Spec
+	if err := json.Unmarshal(got.Events[1].Data, &replayed); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if replayed.Title != "first, revised" {
+		t.Fatalf("replayed title = %q", replayed.Title)
+	}
+
+	// Resuming from the first cursor skips what was already applied.
+	resp = env.get(t, "/api/events?project=proj&entity_type=spec&entity_id="+first.ID+"&cursor="+strconv.FormatUint(got.Events[0].Cursor, 10))
+	requireStatus(t, resp, http.StatusOK)
+	if rest := decodeJSON[listEventsResponse](t, resp); len(rest.Events) != 1 || rest.Events[0].Type != string(core.EventSpecUpdated) {
+		t.Fatalf("resumed events = %+v", rest.Events)
+	}
+
+	resp = env.get(t, "/api/events/count?project=proj&entity_type=spec")
+	requireStatus(t, resp, http.StatusOK)
+	if c := decodeJSON[countEventsResponse](t, resp); c.Count != 3 {
+		t.Fatalf("project spec count = %d, want 3", c.Count)
+	}
+	resp = env.get(t, "/api/events/count?project=proj&entity_type=spec&entity_id="+first.ID)
+	requireStatus(t, resp, http.StatusOK)
+	if c := decodeJSON[countEventsResponse](t, resp); c.Count != 2 || c.EntityID != first.ID {
+		t.Fatalf("entity count = %+v, want 2", c)
+	}
+}
+
+func TestDomainEventLogValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.get(t, "/api/events?project=proj&entity_id=abc")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/events?project=proj&cursor=nope")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/events?project=proj&schema_version=99")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/events/count", map[string]any{})
+	requireStatus(t, resp, http.StatusMethodNotAllowed)
+	resp.Body.Close()
+}
@@ -0,0 +1,140 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Feature flag handlers. Flags are managed through the admin API; handlers
+// check them with flagEnabled.
+
+type setFlagRequest struct {
+	Project     string `json:"project"`
+	Enabled     bool   `json:"enabled"`
+	Description string `json:"description,omitempty"`
+}
+
+// validFlagName keeps flag names to lowercase identifiers so they are
+// stable as JSON keys.
+func validFlagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *DomainService) handleAdminFlags(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get: s.listFlags,
+	})
+}
+
+func (s *DomainService) handleAdminFlagByName(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/flags/"), "/")
+	if !validFlagName(name) {
+		writeNotFound(w)
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		put:    func(w http.ResponseWriter, r *http.Request) { s.setFlag(w, r, name) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteFlag(w, r, name) },
+	})
+}
+
+// listFlags returns every flag, or with ?project= only that project's flags
+// and the server-wide defaults.
+func (s *DomainService) listFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := s.domainStore.ListFeatureFlags(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	out := []core.FeatureFlag{}
+	project := r.URL.Query().Get("project")
+	for _, f := range flags {
+		if project == "" || f.Project == "" || f.Project == project {
+			out = append(out, f)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// setFlag creates or replaces a flag. An empty project sets the server-wide
+// default.
+func (s *DomainService) setFlag(w http.ResponseWriter, r *http.Request, name string) {
+	limitBody(w, r)
+	var req setFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	flag, err := s.domainStore.SetFeatureFlag(r.Context(), core.FeatureFlag{
+		Project:     strings.TrimSpace(req.Project),
+		Name:        name,
+		Enabled:     req.Enabled,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flag)
+}
+
+func (s *DomainService) deleteFlag(w http.ResponseWriter, r *http.Request, name string) {
+	if err := s.domainStore.DeleteFeatureFlag(r.Context(), r.URL.Query().Get("project"), name); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// flagEnabled reports whether flag name is on for project. Lookup failures
+// are logged and treated as off, so a flag can only ever opt in.
+func (s *DomainService) flagEnabled(ctx context.Context, project, name string) bool {
+	flags, err := s.domainStore.EffectiveFeatureFlags(ctx, project)
+	if err != nil {
+		log.Printf("WARN: feature flag %s for %s: %v", name, project, err)
+		return false
+	}
+	return flags[name]
+}
+
+// requestFlags resolves the effective flags for the caller's project, for
+// the capabilities endpoint.
+func (s *DomainService) requestFlags(r *http.Request) map[string]bool {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	flags, err := s.domainStore.EffectiveFeatureFlags(r.Context(), project)
+	if err != nil || flags == nil {
+		return map[string]bool{}
+	}
+	return flags
+}
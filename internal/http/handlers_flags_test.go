@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.put(t, "/api/admin/flags/strict_validation", map[string]any{"enabled": true, "description": "reject unknown fields"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.put(t, "/api/admin/flags/strict_validation", map[string]any{"project": "beta", "enabled": false})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.put(t, "/api/admin/flags/approval_gates", map[string]any{"project": "beta", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.put(t, "/api/admin/flags/Bad%20Name", map[string]any{"enabled": true})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	flagsFor := func(project string) map[string]bool {
+		t.Helper()
+		resp := env.get(t, "/api/capabilities?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[capabilitiesResponse](t, resp).Flags
+	}
+	if got := flagsFor("alpha"); !got["strict_validation"] || got["approval_gates"] {
+		t.Errorf("alpha should inherit the default only, got %v", got)
+	}
+	if got := flagsFor("beta"); got["strict_validation"] || !got["approval_gates"] {
+		t.Errorf("beta overrides should win, got %v", got)
+	}
+
+	resp = env.get(t, "/api/admin/flags?project=alpha")
+	requireStatus(t, resp, http.StatusOK)
+	if flags := decodeJSON[[]core.FeatureFlag](t, resp); len(flags) != 1 || flags[0].Description != "reject unknown fields" {
+		t.Errorf("expected only the default for alpha, got %+v", flags)
+	}
+	resp = env.get(t, "/api/admin/flags")
+	requireStatus(t, resp, http.StatusOK)
+	if flags := decodeJSON[[]core.FeatureFlag](t, resp); len(flags) != 3 {
+		t.Errorf("expected 3 flags, got %d", len(flags))
+	}
+
+	resp = env.delete(t, "/api/admin/flags/strict_validation?project=beta")
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	if got := flagsFor("beta"); !got["strict_validation"] {
+		t.Errorf("beta should fall back to the default after delete, got %v", got)
+	}
+	resp = env.delete(t, "/api/admin/flags/strict_validation?project=beta")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestFeatureFlagsRejectAPIKeys(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	ring := auth.NewKeyring(false, map[string]string{"secret": "alpha"})
+	srv := httptest.NewServer(NewDomainRouter(NewDomainService(st), nil, auth.Middleware(ring)))
+	t.Cleanup(srv.Close)
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/api/admin/flags/approval_gates", strings.NewReader(`{"project":"alpha","enabled":true}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireStatus(t, resp, http.StatusForbidden)
+	resp.Body.Close()
+}
+
+func TestStoryThreadsProjectFlag(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.put(t, "/api/admin/flags/"+core.FlagStoryThreads, map[string]any{"project": "alpha", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	for _, project := range []string{"alpha", "beta"} {
+		resp = env.post(t, "/api/stories", map[string]any{"project": project, "epic_id": "e1", "title": "Checkout"})
+		requireStatus(t, resp, http.StatusCreated)
+		story := decodeJSON[core.Story](t, resp)
+		resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": "Card form"})
+		requireStatus(t, resp, http.StatusCreated)
+		task := decodeJSON[core.Task](t, resp)
+		resp = env.post(t, "/api/tasks/"+task.ID+"/assign?project="+project, map[string]any{"agent": "dev"})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+
+		resp = env.get(t, "/api/threads/"+url.PathEscape(core.StoryThreadID(story.ID))+"?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		n := len(decodeJSON[threadMessagesResponse](t, resp).Messages)
+		if want := map[string]int{"alpha": 1, "beta": 0}[project]; n != want {
+			t.Errorf("%s: expected %d mirrored messages, got %d", project, want, n)
+		}
+	}
+}
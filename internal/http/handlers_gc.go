@@ -0,0 +1,39 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// handleAdminGCRun collects garbage now: recipient, poke, inbox and thread
+// index rows left without a message, in every project.
+func (s *DomainService) handleAdminGCRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	run, err := s.CollectGarbage(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(run)
+}
+
+// CollectGarbage removes orphaned index rows and logs what was reclaimed.
+func (s *DomainService) CollectGarbage(ctx context.Context) (core.GCRun, error) {
+	run, err := s.domainStore.CollectGarbage(ctx)
+	if err == nil && run.Reclaimed()+run.UnreadRecounted > 0 {
+		log.Printf("gc: reclaimed %d recipient(s), %d inbox row(s), %d thread(s); recounted %d thread unread count(s)",
+			run.Recipients, run.InboxRows, run.Threads, run.UnreadRecounted)
+	}
+	return run, err
+}
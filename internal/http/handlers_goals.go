@@ -0,0 +1,379 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Goal (OKR) handlers
+
+func (s *DomainService) handleGoals(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get:  s.listGoals,
+		post: s.createGoal,
+	})
+}
+
+func (s *DomainService) handleGoalByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/goals/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
+		return
+	}
+	id := parts[0]
+
+	// Handle /api/goals/{id}/link, /unlink, /links and /rollup
+	if len(parts) >= 2 {
+		switch parts[1] {
+		case "link":
+			s.linkGoal(w, r, id)
+			return
+		case "unlink":
+			s.unlinkGoal(w, r, id)
+			return
+		case "links":
+			s.getGoalLinks(w, r, id)
+			return
+		case "rollup":
+			s.getGoalRollup(w, r, id)
+			return
+		}
+		writeNotFound(w)
+		return
+	}
+
+	dispatchByMethod(w, r, methodHandlers{
+		get:    func(w http.ResponseWriter, r *http.Request) { s.getGoal(w, r, id) },
+		put:    func(w http.ResponseWriter, r *http.Request) { s.updateGoal(w, r, id) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteGoal(w, r, id) },
+	})
+}
+
+func (s *DomainService) createGoal(w http.ResponseWriter, r *http.Request) {
+	var goal core.Goal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if strings.TrimSpace(goal.Title) == "" {
+		writeJSONError(w, http.StatusBadRequest, "title is required", "missing_field")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && goal.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, goal.Project) {
+		return
+	}
+	if handled := replaceExisting(w, r, goal.ID, func() (core.Goal, error) {
+		return s.domainStore.GetGoal(r.Context(), goal.Project, goal.ID)
+	}, func(current core.Goal) {
+		goal.Version = current.Version
+		s.saveGoal(w, r, goal)
+	}); handled {
+		return
+	}
+	created, err := s.domainStore.CreateGoal(r.Context(), goal)
+	if err != nil {
+		if errors.Is(err, core.ErrAlreadyExists) {
+			current, getErr := s.domainStore.GetGoal(r.Context(), goal.Project, goal.ID)
+			writeAlreadyExists(w, current, getErr)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), goal.Project, core.EventGoalCreated, created.ID, created)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (s *DomainService) getGoal(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	goal, err := s.domainStore.GetGoal(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goal)
+}
+
+func (s *DomainService) listGoals(w http.ResponseWriter, r *http.Request) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	status := r.URL.Query().Get("status")
+	goals, err := s.domainStore.ListGoals(r.Context(), project, status)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if goals == nil {
+		goals = []core.Goal{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goals)
+}
+
+func (s *DomainService) updateGoal(w http.ResponseWriter, r *http.Request, id string) {
+	var goal core.Goal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	goal.ID = id
+	s.saveGoal(w, r, goal)
+}
+
+// saveGoal writes goal over the stored goal with its ID, at goal.Version: a PUT,
+// or a create that replaces an existing goal with ?on_conflict=replace.
+func (s *DomainService) saveGoal(w http.ResponseWriter, r *http.Request, goal core.Goal) {
+	id := goal.ID
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && goal.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.checkTransition(w, r, goal.Project, "goal", string(goal.Status), func() (string, error) {
+		current, err := s.domainStore.GetGoal(r.Context(), goal.Project, id)
+		return string(current.Status), err
+	}) {
+		return
+	}
+	updated, err := s.domainStore.UpdateGoal(r.Context(), goal)
+	if err != nil {
+		if errors.Is(err, core.ErrConcurrentModification) {
+			current, getErr := s.domainStore.GetGoal(r.Context(), goal.Project, id)
+			writeVersionConflict(w, current, current.Version, getErr)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), goal.Project, core.EventGoalUpdated, updated.ID, updated)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (s *DomainService) deleteGoal(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if err := s.domainStore.DeleteGoal(r.Context(), project, id); err != nil {
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), project, core.EventGoalDeleted, id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type goalLinkRequest struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+}
+
+func (s *DomainService) linkGoal(w http.ResponseWriter, r *http.Request, goalID string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	var req goalLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if !core.ValidGoalLinkType(req.EntityType) || req.EntityID == "" {
+		writeJSONError(w, http.StatusBadRequest, "entity_type must be spec or epic, and entity_id is required", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if _, err := s.domainStore.GetGoal(r.Context(), project, goalID); err != nil {
+		writeNotFound(w)
+		return
+	}
+	if err := s.getGoalLinkTarget(r.Context(), project, req); err != nil {
+		writeJSONError(w, http.StatusNotFound, req.EntityType+" not found", "not_found")
+		return
+	}
+	if err := s.domainStore.LinkGoal(r.Context(), project, goalID, req.EntityType, req.EntityID); err != nil {
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), project, core.EventGoalLinked, goalID, req)
+	w.WriteHeader(http.StatusOK)
+}
+
+// getGoalLinkTarget looks up the spec or epic a link names in project,
+// failing when it doesn't exist.
+func (s *DomainService) getGoalLinkTarget(ctx context.Context, project string, req goalLinkRequest) error {
+	var err error
+	switch req.EntityType {
+	case core.GoalLinkSpec:
+		_, err = s.domainStore.GetSpec(ctx, project, req.EntityID)
+	case core.GoalLinkEpic:
+		_, err = s.domainStore.GetEpic(ctx, project, req.EntityID)
+	}
+	return err
+}
+
+func (s *DomainService) unlinkGoal(w http.ResponseWriter, r *http.Request, goalID string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	var req goalLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if err := s.domainStore.UnlinkGoal(r.Context(), project, goalID, req.EntityType, req.EntityID); err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *DomainService) getGoalLinks(w http.ResponseWriter, r *http.Request, goalID string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	links, err := s.domainStore.GetGoalLinks(r.Context(), project, goalID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if links == nil {
+		links = []core.GoalLink{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// getGoalRollup reports progress on a goal from the current status of every
+// linked spec and epic. Validated specs and done epics count as complete;
+// archived specs are shown but excluded from the totals.
+func (s *DomainService) getGoalRollup(w http.ResponseWriter, r *http.Request, goalID string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	goal, err := s.domainStore.GetGoal(r.Context(), project, goalID)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	links, err := s.domainStore.GetGoalLinks(r.Context(), project, goalID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	rollup := core.GoalRollup{Goal: goal, Links: []core.GoalLinkState{}}
+	for _, link := range links {
+		state := core.GoalLinkState{EntityType: link.EntityType, EntityID: link.EntityID}
+		counted, done, active := false, false, false
+		switch link.EntityType {
+		case core.GoalLinkSpec:
+			spec, err := s.domainStore.GetSpec(r.Context(), project, link.EntityID)
+			if err != nil {
+				state.Missing = true
+				break
+			}
+			state.Title, state.Status = spec.Title, string(spec.Status)
+			counted = spec.Status != core.SpecStatusArchived
+			done = spec.Status == core.SpecStatusValidated
+			active = spec.Status == core.SpecStatusResearch
+		case core.GoalLinkEpic:
+			epic, err := s.domainStore.GetEpic(r.Context(), project, link.EntityID)
+			if err != nil {
+				state.Missing = true
+				break
+			}
+			state.Title, state.Status = epic.Title, string(epic.Status)
+			counted = true
+			done = epic.Status == core.EpicStatusDone
+			active = epic.Status == core.EpicStatusInProgress
+		}
+		rollup.Links = append(rollup.Links, state)
+		if !counted {
+			continue
+		}
+		rollup.Total++
+		if done {
+			rollup.Done++
+		} else if active {
+			rollup.InProgress++
+		}
+	}
+	if rollup.Total > 0 {
+		rollup.Progress = float64(rollup.Done) / float64(rollup.Total)
+	}
+	rollup.KeyResultsPct = keyResultProgress(goal.KeyResults)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rollup)
+}
+
+// keyResultProgress returns the mean completion ratio of key results with a
+// positive target, each capped at 1.
+func keyResultProgress(krs []core.KeyResult) float64 {
+	var sum float64
+	var n int
+	for _, kr := range krs {
+		if kr.Target <= 0 {
+			continue
+		}
+		ratio := kr.Current / kr.Target
+		if ratio > 1 {
+			ratio = 1
+		}
+		if ratio < 0 {
+			ratio = 0
+		}
+		sum += ratio
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
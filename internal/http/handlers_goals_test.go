@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestGoalHTTP(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj-test"
+
+	resp := env.post(t, "/api/goals", map[string]any{
+		"project": project,
+		"title":   "Ship v1",
+		"period":  "2026-Q3",
+		"key_results": []map[string]any{
+			{"description": "Validated specs", "target": 4, "current": 1},
+		},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	goal := decodeJSON[map[string]any](t, resp)
+	goalID := goal["id"].(string)
+	if goal["status"] != "active" {
+		t.Fatalf("expected default status active, got %v", goal["status"])
+	}
+
+	resp = env.post(t, "/api/specs", map[string]any{"project": project, "title": "Spec A", "status": "validated"})
+	requireStatus(t, resp, http.StatusCreated)
+	specID := decodeJSON[map[string]any](t, resp)["id"].(string)
+
+	resp = env.post(t, "/api/epics", map[string]any{"project": project, "title": "Epic A", "status": "in_progress"})
+	requireStatus(t, resp, http.StatusCreated)
+	epicID := decodeJSON[map[string]any](t, resp)["id"].(string)
+
+	t.Run("link", func(t *testing.T) {
+		resp := env.post(t, "/api/goals/"+goalID+"/link?project="+project, map[string]any{"entity_type": "spec", "entity_id": specID})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+		resp = env.post(t, "/api/goals/"+goalID+"/link?project="+project, map[string]any{"entity_type": "epic", "entity_id": epicID})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+
+		resp = env.get(t, "/api/goals/"+goalID+"/links?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		links := decodeJSON[[]map[string]any](t, resp)
+		if len(links) != 2 {
+			t.Fatalf("expected 2 links, got %d", len(links))
+		}
+	})
+
+	t.Run("link invalid type", func(t *testing.T) {
+		resp := env.post(t, "/api/goals/"+goalID+"/link?project="+project, map[string]any{"entity_type": "task", "entity_id": "x"})
+		requireStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	})
+
+	t.Run("link missing entity", func(t *testing.T) {
+		for _, typ := range []string{"spec", "epic"} {
+			resp := env.post(t, "/api/goals/"+goalID+"/link?project="+project, map[string]any{"entity_type": typ, "entity_id": "nope"})
+			requireStatus(t, resp, http.StatusNotFound)
+			resp.Body.Close()
+		}
+		// An epic's ID doesn't name a spec.
+		resp := env.post(t, "/api/goals/"+goalID+"/link?project="+project, map[string]any{"entity_type": "spec", "entity_id": epicID})
+		requireStatus(t, resp, http.StatusNotFound)
+		resp.Body.Close()
+	})
+
+	t.Run("link unknown goal", func(t *testing.T) {
+		resp := env.post(t, "/api/goals/nope/link?project="+project, map[string]any{"entity_type": "spec", "entity_id": specID})
+		requireStatus(t, resp, http.StatusNotFound)
+		resp.Body.Close()
+	})
+
+	t.Run("rollup", func(t *testing.T) {
+		resp := env.get(t, "/api/goals/"+goalID+"/rollup?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		rollup := decodeJSON[map[string]any](t, resp)
+		if rollup["total"].(float64) != 2 || rollup["done"].(float64) != 1 || rollup["in_progress"].(float64) != 1 {
+			t.Fatalf("unexpected rollup counts: %v", rollup)
+		}
+		if rollup["progress"].(float64) != 0.5 {
+			t.Fatalf("expected progress 0.5, got %v", rollup["progress"])
+		}
+		if rollup["key_results_pct"].(float64) != 0.25 {
+			t.Fatalf("expected key_results_pct 0.25, got %v", rollup["key_results_pct"])
+		}
+	})
+
+	t.Run("unlink", func(t *testing.T) {
+		resp := env.post(t, "/api/goals/"+goalID+"/unlink?project="+project, map[string]any{"entity_type": "epic", "entity_id": epicID})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+
+		resp = env.get(t, "/api/goals/"+goalID+"/rollup?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		rollup := decodeJSON[map[string]any](t, resp)
+		if rollup["total"].(float64) != 1 || rollup["progress"].(float64) != 1 {
+			t.Fatalf("unexpected rollup after unlink: %v", rollup)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := env.delete(t, "/api/goals/"+goalID+"?project="+project)
+		requireStatus(t, resp, http.StatusNoContent)
+		resp.Body.Close()
+
+		resp = env.get(t, "/api/goals/"+goalID+"/rollup?project="+project)
+		requireStatus(t, resp, http.StatusNotFound)
+		resp.Body.Close()
+	})
+}
+
+func TestDeleteGoalEmitsDeleted(t *testing.T) {
+	env, bus := newRecordingEnv(t)
+	resp := env.post(t, "/api/goals", map[string]any{"project": "proj", "title": "Ship v1"})
+	requireStatus(t, resp, http.StatusCreated)
+	goal := decodeJSON[core.Goal](t, resp)
+
+	resp = env.delete(t, "/api/goals/"+goal.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	if got := bus.ofType(core.EventGoalDeleted); len(got) != 1 {
+		t.Fatalf("goal.deleted events = %v", got)
+	}
+	if got := bus.ofType(core.EventGoalArchived); len(got) != 0 {
+		t.Fatalf("hard delete emitted goal.archived: %v", got)
+	}
+}
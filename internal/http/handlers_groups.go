@@ -0,0 +1,376 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// groupAddressPrefix marks a To/CC entry as a contact group name rather
+// than an agent ID.
+const groupAddressPrefix = "@"
+
+func isGroupAddress(addr string) bool {
+	return strings.HasPrefix(addr, groupAddressPrefix)
+}
+
+type apiContactGroup struct {
+	Project     string   `json:"project"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Members     []string `json:"members"`
+	Team        bool     `json:"team,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+}
+
+func toAPIContactGroup(g core.ContactGroup) apiContactGroup {
+	members := g.Members
+	if members == nil {
+		members = []string{}
+	}
+	return apiContactGroup{
+		Project:     g.Project,
+		Name:        g.Name,
+		Description: g.Description,
+		Members:     members,
+		Team:        g.Team,
+		CreatedAt:   g.CreatedAt.Format(time.RFC3339Nano),
+		UpdatedAt:   g.UpdatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+type groupMemberRequest struct {
+	Agent string `json:"agent"`
+}
+
+// validGroupName rejects names that couldn't be addressed unambiguously.
+func validGroupName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "@/ \t\n")
+}
+
+// groupProject resolves the project for a group request: the API key's
+// project, else the explicit one. Returns false after writing 403 when an
+// API-key caller names a different project.
+func groupProject(w http.ResponseWriter, r *http.Request, explicit string) (string, bool) {
+	info, _ := auth.FromContext(r.Context())
+	if explicit == "" {
+		explicit = r.URL.Query().Get("project")
+	}
+	if info.Mode == auth.ModeAPIKey {
+		if explicit != "" && explicit != info.Project {
+			writeProjectMismatch(w)
+			return "", false
+		}
+		return info.Project, true
+	}
+	return explicit, true
+}
+
+func (s *Service) handleGroups(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get:  s.listGroups,
+		post: s.createGroup,
+	})
+}
+
+func (s *Service) handleGroupByName(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
+		return
+	}
+	name := parts[0]
+
+	// Handle /api/groups/{name}/members and /api/groups/{name}/members/{agent}
+	if len(parts) >= 2 {
+		if parts[1] != "members" || len(parts) > 3 {
+			writeNotFound(w)
+			return
+		}
+		if len(parts) == 3 {
+			if r.Method != http.MethodDelete {
+				writeMethodNotAllowed(w)
+				return
+			}
+			s.removeGroupMember(w, r, name, parts[2])
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w)
+			return
+		}
+		s.addGroupMember(w, r, name)
+		return
+	}
+
+	dispatchByMethod(w, r, methodHandlers{
+		get:    func(w http.ResponseWriter, r *http.Request) { s.getGroup(w, r, name) },
+		put:    func(w http.ResponseWriter, r *http.Request) { s.updateGroup(w, r, name) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteGroup(w, r, name) },
+	})
+}
+
+func (s *Service) createGroup(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req apiContactGroup
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if !validGroupName(req.Name) {
+		writeJSONError(w, http.StatusBadRequest, "invalid group name", "invalid_request")
+		return
+	}
+	project, ok := groupProject(w, r, req.Project)
+	if !ok {
+		return
+	}
+	created, err := s.store.CreateContactGroup(r.Context(), core.ContactGroup{
+		Project:     project,
+		Name:        req.Name,
+		Description: req.Description,
+		Members:     cleanMembers(req.Members),
+		Team:        req.Team,
+	})
+	if err != nil {
+		if errors.Is(err, core.ErrAlreadyExists) {
+			writeJSONError(w, http.StatusConflict, "group already exists", "group_exists")
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toAPIContactGroup(created))
+}
+
+func (s *Service) listGroups(w http.ResponseWriter, r *http.Request) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	groups, err := s.store.ListContactGroups(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	out := make([]apiContactGroup, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, toAPIContactGroup(g))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func (s *Service) getGroup(w http.ResponseWriter, r *http.Request, name string) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	group, err := s.store.GetContactGroup(r.Context(), project, name)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toAPIContactGroup(group))
+}
+
+// updateGroup replaces the group's description, team flag and full member
+// list.
+func (s *Service) updateGroup(w http.ResponseWriter, r *http.Request, name string) {
+	limitBody(w, r)
+	var req apiContactGroup
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	project, ok := groupProject(w, r, req.Project)
+	if !ok {
+		return
+	}
+	updated, err := s.store.UpdateContactGroup(r.Context(), core.ContactGroup{
+		Project:     project,
+		Name:        name,
+		Description: req.Description,
+		Members:     cleanMembers(req.Members),
+		Team:        req.Team,
+	})
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toAPIContactGroup(updated))
+}
+
+func (s *Service) deleteGroup(w http.ResponseWriter, r *http.Request, name string) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteContactGroup(r.Context(), project, name); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) addGroupMember(w http.ResponseWriter, r *http.Request, name string) {
+	limitBody(w, r)
+	var req groupMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	agent := strings.TrimSpace(req.Agent)
+	if agent == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
+		return
+	}
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	if err := s.store.AddContactGroupMember(r.Context(), project, name, agent); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) removeGroupMember(w http.ResponseWriter, r *http.Request, name, agent string) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	if err := s.store.RemoveContactGroupMember(r.Context(), project, name, agent); err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func cleanMembers(members []string) []string {
+	out := make([]string, 0, len(members))
+	for _, m := range members {
+		if m = strings.TrimSpace(m); m != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// expandGroupAddresses replaces "@group" entries in the request's To and CC
+// with the groups' current members (minus the sender) and returns the
+// expansion so it can be recorded on the message. Team addresses are left
+// as they are: the team itself is the recipient. BCC does not accept
+// groups, since the recorded expansion would disclose its members. Writes
+// a 400 and returns false when a group doesn't exist.
+func (s *Service) expandGroupAddresses(ctx context.Context, w http.ResponseWriter, project string, req *sendMessageRequest) (map[string][]string, bool) {
+	groups := map[string][]string{}
+	teams := map[string]bool{}
+	expand := func(addrs []string) ([]string, bool) {
+		var out []string
+		for _, addr := range addrs {
+			name, isGroup := strings.CutPrefix(addr, groupAddressPrefix)
+			if !isGroup || teams[name] {
+				out = append(out, addr)
+				continue
+			}
+			members, seen := groups[name]
+			if !seen {
+				group, err := s.store.GetContactGroup(ctx, project, name)
+				if err != nil {
+					if errors.Is(err, core.ErrNotFound) {
+						writeJSONError(w, http.StatusBadRequest, "unknown group: "+name, "unknown_group")
+					} else {
+						writeInternalError(w)
+					}
+					return nil, false
+				}
+				if group.Team {
+					teams[name] = true
+					out = append(out, addr)
+					continue
+				}
+				members = withoutAgent(group.Members, req.From)
+				groups[name] = members
+			}
+			out = append(out, members...)
+		}
+		return dedupeAgents(out), true
+	}
+
+	var ok bool
+	if req.To, ok = expand(req.To); !ok {
+		return nil, false
+	}
+	if req.CC, ok = expand(req.CC); !ok {
+		return nil, false
+	}
+	for _, addr := range req.BCC {
+		if strings.HasPrefix(addr, groupAddressPrefix) {
+			writeJSONError(w, http.StatusBadRequest, "groups cannot be used in bcc", "invalid_request")
+			return nil, false
+		}
+	}
+	if len(groups) == 0 {
+		return nil, true
+	}
+	return groups, true
+}
+
+// deliveryTargets resolves team addresses among recipients to the teams'
+// current members, for live notification. Other recipients pass through.
+func (s *Service) deliveryTargets(ctx context.Context, project string, recipients []string) []string {
+	out := make([]string, 0, len(recipients))
+	for _, addr := range recipients {
+		name, isGroup := strings.CutPrefix(addr, groupAddressPrefix)
+		if !isGroup {
+			out = append(out, addr)
+			continue
+		}
+		group, err := s.store.GetContactGroup(ctx, project, name)
+		if err != nil || !group.Team {
+			continue
+		}
+		out = append(out, group.Members...)
+	}
+	return dedupeAgents(out)
+}
+
+// isTeamMember reports whether agent belongs to the team addressed as addr.
+func (s *Service) isTeamMember(ctx context.Context, project, addr, agent string) bool {
+	name, isGroup := strings.CutPrefix(addr, groupAddressPrefix)
+	if !isGroup || agent == "" {
+		return false
+	}
+	group, err := s.store.GetContactGroup(ctx, project, name)
+	if err != nil || !group.Team {
+		return false
+	}
+	return slices.Contains(group.Members, agent)
+}
@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestContactGroupsCRUD(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/groups", map[string]any{"project": project, "name": "bad name"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/groups", map[string]any{
+		"project": project, "name": "reviewers", "description": "Code reviewers", "members": []string{"bob", "carol", "bob"},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	group := decodeJSON[apiContactGroup](t, resp)
+	if len(group.Members) != 2 {
+		t.Fatalf("expected deduped members, got %v", group.Members)
+	}
+
+	resp = env.post(t, "/api/groups", map[string]any{"project": project, "name": "reviewers"})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/groups/reviewers/members?project="+project, map[string]any{"agent": "dave"})
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = env.delete(t, "/api/groups/reviewers/members/bob?project="+project)
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/groups/reviewers?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	group = decodeJSON[apiContactGroup](t, resp)
+	if len(group.Members) != 2 || group.Members[0] != "carol" || group.Members[1] != "dave" {
+		t.Fatalf("unexpected members: %v", group.Members)
+	}
+
+	resp = env.put(t, "/api/groups/reviewers", map[string]any{"project": project, "members": []string{"erin"}})
+	requireStatus(t, resp, http.StatusOK)
+	if updated := decodeJSON[apiContactGroup](t, resp); len(updated.Members) != 1 || updated.Members[0] != "erin" {
+		t.Fatalf("expected members replaced, got %v", updated.Members)
+	}
+
+	resp = env.get(t, "/api/groups?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if groups := decodeJSON[[]apiContactGroup](t, resp); len(groups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(groups))
+	}
+
+	resp = env.delete(t, "/api/groups/reviewers?project="+project)
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = env.get(t, "/api/groups/reviewers?project="+project)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestSendToContactGroup(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/groups", map[string]any{
+		"project": project, "name": "reviewers", "members": []string{"alice", "bob", "carol"},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "alice", "to": []string{"@nobody"}, "body": "hi",
+	})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "alice", "to": []string{"@reviewers", "bob"}, "body": "please review",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// Membership changes after the send don't rewrite history.
+	resp = env.put(t, "/api/groups/reviewers", map[string]any{"project": project, "members": []string{"dave"}})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	for _, agent := range []string{"bob", "carol"} {
+		resp := env.get(t, "/api/inbox/"+agent+"?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		msgs := decodeJSON[inboxResponse](t, resp).Messages
+		if len(msgs) != 1 {
+			t.Fatalf("%s: expected 1 message, got %d", agent, len(msgs))
+		}
+		msg := msgs[0]
+		if len(msg.To) != 2 || msg.To[0] != "bob" || msg.To[1] != "carol" {
+			t.Errorf("expected expanded to [bob carol], got %v", msg.To)
+		}
+		if got := msg.Groups["reviewers"]; len(got) != 2 || got[0] != "bob" || got[1] != "carol" {
+			t.Errorf("expected recorded membership [bob carol], got %v", msg.Groups)
+		}
+	}
+
+	resp = env.get(t, "/api/inbox/alice?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if msgs := decodeJSON[inboxResponse](t, resp).Messages; len(msgs) != 0 {
+		t.Errorf("sender should not receive their own group message, got %d", len(msgs))
+	}
+}
+
+func TestSendToTeam(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/groups", map[string]any{
+		"project": project, "name": "frontend", "team": true, "members": []string{"alice", "bob"},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	if g := decodeJSON[apiContactGroup](t, resp); !g.Team {
+		t.Fatalf("expected team group, got %+v", g)
+	}
+
+	resp = env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "lead", "to": []string{"@frontend"}, "body": "ship it",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	sent := decodeJSON[sendMessageResponse](t, resp)
+
+	// Membership is resolved at read time: a member added after the send
+	// sees the team's mail.
+	resp = env.post(t, "/api/groups/frontend/members?project="+project, map[string]any{"agent": "carol"})
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+
+	for _, agent := range []string{"alice", "bob", "carol"} {
+		resp := env.get(t, "/api/inbox/"+agent+"?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		msgs := decodeJSON[inboxResponse](t, resp).Messages
+		if len(msgs) != 1 || len(msgs[0].To) != 1 || msgs[0].To[0] != "@frontend" {
+			t.Fatalf("%s: expected the team message, got %+v", agent, msgs)
+		}
+	}
+
+	// One member reading it reads it for the team.
+	resp = env.post(t, "/api/messages/"+sent.MessageID+"/read?project="+project, map[string]any{"agent": "bob"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.get(t, "/api/inbox/alice/counts?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if c := decodeJSON[inboxCountsResponse](t, resp); c.Total != 1 || c.Unread != 0 {
+		t.Fatalf("alice counts = %+v, want 1 total, 0 unread", c)
+	}
+
+	resp = env.get(t, "/api/inbox/dave?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if msgs := decodeJSON[inboxResponse](t, resp).Messages; len(msgs) != 0 {
+		t.Fatalf("non-member got %d messages", len(msgs))
+	}
+}
@@ -26,7 +26,7 @@ const healthPingTimeout = 500 * time.Millisecond
 func newHealthHandler(p Pinger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -49,3 +49,40 @@ func newHealthHandler(p Pinger) http.HandlerFunc {
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	}
 }
+
+// handleReady reports whether the server should take traffic: the database
+// answers and the storage circuit breaker (the read one, when breakers are
+// per category) isn't open. Unlike /health it is
+// meant for readiness gates, so a tripped breaker (requests failing fast)
+// counts as not ready even though the process is alive.
+func (s *DomainService) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	notReady := func(reason string) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "not_ready", "reason": reason})
+	}
+	if s.pinger != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), healthPingTimeout)
+		defer cancel()
+		if err := s.pinger.Ping(ctx); err != nil {
+			notReady("database: " + err.Error())
+			return
+		}
+	}
+	if cats, ok := s.breaker.(CategoryBreakerStater); ok {
+		// Only the read breaker decides: with writes or sweeps failing the
+		// server can still answer reads.
+		if cats.CircuitBreakerStates()["reads"] == "open" {
+			notReady("storage read circuit breaker open")
+			return
+		}
+	} else if s.breaker != nil && s.breaker.CircuitBreakerState() == "open" {
+		notReady("storage circuit breaker open")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+}
@@ -79,3 +79,39 @@ func TestHealthHandlerRejectsNonGET(t *testing.T) {
 		t.Errorf("status = %d, want 405", w.Code)
 	}
 }
+
+type stubBreaker string
+
+func (b stubBreaker) CircuitBreakerState() string { return string(b) }
+
+type stubCategoryBreaker map[string]string
+
+func (b stubCategoryBreaker) CircuitBreakerState() string { return "open" }
+
+func (b stubCategoryBreaker) CircuitBreakerStates() map[string]string { return b }
+
+func TestReadyHandler(t *testing.T) {
+	cases := []struct {
+		name    string
+		pinger  Pinger
+		breaker BreakerStater
+		want    int
+	}{
+		{"healthy", stubPinger{}, stubBreaker("closed"), http.StatusOK},
+		{"db down", stubPinger{err: errors.New("database is locked")}, stubBreaker("closed"), http.StatusServiceUnavailable},
+		{"breaker open", stubPinger{}, stubBreaker("open"), http.StatusServiceUnavailable},
+		{"half open", stubPinger{}, stubBreaker("half_open"), http.StatusOK},
+		{"writes open", stubPinger{}, stubCategoryBreaker{"reads": "closed", "writes": "open"}, http.StatusOK},
+		{"reads open", stubPinger{}, stubCategoryBreaker{"reads": "open", "writes": "closed"}, http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewDomainService(nil).WithPinger(tc.pinger).WithMetricsSources(tc.breaker, nil)
+			w := httptest.NewRecorder()
+			svc.handleReady(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tc.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
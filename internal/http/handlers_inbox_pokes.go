@@ -43,13 +43,13 @@ func (s *Service) handleInboxPokeAction(w http.ResponseWriter, r *http.Request)
 func (s *Service) listInboxPokes(w http.ResponseWriter, r *http.Request) {
 	project, agent, ok := inboxPokeScope(r)
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "project and agent are required", "missing_field")
 		return
 	}
 
 	pending, err := s.store.ListPendingPokes(r.Context(), project, agent)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -70,7 +70,7 @@ func (s *Service) listInboxPokes(w http.ResponseWriter, r *http.Request) {
 func (s *Service) ackInboxPoke(w http.ResponseWriter, r *http.Request) {
 	project, agent, ok := inboxPokeScope(r)
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "project and agent are required", "missing_field")
 		return
 	}
 
@@ -78,12 +78,12 @@ func (s *Service) ackInboxPoke(w http.ResponseWriter, r *http.Request) {
 	messageID = strings.TrimSuffix(messageID, "/ack")
 	messageID = strings.Trim(messageID, "/")
 	if messageID == "" || !strings.HasSuffix(r.URL.Path, "/ack") {
-		w.WriteHeader(http.StatusBadRequest)
+		writeNotFound(w)
 		return
 	}
 
 	if err := s.store.MarkPokeSurfaced(r.Context(), project, agent, messageID); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -10,17 +10,14 @@ import (
 
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/livetransport"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestInboxPokesListAndAck(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewDomainService(st)
 
-	_, err = st.RegisterAgent(context.Background(), core.Agent{
+	_, err := st.RegisterAgent(context.Background(), core.Agent{
 		Name:    "bob",
 		Project: "p1",
 		Status:  "active",
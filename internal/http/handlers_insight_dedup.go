@@ -0,0 +1,95 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Insight deduplication. With the dedup_insights flag on, creating an
+// insight whose source and title match an existing one in the project
+// (core.InsightContentHash) returns the existing insight with 200 instead
+// of a new one with 201. Duplicates that got in anyway can be folded
+// together with POST /api/insights/{id}/merge.
+
+type insightMergeEvent struct {
+	Insight core.Insight `json:"insight"`
+	Merged  []string     `json:"merged"`
+}
+
+// findDuplicateInsight looks for an insight matching insight's content when
+// the project deduplicates insights. On a lookup error it writes the
+// response and returns ok false. Two creates of the same insight can both
+// miss; the store then fails the later one with core.ErrDuplicateInsight,
+// which writeDuplicateInsight answers as if it had been found here.
+func (s *DomainService) findDuplicateInsight(w http.ResponseWriter, r *http.Request, insight core.Insight) (existing core.Insight, found, ok bool) {
+	if !s.flagEnabled(r.Context(), insight.Project, core.FlagDedupInsights) {
+		return core.Insight{}, false, true
+	}
+	existing, err := s.domainStore.FindInsightByHash(r.Context(), insight.Project, core.InsightContentHash(insight.Source, insight.Title))
+	switch {
+	case err == nil:
+		return existing, true, true
+	case errors.Is(err, core.ErrNotFound):
+		return core.Insight{}, false, true
+	default:
+		writeInternalError(w)
+		return core.Insight{}, false, false
+	}
+}
+
+// writeDuplicateInsight answers a create the store rejected with
+// core.ErrDuplicateInsight with the insight that got there first.
+func (s *DomainService) writeDuplicateInsight(w http.ResponseWriter, r *http.Request, insight core.Insight) {
+	existing, err := s.domainStore.FindInsightByHash(r.Context(), insight.Project, core.InsightContentHash(insight.Source, insight.Title))
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(existing)
+}
+
+func (s *DomainService) mergeInsights(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	var req struct {
+		Project    string   `json:"project"`
+		Duplicates []string `json:"duplicates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey {
+		if req.Project != "" && req.Project != info.Project {
+			writeProjectMismatch(w)
+			return
+		}
+		req.Project = info.Project
+	}
+	if len(req.Duplicates) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "duplicates is required", "missing_field")
+		return
+	}
+	merged, err := s.domainStore.MergeInsights(r.Context(), req.Project, id, req.Duplicates)
+	if errors.Is(err, core.ErrNotFound) {
+		writeNotFound(w)
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), req.Project, core.EventInsightMerged, merged.ID,
+		insightMergeEvent{Insight: merged, Merged: slices.DeleteFunc(slices.Clone(req.Duplicates), func(d string) bool { return d == merged.ID })})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(merged)
+}
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestInsightDedupAndMerge(t *testing.T) {
+	env := newTestEnv(t)
+	create := func(title string) (*http.Response, core.Insight) {
+		t.Helper()
+		resp := env.post(t, "/api/insights", core.Insight{Project: "proj", Source: "pollard", Category: "perf", Title: title, Score: 0.4})
+		return resp, decodeJSON[core.Insight](t, resp)
+	}
+
+	resp, first := create("Flaky test: TestFoo")
+	requireStatus(t, resp, http.StatusCreated)
+	resp, second := create("flaky test  TESTFOO")
+	requireStatus(t, resp, http.StatusCreated)
+	if first.ContentHash == "" || first.ContentHash != second.ContentHash {
+		t.Fatalf("hashes %q, %q: want equal and set", first.ContentHash, second.ContentHash)
+	}
+
+	resp = env.put(t, "/api/admin/flags/dedup_insights", map[string]any{"project": "proj", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp, again := create("Flaky test -- TestFoo")
+	requireStatus(t, resp, http.StatusOK)
+	if again.ID != first.ID {
+		t.Fatalf("dedup returned %s, want oldest match %s", again.ID, first.ID)
+	}
+
+	resp = env.post(t, "/api/specs", core.Spec{Project: "proj", Title: "Spec", Status: core.SpecStatusDraft})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+	resp = env.post(t, "/api/insights/"+second.ID+"/link?project=proj", map[string]string{"spec_id": spec.ID})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/insights/"+first.ID+"/merge", map[string]any{"project": "proj", "duplicates": []string{second.ID}})
+	requireStatus(t, resp, http.StatusOK)
+	merged := decodeJSON[core.Insight](t, resp)
+	if merged.ID != first.ID || merged.SpecID != spec.ID {
+		t.Fatalf("merged = %+v, want %s keeping the duplicate's spec link", merged, first.ID)
+	}
+
+	resp = env.get(t, "/api/insights/"+second.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.Insight](t, resp); got.ID != first.ID {
+		t.Fatalf("merged-away ID resolved to %s, want %s", got.ID, first.ID)
+	}
+	resp = env.get(t, "/api/insights?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if all := decodeJSON[[]core.Insight](t, resp); len(all) != 1 {
+		t.Fatalf("insights after merge = %d, want 1", len(all))
+	}
+
+	resp = env.post(t, "/api/insights/"+first.ID+"/merge", map[string]any{"project": "proj", "duplicates": []string{"nope"}})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
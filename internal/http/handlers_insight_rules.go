@@ -0,0 +1,262 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Insight routing rule handlers
+
+// insightRouteAction is one effect a matching rule has on an insight.
+type insightRouteAction struct {
+	RuleID      string `json:"rule_id"`
+	RuleName    string `json:"rule_name"`
+	LinkSpecID  string `json:"link_spec_id,omitempty"`
+	NotifyAgent string `json:"notify_agent,omitempty"`
+}
+
+// insightRuleTestResponse is returned by the dry-run endpoint.
+type insightRuleTestResponse struct {
+	Matched []core.InsightRule   `json:"matched"`
+	Actions []insightRouteAction `json:"actions"`
+}
+
+func (s *DomainService) handleInsightRules(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get:  s.listInsightRules,
+		post: s.createInsightRule,
+	})
+}
+
+func (s *DomainService) handleInsightRuleByID(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/insight-rules/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		writeNotFound(w)
+		return
+	}
+	if id == "test" {
+		s.testInsightRules(w, r)
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get:    func(w http.ResponseWriter, r *http.Request) { s.getInsightRule(w, r, id) },
+		put:    func(w http.ResponseWriter, r *http.Request) { s.updateInsightRule(w, r, id) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteInsightRule(w, r, id) },
+	})
+}
+
+// validInsightRule requires a name and at least one action.
+func validInsightRule(rule core.InsightRule) bool {
+	return strings.TrimSpace(rule.Name) != "" && (rule.SpecID != "" || rule.NotifyAgent != "")
+}
+
+// insightRuleRequest defaults Enabled to true when omitted.
+type insightRuleRequest struct {
+	core.InsightRule
+	Enabled *bool `json:"enabled"`
+}
+
+func (req insightRuleRequest) rule() core.InsightRule {
+	rule := req.InsightRule
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	return rule
+}
+
+func (s *DomainService) createInsightRule(w http.ResponseWriter, r *http.Request) {
+	var req insightRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	rule := req.rule()
+	if !validInsightRule(rule) {
+		writeJSONError(w, http.StatusBadRequest, "rule needs a name and a spec_id or notify_agent", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && rule.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	created, err := s.domainStore.CreateInsightRule(r.Context(), rule)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (s *DomainService) getInsightRule(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	rule, err := s.domainStore.GetInsightRule(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (s *DomainService) listInsightRules(w http.ResponseWriter, r *http.Request) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	rules, err := s.domainStore.ListInsightRules(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if rules == nil {
+		rules = []core.InsightRule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (s *DomainService) updateInsightRule(w http.ResponseWriter, r *http.Request, id string) {
+	var req insightRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	rule := req.rule()
+	rule.ID = id
+	if !validInsightRule(rule) {
+		writeJSONError(w, http.StatusBadRequest, "rule needs a name and a spec_id or notify_agent", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && rule.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	updated, err := s.domainStore.UpdateInsightRule(r.Context(), rule)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (s *DomainService) deleteInsightRule(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if err := s.domainStore.DeleteInsightRule(r.Context(), project, id); err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// testInsightRules evaluates the project's rules against a candidate insight
+// and reports what would happen, without creating, linking or notifying.
+func (s *DomainService) testInsightRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	var insight core.Insight
+	if err := json.NewDecoder(r.Body).Decode(&insight); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && insight.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	matched, actions, err := s.planInsightRoutes(r.Context(), insight)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if matched == nil {
+		matched = []core.InsightRule{}
+	}
+	if actions == nil {
+		actions = []insightRouteAction{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(insightRuleTestResponse{Matched: matched, Actions: actions})
+}
+
+// planInsightRoutes returns the enabled rules matching insight and the
+// actions they imply. Only the first matching rule with a spec links the
+// insight, and only if the insight isn't already linked; every matching
+// rule with an agent notifies it.
+func (s *DomainService) planInsightRoutes(ctx context.Context, insight core.Insight) ([]core.InsightRule, []insightRouteAction, error) {
+	rules, err := s.domainStore.ListInsightRules(ctx, insight.Project)
+	if err != nil {
+		return nil, nil, err
+	}
+	var matched []core.InsightRule
+	var actions []insightRouteAction
+	linked := insight.SpecID != ""
+	for _, rule := range rules {
+		if !rule.Enabled || !rule.Matches(insight) {
+			continue
+		}
+		matched = append(matched, rule)
+		action := insightRouteAction{RuleID: rule.ID, RuleName: rule.Name, NotifyAgent: rule.NotifyAgent}
+		if rule.SpecID != "" && !linked {
+			action.LinkSpecID = rule.SpecID
+			linked = true
+		}
+		if action.LinkSpecID != "" || action.NotifyAgent != "" {
+			actions = append(actions, action)
+		}
+	}
+	return matched, actions, nil
+}
+
+// applyInsightRules runs the routing actions for a newly created insight and
+// returns it with any spec link applied. Failures are logged rather than
+// failing the create.
+func (s *DomainService) applyInsightRules(ctx context.Context, insight core.Insight) core.Insight {
+	_, actions, err := s.planInsightRoutes(ctx, insight)
+	if err != nil {
+		log.Printf("WARN: insight rules for %s: %v", insight.ID, err)
+		return insight
+	}
+	for _, action := range actions {
+		if action.LinkSpecID != "" {
+			if err := s.domainStore.LinkInsightToSpec(ctx, insight.Project, insight.ID, action.LinkSpecID); err != nil {
+				log.Printf("WARN: insight rule %s: link %s to spec %s: %v", action.RuleID, insight.ID, action.LinkSpecID, err)
+				action.LinkSpecID = ""
+			} else {
+				insight.SpecID = action.LinkSpecID
+			}
+		}
+		if action.NotifyAgent != "" {
+			subject := fmt.Sprintf("Insight routed: %s", insight.Title)
+			body := fmt.Sprintf("Rule %q matched insight %s (%s, score %.2f).", action.RuleName, insight.ID, insight.Category, insight.Score)
+			if insight.URL != "" {
+				body += "\n" + insight.URL
+			}
+			if _, err := s.sendSystemMessage(ctx, insight.Project, []string{action.NotifyAgent}, subject, body); err != nil {
+				log.Printf("WARN: insight rule %s: notify %s: %v", action.RuleID, action.NotifyAgent, err)
+			}
+		}
+		s.broadcastDomainEvent(ctx, insight.Project, core.EventInsightRouted, insight.ID, action)
+	}
+	return insight
+}
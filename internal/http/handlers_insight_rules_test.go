@@ -0,0 +1,118 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestInsightRulesCRUD(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	// A rule needs a name and at least one action.
+	resp := env.post(t, "/api/insight-rules", map[string]any{"project": project, "name": "noop"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/insight-rules", map[string]any{
+		"project": project, "name": "competitors", "category": "competitor", "notify_agent": "pm",
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	rule := decodeJSON[core.InsightRule](t, resp)
+	if rule.ID == "" || !rule.Enabled {
+		t.Fatalf("unexpected rule: %+v", rule)
+	}
+
+	resp = env.put(t, "/api/insight-rules/"+rule.ID, map[string]any{
+		"project": project, "name": "competitors", "category": "competitor", "notify_agent": "pm", "enabled": false,
+	})
+	requireStatus(t, resp, http.StatusOK)
+	if updated := decodeJSON[core.InsightRule](t, resp); updated.Enabled {
+		t.Fatal("expected rule to be disabled")
+	}
+
+	resp = env.get(t, "/api/insight-rules?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if rules := decodeJSON[[]core.InsightRule](t, resp); len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+
+	resp = env.delete(t, "/api/insight-rules/"+rule.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/insight-rules/"+rule.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestInsightRulesRouteOnCreate(t *testing.T) {
+	env, bus := newRecordingEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": project, "title": "Pricing"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+
+	resp = env.post(t, "/api/insight-rules", map[string]any{
+		"project": project, "name": "hot competitors", "category": "competitor",
+		"min_score": 0.8, "spec_id": spec.ID, "notify_agent": "pm",
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	// Dry run reports the plan without side effects.
+	resp = env.post(t, "/api/insight-rules/test", map[string]any{
+		"project": project, "category": "Competitor", "source": "web", "title": "Rival cut prices", "score": 0.9,
+	})
+	requireStatus(t, resp, http.StatusOK)
+	plan := decodeJSON[insightRuleTestResponse](t, resp)
+	if len(plan.Matched) != 1 || len(plan.Actions) != 1 || plan.Actions[0].LinkSpecID != spec.ID {
+		t.Fatalf("unexpected dry run: %+v", plan)
+	}
+	if len(bus.ofType(core.EventInsightRouted)) != 0 {
+		t.Fatal("dry run must not route")
+	}
+
+	// Below the score threshold: no match.
+	resp = env.post(t, "/api/insights", map[string]any{
+		"project": project, "category": "competitor", "source": "web", "title": "Minor rumor", "score": 0.5,
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	if low := decodeJSON[core.Insight](t, resp); low.SpecID != "" {
+		t.Fatalf("low-score insight should not be linked, got %q", low.SpecID)
+	}
+
+	resp = env.post(t, "/api/insights", map[string]any{
+		"project": project, "category": "competitor", "source": "web", "title": "Rival cut prices", "score": 0.9,
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	insight := decodeJSON[core.Insight](t, resp)
+	if insight.SpecID != spec.ID {
+		t.Fatalf("expected insight linked to %s, got %q", spec.ID, insight.SpecID)
+	}
+
+	resp = env.get(t, "/api/insights/"+insight.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if stored := decodeJSON[core.Insight](t, resp); stored.SpecID != spec.ID {
+		t.Fatalf("link not persisted: %q", stored.SpecID)
+	}
+
+	resp = env.get(t, "/api/inbox/pm?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	inbox := decodeJSON[map[string]any](t, resp)
+	msgs := inbox["messages"].([]any)
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(msgs))
+	}
+	if from := msgs[0].(map[string]any)["from"]; from != systemSender {
+		t.Errorf("expected system sender, got %v", from)
+	}
+
+	routed := bus.ofType(core.EventInsightRouted)
+	if len(routed) != 1 {
+		t.Fatalf("expected 1 insight.routed event, got %d", len(routed))
+	}
+}
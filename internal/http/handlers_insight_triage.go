@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Insight triage: insights move new -> triaged -> actioned | dismissed, and
+// every update emits insight.updated, plus insight.status_changed when the
+// status moves.
+
+// insightPatch is a partial insight update. Absent fields keep their stored
+// values; an absent version means "whatever is current".
+type insightPatch struct {
+	Project  string              `json:"project"`
+	SpecID   *string             `json:"spec_id"`
+	Category *string             `json:"category"`
+	Title    *string             `json:"title"`
+	Body     *string             `json:"body"`
+	URL      *string             `json:"url"`
+	Score    *float64            `json:"score"`
+	Status   *core.InsightStatus `json:"status"`
+	Version  *int64              `json:"version"`
+}
+
+type insightStatusChange struct {
+	Insight core.Insight       `json:"insight"`
+	From    core.InsightStatus `json:"from"`
+	To      core.InsightStatus `json:"to"`
+}
+
+func (s *DomainService) updateInsight(w http.ResponseWriter, r *http.Request, id string) {
+	var insight core.Insight
+	if err := json.NewDecoder(r.Body).Decode(&insight); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	insight.ID = id
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && insight.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	current, err := s.domainStore.GetInsight(r.Context(), insight.Project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	s.saveInsight(r.Context(), w, current, insight)
+}
+
+// patchInsight applies a partial update, typically a triage decision like
+// {"status": "dismissed"}.
+func (s *DomainService) patchInsight(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var patch insightPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	project, ok := groupProject(w, r, patch.Project)
+	if !ok {
+		return
+	}
+	current, err := s.domainStore.GetInsight(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	next := current
+	setIf(&next.SpecID, patch.SpecID)
+	setIf(&next.Category, patch.Category)
+	setIf(&next.Title, patch.Title)
+	setIf(&next.Body, patch.Body)
+	setIf(&next.URL, patch.URL)
+	setIf(&next.Score, patch.Score)
+	setIf(&next.Status, patch.Status)
+	setIf(&next.Version, patch.Version)
+	s.saveInsight(r.Context(), w, current, next)
+}
+
+func setIf[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// saveInsight validates the status move from current to next, stores next
+// under optimistic locking and announces the change.
+func (s *DomainService) saveInsight(ctx context.Context, w http.ResponseWriter, current, next core.Insight) {
+	if next.Status == "" {
+		next.Status = current.Status
+	}
+	if !core.ValidInsightStatus(next.Status) {
+		writeJSONError(w, http.StatusBadRequest, "invalid status: "+string(next.Status), "invalid_request")
+		return
+	}
+	if !current.Status.CanTransitionTo(next.Status) {
+		writeJSONError(w, http.StatusConflict,
+			"cannot move insight from "+string(current.Status)+" to "+string(next.Status), "invalid_transition")
+		return
+	}
+
+	updated, err := s.domainStore.UpdateInsight(ctx, next)
+	if err != nil {
+		if errors.Is(err, core.ErrConcurrentModification) {
+			current, getErr := s.domainStore.GetInsight(ctx, next.Project, next.ID)
+			writeVersionConflict(w, current, current.Version, getErr)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(ctx, updated.Project, core.EventInsightUpdated, updated.ID, updated)
+	if updated.Status != current.Status {
+		s.broadcastDomainEvent(ctx, updated.Project, core.EventInsightStatusChanged, updated.ID,
+			insightStatusChange{Insight: updated, From: current.Status, To: updated.Status})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestInsightTriageLifecycle(t *testing.T) {
+	env, bus := newRecordingEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/insights", map[string]any{
+		"project": project, "source": "pollard", "category": "competitor", "title": "Rival ships X", "score": 0.4,
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	created := decodeJSON[core.Insight](t, resp)
+	if created.Status != core.InsightStatusNew {
+		t.Fatalf("new insight status = %q, want new", created.Status)
+	}
+
+	// PATCH only touches the fields it names.
+	resp = env.patch(t, "/api/insights/"+created.ID, map[string]any{"project": project, "status": "triaged", "score": 0.8})
+	requireStatus(t, resp, http.StatusOK)
+	triaged := decodeJSON[core.Insight](t, resp)
+	if triaged.Status != core.InsightStatusTriaged || triaged.Score != 0.8 || triaged.Title != "Rival ships X" {
+		t.Fatalf("triaged = %+v", triaged)
+	}
+	if triaged.Version != created.Version+1 {
+		t.Fatalf("version = %d, want %d", triaged.Version, created.Version+1)
+	}
+
+	changes := bus.ofType(core.EventInsightStatusChanged)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 status change event, got %d", len(changes))
+	}
+	change := changes[0]["data"].(insightStatusChange)
+	if change.From != core.InsightStatusNew || change.To != core.InsightStatusTriaged {
+		t.Fatalf("status change = %s -> %s", change.From, change.To)
+	}
+	if got := len(bus.ofType(core.EventInsightUpdated)); got != 1 {
+		t.Fatalf("expected 1 insight.updated event, got %d", got)
+	}
+
+	// A stale version loses even on PATCH.
+	resp = env.patch(t, "/api/insights/"+created.ID, map[string]any{"project": project, "title": "late", "version": created.Version})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	resp = env.patch(t, "/api/insights/"+created.ID, map[string]any{"project": project, "status": "actioned"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// Actioned is terminal.
+	resp = env.patch(t, "/api/insights/"+created.ID, map[string]any{"project": project, "status": "new"})
+	requireStatus(t, resp, http.StatusConflict)
+	if body := decodeJSON[map[string]any](t, resp); body["code"] != "invalid_transition" {
+		t.Fatalf("expected invalid_transition, got %v", body)
+	}
+
+	resp = env.patch(t, "/api/insights/"+created.ID, map[string]any{"project": project, "status": "bogus"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	// Filter the backlog by status.
+	resp = env.post(t, "/api/insights", map[string]any{"project": project, "source": "pollard", "category": "trend", "title": "Fresh"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.get(t, "/api/insights?project="+project+"&status=new")
+	requireStatus(t, resp, http.StatusOK)
+	backlog := decodeJSON[[]core.Insight](t, resp)
+	if len(backlog) != 1 || backlog[0].Title != "Fresh" {
+		t.Fatalf("new backlog = %+v, want only Fresh", backlog)
+	}
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// KeyManager lists and revokes the server's API keys. Keys are named by
+// their fingerprint (auth.KeyID), never by the key itself.
+type KeyManager interface {
+	ListKeys() ([]auth.KeyInfo, error)
+	// RevokeKey returns core.ErrNotFound for an unknown ID.
+	RevokeKey(id string) (auth.KeyInfo, error)
+}
+
+// handleAdminKeys serves GET /api/admin/keys, optionally narrowed to one
+// ?project=.
+func (s *DomainService) handleAdminKeys(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) || !s.requireKeyManager(w) {
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get: func(w http.ResponseWriter, r *http.Request) {
+			keys, err := s.keyAdmin.ListKeys()
+			if err != nil {
+				writeInternalError(w)
+				return
+			}
+			out := []auth.KeyInfo{}
+			project := r.URL.Query().Get("project")
+			for _, k := range keys {
+				if project == "" || k.Project == project {
+					out = append(out, k)
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"keys": out})
+		},
+	})
+}
+
+// handleAdminKeyByID serves DELETE /api/admin/keys/{id}, which revokes the
+// key at once and returns what it was issued to.
+func (s *DomainService) handleAdminKeyByID(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) || !s.requireKeyManager(w) {
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/keys/"), "/")
+	if id == "" {
+		writeNotFound(w)
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		delete: func(w http.ResponseWriter, r *http.Request) {
+			info, err := s.keyAdmin.RevokeKey(id)
+			if err != nil {
+				if errors.Is(err, core.ErrNotFound) {
+					writeNotFound(w)
+					return
+				}
+				writeInternalError(w)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(info)
+		},
+	})
+}
+
+func (s *DomainService) requireKeyManager(w http.ResponseWriter) bool {
+	if s.keyAdmin == nil {
+		writeJSONError(w, http.StatusNotImplemented, "key management not configured", "keys_unavailable")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,351 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Lifecycle hook handlers. Hooks are declarative rules that fire when a
+// spec, epic, story or task changes status through PUT; each run is
+// recorded in the hook audit log whether or not its actions succeed.
+
+const (
+	defaultHookRunLimit = 50
+	maxHookRunLimit     = 500
+)
+
+// hookSubject is the entity whose status change is being run past the
+// project's hooks.
+type hookSubject struct {
+	Project    string
+	EntityType string
+	ID         string
+	ShortID    string
+	Title      string
+	From       string
+	To         string
+	// StoryID is the story tasks created by the hook go under: the entity
+	// itself for a story, a task's story for a task.
+	StoryID string
+}
+
+// expand fills a hook template's placeholders in from the subject.
+func (subj hookSubject) expand(tmpl string) string {
+	return strings.NewReplacer(
+		"{{id}}", subj.ID,
+		"{{short_id}}", subj.ShortID,
+		"{{title}}", subj.Title,
+		"{{type}}", subj.EntityType,
+		"{{from}}", subj.From,
+		"{{to}}", subj.To,
+		"{{project}}", subj.Project,
+	).Replace(tmpl)
+}
+
+// lifecycleHookRequest defaults Enabled to true when omitted.
+type lifecycleHookRequest struct {
+	core.LifecycleHook
+	Enabled *bool `json:"enabled"`
+}
+
+func (req lifecycleHookRequest) hook() core.LifecycleHook {
+	hook := req.LifecycleHook
+	hook.Enabled = req.Enabled == nil || *req.Enabled
+	return hook
+}
+
+type hookRunsResponse struct {
+	Runs []core.HookRun `json:"runs"`
+}
+
+// validLifecycleHook returns why hook can't be saved, or "" if it can.
+func validLifecycleHook(hook core.LifecycleHook) string {
+	if strings.TrimSpace(hook.Name) == "" {
+		return "hook needs a name"
+	}
+	if !slices.Contains(core.HookEntityTypes, hook.EntityType) {
+		return "entity_type must be one of " + strings.Join(core.HookEntityTypes, ", ")
+	}
+	if !core.KnownStatus(hook.EntityType, hook.ToStatus) {
+		return "unknown to_status for " + hook.EntityType + ": " + hook.ToStatus
+	}
+	if hook.FromStatus != "" && !core.KnownStatus(hook.EntityType, hook.FromStatus) {
+		return "unknown from_status for " + hook.EntityType + ": " + hook.FromStatus
+	}
+	if len(hook.Actions) == 0 {
+		return "hook needs at least one action"
+	}
+	for i, a := range hook.Actions {
+		switch a.Type {
+		case core.HookActionCreateTask:
+			if strings.TrimSpace(a.Title) == "" {
+				return fmt.Sprintf("actions[%d]: create_task needs a title", i)
+			}
+		case core.HookActionNotify:
+			if strings.TrimSpace(a.Agent) == "" {
+				return fmt.Sprintf("actions[%d]: notify needs an agent", i)
+			}
+		default:
+			return fmt.Sprintf("actions[%d]: unknown type %q", i, a.Type)
+		}
+	}
+	return ""
+}
+
+func (s *DomainService) handleLifecycleHooks(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get:  s.listLifecycleHooks,
+		post: s.createLifecycleHook,
+	})
+}
+
+func (s *DomainService) handleLifecycleHookByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/hooks/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" || len(parts) > 2 {
+		writeNotFound(w)
+		return
+	}
+	id := parts[0]
+	if len(parts) == 2 {
+		if parts[1] != "runs" {
+			writeNotFound(w)
+			return
+		}
+		dispatchByMethod(w, r, methodHandlers{
+			get: func(w http.ResponseWriter, r *http.Request) { s.listHookRuns(w, r, id) },
+		})
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get:    func(w http.ResponseWriter, r *http.Request) { s.getLifecycleHook(w, r, id) },
+		put:    func(w http.ResponseWriter, r *http.Request) { s.updateLifecycleHook(w, r, id) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteLifecycleHook(w, r, id) },
+	})
+}
+
+func (s *DomainService) createLifecycleHook(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req lifecycleHookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	hook := req.hook()
+	if msg := validLifecycleHook(hook); msg != "" {
+		writeJSONError(w, http.StatusBadRequest, msg, "invalid_hook")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && hook.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	created, err := s.domainStore.CreateLifecycleHook(r.Context(), hook)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (s *DomainService) getLifecycleHook(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	hook, err := s.domainStore.GetLifecycleHook(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+func (s *DomainService) listLifecycleHooks(w http.ResponseWriter, r *http.Request) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	hooks, err := s.domainStore.ListLifecycleHooks(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if hooks == nil {
+		hooks = []core.LifecycleHook{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hooks)
+}
+
+func (s *DomainService) updateLifecycleHook(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var req lifecycleHookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	hook := req.hook()
+	hook.ID = id
+	if msg := validLifecycleHook(hook); msg != "" {
+		writeJSONError(w, http.StatusBadRequest, msg, "invalid_hook")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && hook.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	updated, err := s.domainStore.UpdateLifecycleHook(r.Context(), hook)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (s *DomainService) deleteLifecycleHook(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if err := s.domainStore.DeleteLifecycleHook(r.Context(), project, id); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listHookRuns serves a hook's audit log, newest first. The log outlives
+// the hook, so a deleted hook's runs can still be read.
+func (s *DomainService) listHookRuns(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	limit := defaultHookRunLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer", "invalid_limit")
+			return
+		}
+		limit = min(n, maxHookRunLimit)
+	}
+	runs, err := s.domainStore.ListHookRuns(r.Context(), project, id, limit)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if runs == nil {
+		runs = []core.HookRun{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hookRunsResponse{Runs: runs})
+}
+
+// runLifecycleHooks fires the project's enabled hooks matching subj's
+// status change, in creation order, and records each run. Hooks only
+// create tasks and send messages, neither of which changes a status, so
+// one hook can't set off another. Failures are logged and recorded, never
+// returned: the change that fired the hook has already been saved.
+func (s *DomainService) runLifecycleHooks(ctx context.Context, subj hookSubject) {
+	hooks, err := s.domainStore.ListLifecycleHooks(ctx, subj.Project)
+	if err != nil {
+		log.Printf("WARN: lifecycle hooks for %s %s: %v", subj.EntityType, subj.ID, err)
+		return
+	}
+	for _, hook := range hooks {
+		if !hook.Enabled || !hook.Matches(subj.EntityType, subj.From, subj.To) {
+			continue
+		}
+		run := core.HookRun{
+			Project:    subj.Project,
+			HookID:     hook.ID,
+			HookName:   hook.Name,
+			EntityType: subj.EntityType,
+			EntityID:   subj.ID,
+			FromStatus: subj.From,
+			ToStatus:   subj.To,
+			OK:         true,
+		}
+		for _, action := range hook.Actions {
+			result := s.runHookAction(ctx, subj, action)
+			if result.Error != "" {
+				log.Printf("WARN: lifecycle hook %s: %s on %s %s: %s", hook.ID, action.Type, subj.EntityType, subj.ID, result.Error)
+				run.OK = false
+			}
+			run.Results = append(run.Results, result)
+		}
+		if _, err := s.domainStore.RecordHookRun(ctx, run); err != nil {
+			log.Printf("WARN: record lifecycle hook %s run: %v", hook.ID, err)
+		}
+	}
+}
+
+func (s *DomainService) runHookAction(ctx context.Context, subj hookSubject, action core.HookAction) core.HookActionResult {
+	result := core.HookActionResult{Type: action.Type}
+	switch action.Type {
+	case core.HookActionCreateTask:
+		task := core.Task{
+			Project: subj.Project,
+			StoryID: subj.StoryID,
+			Title:   subj.expand(action.Title),
+			Agent:   action.Agent,
+			Status:  core.TaskStatusPending,
+		}
+		if task.StoryID != "" {
+			if story, err := s.domainStore.GetStory(ctx, task.Project, task.StoryID); err == nil {
+				task.Priority = story.Priority
+			}
+		}
+		created, err := s.domainStore.CreateTask(ctx, task)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.TaskID = created.ID
+		s.broadcastDomainEvent(ctx, subj.Project, core.EventTaskCreated, created.ID, created)
+	case core.HookActionNotify:
+		body := subj.expand(action.Message)
+		if body == "" {
+			body = subj.expand("{{type}} {{title}} ({{id}}) moved from {{from}} to {{to}}.")
+		}
+		msg, err := s.sendSystemMessage(ctx, subj.Project, []string{action.Agent}, subj.expand("Hook: {{type}} {{to}}: {{title}}"), body)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.MessageID = msg.ID
+	default:
+		result.Error = fmt.Sprintf("unknown action type %q", action.Type)
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestLifecycleHookCreatesReviewTask(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/hooks", map[string]any{
+		"project": "proj", "name": "review on review", "entity_type": "story",
+		"from_status": "in_progress", "to_status": "review",
+		"actions": []core.HookAction{
+			{Type: core.HookActionCreateTask, Title: "Review {{short_id}}: {{title}}", Agent: "critic"},
+			{Type: core.HookActionNotify, Agent: "lead"},
+		},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	hook := decodeJSON[core.LifecycleHook](t, resp)
+	if !hook.Enabled || len(hook.Actions) != 2 {
+		t.Fatalf("hook = %+v", hook)
+	}
+
+	resp = env.post(t, "/api/stories", map[string]any{"project": "proj", "title": "Login", "status": "in_progress", "priority": "high"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+
+	// in_progress -> review fires the hook.
+	story.Status = core.StoryStatusReview
+	resp = env.put(t, "/api/stories/"+story.ID, story)
+	requireStatus(t, resp, http.StatusOK)
+	story = decodeJSON[core.Story](t, resp)
+
+	resp = env.get(t, "/api/tasks?project=proj&agent=critic")
+	requireStatus(t, resp, http.StatusOK)
+	tasks := decodeJSON[[]core.Task](t, resp)
+	if len(tasks) != 1 {
+		t.Fatalf("tasks = %+v, want one review task", tasks)
+	}
+	review := tasks[0]
+	if review.Title != "Review "+story.ShortID+": Login" || review.StoryID != story.ID ||
+		review.Status != core.TaskStatusPending || review.Priority != core.PriorityHigh {
+		t.Fatalf("review task = %+v", review)
+	}
+
+	resp = env.get(t, "/api/hooks/"+hook.ID+"/runs?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	runs := decodeJSON[hookRunsResponse](t, resp).Runs
+	if len(runs) != 1 {
+		t.Fatalf("runs = %+v, want one", runs)
+	}
+	run := runs[0]
+	if !run.OK || run.EntityID != story.ID || run.FromStatus != "in_progress" || run.ToStatus != "review" ||
+		len(run.Results) != 2 || run.Results[0].TaskID != review.ID || run.Results[1].MessageID == "" {
+		t.Fatalf("run = %+v", run)
+	}
+
+	// Saving without a status change, or with the hook disabled, doesn't fire.
+	resp = env.put(t, "/api/stories/"+story.ID, story)
+	requireStatus(t, resp, http.StatusOK)
+	story = decodeJSON[core.Story](t, resp)
+	hook.Enabled = false
+	resp = env.put(t, "/api/hooks/"+hook.ID, hook)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	story.Status = core.StoryStatusInProgress
+	resp = env.put(t, "/api/stories/"+story.ID, story)
+	requireStatus(t, resp, http.StatusOK)
+	story = decodeJSON[core.Story](t, resp)
+	story.Status = core.StoryStatusReview
+	resp = env.put(t, "/api/stories/"+story.ID, story)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.get(t, "/api/hooks/"+hook.ID+"/runs?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if runs := decodeJSON[hookRunsResponse](t, resp).Runs; len(runs) != 1 {
+		t.Fatalf("runs = %+v, want still one", runs)
+	}
+
+	for _, body := range []map[string]any{
+		{"name": "no actions", "entity_type": "story", "to_status": "review"},
+		{"name": "bad type", "entity_type": "session", "to_status": "idle", "actions": []core.HookAction{{Type: core.HookActionNotify, Agent: "a"}}},
+		{"name": "bad status", "entity_type": "story", "to_status": "shipped", "actions": []core.HookAction{{Type: core.HookActionNotify, Agent: "a"}}},
+		{"name": "bad action", "entity_type": "task", "to_status": "done", "actions": []core.HookAction{{Type: "run_script"}}},
+	} {
+		body["project"] = "proj"
+		resp := env.post(t, "/api/hooks", body)
+		requireStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// entityPaths maps entity types to their collection path, for canonical
+// URLs.
+var entityPaths = map[string]string{
+	"spec":    "/api/specs/",
+	"epic":    "/api/epics/",
+	"story":   "/api/stories/",
+	"task":    "/api/tasks/",
+	"insight": "/api/insights/",
+	"session": "/api/sessions/",
+	"cuj":     "/api/cujs/",
+	"goal":    "/api/goals/",
+}
+
+type lookupResponse struct {
+	ID      string           `json:"id"`
+	Matches []core.EntityRef `json:"matches"`
+}
+
+// handleLookup serves GET /api/lookup/{id}: what is this ID? It takes a
+// UUID or short ID of any entity type and returns every entity in the
+// caller's project it names (IDs are only unique per type, so there may be
+// more than one), with a canonical URL to fetch each. 404 if none.
+func (s *DomainService) handleLookup(w http.ResponseWriter, r *http.Request) {
+	ref := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/lookup/"), "/")
+	if ref == "" || strings.Contains(ref, "/") {
+		writeNotFound(w)
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get: func(w http.ResponseWriter, r *http.Request) { s.lookup(w, r, ref) },
+	})
+}
+
+func (s *DomainService) lookup(w http.ResponseWriter, r *http.Request, ref string) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	matches, err := s.domainStore.LookupEntity(r.Context(), project, ref)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if len(matches) == 0 {
+		writeJSONError(w, http.StatusNotFound, "no entity with this id", "not_found")
+		return
+	}
+	for i := range matches {
+		m := &matches[i]
+		m.URL = entityPaths[m.Type] + url.PathEscape(m.ID) + "?project=" + url.QueryEscape(m.Project)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(lookupResponse{ID: ref, Matches: matches})
+}
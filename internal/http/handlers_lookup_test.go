@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestLookupByIDOrShortID(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "Fix flaky tests", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+	resp = env.post(t, "/api/insights", map[string]any{"project": "proj", "title": "Users want dark mode", "source": "survey", "category": "ux"})
+	requireStatus(t, resp, http.StatusCreated)
+	insight := decodeJSON[core.Insight](t, resp)
+
+	for _, ref := range []string{task.ID, "task-1"} {
+		resp = env.get(t, "/api/lookup/"+ref+"?project=proj")
+		requireStatus(t, resp, http.StatusOK)
+		got := decodeJSON[lookupResponse](t, resp)
+		if len(got.Matches) != 1 {
+			t.Fatalf("lookup %s = %+v", ref, got.Matches)
+		}
+		m := got.Matches[0]
+		if m.Type != "task" || m.ID != task.ID || m.ShortID != "TASK-1" || m.Title != "Fix flaky tests" || m.Status != "pending" {
+			t.Fatalf("lookup %s = %+v", ref, m)
+		}
+		// The canonical URL fetches the entity.
+		resp = env.get(t, m.URL)
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	resp = env.get(t, "/api/lookup/"+insight.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[lookupResponse](t, resp); len(got.Matches) != 1 || got.Matches[0].Type != "insight" {
+		t.Fatalf("insight lookup = %+v", got.Matches)
+	}
+
+	// Lookups stay within the project.
+	resp = env.get(t, "/api/lookup/"+task.ID+"?project=other")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
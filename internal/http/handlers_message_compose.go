@@ -0,0 +1,234 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// replyRequest is the body of POST /api/messages/{id}/reply. Recipients
+// and threading are derived from the original message.
+type replyRequest struct {
+	Project     string             `json:"project"`
+	From        string             `json:"from"`
+	Body        string             `json:"body"`
+	ReplyAll    bool               `json:"reply_all,omitempty"`
+	Quote       bool               `json:"quote,omitempty"`
+	Importance  string             `json:"importance,omitempty"`
+	AckRequired bool               `json:"ack_required,omitempty"`
+	Transport   core.TransportMode `json:"transport,omitempty"`
+}
+
+// forwardRequest is the body of POST /api/messages/{id}/forward. Body is an
+// optional note placed above the forwarded content.
+type forwardRequest struct {
+	Project     string             `json:"project"`
+	From        string             `json:"from"`
+	To          []string           `json:"to"`
+	CC          []string           `json:"cc,omitempty"`
+	BCC         []string           `json:"bcc,omitempty"`
+	Body        string             `json:"body,omitempty"`
+	Importance  string             `json:"importance,omitempty"`
+	AckRequired bool               `json:"ack_required,omitempty"`
+	Transport   core.TransportMode `json:"transport,omitempty"`
+}
+
+// replyToMessage sends a reply in the original's thread, addressed to its
+// sender (plus its To and CC recipients with reply_all), with a "Re:"
+// subject and in_reply_to pointing at the original.
+func (s *Service) replyToMessage(w http.ResponseWriter, r *http.Request, msgID string) {
+	limitBody(w, r)
+	var req replyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if strings.TrimSpace(req.From) == "" {
+		writeJSONError(w, http.StatusBadRequest, "from is required", "missing_field")
+		return
+	}
+	orig, ok := s.loadOriginal(w, r, req.Project, msgID, req.From)
+	if !ok {
+		return
+	}
+
+	to := []string{orig.From}
+	var cc []string
+	if req.ReplyAll {
+		to = append(to, orig.To...)
+		cc = orig.CC
+	}
+	to = withoutAgent(dedupeAgents(to), req.From)
+	cc = withoutAgent(dedupeAgents(cc), req.From)
+	if len(to) == 0 {
+		// Replying to one's own message goes back to its recipients.
+		to = withoutAgent(dedupeAgents(orig.To), req.From)
+	}
+	if len(to) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "to is required", "missing_field")
+		return
+	}
+
+	body := req.Body
+	if req.Quote {
+		body = strings.TrimRight(body, "\n") + "\n\n" + quoteMessage(orig)
+	}
+	s.sendMessage(w, r.Context(), sendMessageRequest{
+		ThreadID:    threadOf(orig),
+		Project:     orig.Project,
+		From:        req.From,
+		To:          to,
+		CC:          cc,
+		Subject:     prefixSubject("Re:", orig.Subject),
+		Topic:       orig.Topic,
+		InReplyTo:   orig.ID,
+		Body:        body,
+		Importance:  req.Importance,
+		Transport:   req.Transport,
+		AckRequired: req.AckRequired,
+	})
+}
+
+// forwardMessage sends a copy of the original to new recipients with a
+// "Fwd:" subject. The forward starts its own thread (keyed by the new
+// message ID) and links back to the original via in_reply_to.
+func (s *Service) forwardMessage(w http.ResponseWriter, r *http.Request, msgID string) {
+	limitBody(w, r)
+	var req forwardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if strings.TrimSpace(req.From) == "" || len(req.To) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "from and to are required", "missing_field")
+		return
+	}
+	orig, ok := s.loadOriginal(w, r, req.Project, msgID, req.From)
+	if !ok {
+		return
+	}
+
+	body := forwardedBlock(orig)
+	if note := strings.TrimSpace(req.Body); note != "" {
+		body = note + "\n\n" + body
+	}
+	id := uuid.NewString()
+	s.sendMessage(w, r.Context(), sendMessageRequest{
+		ID:          id,
+		ThreadID:    id,
+		Project:     orig.Project,
+		From:        req.From,
+		To:          req.To,
+		CC:          req.CC,
+		BCC:         req.BCC,
+		Subject:     prefixSubject("Fwd:", orig.Subject),
+		Topic:       orig.Topic,
+		InReplyTo:   orig.ID,
+		Body:        body,
+		Importance:  req.Importance,
+		Transport:   req.Transport,
+		AckRequired: req.AckRequired,
+	})
+}
+
+// loadOriginal fetches the message being replied to or forwarded and checks
+// that agent was its sender or one of its recipients. It writes the error
+// response itself and returns false on failure.
+func (s *Service) loadOriginal(w http.ResponseWriter, r *http.Request, project, msgID, agent string) (core.Message, bool) {
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey {
+		if project != "" && project != info.Project {
+			writeProjectMismatch(w)
+			return core.Message{}, false
+		}
+		project = info.Project
+	}
+	if project == "" {
+		project = strings.TrimSpace(r.URL.Query().Get("project"))
+	}
+	orig, err := s.store.GetMessage(r.Context(), project, msgID)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return core.Message{}, false
+		}
+		writeInternalError(w)
+		return core.Message{}, false
+	}
+	if agent != orig.From && !slices.Contains(orig.Recipients(), agent) {
+		writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
+		return core.Message{}, false
+	}
+	return orig, true
+}
+
+// threadOf returns the thread a reply to m belongs in. A message sent
+// outside any thread becomes the root of a new one.
+func threadOf(m core.Message) string {
+	if m.ThreadID != "" {
+		return m.ThreadID
+	}
+	return m.ID
+}
+
+// prefixSubject adds prefix ("Re:" or "Fwd:") unless the subject already
+// starts with it, so repeated replies don't stack "Re: Re: ...".
+func prefixSubject(prefix, subject string) string {
+	subject = strings.TrimSpace(subject)
+	if strings.HasPrefix(strings.ToLower(subject), strings.ToLower(prefix)) {
+		return subject
+	}
+	if subject == "" {
+		return prefix
+	}
+	return prefix + " " + subject
+}
+
+// quoteMessage renders m as an attribution line followed by its body with
+// each line prefixed by "> ".
+func quoteMessage(m core.Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "On %s, %s wrote:\n", m.CreatedAt.UTC().Format(time.RFC3339), m.From)
+	for _, line := range strings.Split(strings.TrimRight(m.Body, "\n"), "\n") {
+		if line == "" {
+			b.WriteString(">\n")
+			continue
+		}
+		b.WriteString("> " + line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// forwardedBlock renders m with a header block in the conventional
+// forwarded-message layout. BCC recipients are never included.
+func forwardedBlock(m core.Message) string {
+	var b strings.Builder
+	b.WriteString("---------- Forwarded message ----------\n")
+	fmt.Fprintf(&b, "From: %s\n", m.From)
+	fmt.Fprintf(&b, "Date: %s\n", m.CreatedAt.UTC().Format(time.RFC3339))
+	if m.Subject != "" {
+		fmt.Fprintf(&b, "Subject: %s\n", m.Subject)
+	}
+	fmt.Fprintf(&b, "To: %s\n", strings.Join(m.To, ", "))
+	if len(m.CC) > 0 {
+		fmt.Fprintf(&b, "Cc: %s\n", strings.Join(m.CC, ", "))
+	}
+	b.WriteString("\n" + m.Body)
+	return b.String()
+}
+
+func dedupeAgents(agents []string) []string {
+	return core.Message{To: agents}.Recipients()
+}
+
+func withoutAgent(agents []string, agent string) []string {
+	return slices.DeleteFunc(slices.Clone(agents), func(a string) bool { return a == agent })
+}
@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReplyAndForward(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/messages", map[string]any{
+		"project": project,
+		"from":    "alice",
+		"to":      []string{"bob"},
+		"cc":      []string{"carol"},
+		"subject": "Deploy plan",
+		"body":    "ship at noon\nok?",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	origID := decodeJSON[sendMessageResponse](t, resp).MessageID
+
+	// Outsiders can't reply to or forward a message they never saw.
+	resp = env.post(t, "/api/messages/"+origID+"/reply?project="+project, map[string]any{"from": "mallory", "body": "hi"})
+	requireStatus(t, resp, http.StatusForbidden)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/messages/missing/reply?project="+project, map[string]any{"from": "bob", "body": "hi"})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/messages/"+origID+"/reply?project="+project, map[string]any{
+		"from": "bob", "body": "yes", "reply_all": true, "quote": true,
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	inbox := func(agent string) []apiMessage {
+		t.Helper()
+		resp := env.get(t, "/api/inbox/"+agent+"?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[inboxResponse](t, resp).Messages
+	}
+
+	replies := inbox("alice")
+	if len(replies) != 1 {
+		t.Fatalf("alice expected 1 reply, got %d", len(replies))
+	}
+	reply := replies[0]
+	if reply.Subject != "Re: Deploy plan" || reply.InReplyTo != origID || reply.ThreadID != origID {
+		t.Fatalf("unexpected reply threading: %+v", reply)
+	}
+	if !strings.Contains(reply.Body, "alice wrote:\n> ship at noon\n> ok?") {
+		t.Errorf("expected quoted original, got %q", reply.Body)
+	}
+	if len(inbox("carol")) != 2 {
+		t.Error("reply_all should reach the original cc")
+	}
+
+	// A reply to a reply keeps a single "Re:" prefix and the same thread.
+	resp = env.post(t, "/api/messages/"+reply.ID+"/reply?project="+project, map[string]any{"from": "alice", "body": "great"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	bobMsgs := inbox("bob")
+	last := bobMsgs[len(bobMsgs)-1]
+	if last.Subject != "Re: Deploy plan" || last.ThreadID != origID {
+		t.Errorf("unexpected nested reply: %+v", last)
+	}
+
+	resp = env.post(t, "/api/messages/"+origID+"/forward?project="+project, map[string]any{
+		"from": "bob", "to": []string{"dave"}, "body": "FYI",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	fwdID := decodeJSON[sendMessageResponse](t, resp).MessageID
+
+	fwds := inbox("dave")
+	if len(fwds) != 1 {
+		t.Fatalf("dave expected 1 forward, got %d", len(fwds))
+	}
+	fwd := fwds[0]
+	if fwd.Subject != "Fwd: Deploy plan" || fwd.InReplyTo != origID || fwd.ThreadID != fwdID {
+		t.Fatalf("unexpected forward: %+v", fwd)
+	}
+	if !strings.HasPrefix(fwd.Body, "FYI\n\n---------- Forwarded message ----------\nFrom: alice\n") ||
+		!strings.HasSuffix(fwd.Body, "ship at noon\nok?") {
+		t.Errorf("unexpected forward body: %q", fwd.Body)
+	}
+}
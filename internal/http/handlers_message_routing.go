@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Capability routing. A send with a route names a capability expression
+// instead of (or as well as) agents: the server picks the least-loaded
+// eligible agent, or every eligible agent with fan_out, adds them to To and
+// records the resolution on the message.
+
+// routeFreshness is how recently an agent must have been seen to be routed
+// to; it matches the sweeper's heartbeat grace period.
+const routeFreshness = 5 * time.Minute
+
+type sendRouteRequest struct {
+	Capability string `json:"capability"`
+	FanOut     bool   `json:"fan_out,omitempty"`
+}
+
+// routeCandidate is an eligible agent and the load it is ranked by.
+type routeCandidate struct {
+	agent    core.Agent
+	unread   int
+	lastSeen time.Time
+}
+
+// resolveRoute picks req.Route's recipients and adds them to req.To. It
+// writes the error response itself and returns (nil, false) when the
+// expression is invalid or no agent is eligible; with no route it returns
+// (nil, true).
+func (s *Service) resolveRoute(ctx context.Context, w http.ResponseWriter, project string, req *sendMessageRequest) (*core.MessageRoute, bool) {
+	if req.Route == nil {
+		return nil, true
+	}
+	expr, err := core.ParseCapabilityExpr(req.Route.Capability)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "route.capability needs capabilities joined by & and |", "invalid_route")
+		return nil, false
+	}
+	reg, err := s.capabilityRegistry(ctx, project)
+	if err != nil {
+		writeInternalError(w)
+		return nil, false
+	}
+	expr, unknown := expr.Normalize(reg)
+	if len(unknown) > 0 {
+		writeJSONError(w, http.StatusBadRequest, "unknown capabilities: "+strings.Join(unknown, ", "), "unknown_capability")
+		return nil, false
+	}
+
+	candidates, err := s.routeCandidates(ctx, project, req.From, expr, reg)
+	if err != nil {
+		writeInternalError(w)
+		return nil, false
+	}
+	if len(candidates) == 0 {
+		writeJSONErrorFields(w, http.StatusServiceUnavailable, "no recently seen agent matches "+expr.String(), "no_eligible_agent", map[string]any{
+			"capability": expr.String(),
+		})
+		return nil, false
+	}
+
+	route := &core.MessageRoute{Capability: expr.String(), FanOut: req.Route.FanOut}
+	for _, c := range candidates {
+		route.Candidates = append(route.Candidates, c.agent.ID)
+	}
+	route.Selected = route.Candidates[:1]
+	if route.FanOut {
+		route.Selected = route.Candidates
+	}
+	req.To = dedupeAgents(append(req.To, route.Selected...))
+	return route, true
+}
+
+// routeCandidates lists the agents other than sender that match expr and
+// were seen within routeFreshness, least loaded first: fewest unread
+// messages, then most recently seen.
+func (s *Service) routeCandidates(ctx context.Context, project, sender string, expr core.CapabilityExpr, reg core.CapabilityRegistry) ([]routeCandidate, error) {
+	agents, err := s.store.ListAgents(ctx, project, nil)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := clock.Now().UTC().Add(-routeFreshness)
+	var out []routeCandidate
+	for _, a := range agents {
+		if a.ID == sender || a.LastSeen.Before(cutoff) || !expr.Matches(canonicalCapabilities(reg, a.Capabilities)) {
+			continue
+		}
+		_, unread, err := s.store.InboxCounts(ctx, project, a.ID)
+		if err != nil && !errors.Is(err, core.ErrNotFound) {
+			return nil, err
+		}
+		out = append(out, routeCandidate{agent: a, unread: unread, lastSeen: a.LastSeen})
+	}
+	slices.SortFunc(out, func(a, b routeCandidate) int {
+		return cmp.Or(
+			cmp.Compare(a.unread, b.unread),
+			b.lastSeen.Compare(a.lastSeen),
+			cmp.Compare(a.agent.ID, b.agent.ID),
+		)
+	})
+	return out, nil
+}
+
+// canonicalCapabilities maps caps through reg, so an agent that registered
+// an alias before it was added to the registry still matches.
+func canonicalCapabilities(reg core.CapabilityRegistry, caps []string) []string {
+	out := make([]string, len(caps))
+	for i, c := range caps {
+		if name, ok := reg.Canonical(c); ok {
+			c = name
+		}
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+)
+
+func TestSendRoutesByCapability(t *testing.T) {
+	env := newTestEnv(t)
+	t.Cleanup(clock.Reset)
+	register := func(name string, caps ...string) string {
+		t.Helper()
+		resp := env.post(t, "/api/agents", map[string]any{"name": name, "project": "proj", "capabilities": caps})
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[registerAgentResponse](t, resp).AgentID
+	}
+	send := func(body map[string]any) *http.Response {
+		t.Helper()
+		body["project"] = "proj"
+		body["from"] = "lead"
+		if _, ok := body["body"]; !ok {
+			body["body"] = "please review"
+		}
+		return env.post(t, "/api/messages", body)
+	}
+
+	stale := register("stale", "go", "review")
+	clock.Advance(10 * time.Minute)
+	busy := register("busy", "go", "review")
+	idle := register("idle", "Go", "review")
+	rusty := register("rusty", "rust", "review")
+	register("writer", "go")
+
+	// busy already has work waiting, so the idle reviewer gets it.
+	resp := send(map[string]any{"to": []string{busy}, "body": "earlier"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = send(map[string]any{"route": map[string]any{"capability": "review & go"}})
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[sendMessageResponse](t, resp)
+	if got.Route == nil || got.Route.Capability != "review & go" || len(got.Route.Selected) != 1 || got.Route.Selected[0] != idle {
+		t.Fatalf("route = %+v, want idle selected", got.Route)
+	}
+	if len(got.Route.Candidates) != 2 || got.Route.Candidates[0] != idle || got.Route.Candidates[1] != busy {
+		t.Fatalf("candidates = %v, want [idle busy] (stale excluded)", got.Route.Candidates)
+	}
+	resp = env.get(t, "/api/inbox/"+idle+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	msgs := decodeJSON[inboxResponse](t, resp).Messages
+	if len(msgs) != 1 || msgs[0].Route == nil || msgs[0].Route.Selected[0] != idle {
+		t.Fatalf("idle inbox = %+v, want the routed message with its route", msgs)
+	}
+	resp = env.get(t, "/api/inbox/"+stale+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if msgs := decodeJSON[inboxResponse](t, resp).Messages; len(msgs) != 0 {
+		t.Fatalf("stale agent was routed to: %+v", msgs)
+	}
+
+	// Fan out reaches every match.
+	resp = send(map[string]any{"route": map[string]any{"capability": "go & review | rust & review", "fan_out": true}})
+	requireStatus(t, resp, http.StatusOK)
+	got = decodeJSON[sendMessageResponse](t, resp)
+	if got.Route == nil || len(got.Route.Selected) != 3 {
+		t.Fatalf("fan out route = %+v, want three agents", got.Route)
+	}
+	resp = env.get(t, "/api/inbox/"+rusty+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if msgs := decodeJSON[inboxResponse](t, resp).Messages; len(msgs) != 1 || len(msgs[0].To) != 3 || !msgs[0].Route.FanOut {
+		t.Fatalf("rusty inbox = %+v, want the fanned-out message", msgs)
+	}
+
+	resp = send(map[string]any{"route": map[string]any{"capability": "go &"}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = send(map[string]any{"route": map[string]any{"capability": "haskell"}})
+	requireStatus(t, resp, http.StatusServiceUnavailable)
+	if e := decodeJSON[errorResponse](t, resp); e.Code != "no_eligible_agent" {
+		t.Fatalf("error = %+v, want no_eligible_agent", e)
+	}
+}
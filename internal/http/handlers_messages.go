@@ -5,18 +5,23 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/anomaly"
 	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
 	"github.com/mistakeknot/intermute/internal/core"
 )
 
 type sendMessageRequest struct {
 	ID               string             `json:"id"`
 	ThreadID         string             `json:"thread_id"`
+	EntityType       string             `json:"entity_type,omitempty"`
+	EntityID         string             `json:"entity_id,omitempty"`
 	Project          string             `json:"project"`
 	From             string             `json:"from"`
 	To               []string           `json:"to"`
@@ -24,40 +29,54 @@ type sendMessageRequest struct {
 	BCC              []string           `json:"bcc,omitempty"`
 	Subject          string             `json:"subject,omitempty"`
 	Topic            string             `json:"topic,omitempty"`
+	InReplyTo        string             `json:"in_reply_to,omitempty"`
 	Body             string             `json:"body"`
+	Attachments      []core.Attachment  `json:"attachments,omitempty"`
 	Importance       string             `json:"importance,omitempty"`
 	Transport        core.TransportMode `json:"transport,omitempty"`
 	TargetWindowUUID string             `json:"target_window_uuid,omitempty"`
 	AckRequired      bool               `json:"ack_required,omitempty"`
+	AckPolicy        string             `json:"ack_policy,omitempty"`
+	Route            *sendRouteRequest  `json:"route,omitempty"`
 }
 
 type sendMessageResponse struct {
-	MessageID string   `json:"message_id"`
-	Cursor    uint64   `json:"cursor"`
-	Denied    []string `json:"denied,omitempty"`
-	Delivery  any      `json:"delivery,omitempty"`
+	MessageID string             `json:"message_id"`
+	ThreadID  string             `json:"thread_id,omitempty"`
+	Cursor    uint64             `json:"cursor"`
+	Denied    []string           `json:"denied,omitempty"`
+	Delivery  any                `json:"delivery,omitempty"`
+	Route     *core.MessageRoute `json:"route,omitempty"`
 }
 
-type policyDeniedResponse struct {
-	Error  string   `json:"error"`
-	Denied []string `json:"denied"`
+// writePolicyDenied rejects a send whose every recipient's contact policy
+// refused the sender.
+func writePolicyDenied(w http.ResponseWriter, denied []string) {
+	writeJSONErrorFields(w, http.StatusForbidden, "every recipient's contact policy denied the sender", "policy_denied", map[string]any{
+		"denied": denied,
+	})
 }
 
 type apiMessage struct {
-	ID          string   `json:"id"`
-	ThreadID    string   `json:"thread_id"`
-	Project     string   `json:"project"`
-	From        string   `json:"from"`
-	To          []string `json:"to"`
-	CC          []string `json:"cc,omitempty"`
-	BCC         []string `json:"bcc,omitempty"`
-	Subject     string   `json:"subject,omitempty"`
-	Topic       string   `json:"topic,omitempty"`
-	Body        string   `json:"body"`
-	Importance  string   `json:"importance,omitempty"`
-	AckRequired bool     `json:"ack_required,omitempty"`
-	CreatedAt   string   `json:"created_at"`
-	Cursor      uint64   `json:"cursor"`
+	ID          string              `json:"id"`
+	ThreadID    string              `json:"thread_id"`
+	Project     string              `json:"project"`
+	From        string              `json:"from"`
+	To          []string            `json:"to"`
+	CC          []string            `json:"cc,omitempty"`
+	BCC         []string            `json:"bcc,omitempty"`
+	Subject     string              `json:"subject,omitempty"`
+	Topic       string              `json:"topic,omitempty"`
+	InReplyTo   string              `json:"in_reply_to,omitempty"`
+	Groups      map[string][]string `json:"groups,omitempty"`
+	Route       *core.MessageRoute  `json:"route,omitempty"`
+	Body        string              `json:"body"`
+	Attachments []core.Attachment   `json:"attachments,omitempty"`
+	Importance  string              `json:"importance,omitempty"`
+	AckRequired bool                `json:"ack_required,omitempty"`
+	AckPolicy   core.AckPolicy      `json:"ack_policy,omitempty"`
+	CreatedAt   string              `json:"created_at"`
+	Cursor      uint64              `json:"cursor"`
 }
 
 type inboxResponse struct {
@@ -65,6 +84,42 @@ type inboxResponse struct {
 	Cursor   uint64       `json:"cursor"`
 }
 
+// messageViewer is the agent messages are rendered for (toAPIMessage): the
+// caller's bound or declared identity, never the agent the request names
+// in its path or ?agent=, so naming a sender doesn't disclose its BCC
+// lists. Callers without an identity see no BCC recipients.
+func messageViewer(r *http.Request) string {
+	info, _ := auth.FromContext(r.Context())
+	return info.AgentID
+}
+
+// toAPIMessage renders m as seen by viewer; see core.Message.VisibleTo for
+// how BCC recipients are redacted.
+func toAPIMessage(m core.Message, viewer string) apiMessage {
+	m = m.VisibleTo(viewer)
+	return apiMessage{
+		ID:          m.ID,
+		ThreadID:    m.ThreadID,
+		Project:     m.Project,
+		From:        m.From,
+		To:          m.To,
+		CC:          m.CC,
+		BCC:         m.BCC,
+		Subject:     m.Subject,
+		Topic:       m.Topic,
+		InReplyTo:   m.InReplyTo,
+		Groups:      m.Groups,
+		Route:       m.Route,
+		Body:        m.Body,
+		Attachments: m.Attachments,
+		Importance:  m.Importance,
+		AckRequired: m.AckRequired,
+		AckPolicy:   m.AckPolicy,
+		CreatedAt:   m.CreatedAt.Format(time.RFC3339Nano),
+		Cursor:      m.Cursor,
+	}
+}
+
 type recipientPlan struct {
 	Agent      string
 	FocusState string
@@ -82,20 +137,53 @@ type allowedRecipients struct {
 
 func (s *Service) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
-	limitBody(w, r)
+	s.limitSendBody(w, r)
 	req, ok := parseSendRequest(w, r)
 	if !ok {
 		return
 	}
-	ctx := r.Context()
+	if req.EntityType != "" || req.EntityID != "" {
+		writeJSONError(w, http.StatusBadRequest, "addressing an entity needs the domain API", "invalid_request")
+		return
+	}
+	s.sendMessage(w, r.Context(), req)
+}
+
+// sendMessage runs an already-validated send request through contact
+// policy, transport selection and delivery, and writes the response.
+func (s *Service) sendMessage(w http.ResponseWriter, ctx context.Context, req sendMessageRequest) {
 	project := strings.TrimSpace(req.Project)
+	if !s.checkMessageBody(w, req.Body) {
+		return
+	}
+	attachments, ok := s.resolveAttachments(ctx, w, project, req.Attachments)
+	if !ok {
+		return
+	}
+
+	route, ok := s.resolveRoute(ctx, w, project, &req)
+	if !ok {
+		return
+	}
+	groups, ok := s.expandGroupAddresses(ctx, w, project, &req)
+	if !ok {
+		return
+	}
+	if len(req.To) == 0 && len(req.CC) == 0 && len(req.BCC) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "no recipients after group expansion", "no_recipients")
+		return
+	}
 
 	transport := s.resolveTransport(ctx, req.Transport)
 	if !core.ValidTransport(transport) {
-		http.Error(w, "invalid transport", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "invalid transport", "invalid_request")
+		return
+	}
+	if transport != core.TransportAsync && slices.ContainsFunc(slices.Concat(req.To, req.CC), isGroupAddress) {
+		writeJSONError(w, http.StatusBadRequest, "teams only receive async messages", "invalid_request")
 		return
 	}
 
@@ -109,10 +197,7 @@ func (s *Service) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 
 	plans, busy := s.resolveRecipientPlans(ctx, project, req.TargetWindowUUID, transport, allowed.To)
 	if busy != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"error":       "recipient_busy",
+		writeJSONErrorFields(w, http.StatusServiceUnavailable, busy.Agent+" is busy ("+busy.FocusState+")", "recipient_busy", map[string]any{
 			"agent":       busy.Agent,
 			"focus_state": busy.FocusState,
 		})
@@ -120,6 +205,12 @@ func (s *Service) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	msg := buildSendMessage(req, project, transport, allowed)
+	msg.Groups = groups
+	msg.Route = route
+	msg.Attachments = attachments
+	if !applyAckPolicy(w, req, &msg) {
+		return
+	}
 	deliveries, pokeEvents := s.deliverLive(ctx, project, msg, transport, plans)
 
 	if transport == core.TransportLive {
@@ -134,21 +225,21 @@ func (s *Service) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 func parseSendRequest(w http.ResponseWriter, r *http.Request) (sendMessageRequest, bool) {
 	var req sendMessageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return req, false
 	}
-	if strings.TrimSpace(req.From) == "" || len(req.To) == 0 {
-		w.WriteHeader(http.StatusBadRequest)
+	if strings.TrimSpace(req.From) == "" || (len(req.To) == 0 && req.Route == nil) {
+		writeJSONError(w, http.StatusBadRequest, "from and to (or route) are required", "missing_field")
 		return req, false
 	}
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey {
 		if strings.TrimSpace(req.Project) == "" {
-			w.WriteHeader(http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "project is required", "missing_field")
 			return req, false
 		}
 		if req.Project != info.Project {
-			w.WriteHeader(http.StatusForbidden)
+			writeProjectMismatch(w)
 			return req, false
 		}
 	}
@@ -196,12 +287,7 @@ func (s *Service) resolveAllowedRecipients(ctx context.Context, w http.ResponseW
 	allDenied := append(append(deniedTo, deniedCC...), deniedBCC...)
 
 	if len(allowedTo) == 0 && len(allowedCC) == 0 && len(allowedBCC) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		_ = json.NewEncoder(w).Encode(policyDeniedResponse{
-			Error:  "policy_denied",
-			Denied: allDenied,
-		})
+		writePolicyDenied(w, allDenied)
 		return allowedRecipients{}, false
 	}
 	return allowedRecipients{
@@ -223,10 +309,7 @@ func (s *Service) enforceLiveRateLimit(w http.ResponseWriter, from string, to []
 		if s.liveAllow(from, recipient) {
 			continue
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusTooManyRequests)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"error":               "rate_limit",
+		writeJSONErrorFields(w, http.StatusTooManyRequests, "live send rate limit exceeded for "+recipient, "rate_limit", map[string]any{
 			"retry_after_seconds": int(liveRateWindow / time.Second),
 		})
 		return false
@@ -249,11 +332,12 @@ func buildSendMessage(req sendMessageRequest, project string, transport core.Tra
 		BCC:         allowed.BCC,
 		Subject:     req.Subject,
 		Topic:       req.Topic,
+		InReplyTo:   req.InReplyTo,
 		Body:        req.Body,
 		Importance:  req.Importance,
 		Transport:   transport,
 		AckRequired: req.AckRequired,
-		CreatedAt:   time.Now().UTC(),
+		CreatedAt:   clock.Now().UTC(),
 	}
 }
 
@@ -264,16 +348,13 @@ func buildSendMessage(req sendMessageRequest, project string, transport core.Tra
 // the successful inject is not audited, matching plan intent.
 func (s *Service) respondLive(w http.ResponseWriter, ctx context.Context, pokeEvents []core.Event, deliveries map[string]string, denied []string) {
 	if len(pokeEvents) == 0 || hasFailedDelivery(deliveries) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"error":    "delivery_failed",
+		writeJSONErrorFields(w, http.StatusServiceUnavailable, "live delivery failed", "delivery_failed", map[string]any{
 			"delivery": collapseDeliveries(deliveries),
 		})
 		return
 	}
 	if _, err := s.store.AppendEvents(ctx, pokeEvents...); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -286,34 +367,34 @@ func (s *Service) respondLive(w http.ResponseWriter, ctx context.Context, pokeEv
 // respondDurable writes the HTTP response for transport in {async, both}.
 // Durable message + poke events commit atomically via AppendEvents.
 func (s *Service) respondDurable(w http.ResponseWriter, ctx context.Context, project string, msg core.Message, pokeEvents []core.Event, deliveries map[string]string, denied []string) {
-	events := []core.Event{{Type: core.EventMessageCreated, Project: project, Message: msg}}
+	events := []core.Event{{ID: uuid.NewString(), Type: core.EventMessageCreated, Project: project, Message: msg}}
 	events = append(events, pokeEvents...)
 	cursors, err := s.store.AppendEvents(ctx, events...)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	cursor := uint64(0)
 	if len(cursors) > 0 {
 		cursor = cursors[0]
 	}
+	if n := len(cursors); n > 0 {
+		noteEventCursor(ctx, cursors[n-1])
+	}
+	s.observe(func(d *anomaly.Detector) []core.Anomaly { return d.ObserveMessage(project, msg.ThreadID) })
 	if s.bus != nil {
-		for _, agent := range msg.To {
-			s.bus.Broadcast(project, agent, map[string]any{
-				"type":       string(core.EventMessageCreated),
-				"project":    project,
-				"message_id": msg.ID,
-				"cursor":     cursor,
-				"agent":      agent,
-			})
+		for _, agent := range s.deliveryTargets(ctx, project, msg.Recipients()) {
+			s.bus.Broadcast(project, agent, messageCreatedEvent(ctx, project, agent, events[0].ID, msg.ID, cursor))
 		}
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(sendMessageResponse{
 		MessageID: msg.ID,
+		ThreadID:  msg.ThreadID,
 		Cursor:    cursor,
 		Denied:    denied,
 		Delivery:  collapseDeliveries(deliveries),
+		Route:     msg.Route,
 	})
 }
 
@@ -408,7 +489,7 @@ func (s *Service) deliverLive(ctx context.Context, project string, msg core.Mess
 					To:        []string{plan.Agent},
 					Body:      msg.Body,
 					Transport: msg.Transport,
-					CreatedAt: time.Now().UTC(),
+					CreatedAt: clock.Now().UTC(),
 					Metadata: map[string]string{
 						"poke_result": core.PokeResultDeferred,
 						"poke_reason": "recipient_" + plan.FocusState,
@@ -431,7 +512,7 @@ func (s *Service) deliverLive(ctx context.Context, project string, msg core.Mess
 							To:        []string{plan.Agent},
 							Body:      msg.Body,
 							Transport: msg.Transport,
-							CreatedAt: time.Now().UTC(),
+							CreatedAt: clock.Now().UTC(),
 							Metadata: map[string]string{
 								"poke_result": core.PokeResultFailed,
 								"poke_reason": err.Error(),
@@ -452,7 +533,7 @@ func (s *Service) deliverLive(ctx context.Context, project string, msg core.Mess
 						To:        []string{plan.Agent},
 						Body:      msg.Body,
 						Transport: msg.Transport,
-						CreatedAt: time.Now().UTC(),
+						CreatedAt: clock.Now().UTC(),
 						Metadata: map[string]string{
 							"poke_result": core.PokeResultDeferred,
 							"poke_reason": "inject_failed: " + err.Error(),
@@ -474,7 +555,7 @@ func (s *Service) deliverLive(ctx context.Context, project string, msg core.Mess
 					To:        []string{plan.Agent},
 					Body:      msg.Body,
 					Transport: msg.Transport,
-					CreatedAt: time.Now().UTC(),
+					CreatedAt: clock.Now().UTC(),
 					Metadata: map[string]string{
 						"poke_result": core.PokeResultInjected,
 					},
@@ -570,7 +651,7 @@ func (s *Service) senderAllowedAuto(ctx context.Context, project, sender, recipi
 
 func (s *Service) handleInbox(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	// Check if this is a counts request: /api/inbox/{agent}/counts
@@ -584,9 +665,13 @@ func (s *Service) handleInbox(w http.ResponseWriter, r *http.Request) {
 		s.handleStaleAcks(w, r)
 		return
 	}
+	if strings.HasSuffix(path, "/snoozed") {
+		s.handleInboxSnoozed(w, r)
+		return
+	}
 	agent := strings.Trim(path, "/")
 	if agent == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -606,9 +691,22 @@ func (s *Service) handleInbox(w http.ResponseWriter, r *http.Request) {
 			limit = parsed
 		}
 	}
-	msgs, err := s.store.InboxSince(r.Context(), project, agent, cursor, limit)
+	wait, err := parseInboxWait(r.URL.Query().Get("wait"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid wait: "+err.Error(), "invalid_request")
+		return
+	}
+	// Re-deliver anything whose snooze has ended before reading, rather
+	// than waiting for the next sweep.
+	wakes, err := s.store.WakeSnoozed(r.Context(), project, agent, clock.Now().UTC())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	s.broadcastWakes(wakes)
+	msgs, err := s.waitForInbox(r.Context(), project, agent, cursor, limit, wait)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	lastCursor := cursor
@@ -617,22 +715,7 @@ func (s *Service) handleInbox(w http.ResponseWriter, r *http.Request) {
 	}
 	apiMsgs := make([]apiMessage, 0, len(msgs))
 	for _, m := range msgs {
-		apiMsgs = append(apiMsgs, apiMessage{
-			ID:          m.ID,
-			ThreadID:    m.ThreadID,
-			Project:     m.Project,
-			From:        m.From,
-			To:          m.To,
-			CC:          m.CC,
-			BCC:         m.BCC,
-			Subject:     m.Subject,
-			Topic:       m.Topic,
-			Body:        m.Body,
-			Importance:  m.Importance,
-			AckRequired: m.AckRequired,
-			CreatedAt:   m.CreatedAt.Format(time.RFC3339Nano),
-			Cursor:      m.Cursor,
-		})
+		apiMsgs = append(apiMsgs, toAPIMessage(m, messageViewer(r)))
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(inboxResponse{Messages: apiMsgs, Cursor: lastCursor})
@@ -645,7 +728,7 @@ type inboxCountsResponse struct {
 
 func (s *Service) handleInboxCounts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	// Path: /api/inbox/{agent}/counts
@@ -653,7 +736,7 @@ func (s *Service) handleInboxCounts(w http.ResponseWriter, r *http.Request) {
 	path = strings.TrimSuffix(path, "/counts")
 	agent := strings.Trim(path, "/")
 	if agent == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
 		return
 	}
 
@@ -665,7 +748,7 @@ func (s *Service) handleInboxCounts(w http.ResponseWriter, r *http.Request) {
 
 	total, unread, err := s.store.InboxCounts(r.Context(), project, agent)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -697,7 +780,7 @@ type staleAcksResponse struct {
 
 func (s *Service) handleStaleAcks(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	// Path: /api/inbox/{agent}/stale-acks
@@ -705,7 +788,7 @@ func (s *Service) handleStaleAcks(w http.ResponseWriter, r *http.Request) {
 	path = strings.TrimSuffix(path, "/stale-acks")
 	agent := strings.Trim(path, "/")
 	if agent == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
 		return
 	}
 
@@ -730,7 +813,7 @@ func (s *Service) handleStaleAcks(w http.ResponseWriter, r *http.Request) {
 
 	staleAcks, err := s.store.InboxStaleAcks(r.Context(), project, agent, ttlSeconds, limit)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -771,17 +854,31 @@ type messageActionRequest struct {
 
 func (s *Service) handleMessageAction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	path := strings.TrimPrefix(r.URL.Path, "/api/messages/")
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	if len(parts) != 2 {
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	msgID := parts[0]
 	action := parts[1]
+	switch action {
+	case "reply":
+		s.replyToMessage(w, r, msgID)
+		return
+	case "forward":
+		s.forwardMessage(w, r, msgID)
+		return
+	case "snooze":
+		s.snoozeMessage(w, r, msgID)
+		return
+	case "unsnooze":
+		s.unsnoozeMessage(w, r, msgID)
+		return
+	}
 	var evType core.EventType
 	switch action {
 	case "ack":
@@ -789,7 +886,7 @@ func (s *Service) handleMessageAction(w http.ResponseWriter, r *http.Request) {
 	case "read":
 		evType = core.EventMessageRead
 	default:
-		w.WriteHeader(http.StatusNotFound)
+		writeNotFound(w)
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
@@ -807,40 +904,53 @@ func (s *Service) handleMessageAction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update per-recipient tracking if agent ID is provided
+	acked := false
 	if agentID != "" {
 		switch action {
 		case "read":
 			_ = s.store.MarkRead(r.Context(), project, msgID, agentID)
 		case "ack":
-			_ = s.store.MarkAck(r.Context(), project, msgID, agentID)
+			acked = s.store.MarkAck(r.Context(), project, msgID, agentID) == nil
 		}
 	}
 
-	_, err := s.store.AppendEvent(r.Context(), core.Event{Type: evType, Agent: agentID, Project: project, Message: core.Message{ID: msgID, Project: project}})
+	eventID := uuid.NewString()
+	cursor, err := s.store.AppendEvent(r.Context(), core.Event{ID: eventID, Type: evType, Agent: agentID, Project: project, Message: core.Message{ID: msgID, Project: project}})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	noteEventCursor(r.Context(), cursor)
 	if s.bus != nil {
-		s.bus.Broadcast(project, "", map[string]any{
+		event := map[string]any{
 			"type":       string(evType),
+			"event_id":   eventID,
 			"project":    project,
 			"message_id": msgID,
-		})
+		}
+		addTrace(event, core.TraceFromContext(r.Context()))
+		s.bus.Broadcast(project, "", event)
+	}
+	if acked {
+		if quorum, err := s.checkAckQuorum(r.Context(), project, msgID, agentID); err == nil && quorum.Policy != "" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"quorum": quorum})
+			return
+		}
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *Service) handleTopicMessages(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	// Path: /api/topics/{project}/{topic}
 	path := strings.TrimPrefix(r.URL.Path, "/api/topics/")
 	parts := strings.SplitN(strings.Trim(path, "/"), "/", 2)
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "path must be /api/topics/{project}/{topic}", "invalid_request")
 		return
 	}
 	project := parts[0]
@@ -849,7 +959,7 @@ func (s *Service) handleTopicMessages(w http.ResponseWriter, r *http.Request) {
 	info, _ := auth.FromContext(r.Context())
 	if info.Mode == auth.ModeAPIKey {
 		if project != info.Project {
-			w.WriteHeader(http.StatusForbidden)
+			writeProjectMismatch(w)
 			return
 		}
 	}
@@ -869,31 +979,17 @@ func (s *Service) handleTopicMessages(w http.ResponseWriter, r *http.Request) {
 
 	msgs, err := s.store.TopicMessages(r.Context(), project, topic, cursor, limit)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 	lastCursor := cursor
 	if len(msgs) > 0 {
 		lastCursor = msgs[len(msgs)-1].Cursor
 	}
+	viewer := messageViewer(r)
 	apiMsgs := make([]apiMessage, 0, len(msgs))
 	for _, m := range msgs {
-		apiMsgs = append(apiMsgs, apiMessage{
-			ID:          m.ID,
-			ThreadID:    m.ThreadID,
-			Project:     m.Project,
-			From:        m.From,
-			To:          m.To,
-			CC:          m.CC,
-			BCC:         m.BCC,
-			Subject:     m.Subject,
-			Topic:       m.Topic,
-			Body:        m.Body,
-			Importance:  m.Importance,
-			AckRequired: m.AckRequired,
-			CreatedAt:   m.CreatedAt.Format(time.RFC3339Nano),
-			Cursor:      m.Cursor,
-		})
+		apiMsgs = append(apiMsgs, toAPIMessage(m, viewer))
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(inboxResponse{Messages: apiMsgs, Cursor: lastCursor})
@@ -918,16 +1014,19 @@ type broadcastResponse struct {
 
 func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	var req broadcastRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	if strings.TrimSpace(req.From) == "" || strings.TrimSpace(req.Topic) == "" || strings.TrimSpace(req.Body) == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "from, topic and body are required", "missing_field")
+		return
+	}
+	if !s.checkMessageBody(w, req.Body) {
 		return
 	}
 
@@ -935,11 +1034,11 @@ func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 	project := strings.TrimSpace(req.Project)
 	if info.Mode == auth.ModeAPIKey {
 		if project == "" {
-			w.WriteHeader(http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "project is required", "missing_field")
 			return
 		}
 		if project != info.Project {
-			w.WriteHeader(http.StatusForbidden)
+			writeProjectMismatch(w)
 			return
 		}
 	}
@@ -947,7 +1046,7 @@ func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 	// Rate limit: 10 broadcasts per minute per sender
 	if s.broadcastExceeded(project, req.From) {
 		w.Header().Set("Retry-After", "60")
-		w.WriteHeader(http.StatusTooManyRequests)
+		writeJSONError(w, http.StatusTooManyRequests, "broadcast rate limit exceeded", "rate_limited")
 		return
 	}
 
@@ -956,7 +1055,7 @@ func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 	// Resolve all agents in the project
 	agents, err := s.store.ListAgents(ctx, project, nil)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -976,12 +1075,7 @@ func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 	// Filter by contact policies (no threadID exception for broadcasts)
 	allowed, denied := s.filterByPolicy(ctx, project, req.From, "", toList)
 	if len(allowed) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		_ = json.NewEncoder(w).Encode(policyDeniedResponse{
-			Error:  "policy_denied",
-			Denied: denied,
-		})
+		writePolicyDenied(w, denied)
 		return
 	}
 
@@ -994,28 +1088,25 @@ func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 		Subject:   req.Subject,
 		Topic:     req.Topic,
 		Body:      req.Body,
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: clock.Now().UTC(),
 	}
+	eventID := uuid.NewString()
 	cursor, err := s.store.AppendEvent(ctx, core.Event{
+		ID:      eventID,
 		Type:    core.EventMessageCreated,
 		Project: project,
 		Message: msg,
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	noteEventCursor(ctx, cursor)
 
 	// SSE notification per recipient
 	if s.bus != nil {
 		for _, agent := range allowed {
-			s.bus.Broadcast(project, agent, map[string]any{
-				"type":       string(core.EventMessageCreated),
-				"project":    project,
-				"message_id": msgID,
-				"cursor":     cursor,
-				"agent":      agent,
-			})
+			s.bus.Broadcast(project, agent, messageCreatedEvent(ctx, project, agent, eventID, msgID, cursor))
 		}
 	}
 
@@ -1027,3 +1118,59 @@ func (s *Service) handleBroadcast(w http.ResponseWriter, r *http.Request) {
 		Denied:    denied,
 	})
 }
+
+// messageCreatedEvent is the message.created notification pushed to
+// agent, one of the recipients.
+func messageCreatedEvent(ctx context.Context, project, agent, eventID, messageID string, cursor uint64) map[string]any {
+	event := map[string]any{
+		"type":       string(core.EventMessageCreated),
+		"event_id":   eventID,
+		"project":    project,
+		"message_id": messageID,
+		"cursor":     cursor,
+		"agent":      agent,
+	}
+	addTrace(event, core.TraceFromContext(ctx))
+	return event
+}
+
+// systemSender is the From address on messages the server itself sends.
+const systemSender = "intermute"
+
+// sendSystemMessage appends a server-originated message to the recipients'
+// inboxes and notifies them over the bus. It bypasses contact policies.
+func (s *Service) sendSystemMessage(ctx context.Context, project string, to []string, subject, body string) (core.Message, error) {
+	return s.sendSystemThreadMessage(ctx, project, "", to, subject, body)
+}
+
+// sendSystemThreadMessage is sendSystemMessage posted into threadID. The
+// message is recorded in the thread even when to is empty.
+func (s *Service) sendSystemThreadMessage(ctx context.Context, project, threadID string, to []string, subject, body string) (core.Message, error) {
+	msg := core.Message{
+		ID:        uuid.NewString(),
+		ThreadID:  threadID,
+		Project:   project,
+		From:      systemSender,
+		To:        to,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: clock.Now().UTC(),
+	}
+	eventID := uuid.NewString()
+	cursor, err := s.store.AppendEvent(ctx, core.Event{
+		ID:      eventID,
+		Type:    core.EventMessageCreated,
+		Project: project,
+		Message: msg,
+	})
+	if err != nil {
+		return core.Message{}, err
+	}
+	noteEventCursor(ctx, cursor)
+	if s.bus != nil {
+		for _, agent := range s.deliveryTargets(ctx, project, to) {
+			s.bus.Broadcast(project, agent, messageCreatedEvent(ctx, project, agent, eventID, msg.ID, cursor))
+		}
+	}
+	return msg, nil
+}
@@ -11,16 +11,14 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/mistakeknot/intermute/internal/auth"
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/livetransport"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestSendMessageAndFetchInbox(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -313,10 +311,7 @@ func (f *fakeLiveDelivery) ValidateTarget(_ *livetransport.Target) error {
 
 func newTransportTestService(t *testing.T) (*Service, *fakeLiveDelivery) {
 	t.Helper()
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	fake := &fakeLiveDelivery{}
 	return NewService(st).WithLiveDelivery(fake), fake
 }
@@ -532,3 +527,86 @@ func TestSendTransportLiveRateLimit(t *testing.T) {
 		t.Fatalf("expected retry_after_seconds body, got %s", rr.Body.String())
 	}
 }
+
+func TestBCCVisibility(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/messages", map[string]any{
+		"project":   project,
+		"from":      "alice",
+		"to":        []string{"bob"},
+		"cc":        []string{"carol", "bob"},
+		"bcc":       []string{"dave", "erin"},
+		"thread_id": "t1",
+		"body":      "quiet update",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// Callers identify themselves with X-Agent-ID; the agent a request
+	// names in its path or ?agent= doesn't count.
+	authed := httptest.NewServer(NewDomainRouter(NewDomainService(env.store), nil, auth.Middleware(auth.NewKeyring(true, nil))))
+	t.Cleanup(authed.Close)
+	getAs := func(viewer, path string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, authed.URL+path, nil)
+		if viewer != "" {
+			req.Header.Set("X-Agent-ID", viewer)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		requireStatus(t, resp, http.StatusOK)
+		return resp
+	}
+	inboxOf := func(agent string) []apiMessage {
+		t.Helper()
+		return decodeJSON[inboxResponse](t, getAs(agent, "/api/inbox/"+agent+"?project="+project)).Messages
+	}
+
+	// Every recipient gets exactly one copy, whichever fields they appear in.
+	for _, agent := range []string{"bob", "carol", "dave", "erin"} {
+		if msgs := inboxOf(agent); len(msgs) != 1 {
+			t.Fatalf("%s: expected 1 message, got %d", agent, len(msgs))
+		}
+	}
+
+	if bcc := inboxOf("bob")[0].BCC; len(bcc) != 0 {
+		t.Errorf("to recipient should not see bcc, got %v", bcc)
+	}
+	if bcc := inboxOf("dave")[0].BCC; len(bcc) != 1 || bcc[0] != "dave" {
+		t.Errorf("bcc recipient should see only themselves, got %v", bcc)
+	}
+
+	threadBCC := func(viewer, named string) []string {
+		t.Helper()
+		resp := getAs(viewer, "/api/threads/t1?project="+project+"&agent="+named)
+		msgs := decodeJSON[threadMessagesResponse](t, resp).Messages
+		if len(msgs) != 1 {
+			t.Fatalf("expected 1 thread message, got %d", len(msgs))
+		}
+		return msgs[0].BCC
+	}
+	if bcc := threadBCC("alice", ""); len(bcc) != 2 {
+		t.Errorf("sender should see full bcc, got %v", bcc)
+	}
+	if bcc := threadBCC("carol", ""); len(bcc) != 0 {
+		t.Errorf("cc recipient should not see bcc, got %v", bcc)
+	}
+	if bcc := threadBCC("", ""); len(bcc) != 0 {
+		t.Errorf("anonymous thread read should not see bcc, got %v", bcc)
+	}
+	if bcc := threadBCC("", "alice"); len(bcc) != 0 {
+		t.Errorf("anonymous read naming the sender should not see bcc, got %v", bcc)
+	}
+	if bcc := threadBCC("bob", "alice"); len(bcc) != 0 {
+		t.Errorf("bob naming alice should not see bcc, got %v", bcc)
+	}
+	resp = env.get(t, "/api/inbox/dave?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if msgs := decodeJSON[inboxResponse](t, resp).Messages; len(msgs) != 1 || len(msgs[0].BCC) != 0 {
+		t.Errorf("unidentified inbox read should not see bcc, got %+v", msgs)
+	}
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// BreakerStater reports the storage circuit breaker state
+// ("closed", "open", "half_open"). Implemented by *sqlite.ResilientStore.
+type BreakerStater interface {
+	CircuitBreakerState() string
+}
+
+// CategoryBreakerStater is a BreakerStater with one breaker per operation
+// category ("reads", "writes", "sweeps"). Implemented by
+// *sqlite.ResilientStore.
+type CategoryBreakerStater interface {
+	BreakerStater
+	CircuitBreakerStates() map[string]string
+}
+
+// SweepCounter reports how many expired reservations have been swept.
+// Implemented by *sqlite.Sweeper.
+type SweepCounter interface {
+	SweptTotal() uint64
+}
+
+// WithMetricsSources wires process-local gauges into /metrics. Either may
+// be nil, in which case the corresponding series are omitted.
+func (s *DomainService) WithMetricsSources(breaker BreakerStater, sweeper SweepCounter) *DomainService {
+	s.breaker = breaker
+	s.sweeper = sweeper
+	return s
+}
+
+// handleMetrics exports coordination-health gauges in the Prometheus text
+// exposition format, so alerts can fire on stuck work rather than only on
+// server liveness.
+func (s *DomainService) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	m, err := s.domainStore.DomainMetrics(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	writeLabeledGauge(bw, "intermute_tasks", "Tasks by project and status.", m.TasksByStatus, true)
+	writeLabeledGauge(bw, "intermute_stale_agents", "Agents whose last heartbeat is older than the stale threshold.", m.StaleAgents, false)
+	writeLabeledGauge(bw, "intermute_active_reservations", "Unreleased, unexpired file reservations.", m.ActiveReservations, false)
+	writeLabeledGauge(bw, "intermute_unacked_messages", "Recipients that have not acked an ack_required message.", m.UnackedMessages, false)
+	s.queries.write(bw)
+	s.compress.write(bw)
+	s.requests.write(bw)
+
+	if s.breaker != nil {
+		state := s.breaker.CircuitBreakerState()
+		fmt.Fprintln(bw, "# HELP intermute_circuit_breaker_state Storage circuit breaker state (1 for the current state).")
+		fmt.Fprintln(bw, "# TYPE intermute_circuit_breaker_state gauge")
+		for _, st := range []string{"closed", "open", "half_open"} {
+			v := 0
+			if st == state {
+				v = 1
+			}
+			fmt.Fprintf(bw, "intermute_circuit_breaker_state{state=%q} %d\n", st, v)
+		}
+		if cats, ok := s.breaker.(CategoryBreakerStater); ok {
+			states := cats.CircuitBreakerStates()
+			fmt.Fprintln(bw, "# HELP intermute_circuit_breaker_category_state Per-category storage circuit breaker state (1 for the current state).")
+			fmt.Fprintln(bw, "# TYPE intermute_circuit_breaker_category_state gauge")
+			for _, cat := range slices.Sorted(maps.Keys(states)) {
+				for _, st := range []string{"closed", "open", "half_open"} {
+					v := 0
+					if st == states[cat] {
+						v = 1
+					}
+					fmt.Fprintf(bw, "intermute_circuit_breaker_category_state{category=%q,state=%q} %d\n", cat, st, v)
+				}
+			}
+		}
+	}
+	if s.sweeper != nil {
+		fmt.Fprintln(bw, "# HELP intermute_sweeper_deleted_total Expired reservations removed by the sweeper.")
+		fmt.Fprintln(bw, "# TYPE intermute_sweeper_deleted_total counter")
+		fmt.Fprintf(bw, "intermute_sweeper_deleted_total %d\n", s.sweeper.SweptTotal())
+	}
+}
+
+func writeLabeledGauge(w *bufio.Writer, name, help string, counts []core.LabeledCount, withStatus bool) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	for _, c := range counts {
+		if withStatus {
+			fmt.Fprintf(w, "%s{project=\"%s\",status=\"%s\"} %d\n", name, escapeLabel(c.Project), escapeLabel(c.Status), c.Count)
+		} else {
+			fmt.Fprintf(w, "%s{project=\"%s\"} %d\n", name, escapeLabel(c.Project), c.Count)
+		}
+	}
+}
+
+// escapeLabel escapes a label value per the Prometheus text format.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
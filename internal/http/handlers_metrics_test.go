@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+type fakeBreaker string
+
+func (f fakeBreaker) CircuitBreakerState() string { return string(f) }
+
+type fakeSweeper uint64
+
+func (f fakeSweeper) SweptTotal() uint64 { return uint64(f) }
+
+func TestMetricsExport(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	svc := NewDomainService(st).WithMetricsSources(fakeBreaker("open"), fakeSweeper(3))
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+	env := &testEnv{srv: srv, store: st}
+
+	for _, status := range []string{"pending", "pending", "blocked"} {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "alpha", "title": "t", "status": status})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+	resp := env.post(t, "/api/messages", map[string]any{
+		"project": "alpha", "from": "a", "to": []string{"b", "c"}, "body": "please ack", "ack_required": true,
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/metrics")
+	requireStatus(t, resp, http.StatusOK)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	out := string(body)
+
+	for _, want := range []string{
+		`intermute_tasks{project="alpha",status="pending"} 2`,
+		`intermute_tasks{project="alpha",status="blocked"} 1`,
+		`intermute_unacked_messages{project="alpha"} 2`,
+		`intermute_circuit_breaker_state{state="open"} 1`,
+		`intermute_circuit_breaker_state{state="closed"} 0`,
+		`intermute_sweeper_deleted_total 3`,
+		`# TYPE intermute_active_reservations gauge`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("escapeLabel = %q", got)
+	}
+}
+
+func TestMetricsRequestQueryHistograms(t *testing.T) {
+	env := newTestEnv(t)
+
+	for range 2 {
+		resp := env.get(t, "/api/admin/overview")
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	resp := env.get(t, "/metrics")
+	requireStatus(t, resp, http.StatusOK)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	out := string(body)
+
+	for _, want := range []string{
+		`# TYPE intermute_request_queries histogram`,
+		`intermute_request_queries_count{route="/api/admin/overview"} 2`,
+		`intermute_request_queries_bucket{route="/api/admin/overview",le="+Inf"} 2`,
+		`intermute_request_query_seconds_count{route="/api/admin/overview"} 2`,
+		// The overview and DB size queries run with the request context.
+		`intermute_request_queries_bucket{route="/api/admin/overview",le="1"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+// recordingBus captures broadcast events for assertions.
+type recordingBus struct {
+	mu     sync.Mutex
+	events []map[string]any
+}
+
+func (b *recordingBus) Broadcast(_, _ string, event any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if m, ok := event.(map[string]any); ok {
+		b.events = append(b.events, m)
+	}
+}
+
+func (b *recordingBus) ofType(t core.EventType) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, e := range b.events {
+		if e["type"] == string(t) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func newRecordingEnv(t *testing.T) (*testEnv, *recordingBus) {
+	t.Helper()
+	st := storagetest.NewSQLite(t)
+	bus := &recordingBus{}
+	svc := NewDomainService(st).WithBroadcaster(bus)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+	return &testEnv{srv: srv, store: st}, bus
+}
+
+func TestTaskInheritsStoryPriority(t *testing.T) {
+	env, bus := newRecordingEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/stories", map[string]any{"project": project, "epic_id": "e1", "title": "Checkout", "priority": "high"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": "inherits"})
+	requireStatus(t, resp, http.StatusCreated)
+	inherited := decodeJSON[core.Task](t, resp)
+	if inherited.Priority != core.PriorityHigh {
+		t.Fatalf("expected inherited high priority, got %q", inherited.Priority)
+	}
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": "override", "priority": "low"})
+	requireStatus(t, resp, http.StatusCreated)
+	if task := decodeJSON[core.Task](t, resp); task.Priority != core.PriorityLow {
+		t.Fatalf("expected explicit low priority, got %q", task.Priority)
+	}
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "title": "orphan"})
+	requireStatus(t, resp, http.StatusCreated)
+	if task := decodeJSON[core.Task](t, resp); task.Priority != core.PriorityMedium {
+		t.Fatalf("expected default medium priority, got %q", task.Priority)
+	}
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "title": "bad", "priority": "urgent"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	// Blocking a task under a high-priority story flags the story; an update
+	// that omits priority keeps the stored value.
+	resp = env.put(t, "/api/tasks/"+inherited.ID, map[string]any{
+		"project": project, "story_id": story.ID, "title": "inherits", "status": "blocked", "version": inherited.Version,
+	})
+	requireStatus(t, resp, http.StatusOK)
+	if task := decodeJSON[core.Task](t, resp); task.Priority != core.PriorityHigh {
+		t.Fatalf("expected priority preserved on update, got %q", task.Priority)
+	}
+	atRisk := bus.ofType(core.EventStoryAtRisk)
+	if len(atRisk) != 1 || atRisk[0]["entity_id"] != story.ID {
+		t.Fatalf("expected one story.at_risk event for %s, got %+v", story.ID, atRisk)
+	}
+}
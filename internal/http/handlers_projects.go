@@ -0,0 +1,362 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// KeyProvisioner mints and persists an API key for a project.
+type KeyProvisioner interface {
+	ProvisionKey(project string) (string, error)
+}
+
+const (
+	ProjectTemplateBasic = "basic"
+	ProjectTemplateEmpty = "empty"
+)
+
+// basicTemplateFlags are the project feature flags the basic template turns
+// on: status changes follow the workflow, and a story moves to review once
+// its last task is done.
+var basicTemplateFlags = map[string]bool{
+	core.FlagStrictTransitions: true,
+	core.FlagStoryAutoAdvance:  true,
+}
+
+type createProjectRequest struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Template    string `json:"template,omitempty"`
+	WithDevKey  bool   `json:"with_dev_key,omitempty"`
+	// ShortIDSequences applies from the start, so the template's starter
+	// spec is numbered under it too.
+	ShortIDSequences core.ShortIDSequences `json:"short_id_sequences,omitempty"`
+	SpecStaleDays    int                   `json:"spec_stale_days,omitempty"`
+	// Flags sets project feature flags on top of the template's defaults;
+	// false turns a default off.
+	Flags map[string]bool `json:"flags,omitempty"`
+}
+
+// CreateProjectResponse is returned by POST /api/projects.
+type CreateProjectResponse struct {
+	Project  string                     `json:"project"`
+	Template string                     `json:"template"`
+	Metadata core.Project               `json:"metadata"`
+	Key      string                     `json:"key,omitempty"`
+	Spec     *core.Spec                 `json:"spec,omitempty"`
+	CUJs     []core.CriticalUserJourney `json:"cujs"`
+	// Flags are the project feature flags set at creation.
+	Flags map[string]bool `json:"flags"`
+}
+
+type updateProjectRequest struct {
+	DisplayName   *string `json:"display_name"`
+	Description   *string `json:"description"`
+	SpecStaleDays *int    `json:"spec_stale_days"`
+	// ShortIDSequences, when sent, replaces the whole configuration: entity
+	// types left out go back to the defaults.
+	ShortIDSequences *core.ShortIDSequences `json:"short_id_sequences"`
+}
+
+// WithRequireProjects rejects entity creation under projects that aren't
+// registered through POST /api/projects. The unscoped project "" can't be
+// registered and is always accepted. Creation under an archived project is
+// rejected either way.
+func (s *DomainService) WithRequireProjects(on bool) *DomainService {
+	s.requireProjects = on
+	return s
+}
+
+// requireLiveProject checks that entities may be created under project,
+// writing the error response if not.
+func (s *DomainService) requireLiveProject(w http.ResponseWriter, r *http.Request, project string) bool {
+	registered, archived, err := s.domainStore.CheckProject(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return false
+	}
+	if archived {
+		writeJSONError(w, http.StatusConflict, "project "+project+" is archived", "project_archived")
+		return false
+	}
+	if !registered && s.requireProjects && project != "" {
+		writeJSONError(w, http.StatusNotFound, "project "+project+" does not exist", "project_not_found")
+		return false
+	}
+	return true
+}
+
+func (s *DomainService) handleProjects(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get:  s.listProjects,
+		post: s.createProject,
+	})
+}
+
+// listProjects returns registered projects; API-key callers see only their
+// own. Archived projects are left out unless ?include_archived=true.
+func (s *DomainService) listProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.domainStore.ListProjects(r.Context(), r.URL.Query().Get("include_archived") == "true")
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if info, _ := auth.FromContext(r.Context()); info.Mode == auth.ModeAPIKey {
+		own := []core.Project{}
+		for _, p := range projects {
+			if p.Name == info.Project {
+				own = append(own, p)
+			}
+		}
+		projects = own
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"projects": projects})
+}
+
+func (s *DomainService) getProject(w http.ResponseWriter, r *http.Request, name string) {
+	p, err := s.domainStore.GetProject(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// updateProject changes a project's display name, description, spec
+// staleness window or short ID sequences; fields left out of the body keep
+// their value.
+func (s *DomainService) updateProject(w http.ResponseWriter, r *http.Request, name string) {
+	limitBody(w, r)
+	var req updateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	p, err := s.domainStore.GetProject(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	if req.DisplayName != nil {
+		p.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Description != nil {
+		p.Description = *req.Description
+	}
+	if req.SpecStaleDays != nil {
+		if *req.SpecStaleDays < 0 {
+			writeJSONError(w, http.StatusBadRequest, "spec_stale_days must not be negative", "invalid_request")
+			return
+		}
+		p.SpecStaleDays = *req.SpecStaleDays
+	}
+	if req.ShortIDSequences != nil {
+		seqs, err := req.ShortIDSequences.Normalize()
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_short_id_sequence")
+			return
+		}
+		p.ShortIDSequences = seqs
+	}
+	updated, err := s.domainStore.UpdateProject(r.Context(), p)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(updated)
+}
+
+// deleteProject unregisters an empty project. One with agents, messages or
+// entities gets 409 project_not_empty: archive it instead.
+func (s *DomainService) deleteProject(w http.ResponseWriter, r *http.Request, name string) {
+	if err := s.domainStore.DeleteProject(r.Context(), name); err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotFound):
+			writeNotFound(w)
+		case errors.Is(err, core.ErrProjectNotEmpty):
+			writeJSONError(w, http.StatusConflict, "project still has agents, messages or entities", "project_not_empty")
+		default:
+			writeInternalError(w)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// createProject registers a project and bootstraps it in one call: an
+// optional API key, default settings and, from the chosen template, a
+// starter spec with a CUJ skeleton and default feature flags.
+func (s *DomainService) createProject(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req createProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeJSONError(w, http.StatusBadRequest, "name required", "invalid_request")
+		return
+	}
+	if req.Template == "" {
+		req.Template = ProjectTemplateBasic
+	}
+	if req.Template != ProjectTemplateBasic && req.Template != ProjectTemplateEmpty {
+		writeJSONError(w, http.StatusBadRequest, "unknown template: "+req.Template, "unknown_template")
+		return
+	}
+	sequences, err := req.ShortIDSequences.Normalize()
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_short_id_sequence")
+		return
+	}
+	if req.SpecStaleDays < 0 {
+		writeJSONError(w, http.StatusBadRequest, "spec_stale_days must not be negative", "invalid_request")
+		return
+	}
+	flags := map[string]bool{}
+	if req.Template == ProjectTemplateBasic {
+		maps.Copy(flags, basicTemplateFlags)
+	}
+	for name, on := range req.Flags {
+		if !validFlagName(name) {
+			writeJSONError(w, http.StatusBadRequest, "invalid flag name: "+name, "invalid_flag")
+			return
+		}
+		flags[name] = on
+	}
+
+	// API-key callers are scoped to their own project and may not mint keys;
+	// provisioning new projects' keys is a localhost (operator) action.
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && (req.Name != info.Project || req.WithDevKey) {
+		writeProjectMismatch(w)
+		return
+	}
+	if req.WithDevKey && s.keys == nil {
+		writeJSONError(w, http.StatusNotImplemented, "key provisioning not configured", "keys_unavailable")
+		return
+	}
+
+	existing, err := s.domainStore.ListSpecs(r.Context(), req.Name, "")
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if len(existing) > 0 {
+		writeJSONError(w, http.StatusConflict, "project already has specs", "project_exists")
+		return
+	}
+	registered, err := s.domainStore.CreateProject(r.Context(), core.Project{
+		Name:             req.Name,
+		DisplayName:      strings.TrimSpace(req.DisplayName),
+		Description:      req.Description,
+		SpecStaleDays:    req.SpecStaleDays,
+		ShortIDSequences: sequences,
+	})
+	if err != nil {
+		if errors.Is(err, core.ErrAlreadyExists) {
+			writeJSONError(w, http.StatusConflict, "project already exists", "project_exists")
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+
+	resp := CreateProjectResponse{
+		Project:  req.Name,
+		Template: req.Template,
+		Metadata: registered,
+		CUJs:     []core.CriticalUserJourney{},
+		Flags:    flags,
+	}
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		if _, err := s.domainStore.SetFeatureFlag(r.Context(), core.FeatureFlag{
+			Project: req.Name, Name: name, Enabled: flags[name], Description: "set at project creation",
+		}); err != nil {
+			writeInternalError(w)
+			return
+		}
+	}
+	if req.Template == ProjectTemplateBasic {
+		spec, err := s.domainStore.CreateSpec(r.Context(), starterSpec(req.Name))
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		s.broadcastDomainEvent(r.Context(), req.Name, core.EventSpecCreated, spec.ID, spec)
+		resp.Spec = &spec
+
+		cuj, err := s.domainStore.CreateCUJ(r.Context(), starterCUJ(req.Name, spec.ID))
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		s.broadcastDomainEvent(r.Context(), req.Name, core.EventCUJCreated, cuj.ID, cuj)
+		resp.CUJs = append(resp.CUJs, cuj)
+	}
+	// Mint the key last so a failed bootstrap doesn't leave a dangling key.
+	if req.WithDevKey {
+		key, err := s.keys.ProvisionKey(req.Name)
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		resp.Key = key
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func starterSpec(project string) core.Spec {
+	return core.Spec{
+		Project: project,
+		Title:   project,
+		Vision:  "Describe the end state this project is working toward.",
+		Users:   "Who uses this, and what they are trying to get done.",
+		Problem: "What is painful or impossible today.",
+		Status:  core.SpecStatusDraft,
+	}
+}
+
+func starterCUJ(project, specID string) core.CriticalUserJourney {
+	return core.CriticalUserJourney{
+		Project:  project,
+		SpecID:   specID,
+		Title:    "First successful use",
+		Persona:  "New user",
+		Priority: core.CUJPriorityHigh,
+		Steps: []core.CUJStep{
+			{Order: 1, Action: "Discover the entry point", Expected: "User knows where to start"},
+			{Order: 2, Action: "Complete the core action", Expected: "The primary outcome is produced"},
+			{Order: 3, Action: "Confirm the result", Expected: "User can verify it worked"},
+		},
+		SuccessCriteria: []string{"A new user reaches the primary outcome without help"},
+		Status:          core.CUJStatusDraft,
+	}
+}
@@ -0,0 +1,209 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+type fakeKeyProvisioner struct{ calls []string }
+
+func (f *fakeKeyProvisioner) ProvisionKey(project string) (string, error) {
+	f.calls = append(f.calls, project)
+	return fmt.Sprintf("key-%s", project), nil
+}
+
+func TestCreateProjectBasicTemplate(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/projects", map[string]any{"name": "alpha"})
+	requireStatus(t, resp, http.StatusCreated)
+	out := decodeJSON[CreateProjectResponse](t, resp)
+	if out.Spec == nil || out.Spec.Project != "alpha" {
+		t.Fatalf("expected starter spec for alpha, got %+v", out.Spec)
+	}
+	if len(out.CUJs) != 1 || out.CUJs[0].SpecID != out.Spec.ID {
+		t.Fatalf("expected one CUJ linked to starter spec, got %+v", out.CUJs)
+	}
+	if out.Key != "" {
+		t.Fatalf("expected no key without with_dev_key, got %q", out.Key)
+	}
+	flags, err := env.store.EffectiveFeatureFlags(context.Background(), "alpha")
+	if err != nil || !flags[core.FlagStrictTransitions] || !flags[core.FlagStoryAutoAdvance] {
+		t.Fatalf("basic template flags = %v, %v; want strict transitions and story auto-advance", flags, err)
+	}
+
+	// Bootstrapping again is a conflict.
+	resp = env.post(t, "/api/projects", map[string]any{"name": "alpha"})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+}
+
+func TestCreateProjectSettings(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/projects", map[string]any{
+		"name": "beta", "template": "empty", "spec_stale_days": 14,
+		"flags": map[string]bool{core.FlagDedupInsights: true},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	out := decodeJSON[CreateProjectResponse](t, resp)
+	if out.Metadata.SpecStaleDays != 14 {
+		t.Fatalf("spec_stale_days = %d, want 14", out.Metadata.SpecStaleDays)
+	}
+	if len(out.Flags) != 1 || !out.Flags[core.FlagDedupInsights] {
+		t.Fatalf("flags = %v, want only dedup_insights: the empty template sets none", out.Flags)
+	}
+
+	resp = env.post(t, "/api/projects", map[string]any{
+		"name": "gamma", "flags": map[string]bool{core.FlagStrictTransitions: false},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	flags, err := env.store.EffectiveFeatureFlags(context.Background(), "gamma")
+	if err != nil || flags[core.FlagStrictTransitions] || !flags[core.FlagStoryAutoAdvance] {
+		t.Fatalf("flags = %v, %v; want strict transitions turned off, the other default kept", flags, err)
+	}
+}
+
+func TestCreateProjectValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/projects", map[string]any{"name": ""})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/projects", map[string]any{"name": "x", "template": "nope"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/projects", map[string]any{"name": "x", "flags": map[string]bool{"Not A Flag": true}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	// No provisioner wired in.
+	resp = env.post(t, "/api/projects", map[string]any{"name": "x", "with_dev_key": true})
+	requireStatus(t, resp, http.StatusNotImplemented)
+	resp.Body.Close()
+}
+
+func TestCreateProjectWithDevKey(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	keys := &fakeKeyProvisioner{}
+	svc := NewDomainService(st).WithKeyProvisioner(keys)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+	env := &testEnv{srv: srv, store: st}
+
+	resp := env.post(t, "/api/projects", map[string]any{"name": "beta", "template": "empty", "with_dev_key": true})
+	requireStatus(t, resp, http.StatusCreated)
+	out := decodeJSON[CreateProjectResponse](t, resp)
+	if out.Key != "key-beta" || len(keys.calls) != 1 {
+		t.Fatalf("expected provisioned key for beta, got %q (calls %v)", out.Key, keys.calls)
+	}
+	if out.Spec != nil {
+		t.Fatalf("empty template should not create a spec, got %+v", out.Spec)
+	}
+}
+
+func TestCreateProjectAPIKeyCannotMintKeys(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	keys := &fakeKeyProvisioner{}
+	ring := auth.NewKeyring(false, map[string]string{"secret": "gamma"})
+	svc := NewDomainService(st).WithKeyProvisioner(keys)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, auth.Middleware(ring)))
+	t.Cleanup(srv.Close)
+
+	for _, body := range []string{
+		`{"name":"other"}`,
+		`{"name":"gamma","with_dev_key":true}`,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/projects", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireStatus(t, resp, http.StatusForbidden)
+		resp.Body.Close()
+	}
+	if len(keys.calls) != 0 {
+		t.Fatalf("expected no keys minted, got %v", keys.calls)
+	}
+}
+
+func TestProjectRegistryLifecycle(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	srv := httptest.NewServer(NewDomainRouter(NewDomainService(st).WithRequireProjects(true), nil, nil))
+	t.Cleanup(srv.Close)
+	env := &testEnv{srv: srv, store: st}
+
+	// Entities need a registered project.
+	resp := env.post(t, "/api/specs", map[string]any{"project": "alpha", "title": "S"})
+	requireStatus(t, resp, http.StatusNotFound)
+	if body := decodeJSON[errorResponse](t, resp); body.Code != "project_not_found" {
+		t.Fatalf("unregistered project code = %q", body.Code)
+	}
+
+	// The unscoped project can't be registered, so it never needs to be.
+	resp = env.post(t, "/api/specs", map[string]any{"title": "S"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/projects", map[string]any{"name": "alpha", "display_name": "Alpha", "template": "empty"})
+	requireStatus(t, resp, http.StatusCreated)
+	if out := decodeJSON[CreateProjectResponse](t, resp); out.Metadata.DisplayName != "Alpha" || out.Metadata.CreatedAt.IsZero() {
+		t.Fatalf("created project = %+v", out.Metadata)
+	}
+	resp = env.post(t, "/api/specs", map[string]any{"project": "alpha", "title": "S"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.patch(t, "/api/projects/alpha", map[string]any{"description": "the first"})
+	requireStatus(t, resp, http.StatusOK)
+	if p := decodeJSON[core.Project](t, resp); p.DisplayName != "Alpha" || p.Description != "the first" {
+		t.Fatalf("patched project = %+v", p)
+	}
+
+	resp = env.delete(t, "/api/projects/alpha")
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	// Archived projects take no new entities and drop out of the list.
+	resp = env.post(t, "/api/admin/projects/alpha/archive", map[string]any{})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.post(t, "/api/specs", map[string]any{"project": "alpha", "title": "T"})
+	requireStatus(t, resp, http.StatusConflict)
+	if body := decodeJSON[errorResponse](t, resp); body.Code != "project_archived" {
+		t.Fatalf("archived project code = %q", body.Code)
+	}
+	resp = env.get(t, "/api/projects")
+	requireStatus(t, resp, http.StatusOK)
+	if list := decodeJSON[map[string][]core.Project](t, resp); len(list["projects"]) != 0 {
+		t.Fatalf("default list = %+v, want archived alpha left out", list)
+	}
+	resp = env.get(t, "/api/projects/alpha")
+	requireStatus(t, resp, http.StatusOK)
+	if p := decodeJSON[core.Project](t, resp); !p.Archived || p.ArchivedAt == nil {
+		t.Fatalf("archived project = %+v", p)
+	}
+
+	// An empty project can be deleted.
+	resp = env.post(t, "/api/projects", map[string]any{"name": "beta", "template": "empty"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.delete(t, "/api/projects/beta")
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = env.get(t, "/api/projects/beta")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -0,0 +1,505 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Scheduled report handlers
+
+// defaultReportHour is the UTC hour reports run at when none is given.
+const defaultReportHour = 9
+
+// reportScheduleRequest defaults Enabled to true, Hour to defaultReportHour
+// and the cadence by kind (daily stand-ups, weekly spec progress on Monday)
+// when omitted.
+type reportScheduleRequest struct {
+	core.ReportSchedule
+	Hour    *int          `json:"hour"`
+	Weekday *time.Weekday `json:"weekday"`
+	Enabled *bool         `json:"enabled"`
+}
+
+func (req reportScheduleRequest) schedule() core.ReportSchedule {
+	sched := req.ReportSchedule
+	sched.Enabled = req.Enabled == nil || *req.Enabled
+	sched.Hour = defaultReportHour
+	if req.Hour != nil {
+		sched.Hour = *req.Hour
+	}
+	sched.Weekday = time.Monday
+	if req.Weekday != nil {
+		sched.Weekday = *req.Weekday
+	}
+	if sched.Cadence == "" {
+		sched.Cadence = core.ReportCadenceDaily
+		if sched.Kind == core.ReportKindSpecProgress {
+			sched.Cadence = core.ReportCadenceWeekly
+		}
+	}
+	sched.Recipients = cleanMembers(sched.Recipients)
+	return sched
+}
+
+func validReportSchedule(sched core.ReportSchedule) bool {
+	switch sched.Kind {
+	case core.ReportKindStandup, core.ReportKindSpecProgress:
+	default:
+		return false
+	}
+	switch sched.Cadence {
+	case core.ReportCadenceDaily, core.ReportCadenceWeekly:
+	default:
+		return false
+	}
+	return sched.Hour >= 0 && sched.Hour <= 23 &&
+		sched.Weekday >= time.Sunday && sched.Weekday <= time.Saturday &&
+		len(sched.Recipients) > 0
+}
+
+// reportPreview is a rendered report that has not been delivered.
+type reportPreview struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// reportRunResponse is returned when a report is delivered on demand.
+type reportRunResponse struct {
+	MessageID  string   `json:"message_id"`
+	Recipients []string `json:"recipients"`
+}
+
+func (s *DomainService) handleReports(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get:  s.listReportSchedules,
+		post: s.createReportSchedule,
+	})
+}
+
+func (s *DomainService) handleReportByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/reports/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" || len(parts) > 2 {
+		writeNotFound(w)
+		return
+	}
+	id := parts[0]
+
+	// Handle /api/reports/{id}/preview and /api/reports/{id}/run
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "preview":
+			if r.Method != http.MethodGet {
+				writeMethodNotAllowed(w)
+				return
+			}
+			s.previewReport(w, r, id)
+		case "run":
+			if r.Method != http.MethodPost {
+				writeMethodNotAllowed(w)
+				return
+			}
+			s.runReport(w, r, id)
+		default:
+			writeNotFound(w)
+		}
+		return
+	}
+
+	dispatchByMethod(w, r, methodHandlers{
+		get:    func(w http.ResponseWriter, r *http.Request) { s.getReportSchedule(w, r, id) },
+		put:    func(w http.ResponseWriter, r *http.Request) { s.updateReportSchedule(w, r, id) },
+		delete: func(w http.ResponseWriter, r *http.Request) { s.deleteReportSchedule(w, r, id) },
+	})
+}
+
+func (s *DomainService) createReportSchedule(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req reportScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	sched := req.schedule()
+	if !validReportSchedule(sched) {
+		writeJSONError(w, http.StatusBadRequest, "invalid report schedule", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && sched.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.checkReportRecipients(w, r.Context(), sched) {
+		return
+	}
+	created, err := s.domainStore.CreateReportSchedule(r.Context(), sched)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (s *DomainService) getReportSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	sched, err := s.domainStore.GetReportSchedule(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sched)
+}
+
+func (s *DomainService) listReportSchedules(w http.ResponseWriter, r *http.Request) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	scheds, err := s.domainStore.ListReportSchedules(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if scheds == nil {
+		scheds = []core.ReportSchedule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scheds)
+}
+
+func (s *DomainService) updateReportSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var req reportScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	sched := req.schedule()
+	sched.ID = id
+	if !validReportSchedule(sched) {
+		writeJSONError(w, http.StatusBadRequest, "invalid report schedule", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey && sched.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.checkReportRecipients(w, r.Context(), sched) {
+		return
+	}
+	updated, err := s.domainStore.UpdateReportSchedule(r.Context(), sched)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (s *DomainService) deleteReportSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if err := s.domainStore.DeleteReportSchedule(r.Context(), project, id); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// previewReport renders a schedule's report as it would be delivered now,
+// without sending it or recording a run.
+func (s *DomainService) previewReport(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	sched, err := s.domainStore.GetReportSchedule(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	subject, body, err := s.renderReport(r.Context(), sched, clock.Now().UTC())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reportPreview{Subject: subject, Body: body})
+}
+
+// runReport delivers a schedule's report immediately. The run is recorded
+// (so the next stand-up covers movements from now) but the next scheduled
+// delivery is unchanged.
+func (s *DomainService) runReport(w http.ResponseWriter, r *http.Request, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	sched, err := s.domainStore.GetReportSchedule(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	now := clock.Now().UTC()
+	msg, err := s.deliverReport(r.Context(), sched, now)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), "unknown_group")
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	if err := s.domainStore.MarkReportRun(r.Context(), sched.Project, sched.ID, now, sched.NextRunAt); err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reportRunResponse{MessageID: msg.ID, Recipients: msg.To})
+}
+
+// checkReportRecipients rejects schedules addressed to groups that don't
+// exist. It writes the error response itself and returns false on failure.
+func (s *DomainService) checkReportRecipients(w http.ResponseWriter, ctx context.Context, sched core.ReportSchedule) bool {
+	if _, err := s.resolveRecipients(ctx, sched.Project, sched.Recipients); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), "unknown_group")
+			return false
+		}
+		writeInternalError(w)
+		return false
+	}
+	return true
+}
+
+// resolveRecipients expands "@group" entries to the groups' current members
+// and dedupes the result. Unknown groups return an error wrapping
+// core.ErrNotFound.
+func (s *Service) resolveRecipients(ctx context.Context, project string, addrs []string) ([]string, error) {
+	var out []string
+	for _, addr := range addrs {
+		name, isGroup := strings.CutPrefix(addr, groupAddressPrefix)
+		if !isGroup {
+			out = append(out, addr)
+			continue
+		}
+		group, err := s.store.GetContactGroup(ctx, project, name)
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				return nil, fmt.Errorf("unknown group %s: %w", name, err)
+			}
+			return nil, err
+		}
+		out = append(out, group.Members...)
+	}
+	return dedupeAgents(out), nil
+}
+
+// RunDueReports delivers every enabled report whose next run is at or
+// before now and advances its schedule. A report that fails to render or
+// deliver is skipped until its next slot rather than retried every tick.
+func (s *DomainService) RunDueReports(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.domainStore.DueReportSchedules(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, sched := range due {
+		if _, err := s.deliverReport(ctx, sched, now); err != nil {
+			log.Printf("WARN: report %s (%s/%s): %v", sched.ID, sched.Project, sched.Kind, err)
+		} else {
+			delivered++
+		}
+		if err := s.domainStore.MarkReportRun(ctx, sched.Project, sched.ID, now, sched.NextRunAfter(now)); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// deliverReport renders sched's report and sends it from the system sender
+// to the schedule's resolved recipients.
+func (s *DomainService) deliverReport(ctx context.Context, sched core.ReportSchedule, now time.Time) (core.Message, error) {
+	to, err := s.resolveRecipients(ctx, sched.Project, sched.Recipients)
+	if err != nil {
+		return core.Message{}, err
+	}
+	if len(to) == 0 {
+		return core.Message{}, fmt.Errorf("no recipients")
+	}
+	subject, body, err := s.renderReport(ctx, sched, now)
+	if err != nil {
+		return core.Message{}, err
+	}
+	return s.sendSystemMessage(ctx, sched.Project, to, subject, body)
+}
+
+func (s *DomainService) renderReport(ctx context.Context, sched core.ReportSchedule, now time.Time) (string, string, error) {
+	switch sched.Kind {
+	case core.ReportKindStandup:
+		since := now.Add(-24 * time.Hour)
+		if sched.Cadence == core.ReportCadenceWeekly {
+			since = now.Add(-7 * 24 * time.Hour)
+		}
+		if sched.LastRunAt != nil {
+			since = *sched.LastRunAt
+		}
+		return s.renderStandup(ctx, sched.Project, since, now)
+	case core.ReportKindSpecProgress:
+		return s.renderSpecProgress(ctx, sched.Project, now)
+	}
+	return "", "", fmt.Errorf("unknown report kind %q", sched.Kind)
+}
+
+// standupOrder is the order task statuses are listed in a stand-up.
+var standupOrder = []core.TaskStatus{
+	core.TaskStatusDone,
+	core.TaskStatusRunning,
+	core.TaskStatusBlocked,
+	core.TaskStatusPending,
+}
+
+// renderStandup lists tasks created or updated in [since, now), grouped by
+// their current status, followed by project-wide status totals.
+func (s *DomainService) renderStandup(ctx context.Context, project string, since, now time.Time) (string, string, error) {
+	tasks, err := s.domainStore.ListTasks(ctx, project, "", "")
+	if err != nil {
+		return "", "", err
+	}
+	moved := map[core.TaskStatus][]core.Task{}
+	totals := map[core.TaskStatus]int{}
+	movedCount := 0
+	for _, t := range tasks {
+		totals[t.Status]++
+		if !t.UpdatedAt.Before(since) && t.UpdatedAt.Before(now) {
+			moved[t.Status] = append(moved[t.Status], t)
+			movedCount++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task movements since %s:\n", since.UTC().Format(time.RFC3339))
+	if movedCount == 0 {
+		b.WriteString("\nNo task movements.\n")
+	}
+	for _, status := range standupOrder {
+		group := moved[status]
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s (%d)\n", status, len(group))
+		for _, t := range group {
+			line := "- " + t.Title
+			if t.Agent != "" {
+				line += " [" + t.Agent + "]"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	fmt.Fprintf(&b, "\nTotals: %d pending, %d running, %d blocked, %d done\n",
+		totals[core.TaskStatusPending], totals[core.TaskStatusRunning],
+		totals[core.TaskStatusBlocked], totals[core.TaskStatusDone])
+
+	subject := fmt.Sprintf("Stand-up: %s %s", project, now.UTC().Format(time.DateOnly))
+	return subject, strings.TrimRight(b.String(), "\n"), nil
+}
+
+// renderSpecProgress summarises story and task completion for every spec
+// that isn't archived.
+func (s *DomainService) renderSpecProgress(ctx context.Context, project string, now time.Time) (string, string, error) {
+	specs, err := s.domainStore.ListSpecs(ctx, project, "")
+	if err != nil {
+		return "", "", err
+	}
+	tasks, err := s.domainStore.ListTasks(ctx, project, "", "")
+	if err != nil {
+		return "", "", err
+	}
+	tasksByStory := map[string][]core.Task{}
+	for _, t := range tasks {
+		if t.StoryID != "" {
+			tasksByStory[t.StoryID] = append(tasksByStory[t.StoryID], t)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Spec progress:\n\n")
+	listed := 0
+	for _, spec := range specs {
+		if spec.Status == core.SpecStatusArchived {
+			continue
+		}
+		listed++
+		epics, err := s.domainStore.ListEpics(ctx, project, spec.ID)
+		if err != nil {
+			return "", "", err
+		}
+		var stories, storiesDone, specTasks, tasksDone int
+		for _, epic := range epics {
+			ss, err := s.domainStore.ListStories(ctx, project, epic.ID)
+			if err != nil {
+				return "", "", err
+			}
+			for _, story := range ss {
+				stories++
+				if story.Status == core.StoryStatusDone {
+					storiesDone++
+				}
+				for _, t := range tasksByStory[story.ID] {
+					specTasks++
+					if t.Status == core.TaskStatusDone {
+						tasksDone++
+					}
+				}
+			}
+		}
+		fmt.Fprintf(&b, "- %s (%s): %d/%d stories done (%d%%), %d/%d tasks done\n",
+			spec.Title, spec.Status, storiesDone, stories, percent(storiesDone, stories), tasksDone, specTasks)
+	}
+	if listed == 0 {
+		b.WriteString("No active specs.\n")
+	}
+
+	subject := fmt.Sprintf("Spec progress: %s week of %s", project, now.UTC().Format(time.DateOnly))
+	return subject, strings.TrimRight(b.String(), "\n"), nil
+}
+
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
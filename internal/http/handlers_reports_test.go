@@ -0,0 +1,155 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestReportSchedulesCRUD(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	for name, body := range map[string]map[string]any{
+		"no recipients": {"project": project, "kind": "standup"},
+		"bad kind":      {"project": project, "kind": "haiku", "recipients": []string{"pm"}},
+		"bad hour":      {"project": project, "kind": "standup", "hour": 24, "recipients": []string{"pm"}},
+	} {
+		resp := env.post(t, "/api/reports", body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+
+	resp := env.post(t, "/api/reports", map[string]any{"project": project, "kind": "standup", "recipients": []string{"@nobody"}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/reports", map[string]any{"project": project, "kind": "spec_progress", "recipients": []string{"pm"}})
+	requireStatus(t, resp, http.StatusCreated)
+	sched := decodeJSON[core.ReportSchedule](t, resp)
+	if sched.Cadence != core.ReportCadenceWeekly || sched.Weekday != time.Monday || sched.Hour != defaultReportHour || !sched.Enabled {
+		t.Fatalf("unexpected defaults: %+v", sched)
+	}
+	if sched.NextRunAt.Weekday() != time.Monday || sched.NextRunAt.Hour() != defaultReportHour || !sched.NextRunAt.After(time.Now()) {
+		t.Errorf("unexpected next run: %v", sched.NextRunAt)
+	}
+
+	resp = env.put(t, "/api/reports/"+sched.ID, map[string]any{
+		"project": project, "kind": "standup", "cadence": "daily", "hour": 17, "recipients": []string{"pm", "lead"},
+	})
+	requireStatus(t, resp, http.StatusOK)
+	if updated := decodeJSON[core.ReportSchedule](t, resp); updated.Kind != core.ReportKindStandup || updated.NextRunAt.Hour() != 17 {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+
+	resp = env.get(t, "/api/reports?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if scheds := decodeJSON[[]core.ReportSchedule](t, resp); len(scheds) != 1 || len(scheds[0].Recipients) != 2 {
+		t.Fatalf("unexpected list: %+v", scheds)
+	}
+
+	resp = env.delete(t, "/api/reports/"+sched.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/reports/"+sched.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestReportDelivery(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/groups", map[string]any{"project": project, "name": "leads", "members": []string{"lead", "pm"}})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/specs", map[string]any{"project": project, "title": "Checkout"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+	resp = env.post(t, "/api/epics", map[string]any{"project": project, "spec_id": spec.ID, "title": "Payments"})
+	requireStatus(t, resp, http.StatusCreated)
+	epic := decodeJSON[core.Epic](t, resp)
+	resp = env.post(t, "/api/stories", map[string]any{"project": project, "epic_id": epic.ID, "title": "Card form"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+	for _, title := range []string{"Validate card", "Store token"} {
+		resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": title, "agent": "dev"})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+
+	resp = env.post(t, "/api/reports", map[string]any{"project": project, "kind": "standup", "recipients": []string{"@leads", "pm"}})
+	requireStatus(t, resp, http.StatusCreated)
+	standup := decodeJSON[core.ReportSchedule](t, resp)
+
+	resp = env.get(t, "/api/reports/"+standup.ID+"/preview?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	preview := decodeJSON[reportPreview](t, resp)
+	if !strings.HasPrefix(preview.Subject, "Stand-up: proj") || !strings.Contains(preview.Body, "- Validate card [dev]") ||
+		!strings.Contains(preview.Body, "Totals: 2 pending, 0 running, 0 blocked, 0 done") {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+
+	resp = env.post(t, "/api/reports/"+standup.ID+"/run?project="+project, map[string]any{})
+	requireStatus(t, resp, http.StatusOK)
+	run := decodeJSON[reportRunResponse](t, resp)
+	if len(run.Recipients) != 2 {
+		t.Fatalf("expected group and direct recipient deduped to 2, got %v", run.Recipients)
+	}
+	resp = env.get(t, "/api/inbox/lead?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	inbox := decodeJSON[inboxResponse](t, resp).Messages
+	if len(inbox) != 1 || inbox[0].From != systemSender || inbox[0].Body != preview.Body {
+		t.Fatalf("unexpected delivered report: %+v", inbox)
+	}
+
+	// The next stand-up only covers movements since the manual run.
+	resp = env.get(t, "/api/reports/"+standup.ID+"/preview?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if p := decodeJSON[reportPreview](t, resp); !strings.Contains(p.Body, "No task movements.") {
+		t.Errorf("expected no movements after run, got %q", p.Body)
+	}
+
+	resp = env.post(t, "/api/reports", map[string]any{"project": project, "kind": "spec_progress", "recipients": []string{"pm"}})
+	requireStatus(t, resp, http.StatusCreated)
+	progress := decodeJSON[core.ReportSchedule](t, resp)
+
+	// Due reports are delivered by the scheduler and their next run advanced.
+	svc := NewDomainService(env.store)
+	delivered, err := svc.RunDueReports(context.Background(), progress.NextRunAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("run due reports: %v", err)
+	}
+	if delivered != 2 {
+		t.Fatalf("expected both schedules delivered, got %d", delivered)
+	}
+	resp = env.get(t, "/api/inbox/pm?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	var found bool
+	for _, m := range decodeJSON[inboxResponse](t, resp).Messages {
+		if strings.HasPrefix(m.Subject, "Spec progress:") {
+			found = true
+			if !strings.Contains(m.Body, "- Checkout (draft): 0/1 stories done (0%), 0/2 tasks done") {
+				t.Errorf("unexpected spec progress body: %q", m.Body)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("spec progress report not delivered")
+	}
+
+	resp = env.get(t, "/api/reports/"+progress.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	after := decodeJSON[core.ReportSchedule](t, resp)
+	if after.LastRunAt == nil || !after.NextRunAt.After(progress.NextRunAt) {
+		t.Errorf("expected schedule to advance, got %+v", after)
+	}
+}
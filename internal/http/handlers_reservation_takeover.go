@@ -0,0 +1,196 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Bounds on how long a holder gets to answer a takeover request.
+const (
+	defaultTakeoverWindow = 2 * time.Minute
+	maxTakeoverWindow     = time.Hour
+)
+
+type takeoverRequest struct {
+	AgentID        string `json:"agent_id"`
+	Reason         string `json:"reason"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+type takeoverResponse struct {
+	AgentID string `json:"agent_id"`
+	Accept  bool   `json:"accept"`
+}
+
+// requestTakeover serves POST /api/reservations/{id}/request-takeover: a
+// blocked agent asks the holder of a reservation that looks stuck to hand
+// it over. The holder is messaged and has the window to answer; if they
+// don't, the sweeper transfers the reservation.
+func (s *Service) requestTakeover(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var req takeoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if req.AgentID == "" {
+		req.AgentID = info.AgentID
+	}
+	if req.AgentID == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent_id is required", "invalid_request")
+		return
+	}
+	if info.AgentID != "" && req.AgentID != info.AgentID {
+		writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
+		return
+	}
+	reservation, err := s.store.GetReservation(r.Context(), id)
+	if errors.Is(err, core.ErrNotFound) {
+		writeNotFound(w)
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if info.Mode == auth.ModeAPIKey && reservation.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+
+	window := defaultTakeoverWindow
+	if req.TimeoutSeconds > 0 {
+		window = min(time.Duration(req.TimeoutSeconds)*time.Second, maxTakeoverWindow)
+	}
+	takeover, err := s.store.RequestReservationTakeover(r.Context(), core.ReservationTakeover{
+		ReservationID: id,
+		Requester:     req.AgentID,
+		Reason:        req.Reason,
+		Deadline:      clock.Now().UTC().Add(window),
+	})
+	if errors.Is(err, core.ErrTakeoverPending) {
+		writeJSONError(w, http.StatusConflict, "a takeover of this reservation is already pending", "takeover_pending")
+		return
+	}
+	if errors.Is(err, core.ErrNotFound) {
+		writeJSONError(w, http.StatusConflict, "reservation is not active or already held by the requester", "reservation_inactive")
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	subject := fmt.Sprintf("Takeover requested: %s", reservation.PathPattern)
+	body := fmt.Sprintf("%s asks to take over your reservation of %s (%s).", takeover.Requester, reservation.PathPattern, reservation.ID)
+	if takeover.Reason != "" {
+		body += "\n\nReason: " + takeover.Reason
+	}
+	body += fmt.Sprintf("\n\nAnswer with POST /api/reservations/%s/takeover {\"accept\": true|false} before %s, or it will be transferred.",
+		reservation.ID, takeover.Deadline.Format(time.RFC3339))
+	if _, err := s.sendSystemMessage(r.Context(), takeover.Project, []string{takeover.Holder}, subject, body); err != nil {
+		log.Printf("WARN: takeover of reservation %s: notify %s: %v", id, takeover.Holder, err)
+	}
+	s.broadcastTakeover(r, core.EventReservationTakeoverRequested, takeover)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(takeover)
+}
+
+// getTakeover serves GET /api/reservations/{id}/takeover, the latest
+// takeover request for the reservation.
+func (s *Service) getTakeover(w http.ResponseWriter, r *http.Request, id string) {
+	takeover, err := s.store.LatestReservationTakeover(r.Context(), id)
+	if errors.Is(err, core.ErrNotFound) {
+		writeNotFound(w)
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if info, _ := auth.FromContext(r.Context()); info.Mode == auth.ModeAPIKey && takeover.Project != info.Project {
+		writeNotFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(takeover)
+}
+
+// respondTakeover serves POST /api/reservations/{id}/takeover, the
+// holder's answer to a pending takeover: accept hands the reservation over
+// now, decline keeps it. The answering agent is the caller's, or agent_id
+// for callers without one.
+func (s *Service) respondTakeover(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var req takeoverResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	reservation, err := s.store.GetReservation(r.Context(), id)
+	if errors.Is(err, core.ErrNotFound) {
+		writeNotFound(w)
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if req.AgentID == "" {
+		req.AgentID = info.AgentID
+	}
+	if req.AgentID == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent_id is required", "invalid_request")
+		return
+	}
+	if info.AgentID != "" && req.AgentID != info.AgentID {
+		writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
+		return
+	}
+	if reservation.AgentID != req.AgentID && !s.isTeamMember(r.Context(), reservation.Project, reservation.AgentID, req.AgentID) {
+		writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
+		return
+	}
+	takeover, err := s.store.RespondReservationTakeover(r.Context(), id, req.Accept)
+	if errors.Is(err, core.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, "no pending takeover for this reservation", "not_found")
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	event := core.EventReservationTakeoverDeclined
+	if takeover.Status == core.TakeoverTransferred {
+		event = core.EventReservationTransferred
+	}
+	s.broadcastTakeover(r, event, takeover)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(takeover)
+}
+
+func (s *Service) broadcastTakeover(r *http.Request, evType core.EventType, t core.ReservationTakeover) {
+	if s.bus == nil {
+		return
+	}
+	event := map[string]any{
+		"type":           string(evType),
+		"project":        t.Project,
+		"reservation_id": t.ReservationID,
+		"takeover":       t,
+	}
+	addTrace(event, core.TraceFromContext(r.Context()))
+	s.bus.Broadcast(t.Project, "", event)
+}
@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestReservationTakeover(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	svc := NewService(st)
+	ring := auth.NewKeyring(true, map[string]string{"secret": "proj-a"})
+	h := NewRouter(svc, nil, auth.Middleware(ring))
+	do := func(method, path, agent string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.RemoteAddr = "203.0.113.10:9999"
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Agent-ID", agent)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) core.ReservationTakeover {
+		t.Helper()
+		var out core.ReservationTakeover
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Fatalf("decode takeover: %v", err)
+		}
+		return out
+	}
+
+	rec := do(http.MethodPost, "/api/reservations", "stuck", map[string]any{
+		"agent_id": "stuck", "path_pattern": "pkg/*.go", "exclusive": true, "ttl_minutes": 60,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("reserve: %d", rec.Code)
+	}
+	var held apiReservation
+	_ = json.NewDecoder(rec.Body).Decode(&held)
+	takeoverPath := "/api/reservations/" + held.ID + "/takeover"
+	requestPath := "/api/reservations/" + held.ID + "/request-takeover"
+
+	if rec := do(http.MethodPost, requestPath, "stuck", map[string]any{}); rec.Code != http.StatusConflict {
+		t.Fatalf("holder requesting own takeover expected 409, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, requestPath, "blocked", map[string]any{"reason": "no progress for an hour", "timeout_seconds": 30})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("request takeover: %d %s", rec.Code, rec.Body.String())
+	}
+	first := decode(rec)
+	if first.Holder != "stuck" || first.Requester != "blocked" || first.Status != core.TakeoverPending {
+		t.Fatalf("takeover = %+v", first)
+	}
+	if rec := do(http.MethodPost, requestPath, "other", map[string]any{}); rec.Code != http.StatusConflict {
+		t.Fatalf("second pending takeover expected 409, got %d", rec.Code)
+	}
+
+	// The holder is told, and only the holder can answer.
+	rec = do(http.MethodGet, "/api/inbox/stuck?project=proj-a", "stuck", nil)
+	if !bytes.Contains(rec.Body.Bytes(), []byte("Takeover requested")) {
+		t.Fatalf("holder inbox = %s", rec.Body.String())
+	}
+	if rec := do(http.MethodPost, takeoverPath, "blocked", map[string]any{"accept": true}); rec.Code != http.StatusForbidden {
+		t.Fatalf("requester answering expected 403, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, takeoverPath, "blocked", map[string]any{"agent_id": "stuck", "accept": true}); rec.Code != http.StatusForbidden {
+		t.Fatalf("requester answering as the holder expected 403, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, takeoverPath, "stuck", map[string]any{"accept": false})
+	if rec.Code != http.StatusOK || decode(rec).Status != core.TakeoverDeclined {
+		t.Fatalf("decline: %d", rec.Code)
+	}
+
+	// Unanswered, the reservation moves to the requester at the deadline.
+	rec = do(http.MethodPost, requestPath, "blocked", map[string]any{"timeout_seconds": 30})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("second request: %d", rec.Code)
+	}
+	second := decode(rec)
+	ctx := context.Background()
+	if resolved, _ := st.ResolveReservationTakeovers(ctx, time.Now().UTC()); len(resolved) != 0 {
+		t.Fatalf("resolved before the deadline: %+v", resolved)
+	}
+	resolved, err := st.ResolveReservationTakeovers(ctx, second.Deadline.Add(time.Second))
+	if err != nil || len(resolved) != 1 || resolved[0].Status != core.TakeoverTransferred {
+		t.Fatalf("resolve = %+v, %v", resolved, err)
+	}
+
+	rec = do(http.MethodGet, takeoverPath, "blocked", nil)
+	latest := decode(rec)
+	if latest.ID != second.ID || latest.NewReservationID == "" {
+		t.Fatalf("latest takeover = %+v", latest)
+	}
+	old, _ := st.GetReservation(ctx, held.ID)
+	next, err := st.GetReservation(ctx, latest.NewReservationID)
+	if err != nil || old.IsActive() || next.AgentID != "blocked" || next.PathPattern != "pkg/*.go" || !next.IsActive() {
+		t.Fatalf("old = %+v, new = %+v, %v", old, next, err)
+	}
+}
+
+func TestReservationTakeoverWithoutKey(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	srv := httptest.NewServer(NewRouter(NewService(st), nil, auth.Middleware(auth.NewKeyring(true, nil))))
+	t.Cleanup(srv.Close)
+	post := func(path string, body any) *http.Response {
+		t.Helper()
+		buf, _ := json.Marshal(body)
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(buf))
+		if err != nil {
+			t.Fatalf("post %s: %v", path, err)
+		}
+		return resp
+	}
+
+	resp := post("/api/reservations", map[string]any{
+		"agent_id": "stuck", "project": "proj", "path_pattern": "pkg/*.go", "exclusive": true, "ttl_minutes": 60,
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	held := decodeJSON[apiReservation](t, resp)
+	resp = post("/api/reservations/"+held.ID+"/request-takeover", map[string]any{"agent_id": "blocked"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	takeoverPath := "/api/reservations/" + held.ID + "/takeover"
+	resp = post(takeoverPath, map[string]any{"accept": true})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = post(takeoverPath, map[string]any{"agent_id": "blocked", "accept": true})
+	requireStatus(t, resp, http.StatusForbidden)
+	resp.Body.Close()
+	resp = post(takeoverPath, map[string]any{"agent_id": "stuck", "accept": true})
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.ReservationTakeover](t, resp); got.Status != core.TakeoverTransferred {
+		t.Fatalf("takeover = %+v", got)
+	}
+}
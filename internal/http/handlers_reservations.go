@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mistakeknot/intermute/internal/anomaly"
 	"github.com/mistakeknot/intermute/internal/auth"
 	"github.com/mistakeknot/intermute/internal/core"
 )
@@ -76,15 +77,32 @@ func (s *Service) handleReservations(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Service) handleReservationByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	// Extract ID from path: /api/reservations/{id}
+	// Extract ID from path: /api/reservations/{id}[/request-takeover|/takeover]
 	path := strings.TrimPrefix(r.URL.Path, "/api/reservations/")
-	id := strings.Trim(path, "/")
+	id, sub, _ := strings.Cut(strings.Trim(path, "/"), "/")
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id is required", "missing_field")
+		return
+	}
+	switch sub {
+	case "":
+	case "request-takeover":
+		dispatchByMethod(w, r, methodHandlers{
+			post: func(w http.ResponseWriter, r *http.Request) { s.requestTakeover(w, r, id) },
+		})
+		return
+	case "takeover":
+		dispatchByMethod(w, r, methodHandlers{
+			get:  func(w http.ResponseWriter, r *http.Request) { s.getTakeover(w, r, id) },
+			post: func(w http.ResponseWriter, r *http.Request) { s.respondTakeover(w, r, id) },
+		})
+		return
+	default:
+		writeNotFound(w)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeMethodNotAllowed(w)
 		return
 	}
 	s.releaseReservation(w, r, id)
@@ -93,11 +111,11 @@ func (s *Service) handleReservationByID(w http.ResponseWriter, r *http.Request)
 func (s *Service) createReservation(w http.ResponseWriter, r *http.Request) {
 	var req reservationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 	if req.AgentID == "" || req.PathPattern == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "agent_id and path_pattern are required", "missing_field")
 		return
 	}
 
@@ -107,9 +125,21 @@ func (s *Service) createReservation(w http.ResponseWriter, r *http.Request) {
 		project = info.Project
 	}
 	if info.Mode == auth.ModeAPIKey && project != info.Project {
-		w.WriteHeader(http.StatusForbidden)
+		writeProjectMismatch(w)
 		return
 	}
+	// A team reservation ("@name") is taken by one of its members.
+	if name, isTeam := strings.CutPrefix(req.AgentID, groupAddressPrefix); isTeam {
+		group, err := s.store.GetContactGroup(r.Context(), project, name)
+		if err != nil || !group.Team {
+			writeJSONError(w, http.StatusBadRequest, "unknown team: "+name, "unknown_team")
+			return
+		}
+		if info.AgentID != "" && !s.isTeamMember(r.Context(), project, req.AgentID, info.AgentID) {
+			writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
+			return
+		}
+	}
 
 	ttl := 30 * time.Minute
 	if req.TTLMinutes > 0 {
@@ -131,17 +161,17 @@ func (s *Service) createReservation(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		var conflictErr *core.ConflictError
 		if errors.As(err, &conflictErr) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			_ = json.NewEncoder(w).Encode(map[string]any{
-				"error":     "reservation_conflict",
+			writeJSONErrorFields(w, http.StatusConflict, conflictErr.Error(), "reservation_conflict", map[string]any{
 				"conflicts": conflictErr.Conflicts,
 			})
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
+	s.observe(func(d *anomaly.Detector) []core.Anomaly {
+		return d.ObserveReservation(project, req.PathPattern, req.AgentID)
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -164,12 +194,12 @@ func (s *Service) listReservations(w http.ResponseWriter, r *http.Request) {
 	} else if project != "" {
 		reservations, err = s.store.ActiveReservations(r.Context(), project)
 	} else {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "project or agent is required", "missing_field")
 		return
 	}
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -184,14 +214,14 @@ func (s *Service) listReservations(w http.ResponseWriter, r *http.Request) {
 
 func (s *Service) checkConflicts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	project := r.URL.Query().Get("project")
 	pattern := r.URL.Query().Get("pattern")
 	if project == "" || pattern == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "project and pattern are required", "missing_field")
 		return
 	}
 
@@ -199,7 +229,7 @@ func (s *Service) checkConflicts(w http.ResponseWriter, r *http.Request) {
 
 	conflicts, err := s.store.CheckConflicts(r.Context(), project, pattern, exclusive)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -213,22 +243,22 @@ func (s *Service) releaseReservation(w http.ResponseWriter, r *http.Request, id
 	reservation, err := s.store.GetReservation(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, core.ErrNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+			writeNotFound(w)
 		} else {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeInternalError(w)
 		}
 		return
 	}
 	info, _ := auth.FromContext(r.Context())
-	if reservation.AgentID != info.AgentID {
-		w.WriteHeader(http.StatusForbidden)
+	if reservation.AgentID != info.AgentID && !s.isTeamMember(r.Context(), reservation.Project, reservation.AgentID, info.AgentID) {
+		writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
 		return
 	}
-	if err := s.store.ReleaseReservation(r.Context(), id, info.AgentID); err != nil {
+	if err := s.store.ReleaseReservation(r.Context(), id, reservation.AgentID); err != nil {
 		if errors.Is(err, core.ErrNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+			writeNotFound(w)
 		} else {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeInternalError(w)
 		}
 		return
 	}
@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,13 +10,11 @@ import (
 
 	"github.com/mistakeknot/intermute/internal/auth"
 	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestReleaseReservationOwnershipEnforced(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	ring := auth.NewKeyring(true, map[string]string{"secret": "proj-a"})
 	h := NewRouter(svc, nil, auth.Middleware(ring))
@@ -87,10 +86,7 @@ type reservationTestEnv struct {
 
 func newReservationTestEnv(t *testing.T) *reservationTestEnv {
 	t.Helper()
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	t.Cleanup(srv.Close)
@@ -191,6 +187,9 @@ func TestReservationOverlapConflict(t *testing.T) {
 	if detail["pattern"] == nil || detail["held_by"] == nil {
 		t.Fatal("conflict detail missing pattern or held_by")
 	}
+	if detail["exclusive"] != true || detail["expires_at"] == nil || detail["reservation_id"] == nil {
+		t.Fatalf("conflict detail = %v, want exclusive, expires_at and reservation_id", detail)
+	}
 }
 
 func TestReservationSharedAllowed(t *testing.T) {
@@ -242,7 +241,7 @@ func TestReservationReleaseAndVerify(t *testing.T) {
 	resID := res["id"].(string)
 
 	// Release via store (the HTTP DELETE endpoint requires auth agent matching)
-	if err := env.store.ReleaseReservation(nil, resID, "agent-a"); err != nil {
+	if err := env.store.ReleaseReservation(context.Background(), resID, "agent-a"); err != nil {
 		t.Fatalf("release: %v", err)
 	}
 
@@ -264,3 +263,63 @@ func TestReservationListRequiresProjectOrAgent(t *testing.T) {
 	requireStatus(t, resp, http.StatusBadRequest)
 	resp.Body.Close()
 }
+
+func TestTeamReservation(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	svc := NewService(st)
+	ring := auth.NewKeyring(true, map[string]string{"secret": "proj-a"})
+	h := NewRouter(svc, nil, auth.Middleware(ring))
+	do := func(method, path, agent string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.RemoteAddr = "203.0.113.10:9999"
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Agent-ID", agent)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPost, "/api/groups", "lead", map[string]any{"name": "frontend", "team": true, "members": []string{"alice", "bob"}}); rec.Code != http.StatusCreated {
+		t.Fatalf("create team: %d", rec.Code)
+	}
+	reserve := func(agent, holder, pattern string) *httptest.ResponseRecorder {
+		return do(http.MethodPost, "/api/reservations", agent, map[string]any{
+			"agent_id": holder, "path_pattern": pattern, "exclusive": true,
+		})
+	}
+
+	if rec := reserve("mallory", "@frontend", "web/*.ts"); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-member team reservation expected 403, got %d", rec.Code)
+	}
+	if rec := reserve("alice", "@backend", "web/*.ts"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown team expected 400, got %d", rec.Code)
+	}
+	rec := reserve("alice", "@frontend", "web/*.ts")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("team reservation expected 201, got %d", rec.Code)
+	}
+	var created apiReservation
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	// Members work under the team's lock; everyone else conflicts with it.
+	if rec := reserve("bob", "bob", "web/app.ts"); rec.Code != http.StatusCreated {
+		t.Fatalf("member reservation inside team lock expected 201, got %d", rec.Code)
+	}
+	if rec := reserve("carol", "carol", "web/index.ts"); rec.Code != http.StatusConflict {
+		t.Fatalf("outsider reservation expected 409, got %d", rec.Code)
+	}
+
+	if rec := do(http.MethodDelete, "/api/reservations/"+created.ID, "carol", nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("outsider release expected 403, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/reservations/"+created.ID, "bob", nil); rec.Code != http.StatusOK {
+		t.Fatalf("member release expected 200, got %d", rec.Code)
+	}
+}
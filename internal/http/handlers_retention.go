@@ -0,0 +1,124 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Retention handlers. A project's retention policy bounds its message and
+// event history by age and row count; the RetentionPurger applies every
+// policy periodically and POST /api/admin/retention/run applies them now.
+
+type retentionRunResponse struct {
+	Runs []core.RetentionRun `json:"runs"`
+}
+
+// handleAdminRetention lists every project's retention policy.
+func (s *DomainService) handleAdminRetention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	policies, err := s.domainStore.ListRetentionPolicies(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if policies == nil {
+		policies = []core.RetentionPolicy{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(policies)
+}
+
+// handleAdminRetentionRun purges now: one project's history with
+// ?project=, otherwise every project that has a policy.
+func (s *DomainService) handleAdminRetentionRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	runs, err := s.RunRetention(r.Context(), r.URL.Query().Get("project"))
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeJSONError(w, http.StatusNotFound, "project has no retention policy", "not_found")
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(retentionRunResponse{Runs: runs})
+}
+
+func (s *DomainService) getRetentionPolicy(w http.ResponseWriter, r *http.Request, project string) {
+	policy, err := s.domainStore.GetRetentionPolicy(r.Context(), project)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(policy)
+}
+
+func (s *DomainService) putRetentionPolicy(w http.ResponseWriter, r *http.Request, project string) {
+	limitBody(w, r)
+	var policy core.RetentionPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if policy.MaxAgeDays < 0 || policy.MaxRows < 0 || (policy.MaxAgeDays == 0 && policy.MaxRows == 0) {
+		writeJSONError(w, http.StatusBadRequest, "set max_age_days, max_rows or both to a positive number", "invalid_policy")
+		return
+	}
+	policy.Project = project
+	saved, err := s.domainStore.SetRetentionPolicy(r.Context(), policy)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(saved)
+}
+
+func (s *DomainService) deleteRetentionPolicy(w http.ResponseWriter, r *http.Request, project string) {
+	if err := s.domainStore.DeleteRetentionPolicy(r.Context(), project); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunRetention applies project's retention policy, or every policy when
+// project is "", and logs what was purged.
+func (s *DomainService) RunRetention(ctx context.Context, project string) ([]core.RetentionRun, error) {
+	runs, err := s.domainStore.PurgeRetention(ctx, project)
+	for _, run := range runs {
+		if run.MessagesDeleted+run.EventsDeleted+run.InboxRows+run.Threads == 0 {
+			continue
+		}
+		log.Printf("retention: project %q: purged %d message(s), %d event(s); compacted %d inbox row(s), %d thread(s)",
+			run.Project, run.MessagesDeleted, run.EventsDeleted, run.InboxRows, run.Threads)
+	}
+	return runs, err
+}
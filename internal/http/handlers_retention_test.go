@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestRetentionPurgesOldHistory(t *testing.T) {
+	env := newTestEnv(t)
+	t.Cleanup(clock.Reset)
+
+	send := func(project, thread, body string) {
+		t.Helper()
+		resp := env.post(t, "/api/messages", map[string]any{
+			"project": project, "from": "alice", "to": []string{"bob"}, "thread_id": thread, "body": body,
+		})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	inbox := func(project string) []apiMessage {
+		t.Helper()
+		resp := env.get(t, "/api/inbox/bob?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[inboxResponse](t, resp).Messages
+	}
+	run := func() core.RetentionRun {
+		t.Helper()
+		resp := env.post(t, "/api/admin/retention/run?project=proj", nil)
+		requireStatus(t, resp, http.StatusOK)
+		runs := decodeJSON[retentionRunResponse](t, resp).Runs
+		if len(runs) != 1 {
+			t.Fatalf("runs = %+v, want one", runs)
+		}
+		return runs[0]
+	}
+
+	send("proj", "old", "one")
+	send("proj", "old", "two")
+	// A deferred live poke has no message behind it and must survive.
+	if _, err := env.store.AppendEvent(context.Background(), core.Event{Type: core.EventPeerWindowPoke, Project: "proj", Message: core.Message{
+		ID: "poke", Project: "proj", From: "alice", To: []string{"bob"}, Body: "ping",
+		Metadata: map[string]string{"poke_result": core.PokeResultDeferred},
+	}}); err != nil {
+		t.Fatalf("append poke: %v", err)
+	}
+	send("other", "old", "untouched")
+	clock.Advance(10 * 24 * time.Hour)
+	send("proj", "new", "three")
+
+	resp := env.post(t, "/api/admin/retention/run?project=proj", nil)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+	resp = env.put(t, "/api/admin/projects/proj/retention", map[string]any{"max_age_days": 0})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = env.put(t, "/api/admin/projects/proj/retention", map[string]any{"max_age_days": 7})
+	requireStatus(t, resp, http.StatusOK)
+	if policy := decodeJSON[core.RetentionPolicy](t, resp); policy.Project != "proj" || policy.MaxAgeDays != 7 {
+		t.Fatalf("policy = %+v", policy)
+	}
+
+	// The old thread is indexed for both alice and bob.
+	got := run()
+	if got.MessagesDeleted != 2 || got.EventsDeleted == 0 || got.InboxRows != 2 || got.Threads != 2 {
+		t.Fatalf("age purge = %+v", got)
+	}
+	if msgs := inbox("proj"); len(msgs) != 1 || msgs[0].Body != "three" {
+		t.Fatalf("inbox after age purge = %+v", msgs)
+	}
+	resp = env.get(t, "/api/threads?project=proj&agent=bob")
+	requireStatus(t, resp, http.StatusOK)
+	if threads := decodeJSON[listThreadsResponse](t, resp).Threads; len(threads) != 1 || threads[0].ThreadID != "new" {
+		t.Fatalf("threads after purge = %+v", threads)
+	}
+	if msgs := inbox("other"); len(msgs) != 1 {
+		t.Fatalf("project without a policy lost history: %+v", msgs)
+	}
+	if pokes, err := env.store.ListPendingPokes(context.Background(), "proj", "bob"); err != nil || len(pokes) != 1 {
+		t.Fatalf("pending pokes after purge = %v, %v; want the deferred one", pokes, err)
+	}
+
+	// A row cap keeps only the newest messages.
+	resp = env.put(t, "/api/admin/projects/proj/retention", map[string]any{"max_rows": 1})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	send("proj", "new", "four")
+	if got := run(); got.MessagesDeleted != 1 {
+		t.Fatalf("row cap purge = %+v", got)
+	}
+	if msgs := inbox("proj"); len(msgs) != 1 || msgs[0].Body != "four" {
+		t.Fatalf("inbox after row cap = %+v", msgs)
+	}
+
+	resp = env.get(t, "/api/admin/retention")
+	requireStatus(t, resp, http.StatusOK)
+	if policies := decodeJSON[[]core.RetentionPolicy](t, resp); len(policies) != 1 || policies[0].MaxRows != 1 {
+		t.Fatalf("policies = %+v", policies)
+	}
+	resp = env.delete(t, "/api/admin/projects/proj/retention")
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = env.get(t, "/api/admin/projects/proj/retention")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+type searchResponse struct {
+	Query   string              `json:"query"`
+	Results []core.SearchResult `json:"results"`
+}
+
+func (s *DomainService) handleSearch(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get: s.search,
+	})
+}
+
+// search serves GET /api/search?q=...&kind=spec,story&limit=... over spec
+// titles and visions, story titles and acceptance criteria, insight titles
+// and bodies, and message subjects and bodies.
+func (s *DomainService) search(w http.ResponseWriter, r *http.Request) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	text := strings.TrimSpace(q.Get("q"))
+	if text == "" {
+		writeJSONError(w, http.StatusBadRequest, "q required", "invalid_request")
+		return
+	}
+	query := core.SearchQuery{Project: project, Query: text}
+	if v := q.Get("kind"); v != "" {
+		for _, k := range strings.Split(v, ",") {
+			kind := core.SearchKind(strings.TrimSpace(k))
+			if !core.ValidSearchKind(kind) {
+				writeJSONError(w, http.StatusBadRequest, "unknown kind: "+string(kind), "invalid_request")
+				return
+			}
+			query.Kinds = append(query.Kinds, kind)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			query.Limit = parsed
+		}
+	}
+
+	results, err := s.domainStore.Search(r.Context(), query)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(searchResponse{Query: text, Results: results})
+}
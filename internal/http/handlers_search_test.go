@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func searchFor(t *testing.T, env *testEnv, params url.Values) []core.SearchResult {
+	t.Helper()
+	resp := env.get(t, "/api/search?"+params.Encode())
+	requireStatus(t, resp, http.StatusOK)
+	return decodeJSON[searchResponse](t, resp).Results
+}
+
+func TestSearchAcrossKinds(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": "proj", "title": "Webhook delivery", "vision": "Retries with an exponential backoff budget", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+	resp = env.post(t, "/api/stories", map[string]any{"project": "proj", "epic_id": "e1", "title": "Dead letters", "acceptance_criteria": []string{"failed webhooks land in a dead letter queue"}, "status": "todo"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.post(t, "/api/insights", map[string]any{"project": "proj", "source": "user", "category": "ux", "title": "Users want webhook replay", "body": "Replaying failed deliveries by hand is tedious"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	sendTestMessage(t, env, "proj", "alice", []string{"bob"}, "the webhook backoff looks too aggressive")
+	resp = env.post(t, "/api/specs", map[string]any{"project": "other", "title": "Webhook elsewhere", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	results := searchFor(t, env, url.Values{"project": {"proj"}, "q": {"webhook"}})
+	kinds := map[core.SearchKind]bool{}
+	for _, r := range results {
+		if r.Project != "proj" {
+			t.Fatalf("result from another project: %+v", r)
+		}
+		kinds[r.Kind] = true
+	}
+	for _, k := range []core.SearchKind{core.SearchKindSpec, core.SearchKindStory, core.SearchKindInsight, core.SearchKindMessage} {
+		if !kinds[k] {
+			t.Fatalf("no %s result for webhook: %+v", k, results)
+		}
+	}
+
+	// Terms are ANDed and stemmed; kind narrows the result set.
+	results = searchFor(t, env, url.Values{"project": {"proj"}, "q": {"retry budget"}, "kind": {"spec,insight"}})
+	if len(results) != 1 || results[0].ID != spec.ID || results[0].Score <= 0 || results[0].Snippet == "" {
+		t.Fatalf("retry budget = %+v, want the spec", results)
+	}
+
+	// Updates re-index; deletes drop out.
+	spec.Vision = "Circuit breaking per endpoint"
+	resp = env.put(t, "/api/specs/"+spec.ID, spec)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if results = searchFor(t, env, url.Values{"project": {"proj"}, "q": {"budget"}, "kind": {"spec"}}); len(results) != 0 {
+		t.Fatalf("stale vision still matches: %+v", results)
+	}
+	if results = searchFor(t, env, url.Values{"project": {"proj"}, "q": {"circuit"}}); len(results) != 1 {
+		t.Fatalf("updated vision not indexed: %+v", results)
+	}
+	resp = env.delete(t, "/api/specs/"+spec.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	if results = searchFor(t, env, url.Values{"project": {"proj"}, "q": {"circuit"}}); len(results) != 0 {
+		t.Fatalf("deleted spec still matches: %+v", results)
+	}
+}
+
+func TestSearchValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.get(t, "/api/search?project=proj")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/search?project=proj&q=x&kind=task")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	// FTS syntax in the query is matched literally rather than failing.
+	if results := searchFor(t, env, url.Values{"project": {"proj"}, "q": {`"unbalanced AND (`}}); len(results) != 0 {
+		t.Fatalf("results = %+v", results)
+	}
+}
@@ -0,0 +1,160 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Session analytics: per-agent session counts, durations, error rates and
+// throughput over a window, from the session history (see SessionHistory).
+// Agents whose sessions keep ending in error stand out by error_rate.
+
+// defaultSessionWindow is how far back the report looks without ?since.
+const defaultSessionWindow = 30 * 24 * time.Hour
+
+// sessionStats summarises one agent's sessions, over the whole window or
+// one bucket of it (PeriodStart). Durations only count sessions that have
+// ended; errored sessions are those whose last known status is error.
+type sessionStats struct {
+	Agent              string     `json:"agent"`
+	PeriodStart        *time.Time `json:"period_start,omitempty"`
+	Sessions           int        `json:"sessions"`
+	Ended              int        `json:"ended"`
+	Errored            int        `json:"errored"`
+	ErrorRate          float64    `json:"error_rate"`
+	AvgDurationSeconds float64    `json:"avg_duration_seconds"`
+	TasksCompleted     int        `json:"tasks_completed"`
+	TasksPerSession    float64    `json:"tasks_per_session"`
+
+	totalDuration time.Duration
+}
+
+type sessionAnalyticsResponse struct {
+	Project string         `json:"project"`
+	Since   time.Time      `json:"since"`
+	Until   time.Time      `json:"until"`
+	Bucket  string         `json:"bucket,omitempty"`
+	Stats   []sessionStats `json:"stats"`
+}
+
+// bucketStart returns the start of the day or (Monday-based) week t falls
+// in, in UTC.
+func bucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == "week" {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+// summariseSessions groups records by agent, and by bucket when one is
+// given, ordered by agent then period.
+func summariseSessions(records []core.SessionRecord, bucket string) []sessionStats {
+	type key struct {
+		agent  string
+		period time.Time
+	}
+	byKey := map[key]*sessionStats{}
+	for _, rec := range records {
+		k := key{agent: rec.Agent}
+		if bucket != "" {
+			k.period = bucketStart(rec.StartedAt, bucket)
+		}
+		st := byKey[k]
+		if st == nil {
+			st = &sessionStats{Agent: rec.Agent}
+			if bucket != "" {
+				period := k.period
+				st.PeriodStart = &period
+			}
+			byKey[k] = st
+		}
+		st.Sessions++
+		st.TasksCompleted += rec.TasksDone
+		if rec.Status == core.SessionStatusError {
+			st.Errored++
+		}
+		if rec.EndedAt != nil {
+			st.Ended++
+			st.totalDuration += rec.EndedAt.Sub(rec.StartedAt)
+		}
+	}
+	out := make([]sessionStats, 0, len(byKey))
+	for _, st := range byKey {
+		st.ErrorRate = float64(st.Errored) / float64(st.Sessions)
+		st.TasksPerSession = float64(st.TasksCompleted) / float64(st.Sessions)
+		if st.Ended > 0 {
+			st.AvgDurationSeconds = st.totalDuration.Seconds() / float64(st.Ended)
+		}
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Agent != out[j].Agent {
+			return out[i].Agent < out[j].Agent
+		}
+		return out[i].PeriodStart != nil && out[i].PeriodStart.Before(*out[j].PeriodStart)
+	})
+	return out
+}
+
+// handleSessionAnalytics serves GET /api/reports/sessions?since=&until=
+// &bucket=day|week.
+func (s *DomainService) handleSessionAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	q := r.URL.Query()
+	project := info.Project
+	if project == "" {
+		project = q.Get("project")
+	}
+	until := clock.Now().UTC()
+	if v := q.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "until must be RFC 3339", "invalid_time")
+			return
+		}
+		until = t.UTC()
+	}
+	since := until.Add(-defaultSessionWindow)
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "since must be RFC 3339", "invalid_time")
+			return
+		}
+		since = t.UTC()
+	}
+	if !since.Before(until) {
+		writeJSONError(w, http.StatusBadRequest, "since must be before until", "invalid_time")
+		return
+	}
+	bucket := q.Get("bucket")
+	if bucket != "" && bucket != "day" && bucket != "week" {
+		writeJSONError(w, http.StatusBadRequest, "bucket must be day or week", "invalid_bucket")
+		return
+	}
+	records, err := s.domainStore.SessionHistory(r.Context(), project, since, until)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessionAnalyticsResponse{
+		Project: project,
+		Since:   since,
+		Until:   until,
+		Bucket:  bucket,
+		Stats:   summariseSessions(records, bucket),
+	})
+}
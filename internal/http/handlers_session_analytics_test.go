@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestSessionAnalytics(t *testing.T) {
+	env := newTestEnv(t)
+	t.Cleanup(clock.Reset)
+
+	start := func(agent string) core.Session {
+		t.Helper()
+		resp := env.post(t, "/api/sessions", map[string]any{"project": "proj", "name": agent + "-tmux", "agent": agent, "status": "running"})
+		requireStatus(t, resp, http.StatusCreated)
+		return decodeJSON[core.Session](t, resp)
+	}
+	stop := func(s core.Session) {
+		t.Helper()
+		resp := env.delete(t, "/api/sessions/"+s.ID+"?project=proj")
+		requireStatus(t, resp, http.StatusNoContent)
+		resp.Body.Close()
+	}
+
+	// flaky crashes twice in a row; steady finishes a session with two
+	// tasks done and has one still running.
+	crash1, crash2, good := start("flaky"), start("flaky"), start("steady")
+	start("steady")
+	for _, title := range []string{"one", "two"} {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": title, "status": "done", "session_id": good.ID})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+	clock.Advance(10 * time.Minute)
+	for _, s := range []core.Session{crash1, crash2} {
+		s.Status = core.SessionStatusError
+		resp := env.put(t, "/api/sessions/"+s.ID, s)
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	stop(crash1)
+	stop(crash2)
+	stop(good)
+
+	resp := env.get(t, "/api/reports/sessions?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[sessionAnalyticsResponse](t, resp).Stats
+	if len(got) != 2 || got[0].Agent != "flaky" || got[1].Agent != "steady" {
+		t.Fatalf("stats = %+v, want flaky then steady", got)
+	}
+	flaky, steady := got[0], got[1]
+	if flaky.Sessions != 2 || flaky.Ended != 2 || flaky.Errored != 2 || flaky.ErrorRate != 1 {
+		t.Fatalf("flaky = %+v", flaky)
+	}
+	if flaky.AvgDurationSeconds < 600 || flaky.AvgDurationSeconds > 660 {
+		t.Fatalf("flaky avg duration = %v, want ~10m", flaky.AvgDurationSeconds)
+	}
+	if steady.Sessions != 2 || steady.Ended != 1 || steady.Errored != 0 || steady.TasksCompleted != 2 || steady.TasksPerSession != 1 {
+		t.Fatalf("steady = %+v", steady)
+	}
+
+	resp = env.get(t, "/api/reports/sessions?project=proj&bucket=day")
+	requireStatus(t, resp, http.StatusOK)
+	for _, st := range decodeJSON[sessionAnalyticsResponse](t, resp).Stats {
+		if st.PeriodStart == nil || !st.PeriodStart.Equal(bucketStart(crash1.StartedAt, "day")) {
+			t.Fatalf("bucketed stats = %+v", st)
+		}
+	}
+
+	// Nothing started in a window that ends before these sessions.
+	resp = env.get(t, "/api/reports/sessions?project=proj&until="+crash1.StartedAt.Add(-time.Hour).Format(time.RFC3339))
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[sessionAnalyticsResponse](t, resp).Stats; len(got) != 0 {
+		t.Fatalf("stats before the window = %+v", got)
+	}
+
+	for _, q := range []string{"bucket=month", "since=yesterday", "since=2030-01-01T00:00:00Z&until=2029-01-01T00:00:00Z"} {
+		resp := env.get(t, "/api/reports/sessions?project=proj&"+q)
+		requireStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+const (
+	defaultContextThreads  = 5
+	defaultContextMessages = 10
+	maxContextThreads      = 50
+	maxContextMessages     = 100
+)
+
+// sessionContextResponse is the resume bundle for a restarting agent session.
+// Task, Story, Epic and Spec are nil when the session has no task or the
+// chain of parent links is broken.
+type sessionContextResponse struct {
+	Session      core.Session           `json:"session"`
+	Task         *core.Task             `json:"task,omitempty"`
+	Story        *core.Story            `json:"story,omitempty"`
+	Epic         *core.Epic             `json:"epic,omitempty"`
+	Spec         *core.Spec             `json:"spec,omitempty"`
+	Threads      []sessionContextThread `json:"threads"`
+	Reservations []apiReservation       `json:"reservations"`
+	Cursor       uint64                 `json:"cursor"`
+}
+
+type sessionContextThread struct {
+	ThreadID     string       `json:"thread_id"`
+	MessageCount int          `json:"message_count"`
+	Messages     []apiMessage `json:"messages"`
+}
+
+// getSessionContext assembles everything an agent needs to resume a session
+// in one call: the session's task and its story/epic/spec lineage, the tail
+// of the agent's most recent threads, its active reservations, and the event
+// cursor to resume inbox streaming from.
+func (s *DomainService) getSessionContext(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	threadLimit := queryIntBounded(r, "threads", defaultContextThreads, maxContextThreads)
+	messageLimit := queryIntBounded(r, "messages", defaultContextMessages, maxContextMessages)
+	ctx := r.Context()
+
+	// Read the cursor before anything else so that messages arriving while
+	// the bundle is assembled are replayed rather than skipped.
+	cursor, err := s.store.CurrentCursor(ctx)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	session, err := s.domainStore.GetSession(ctx, project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	resp := sessionContextResponse{
+		Session:      session,
+		Threads:      []sessionContextThread{},
+		Reservations: []apiReservation{},
+		Cursor:       cursor,
+	}
+
+	if session.TaskID != "" {
+		if task, err := s.domainStore.GetTask(ctx, session.Project, session.TaskID); err == nil {
+			resp.Task = &task
+			if task.StoryID != "" {
+				if story, err := s.domainStore.GetStory(ctx, session.Project, task.StoryID); err == nil {
+					resp.Story = &story
+					if epic, err := s.domainStore.GetEpic(ctx, session.Project, story.EpicID); err == nil {
+						resp.Epic = &epic
+						if epic.SpecID != "" {
+							if spec, err := s.domainStore.GetSpec(ctx, session.Project, epic.SpecID); err == nil {
+								resp.Spec = &spec
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	threads, err := s.store.ListThreads(ctx, session.Project, session.Agent, 0, threadLimit)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	for _, t := range threads {
+		msgs, err := s.store.ThreadMessages(ctx, session.Project, t.ThreadID, 0)
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		if len(msgs) > messageLimit {
+			msgs = msgs[len(msgs)-messageLimit:]
+		}
+		thread := sessionContextThread{
+			ThreadID:     t.ThreadID,
+			MessageCount: t.MessageCount,
+			Messages:     make([]apiMessage, 0, len(msgs)),
+		}
+		for _, m := range msgs {
+			thread.Messages = append(thread.Messages, toAPIMessage(m, session.Agent))
+		}
+		resp.Threads = append(resp.Threads, thread)
+	}
+
+	reservations, err := s.store.AgentReservations(ctx, session.Agent)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	for _, res := range reservations {
+		if res.IsActive() && res.Project == session.Project {
+			resp.Reservations = append(resp.Reservations, toAPIReservation(res))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// queryIntBounded parses a positive integer query parameter, falling back to
+// def when absent or invalid and clamping to max.
+func queryIntBounded(r *http.Request, key string, def, max int) int {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return def
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
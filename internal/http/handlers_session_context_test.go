@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestSessionContext(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	const project = "proj"
+
+	spec, _ := env.store.CreateSpec(ctx, core.Spec{Project: project, Title: "Spec"})
+	epic, _ := env.store.CreateEpic(ctx, core.Epic{Project: project, SpecID: spec.ID, Title: "Epic"})
+	story, _ := env.store.CreateStory(ctx, core.Story{Project: project, EpicID: epic.ID, Title: "Story"})
+	task, _ := env.store.CreateTask(ctx, core.Task{Project: project, StoryID: story.ID, Title: "Task", Agent: "alice"})
+	session, err := env.store.CreateSession(ctx, core.Session{Project: project, Name: "s1", Agent: "alice", TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		resp := env.post(t, "/api/messages", map[string]any{
+			"project":   project,
+			"from":      "bob",
+			"to":        []string{"alice"},
+			"thread_id": "t1",
+			"body":      fmt.Sprintf("msg %d", i),
+		})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	resp := env.post(t, "/api/reservations", map[string]any{
+		"agent_id":     "alice",
+		"project":      project,
+		"path_pattern": "cmd/**",
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/sessions/"+session.ID+"/context?project="+project+"&messages=2")
+	requireStatus(t, resp, http.StatusOK)
+	bundle := decodeJSON[sessionContextResponse](t, resp)
+
+	if bundle.Task == nil || bundle.Task.ID != task.ID {
+		t.Fatalf("expected task %s, got %+v", task.ID, bundle.Task)
+	}
+	if bundle.Story == nil || bundle.Epic == nil || bundle.Spec == nil || bundle.Spec.ID != spec.ID {
+		t.Fatalf("expected full story/epic/spec lineage, got story=%v epic=%v spec=%v", bundle.Story, bundle.Epic, bundle.Spec)
+	}
+	if len(bundle.Threads) != 1 || bundle.Threads[0].ThreadID != "t1" {
+		t.Fatalf("expected thread t1, got %+v", bundle.Threads)
+	}
+	if got := bundle.Threads[0].Messages; len(got) != 2 || got[1].Body != "msg 2" {
+		t.Fatalf("expected last 2 messages of t1, got %+v", got)
+	}
+	if len(bundle.Reservations) != 1 {
+		t.Fatalf("expected 1 reservation, got %d", len(bundle.Reservations))
+	}
+	if bundle.Cursor == 0 {
+		t.Fatal("expected non-zero resume cursor")
+	}
+
+	// Resuming from the cursor yields nothing already covered by the bundle.
+	resp = env.get(t, fmt.Sprintf("/api/inbox/alice?project=%s&since_cursor=%d", project, bundle.Cursor))
+	requireStatus(t, resp, http.StatusOK)
+	inbox := decodeJSON[inboxResponse](t, resp)
+	if len(inbox.Messages) != 0 {
+		t.Fatalf("expected empty inbox after resume cursor, got %d", len(inbox.Messages))
+	}
+}
+
+func TestSessionContextNotFound(t *testing.T) {
+	env := newTestEnv(t)
+	resp := env.get(t, "/api/sessions/missing/context?project=proj")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Short IDs. Specs, epics, stories and tasks carry a per-project short ID
+// ("TASK-348") next to their UUID; every by-ID endpoint accepts either.
+
+// resolveShortID maps ref to the entity's UUID if it is a short ID for
+// entityType. Anything else, including a short ID that doesn't resolve, is
+// returned unchanged so the caller's own lookup decides the outcome.
+func resolveShortID(ctx context.Context, store storage.DomainStore, project, entityType, ref string) string {
+	if core.ParseShortID(ref) == "" {
+		return ref
+	}
+	id, err := store.ResolveShortID(ctx, project, entityType, ref)
+	if err != nil {
+		log.Printf("WARN: resolve %s %s: %v", entityType, ref, err)
+		return ref
+	}
+	if id == "" {
+		return ref
+	}
+	return id
+}
+
+// resolveEntityID resolves a by-ID path segment. The project is the
+// caller's key project or ?project=; without either, a short ID resolves
+// only if a single project has it.
+func (s *DomainService) resolveEntityID(r *http.Request, entityType, id string) string {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	return resolveShortID(r.Context(), s.domainStore, project, entityType, id)
+}
+
+// acceptShortIDs wraps a by-ID getter so it also accepts short IDs.
+func acceptShortIDs[T any](store storage.DomainStore, entityType string, get func(ctx context.Context, project, id string) (T, error)) func(ctx context.Context, project, id string) (T, error) {
+	return func(ctx context.Context, project, id string) (T, error) {
+		return get(ctx, project, resolveShortID(ctx, store, project, entityType, id))
+	}
+}
@@ -0,0 +1,118 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestShortIDsOnByIDEndpoints(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "first", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	first := decodeJSON[core.Task](t, resp)
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "second", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	second := decodeJSON[core.Task](t, resp)
+	if first.ShortID != "TASK-1" || second.ShortID != "TASK-2" {
+		t.Fatalf("short ids = %q, %q", first.ShortID, second.ShortID)
+	}
+
+	resp = env.get(t, "/api/tasks/task-2?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.Task](t, resp); got.ID != second.ID {
+		t.Fatalf("GET by short id = %s, want %s", got.ID, second.ID)
+	}
+
+	// Updates by short ID apply to the UUID and keep the short ID.
+	update := first
+	update.ShortID = ""
+	update.Status = core.TaskStatusRunning
+	resp = env.put(t, "/api/tasks/TASK-1", update)
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.Task](t, resp); got.ID != first.ID || got.ShortID != "TASK-1" || got.Status != core.TaskStatusRunning {
+		t.Fatalf("PUT by short id = %+v", got)
+	}
+
+	resp = env.post(t, "/api/tasks/batch-get", map[string]any{"project": "proj", "ids": []string{"TASK-2", first.ID, "TASK-9"}})
+	requireStatus(t, resp, http.StatusOK)
+	batch := decodeJSON[batchGetResponse[core.Task]](t, resp)
+	if len(batch.Found) != 2 || len(batch.Missing) != 1 || batch.Missing[0] != "TASK-9" {
+		t.Fatalf("batch = %+v", batch)
+	}
+
+	resp = env.get(t, "/api/tasks/TASK-9?project=proj")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestProjectShortIDSequences(t *testing.T) {
+	env := newTestEnv(t)
+	createTask := func(title string) core.Task {
+		t.Helper()
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "pay", "title": title, "status": "pending"})
+		requireStatus(t, resp, http.StatusCreated)
+		return decodeJSON[core.Task](t, resp)
+	}
+
+	resp := env.post(t, "/api/projects", map[string]any{
+		"name":               "pay",
+		"short_id_sequences": map[string]any{"spec": map[string]any{"prefix": "pay"}, "task": map[string]any{"start": 100}},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	created := decodeJSON[CreateProjectResponse](t, resp)
+	if created.Spec.ShortID != "PAY-1" {
+		t.Fatalf("starter spec short id = %q, want PAY-1", created.Spec.ShortID)
+	}
+	if seq := created.Metadata.ShortIDSequences; seq["spec"].Prefix != "PAY" || seq["task"].Start != 100 {
+		t.Fatalf("sequences = %+v", seq)
+	}
+	if first := createTask("first"); first.ShortID != "TASK-100" {
+		t.Fatalf("first task short id = %q, want TASK-100", first.ShortID)
+	}
+
+	// A new prefix counts from start; the old short IDs keep resolving.
+	resp = env.patch(t, "/api/projects/pay", map[string]any{
+		"short_id_sequences": map[string]any{"task": map[string]any{"prefix": "core", "start": 1}},
+	})
+	requireStatus(t, resp, http.StatusOK)
+	if p := decodeJSON[core.Project](t, resp); p.ShortIDSequences["task"].Prefix != "CORE" || len(p.ShortIDSequences) != 1 {
+		t.Fatalf("updated sequences = %+v", p.ShortIDSequences)
+	}
+	second := createTask("second")
+	if second.ShortID != "CORE-1" {
+		t.Fatalf("second task short id = %q, want CORE-1", second.ShortID)
+	}
+	for ref, want := range map[string]string{"task-100": "first", "core-1": "second"} {
+		resp = env.get(t, "/api/tasks/"+ref+"?project=pay")
+		requireStatus(t, resp, http.StatusOK)
+		if got := decodeJSON[core.Task](t, resp); got.Title != want {
+			t.Fatalf("GET %s = %q, want %q", ref, got.Title, want)
+		}
+	}
+
+	// Switching back resumes the old counter, and a lower start never
+	// reissues a number.
+	resp = env.patch(t, "/api/projects/pay", map[string]any{"short_id_sequences": map[string]any{}})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if third := createTask("third"); third.ShortID != "TASK-101" {
+		t.Fatalf("third task short id = %q, want TASK-101", third.ShortID)
+	}
+
+	for _, seqs := range []map[string]any{
+		{"task": map[string]any{"prefix": "2X"}},
+		{"task": map[string]any{"prefix": "PAY-X"}},
+		{"task": map[string]any{"start": -1}},
+		{"insight": map[string]any{"prefix": "INS"}},
+		{"task": map[string]any{"prefix": "EPIC"}},
+	} {
+		resp = env.patch(t, "/api/projects/pay", map[string]any{"short_id_sequences": seqs})
+		requireStatus(t, resp, http.StatusBadRequest)
+		if e := decodeJSON[map[string]any](t, resp); e["code"] != "invalid_short_id_sequence" {
+			t.Fatalf("%v: error = %v", seqs, e)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Project snapshots move a project between servers or seed test fixtures:
+// GET /api/projects/{project}/export dumps it, and
+// POST /api/projects/{project}/import restores a dump into a project that
+// has no domain entities or messages yet, possibly under another name.
+
+// maxSnapshotBody caps import bodies; snapshots carry whole projects, so
+// the usual request limit is far too small.
+const maxSnapshotBody = 256 << 20 // 256 MiB
+
+// handleProjectByName serves /api/projects/{project} itself plus its
+// /export and /import.
+func (s *DomainService) handleProjectByName(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/projects/"), "/")
+	project, action, _ := strings.Cut(path, "/")
+	if project == "" {
+		writeNotFound(w)
+		return
+	}
+	// API-key callers are scoped to their own project.
+	if info, _ := auth.FromContext(r.Context()); info.Mode == auth.ModeAPIKey && info.Project != project {
+		writeProjectMismatch(w)
+		return
+	}
+	switch action {
+	case "":
+		dispatchByMethod(w, r, methodHandlers{
+			get:    func(w http.ResponseWriter, r *http.Request) { s.getProject(w, r, project) },
+			patch:  func(w http.ResponseWriter, r *http.Request) { s.updateProject(w, r, project) },
+			delete: func(w http.ResponseWriter, r *http.Request) { s.deleteProject(w, r, project) },
+		})
+	case "export":
+		dispatchByMethod(w, r, methodHandlers{
+			get: func(w http.ResponseWriter, r *http.Request) { s.exportProject(w, r, project) },
+		})
+	case "import":
+		dispatchByMethod(w, r, methodHandlers{
+			post: func(w http.ResponseWriter, r *http.Request) { s.importProject(w, r, project) },
+		})
+	default:
+		writeNotFound(w)
+	}
+}
+
+func (s *DomainService) exportProject(w http.ResponseWriter, r *http.Request, project string) {
+	snap, err := s.domainStore.ExportProject(r.Context(), project)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(snap)
+}
+
+func (s *DomainService) importProject(w http.ResponseWriter, r *http.Request, project string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSnapshotBody)
+	var snap core.ProjectSnapshot
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&snap); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	result, err := s.domainStore.ImportProject(r.Context(), project, snap)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidSnapshot):
+			writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_snapshot")
+		case errors.Is(err, core.ErrAlreadyExists):
+			writeJSONError(w, http.StatusConflict, "project already has data", "project_not_empty")
+		default:
+			writeInternalError(w)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(result)
+}
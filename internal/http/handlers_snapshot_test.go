@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestProjectExportImport(t *testing.T) {
+	src := newTestEnv(t)
+
+	resp := src.post(t, "/api/projects", map[string]any{"name": "alpha"})
+	requireStatus(t, resp, http.StatusCreated)
+	created := decodeJSON[CreateProjectResponse](t, resp)
+	resp = src.post(t, "/api/tasks", map[string]any{"project": "alpha", "title": "ship it", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+	resp = src.post(t, "/api/messages", map[string]any{
+		"project": "alpha", "from": "alice", "to": []string{"bob"}, "thread_id": "t1", "body": "hello",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = src.get(t, "/api/projects/alpha/export")
+	requireStatus(t, resp, http.StatusOK)
+	snap := decodeJSON[core.ProjectSnapshot](t, resp)
+	if snap.Version != core.ProjectSnapshotVersion || len(snap.Tables["specs"]) != 1 || len(snap.Tables["messages"]) != 1 {
+		t.Fatalf("unexpected snapshot: version %d, %d specs, %d messages",
+			snap.Version, len(snap.Tables["specs"]), len(snap.Tables["messages"]))
+	}
+
+	// Restore on another server, under another name, behind existing traffic
+	// so inbox cursors have to move.
+	dst := newTestEnv(t)
+	resp = dst.post(t, "/api/messages", map[string]any{"project": "other", "from": "x", "to": []string{"y"}, "body": "noise"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = dst.post(t, "/api/projects/beta/import", snap)
+	requireStatus(t, resp, http.StatusCreated)
+	result := decodeJSON[core.ProjectImport](t, resp)
+	if result.Project != "beta" || result.Rows["tasks"] != 1 || result.Rows["cujs"] != 1 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+
+	resp = dst.get(t, "/api/specs/"+created.Spec.ID+"?project=beta")
+	requireStatus(t, resp, http.StatusOK)
+	if spec := decodeJSON[core.Spec](t, resp); spec.Project != "beta" || spec.Title != created.Spec.Title {
+		t.Fatalf("unexpected imported spec: %+v", spec)
+	}
+	resp = dst.get(t, "/api/tasks/"+task.ID+"?project=beta")
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.Task](t, resp); got.ShortID != task.ShortID {
+		t.Fatalf("short id = %q, want %q", got.ShortID, task.ShortID)
+	}
+
+	resp = dst.get(t, "/api/inbox/bob?project=beta")
+	requireStatus(t, resp, http.StatusOK)
+	msgs := decodeJSON[inboxResponse](t, resp).Messages
+	if len(msgs) != 1 || msgs[0].Body != "hello" || msgs[0].Cursor <= 1 {
+		t.Fatalf("unexpected imported inbox: %+v", msgs)
+	}
+
+	// Short IDs carry on from the source's counters.
+	resp = dst.post(t, "/api/tasks", map[string]any{"project": "beta", "title": "next", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	if next := decodeJSON[core.Task](t, resp); next.ShortID != "TASK-2" {
+		t.Fatalf("next short id = %q, want TASK-2", next.ShortID)
+	}
+
+	// A project with data can't be imported into.
+	resp = dst.post(t, "/api/projects/beta/import", snap)
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+}
+
+func TestProjectImportRejectsBadSnapshot(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/projects/p/import", map[string]any{"version": 99, "tables": map[string]any{}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	snap := map[string]any{
+		"version": core.ProjectSnapshotVersion,
+		"tables":  map[string]any{"specs": []map[string]any{{"id": "s1", "nope": 1}}},
+	}
+	resp = env.post(t, "/api/projects/p/import", snap)
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/projects/p/import", map[string]any{
+		"version": core.ProjectSnapshotVersion,
+		"tables":  map[string]any{"agents": []any{}},
+	})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+}
@@ -0,0 +1,193 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// maxSnooze caps how far ahead a message can be snoozed.
+const maxSnooze = 30 * 24 * time.Hour
+
+// snoozeRequest sets the wake time either absolutely (until, RFC3339) or
+// relative to now (for, a Go duration such as "30m").
+type snoozeRequest struct {
+	Agent string `json:"agent"`
+	Until string `json:"until,omitempty"`
+	For   string `json:"for,omitempty"`
+}
+
+type snoozeResponse struct {
+	MessageID string `json:"message_id"`
+	Agent     string `json:"agent"`
+	Until     string `json:"until"`
+}
+
+type unsnoozeResponse struct {
+	MessageID string `json:"message_id"`
+	Agent     string `json:"agent"`
+	Cursor    uint64 `json:"cursor"`
+}
+
+type snoozedItem struct {
+	Message apiMessage `json:"message"`
+	Until   string     `json:"until"`
+}
+
+type snoozedResponse struct {
+	Messages []snoozedItem `json:"messages"`
+}
+
+// parseSnoozeUntil resolves a snooze request to an absolute wake time in
+// (now, now+maxSnooze].
+func parseSnoozeUntil(req snoozeRequest, now time.Time) (time.Time, bool) {
+	var until time.Time
+	switch {
+	case req.Until != "" && req.For != "":
+		return time.Time{}, false
+	case req.Until != "":
+		t, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			return time.Time{}, false
+		}
+		until = t
+	case req.For != "":
+		d, err := time.ParseDuration(req.For)
+		if err != nil {
+			return time.Time{}, false
+		}
+		until = now.Add(d)
+	default:
+		return time.Time{}, false
+	}
+	if !until.After(now) || until.Sub(now) > maxSnooze {
+		return time.Time{}, false
+	}
+	return until.UTC(), true
+}
+
+// snoozeMessage hides a message from one recipient's inbox until a chosen
+// time, after which it reappears as unread at a fresh cursor.
+func (s *Service) snoozeMessage(w http.ResponseWriter, r *http.Request, msgID string) {
+	limitBody(w, r)
+	var req snoozeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	agent := strings.TrimSpace(req.Agent)
+	until, ok := parseSnoozeUntil(req, clock.Now().UTC())
+	if agent == "" || !ok {
+		writeJSONError(w, http.StatusBadRequest, "agent and one of until or for are required", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = strings.TrimSpace(r.URL.Query().Get("project"))
+	}
+	if err := s.store.SnoozeMessage(r.Context(), project, msgID, agent, until); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(snoozeResponse{
+		MessageID: msgID,
+		Agent:     agent,
+		Until:     until.Format(time.RFC3339Nano),
+	})
+}
+
+// unsnoozeMessage ends a snooze early, re-delivering the message now.
+func (s *Service) unsnoozeMessage(w http.ResponseWriter, r *http.Request, msgID string) {
+	limitBody(w, r)
+	var req snoozeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	agent := strings.TrimSpace(req.Agent)
+	if agent == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = strings.TrimSpace(r.URL.Query().Get("project"))
+	}
+	wake, err := s.store.UnsnoozeMessage(r.Context(), project, msgID, agent)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	s.broadcastWakes([]core.SnoozeWake{wake})
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(unsnoozeResponse{MessageID: msgID, Agent: agent, Cursor: wake.Cursor})
+}
+
+// handleInboxSnoozed lists an agent's currently snoozed messages.
+func (s *Service) handleInboxSnoozed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	// Path: /api/inbox/{agent}/snoozed
+	path := strings.TrimPrefix(r.URL.Path, "/api/inbox/")
+	agent := strings.Trim(strings.TrimSuffix(path, "/snoozed"), "/")
+	if agent == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = strings.TrimSpace(r.URL.Query().Get("project"))
+	}
+	snoozed, err := s.store.ListSnoozed(r.Context(), project, agent)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	resp := snoozedResponse{Messages: make([]snoozedItem, 0, len(snoozed))}
+	viewer := messageViewer(r)
+	for _, sm := range snoozed {
+		resp.Messages = append(resp.Messages, snoozedItem{
+			Message: toAPIMessage(sm.Message, viewer),
+			Until:   sm.Until.Format(time.RFC3339Nano),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// broadcastWakes notifies each agent whose snoozed message was re-delivered.
+func (s *Service) broadcastWakes(wakes []core.SnoozeWake) {
+	if s.bus == nil {
+		return
+	}
+	for _, wake := range wakes {
+		s.bus.Broadcast(wake.Project, wake.Agent, map[string]any{
+			"type":       string(core.EventMessageUnsnoozed),
+			"event_id":   wake.EventID,
+			"project":    wake.Project,
+			"message_id": wake.MessageID,
+			"cursor":     wake.Cursor,
+			"agent":      wake.Agent,
+		})
+	}
+}
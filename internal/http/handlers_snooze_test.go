@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSnoozeMessage(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/messages", map[string]any{
+		"project": project,
+		"from":    "alice",
+		"to":      []string{"bob"},
+		"body":    "look at this later",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	msgID := decodeJSON[sendMessageResponse](t, resp).MessageID
+
+	resp = env.get(t, "/api/inbox/bob?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	before := decodeJSON[inboxResponse](t, resp)
+	if len(before.Messages) != 1 {
+		t.Fatalf("expected 1 message before snooze, got %d", len(before.Messages))
+	}
+
+	for name, body := range map[string]map[string]any{
+		"missing agent": {"for": "1h"},
+		"missing time":  {"agent": "bob"},
+		"past":          {"agent": "bob", "until": "2001-01-01T00:00:00Z"},
+		"both":          {"agent": "bob", "for": "1h", "until": "2999-01-01T00:00:00Z"},
+		"too far":       {"agent": "bob", "for": "10000h"},
+	} {
+		resp = env.post(t, "/api/messages/"+msgID+"/snooze?project="+project, body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+
+	resp = env.post(t, "/api/messages/"+msgID+"/snooze?project="+project, map[string]any{"agent": "carol", "for": "1h"})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/messages/"+msgID+"/snooze?project="+project, map[string]any{"agent": "bob", "for": "1h"})
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[snoozeResponse](t, resp); got.MessageID != msgID || got.Until == "" {
+		t.Fatalf("unexpected snooze response: %+v", got)
+	}
+
+	resp = env.get(t, "/api/inbox/bob?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if n := len(decodeJSON[inboxResponse](t, resp).Messages); n != 0 {
+		t.Fatalf("snoozed message should be hidden, got %d", n)
+	}
+	resp = env.get(t, "/api/inbox/bob/counts?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if counts := decodeJSON[inboxCountsResponse](t, resp); counts.Unread != 0 {
+		t.Errorf("expected 0 unread while snoozed, got %d", counts.Unread)
+	}
+
+	resp = env.get(t, "/api/inbox/bob/snoozed?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	snoozed := decodeJSON[snoozedResponse](t, resp).Messages
+	if len(snoozed) != 1 || snoozed[0].Message.ID != msgID || snoozed[0].Until == "" {
+		t.Fatalf("unexpected snoozed list: %+v", snoozed)
+	}
+
+	resp = env.post(t, "/api/messages/"+msgID+"/unsnooze?project="+project, map[string]any{"agent": "bob"})
+	requireStatus(t, resp, http.StatusOK)
+	woke := decodeJSON[unsnoozeResponse](t, resp)
+	if woke.Cursor <= before.Cursor {
+		t.Fatalf("expected wake cursor past %d, got %d", before.Cursor, woke.Cursor)
+	}
+
+	// The message is re-delivered after the cursor bob had already consumed.
+	resp = env.get(t, fmt.Sprintf("/api/inbox/bob?project=%s&since_cursor=%d", project, before.Cursor))
+	requireStatus(t, resp, http.StatusOK)
+	after := decodeJSON[inboxResponse](t, resp).Messages
+	if len(after) != 1 || after[0].ID != msgID {
+		t.Fatalf("expected re-delivered message, got %+v", after)
+	}
+
+	resp = env.post(t, "/api/messages/"+msgID+"/unsnooze?project="+project, map[string]any{"agent": "bob"})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/textdiff"
+)
+
+// longFieldChars is the length past which a changed field also gets a
+// rendered unified diff; shorter values read fine side by side.
+const longFieldChars = 80
+
+// specFieldDiff is one changed field between two spec versions. Unified is
+// set for long or multi-line text fields.
+type specFieldDiff struct {
+	Field   string `json:"field"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Unified string `json:"unified,omitempty"`
+}
+
+type specDiffResponse struct {
+	SpecID  string          `json:"spec_id"`
+	From    int64           `json:"from"`
+	To      int64           `json:"to"`
+	Changes []specFieldDiff `json:"changes"`
+}
+
+// diffSpec compares two recorded versions of a spec field by field.
+// ?from defaults to the version before ?to, and ?to to the current one.
+func (s *DomainService) diffSpec(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	current, err := s.domainStore.GetSpec(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+
+	to, ok := parseVersionParam(w, r, "to", current.Version)
+	if !ok {
+		return
+	}
+	from, ok := parseVersionParam(w, r, "from", to-1)
+	if !ok {
+		return
+	}
+	if from < 1 || to > current.Version || from > to {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("versions must satisfy 1 <= from <= to <= %d", current.Version), "invalid_range")
+		return
+	}
+
+	a, err := s.domainStore.GetSpecRevision(r.Context(), project, id, from)
+	if err != nil {
+		writeRevisionError(w, err, from)
+		return
+	}
+	b, err := s.domainStore.GetSpecRevision(r.Context(), project, id, to)
+	if err != nil {
+		writeRevisionError(w, err, to)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(specDiffResponse{
+		SpecID:  id,
+		From:    from,
+		To:      to,
+		Changes: diffSpecFields(a, b),
+	})
+}
+
+func parseVersionParam(w http.ResponseWriter, r *http.Request, key string, def int64) (int64, bool) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return def, true
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid "+key+" version", "invalid_range")
+		return 0, false
+	}
+	return v, true
+}
+
+func writeRevisionError(w http.ResponseWriter, err error, version int64) {
+	if errors.Is(err, core.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("version %d was not recorded", version), "revision_not_found")
+		return
+	}
+	writeInternalError(w)
+}
+
+// diffSpecFields lists the content fields that differ between a and b, in
+// the spec's field order.
+func diffSpecFields(a, b core.Spec) []specFieldDiff {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"title", a.Title, b.Title},
+		{"vision", a.Vision, b.Vision},
+		{"users", a.Users, b.Users},
+		{"problem", a.Problem, b.Problem},
+		{"status", string(a.Status), string(b.Status)},
+	}
+	changes := []specFieldDiff{}
+	for _, f := range fields {
+		if f.from == f.to {
+			continue
+		}
+		d := specFieldDiff{Field: f.name, From: f.from, To: f.to}
+		if isLongText(f.from) || isLongText(f.to) {
+			d.Unified = textdiff.Unified(f.from, f.to,
+				fmt.Sprintf("%s@v%d", f.name, a.Version), fmt.Sprintf("%s@v%d", f.name, b.Version))
+		}
+		changes = append(changes, d)
+	}
+	return changes
+}
+
+func isLongText(s string) bool {
+	return len(s) > longFieldChars || strings.Contains(s, "\n")
+}
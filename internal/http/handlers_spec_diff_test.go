@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestSpecDiffBetweenVersions(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": project, "title": "Checkout", "vision": "line one\nline two\nline three"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+
+	edits := []map[string]any{
+		{"title": "Checkout", "vision": "line one\nline 2\nline three", "status": "draft"},
+		{"title": "Checkout v2", "vision": "line one\nline 2\nline three", "status": "research"},
+	}
+	for _, edit := range edits {
+		edit["project"] = project
+		edit["version"] = spec.Version
+		resp = env.put(t, "/api/specs/"+spec.ID, edit)
+		requireStatus(t, resp, http.StatusOK)
+		spec = decodeJSON[core.Spec](t, resp)
+	}
+
+	resp = env.get(t, "/api/specs/"+spec.ID+"/diff?project="+project+"&from=1&to=3")
+	requireStatus(t, resp, http.StatusOK)
+	diff := decodeJSON[specDiffResponse](t, resp)
+	if diff.From != 1 || diff.To != 3 || len(diff.Changes) != 3 {
+		t.Fatalf("expected title, vision and status changes from v1 to v3, got %+v", diff)
+	}
+	if diff.Changes[0].Field != "title" || diff.Changes[0].To != "Checkout v2" || diff.Changes[0].Unified != "" {
+		t.Fatalf("unexpected title change: %+v", diff.Changes[0])
+	}
+	vision := diff.Changes[1]
+	if vision.Field != "vision" || !strings.Contains(vision.Unified, "-line two\n+line 2\n") {
+		t.Fatalf("expected unified diff for multi-line vision, got %+v", vision)
+	}
+
+	// Defaults compare the current version with the one before it.
+	resp = env.get(t, "/api/specs/"+spec.ID+"/diff?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	diff = decodeJSON[specDiffResponse](t, resp)
+	if diff.From != 2 || diff.To != 3 || len(diff.Changes) != 2 {
+		t.Fatalf("expected title and status changes from v2 to v3, got %+v", diff)
+	}
+
+	resp = env.get(t, "/api/specs/"+spec.ID+"/diff?project="+project+"&from=3&to=9")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+}
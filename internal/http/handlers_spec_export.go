@@ -0,0 +1,160 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// exportSpec serves GET /api/specs/{id}/export?format=markdown: the spec
+// with its epics, their stories and acceptance criteria, and its CUJs as
+// one Markdown document for design reviews.
+func (s *DomainService) exportSpec(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ThreadExportMarkdown
+	}
+	if format != ThreadExportMarkdown {
+		writeJSONError(w, http.StatusBadRequest, "unsupported format: "+format, "unsupported_format")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+
+	ctx := r.Context()
+	spec, err := s.domainStore.GetSpec(ctx, project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	epics, err := s.domainStore.ListEpics(ctx, project, spec.ID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	stories := make(map[string][]core.Story, len(epics))
+	for _, e := range epics {
+		if stories[e.ID], err = s.domainStore.ListStories(ctx, project, e.ID); err != nil {
+			writeInternalError(w)
+			return
+		}
+	}
+	cujs, err := s.domainStore.ListCUJs(ctx, project, spec.ID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write([]byte(specMarkdown(spec, epics, stories, cujs)))
+}
+
+// specMarkdown renders the spec's own sections, then each epic with its
+// stories in rank order, then the CUJs. Empty sections are left out.
+func specMarkdown(spec core.Spec, epics []core.Epic, stories map[string][]core.Story, cujs []core.CriticalUserJourney) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", spec.Title)
+	fmt.Fprintf(&b, "_%s · %s · version %d · updated %s_\n", entityLabel(spec.ShortID, spec.ID), spec.Status, spec.Version, spec.UpdatedAt.UTC().Format("2006-01-02"))
+	for _, sec := range []struct{ title, body string }{
+		{"Vision", spec.Vision},
+		{"Users", spec.Users},
+		{"Problem", spec.Problem},
+	} {
+		if body := strings.TrimSpace(sec.body); body != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", sec.title, body)
+		}
+	}
+
+	if len(epics) > 0 {
+		b.WriteString("\n## Epics\n")
+	}
+	for _, e := range epics {
+		fmt.Fprintf(&b, "\n### %s\n\n", e.Title)
+		fmt.Fprintf(&b, "_%s · %s_\n", entityLabel(e.ShortID, e.ID), e.Status)
+		if desc := strings.TrimSpace(e.Description); desc != "" {
+			b.WriteString("\n" + desc + "\n")
+		}
+		for _, st := range stories[e.ID] {
+			fmt.Fprintf(&b, "\n#### %s\n\n", st.Title)
+			meta := []string{entityLabel(st.ShortID, st.ID), string(st.Status)}
+			if st.Priority != "" {
+				meta = append(meta, string(st.Priority)+" priority")
+			}
+			fmt.Fprintf(&b, "_%s_\n", strings.Join(meta, " · "))
+			if len(st.AcceptanceCriteria) > 0 {
+				b.WriteString("\nAcceptance criteria:\n\n")
+				for _, ac := range st.AcceptanceCriteria {
+					fmt.Fprintf(&b, "- [ ] %s\n", ac)
+				}
+			}
+		}
+	}
+
+	if len(cujs) > 0 {
+		b.WriteString("\n## Critical User Journeys\n")
+	}
+	for _, c := range cujs {
+		fmt.Fprintf(&b, "\n### %s\n\n", c.Title)
+		meta := []string{string(c.Status)}
+		if c.Priority != "" {
+			meta = append(meta, string(c.Priority)+" priority")
+		}
+		if c.Persona != "" {
+			meta = append(meta, "persona: "+c.Persona)
+		}
+		fmt.Fprintf(&b, "_%s_\n", strings.Join(meta, " · "))
+		if c.EntryPoint != "" || c.ExitPoint != "" {
+			b.WriteString("\n")
+			if c.EntryPoint != "" {
+				fmt.Fprintf(&b, "- **Entry:** %s\n", c.EntryPoint)
+			}
+			if c.ExitPoint != "" {
+				fmt.Fprintf(&b, "- **Exit:** %s\n", c.ExitPoint)
+			}
+		}
+		if len(c.Steps) > 0 {
+			b.WriteString("\nSteps:\n\n")
+			for i, step := range c.Steps {
+				fmt.Fprintf(&b, "%d. %s", i+1, step.Action)
+				if step.Expected != "" {
+					fmt.Fprintf(&b, " → %s", step.Expected)
+				}
+				b.WriteString("\n")
+				for _, alt := range step.Alternatives {
+					fmt.Fprintf(&b, "   - Alternative: %s\n", alt)
+				}
+			}
+		}
+		writeMarkdownList(&b, "Success criteria", c.SuccessCriteria)
+		writeMarkdownList(&b, "Error recovery", c.ErrorRecovery)
+	}
+	return b.String()
+}
+
+func writeMarkdownList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s:\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+// entityLabel prefers the short ID, which reads better in a document.
+func entityLabel(shortID, id string) string {
+	if shortID != "" {
+		return shortID
+	}
+	return id
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestExportSpecMarkdown(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": project, "title": "Checkout", "vision": "One-click buying", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+
+	resp = env.post(t, "/api/epics", map[string]any{"project": project, "spec_id": spec.ID, "title": "Payments"})
+	requireStatus(t, resp, http.StatusCreated)
+	epic := decodeJSON[core.Epic](t, resp)
+
+	resp = env.post(t, "/api/stories", map[string]any{
+		"project": project, "epic_id": epic.ID, "title": "Card form",
+		"acceptance_criteria": []string{"Rejects expired cards", "Masks the number"},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/cujs", map[string]any{
+		"project": project, "spec_id": spec.ID, "title": "Buy a book", "priority": "high",
+		"steps": []map[string]any{{"order": 1, "action": "Open cart", "expected": "Cart shows items"}},
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/specs/"+spec.ID+"/export?format=markdown&project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Errorf("content type = %q", ct)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	doc := string(raw)
+	for _, want := range []string{
+		"# Checkout\n",
+		"## Vision\n\nOne-click buying\n",
+		"### Payments\n",
+		"#### Card form\n",
+		"- [ ] Rejects expired cards\n",
+		"## Critical User Journeys\n",
+		"### Buy a book\n",
+		"1. Open cart → Cart shows items\n",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("export missing %q:\n%s", want, doc)
+		}
+	}
+	if strings.Index(doc, "## Epics") > strings.Index(doc, "## Critical User Journeys") {
+		t.Error("epics should come before CUJs")
+	}
+
+	resp = env.get(t, "/api/specs/"+spec.ID+"/export?format=pdf&project="+project)
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/specs/missing/export?project="+project)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
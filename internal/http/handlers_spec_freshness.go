@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// reviewSpec marks validated spec id reviewed, restarting its staleness
+// window, for planners that re-validated it without changing it.
+func (s *DomainService) reviewSpec(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	spec, err := s.domainStore.GetSpec(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	if spec.Status != core.SpecStatusValidated {
+		writeJSONError(w, http.StatusConflict, "only validated specs can be reviewed", "spec_not_validated")
+		return
+	}
+	reviewed, err := s.domainStore.ReviewSpec(r.Context(), project, id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), project, core.EventSpecUpdated, reviewed.ID, reviewed)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviewed)
+}
+
+// markStale sets Stale on each spec by its project's staleness window.
+// Projects are listed rather than looked up one by one: specs may belong
+// to projects that were never registered, which use the default window.
+func (s *DomainService) markStale(ctx context.Context, specs []core.Spec) error {
+	if len(specs) == 0 {
+		return nil
+	}
+	projects, err := s.domainStore.ListProjects(ctx, true)
+	if err != nil {
+		return err
+	}
+	days := make(map[string]int, len(projects))
+	for _, p := range projects {
+		days[p.Name] = p.SpecStaleDays
+	}
+	now := clock.Now().UTC()
+	for i := range specs {
+		specs[i].Stale = specs[i].IsStale(now, core.SpecStaleWindow(days[specs[i].Project]))
+	}
+	return nil
+}
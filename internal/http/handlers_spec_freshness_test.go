@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestSpecStaleness(t *testing.T) {
+	env := newTestEnv(t)
+	t.Cleanup(clock.Reset)
+	const project = "proj"
+
+	resp := env.post(t, "/api/projects", map[string]any{"name": project, "template": "empty"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.patch(t, "/api/projects/"+project, map[string]any{"spec_stale_days": -1})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = env.patch(t, "/api/projects/"+project, map[string]any{"spec_stale_days": 14})
+	requireStatus(t, resp, http.StatusOK)
+	if p := decodeJSON[core.Project](t, resp); p.SpecStaleDays != 14 {
+		t.Fatalf("patched project = %+v, want spec_stale_days 14", p)
+	}
+
+	resp = env.post(t, "/api/specs", map[string]any{"project": project, "title": "Checkout", "status": "validated"})
+	requireStatus(t, resp, http.StatusCreated)
+	validated := decodeJSON[core.Spec](t, resp)
+	resp = env.post(t, "/api/specs", map[string]any{"project": project, "title": "Search", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	draft := decodeJSON[core.Spec](t, resp)
+
+	resp = env.post(t, "/api/specs/"+draft.ID+"/review?project="+project, map[string]any{})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	listStale := func() []core.Spec {
+		t.Helper()
+		resp := env.get(t, "/api/specs?project="+project+"&stale=true")
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[[]core.Spec](t, resp)
+	}
+	if got := listStale(); len(got) != 0 {
+		t.Fatalf("stale specs before the window = %+v", got)
+	}
+
+	clock.Advance(15 * 24 * time.Hour)
+	got := listStale()
+	if len(got) != 1 || got[0].ID != validated.ID || !got[0].Stale {
+		t.Fatalf("stale specs = %+v, want only the validated spec", got)
+	}
+	resp = env.get(t, "/api/specs/"+validated.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if spec := decodeJSON[core.Spec](t, resp); !spec.Stale {
+		t.Fatalf("GET spec = %+v, want stale", spec)
+	}
+
+	resp = env.post(t, "/api/specs/"+validated.ID+"/review?project="+project, map[string]any{})
+	requireStatus(t, resp, http.StatusOK)
+	reviewed := decodeJSON[core.Spec](t, resp)
+	if reviewed.Stale || reviewed.Version != validated.Version || !reviewed.LastReviewedAt.After(*validated.LastReviewedAt) {
+		t.Fatalf("reviewed spec = %+v", reviewed)
+	}
+	if got := listStale(); len(got) != 0 {
+		t.Fatalf("stale specs after review = %+v", got)
+	}
+}
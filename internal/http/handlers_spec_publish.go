@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// publishedSpecResponse adds the stable permalink to a published snapshot.
+type publishedSpecResponse struct {
+	core.PublishedSpec
+	Permalink string `json:"permalink"`
+}
+
+func publishedPermalink(p core.PublishedSpec) string {
+	return fmt.Sprintf("/api/specs/%s/published/%d?project=%s",
+		url.PathEscape(p.SpecID), p.Number, url.QueryEscape(p.Project))
+}
+
+func toPublishedResponse(p core.PublishedSpec) publishedSpecResponse {
+	return publishedSpecResponse{PublishedSpec: p, Permalink: publishedPermalink(p)}
+}
+
+// publishSpec snapshots a validated spec and its CUJs as the next immutable
+// published version. Specs in any other status are rejected with 409.
+func (s *DomainService) publishSpec(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	spec, err := s.domainStore.GetSpec(r.Context(), project, id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	if spec.Status != core.SpecStatusValidated {
+		writeJSONError(w, http.StatusConflict, "only validated specs can be published", "spec_not_validated")
+		return
+	}
+	pub, err := s.domainStore.PublishSpec(r.Context(), project, id, info.AgentID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	resp := toPublishedResponse(pub)
+	s.broadcastDomainEvent(r.Context(), project, core.EventSpecPublished, id, map[string]any{
+		"number":    pub.Number,
+		"permalink": resp.Permalink,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *DomainService) listPublishedSpecs(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	versions, err := s.domainStore.ListPublishedSpecs(r.Context(), project, id)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	out := make([]publishedSpecResponse, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, toPublishedResponse(v))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func (s *DomainService) getPublishedSpec(w http.ResponseWriter, r *http.Request, id, rawNumber string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	number, err := strconv.Atoi(rawNumber)
+	if err != nil || number <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "publication number must be a positive integer", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	pub, err := s.domainStore.GetPublishedSpec(r.Context(), project, id, number)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	// Published versions never change, so clients and proxies may cache
+	// them indefinitely.
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toPublishedResponse(pub))
+}
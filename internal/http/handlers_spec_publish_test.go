@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPublishSpecSnapshots(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/specs", map[string]any{"project": project, "title": "Checkout", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[map[string]any](t, resp)
+	specID := spec["id"].(string)
+
+	resp = env.post(t, "/api/cujs", map[string]any{"project": project, "spec_id": specID, "title": "Buy", "priority": "high"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	// Drafts cannot be published.
+	resp = env.post(t, "/api/specs/"+specID+"/publish?project="+project, map[string]any{})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	resp = env.put(t, "/api/specs/"+specID, map[string]any{
+		"project": project, "title": "Checkout", "status": "validated", "version": spec["version"],
+	})
+	requireStatus(t, resp, http.StatusOK)
+	updated := decodeJSON[map[string]any](t, resp)
+
+	resp = env.post(t, "/api/specs/"+specID+"/publish?project="+project, map[string]any{})
+	requireStatus(t, resp, http.StatusCreated)
+	pub := decodeJSON[publishedSpecResponse](t, resp)
+	if pub.Number != 1 || len(pub.CUJs) != 1 || pub.Spec.Title != "Checkout" {
+		t.Fatalf("unexpected first publish: %+v", pub)
+	}
+
+	// Later edits don't alter the snapshot.
+	resp = env.put(t, "/api/specs/"+specID, map[string]any{
+		"project": project, "title": "Checkout v2", "status": "validated", "version": updated["version"],
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.post(t, "/api/specs/"+specID+"/publish?project="+project, map[string]any{})
+	requireStatus(t, resp, http.StatusCreated)
+	if second := decodeJSON[publishedSpecResponse](t, resp); second.Number != 2 {
+		t.Fatalf("expected version 2, got %d", second.Number)
+	}
+
+	resp = env.get(t, pub.Permalink)
+	requireStatus(t, resp, http.StatusOK)
+	if resp.Header.Get("Cache-Control") == "" {
+		t.Fatal("expected immutable Cache-Control on permalink")
+	}
+	frozen := decodeJSON[publishedSpecResponse](t, resp)
+	if frozen.Spec.Title != "Checkout" {
+		t.Fatalf("permalink should return frozen title, got %q", frozen.Spec.Title)
+	}
+
+	resp = env.get(t, "/api/specs/"+specID+"/published?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if list := decodeJSON[[]publishedSpecResponse](t, resp); len(list) != 2 {
+		t.Fatalf("expected 2 published versions, got %d", len(list))
+	}
+
+	resp = env.get(t, "/api/specs/"+specID+"/published/9?project="+project)
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
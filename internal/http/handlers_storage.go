@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Storage alert levels, in increasing severity.
+const (
+	StorageLevelOK       = "ok"
+	StorageLevelWarn     = "warn"
+	StorageLevelCritical = "critical"
+)
+
+// maxAlertSuggestions caps the pruning suggestions attached to an alert.
+const maxAlertSuggestions = 3
+
+// StorageLimits are soft database size thresholds in bytes. Zero disables
+// a threshold. Crossing one raises an alert; nothing is ever refused.
+type StorageLimits struct {
+	WarnBytes     int64 `json:"warn_bytes,omitempty"`
+	CriticalBytes int64 `json:"critical_bytes,omitempty"`
+}
+
+// Level classifies size against the limits.
+func (l StorageLimits) Level(size int64) string {
+	switch {
+	case l.CriticalBytes > 0 && size >= l.CriticalBytes:
+		return StorageLevelCritical
+	case l.WarnBytes > 0 && size >= l.WarnBytes:
+		return StorageLevelWarn
+	default:
+		return StorageLevelOK
+	}
+}
+
+func (l StorageLimits) threshold(level string) int64 {
+	if level == StorageLevelCritical {
+		return l.CriticalBytes
+	}
+	return l.WarnBytes
+}
+
+func storageSeverity(level string) int {
+	switch level {
+	case StorageLevelCritical:
+		return 2
+	case StorageLevelWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type storageResponse struct {
+	core.StorageReport
+	Limits StorageLimits `json:"limits"`
+	Level  string        `json:"level"`
+}
+
+// WithStorageLimits sets the database size thresholds reported by
+// /api/admin/storage and checked by the StorageMonitor.
+func (s *DomainService) WithStorageLimits(limits StorageLimits) *DomainService {
+	s.storage = limits
+	return s
+}
+
+// handleAdminStorage reports table sizes, the current alert level and
+// pruning suggestions ranked by reclaimable space.
+func (s *DomainService) handleAdminStorage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	report, err := s.domainStore.StorageReport(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(storageResponse{
+		StorageReport: report,
+		Limits:        s.storage,
+		Level:         s.storage.Level(report.DBSizeBytes),
+	})
+}
+
+// handleAdminCounts returns the row counts of the main tables, for
+// comparing a backup against the live database.
+func (s *DomainService) handleAdminCounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	counts, err := s.domainStore.TableCounts(r.Context())
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"counts": counts})
+}
+
+// CheckStorage compares the database size with the limits and returns the
+// current level. When it is more severe than previous, the crossing is
+// logged and broadcast to every project as a storage.size_threshold event
+// carrying the top pruning suggestions, and sent to the system topic.
+func (s *DomainService) CheckStorage(ctx context.Context, previous string) (string, error) {
+	size, err := s.domainStore.DBSizeBytes(ctx)
+	if err != nil {
+		return previous, err
+	}
+	level := s.storage.Level(size)
+	if storageSeverity(level) <= storageSeverity(previous) {
+		return level, nil
+	}
+
+	threshold := s.storage.threshold(level)
+	log.Printf("WARN: database size %d bytes crossed the %s threshold (%d bytes)", size, level, threshold)
+	data := map[string]any{
+		"level":           level,
+		"db_size_bytes":   size,
+		"threshold_bytes": threshold,
+	}
+	if report, err := s.domainStore.StorageReport(ctx); err != nil {
+		log.Printf("WARN: storage report: %v", err)
+	} else {
+		suggestions := report.Suggestions
+		if len(suggestions) > maxAlertSuggestions {
+			suggestions = suggestions[:maxAlertSuggestions]
+		}
+		data["suggestions"] = suggestions
+		for _, sg := range suggestions {
+			log.Printf("WARN:   %s would reclaim ~%d bytes (%d rows): %s", sg.Policy, sg.EstimatedBytes, sg.Rows, sg.Description)
+		}
+	}
+	s.broadcastDomainEvent(ctx, "", core.EventStorageThreshold, "database", data)
+	s.notifySystem(core.EventSystemStorageThreshold, data)
+	return level, nil
+}
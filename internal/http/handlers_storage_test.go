@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestAdminStorageReport(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	old := time.Now().UTC().AddDate(0, 0, -45)
+	for i := 0; i < 20; i++ {
+		msg := core.Message{ID: "old-" + string(rune('a'+i)), Project: "proj", From: "alice", To: []string{"bob"}, Body: "stale"}
+		if _, err := st.AppendEvent(context.Background(), core.Event{Type: core.EventMessageCreated, Message: msg, CreatedAt: old}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	svc := NewDomainService(st).WithStorageLimits(StorageLimits{WarnBytes: 1})
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+	env := &testEnv{srv: srv, store: st}
+
+	resp := env.get(t, "/api/admin/storage")
+	requireStatus(t, resp, http.StatusOK)
+	report := decodeJSON[storageResponse](t, resp)
+	if report.DBSizeBytes <= 0 || len(report.Tables) == 0 {
+		t.Fatalf("expected table sizes, got %+v", report)
+	}
+	if report.Level != StorageLevelWarn {
+		t.Fatalf("expected warn level with a 1-byte limit, got %q", report.Level)
+	}
+	var found bool
+	for _, sg := range report.Suggestions {
+		if sg.Policy == "events_30d" && sg.Rows == 20 && sg.EstimatedBytes > 0 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected events_30d suggestion covering 20 rows, got %+v", report.Suggestions)
+	}
+}
+
+func TestCheckStorageAlertsOncePerLevel(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	bus := &recordingBus{}
+	svc := NewDomainService(st).WithBroadcaster(bus).WithStorageLimits(StorageLimits{WarnBytes: 1, CriticalBytes: 1 << 40})
+	ctx := context.Background()
+
+	level, err := svc.CheckStorage(ctx, StorageLevelOK)
+	if err != nil || level != StorageLevelWarn {
+		t.Fatalf("expected warn, got %q (%v)", level, err)
+	}
+	if level, _ = svc.CheckStorage(ctx, level); level != StorageLevelWarn {
+		t.Fatalf("expected warn to persist, got %q", level)
+	}
+	events := bus.ofType(core.EventStorageThreshold)
+	if len(events) != 1 {
+		t.Fatalf("expected a single alert while staying at warn, got %d", len(events))
+	}
+	if data, _ := events[0]["data"].(map[string]any); data["level"] != StorageLevelWarn {
+		t.Fatalf("unexpected alert payload: %v", events[0])
+	}
+}
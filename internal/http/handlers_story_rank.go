@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// rankStoryRequest places a story between two others in its epic. After is
+// the story it should follow and Before the one it should precede; give
+// either or both.
+type rankStoryRequest struct {
+	Project string `json:"project"`
+	After   string `json:"after,omitempty"`
+	Before  string `json:"before,omitempty"`
+}
+
+// rankStory handles PUT /api/stories/{id}/rank. Only the moved story's
+// rank changes, so drag-and-drop reordering is one small write however
+// long the epic is.
+func (s *DomainService) rankStory(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPut {
+		writeMethodNotAllowed(w)
+		return
+	}
+	var req rankStoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON body", "invalid_request")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if info.Mode == auth.ModeAPIKey {
+		if req.Project != "" && req.Project != info.Project {
+			writeProjectMismatch(w)
+			return
+		}
+		req.Project = info.Project
+	}
+	if req.Project == "" {
+		req.Project = r.URL.Query().Get("project")
+	}
+	if req.After == "" && req.Before == "" {
+		writeJSONError(w, http.StatusBadRequest, "after or before is required", "invalid_request")
+		return
+	}
+	resolve := func(ref string) string {
+		if ref == "" {
+			return ""
+		}
+		return resolveShortID(r.Context(), s.domainStore, req.Project, "story", ref)
+	}
+	updated, err := s.domainStore.RankStory(r.Context(), req.Project, id, resolve(req.After), resolve(req.Before))
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		writeNotFound(w)
+		return
+	case errors.Is(err, core.ErrInvalidRank):
+		writeJSONError(w, http.StatusBadRequest, "after and before must be other stories of the same epic, in order", "invalid_rank")
+		return
+	case err != nil:
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), updated.Project, core.EventStoryUpdated, updated.ID, updated)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
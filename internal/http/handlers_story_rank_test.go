@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestStoryRankReorders(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/epics", map[string]any{"project": "proj", "title": "Epic"})
+	requireStatus(t, resp, http.StatusCreated)
+	epic := decodeJSON[core.Epic](t, resp)
+
+	ids := map[string]string{}
+	for _, title := range []string{"a", "b", "c"} {
+		resp := env.post(t, "/api/stories", map[string]any{"project": "proj", "epic_id": epic.ID, "title": title})
+		requireStatus(t, resp, http.StatusCreated)
+		story := decodeJSON[core.Story](t, resp)
+		if story.Rank == "" {
+			t.Fatalf("story %s created without a rank", title)
+		}
+		ids[title] = story.ID
+	}
+	order := func() string {
+		t.Helper()
+		resp := env.get(t, "/api/stories?project=proj&epic="+epic.ID)
+		requireStatus(t, resp, http.StatusOK)
+		var titles []string
+		for _, s := range decodeJSON[[]core.Story](t, resp) {
+			titles = append(titles, s.Title)
+		}
+		return strings.Join(titles, "")
+	}
+
+	resp = env.put(t, "/api/stories/"+ids["c"]+"/rank", map[string]any{"project": "proj", "before": ids["a"]})
+	requireStatus(t, resp, http.StatusOK)
+	if moved := decodeJSON[core.Story](t, resp); moved.ID != ids["c"] || moved.Rank == "" {
+		t.Fatalf("expected the ranked story back, got %+v", moved)
+	}
+	if got := order(); got != "cab" {
+		t.Fatalf("order = %s, want cab", got)
+	}
+
+	resp = env.put(t, "/api/stories/"+ids["a"]+"/rank", map[string]any{"project": "proj", "after": ids["b"]})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if got := order(); got != "cba" {
+		t.Fatalf("order = %s, want cba", got)
+	}
+
+	resp = env.put(t, "/api/stories/"+ids["a"]+"/rank", map[string]any{"project": "proj"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = env.put(t, "/api/stories/"+ids["a"]+"/rank", map[string]any{"project": "proj", "after": ids["b"], "before": ids["c"]})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = env.put(t, "/api/stories/missing/rank", map[string]any{"project": "proj", "after": ids["b"]})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
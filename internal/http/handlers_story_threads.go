@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// WithStoryThreads enables mirroring task assignments, blocks and
+// completions as system messages into their story's thread (see
+// core.StoryThreadID) for every project. Individual projects can opt in
+// with the core.FlagStoryThreads feature flag instead.
+func (s *DomainService) WithStoryThreads(enabled bool) *DomainService {
+	s.storyThreads = enabled
+	return s
+}
+
+func (s *DomainService) storyThreadsEnabled(ctx context.Context, project string) bool {
+	return s.storyThreads || s.flagEnabled(ctx, project, core.FlagStoryThreads)
+}
+
+// taskChange is a significant task transition worth narrating.
+type taskChange string
+
+const (
+	taskAssigned  taskChange = "assigned"
+	taskBlocked   taskChange = "blocked"
+	taskCompleted taskChange = "completed"
+)
+
+// mirrorTaskChange posts the significant transitions between before and
+// after into the story thread. before is nil when the previous state is
+// unknown, in which case any current assignment, block or completion is
+// treated as new.
+func (s *DomainService) mirrorTaskChange(ctx context.Context, before *core.Task, after core.Task) {
+	if after.StoryID == "" || !s.storyThreadsEnabled(ctx, after.Project) {
+		return
+	}
+	if after.Agent != "" && (before == nil || before.Agent != after.Agent) {
+		s.mirrorTaskEvent(ctx, taskAssigned, after)
+	}
+	if before != nil && before.Status == after.Status {
+		return
+	}
+	switch after.Status {
+	case core.TaskStatusBlocked:
+		s.mirrorTaskEvent(ctx, taskBlocked, after)
+	case core.TaskStatusDone:
+		s.mirrorTaskEvent(ctx, taskCompleted, after)
+	}
+}
+
+// mirrorTaskEvent posts one task change into the story thread, addressed to
+// everyone already in that thread (plus the assignee on assignment). Tasks
+// outside a story are not mirrored. Failures are logged, never surfaced:
+// the narrative must not block the update it describes.
+func (s *DomainService) mirrorTaskEvent(ctx context.Context, change taskChange, task core.Task) {
+	if task.StoryID == "" || !s.storyThreadsEnabled(ctx, task.Project) {
+		return
+	}
+	story, err := s.domainStore.GetStory(ctx, task.Project, task.StoryID)
+	if err != nil {
+		log.Printf("WARN: story thread for task %s: %v", task.ID, err)
+		return
+	}
+	threadID := core.StoryThreadID(story.ID)
+	to, err := s.threadFollowers(ctx, task.Project, threadID)
+	if err != nil {
+		log.Printf("WARN: story thread %s: %v", threadID, err)
+		return
+	}
+	if change == taskAssigned && task.Agent != "" {
+		to = dedupeAgents(append(to, task.Agent))
+	}
+	subject := prefixSubject("Story:", story.Title)
+	if _, err := s.sendSystemThreadMessage(ctx, task.Project, threadID, to, subject, describeTaskChange(change, task)); err != nil {
+		log.Printf("WARN: story thread %s: %v", threadID, err)
+	}
+}
+
+// threadFollowers returns every agent that has sent or received a message in
+// the thread, excluding the system sender.
+func (s *Service) threadFollowers(ctx context.Context, project, threadID string) ([]string, error) {
+	msgs, err := s.store.ThreadMessages(ctx, project, threadID, 0)
+	if err != nil {
+		return nil, err
+	}
+	var agents []string
+	for _, m := range msgs {
+		agents = append(agents, m.From)
+		agents = append(agents, m.Recipients()...)
+	}
+	return withoutAgent(dedupeAgents(agents), systemSender), nil
+}
+
+func describeTaskChange(change taskChange, task core.Task) string {
+	name := fmt.Sprintf("Task %q (%s)", task.Title, task.ID)
+	switch change {
+	case taskAssigned:
+		return fmt.Sprintf("%s was assigned to %s.", name, task.Agent)
+	case taskBlocked:
+		if task.Agent == "" {
+			return name + " is blocked."
+		}
+		return fmt.Sprintf("%s is blocked (assigned to %s).", name, task.Agent)
+	default:
+		if task.Agent == "" {
+			return name + " was completed."
+		}
+		return fmt.Sprintf("%s was completed by %s.", name, task.Agent)
+	}
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestStoryThreadMirrorsTaskChanges(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	svc := NewDomainService(st).WithStoryThreads(true)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+	env := &testEnv{srv: srv, store: st}
+	const project = "proj"
+
+	resp := env.post(t, "/api/stories", map[string]any{"project": project, "epic_id": "e1", "title": "Checkout"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+	threadID := core.StoryThreadID(story.ID)
+
+	// A planner already discussing the story follows its thread.
+	resp = env.post(t, "/api/messages", map[string]any{
+		"project": project, "from": "planner", "to": []string{"lead"}, "thread_id": threadID, "body": "kicking this off",
+	})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": "Card form"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+
+	resp = env.post(t, "/api/tasks/"+task.ID+"/assign?project="+project, map[string]any{"agent": "dev"})
+	requireStatus(t, resp, http.StatusOK)
+	task = decodeJSON[core.Task](t, resp)
+
+	task.Status = core.TaskStatusBlocked
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusOK)
+	task = decodeJSON[core.Task](t, resp)
+
+	// Re-saving without a status change is not narrated again.
+	task.Title = "Card form v2"
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusOK)
+	task = decodeJSON[core.Task](t, resp)
+
+	task.Status = core.TaskStatusDone
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/threads/"+url.PathEscape(threadID)+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	msgs := decodeJSON[threadMessagesResponse](t, resp).Messages
+	if len(msgs) != 4 {
+		t.Fatalf("expected 1 human + 3 mirrored messages, got %d: %+v", len(msgs), msgs)
+	}
+	wants := []string{"was assigned to dev", "is blocked (assigned to dev)", "Card form v2\" (" + task.ID + ") was completed by dev"}
+	for i, want := range wants {
+		m := msgs[i+1]
+		if m.From != systemSender || m.Subject != "Story: Checkout" || !strings.Contains(m.Body, want) {
+			t.Errorf("message %d: expected %q from %s, got %+v", i+1, want, systemSender, m)
+		}
+	}
+
+	// Followers get the updates in their inbox; the assignee gets the assignment.
+	resp = env.get(t, "/api/inbox/lead?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if n := len(decodeJSON[inboxResponse](t, resp).Messages); n != 4 {
+		t.Errorf("lead expected 4 inbox messages, got %d", n)
+	}
+	resp = env.get(t, "/api/inbox/dev?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if n := len(decodeJSON[inboxResponse](t, resp).Messages); n == 0 {
+		t.Error("assignee should be notified of the assignment")
+	}
+}
+
+func TestStoryThreadsOffByDefault(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/stories", map[string]any{"project": project, "epic_id": "e1", "title": "Checkout"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": "Card form"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+	resp = env.post(t, "/api/tasks/"+task.ID+"/assign?project="+project, map[string]any{"agent": "dev"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/threads/"+url.PathEscape(core.StoryThreadID(story.ID))+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if n := len(decodeJSON[threadMessagesResponse](t, resp).Messages); n != 0 {
+		t.Fatalf("expected no mirrored messages when disabled, got %d", n)
+	}
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Blocked tasks. A task set to blocked may say why (blocked_reason plus
+// free-text blocked_detail) and what it waits for (unblock_when). Waits on a
+// task are checked whenever a task changes; waits on a reservation are
+// checked by the sweeper; waits on an external ref clear when someone
+// reports it resolved through POST /api/tasks/unblock. Either way the task
+// goes back to work and task.unblocked is emitted.
+
+type unblockRequest struct {
+	Project     string `json:"project"`
+	ExternalRef string `json:"external_ref"`
+}
+
+type unblockResponse struct {
+	Tasks []core.Task `json:"tasks"`
+}
+
+// validTaskBlock writes a 400 and returns false when a task's blocked
+// reason isn't known or its unblock condition doesn't name exactly one
+// thing to wait for.
+func validTaskBlock(w http.ResponseWriter, task core.Task) bool {
+	if task.BlockedReason != "" && !core.ValidBlockedReason(task.BlockedReason) {
+		writeJSONError(w, http.StatusBadRequest, "invalid blocked_reason: "+string(task.BlockedReason), "invalid_blocked_reason")
+		return false
+	}
+	c := task.UnblockWhen
+	if c == nil {
+		return true
+	}
+	set := 0
+	for _, v := range []string{c.TaskID, c.PathPattern, c.ExternalRef} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		writeJSONError(w, http.StatusBadRequest, "unblock_when needs exactly one of task_id, path_pattern, external_ref", "invalid_unblock_condition")
+		return false
+	}
+	if c.PathPattern != "" {
+		return validExpectedPaths(w, []string{c.PathPattern})
+	}
+	return true
+}
+
+// unblockReady unblocks project's tasks whose condition has cleared and
+// announces each; see UnblockReadyTasks.
+func (s *DomainService) unblockReady(ctx context.Context, project, externalRef string) []core.Task {
+	unblocked, err := s.domainStore.UnblockReadyTasks(ctx, project, externalRef)
+	if err != nil {
+		log.Printf("WARN: unblock tasks in %s: %v", project, err)
+	}
+	for _, t := range unblocked {
+		s.broadcastDomainEvent(ctx, project, core.EventTaskUnblocked, t.ID, t)
+	}
+	return unblocked
+}
+
+// afterTaskChange unblocks the tasks task's new state releases: its
+// dependents when it is done, or itself when it was blocked on something
+// that has already cleared. It returns task as it now stands.
+func (s *DomainService) afterTaskChange(ctx context.Context, task core.Task) core.Task {
+	if task.Status != core.TaskStatusDone && (task.Status != core.TaskStatusBlocked || task.UnblockWhen == nil) {
+		return task
+	}
+	for _, t := range s.unblockReady(ctx, task.Project, "") {
+		if t.ID == task.ID {
+			task = t
+		}
+	}
+	return task
+}
+
+func (s *DomainService) handleTaskUnblock(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		post: s.unblockExternal,
+	})
+}
+
+// unblockExternal serves POST /api/tasks/unblock: external_ref has been
+// resolved, so the tasks waiting on it go back to work.
+func (s *DomainService) unblockExternal(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req unblockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	if req.Project == "" {
+		req.Project = info.Project
+	}
+	if info.Mode == auth.ModeAPIKey && req.Project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	req.ExternalRef = strings.TrimSpace(req.ExternalRef)
+	if req.Project == "" || req.ExternalRef == "" {
+		writeJSONError(w, http.StatusBadRequest, "project and external_ref are required", "missing_field")
+		return
+	}
+	unblocked, err := s.domainStore.UnblockReadyTasks(r.Context(), req.Project, req.ExternalRef)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	for _, t := range unblocked {
+		s.broadcastDomainEvent(r.Context(), req.Project, core.EventTaskUnblocked, t.ID, t)
+	}
+	if unblocked == nil {
+		unblocked = []core.Task{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(unblockResponse{Tasks: unblocked})
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestTaskUnblockConditions(t *testing.T) {
+	env := newTestEnv(t)
+
+	create := func(body map[string]any) core.Task {
+		t.Helper()
+		body["project"] = "proj"
+		resp := env.post(t, "/api/tasks", body)
+		requireStatus(t, resp, http.StatusCreated)
+		return decodeJSON[core.Task](t, resp)
+	}
+	schema := create(map[string]any{"title": "schema", "status": core.TaskStatusRunning, "agent": "agent-a"})
+	api := create(map[string]any{
+		"title": "api", "status": core.TaskStatusBlocked, "agent": "agent-b",
+		"blocked_reason": core.BlockedReasonDependency, "blocked_detail": "needs the new columns",
+		"unblock_when": core.UnblockCondition{TaskID: schema.ID},
+	})
+	if api.BlockedReason != core.BlockedReasonDependency || api.UnblockWhen == nil || api.UnblockWhen.TaskID != schema.ID {
+		t.Fatalf("block not recorded: %+v", api)
+	}
+	vendor := create(map[string]any{
+		"title": "vendor fix", "status": core.TaskStatusBlocked,
+		"blocked_reason": core.BlockedReasonExternal, "unblock_when": core.UnblockCondition{ExternalRef: "GH-42"},
+	})
+
+	// Finishing schema releases api, which goes back to its agent.
+	schema.Status = core.TaskStatusDone
+	resp := env.put(t, "/api/tasks/"+schema.ID, schema)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	resp = env.get(t, "/api/tasks/"+api.ID+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[core.Task](t, resp)
+	if got.Status != core.TaskStatusRunning || got.BlockedReason != "" || got.UnblockWhen != nil {
+		t.Fatalf("api after schema done = %+v", got)
+	}
+	resp = env.get(t, "/api/events?project=proj&entity_type=task&entity_id="+api.ID)
+	requireStatus(t, resp, http.StatusOK)
+	events := decodeJSON[listEventsResponse](t, resp).Events
+	if n := len(events); n == 0 || events[n-1].Type != string(core.EventTaskUnblocked) {
+		t.Fatalf("events = %+v, want task.unblocked last", events)
+	}
+
+	// An external ref clears only when reported.
+	resp = env.post(t, "/api/tasks/unblock", map[string]any{"project": "proj", "external_ref": "GH-7"})
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[unblockResponse](t, resp).Tasks; len(got) != 0 {
+		t.Fatalf("unrelated ref unblocked %+v", got)
+	}
+	resp = env.post(t, "/api/tasks/unblock", map[string]any{"project": "proj", "external_ref": "GH-42"})
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[unblockResponse](t, resp).Tasks; len(got) != 1 || got[0].ID != vendor.ID || got[0].Status != core.TaskStatusPending {
+		t.Fatalf("unblocked = %+v, want vendor fix pending", got)
+	}
+
+	// A task blocked on one that's already done never stays blocked.
+	late := create(map[string]any{
+		"title": "late", "status": core.TaskStatusBlocked, "unblock_when": core.UnblockCondition{TaskID: schema.ID},
+	})
+	if late.Status != core.TaskStatusPending {
+		t.Fatalf("late = %+v, want pending", late)
+	}
+
+	for _, body := range []map[string]any{
+		{"title": "bad reason", "status": core.TaskStatusBlocked, "blocked_reason": "bored"},
+		{"title": "two conditions", "status": core.TaskStatusBlocked, "unblock_when": core.UnblockCondition{TaskID: "x", ExternalRef: "y"}},
+		{"title": "bad pattern", "status": core.TaskStatusBlocked, "unblock_when": core.UnblockCondition{PathPattern: "a/[b"}},
+	} {
+		body["project"] = "proj"
+		resp := env.post(t, "/api/tasks", body)
+		requireStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	}
+}
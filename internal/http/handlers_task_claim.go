@@ -0,0 +1,226 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/anomaly"
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// maxClaimTasks caps how many tasks one claim can take.
+const maxClaimTasks = 50
+
+// maxTaskLease caps a claim lease, like the reservation TTL.
+const maxTaskLease = 24 * time.Hour
+
+// claimTasksRequest is the body of POST /api/tasks/claim. Agent defaults to
+// the calling agent; Capabilities default to the agent's registered ones.
+// Count is how many tasks a batch worker wants (Limit is its older name).
+// LeaseSeconds puts the claimed tasks under one shared lease.
+type claimTasksRequest struct {
+	Project      string   `json:"project"`
+	Agent        string   `json:"agent"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Count        int      `json:"count,omitempty"`
+	Limit        int      `json:"limit,omitempty"`
+	Steal        bool     `json:"steal,omitempty"`
+	LeaseSeconds int      `json:"lease_seconds,omitempty"`
+}
+
+type claimTasksResponse struct {
+	Tasks []core.Task     `json:"tasks"`
+	Lease *core.TaskLease `json:"lease,omitempty"`
+}
+
+// leaseDuration converts a requested lease length, capped at maxTaskLease.
+func leaseDuration(seconds int) time.Duration {
+	d := time.Duration(seconds) * time.Second
+	if d > maxTaskLease {
+		d = maxTaskLease
+	}
+	return d
+}
+
+func (s *DomainService) handleTaskClaim(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		post: s.claimTasks,
+	})
+}
+
+// claimTasks assigns the oldest pending tasks the agent can do to it in one
+// transaction, replacing the ListTasks + assign race. A claim that finds
+// fewer than asked returns what it found, and one that finds nothing an
+// empty list (and no lease).
+func (s *DomainService) claimTasks(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req claimTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	project, ok := groupProject(w, r, req.Project)
+	if !ok {
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	agent := req.Agent
+	if agent == "" {
+		agent = info.AgentID
+	}
+	if agent == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent required", "invalid_request")
+		return
+	}
+	if info.AgentID != "" && agent != info.AgentID {
+		writeJSONError(w, http.StatusForbidden, "not allowed for this agent", "forbidden")
+		return
+	}
+	if req.Count > 0 {
+		req.Limit = req.Count
+	}
+	if req.Limit > maxClaimTasks {
+		req.Limit = maxClaimTasks
+	}
+	if req.LeaseSeconds < 0 {
+		writeJSONError(w, http.StatusBadRequest, "lease_seconds must be positive", "invalid_request")
+		return
+	}
+	caps := req.Capabilities
+	if caps == nil {
+		agents, err := s.domainStore.ListAgents(r.Context(), project, nil)
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		for _, a := range agents {
+			if a.ID == agent {
+				caps = a.Capabilities
+				break
+			}
+		}
+	}
+
+	claim := core.TaskClaim{
+		Project:      project,
+		Agent:        agent,
+		Capabilities: caps,
+		Steal:        req.Steal,
+		Limit:        req.Limit,
+	}
+	if req.LeaseSeconds > 0 {
+		claim.LeaseID = uuid.NewString()
+		claim.LeaseExpiresAt = clock.Now().UTC().Add(leaseDuration(req.LeaseSeconds))
+	}
+	claimed, err := s.domainStore.ClaimTasks(r.Context(), claim)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	resp := claimTasksResponse{Tasks: claimed}
+	if claim.LeaseID != "" && len(claimed) > 0 {
+		resp.Lease = &core.TaskLease{
+			ID:        claim.LeaseID,
+			Project:   project,
+			Agent:     agent,
+			ExpiresAt: claim.LeaseExpiresAt,
+		}
+		for _, task := range claimed {
+			resp.Lease.TaskIDs = append(resp.Lease.TaskIDs, task.ID)
+		}
+	}
+	for _, task := range claimed {
+		s.broadcastDomainEvent(r.Context(), project, core.EventTaskAssigned, task.ID, task)
+		s.mirrorTaskEvent(r.Context(), taskAssigned, task)
+		s.observe(func(d *anomaly.Detector) []core.Anomaly { return d.ObserveTask(task) })
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// renewTaskLeaseRequest is the body of POST /api/tasks/leases/{id}/renew.
+type renewTaskLeaseRequest struct {
+	Project      string `json:"project"`
+	LeaseSeconds int    `json:"lease_seconds"`
+}
+
+type releaseTaskLeaseResponse struct {
+	Tasks []core.Task `json:"tasks"`
+}
+
+// handleTaskLease serves POST /api/tasks/leases/{id}/renew, which extends
+// a claim lease, and DELETE /api/tasks/leases/{id}, which ends it early and
+// returns its unfinished tasks to pending.
+func (s *DomainService) handleTaskLease(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tasks/leases/"), "/"), "/")
+	if parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "renew") {
+		writeNotFound(w)
+		return
+	}
+	id := parts[0]
+	if len(parts) == 2 {
+		dispatchByMethod(w, r, methodHandlers{
+			post: func(w http.ResponseWriter, r *http.Request) { s.renewTaskLease(w, r, id) },
+		})
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		delete: func(w http.ResponseWriter, r *http.Request) { s.releaseTaskLease(w, r, id) },
+	})
+}
+
+func (s *DomainService) renewTaskLease(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var req renewTaskLeaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if req.LeaseSeconds <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "lease_seconds must be positive", "invalid_request")
+		return
+	}
+	project, ok := groupProject(w, r, req.Project)
+	if !ok {
+		return
+	}
+	lease, err := s.domainStore.RenewTaskLease(r.Context(), project, id, clock.Now().UTC().Add(leaseDuration(req.LeaseSeconds)))
+	if errors.Is(err, core.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, "lease not found or already expired", "lease_expired")
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(lease)
+}
+
+func (s *DomainService) releaseTaskLease(w http.ResponseWriter, r *http.Request, id string) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	returned, err := s.domainStore.ReleaseTaskLease(r.Context(), project, id)
+	if errors.Is(err, core.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, "lease not found or already expired", "lease_expired")
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	for _, task := range returned {
+		s.broadcastDomainEvent(r.Context(), project, core.EventTaskLeaseExpired, task.ID, task)
+		s.observe(func(d *anomaly.Detector) []core.Anomaly { return d.ObserveTask(task) })
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(releaseTaskLeaseResponse{Tasks: returned})
+}
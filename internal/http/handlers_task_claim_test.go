@@ -0,0 +1,190 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestClaimTasksOldestMatchingFirst(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, task := range []map[string]any{
+		{"project": "proj", "title": "needs rust", "capabilities": []string{"rust"}},
+		{"project": "proj", "title": "oldest go", "capabilities": []string{"Go"}},
+		{"project": "proj", "title": "anyone"},
+		{"project": "proj", "title": "taken", "agent": "someone-else"},
+		{"project": "proj", "title": "newest go", "capabilities": []string{"go"}},
+	} {
+		task["status"] = "pending"
+		resp := env.post(t, "/api/tasks", task)
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+
+	resp := env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": "worker", "capabilities": []string{"go"}, "limit": 2})
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[claimTasksResponse](t, resp)
+	if len(got.Tasks) != 2 || got.Tasks[0].Title != "oldest go" || got.Tasks[1].Title != "anyone" {
+		t.Fatalf("claimed = %+v", got.Tasks)
+	}
+	for _, task := range got.Tasks {
+		if task.Agent != "worker" || task.Status != core.TaskStatusRunning {
+			t.Fatalf("claimed task = %+v", task)
+		}
+	}
+
+	// Claimed tasks are no longer pending; stealing takes the assigned one.
+	resp = env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": "worker", "capabilities": []string{"go"}, "limit": 5, "steal": true})
+	requireStatus(t, resp, http.StatusOK)
+	got = decodeJSON[claimTasksResponse](t, resp)
+	if len(got.Tasks) != 2 || got.Tasks[0].Title != "taken" || got.Tasks[1].Title != "newest go" {
+		t.Fatalf("second claim = %+v", got.Tasks)
+	}
+
+	resp = env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": "worker", "capabilities": []string{"go"}})
+	requireStatus(t, resp, http.StatusOK)
+	if got = decodeJSON[claimTasksResponse](t, resp); len(got.Tasks) != 0 {
+		t.Fatalf("nothing left, claimed %+v", got.Tasks)
+	}
+}
+
+func TestClaimTasksUsesRegisteredCapabilities(t *testing.T) {
+	env := newTestEnv(t)
+	resp := env.post(t, "/api/agents", map[string]any{"name": "rusty", "project": "proj", "capabilities": []string{"rust"}})
+	requireStatus(t, resp, http.StatusOK)
+	agent := decodeJSON[registerAgentResponse](t, resp)
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "needs rust", "status": "pending", "capabilities": []string{"rust"}})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": agent.AgentID})
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[claimTasksResponse](t, resp); len(got.Tasks) != 1 || got.Tasks[0].Agent != agent.AgentID {
+		t.Fatalf("claimed = %+v", got.Tasks)
+	}
+
+	resp = env.post(t, "/api/tasks/claim", map[string]any{"project": "proj"})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+}
+
+func TestClaimTasksConcurrentNoDoubleClaims(t *testing.T) {
+	env := newTestEnv(t)
+	const tasks, workers = 20, 8
+	for i := 0; i < tasks; i++ {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "work", "status": "pending"})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+
+	var mu sync.Mutex
+	owners := map[string]string{}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		agent := "worker-" + string(rune('a'+w))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				resp := env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": agent, "limit": 3})
+				if resp.StatusCode != http.StatusOK {
+					resp.Body.Close()
+					t.Errorf("claim status %d", resp.StatusCode)
+					return
+				}
+				got := decodeJSON[claimTasksResponse](t, resp)
+				if len(got.Tasks) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, task := range got.Tasks {
+					if prev, ok := owners[task.ID]; ok {
+						t.Errorf("task %s claimed by %s and %s", task.ID, prev, agent)
+					}
+					owners[task.ID] = agent
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(owners) != tasks {
+		t.Fatalf("claimed %d tasks, want %d", len(owners), tasks)
+	}
+}
+
+func TestClaimTasksBatchWithLease(t *testing.T) {
+	env := newTestEnv(t)
+	for i := 0; i < 3; i++ {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "work", "status": "pending"})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+
+	// Fewer available than asked for: the claim returns what there is.
+	resp := env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": "batcher", "count": 10, "lease_seconds": 60})
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[claimTasksResponse](t, resp)
+	if len(got.Tasks) != 3 || got.Lease == nil || len(got.Lease.TaskIDs) != 3 || got.Lease.Agent != "batcher" {
+		t.Fatalf("batch claim = %+v", got)
+	}
+	leaseID := got.Lease.ID
+
+	resp = env.post(t, "/api/tasks/claim", map[string]any{"project": "proj", "agent": "batcher", "count": 10, "lease_seconds": 60})
+	requireStatus(t, resp, http.StatusOK)
+	if empty := decodeJSON[claimTasksResponse](t, resp); len(empty.Tasks) != 0 || empty.Lease != nil {
+		t.Fatalf("empty claim = %+v", empty)
+	}
+
+	resp = env.post(t, "/api/tasks/leases/"+leaseID+"/renew", map[string]any{"project": "proj", "lease_seconds": 120})
+	requireStatus(t, resp, http.StatusOK)
+	if renewed := decodeJSON[core.TaskLease](t, resp); !renewed.ExpiresAt.After(got.Lease.ExpiresAt) {
+		t.Fatalf("renewed lease = %+v", renewed)
+	}
+
+	resp = env.delete(t, "/api/tasks/leases/"+leaseID+"?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	released := decodeJSON[releaseTaskLeaseResponse](t, resp)
+	if len(released.Tasks) != 3 || released.Tasks[0].Status != core.TaskStatusPending || released.Tasks[0].Agent != "" {
+		t.Fatalf("released = %+v", released.Tasks)
+	}
+
+	resp = env.post(t, "/api/tasks/leases/"+leaseID+"/renew", map[string]any{"project": "proj", "lease_seconds": 120})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+func TestClaimTasksAsOtherAgentForbidden(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	ring := auth.NewKeyring(true, map[string]string{"secret": "proj-a"})
+	h := NewDomainRouter(NewDomainService(st), nil, auth.Middleware(ring))
+	claim := func(agent string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{"project": "proj-a", "agent": agent})
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/claim", bytes.NewReader(body))
+		req.RemoteAddr = "203.0.113.10:9999"
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Agent-ID", "worker")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := claim("someone-else"); rec.Code != http.StatusForbidden {
+		t.Fatalf("claim as another agent: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := claim("worker"); rec.Code != http.StatusOK {
+		t.Fatalf("claim as self: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := claim(""); rec.Code != http.StatusOK {
+		t.Fatalf("claim defaulting to self: %d %s", rec.Code, rec.Body.String())
+	}
+}
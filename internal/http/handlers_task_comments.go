@@ -0,0 +1,157 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Task comment handlers. Comments are progress notes agents record against
+// a task under /api/tasks/{id}/comments, so a work log doesn't have to go
+// through messages. They are append-only apart from deletion.
+
+const (
+	defaultTaskCommentLimit = 50
+	maxTaskCommentLimit     = 500
+)
+
+type taskCommentsResponse struct {
+	Comments []core.TaskComment `json:"comments"`
+	// Cursor is the last returned comment's; pass it as ?after= for the
+	// next page. It is the request's own after when the page is empty.
+	Cursor uint64 `json:"cursor"`
+}
+
+// handleTaskComments serves /api/tasks/{id}/comments and
+// /api/tasks/{id}/comments/{comment_id}; rest is the path after the task ID.
+func (s *DomainService) handleTaskComments(w http.ResponseWriter, r *http.Request, taskID string, rest []string) {
+	if len(rest) == 1 {
+		dispatchByMethod(w, r, methodHandlers{
+			delete: func(w http.ResponseWriter, r *http.Request) { s.deleteTaskComment(w, r, taskID, rest[0]) },
+		})
+		return
+	}
+	dispatchByMethod(w, r, methodHandlers{
+		get:  func(w http.ResponseWriter, r *http.Request) { s.listTaskComments(w, r, taskID) },
+		post: func(w http.ResponseWriter, r *http.Request) { s.createTaskComment(w, r, taskID) },
+	})
+}
+
+func (s *DomainService) createTaskComment(w http.ResponseWriter, r *http.Request, taskID string) {
+	limitBody(w, r)
+	var req struct {
+		Project string `json:"project"`
+		Author  string `json:"author"`
+		Body    string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		writeJSONError(w, http.StatusBadRequest, "body is required", "missing_field")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := req.Project
+	if project == "" {
+		project = info.Project
+	}
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if info.Mode == auth.ModeAPIKey && project != info.Project {
+		writeProjectMismatch(w)
+		return
+	}
+	if !s.requireLiveProject(w, r, project) {
+		return
+	}
+	author := req.Author
+	if author == "" {
+		author = info.AgentID
+	}
+	comment, err := s.domainStore.CreateTaskComment(r.Context(), core.TaskComment{
+		Project: project,
+		TaskID:  taskID,
+		Author:  author,
+		Body:    req.Body,
+	})
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	s.broadcastDomainEvent(r.Context(), project, core.EventTaskCommented, taskID, comment)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// listTaskComments pages through a task's comments oldest first, taking
+// ?after= (a cursor from the previous page) and ?limit=.
+func (s *DomainService) listTaskComments(w http.ResponseWriter, r *http.Request, taskID string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	var after uint64
+	if v := r.URL.Query().Get("after"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "after must be a comment cursor", "invalid_cursor")
+			return
+		}
+		after = n
+	}
+	limit := defaultTaskCommentLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer", "invalid_limit")
+			return
+		}
+		limit = min(n, maxTaskCommentLimit)
+	}
+	if _, err := s.domainStore.GetTask(r.Context(), project, taskID); err != nil {
+		writeNotFound(w)
+		return
+	}
+	comments, err := s.domainStore.ListTaskComments(r.Context(), project, taskID, after, limit)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	resp := taskCommentsResponse{Comments: comments, Cursor: after}
+	if n := len(comments); n > 0 {
+		resp.Cursor = comments[n-1].Cursor
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *DomainService) deleteTaskComment(w http.ResponseWriter, r *http.Request, taskID, id string) {
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	if err := s.domainStore.DeleteTaskComment(r.Context(), project, taskID, id); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			writeNotFound(w)
+			return
+		}
+		writeInternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
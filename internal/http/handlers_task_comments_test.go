@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestTaskComments(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "migrate"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+
+	var ids []string
+	for _, body := range []string{"started", "schema done", "backfill running"} {
+		resp := env.post(t, "/api/tasks/"+task.ID+"/comments", map[string]any{"project": "proj", "author": "agent-a", "body": body})
+		requireStatus(t, resp, http.StatusCreated)
+		c := decodeJSON[core.TaskComment](t, resp)
+		if c.TaskID != task.ID || c.Author != "agent-a" || c.Body != body || c.Cursor == 0 {
+			t.Fatalf("created comment = %+v", c)
+		}
+		ids = append(ids, c.ID)
+	}
+
+	resp = env.post(t, "/api/tasks/"+task.ID+"/comments", map[string]any{"project": "proj", "body": "  "})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = env.post(t, "/api/tasks/nope/comments", map[string]any{"project": "proj", "body": "lost"})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	// Two pages of two, oldest first.
+	resp = env.get(t, "/api/tasks/"+task.ID+"/comments?project=proj&limit=2")
+	requireStatus(t, resp, http.StatusOK)
+	page := decodeJSON[taskCommentsResponse](t, resp)
+	if len(page.Comments) != 2 || page.Comments[0].Body != "started" || page.Comments[1].Body != "schema done" {
+		t.Fatalf("first page = %+v", page.Comments)
+	}
+	resp = env.get(t, "/api/tasks/"+task.ID+"/comments?project=proj&limit=2&after="+strconv.FormatUint(page.Cursor, 10))
+	requireStatus(t, resp, http.StatusOK)
+	page = decodeJSON[taskCommentsResponse](t, resp)
+	if len(page.Comments) != 1 || page.Comments[0].Body != "backfill running" {
+		t.Fatalf("second page = %+v", page.Comments)
+	}
+	resp = env.get(t, "/api/tasks/"+task.ID+"/comments?project=proj&limit=0")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/tasks?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if tasks := decodeJSON[[]core.Task](t, resp); len(tasks) != 1 || tasks[0].CommentCount != 3 {
+		t.Fatalf("listed tasks = %+v, want a comment count of 3", tasks)
+	}
+
+	resp = env.get(t, "/api/events?project=proj&entity_type=task&entity_id="+task.ID)
+	requireStatus(t, resp, http.StatusOK)
+	var commented int
+	for _, e := range decodeJSON[listEventsResponse](t, resp).Events {
+		if e.Type == string(core.EventTaskCommented) {
+			commented++
+		}
+	}
+	if commented != 3 {
+		t.Fatalf("task.commented events = %d, want 3", commented)
+	}
+
+	resp = env.delete(t, "/api/tasks/"+task.ID+"/comments/"+ids[0]+"?project=proj")
+	requireStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = env.delete(t, "/api/tasks/"+task.ID+"/comments/"+ids[0]+"?project=proj")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+	resp = env.get(t, "/api/tasks/"+task.ID+"/comments?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if page := decodeJSON[taskCommentsResponse](t, resp); len(page.Comments) != 2 {
+		t.Fatalf("after delete = %+v", page.Comments)
+	}
+}
@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/glob"
+)
+
+// Conflict-aware planning. Tasks may list the paths they expect to touch
+// (globs, as for reservations); GET /api/tasks/conflicts reports pending
+// and running tasks whose paths overlap, so a planner can avoid scheduling
+// them at the same time.
+
+type taskConflictsResponse struct {
+	Conflicts []core.TaskConflict `json:"conflicts"`
+}
+
+// validExpectedPaths writes a 400 and returns false when a task's expected
+// path isn't a pattern the reservation glob engine accepts.
+func validExpectedPaths(w http.ResponseWriter, paths []string) bool {
+	for _, p := range paths {
+		err := glob.ValidateComplexity(p)
+		if err == nil {
+			_, err = glob.PatternsOverlap(p, p)
+		}
+		if p == "" || err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid expected path: "+p, "invalid_request")
+			return false
+		}
+	}
+	return true
+}
+
+func (s *DomainService) handleTaskConflicts(w http.ResponseWriter, r *http.Request) {
+	dispatchByMethod(w, r, methodHandlers{
+		get: s.taskConflicts,
+	})
+}
+
+// taskConflicts serves GET /api/tasks/conflicts?project=...[&task=id]. With
+// task (an ID or short ID), only that task's conflicts are listed.
+func (s *DomainService) taskConflicts(w http.ResponseWriter, r *http.Request) {
+	project, ok := groupProject(w, r, "")
+	if !ok {
+		return
+	}
+	if project == "" {
+		writeJSONError(w, http.StatusBadRequest, "project is required", "missing_field")
+		return
+	}
+	taskID := r.URL.Query().Get("task")
+	if taskID != "" {
+		taskID = resolveShortID(r.Context(), s.domainStore, project, "task", taskID)
+	}
+	conflicts, err := s.domainStore.TaskConflicts(r.Context(), project, taskID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(taskConflictsResponse{Conflicts: conflicts})
+}
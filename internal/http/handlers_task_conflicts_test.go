@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestTaskConflicts(t *testing.T) {
+	env := newTestEnv(t)
+
+	create := func(title string, status core.TaskStatus, paths []string) core.Task {
+		t.Helper()
+		resp := env.post(t, "/api/tasks", map[string]any{
+			"project": "proj", "title": title, "status": status, "expected_paths": paths,
+		})
+		requireStatus(t, resp, http.StatusCreated)
+		return decodeJSON[core.Task](t, resp)
+	}
+	api := create("api handlers", core.TaskStatusRunning, []string{"internal/http/*.go"})
+	if len(api.ExpectedPaths) != 1 {
+		t.Fatalf("expected_paths not returned: %+v", api)
+	}
+	router := create("router rewrite", core.TaskStatusRunning, []string{"internal/http/router.go", "cmd/*/main.go"})
+	docs := create("docs", core.TaskStatusPending, []string{"docs/*.md", "cmd/intermute/main.go"})
+	create("done work", core.TaskStatusDone, []string{"internal/http/*.go"})
+	create("unplanned", core.TaskStatusPending, nil)
+
+	resp := env.get(t, "/api/tasks/conflicts?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	got := decodeJSON[taskConflictsResponse](t, resp).Conflicts
+	if len(got) != 2 {
+		t.Fatalf("conflicts = %+v, want api/router and router/docs", got)
+	}
+	if got[0].TaskID != api.ID || got[0].OtherID != router.ID || !got[0].Concurrent {
+		t.Fatalf("first conflict = %+v", got[0])
+	}
+	if o := got[0].Overlaps; len(o) != 1 || o[0].Path != "internal/http/*.go" || o[0].OtherPath != "internal/http/router.go" {
+		t.Fatalf("overlaps = %+v", o)
+	}
+	if got[1].TaskID != router.ID || got[1].OtherID != docs.ID || got[1].Concurrent {
+		t.Fatalf("second conflict = %+v", got[1])
+	}
+
+	// Scoped to one task, by short ID, that task comes first.
+	resp = env.get(t, "/api/tasks/conflicts?project=proj&task="+docs.ShortID)
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[taskConflictsResponse](t, resp).Conflicts; len(got) != 1 || got[0].TaskID != docs.ID || got[0].OtherID != router.ID {
+		t.Fatalf("docs conflicts = %+v", got)
+	}
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "bad", "expected_paths": []string{"a/[b"}})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	// Updates that omit expected_paths keep them.
+	api.Title = "api handlers v2"
+	resp = env.put(t, "/api/tasks/"+api.ID, map[string]any{
+		"project": "proj", "title": api.Title, "status": api.Status, "version": api.Version,
+	})
+	requireStatus(t, resp, http.StatusOK)
+	if updated := decodeJSON[core.Task](t, resp); len(updated.ExpectedPaths) != 1 {
+		t.Fatalf("expected_paths dropped on update: %+v", updated)
+	}
+}
@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// taskQuery builds a task listing's query from ?status=, ?agent=,
+// ?filter=, ?sort= and ?limit=, writing the error response itself when
+// one doesn't parse. Like withoutArchived, it leaves done tasks out unless
+// the request filters by status or asks for ?include_archived=true.
+func taskQuery(w http.ResponseWriter, r *http.Request, project string) (core.TaskQuery, bool) {
+	q := r.URL.Query()
+	query := core.TaskQuery{Project: project}
+	if status := q.Get("status"); status != "" {
+		query.Statuses = append(query.Statuses, core.TaskStatus(status))
+	}
+	if agent := q.Get("agent"); agent != "" {
+		query.Agents = append(query.Agents, agent)
+	}
+	if err := core.ParseTaskFilter(&query, q.Get("filter")); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_filter")
+		return core.TaskQuery{}, false
+	}
+	if err := core.ParseTaskSort(&query, q.Get("sort")); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error(), "invalid_sort")
+		return core.TaskQuery{}, false
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer", "invalid_limit")
+			return core.TaskQuery{}, false
+		}
+		query.Limit = n
+	}
+	if len(query.Statuses) == 0 && q.Get("include_archived") != "true" {
+		for _, status := range archivedStatuses["task"] {
+			query.ExcludeStatuses = append(query.ExcludeStatuses, core.TaskStatus(status))
+		}
+	}
+	return query, true
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestListTasksFilterAndSort(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	t.Cleanup(clock.Reset)
+	create := func(title string, status core.TaskStatus, priority core.Priority, storyID string) core.Task {
+		t.Helper()
+		clock.Advance(time.Second) // distinct created_at, for the sort
+		task, err := env.store.CreateTask(ctx, core.Task{Project: "proj", Title: title, Status: status, Priority: priority, StoryID: storyID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	old := create("old", core.TaskStatusPending, core.PriorityHigh, "s1")
+	clock.Advance(48 * time.Hour)
+	since := clock.Now().UTC().Add(-time.Hour)
+	blocked := create("blocked", core.TaskStatusBlocked, core.PriorityLow, "s1")
+	pending := create("pending", core.TaskStatusPending, core.PriorityHigh, "s1")
+	create("running", core.TaskStatusRunning, core.PriorityHigh, "s1")
+	create("other story", core.TaskStatusPending, core.PriorityHigh, "s2")
+	create("done", core.TaskStatusDone, core.PriorityHigh, "s1")
+
+	list := func(params url.Values) []core.Task {
+		t.Helper()
+		params.Set("project", "proj")
+		resp := env.get(t, "/api/tasks?"+params.Encode())
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[[]core.Task](t, resp)
+	}
+	ids := func(tasks []core.Task) []string {
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.ID)
+		}
+		return out
+	}
+
+	got := list(url.Values{
+		"filter": {"status:in(pending,blocked) story:s1 updated_after:" + since.Format(time.RFC3339Nano)},
+		"sort":   {"priority,title"},
+	})
+	if want := []string{pending.ID, blocked.ID}; len(got) != 2 || got[0].ID != want[0] || got[1].ID != want[1] {
+		t.Fatalf("filtered tasks = %v, want %v", ids(got), want)
+	}
+
+	// Without a status term, done tasks stay hidden; limit caps the rest.
+	got = list(url.Values{"sort": {"created_at"}, "limit": {"2"}})
+	if len(got) != 2 || got[0].ID != old.ID || got[1].ID != blocked.ID {
+		t.Fatalf("oldest two tasks = %v", ids(got))
+	}
+	if got = list(url.Values{"filter": {"status:done"}}); len(got) != 1 {
+		t.Fatalf("done tasks = %v, want the one done task", ids(got))
+	}
+
+	for _, tc := range []struct {
+		param, value, code string
+	}{
+		{"filter", "owner:me", "invalid_filter"},
+		{"filter", "status:finished", "invalid_filter"},
+		{"sort", "agent", "invalid_sort"},
+		{"limit", "0", "invalid_limit"},
+	} {
+		resp := env.get(t, "/api/tasks?project=proj&"+tc.param+"="+url.QueryEscape(tc.value))
+		requireStatus(t, resp, http.StatusBadRequest)
+		if body := decodeJSON[map[string]any](t, resp); body["code"] != tc.code {
+			t.Errorf("%s=%s: code = %v, want %s", tc.param, tc.value, body["code"], tc.code)
+		}
+	}
+}
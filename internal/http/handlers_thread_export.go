@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// ThreadExportMarkdown is the only export format so far.
+const ThreadExportMarkdown = "markdown"
+
+// exportThread renders a whole thread as a human-readable transcript for
+// post-mortem review. BCC recipients are never included.
+func (s *Service) exportThread(w http.ResponseWriter, r *http.Request, threadID string) {
+	if threadID == "" {
+		writeJSONError(w, http.StatusBadRequest, "thread_id is required", "missing_field")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ThreadExportMarkdown
+	}
+	if format != ThreadExportMarkdown {
+		writeJSONError(w, http.StatusBadRequest, "unsupported format: "+format, "unsupported_format")
+		return
+	}
+
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = strings.TrimSpace(r.URL.Query().Get("project"))
+	}
+	msgs, err := s.store.ThreadMessages(r.Context(), project, threadID, 0)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	if len(msgs) == 0 {
+		writeNotFound(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write([]byte(threadMarkdown(threadID, msgs)))
+}
+
+// threadMarkdown renders msgs oldest first, one section per message with
+// its sender, recipients and timestamp above the body.
+func threadMarkdown(threadID string, msgs []core.Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Thread %s\n\n", threadID)
+	first, last := msgs[0].CreatedAt.UTC(), msgs[len(msgs)-1].CreatedAt.UTC()
+	fmt.Fprintf(&b, "_%d messages in %s, %s to %s_\n", len(msgs), msgs[0].Project, first.Format(time.RFC3339), last.Format(time.RFC3339))
+	for _, m := range msgs {
+		subject := m.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		fmt.Fprintf(&b, "\n---\n\n## %s\n\n", subject)
+		fmt.Fprintf(&b, "- **From:** %s\n", m.From)
+		if len(m.To) > 0 {
+			fmt.Fprintf(&b, "- **To:** %s\n", strings.Join(m.To, ", "))
+		}
+		if len(m.CC) > 0 {
+			fmt.Fprintf(&b, "- **Cc:** %s\n", strings.Join(m.CC, ", "))
+		}
+		fmt.Fprintf(&b, "- **Date:** %s\n", m.CreatedAt.UTC().Format(time.RFC3339))
+		if m.InReplyTo != "" {
+			fmt.Fprintf(&b, "- **In reply to:** %s\n", m.InReplyTo)
+		}
+		if body := strings.TrimRight(m.Body, "\n"); body != "" {
+			b.WriteString("\n" + body + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExportThreadMarkdown(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, msg := range []map[string]any{
+		{"project": "proj", "from": "alice", "to": []string{"bob"}, "bcc": []string{"eve"}, "thread_id": "t1", "subject": "Deploy plan", "body": "Ship it Friday?"},
+		{"project": "proj", "from": "bob", "to": []string{"alice"}, "cc": []string{"carol"}, "thread_id": "t1", "body": "Monday is safer."},
+	} {
+		resp := env.post(t, "/api/messages", msg)
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	resp := env.get(t, "/api/threads/t1/export?project=proj&format=markdown")
+	requireStatus(t, resp, http.StatusOK)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Fatalf("expected markdown content type, got %q", ct)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	doc := string(raw)
+
+	for _, want := range []string{"# Thread t1", "## Deploy plan", "**From:** alice", "**Cc:** carol", "Monday is safer."} {
+		if !strings.Contains(doc, want) {
+			t.Fatalf("transcript missing %q:\n%s", want, doc)
+		}
+	}
+	if strings.Index(doc, "Ship it Friday?") > strings.Index(doc, "Monday is safer.") {
+		t.Fatalf("expected messages oldest first:\n%s", doc)
+	}
+	if strings.Contains(doc, "eve") {
+		t.Fatalf("transcript must not disclose bcc recipients:\n%s", doc)
+	}
+
+	resp = env.get(t, "/api/threads/t1/export?project=proj&format=pdf")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+	resp = env.get(t, "/api/threads/missing/export?project=proj")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
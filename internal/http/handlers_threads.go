@@ -2,12 +2,14 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
 )
 
 type threadSummaryJSON struct {
@@ -17,6 +19,7 @@ type threadSummaryJSON struct {
 	LastFrom     string `json:"last_from"`
 	LastBody     string `json:"last_body"`
 	LastAt       string `json:"last_at"`
+	Unread       int    `json:"unread"`
 }
 
 type listThreadsResponse struct {
@@ -32,12 +35,12 @@ type threadMessagesResponse struct {
 
 func (s *Service) handleListThreads(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	agent := strings.TrimSpace(r.URL.Query().Get("agent"))
 	if agent == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
 		return
 	}
 
@@ -66,7 +69,7 @@ func (s *Service) handleListThreads(w http.ResponseWriter, r *http.Request) {
 
 	threads, err := s.store.ListThreads(r.Context(), project, agent, cursor, limit)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
@@ -80,6 +83,7 @@ func (s *Service) handleListThreads(w http.ResponseWriter, r *http.Request) {
 			LastFrom:     t.LastFrom,
 			LastBody:     t.LastBody,
 			LastAt:       t.LastAt.Format(time.RFC3339),
+			Unread:       t.Unread,
 		})
 	}
 	if len(threads) > 0 {
@@ -92,15 +96,22 @@ func (s *Service) handleListThreads(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Service) handleThreadMessages(w http.ResponseWriter, r *http.Request) {
+	threadID := strings.TrimPrefix(r.URL.Path, "/api/threads/")
+	threadID = strings.Trim(threadID, "/")
+	if id, ok := strings.CutSuffix(threadID, "/read"); ok {
+		s.markThreadRead(w, r, id)
+		return
+	}
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
+		return
+	}
+	if id, ok := strings.CutSuffix(threadID, "/export"); ok {
+		s.exportThread(w, r, id)
 		return
 	}
-
-	threadID := strings.TrimPrefix(r.URL.Path, "/api/threads/")
-	threadID = strings.Trim(threadID, "/")
 	if threadID == "" {
-		w.WriteHeader(http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "thread_id is required", "missing_field")
 		return
 	}
 
@@ -117,25 +128,19 @@ func (s *Service) handleThreadMessages(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// The viewing agent decides whether BCC recipients are disclosed.
+	viewer := messageViewer(r)
+
 	msgs, err := s.store.ThreadMessages(r.Context(), project, threadID, cursor)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeInternalError(w)
 		return
 	}
 
 	var lastCursor uint64
 	apiMsgs := make([]apiMessage, 0, len(msgs))
 	for _, m := range msgs {
-		apiMsgs = append(apiMsgs, apiMessage{
-			ID:        m.ID,
-			ThreadID:  m.ThreadID,
-			Project:   m.Project,
-			From:      m.From,
-			To:        m.To,
-			Body:      m.Body,
-			CreatedAt: m.CreatedAt.Format(time.RFC3339Nano),
-			Cursor:    m.Cursor,
-		})
+		apiMsgs = append(apiMsgs, toAPIMessage(m, viewer))
 		if m.Cursor > lastCursor {
 			lastCursor = m.Cursor
 		}
@@ -151,3 +156,50 @@ func (s *Service) handleThreadMessages(w http.ResponseWriter, r *http.Request) {
 		Cursor:   lastCursor,
 	})
 }
+
+type markThreadReadResponse struct {
+	ThreadID string `json:"thread_id"`
+	Agent    string `json:"agent"`
+	Read     int    `json:"read"`
+}
+
+// markThreadRead handles POST /api/threads/{id}/read: it marks every
+// message in the thread the agent received as read and zeroes the thread's
+// unread count, reporting how many messages were newly read.
+func (s *Service) markThreadRead(w http.ResponseWriter, r *http.Request, threadID string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	if threadID == "" {
+		writeJSONError(w, http.StatusBadRequest, "thread_id is required", "missing_field")
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = strings.TrimSpace(r.URL.Query().Get("project"))
+	}
+	var req messageActionRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	agent := strings.TrimSpace(req.Agent)
+	if agent == "" {
+		agent = strings.TrimSpace(r.URL.Query().Get("agent"))
+	}
+	if agent == "" {
+		writeJSONError(w, http.StatusBadRequest, "agent is required", "missing_field")
+		return
+	}
+
+	n, err := s.store.MarkThreadRead(r.Context(), project, threadID, agent)
+	if errors.Is(err, core.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, "agent is not in this thread", "not_found")
+		return
+	}
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(markThreadReadResponse{ThreadID: threadID, Agent: agent, Read: n})
+}
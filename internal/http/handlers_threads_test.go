@@ -9,14 +9,11 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 )
 
 func TestListThreadsAndGetMessages(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -89,10 +86,7 @@ func TestListThreadsAndGetMessages(t *testing.T) {
 }
 
 func TestListThreadsRequiresAgent(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -107,10 +101,7 @@ func TestListThreadsRequiresAgent(t *testing.T) {
 }
 
 func TestThreadMessagesRequiresThreadID(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -125,10 +116,7 @@ func TestThreadMessagesRequiresThreadID(t *testing.T) {
 }
 
 func TestListThreadsMethodNotAllowed(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -143,10 +131,7 @@ func TestListThreadsMethodNotAllowed(t *testing.T) {
 }
 
 func TestListThreadsPaginationCursor(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := NewService(st)
 	srv := httptest.NewServer(NewRouter(svc, nil, nil))
 	defer srv.Close()
@@ -211,3 +196,37 @@ func TestListThreadsPaginationCursor(t *testing.T) {
 			second.Threads[0].LastCursor, first.Threads[len(first.Threads)-1].LastCursor)
 	}
 }
+
+func TestThreadUnreadAndMarkThreadRead(t *testing.T) {
+	env := newTestEnv(t)
+	for _, body := range []string{"one", "two"} {
+		resp := env.post(t, "/api/messages", map[string]any{
+			"project": "proj", "from": "alice", "to": []string{"bob"}, "thread_id": "t1", "body": body,
+		})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	list := decodeJSON[listThreadsResponse](t, env.get(t, "/api/threads?agent=bob&project=proj"))
+	if len(list.Threads) != 1 || list.Threads[0].Unread != 2 {
+		t.Fatalf("threads = %+v, want t1 with 2 unread", list.Threads)
+	}
+
+	resp := env.post(t, "/api/threads/t1/read?project=proj", map[string]string{"agent": "bob"})
+	requireStatus(t, resp, http.StatusOK)
+	marked := decodeJSON[markThreadReadResponse](t, resp)
+	if marked.Read != 2 {
+		t.Fatalf("read = %d, want 2", marked.Read)
+	}
+	list = decodeJSON[listThreadsResponse](t, env.get(t, "/api/threads?agent=bob&project=proj"))
+	if list.Threads[0].Unread != 0 {
+		t.Fatalf("unread after mark thread read = %d, want 0", list.Threads[0].Unread)
+	}
+
+	resp = env.post(t, "/api/threads/t1/read?project=proj", map[string]string{"agent": "carol"})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+	resp = env.post(t, "/api/threads/t1/read?project=proj", map[string]string{})
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+}
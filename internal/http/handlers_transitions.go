@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// invalidTransitionResponse is the 422 body for a status change
+// core.AllowedTransitions doesn't list.
+type invalidTransitionResponse struct {
+	errorResponse
+	EntityType string   `json:"entity_type"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Allowed    []string `json:"allowed"`
+}
+
+// taskStatusChange is the task.status_changed payload.
+type taskStatusChange struct {
+	Task core.Task       `json:"task"`
+	From core.TaskStatus `json:"from"`
+	To   core.TaskStatus `json:"to"`
+}
+
+// strictTransitionsEnabled reports whether project enforces status
+// transitions for entityType.
+func (s *DomainService) strictTransitionsEnabled(r *http.Request, project, entityType string) bool {
+	flags, err := s.domainStore.EffectiveFeatureFlags(r.Context(), project)
+	if err != nil {
+		log.Printf("WARN: feature flag %s for %s: %v", core.FlagStrictTransitions, project, err)
+		return false
+	}
+	return flags[core.FlagStrictTransitions] || flags[core.FlagStrictTransitions+"."+entityType]
+}
+
+// checkTransition vets a move to status to when the project enforces
+// transitions. current loads the stored status; if that fails the update
+// goes ahead and reports its own error. On a disallowed move it writes
+// 422 and returns false.
+func (s *DomainService) checkTransition(w http.ResponseWriter, r *http.Request, project, entityType, to string, current func() (string, error)) bool {
+	if !s.strictTransitionsEnabled(r, project, entityType) {
+		return true
+	}
+	from, err := current()
+	if err != nil || core.CanTransition(entityType, from, to) {
+		return true
+	}
+	allowed := core.AllowedTransitions[entityType][from]
+	if allowed == nil {
+		allowed = []string{}
+	}
+	writeErrorBody(w, http.StatusUnprocessableEntity, invalidTransitionResponse{
+		errorResponse: newErrorResponse("cannot move "+entityType+" from "+from+" to "+to, "invalid_transition", nil),
+		EntityType:    entityType,
+		From:          from,
+		To:            to,
+		Allowed:       allowed,
+	})
+	return false
+}
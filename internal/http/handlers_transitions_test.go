@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestStrictTransitions(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "Ship", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+
+	task.Status = core.TaskStatusDone
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusOK)
+	task = decodeJSON[core.Task](t, resp)
+
+	// Without the flag any move is accepted.
+	task.Status = core.TaskStatusPending
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusOK)
+	task = decodeJSON[core.Task](t, resp)
+	task.Status = core.TaskStatusDone
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusOK)
+	task = decodeJSON[core.Task](t, resp)
+
+	resp = env.put(t, "/api/admin/flags/strict_transitions.task", map[string]any{"project": "proj", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	task.Status = core.TaskStatusPending
+	resp = env.put(t, "/api/tasks/"+task.ID, task)
+	requireStatus(t, resp, http.StatusUnprocessableEntity)
+	body := decodeJSON[invalidTransitionResponse](t, resp)
+	if body.Code != "invalid_transition" || body.From != "done" || body.To != "pending" || len(body.Allowed) != 0 {
+		t.Fatalf("unexpected error body: %+v", body)
+	}
+	resp = env.post(t, "/api/tasks/"+task.ID+"/assign?project=proj", map[string]any{"agent": "alice"})
+	requireStatus(t, resp, http.StatusUnprocessableEntity)
+	resp.Body.Close()
+
+	// Only tasks are strict: other entity types still move freely.
+	resp = env.post(t, "/api/specs", map[string]any{"project": "proj", "title": "Spec", "status": "validated"})
+	requireStatus(t, resp, http.StatusCreated)
+	spec := decodeJSON[core.Spec](t, resp)
+	spec.Status = core.SpecStatusDraft
+	resp = env.put(t, "/api/specs/"+spec.ID, spec)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// An allowed move goes through and announces itself.
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "title": "Next", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	next := decodeJSON[core.Task](t, resp)
+	next.Status = core.TaskStatusRunning
+	resp = env.put(t, "/api/tasks/"+next.ID, next)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/events?project=proj&entity_type=task&entity_id="+next.ID)
+	requireStatus(t, resp, http.StatusOK)
+	events := decodeJSON[listEventsResponse](t, resp)
+	last := events.Events[len(events.Events)-1]
+	if last.Type != string(core.EventTaskStatusChanged) {
+		t.Fatalf("expected task.status_changed last, got %s", last.Type)
+	}
+}
@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Unique titles. With the unique_titles flag on (for every entity type, or
+// per type as unique_titles.task etc.), creating or renaming an entity to a
+// title already used in its scope is rejected with 409 and the existing
+// entity's ID, so the caller can link to it instead of duplicating it.
+
+type duplicateTitleResponse struct {
+	errorResponse
+	ExistingID string `json:"existing_id"`
+}
+
+// uniqueTitlesEnabled reports whether project enforces unique titles for
+// entityType.
+func (s *DomainService) uniqueTitlesEnabled(r *http.Request, project, entityType string) bool {
+	flags, err := s.domainStore.EffectiveFeatureFlags(r.Context(), project)
+	if err != nil {
+		log.Printf("WARN: feature flag %s for %s: %v", core.FlagUniqueTitles, project, err)
+		return false
+	}
+	return flags[core.FlagUniqueTitles] || flags[core.FlagUniqueTitles+"."+entityType]
+}
+
+// checkTitle checks scope for a duplicate title when the project enforces
+// unique titles. On a duplicate (409) or lookup error it writes the
+// response and returns false. Two writers can both pass the check; the
+// store's unique index then fails the later write with
+// core.ErrDuplicateTitle, which writeDuplicateTitle answers the same way.
+func (s *DomainService) checkTitle(w http.ResponseWriter, r *http.Request, scope core.TitleScope) bool {
+	if !s.uniqueTitlesEnabled(r, scope.Project, scope.EntityType) {
+		return true
+	}
+	existing, err := s.domainStore.FindByTitle(r.Context(), scope)
+	if err != nil {
+		writeInternalError(w)
+		return false
+	}
+	if existing != "" {
+		writeTitleConflict(w, scope, existing)
+		return false
+	}
+	return true
+}
+
+// writeDuplicateTitle answers a write the store rejected with
+// core.ErrDuplicateTitle, with the ID of the entity that got the title
+// first.
+func (s *DomainService) writeDuplicateTitle(w http.ResponseWriter, r *http.Request, scope core.TitleScope) {
+	existing, err := s.domainStore.FindByTitle(r.Context(), scope)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	writeTitleConflict(w, scope, existing)
+}
+
+func writeTitleConflict(w http.ResponseWriter, scope core.TitleScope, existing string) {
+	writeErrorBody(w, http.StatusConflict, duplicateTitleResponse{
+		errorResponse: newErrorResponse(scope.EntityType+" with this title already exists", "duplicate_title", nil),
+		ExistingID:    existing,
+	})
+}
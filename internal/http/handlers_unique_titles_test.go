@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestUniqueTaskTitles(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.put(t, "/api/admin/flags/unique_titles.task", map[string]any{"project": "proj", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "story_id": "s1", "title": "Fix flaky tests", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	first := decodeJSON[core.Task](t, resp)
+
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "story_id": "s1", "title": "  fix FLAKY tests ", "status": "pending"})
+	requireStatus(t, resp, http.StatusConflict)
+	dup := decodeJSON[duplicateTitleResponse](t, resp)
+	if dup.Code != "duplicate_title" || dup.ExistingID != first.ID {
+		t.Fatalf("conflict = %+v, want existing %s", dup, first.ID)
+	}
+
+	// Other stories, and projects without the flag, are unaffected.
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "story_id": "s2", "title": "Fix flaky tests", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "other", "story_id": "s1", "title": "Fix flaky tests", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	// Renaming into a taken title conflicts; keeping your own title doesn't.
+	resp = env.post(t, "/api/tasks", map[string]any{"project": "proj", "story_id": "s1", "title": "Write docs", "status": "pending"})
+	requireStatus(t, resp, http.StatusCreated)
+	second := decodeJSON[core.Task](t, resp)
+	second.Title = "Fix flaky tests"
+	resp = env.put(t, "/api/tasks/"+second.ID, second)
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+	first.Status = core.TaskStatusRunning
+	resp = env.put(t, "/api/tasks/"+first.ID, first)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+}
+
+func TestUniqueTitlesAllTypes(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.put(t, "/api/admin/flags/unique_titles", map[string]any{"project": "proj", "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/specs", map[string]any{"project": "proj", "title": "Auth", "status": "draft"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.post(t, "/api/specs", map[string]any{"project": "proj", "title": "auth", "status": "draft"})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/epics", map[string]any{"project": "proj", "spec_id": "sp1", "title": "Login", "status": "open"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	resp = env.post(t, "/api/epics", map[string]any{"project": "proj", "spec_id": "sp1", "title": "Login", "status": "open"})
+	requireStatus(t, resp, http.StatusConflict)
+	resp.Body.Close()
+	resp = env.post(t, "/api/epics", map[string]any{"project": "proj", "title": "Login", "status": "open"})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+}
@@ -67,28 +67,22 @@ func (s *Service) handleWindows(w http.ResponseWriter, r *http.Request) {
 // handleWindowByID handles DELETE /api/windows/{window_uuid}?project=X (expire).
 func (s *Service) handleWindowByID(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 	windowUUID := strings.TrimPrefix(r.URL.Path, "/api/windows/")
 	windowUUID = strings.TrimRight(windowUUID, "/")
 	if windowUUID == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "window_uuid required"})
+		writeJSONError(w, http.StatusBadRequest, "window_uuid required", "missing_field")
 		return
 	}
 	project := r.URL.Query().Get("project")
 	if project == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "project query param required"})
+		writeJSONError(w, http.StatusBadRequest, "project query param required", "missing_field")
 		return
 	}
 	if err := s.store.ExpireWindowIdentity(r.Context(), project, windowUUID); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeInternalError(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -99,21 +93,15 @@ func (s *Service) upsertWindow(w http.ResponseWriter, r *http.Request) {
 	limitBody(w, r)
 	var req upsertWindowRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid JSON"})
+		writeInvalidJSON(w, err)
 		return
 	}
 	if req.Project == "" || req.WindowUUID == "" || req.AgentID == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "project, window_uuid, and agent_id are required"})
+		writeJSONError(w, http.StatusBadRequest, "project, window_uuid, and agent_id are required", "missing_field")
 		return
 	}
 	if req.TmuxTarget != "" && !tmuxTargetPattern.MatchString(req.TmuxTarget) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid tmux_target"})
+		writeJSONError(w, http.StatusBadRequest, "invalid tmux_target", "invalid_request")
 		return
 	}
 	if req.DisplayName == "" {
@@ -130,18 +118,14 @@ func (s *Service) upsertWindow(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		if errors.Is(err, core.ErrNotFound) || err.Error() == "agent_token_mismatch" {
-			w.WriteHeader(http.StatusForbidden)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": "agent_token_mismatch"})
+			writeJSONError(w, http.StatusForbidden, "agent_token_mismatch", "agent_token_mismatch")
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeInternalError(w)
 		return
 	}
 	if result == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "upsert returned no result"})
+		writeInternalError(w)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -151,16 +135,12 @@ func (s *Service) upsertWindow(w http.ResponseWriter, r *http.Request) {
 func (s *Service) listWindows(w http.ResponseWriter, r *http.Request) {
 	project := r.URL.Query().Get("project")
 	if project == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "project query param required"})
+		writeJSONError(w, http.StatusBadRequest, "project query param required", "missing_field")
 		return
 	}
 	identities, err := s.store.ListWindowIdentities(r.Context(), project)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeInternalError(w)
 		return
 	}
 	resp := windowListResponse{Windows: make([]windowResponse, len(identities))}
@@ -0,0 +1,137 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// highImportance lists the message importance levels surfaced by the work view.
+var highImportance = []string{"high", "urgent"}
+
+// defaultDeadlineHorizon is how far ahead /work looks for task deadlines
+// when ?horizon_hours is not supplied.
+const defaultDeadlineHorizon = 7 * 24 * time.Hour
+
+// agentWorkResponse is the "my work" read model: everything an agent needs
+// at session start in a single payload.
+type agentWorkResponse struct {
+	AgentID        string           `json:"agent_id"`
+	Project        string           `json:"project"`
+	Tasks          []core.Task      `json:"tasks"`
+	ReviewStories  []core.Story     `json:"review_stories"`
+	UrgentMessages []apiMessage     `json:"urgent_messages"`
+	Reservations   []apiReservation `json:"reservations"`
+	Deadlines      []core.Task      `json:"deadlines"`
+	GeneratedAt    string           `json:"generated_at"`
+}
+
+// handleDomainAgentSubpath serves agent subresources that need domain data
+// and falls through to the messaging handlers for everything else.
+func (s *DomainService) handleDomainAgentSubpath(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/agents/"), "/")
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) == 2 && parts[0] != "" && parts[1] == "work" {
+		s.handleAgentWork(w, r, parts[0])
+		return
+	}
+	s.handleAgentSubpath(w, r)
+}
+
+// handleAgentWork returns the agent's open tasks, stories awaiting review
+// that the agent has open tasks under, unread high-importance messages, held
+// reservations and tasks due within the deadline horizon.
+func (s *DomainService) handleAgentWork(w http.ResponseWriter, r *http.Request, agentID string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	info, _ := auth.FromContext(r.Context())
+	project := info.Project
+	if project == "" {
+		project = r.URL.Query().Get("project")
+	}
+	horizon := defaultDeadlineHorizon
+	if v := r.URL.Query().Get("horizon_hours"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			horizon = time.Duration(parsed) * time.Hour
+		}
+	}
+	ctx := r.Context()
+	now := clock.Now().UTC()
+
+	tasks, err := s.domainStore.ListTasks(ctx, project, "", agentID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	resp := agentWorkResponse{
+		AgentID:        agentID,
+		Project:        project,
+		Tasks:          []core.Task{},
+		ReviewStories:  []core.Story{},
+		UrgentMessages: []apiMessage{},
+		Reservations:   []apiReservation{},
+		Deadlines:      []core.Task{},
+		GeneratedAt:    now.Format(time.RFC3339Nano),
+	}
+
+	seenStories := make(map[string]bool)
+	for _, t := range tasks {
+		if t.Status == core.TaskStatusDone {
+			continue
+		}
+		resp.Tasks = append(resp.Tasks, t)
+		if t.DueAt != nil && t.DueAt.Before(now.Add(horizon)) {
+			resp.Deadlines = append(resp.Deadlines, t)
+		}
+	}
+	for _, t := range resp.Tasks {
+		if t.StoryID == "" || seenStories[t.StoryID] {
+			continue
+		}
+		seenStories[t.StoryID] = true
+		story, err := s.domainStore.GetStory(ctx, t.Project, t.StoryID)
+		if err != nil {
+			continue
+		}
+		if story.Status == core.StoryStatusReview {
+			resp.ReviewStories = append(resp.ReviewStories, story)
+		}
+	}
+	sort.Slice(resp.Deadlines, func(i, j int) bool {
+		return resp.Deadlines[i].DueAt.Before(*resp.Deadlines[j].DueAt)
+	})
+
+	msgs, err := s.store.InboxUnread(ctx, project, agentID, highImportance, 50)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	viewer := messageViewer(r)
+	for _, m := range msgs {
+		resp.UrgentMessages = append(resp.UrgentMessages, toAPIMessage(m, viewer))
+	}
+
+	reservations, err := s.store.AgentReservations(ctx, agentID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+	for _, res := range reservations {
+		if !res.IsActive() || (project != "" && res.Project != project) {
+			continue
+		}
+		resp.Reservations = append(resp.Reservations, toAPIReservation(res))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
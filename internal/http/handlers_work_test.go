@@ -0,0 +1,105 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestAgentWork(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	const project = "proj"
+
+	story, err := env.store.CreateStory(ctx, core.Story{Project: project, EpicID: "e1", Title: "Needs review", Status: core.StoryStatusReview})
+	if err != nil {
+		t.Fatalf("create story: %v", err)
+	}
+	reviewed, err := env.store.CreateStory(ctx, core.Story{Project: project, EpicID: "e1", Title: "Done with it", Status: core.StoryStatusReview})
+	if err != nil {
+		t.Fatalf("create story: %v", err)
+	}
+	soon := time.Now().UTC().Add(2 * time.Hour)
+	later := time.Now().UTC().Add(30 * 24 * time.Hour)
+	for _, task := range []core.Task{
+		{Project: project, Title: "due soon", Agent: "alice", StoryID: story.ID, Status: core.TaskStatusRunning, DueAt: &soon},
+		{Project: project, Title: "due later", Agent: "alice", Status: core.TaskStatusPending, DueAt: &later},
+		{Project: project, Title: "finished", Agent: "alice", StoryID: reviewed.ID, Status: core.TaskStatusDone},
+		{Project: project, Title: "not mine", Agent: "bob", Status: core.TaskStatusPending},
+	} {
+		if _, err := env.store.CreateTask(ctx, task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	for _, importance := range []string{"urgent", "normal"} {
+		resp := env.post(t, "/api/messages", map[string]any{
+			"project":    project,
+			"from":       "bob",
+			"to":         []string{"alice"},
+			"body":       importance,
+			"importance": importance,
+		})
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	resp := env.post(t, "/api/reservations", map[string]any{
+		"agent_id":     "alice",
+		"project":      project,
+		"path_pattern": "internal/*.go",
+		"exclusive":    true,
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/agents/alice/work?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	work := decodeJSON[agentWorkResponse](t, resp)
+
+	if len(work.Tasks) != 2 {
+		t.Fatalf("expected 2 open tasks, got %d", len(work.Tasks))
+	}
+	if len(work.Deadlines) != 1 || work.Deadlines[0].Title != "due soon" {
+		t.Fatalf("expected only 'due soon' in deadlines, got %+v", work.Deadlines)
+	}
+	if len(work.ReviewStories) != 1 || work.ReviewStories[0].ID != story.ID {
+		t.Fatalf("expected only review story %s (linked by an open task), got %+v", story.ID, work.ReviewStories)
+	}
+	if len(work.UrgentMessages) != 1 || work.UrgentMessages[0].Body != "urgent" {
+		t.Fatalf("expected one urgent message, got %+v", work.UrgentMessages)
+	}
+	if len(work.Reservations) != 1 {
+		t.Fatalf("expected 1 reservation, got %d", len(work.Reservations))
+	}
+
+	// Reading the urgent message removes it from the work view.
+	resp = env.post(t, "/api/messages/"+work.UrgentMessages[0].ID+"/read?project="+project, map[string]any{"agent": "alice"})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/agents/alice/work?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	work = decodeJSON[agentWorkResponse](t, resp)
+	if len(work.UrgentMessages) != 0 {
+		t.Fatalf("expected no urgent messages after read, got %d", len(work.UrgentMessages))
+	}
+}
+
+func TestAgentWorkFallsThroughToAgentSubpaths(t *testing.T) {
+	env := newTestEnv(t)
+	resp := env.post(t, "/api/agents/nobody/heartbeat", map[string]any{})
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, env.srv.URL+"/api/agents/alice/work", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireStatus(t, resp, http.StatusMethodNotAllowed)
+	resp.Body.Close()
+}
@@ -0,0 +1,115 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// maxIdempotencyKey bounds the Idempotency-Key header.
+const maxIdempotencyKey = 255
+
+// withIdempotency makes POSTs and PATCHes carrying an Idempotency-Key
+// header safe to retry. The first response (unless it is a 5xx, which is
+// worth retrying) is stored under the key for core.IdempotencyKeyTTL; a
+// repeat of the same request gets it replayed, headers included, marked
+// Idempotent-Replayed: true, without running the handler again. Reusing a key for a different request is
+// rejected with 422, and a repeat that arrives while the original is still
+// running with 409. Keys are scoped to the caller's API-key project.
+func (s *DomainService) withIdempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if (r.Method != http.MethodPost && r.Method != http.MethodPatch) || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			writeJSONError(w, http.StatusBadRequest, "Idempotency-Key is too long", "invalid_idempotency_key")
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+		if err != nil {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large", "body_too_large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.New()
+		sum.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		sum.Write(body)
+		hash := hex.EncodeToString(sum.Sum(nil))
+
+		info, _ := auth.FromContext(r.Context())
+		scope := info.Project + "\x00" + key
+		s.idemMu.Lock()
+		if _, busy := s.idemInFlight[scope]; busy {
+			s.idemMu.Unlock()
+			writeJSONError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress", "idempotency_in_progress")
+			return
+		}
+		s.idemInFlight[scope] = struct{}{}
+		s.idemMu.Unlock()
+		defer func() {
+			s.idemMu.Lock()
+			delete(s.idemInFlight, scope)
+			s.idemMu.Unlock()
+		}()
+
+		stored, err := s.domainStore.GetIdempotentResponse(r.Context(), info.Project, key)
+		switch {
+		case err == nil:
+			if stored.RequestHash != hash {
+				writeJSONError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", "idempotency_key_reused")
+				return
+			}
+			for k, v := range stored.Header {
+				w.Header()[k] = v
+			}
+			if stored.ContentType != "" && w.Header().Get("Content-Type") == "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			_, _ = w.Write(stored.Body)
+			return
+		case !errors.Is(err, core.ErrNotFound):
+			writeInternalError(w)
+			return
+		}
+
+		rec := &etagRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		// X-Event-Cursor is stamped further out, so store it explicitly.
+		header := rec.header.Clone()
+		if cursor := notedEventCursor(r.Context()); cursor > 0 {
+			header.Set(headerEventCursor, strconv.FormatUint(cursor, 10))
+		}
+		if err := s.domainStore.SaveIdempotentResponse(r.Context(), core.IdempotentResponse{
+			Project:     info.Project,
+			Key:         key,
+			RequestHash: hash,
+			Status:      rec.status,
+			ContentType: rec.header.Get("Content-Type"),
+			Header:      header,
+			Body:        rec.body.Bytes(),
+		}); err != nil {
+			log.Printf("WARN: save idempotent response for key %q: %v", key, err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestIdempotencyKeyReplaysCreate(t *testing.T) {
+	env := newTestEnv(t)
+
+	post := func(key, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, env.srv.URL+"/api/tasks", bytes.NewReader([]byte(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /api/tasks: %v", err)
+		}
+		return resp
+	}
+
+	const body = `{"project":"proj","title":"Write docs","status":"pending"}`
+	resp := post("k1", body)
+	requireStatus(t, resp, http.StatusCreated)
+	cursor := resp.Header.Get(headerEventCursor)
+	first := decodeJSON[core.Task](t, resp)
+	if cursor == "" {
+		t.Fatal("create didn't answer with X-Event-Cursor")
+	}
+
+	resp = post("k1", body)
+	requireStatus(t, resp, http.StatusCreated)
+	if resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatal("retry wasn't marked as a replay")
+	}
+	if got := resp.Header.Get(headerEventCursor); got != cursor {
+		t.Fatalf("replayed X-Event-Cursor = %q, want %q", got, cursor)
+	}
+	if again := decodeJSON[core.Task](t, resp); again.ID != first.ID {
+		t.Fatalf("retry created %s, want replay of %s", again.ID, first.ID)
+	}
+
+	resp = post("k1", `{"project":"proj","title":"Something else","status":"pending"}`)
+	requireStatus(t, resp, http.StatusUnprocessableEntity)
+	resp.Body.Close()
+
+	resp = post("k2", body)
+	requireStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/tasks?project=proj")
+	requireStatus(t, resp, http.StatusOK)
+	if tasks := decodeJSON[[]core.Task](t, resp); len(tasks) != 2 {
+		t.Fatalf("tasks = %d, want 2 (one per key)", len(tasks))
+	}
+}
@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+const (
+	// maxInboxWait caps ?wait so a long-poll can't pin a connection forever.
+	maxInboxWait = 60 * time.Second
+	// inboxRecheckInterval is how often a waiting long-poll rereads the
+	// store regardless of notifications, for messages delivered without a
+	// broadcast reaching this server (another process writing the same
+	// database, or a service running without a broadcaster).
+	inboxRecheckInterval = 5 * time.Second
+)
+
+// parseInboxWait accepts a Go duration ("30s") or a bare number of seconds.
+// Empty means no waiting; values above maxInboxWait are clamped.
+func parseInboxWait(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		secs, convErr := strconv.Atoi(v)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid wait %q", v)
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid wait %q", v)
+	}
+	if d > maxInboxWait {
+		d = maxInboxWait
+	}
+	return d, nil
+}
+
+type inboxKey struct{ project, agent string }
+
+// InboxNotifier wakes inbox long-polls. It is a Broadcaster fed the same
+// events as WebSocket clients: a message.created or message.unsnoozed
+// event addressed to an agent wakes every request waiting on that agent's
+// inbox, so waiting costs no database reads until there is something to
+// read. Include it in the bus handed to anything else that broadcasts
+// message events, such as the sweeper.
+type InboxNotifier struct {
+	mu      sync.Mutex
+	waiters map[inboxKey]chan struct{}
+}
+
+func NewInboxNotifier() *InboxNotifier {
+	return &InboxNotifier{waiters: map[inboxKey]chan struct{}{}}
+}
+
+// Broadcast implements Broadcaster.
+func (n *InboxNotifier) Broadcast(project, agent string, event any) {
+	if agent == "" {
+		return
+	}
+	m, ok := event.(map[string]any)
+	if !ok {
+		return
+	}
+	switch m["type"] {
+	case string(core.EventMessageCreated), string(core.EventMessageUnsnoozed):
+		n.wake(project, agent)
+	}
+}
+
+// wait returns a channel closed at the next wake for agent's inbox. Take
+// it before reading the inbox, so a message landing in between isn't
+// missed.
+func (n *InboxNotifier) wait(project, agent string) <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := inboxKey{project, agent}
+	ch, ok := n.waiters[key]
+	if !ok {
+		ch = make(chan struct{})
+		n.waiters[key] = ch
+	}
+	return ch
+}
+
+func (n *InboxNotifier) wake(project, agent string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := inboxKey{project, agent}
+	if ch, ok := n.waiters[key]; ok {
+		close(ch)
+		delete(n.waiters, key)
+	}
+}
+
+// waitForInbox is the long-poll fallback for agents that can't hold a
+// WebSocket open: it returns as soon as messages past cursor exist, or an
+// empty result once wait elapses or the client goes away. Between reads it
+// sleeps until the inbox notifier reports a delivery to agent.
+func (s *Service) waitForInbox(ctx context.Context, project, agent string, cursor uint64, limit int, wait time.Duration) ([]core.Message, error) {
+	woken := s.inbox.wait(project, agent)
+	msgs, err := s.store.InboxSince(ctx, project, agent, cursor, limit)
+	if err != nil || len(msgs) > 0 || wait <= 0 {
+		return msgs, err
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	recheck := time.NewTicker(inboxRecheckInterval)
+	defer recheck.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-deadline.C:
+			return nil, nil
+		case <-woken:
+		case <-recheck.C:
+		}
+		woken = s.inbox.wait(project, agent)
+		msgs, err := s.store.InboxSince(ctx, project, agent, cursor, limit)
+		if err != nil || len(msgs) > 0 {
+			return msgs, err
+		}
+	}
+}
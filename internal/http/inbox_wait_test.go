@@ -0,0 +1,125 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInboxLongPollReturnsOnNewMessage(t *testing.T) {
+	env := newTestEnv(t)
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		buf, _ := json.Marshal(map[string]any{"project": "p", "from": "a", "to": []string{"b"}, "body": "wake up"})
+		if resp, err := http.Post(env.srv.URL+"/api/messages", "application/json", bytes.NewReader(buf)); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	start := time.Now()
+	resp := env.get(t, "/api/inbox/b?project=p&wait=10s")
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[inboxResponse](t, resp)
+	if len(out.Messages) != 1 || out.Messages[0].Body != "wake up" {
+		t.Fatalf("expected the new message, got %+v", out.Messages)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("long-poll did not return promptly: %v", elapsed)
+	}
+}
+
+func TestInboxLongPollTimesOutEmpty(t *testing.T) {
+	env := newTestEnv(t)
+
+	start := time.Now()
+	resp := env.get(t, "/api/inbox/b?project=p&since_cursor=7&wait=300ms")
+	requireStatus(t, resp, http.StatusOK)
+	out := decodeJSON[inboxResponse](t, resp)
+	if len(out.Messages) != 0 || out.Cursor != 7 {
+		t.Fatalf("expected empty response at cursor 7, got %+v", out)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Fatalf("returned before wait elapsed: %v", elapsed)
+	}
+
+	resp = env.get(t, "/api/inbox/b?project=p&wait=soon")
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+}
+
+func TestParseInboxWait(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":    0,
+		"30s": 30 * time.Second,
+		"15":  15 * time.Second,
+		"10m": maxInboxWait,
+	}
+	for in, want := range cases {
+		got, err := parseInboxWait(in)
+		if err != nil || got != want {
+			t.Fatalf("parseInboxWait(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseInboxWait("-1s"); err == nil {
+		t.Fatal("expected error for negative wait")
+	}
+}
+
+func TestInboxNotifierWakesAddressedWaiters(t *testing.T) {
+	n := NewInboxNotifier()
+	b, c := n.wait("p", "b"), n.wait("p", "c")
+	if n.wait("p", "b") != b {
+		t.Fatal("waiters on one inbox should share a channel")
+	}
+
+	n.Broadcast("p", "b", map[string]any{"type": "message.read"})
+	n.Broadcast("p", "", map[string]any{"type": "message.created"})
+	n.Broadcast("other", "b", map[string]any{"type": "message.created"})
+	select {
+	case <-b:
+		t.Fatal("woken by an event that isn't a delivery to b")
+	default:
+	}
+
+	n.Broadcast("p", "b", map[string]any{"type": "message.created"})
+	select {
+	case <-b:
+	default:
+		t.Fatal("delivery to b didn't wake its waiters")
+	}
+	select {
+	case <-c:
+		t.Fatal("delivery to b woke c")
+	default:
+	}
+	if n.wait("p", "b") == b {
+		t.Fatal("a woken channel should be replaced")
+	}
+}
+
+func TestInboxLongPollWakesWithoutPolling(t *testing.T) {
+	env := newTestEnv(t)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		buf, _ := json.Marshal(map[string]any{"project": "p", "from": "a", "to": []string{"b"}, "body": "now"})
+		if resp, err := http.Post(env.srv.URL+"/api/messages", "application/json", bytes.NewReader(buf)); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// Well inside inboxRecheckInterval, so only the notification can have
+	// woken the request.
+	start := time.Now()
+	resp := env.get(t, "/api/inbox/b?project=p&wait=30s")
+	requireStatus(t, resp, http.StatusOK)
+	if out := decodeJSON[inboxResponse](t, resp); len(out.Messages) != 1 {
+		t.Fatalf("expected the new message, got %+v", out.Messages)
+	}
+	if elapsed := time.Since(start); elapsed >= inboxRecheckInterval {
+		t.Fatalf("long-poll took %v; notification didn't wake it", elapsed)
+	}
+}
@@ -0,0 +1,29 @@
+package httpapi
+
+import (
+	"net/http"
+	"slices"
+)
+
+// archivedStatuses are the finished states list endpoints leave out by
+// default, per entity type.
+var archivedStatuses = map[string][]string{
+	"spec":    {"archived"},
+	"epic":    {"done"},
+	"story":   {"done"},
+	"task":    {"done"},
+	"insight": {"dismissed"},
+	"cuj":     {"archived"},
+}
+
+// withoutArchived drops items in an archived status for entityType, unless
+// the request asks for ?include_archived=true or filters by ?status=, in
+// which case the caller gets exactly what it asked for.
+func withoutArchived[T any](r *http.Request, entityType string, items []T, status func(T) string) []T {
+	q := r.URL.Query()
+	if q.Get("include_archived") == "true" || q.Get("status") != "" {
+		return items
+	}
+	archived := archivedStatuses[entityType]
+	return slices.DeleteFunc(items, func(item T) bool { return slices.Contains(archived, status(item)) })
+}
@@ -0,0 +1,43 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestListTasksHidesDoneUnlessAsked(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	for _, task := range []map[string]any{
+		{"project": project, "title": "Open", "status": "pending"},
+		{"project": project, "title": "Finished", "status": "done"},
+	} {
+		resp := env.post(t, "/api/tasks", task)
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	}
+
+	titles := func(query string) []string {
+		t.Helper()
+		resp := env.get(t, "/api/tasks?project="+project+query)
+		requireStatus(t, resp, http.StatusOK)
+		var out []string
+		for _, task := range decodeJSON[[]core.Task](t, resp) {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	if got := titles(""); len(got) != 1 || got[0] != "Open" {
+		t.Errorf("default list = %v, want [Open]", got)
+	}
+	if got := titles("&include_archived=true"); len(got) != 2 {
+		t.Errorf("include_archived list = %v, want both tasks", got)
+	}
+	if got := titles("&status=done"); len(got) != 1 || got[0] != "Finished" {
+		t.Errorf("status=done list = %v, want [Finished]", got)
+	}
+}
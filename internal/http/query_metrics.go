@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Histogram bounds for per-request SQL: how many queries a request issued
+// and how long they took in total.
+var (
+	queryCountBuckets   = []float64{1, 2, 5, 10, 20, 50, 100}
+	querySecondsBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+)
+
+// histogram is a cumulative Prometheus-style histogram.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // per bound, plus a trailing +Inf bucket
+	sum    float64
+	n      uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i, _ := slices.BinarySearch(h.bounds, v)
+	h.counts[i]++
+	h.sum += v
+	h.n++
+}
+
+// queryMetrics aggregates per-request query stats by route for /metrics.
+type queryMetrics struct {
+	mu      sync.Mutex
+	counts  map[string]*histogram
+	seconds map[string]*histogram
+}
+
+func newQueryMetrics() *queryMetrics {
+	return &queryMetrics{counts: map[string]*histogram{}, seconds: map[string]*histogram{}}
+}
+
+func (m *queryMetrics) observe(route string, count int, total time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts[route] == nil {
+		m.counts[route] = newHistogram(queryCountBuckets)
+		m.seconds[route] = newHistogram(querySecondsBuckets)
+	}
+	m.counts[route].observe(float64(count))
+	m.seconds[route].observe(total.Seconds())
+}
+
+func (m *queryMetrics) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	writeHistograms(w, "intermute_request_queries", "SQL queries issued per HTTP request, by route.", m.counts)
+	writeHistograms(w, "intermute_request_query_seconds", "Total SQL time per HTTP request, by route.", m.seconds)
+}
+
+func writeHistograms(w *bufio.Writer, name, help string, byRoute map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for _, route := range slices.Sorted(maps.Keys(byRoute)) {
+		h := byRoute[route]
+		label := escapeLabel(route)
+		var cum uint64
+		for i, bound := range h.bounds {
+			cum += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{route=\"%s\",le=\"%g\"} %d\n", name, label, bound, cum)
+		}
+		fmt.Fprintf(w, "%s_bucket{route=\"%s\",le=\"+Inf\"} %d\n", name, label, h.n)
+		fmt.Fprintf(w, "%s_sum{route=\"%s\"} %g\n", name, label, h.sum)
+		fmt.Fprintf(w, "%s_count{route=\"%s\"} %d\n", name, label, h.n)
+	}
+}
+
+// withQueryStats attaches storage.QueryStats to each request, keyed by the
+// mux pattern that matched it, and folds the totals into m once the handler
+// returns.
+func withQueryStats(m *queryMetrics, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Label by pattern, not path, so IDs don't explode the series count.
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, qs := storage.WithQueryStats(r.Context(), route)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		count, total := qs.Totals()
+		m.observe(route, count, total)
+	})
+}
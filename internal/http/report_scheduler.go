@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+)
+
+// ReportScheduler runs a background goroutine that periodically delivers
+// scheduled reports that have come due.
+type ReportScheduler struct {
+	svc      *DomainService
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewReportScheduler creates a new ReportScheduler. Call Start() to begin.
+func NewReportScheduler(svc *DomainService, interval time.Duration) *ReportScheduler {
+	return &ReportScheduler{
+		svc:      svc,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine. Reports that came due while the
+// server was down are delivered once on startup.
+func (rs *ReportScheduler) Start(ctx context.Context) {
+	ctx, rs.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(rs.done)
+
+		rs.run(ctx)
+
+		ticker := time.NewTicker(rs.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rs.run(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels the goroutine and waits for it to finish.
+func (rs *ReportScheduler) Stop() {
+	if rs.cancel != nil {
+		rs.cancel()
+	}
+	<-rs.done
+}
+
+func (rs *ReportScheduler) run(ctx context.Context) {
+	n, err := rs.svc.RunDueReports(ctx, clock.Now().UTC())
+	if err != nil {
+		log.Printf("report scheduler: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("report scheduler: delivered %d report(s)", n)
+	}
+}
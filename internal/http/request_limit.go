@@ -0,0 +1,173 @@
+package httpapi
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+)
+
+// Defaults for serve's per-caller request rate limit.
+const (
+	DefaultRequestsPerMinute = 1200
+	DefaultRequestBurst      = 200
+)
+
+// requestLimiter is a token bucket per caller: the API key's fingerprint
+// for keyed requests, the remote IP for unauthenticated localhost ones.
+// Buckets refill at perMinute/60 tokens a second up to burst.
+type requestLimiter struct {
+	perMinute int // 0 disables limiting
+	burst     int
+	// now is injectable for tests; defaults to time.Now.
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	allowed   map[string]uint64 // by principal
+	rejected  map[string]uint64
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	at     time.Time
+}
+
+func newRequestLimiter(perMinute, burst int) *requestLimiter {
+	return &requestLimiter{
+		perMinute: perMinute,
+		burst:     burst,
+		buckets:   map[string]*tokenBucket{},
+		allowed:   map[string]uint64{},
+		rejected:  map[string]uint64{},
+	}
+}
+
+// WithRequestRateLimit limits each API key (or, for unauthenticated
+// localhost callers, each remote IP) to perMinute requests, with bursts of
+// up to burst. A perMinute of 0 disables the limit.
+func (s *Service) WithRequestRateLimit(perMinute, burst int) *Service {
+	s.requests.mu.Lock()
+	defer s.requests.mu.Unlock()
+	s.requests.perMinute = max(perMinute, 0)
+	s.requests.burst = max(burst, 1)
+	clear(s.requests.buckets)
+	return s
+}
+
+func (s *DomainService) WithRequestRateLimit(perMinute, burst int) *DomainService {
+	s.Service.WithRequestRateLimit(perMinute, burst)
+	return s
+}
+
+// limit returns the configured requests per minute, 0 when unlimited.
+func (l *requestLimiter) limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.perMinute
+}
+
+// requestPrincipal names the caller a request is counted against.
+func requestPrincipal(r *http.Request) string {
+	if info, ok := auth.FromContext(r.Context()); ok && info.Mode == auth.ModeAPIKey && info.KeyID != "" {
+		return "key:" + info.KeyID
+	}
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" || host == "@" {
+		host = "local" // unix socket
+	}
+	return "ip:" + host
+}
+
+// wrap answers 429 with Retry-After once the caller's bucket is empty. It
+// must run inside the auth middleware, which identifies the key.
+func (l *requestLimiter) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retry := l.allow(requestPrincipal(r))
+		if !ok {
+			seconds := int(math.Ceil(retry.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeJSONErrorFields(w, http.StatusTooManyRequests, "request rate limit exceeded", "rate_limited", map[string]any{
+				"retry_after_seconds": seconds,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from principal's bucket. When there is none it
+// reports how long until there will be.
+func (l *requestLimiter) allow(principal string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perMinute <= 0 {
+		return true, 0
+	}
+	now := time.Now()
+	if l.now != nil {
+		now = l.now()
+	}
+	rate := float64(l.perMinute) / 60 // tokens per second
+	l.sweep(now, rate)
+
+	b, ok := l.buckets[principal]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.burst), at: now}
+		l.buckets[principal] = b
+	}
+	b.tokens = min(float64(l.burst), b.tokens+now.Sub(b.at).Seconds()*rate)
+	b.at = now
+	if b.tokens < 1 {
+		l.rejected[principal]++
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	l.allowed[principal]++
+	return true, 0
+}
+
+// sweep drops buckets that have refilled completely, at most once a
+// minute, so callers that went away don't accumulate.
+func (l *requestLimiter) sweep(now time.Time, rate float64) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for p, b := range l.buckets {
+		if b.tokens+now.Sub(b.at).Seconds()*rate >= float64(l.burst) {
+			delete(l.buckets, p)
+		}
+	}
+}
+
+func (l *requestLimiter) write(w *bufio.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	counters := []struct {
+		name, help string
+		values     map[string]uint64
+	}{
+		{"intermute_http_rate_limit_allowed_total", "Requests let through by the per-caller rate limit, by caller.", l.allowed},
+		{"intermute_http_rate_limit_rejected_total", "Requests refused with 429 by the per-caller rate limit, by caller.", l.rejected},
+	}
+	for _, m := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s counter\n", m.name)
+		for _, p := range slices.Sorted(maps.Keys(m.values)) {
+			fmt.Fprintf(w, "%s{principal=%q} %d\n", m.name, p, m.values[p])
+		}
+	}
+}
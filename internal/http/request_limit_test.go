@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestRequestLimiterRefills(t *testing.T) {
+	l := newRequestLimiter(60, 2) // one token a second
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.allow("key:a"); !ok {
+			t.Fatalf("request %d within burst refused", i)
+		}
+	}
+	ok, retry := l.allow("key:a")
+	if ok {
+		t.Fatal("request past burst allowed")
+	}
+	if retry <= 0 || retry > time.Second {
+		t.Fatalf("retry = %v, want (0, 1s]", retry)
+	}
+	if ok, _ := l.allow("key:b"); !ok {
+		t.Fatal("another caller shares the first caller's bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.allow("key:a"); !ok {
+		t.Fatal("no token after refilling for a second")
+	}
+	if ok, _ := l.allow("key:a"); ok {
+		t.Fatal("refilled more than one token in a second")
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	l.write(w)
+	w.Flush()
+	for _, want := range []string{
+		`intermute_http_rate_limit_allowed_total{principal="key:a"} 3`,
+		`intermute_http_rate_limit_rejected_total{principal="key:a"} 2`,
+		`intermute_http_rate_limit_allowed_total{principal="key:b"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRequestRateLimitPerAPIKey(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	svc := NewService(st).WithRequestRateLimit(60, 3)
+	ring := auth.NewKeyring(true, map[string]string{"secret-a": "proj-a", "secret-b": "proj-b"})
+	h := NewRouter(svc, nil, auth.Middleware(ring))
+
+	get := func(key, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/agents", nil)
+		req.RemoteAddr = remote
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for range 3 {
+		if rr := get("secret-a", "203.0.113.10:9999"); rr.Code != http.StatusOK {
+			t.Fatalf("within burst: status %d", rr.Code)
+		}
+	}
+	// Same key from another address: still the same bucket.
+	rr := get("secret-a", "203.0.113.11:9999")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("past burst: status %d, want 429", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Retry-After = %q, want 1", rr.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(rr.Body.String(), `"rate_limited"`) {
+		t.Fatalf("body = %s", rr.Body.String())
+	}
+
+	if rr := get("secret-b", "203.0.113.10:9999"); rr.Code != http.StatusOK {
+		t.Fatalf("another key throttled with the first: status %d", rr.Code)
+	}
+	// Unauthenticated localhost callers are limited by address.
+	for range 3 {
+		if rr := get("", "127.0.0.1:5000"); rr.Code != http.StatusOK {
+			t.Fatalf("localhost within burst: status %d", rr.Code)
+		}
+	}
+	if rr := get("", "127.0.0.1:5001"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("localhost past burst: status %d, want 429", rr.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// RetentionPurger runs a background goroutine that periodically applies
+// every project's retention policy, then collects the index rows left
+// without a message or thread.
+type RetentionPurger struct {
+	svc      *DomainService
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewRetentionPurger creates a new RetentionPurger. Call Start() to begin.
+func NewRetentionPurger(svc *DomainService, interval time.Duration) *RetentionPurger {
+	return &RetentionPurger{
+		svc:      svc,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine. The first purge waits for the
+// first tick so startup isn't slowed by a large backlog.
+func (p *RetentionPurger) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := p.svc.RunRetention(ctx, ""); err != nil {
+					log.Printf("retention purger: %v", err)
+					p.svc.notifySystem(core.EventSystemRetentionFailed, map[string]any{"error": err.Error()})
+				}
+				if _, err := p.svc.CollectGarbage(ctx); err != nil {
+					log.Printf("retention purger: gc: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop cancels the goroutine and waits for it to finish.
+func (p *RetentionPurger) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	<-p.done
+}
@@ -5,17 +5,23 @@ import "net/http"
 func NewRouter(svc *Service, wsHandler http.Handler, mw func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 	wrap := func(h http.HandlerFunc) http.Handler {
-		handler := http.Handler(h)
+		handler := svc.requests.wrap(withQueryStats(svc.queries, withETag(h)))
 		if mw != nil {
 			handler = mw(handler)
 		}
-		return handler
+		return withTrace(withEventCursor(svc.compress.wrap(handler)))
 	}
 	mux.Handle("/api/agents", wrap(svc.handleAgents))
 	mux.Handle("/api/agents/presence", wrap(svc.handleAgentPresence))
 	mux.Handle("/api/agents/", wrap(svc.handleAgentSubpath))
 	mux.Handle("/api/messages", wrap(svc.handleSendMessage))
 	mux.Handle("/api/messages/", wrap(svc.handleMessageAction))
+	mux.Handle("/api/blobs", wrap(svc.handleBlobs))
+	mux.Handle("/api/blobs/", wrap(svc.handleBlobByID))
+	mux.Handle("/api/groups", wrap(svc.handleGroups))
+	mux.Handle("/api/groups/", wrap(svc.handleGroupByName))
+	mux.Handle("/api/agent-capabilities", wrap(svc.handleAgentCapabilities))
+	mux.Handle("/api/agent-capabilities/", wrap(svc.handleAgentCapabilityByName))
 	mux.Handle("/api/inbox/pokes", wrap(svc.handleInboxPokes))
 	mux.Handle("/api/inbox/pokes/", wrap(svc.handleInboxPokeAction))
 	mux.Handle("/api/inbox/", wrap(svc.handleInbox))
@@ -29,11 +35,13 @@ func NewRouter(svc *Service, wsHandler http.Handler, mw func(http.Handler) http.
 	mux.Handle("/api/windows", wrap(svc.handleWindows))
 	mux.Handle("/api/windows/", wrap(svc.handleWindowByID))
 	if wsHandler != nil {
+		wsHandler = svc.requests.wrap(wsHandler)
 		if mw != nil {
-			mux.Handle("/ws/agents/", mw(wsHandler))
-		} else {
-			mux.Handle("/ws/agents/", wsHandler)
+			wsHandler = mw(wsHandler)
 		}
+		mux.Handle("/ws/agents/", wsHandler)
+		mux.Handle("/ws/system", wsHandler)
 	}
+
 	return mux
 }
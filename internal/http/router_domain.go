@@ -6,17 +6,24 @@ import "net/http"
 func NewDomainRouter(svc *DomainService, wsHandler http.Handler, mw func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 	wrap := func(h http.HandlerFunc) http.Handler {
-		handler := http.Handler(h)
+		handler := svc.requests.wrap(withQueryStats(svc.queries, withETag(svc.withIdempotency(h))))
 		if mw != nil {
 			handler = mw(handler)
 		}
-		return handler
+		return withTrace(withEventCursor(svc.compress.wrap(handler)))
 	}
 
 	// Health check (unauthenticated). Reports actual DB liveness when svc
 	// has a Pinger wired in; falls back to hardcoded-ok in test setups
 	// that don't bother.
 	mux.HandleFunc("/health", newHealthHandler(svc.pinger))
+	// Readiness (unauthenticated): DB liveness plus circuit breaker state.
+	mux.HandleFunc("/readyz", svc.handleReady)
+
+	// Feature discovery
+	mux.Handle("/api/capabilities", wrap(svc.handleCapabilities))
+	mux.Handle("/api/schemas", wrap(svc.handleSchemas))
+	mux.Handle("/api/schemas/", wrap(svc.handleSchemas))
 
 	// File reservations
 	mux.Handle("/api/reservations", wrap(svc.handleReservations))
@@ -30,9 +37,15 @@ func NewDomainRouter(svc *DomainService, wsHandler http.Handler, mw func(http.Ha
 	// Existing messaging endpoints
 	mux.Handle("/api/agents", wrap(svc.handleAgents))
 	mux.Handle("/api/agents/presence", wrap(svc.handleAgentPresence))
-	mux.Handle("/api/agents/", wrap(svc.handleAgentSubpath))
-	mux.Handle("/api/messages", wrap(svc.handleSendMessage))
+	mux.Handle("/api/agents/", wrap(svc.handleDomainAgentSubpath))
+	mux.Handle("/api/messages", wrap(svc.handleDomainSendMessage))
 	mux.Handle("/api/messages/", wrap(svc.handleMessageAction))
+	mux.Handle("/api/blobs", wrap(svc.handleBlobs))
+	mux.Handle("/api/blobs/", wrap(svc.handleBlobByID))
+	mux.Handle("/api/groups", wrap(svc.handleGroups))
+	mux.Handle("/api/groups/", wrap(svc.handleGroupByName))
+	mux.Handle("/api/agent-capabilities", wrap(svc.handleAgentCapabilities))
+	mux.Handle("/api/agent-capabilities/", wrap(svc.handleAgentCapabilityByName))
 	mux.Handle("/api/inbox/pokes", wrap(svc.handleInboxPokes))
 	mux.Handle("/api/inbox/pokes/", wrap(svc.handleInboxPokeAction))
 	mux.Handle("/api/inbox/", wrap(svc.handleInbox))
@@ -41,6 +54,36 @@ func NewDomainRouter(svc *DomainService, wsHandler http.Handler, mw func(http.Ha
 	mux.Handle("/api/topics/", wrap(svc.handleTopicMessages))
 	mux.Handle("/api/broadcast", wrap(svc.handleBroadcast))
 
+	// Operator views (localhost only)
+	mux.Handle("/api/admin/overview", wrap(svc.handleAdminOverview))
+	mux.Handle("/api/admin/keys", wrap(svc.handleAdminKeys))
+	mux.Handle("/api/admin/keys/", wrap(svc.handleAdminKeyByID))
+	mux.Handle("/api/admin/storage", wrap(svc.handleAdminStorage))
+	mux.Handle("/api/admin/counts", wrap(svc.handleAdminCounts))
+	mux.Handle("/api/admin/archives", wrap(svc.handleAdminArchives))
+	mux.Handle("/api/admin/projects/", wrap(svc.handleAdminProjectByName))
+	mux.Handle("/api/admin/retention", wrap(svc.handleAdminRetention))
+	mux.Handle("/api/admin/retention/run", wrap(svc.handleAdminRetentionRun))
+	mux.Handle("/api/admin/gc/run", wrap(svc.handleAdminGCRun))
+	mux.Handle("/api/admin/flags", wrap(svc.handleAdminFlags))
+	mux.Handle("/api/admin/flags/", wrap(svc.handleAdminFlagByName))
+	mux.Handle("/api/admin/clock", wrap(svc.handleAdminClock))
+	mux.Handle("/metrics", wrap(svc.handleMetrics))
+
+	// Project bootstrap
+	mux.Handle("/api/projects", wrap(svc.handleProjects))
+	mux.Handle("/api/projects/", wrap(svc.handleProjectByName))
+
+	// Batch lookups by ID (more specific than the /{id} prefixes below)
+	mux.Handle("/api/specs/batch-get", wrap(batchGet(acceptShortIDs(svc.domainStore, "spec", svc.domainStore.GetSpec))))
+	mux.Handle("/api/epics/batch-get", wrap(batchGet(acceptShortIDs(svc.domainStore, "epic", svc.domainStore.GetEpic))))
+	mux.Handle("/api/stories/batch-get", wrap(batchGet(acceptShortIDs(svc.domainStore, "story", svc.domainStore.GetStory))))
+	mux.Handle("/api/tasks/batch-get", wrap(batchGet(acceptShortIDs(svc.domainStore, "task", svc.domainStore.GetTask))))
+	mux.Handle("/api/insights/batch-get", wrap(batchGet(svc.domainStore.GetInsight)))
+	mux.Handle("/api/sessions/batch-get", wrap(batchGet(svc.domainStore.GetSession)))
+	mux.Handle("/api/cujs/batch-get", wrap(batchGet(svc.domainStore.GetCUJ)))
+	mux.Handle("/api/goals/batch-get", wrap(batchGet(svc.domainStore.GetGoal)))
+
 	// Domain endpoints
 	mux.Handle("/api/specs", wrap(svc.handleSpecs))
 	mux.Handle("/api/specs/", wrap(svc.handleSpecByID))
@@ -49,21 +92,43 @@ func NewDomainRouter(svc *DomainService, wsHandler http.Handler, mw func(http.Ha
 	mux.Handle("/api/stories", wrap(svc.handleStories))
 	mux.Handle("/api/stories/", wrap(svc.handleStoryByID))
 	mux.Handle("/api/tasks", wrap(svc.handleTasks))
+	mux.Handle("/api/tasks/claim", wrap(svc.handleTaskClaim))
+	mux.Handle("/api/tasks/bulk-status", wrap(svc.handleBulkStatus("/api/tasks")))
+	mux.Handle("/api/stories/bulk-status", wrap(svc.handleBulkStatus("/api/stories")))
+	mux.Handle("/api/tasks/leases/", wrap(svc.handleTaskLease))
+	mux.Handle("/api/tasks/conflicts", wrap(svc.handleTaskConflicts))
+	mux.Handle("/api/tasks/unblock", wrap(svc.handleTaskUnblock))
 	mux.Handle("/api/tasks/", wrap(svc.handleTaskByID))
 	mux.Handle("/api/insights", wrap(svc.handleInsights))
 	mux.Handle("/api/insights/", wrap(svc.handleInsightByID))
+	mux.Handle("/api/insight-rules", wrap(svc.handleInsightRules))
+	mux.Handle("/api/insight-rules/", wrap(svc.handleInsightRuleByID))
+	mux.Handle("/api/hooks", wrap(svc.handleLifecycleHooks))
+	mux.Handle("/api/hooks/", wrap(svc.handleLifecycleHookByID))
 	mux.Handle("/api/sessions", wrap(svc.handleSessions))
 	mux.Handle("/api/sessions/", wrap(svc.handleSessionByID))
 	mux.Handle("/api/cujs", wrap(svc.handleCUJs))
 	mux.Handle("/api/cujs/", wrap(svc.handleCUJByID))
+	mux.Handle("/api/batch", wrap(svc.handleBatch))
+	mux.Handle("/api/goals", wrap(svc.handleGoals))
+	mux.Handle("/api/goals/", wrap(svc.handleGoalByID))
+	mux.Handle("/api/search", wrap(svc.handleSearch))
+	mux.Handle("/api/lookup/", wrap(svc.handleLookup))
+	mux.Handle("/api/events", wrap(svc.handleEvents))
+	mux.Handle("/api/events/count", wrap(svc.handleEventCount))
+	mux.Handle("/api/anomalies", wrap(svc.handleAnomalies))
+	mux.Handle("/api/reports", wrap(svc.handleReports))
+	mux.Handle("/api/reports/sessions", wrap(svc.handleSessionAnalytics))
+	mux.Handle("/api/reports/", wrap(svc.handleReportByID))
 
 	// WebSocket
 	if wsHandler != nil {
+		wsHandler = svc.requests.wrap(wsHandler)
 		if mw != nil {
-			mux.Handle("/ws/agents/", mw(wsHandler))
-		} else {
-			mux.Handle("/ws/agents/", wsHandler)
+			wsHandler = mw(wsHandler)
 		}
+		mux.Handle("/ws/agents/", wsHandler)
+		mux.Handle("/ws/system", wsHandler)
 	}
 
 	return mux
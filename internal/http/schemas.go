@@ -0,0 +1,169 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Entity schemas
+//
+// GET /api/schemas/{entity} describes an entity's JSON shape as a JSON
+// Schema (draft 2020-12) generated from its core struct, so non-Go
+// consumers can validate payloads before sending and build forms. Go has
+// no reflection over constants, so the enums are listed here; a test
+// keeps them in step with core.
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEntity is an entity type served under /api/schemas. defaults are
+// the values the server fills in for fields left empty on create.
+type schemaEntity struct {
+	typ      reflect.Type
+	defaults map[string]string
+}
+
+var schemaEntities = map[string]schemaEntity{
+	"spec":    {reflect.TypeFor[core.Spec](), map[string]string{"status": string(core.SpecStatusDraft)}},
+	"epic":    {reflect.TypeFor[core.Epic](), map[string]string{"status": string(core.EpicStatusOpen)}},
+	"story":   {reflect.TypeFor[core.Story](), map[string]string{"status": string(core.StoryStatusTodo), "priority": string(core.PriorityMedium)}},
+	"task":    {reflect.TypeFor[core.Task](), map[string]string{"status": string(core.TaskStatusPending), "priority": string(core.PriorityMedium)}},
+	"insight": {reflect.TypeFor[core.Insight](), map[string]string{"status": string(core.InsightStatusNew)}},
+	"session": {reflect.TypeFor[core.Session](), nil},
+	"cuj":     {reflect.TypeFor[core.CriticalUserJourney](), nil},
+	"goal":    {reflect.TypeFor[core.Goal](), nil},
+}
+
+// schemaEnums lists the values of core's string enums.
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeFor[core.SpecStatus]():    {string(core.SpecStatusDraft), string(core.SpecStatusResearch), string(core.SpecStatusValidated), string(core.SpecStatusArchived)},
+	reflect.TypeFor[core.EpicStatus]():    {string(core.EpicStatusOpen), string(core.EpicStatusInProgress), string(core.EpicStatusDone)},
+	reflect.TypeFor[core.StoryStatus]():   {string(core.StoryStatusTodo), string(core.StoryStatusInProgress), string(core.StoryStatusReview), string(core.StoryStatusDone)},
+	reflect.TypeFor[core.TaskStatus]():    {string(core.TaskStatusPending), string(core.TaskStatusRunning), string(core.TaskStatusBlocked), string(core.TaskStatusDone)},
+	reflect.TypeFor[core.Priority]():      {string(core.PriorityHigh), string(core.PriorityMedium), string(core.PriorityLow)},
+	reflect.TypeFor[core.BlockedReason](): {string(core.BlockedReasonDependency), string(core.BlockedReasonReservation), string(core.BlockedReasonExternal), string(core.BlockedReasonNeedsInput), string(core.BlockedReasonOther)},
+	reflect.TypeFor[core.InsightStatus](): {string(core.InsightStatusNew), string(core.InsightStatusTriaged), string(core.InsightStatusActioned), string(core.InsightStatusDismissed)},
+	reflect.TypeFor[core.SessionStatus](): {string(core.SessionStatusRunning), string(core.SessionStatusIdle), string(core.SessionStatusError)},
+	reflect.TypeFor[core.CUJStatus]():     {string(core.CUJStatusDraft), string(core.CUJStatusValidated), string(core.CUJStatusArchived)},
+	reflect.TypeFor[core.CUJPriority]():   {string(core.CUJPriorityHigh), string(core.CUJPriorityMedium), string(core.CUJPriorityLow)},
+	reflect.TypeFor[core.GoalStatus]():    {string(core.GoalStatusActive), string(core.GoalStatusAchieved), string(core.GoalStatusAbandoned)},
+}
+
+// schemaReadOnly are fields only the server sets; clients may leave them
+// out and the server ignores what they send.
+var schemaReadOnly = map[string]bool{
+	"short_id": true, "version": true, "created_at": true, "updated_at": true,
+	"started_at": true, "content_hash": true, "comment_count": true,
+}
+
+// entitySchema builds the JSON Schema for a registered entity type.
+func entitySchema(name string) (map[string]any, bool) {
+	ent, ok := schemaEntities[name]
+	if !ok {
+		return nil, false
+	}
+	schema := structSchema(ent.typ, ent.defaults)
+	schema["$schema"] = jsonSchemaDialect
+	schema["$id"] = "/api/schemas/" + name
+	schema["title"] = name
+	return schema, true
+}
+
+// structSchema describes a struct by its JSON fields. A field is required
+// unless it is omitempty, read-only, generated (id) or defaulted.
+func structSchema(t reflect.Type, defaults map[string]string) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := typeSchema(f.Type)
+		if schemaReadOnly[name] {
+			prop["readOnly"] = true
+		}
+		def, hasDefault := defaults[name]
+		if hasDefault {
+			prop["default"] = def
+		}
+		props[name] = prop
+		omitempty := slices.Contains(strings.Split(opts, ","), "omitempty")
+		if !omitempty && !schemaReadOnly[name] && !hasDefault && name != "id" {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t, nil)
+	}
+	return map[string]any{} // any value
+}
+
+type schemaIndexResponse struct {
+	Entities []string `json:"entities"`
+}
+
+// handleSchemas serves GET /api/schemas, the entity types with a schema,
+// and GET /api/schemas/{entity}.
+func (s *DomainService) handleSchemas(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/schemas"), "/")
+	if name == "" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(schemaIndexResponse{Entities: slices.Sorted(maps.Keys(schemaEntities))})
+		return
+	}
+	schema, ok := entitySchema(name)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "no schema for "+name, "not_found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	_ = json.NewEncoder(w).Encode(schema)
+}
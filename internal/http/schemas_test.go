@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// The enums are listed by hand; every status the transition tables know
+// must be in its entity's enum.
+func TestSchemaEnumsCoverTransitions(t *testing.T) {
+	for entity, table := range core.AllowedTransitions {
+		ent, ok := schemaEntities[entity]
+		if !ok {
+			t.Errorf("no schema for %s", entity)
+			continue
+		}
+		f, ok := ent.typ.FieldByName("Status")
+		if !ok {
+			t.Fatalf("%s has no Status field", entity)
+		}
+		enum := schemaEnums[f.Type]
+		for from, tos := range table {
+			for _, status := range append([]string{from}, tos...) {
+				if !slices.Contains(enum, status) {
+					t.Errorf("%s status %q missing from the %s enum", entity, status, f.Type)
+				}
+			}
+		}
+	}
+	for _, p := range schemaEnums[reflect.TypeFor[core.Priority]()] {
+		if !core.ValidPriority(core.Priority(p)) {
+			t.Errorf("priority enum has unknown %q", p)
+		}
+	}
+}
+
+func TestEntitySchemas(t *testing.T) {
+	env := newTestEnv(t)
+
+	index := decodeJSON[schemaIndexResponse](t, env.get(t, "/api/schemas"))
+	if !slices.Contains(index.Entities, "task") || len(index.Entities) != len(schemaEntities) {
+		t.Fatalf("entities = %v", index.Entities)
+	}
+
+	resp := env.get(t, "/api/schemas/task")
+	requireStatus(t, resp, http.StatusOK)
+	if ct := resp.Header.Get("Content-Type"); ct != "application/schema+json" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	schema := decodeJSON[map[string]any](t, resp)
+	if schema["$schema"] != jsonSchemaDialect || schema["type"] != "object" {
+		t.Fatalf("schema header = %v / %v", schema["$schema"], schema["type"])
+	}
+	props := schema["properties"].(map[string]any)
+	status := props["status"].(map[string]any)
+	if status["default"] != "pending" || len(status["enum"].([]any)) != 4 {
+		t.Fatalf("status = %v", status)
+	}
+	if props["created_at"].(map[string]any)["format"] != "date-time" || props["created_at"].(map[string]any)["readOnly"] != true {
+		t.Fatalf("created_at = %v", props["created_at"])
+	}
+	unblock := props["unblock_when"].(map[string]any)
+	if unblock["type"] != "object" || unblock["properties"].(map[string]any)["task_id"] == nil {
+		t.Fatalf("unblock_when = %v", unblock)
+	}
+	if props["capabilities"].(map[string]any)["type"] != "array" {
+		t.Fatalf("capabilities = %v", props["capabilities"])
+	}
+	var required []string
+	for _, r := range schema["required"].([]any) {
+		required = append(required, r.(string))
+	}
+	slices.Sort(required)
+	if !slices.Equal(required, []string{"project", "title"}) {
+		t.Fatalf("required = %v, want [project title]", required)
+	}
+
+	resp = env.get(t, "/api/schemas/widget")
+	requireStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
@@ -3,6 +3,8 @@ package httpapi
 import (
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/anomaly"
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/livetransport"
 	"github.com/mistakeknot/intermute/internal/storage"
@@ -14,12 +16,35 @@ type Service struct {
 	bcastRL      *rateLimiter
 	liveDelivery livetransport.LiveDelivery
 	liveLimiter  *rateLimiter
+	anomalies    *anomaly.Detector
+	held         *heldBroadcasts // set while bound to an atomic batch
+	queries      *queryMetrics
+	inbox        *InboxNotifier
+	limits       MessageLimits
+	compress     *compressor
+	requests     *requestLimiter
 }
 
 type Broadcaster interface {
 	Broadcast(project, agent string, event any)
 }
 
+// SystemNotifier receives operational events, such as the WebSocket hub's
+// system topic.
+type SystemNotifier interface {
+	NotifySystem(ev core.SystemEvent)
+}
+
+// Broadcasters fans each broadcast out to several broadcasters, such as
+// the WebSocket hub and the gRPC event stream.
+type Broadcasters []Broadcaster
+
+func (bs Broadcasters) Broadcast(project, agent string, event any) {
+	for _, b := range bs {
+		b.Broadcast(project, agent, event)
+	}
+}
+
 const (
 	broadcastRateLimit  = 10
 	broadcastRateWindow = time.Minute
@@ -33,14 +58,27 @@ func NewService(store storage.Store) *Service {
 		bcastRL:      newRateLimiter(broadcastRateLimit, broadcastRateWindow),
 		liveDelivery: noopLiveDelivery{},
 		liveLimiter:  newRateLimiter(liveRateLimit, liveRateWindow),
+		anomalies:    anomaly.NewDetector(anomaly.Config{}),
+		queries:      newQueryMetrics(),
+		inbox:        NewInboxNotifier(),
+		compress:     newCompressor(DefaultCompressMinBytes),
+		requests:     newRequestLimiter(0, DefaultRequestBurst),
 	}
 }
 
+// WithBroadcaster sends events to b, and to the service's inbox notifier
+// so long-polls wake on deliveries.
 func (s *Service) WithBroadcaster(b Broadcaster) *Service {
-	s.bus = b
+	s.bus = Broadcasters{b, s.inbox}
 	return s
 }
 
+// InboxNotifier returns the notifier that wakes this service's inbox
+// long-polls, for adding to broadcasters the service doesn't own.
+func (s *Service) InboxNotifier() *InboxNotifier {
+	return s.inbox
+}
+
 func (s *Service) WithLiveDelivery(d livetransport.LiveDelivery) *Service {
 	if d == nil {
 		s.liveDelivery = noopLiveDelivery{}
@@ -50,6 +88,40 @@ func (s *Service) WithLiveDelivery(d livetransport.LiveDelivery) *Service {
 	return s
 }
 
+// WithAnomalyDetector replaces the default-threshold anomaly detector.
+func (s *Service) WithAnomalyDetector(d *anomaly.Detector) *Service {
+	if d != nil {
+		s.anomalies = d
+	}
+	return s
+}
+
+// observe feeds the anomaly detector and reports what it flags. Inside an
+// atomic batch the observation is held until the batch commits.
+func (s *Service) observe(fn func(d *anomaly.Detector) []core.Anomaly) {
+	if s.held != nil {
+		s.held.hold(fn)
+		return
+	}
+	s.reportAnomalies(fn(s.anomalies))
+}
+
+// reportAnomalies emits a coordination.anomaly event per flagged pattern.
+func (s *Service) reportAnomalies(found []core.Anomaly) {
+	if s.bus == nil {
+		return
+	}
+	for _, a := range found {
+		s.bus.Broadcast(a.Project, "", map[string]any{
+			"type":      string(core.EventCoordinationAnomaly),
+			"event_id":  uuid.NewString(),
+			"project":   a.Project,
+			"entity_id": a.Subject,
+			"data":      a,
+		})
+	}
+}
+
 // noopLiveDelivery is a no-op implementation used when the service is
 // constructed without a real Injector (tests, async-only deployments).
 // Deliver returns nil — a silent success — so that test harnesses exercising
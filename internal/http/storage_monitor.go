@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StorageMonitor runs a background goroutine that periodically checks the
+// database size against the service's StorageLimits and alerts when a
+// threshold is crossed. Each level alerts once until the size drops back
+// below it.
+type StorageMonitor struct {
+	svc      *DomainService
+	interval time.Duration
+	level    string
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewStorageMonitor creates a new StorageMonitor. Call Start() to begin.
+func NewStorageMonitor(svc *DomainService, interval time.Duration) *StorageMonitor {
+	return &StorageMonitor{
+		svc:      svc,
+		interval: interval,
+		level:    StorageLevelOK,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine, checking once immediately so an
+// oversized database is reported at startup.
+func (m *StorageMonitor) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(m.done)
+
+		m.run(ctx)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.run(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels the goroutine and waits for it to finish.
+func (m *StorageMonitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	<-m.done
+}
+
+func (m *StorageMonitor) run(ctx context.Context) {
+	level, err := m.svc.CheckStorage(ctx, m.level)
+	if err != nil {
+		log.Printf("storage monitor: %v", err)
+		return
+	}
+	m.level = level
+}
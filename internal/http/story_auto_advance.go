@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/mistakeknot/intermute/internal/anomaly"
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Story auto-advance
+//
+// Completing a story's last open task can move the story on in the same
+// transaction as the task update, so two agents finishing the story's last
+// tasks at once can't both miss (or both make) the move. Projects opt in
+// with core.FlagStoryAutoAdvance, which advances to review; a PUT can ask
+// for ?advance_story=review or done, or opt out with none.
+
+const advanceStoryParam = "advance_story"
+
+// storyOrder ranks story statuses so a story is only ever moved forward.
+var storyOrder = []core.StoryStatus{core.StoryStatusTodo, core.StoryStatusInProgress, core.StoryStatusReview, core.StoryStatusDone}
+
+// storyAdvanceTarget returns the status to advance the story of a task
+// being completed to, "" for none. It writes 400 for an unknown
+// ?advance_story= value and returns false.
+func (s *DomainService) storyAdvanceTarget(w http.ResponseWriter, r *http.Request, project string) (core.StoryStatus, bool) {
+	switch v := r.URL.Query().Get(advanceStoryParam); v {
+	case "":
+		if s.flagEnabled(r.Context(), project, core.FlagStoryAutoAdvance) {
+			return core.StoryStatusReview, true
+		}
+		return "", true
+	case "none":
+		return "", true
+	case string(core.StoryStatusReview), string(core.StoryStatusDone):
+		return core.StoryStatus(v), true
+	default:
+		writeJSONError(w, http.StatusBadRequest, advanceStoryParam+" must be review, done or none", "invalid_request")
+		return "", false
+	}
+}
+
+// storyAdvance is a story moved on by a task completion.
+type storyAdvance struct {
+	Story core.Story
+	From  core.StoryStatus
+}
+
+// updateTaskAdvancingStory saves task and, when it completes the last open
+// task of its story, moves the story to target in the same transaction.
+// Without a target, or for a task that isn't newly done, it is a plain
+// UpdateTask.
+func (s *DomainService) updateTaskAdvancingStory(r *http.Request, task core.Task, before *core.Task, target core.StoryStatus) (core.Task, *storyAdvance, error) {
+	ctx := r.Context()
+	if target == "" || task.StoryID == "" || task.Status != core.TaskStatusDone ||
+		(before != nil && before.Status == core.TaskStatusDone) {
+		updated, err := s.domainStore.UpdateTask(ctx, task)
+		return updated, nil, err
+	}
+	strict := s.strictTransitionsEnabled(r, task.Project, "story")
+	var (
+		updated  core.Task
+		advanced *storyAdvance
+		opErr    error
+	)
+	err := s.domainStore.RunInTx(ctx, func(tx storage.DomainStore) bool {
+		updated, opErr = tx.UpdateTask(ctx, task)
+		if opErr != nil {
+			return false
+		}
+		advanced, opErr = advanceStory(ctx, tx, updated, target, strict)
+		return opErr == nil
+	})
+	if err != nil {
+		return core.Task{}, nil, err
+	}
+	if opErr != nil {
+		return core.Task{}, nil, opErr
+	}
+	return updated, advanced, nil
+}
+
+// advanceStory moves done's story to target if none of its tasks are still
+// open and the story is behind target. Under strict transitions a move the
+// table doesn't allow is skipped rather than failing the task update.
+func advanceStory(ctx context.Context, tx storage.DomainStore, done core.Task, target core.StoryStatus, strict bool) (*storyAdvance, error) {
+	tasks, err := tx.ListTasks(ctx, done.Project, "", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if t.StoryID == done.StoryID && t.Status != core.TaskStatusDone {
+			return nil, nil
+		}
+	}
+	story, err := tx.GetStory(ctx, done.Project, done.StoryID)
+	if err != nil {
+		// A dangling story ID doesn't stop the task completing.
+		return nil, nil
+	}
+	from := story.Status
+	if slices.Index(storyOrder, from) >= slices.Index(storyOrder, target) {
+		return nil, nil
+	}
+	if strict && !core.CanTransition("story", string(from), string(target)) {
+		return nil, nil
+	}
+	story.Status = target
+	updated, err := tx.UpdateStory(ctx, story)
+	if err != nil {
+		return nil, err
+	}
+	return &storyAdvance{Story: updated, From: from}, nil
+}
+
+// announceStoryAdvance broadcasts story.updated for an advanced story and
+// runs its lifecycle hooks, as a PUT to the story would.
+func (s *DomainService) announceStoryAdvance(ctx context.Context, adv *storyAdvance) {
+	story := adv.Story
+	s.broadcastDomainEvent(ctx, story.Project, core.EventStoryUpdated, story.ID, story)
+	s.runLifecycleHooks(ctx, hookSubject{
+		Project: story.Project, EntityType: "story", ID: story.ID, ShortID: story.ShortID, Title: story.Title,
+		From: string(adv.From), To: string(story.Status), StoryID: story.ID,
+	})
+	s.observe(func(d *anomaly.Detector) []core.Anomaly {
+		d.ObserveStatus(story.Project, "story/"+story.ID, string(story.Status))
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestStoryAutoAdvance(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.put(t, "/api/admin/flags/"+core.FlagStoryAutoAdvance, map[string]any{"project": project, "enabled": true})
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.post(t, "/api/stories", map[string]any{"project": project, "title": "Checkout", "status": "in_progress"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+
+	var tasks []core.Task
+	for _, title := range []string{"API", "UI"} {
+		resp := env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": title, "status": "running"})
+		requireStatus(t, resp, http.StatusCreated)
+		tasks = append(tasks, decodeJSON[core.Task](t, resp))
+	}
+
+	complete := func(task core.Task, query string) {
+		t.Helper()
+		task.Status = core.TaskStatusDone
+		resp := env.put(t, "/api/tasks/"+task.ID+query, task)
+		requireStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	storyStatus := func() core.StoryStatus {
+		t.Helper()
+		resp := env.get(t, "/api/stories/"+story.ID+"?project="+project)
+		requireStatus(t, resp, http.StatusOK)
+		return decodeJSON[core.Story](t, resp).Status
+	}
+
+	complete(tasks[0], "")
+	if got := storyStatus(); got != core.StoryStatusInProgress {
+		t.Fatalf("story advanced with a task still open: %s", got)
+	}
+	complete(tasks[1], "")
+	if got := storyStatus(); got != core.StoryStatusReview {
+		t.Fatalf("story = %s after its last task, want review", got)
+	}
+}
+
+func TestStoryAutoAdvancePerRequest(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj"
+
+	resp := env.post(t, "/api/stories", map[string]any{"project": project, "title": "Search", "status": "in_progress"})
+	requireStatus(t, resp, http.StatusCreated)
+	story := decodeJSON[core.Story](t, resp)
+	resp = env.post(t, "/api/tasks", map[string]any{"project": project, "story_id": story.ID, "title": "Index", "status": "running"})
+	requireStatus(t, resp, http.StatusCreated)
+	task := decodeJSON[core.Task](t, resp)
+	task.Status = core.TaskStatusDone
+
+	resp = env.put(t, "/api/tasks/"+task.ID+"?advance_story=later", task)
+	requireStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+
+	resp = env.put(t, "/api/tasks/"+task.ID+"?advance_story=done", task)
+	requireStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	resp = env.get(t, "/api/stories/"+story.ID+"?project="+project)
+	requireStatus(t, resp, http.StatusOK)
+	if got := decodeJSON[core.Story](t, resp).Status; got != core.StoryStatusDone {
+		t.Fatalf("story = %s, want done", got)
+	}
+}
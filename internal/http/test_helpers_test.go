@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 	"github.com/mistakeknot/intermute/internal/ws"
 )
 
@@ -21,10 +22,7 @@ type testEnv struct {
 
 func newTestEnv(t *testing.T) *testEnv {
 	t.Helper()
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := ws.NewHub()
 	svc := NewDomainService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(NewDomainRouter(svc, hub.Handler(), nil))
@@ -72,6 +70,24 @@ func (e *testEnv) put(t *testing.T, path string, body any) *http.Response {
 	return resp
 }
 
+func (e *testEnv) patch(t *testing.T, path string, body any) *http.Response {
+	t.Helper()
+	buf, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPatch, e.srv.URL+path, bytes.NewReader(buf))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PATCH %s: %v", path, err)
+	}
+	return resp
+}
+
 func (e *testEnv) delete(t *testing.T, path string) *http.Response {
 	t.Helper()
 	req, err := http.NewRequest(http.MethodDelete, e.srv.URL+path, nil)
@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Request tracing
+//
+// Every API request carries a core.Trace. Callers may send X-Request-ID,
+// X-Correlation-ID (the logical operation the request belongs to) and
+// X-Causation-ID (the event it is reacting to); a missing request ID is
+// generated and a missing correlation ID defaults to the request ID. The
+// trace is echoed in the response headers, persisted on every event the
+// request appends and included in the domain and message events it
+// broadcasts, so downstream consumers can stitch causal chains together.
+
+const (
+	headerRequestID     = "X-Request-ID"
+	headerCorrelationID = "X-Correlation-ID"
+	headerCausationID   = "X-Causation-ID"
+)
+
+// maxTraceIDLen bounds caller-supplied IDs; longer ones are replaced
+// (request ID) or dropped.
+const maxTraceIDLen = 128
+
+func withTrace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := core.Trace{
+			RequestID:     traceHeader(r, headerRequestID),
+			CorrelationID: traceHeader(r, headerCorrelationID),
+			CausationID:   traceHeader(r, headerCausationID),
+		}
+		if t.RequestID == "" {
+			t.RequestID = uuid.NewString()
+		}
+		if t.CorrelationID == "" {
+			t.CorrelationID = t.RequestID
+		}
+		w.Header().Set(headerRequestID, t.RequestID)
+		w.Header().Set(headerCorrelationID, t.CorrelationID)
+		next.ServeHTTP(w, r.WithContext(core.WithTrace(r.Context(), t)))
+	})
+}
+
+func traceHeader(r *http.Request, name string) string {
+	v := strings.TrimSpace(r.Header.Get(name))
+	if len(v) > maxTraceIDLen {
+		return ""
+	}
+	return v
+}
+
+// addTrace copies t into a broadcast event, leaving out empty IDs.
+func addTrace(event map[string]any, t core.Trace) {
+	if t.RequestID != "" {
+		event["request_id"] = t.RequestID
+	}
+	if t.CorrelationID != "" {
+		event["correlation_id"] = t.CorrelationID
+	}
+	if t.CausationID != "" {
+		event["causation_id"] = t.CausationID
+	}
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+func TestTraceIDsReachEvents(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	bus := &recordingBus{}
+	svc := NewDomainService(st).WithBroadcaster(bus)
+	srv := httptest.NewServer(NewDomainRouter(svc, nil, nil))
+	t.Cleanup(srv.Close)
+
+	post := func(headers map[string]string) *http.Response {
+		body, _ := json.Marshal(map[string]any{"project": "proj", "title": "Traced", "status": "pending"})
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/tasks", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+		return resp
+	}
+
+	resp := post(map[string]string{
+		"X-Request-ID":     "req-1",
+		"X-Correlation-ID": "op-7",
+		"X-Causation-ID":   "evt-3",
+	})
+	if resp.Header.Get("X-Request-ID") != "req-1" || resp.Header.Get("X-Correlation-ID") != "op-7" {
+		t.Fatalf("expected trace echoed, got %v", resp.Header)
+	}
+
+	// Without headers a request ID is generated and doubles as the
+	// correlation ID.
+	resp = post(nil)
+	generated := resp.Header.Get("X-Request-ID")
+	if generated == "" || resp.Header.Get("X-Correlation-ID") != generated {
+		t.Fatalf("expected generated request and correlation IDs, got %v", resp.Header)
+	}
+
+	events := bus.ofType(core.EventTaskCreated)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 task.created events, got %d", len(events))
+	}
+	if events[0]["request_id"] != "req-1" || events[0]["correlation_id"] != "op-7" || events[0]["causation_id"] != "evt-3" {
+		t.Fatalf("expected trace on broadcast, got %v", events[0])
+	}
+	if _, ok := events[1]["causation_id"]; ok || events[1]["request_id"] != generated {
+		t.Fatalf("expected generated trace without causation, got %v", events[1])
+	}
+
+	logResp, err := http.Get(srv.URL + "/api/events?project=proj&entity_type=task")
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	defer logResp.Body.Close()
+	var log listEventsResponse
+	if err := json.NewDecoder(logResp.Body).Decode(&log); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(log.Events) != 2 {
+		t.Fatalf("expected 2 logged events, got %d", len(log.Events))
+	}
+	if ev := log.Events[0]; ev.RequestID != "req-1" || ev.CorrelationID != "op-7" || ev.CausationID != "evt-3" {
+		t.Fatalf("expected trace persisted, got %+v", ev)
+	}
+}
@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"net/http"
+)
+
+// Upserts. A create that supplies an ID already in use answers 409
+// already_exists with the stored entity. With ?on_conflict=replace it
+// replaces that entity instead, as a PUT at its current version would, and
+// answers 200 rather than 201.
+
+// alreadyExistsResponse is the 409 body for a create whose ID is taken.
+type alreadyExistsResponse struct {
+	errorResponse
+	Current any `json:"current"`
+}
+
+// writeAlreadyExists answers a create whose ID is taken with current, the
+// stored entity. loadErr is the error from reading it; without the entity
+// the body carries only the error and code.
+func writeAlreadyExists(w http.ResponseWriter, current any, loadErr error) {
+	const msg = "an entity with this id already exists"
+	if loadErr != nil {
+		writeJSONError(w, http.StatusConflict, msg, "already_exists")
+		return
+	}
+	writeErrorBody(w, http.StatusConflict, alreadyExistsResponse{
+		errorResponse: newErrorResponse(msg, "already_exists", nil),
+		Current:       current,
+	})
+}
+
+// replaceExisting handles a create that supplies id when get finds it
+// stored: it writes the 409, or calls replace with the stored entity under
+// ?on_conflict=replace. It also rejects an unknown on_conflict mode. It
+// reports whether the request has been answered; if not, the caller goes
+// on to create.
+func replaceExisting[E any](w http.ResponseWriter, r *http.Request, id string, get func() (E, error), replace func(current E)) bool {
+	mode := r.URL.Query().Get("on_conflict")
+	if mode != "" && mode != "replace" && mode != "error" {
+		writeJSONError(w, http.StatusBadRequest, "on_conflict must be error or replace", "invalid_request")
+		return true
+	}
+	if id == "" {
+		return false
+	}
+	current, err := get()
+	if err != nil {
+		return false
+	}
+	if mode != "replace" {
+		writeAlreadyExists(w, current, nil)
+		return true
+	}
+	replace(current)
+	return true
+}
@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreateWithExistingID(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj-upsert"
+	const id = "spec-fixed-id"
+
+	resp := env.post(t, "/api/specs", map[string]any{
+		"id": id, "project": project, "title": "First", "status": "draft",
+	})
+	requireStatus(t, resp, http.StatusCreated)
+	created := decodeJSON[map[string]any](t, resp)
+
+	t.Run("conflict", func(t *testing.T) {
+		resp := env.post(t, "/api/specs", map[string]any{
+			"id": id, "project": project, "title": "Second", "status": "draft",
+		})
+		requireStatus(t, resp, http.StatusConflict)
+		body := decodeJSON[map[string]any](t, resp)
+		if body["code"] != "already_exists" {
+			t.Fatalf("code = %v, want already_exists", body["code"])
+		}
+		current, _ := body["current"].(map[string]any)
+		if current["title"] != "First" {
+			t.Fatalf("current = %v, want the stored spec", body["current"])
+		}
+	})
+
+	t.Run("bad mode", func(t *testing.T) {
+		resp := env.post(t, "/api/specs?on_conflict=merge", map[string]any{
+			"id": id, "project": project, "title": "Second", "status": "draft",
+		})
+		requireStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		resp := env.post(t, "/api/specs?on_conflict=replace", map[string]any{
+			"id": id, "project": project, "title": "Replaced", "status": "draft",
+		})
+		requireStatus(t, resp, http.StatusOK)
+		spec := decodeJSON[map[string]any](t, resp)
+		if spec["title"] != "Replaced" {
+			t.Fatalf("title = %v, want Replaced", spec["title"])
+		}
+		if spec["version"].(float64) <= created["version"].(float64) {
+			t.Fatalf("version = %v, want above %v", spec["version"], created["version"])
+		}
+	})
+
+	t.Run("replace creates when absent", func(t *testing.T) {
+		resp := env.post(t, "/api/tasks?on_conflict=replace", map[string]any{
+			"id": "task-fixed-id", "project": project, "title": "New", "status": "pending",
+		})
+		requireStatus(t, resp, http.StatusCreated)
+		resp.Body.Close()
+	})
+}
+
+func TestCreateWithExistingIDOtherEntities(t *testing.T) {
+	env := newTestEnv(t)
+	const project = "proj-upsert"
+	for _, tc := range []struct {
+		path string
+		body map[string]any
+	}{
+		{"/api/insights", map[string]any{"source": "test", "category": "note"}},
+		{"/api/cujs", map[string]any{"spec_id": "s1"}},
+		{"/api/goals", map[string]any{}},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			body := func(title string) map[string]any {
+				b := map[string]any{"id": "fixed-id", "project": project, "title": title}
+				for k, v := range tc.body {
+					b[k] = v
+				}
+				return b
+			}
+			resp := env.post(t, tc.path, body("First"))
+			requireStatus(t, resp, http.StatusCreated)
+			resp.Body.Close()
+
+			resp = env.post(t, tc.path, body("Second"))
+			requireStatus(t, resp, http.StatusConflict)
+			conflict := decodeJSON[map[string]any](t, resp)
+			if current, _ := conflict["current"].(map[string]any); conflict["code"] != "already_exists" || current["title"] != "First" {
+				t.Fatalf("conflict = %v", conflict)
+			}
+
+			resp = env.post(t, tc.path+"?on_conflict=replace", body("Replaced"))
+			requireStatus(t, resp, http.StatusOK)
+			if got := decodeJSON[map[string]any](t, resp); got["title"] != "Replaced" || got["version"].(float64) < 2 {
+				t.Fatalf("replaced = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package httpapi
+
+import (
+	"net/http"
+)
+
+// versionConflictResponse is the 409 body for an update that lost the
+// optimistic-lock race. Current is the entity as it now stands, so the
+// caller can merge and retry without another GET.
+type versionConflictResponse struct {
+	errorResponse
+	Version int64 `json:"version"`
+	Current any   `json:"current"`
+}
+
+// writeVersionConflict answers a lost optimistic-lock race with current,
+// the stored entity at version. loadErr is the error from re-reading it;
+// when the entity is gone the body carries only the error and code.
+func writeVersionConflict(w http.ResponseWriter, current any, version int64, loadErr error) {
+	const msg = "entity was modified concurrently"
+	if loadErr != nil {
+		writeJSONError(w, http.StatusConflict, msg, "version_conflict")
+		return
+	}
+	writeErrorBody(w, http.StatusConflict, versionConflictResponse{
+		errorResponse: newErrorResponse(msg, "version_conflict", nil),
+		Version:       version,
+		Current:       current,
+	})
+}
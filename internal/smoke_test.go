@@ -12,7 +12,7 @@ import (
 
 	"github.com/mistakeknot/intermute/internal/auth"
 	httpapi "github.com/mistakeknot/intermute/internal/http"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 	"github.com/mistakeknot/intermute/internal/ws"
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
@@ -62,10 +62,7 @@ func decode[T any](t *testing.T, resp *http.Response) T {
 // TestSmokeMessageFlow exercises the full lifecycle:
 // register agent → connect WS → send message → verify WS event → fetch inbox → mark read → verify counts
 func TestSmokeMessageFlow(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := ws.NewHub()
 	svc := httpapi.NewDomainService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewDomainRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -150,10 +147,7 @@ func TestSmokeMessageFlow(t *testing.T) {
 
 // TestSmokeDomainFlow exercises: create spec → epic → story → task → assign → list filters
 func TestSmokeDomainFlow(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := httpapi.NewDomainService(st)
 	srv := httptest.NewServer(httpapi.NewDomainRouter(svc, nil, nil))
 	defer srv.Close()
@@ -256,10 +250,7 @@ func TestSmokeDomainFlow(t *testing.T) {
 
 // TestSmokeReservationFlow exercises: reserve → verify active → overlapping fails → release → verify released
 func TestSmokeReservationFlow(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	svc := httpapi.NewService(st)
 	// Use NewRouter which includes reservation endpoints
 	srv := httptest.NewServer(httpapi.NewRouter(svc, nil, nil))
@@ -318,7 +309,7 @@ func TestSmokeReservationFlow(t *testing.T) {
 	conflictResp.Body.Close()
 
 	// Release via store (HTTP DELETE requires auth agent matching)
-	if err := st.ReleaseReservation(nil, resID, "agent-a"); err != nil {
+	if err := st.ReleaseReservation(context.Background(), resID, "agent-a"); err != nil {
 		t.Fatalf("release: %v", err)
 	}
 
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/mistakeknot/intermute/internal/core"
 )
@@ -10,12 +11,22 @@ import (
 type DomainStore interface {
 	Store
 
+	// RunInTx runs fn against a view of the store bound to one
+	// transaction, committing only if fn returns true. fn may be run
+	// again if the transaction loses a conflict with another writer.
+	RunInTx(ctx context.Context, fn func(tx DomainStore) (commit bool)) error
+
 	// Spec operations
 	CreateSpec(ctx context.Context, spec core.Spec) (core.Spec, error)
 	GetSpec(ctx context.Context, project, id string) (core.Spec, error)
 	ListSpecs(ctx context.Context, project, status string) ([]core.Spec, error)
 	UpdateSpec(ctx context.Context, spec core.Spec) (core.Spec, error)
 	DeleteSpec(ctx context.Context, project, id string) error
+	PublishSpec(ctx context.Context, project, specID, publishedBy string) (core.PublishedSpec, error)
+	GetPublishedSpec(ctx context.Context, project, specID string, number int) (core.PublishedSpec, error)
+	ListPublishedSpecs(ctx context.Context, project, specID string) ([]core.PublishedSpec, error)
+	GetSpecRevision(ctx context.Context, project, specID string, version int64) (core.Spec, error)
+	ReviewSpec(ctx context.Context, project, id string) (core.Spec, error)
 
 	// Epic operations
 	CreateEpic(ctx context.Context, epic core.Epic) (core.Epic, error)
@@ -30,27 +41,67 @@ type DomainStore interface {
 	ListStories(ctx context.Context, project, epicID string) ([]core.Story, error)
 	UpdateStory(ctx context.Context, story core.Story) (core.Story, error)
 	DeleteStory(ctx context.Context, project, id string) error
+	RankStory(ctx context.Context, project, id, after, before string) (core.Story, error)
 
 	// Task operations
 	CreateTask(ctx context.Context, task core.Task) (core.Task, error)
 	GetTask(ctx context.Context, project, id string) (core.Task, error)
 	ListTasks(ctx context.Context, project, status, agent string) ([]core.Task, error)
+	QueryTasks(ctx context.Context, q core.TaskQuery) ([]core.Task, error)
 	UpdateTask(ctx context.Context, task core.Task) (core.Task, error)
 	DeleteTask(ctx context.Context, project, id string) error
+	ClaimTasks(ctx context.Context, claim core.TaskClaim) ([]core.Task, error)
+	RenewTaskLease(ctx context.Context, project, id string, expiresAt time.Time) (core.TaskLease, error)
+	ReleaseTaskLease(ctx context.Context, project, id string) ([]core.Task, error)
+	TaskConflicts(ctx context.Context, project, taskID string) ([]core.TaskConflict, error)
+	UnblockReadyTasks(ctx context.Context, project, externalRef string) ([]core.Task, error)
+	CreateTaskComment(ctx context.Context, comment core.TaskComment) (core.TaskComment, error)
+	ListTaskComments(ctx context.Context, project, taskID string, after uint64, limit int) ([]core.TaskComment, error)
+	DeleteTaskComment(ctx context.Context, project, taskID, id string) error
 
 	// Insight operations
 	CreateInsight(ctx context.Context, insight core.Insight) (core.Insight, error)
 	GetInsight(ctx context.Context, project, id string) (core.Insight, error)
 	ListInsights(ctx context.Context, project, specID, category string) ([]core.Insight, error)
+	UpdateInsight(ctx context.Context, insight core.Insight) (core.Insight, error)
 	LinkInsightToSpec(ctx context.Context, project, insightID, specID string) error
+	FindInsightByHash(ctx context.Context, project, hash string) (core.Insight, error)
+	MergeInsights(ctx context.Context, project, targetID string, duplicates []string) (core.Insight, error)
 	DeleteInsight(ctx context.Context, project, id string) error
 
+	// Insight routing rule operations
+	CreateInsightRule(ctx context.Context, rule core.InsightRule) (core.InsightRule, error)
+	GetInsightRule(ctx context.Context, project, id string) (core.InsightRule, error)
+	ListInsightRules(ctx context.Context, project string) ([]core.InsightRule, error)
+	UpdateInsightRule(ctx context.Context, rule core.InsightRule) (core.InsightRule, error)
+	DeleteInsightRule(ctx context.Context, project, id string) error
+
+	// Lifecycle hook operations
+	CreateLifecycleHook(ctx context.Context, hook core.LifecycleHook) (core.LifecycleHook, error)
+	GetLifecycleHook(ctx context.Context, project, id string) (core.LifecycleHook, error)
+	ListLifecycleHooks(ctx context.Context, project string) ([]core.LifecycleHook, error)
+	UpdateLifecycleHook(ctx context.Context, hook core.LifecycleHook) (core.LifecycleHook, error)
+	DeleteLifecycleHook(ctx context.Context, project, id string) error
+	RecordHookRun(ctx context.Context, run core.HookRun) (core.HookRun, error)
+	ListHookRuns(ctx context.Context, project, hookID string, limit int) ([]core.HookRun, error)
+
+	// Scheduled report operations
+	CreateReportSchedule(ctx context.Context, sched core.ReportSchedule) (core.ReportSchedule, error)
+	GetReportSchedule(ctx context.Context, project, id string) (core.ReportSchedule, error)
+	ListReportSchedules(ctx context.Context, project string) ([]core.ReportSchedule, error)
+	UpdateReportSchedule(ctx context.Context, sched core.ReportSchedule) (core.ReportSchedule, error)
+	DeleteReportSchedule(ctx context.Context, project, id string) error
+	DueReportSchedules(ctx context.Context, now time.Time) ([]core.ReportSchedule, error)
+	MarkReportRun(ctx context.Context, project, id string, ranAt, next time.Time) error
+
 	// Session operations
 	CreateSession(ctx context.Context, session core.Session) (core.Session, error)
 	GetSession(ctx context.Context, project, id string) (core.Session, error)
 	ListSessions(ctx context.Context, project, status string) ([]core.Session, error)
 	UpdateSession(ctx context.Context, session core.Session) (core.Session, error)
 	DeleteSession(ctx context.Context, project, id string) error
+	HeartbeatSession(ctx context.Context, project, id string) (core.Session, error)
+	SessionHistory(ctx context.Context, project string, since, until time.Time) ([]core.SessionRecord, error)
 
 	// CUJ (Critical User Journey) operations
 	CreateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error)
@@ -61,4 +112,70 @@ type DomainStore interface {
 	LinkCUJToFeature(ctx context.Context, project, cujID, featureID string) error
 	UnlinkCUJFromFeature(ctx context.Context, project, cujID, featureID string) error
 	GetCUJFeatureLinks(ctx context.Context, project, cujID string) ([]core.CUJFeatureLink, error)
+
+	// Goal (OKR) operations
+	CreateGoal(ctx context.Context, goal core.Goal) (core.Goal, error)
+	GetGoal(ctx context.Context, project, id string) (core.Goal, error)
+	ListGoals(ctx context.Context, project, status string) ([]core.Goal, error)
+	UpdateGoal(ctx context.Context, goal core.Goal) (core.Goal, error)
+	DeleteGoal(ctx context.Context, project, id string) error
+	LinkGoal(ctx context.Context, project, goalID, entityType, entityID string) error
+	UnlinkGoal(ctx context.Context, project, goalID, entityType, entityID string) error
+	GetGoalLinks(ctx context.Context, project, goalID string) ([]core.GoalLink, error)
+
+	// Feature flag operations
+	SetFeatureFlag(ctx context.Context, flag core.FeatureFlag) (core.FeatureFlag, error)
+	ListFeatureFlags(ctx context.Context) ([]core.FeatureFlag, error)
+	EffectiveFeatureFlags(ctx context.Context, project string) (map[string]bool, error)
+	DeleteFeatureFlag(ctx context.Context, project, name string) error
+
+	// FindByTitle returns the ID of the entity in scope with the same title,
+	// or "" if there is none
+	FindByTitle(ctx context.Context, scope core.TitleScope) (string, error)
+
+	// ResolveShortID returns the ID behind a short ID such as "TASK-12", or
+	// "" if there is none
+	ResolveShortID(ctx context.Context, project, entityType, ref string) (string, error)
+
+	// LookupEntity finds entities of any type by ID or short ID
+	LookupEntity(ctx context.Context, project, ref string) ([]core.EntityRef, error)
+
+	// Full-text search over specs, stories, insights and messages
+	Search(ctx context.Context, q core.SearchQuery) ([]core.SearchResult, error)
+
+	// Domain event log
+	ListDomainEvents(ctx context.Context, filter core.EventFilter) ([]core.Event, error)
+	CountDomainEvents(ctx context.Context, filter core.EventFilter) (int, error)
+
+	// Admin operations (cross-project)
+	AdminOverview(ctx context.Context) (core.AdminOverview, error)
+	StorageReport(ctx context.Context) (core.StorageReport, error)
+	TableCounts(ctx context.Context) (map[string]int64, error)
+	DBSizeBytes(ctx context.Context) (int64, error)
+	DomainMetrics(ctx context.Context) (core.DomainMetrics, error)
+	ArchiveProject(ctx context.Context, project, coldPath string) (core.ProjectArchive, error)
+	ReactivateProject(ctx context.Context, project string) (core.ProjectArchive, error)
+	ListProjectArchives(ctx context.Context) ([]core.ProjectArchive, error)
+
+	// Message and event retention
+	SetRetentionPolicy(ctx context.Context, policy core.RetentionPolicy) (core.RetentionPolicy, error)
+	GetRetentionPolicy(ctx context.Context, project string) (core.RetentionPolicy, error)
+	ListRetentionPolicies(ctx context.Context) ([]core.RetentionPolicy, error)
+	DeleteRetentionPolicy(ctx context.Context, project string) error
+	PurgeRetention(ctx context.Context, project string) ([]core.RetentionRun, error)
+	CollectGarbage(ctx context.Context) (core.GCRun, error)
+
+	// Idempotency keys for retried POSTs
+	GetIdempotentResponse(ctx context.Context, project, key string) (core.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, resp core.IdempotentResponse) error
+
+	// Project registry
+	CreateProject(ctx context.Context, p core.Project) (core.Project, error)
+	GetProject(ctx context.Context, name string) (core.Project, error)
+	CheckProject(ctx context.Context, name string) (registered, archived bool, err error)
+	ListProjects(ctx context.Context, includeArchived bool) ([]core.Project, error)
+	UpdateProject(ctx context.Context, p core.Project) (core.Project, error)
+	DeleteProject(ctx context.Context, name string) error
+	ExportProject(ctx context.Context, project string) (core.ProjectSnapshot, error)
+	ImportProject(ctx context.Context, project string, snap core.ProjectSnapshot) (core.ProjectImport, error)
 }
@@ -0,0 +1,17 @@
+-- Integer twins for the remaining timestamps queries filter or sort on.
+ALTER TABLE specs ADD COLUMN updated_ms BIGINT GENERATED ALWAYS AS (intermute_ms(updated_at)) STORED;
+ALTER TABLE epics ADD COLUMN updated_ms BIGINT GENERATED ALWAYS AS (intermute_ms(updated_at)) STORED;
+ALTER TABLE cujs ADD COLUMN updated_ms BIGINT GENERATED ALWAYS AS (intermute_ms(updated_at)) STORED;
+ALTER TABLE goals ADD COLUMN updated_ms BIGINT GENERATED ALWAYS AS (intermute_ms(updated_at)) STORED;
+ALTER TABLE sessions ADD COLUMN heartbeat_ms BIGINT GENERATED ALWAYS AS (intermute_ms(heartbeat_at)) STORED;
+ALTER TABLE message_recipients ADD COLUMN snoozed_ms BIGINT GENERATED ALWAYS AS (intermute_ms(snoozed_until)) STORED;
+ALTER TABLE reservation_takeovers ADD COLUMN deadline_ms BIGINT GENERATED ALWAYS AS (intermute_ms(deadline)) STORED;
+ALTER TABLE report_schedules ADD COLUMN next_run_ms BIGINT GENERATED ALWAYS AS (intermute_ms(next_run_at)) STORED;
+ALTER TABLE idempotency_keys ADD COLUMN created_ms BIGINT GENERATED ALWAYS AS (intermute_ms(created_at)) STORED;
+ALTER TABLE window_identities ADD COLUMN expires_ms BIGINT GENERATED ALWAYS AS (intermute_ms(expires_at)) STORED;
+
+CREATE INDEX idx_sessions_heartbeat_ms ON sessions(heartbeat_ms) WHERE heartbeat_ms IS NOT NULL;
+CREATE INDEX idx_recipients_snoozed_ms ON message_recipients(snoozed_ms) WHERE snoozed_ms IS NOT NULL;
+CREATE INDEX idx_takeovers_pending_ms ON reservation_takeovers(deadline_ms) WHERE status = 'pending';
+CREATE INDEX idx_report_schedules_due_ms ON report_schedules(enabled, next_run_ms);
+CREATE INDEX idx_idempotency_keys_created_ms ON idempotency_keys(created_ms);
@@ -0,0 +1,27 @@
+-- Cursors that commit in order. Clients page through events,
+-- topic messages and task comments by the number each row was given,
+-- trusting that once they have seen a number no smaller one will appear.
+-- Concurrent transactions could commit their numbers out of order, so the
+-- numbers are drawn by intermute_ordered_nextval, which first takes a
+-- transaction-level advisory lock: a transaction holding a number keeps
+-- the next one waiting until it commits or rolls back.
+CREATE OR REPLACE FUNCTION intermute_ordered_nextval(seq regclass) RETURNS bigint
+LANGUAGE sql VOLATILE AS $$
+  SELECT pg_advisory_xact_lock(452824687986);
+  SELECT nextval(seq);
+$$;
+
+ALTER TABLE events ALTER COLUMN cursor DROP IDENTITY IF EXISTS;
+CREATE SEQUENCE IF NOT EXISTS events_cursor_ordered OWNED BY events.cursor;
+SELECT setval('events_cursor_ordered', COALESCE((SELECT max(cursor) FROM events), 0) + 1, false);
+ALTER TABLE events ALTER COLUMN cursor SET DEFAULT intermute_ordered_nextval('events_cursor_ordered');
+
+ALTER TABLE messages ALTER COLUMN rowid DROP IDENTITY IF EXISTS;
+CREATE SEQUENCE IF NOT EXISTS messages_rowid_ordered OWNED BY messages.rowid;
+SELECT setval('messages_rowid_ordered', COALESCE((SELECT max(rowid) FROM messages), 0) + 1, false);
+ALTER TABLE messages ALTER COLUMN rowid SET DEFAULT intermute_ordered_nextval('messages_rowid_ordered');
+
+ALTER TABLE task_comments ALTER COLUMN seq DROP IDENTITY IF EXISTS;
+CREATE SEQUENCE IF NOT EXISTS task_comments_seq_ordered OWNED BY task_comments.seq;
+SELECT setval('task_comments_seq_ordered', COALESCE((SELECT max(seq) FROM task_comments), 0) + 1, false);
+ALTER TABLE task_comments ALTER COLUMN seq SET DEFAULT intermute_ordered_nextval('task_comments_seq_ordered');
@@ -0,0 +1,17 @@
+-- Unique titles and insight deduplication enforced by the database rather
+-- than by a lock in one server process. A write sets title_key (the title
+-- lowercased and trimmed) while its project enforces unique titles, and
+-- dedup_key (the content hash) on an insight created while the project
+-- deduplicates insights; the unique indexes then turn away a duplicate
+-- from any replica. Rows written before this have no key.
+ALTER TABLE specs ADD COLUMN title_key TEXT COLLATE "C";
+ALTER TABLE epics ADD COLUMN title_key TEXT COLLATE "C";
+ALTER TABLE stories ADD COLUMN title_key TEXT COLLATE "C";
+ALTER TABLE tasks ADD COLUMN title_key TEXT COLLATE "C";
+ALTER TABLE insights ADD COLUMN dedup_key TEXT COLLATE "C";
+
+CREATE UNIQUE INDEX idx_specs_title_key ON specs(project, title_key) WHERE title_key IS NOT NULL;
+CREATE UNIQUE INDEX idx_epics_title_key ON epics(project, COALESCE(spec_id, ''), title_key) WHERE title_key IS NOT NULL;
+CREATE UNIQUE INDEX idx_stories_title_key ON stories(project, COALESCE(epic_id, ''), title_key) WHERE title_key IS NOT NULL;
+CREATE UNIQUE INDEX idx_tasks_title_key ON tasks(project, COALESCE(story_id, ''), title_key) WHERE title_key IS NOT NULL;
+CREATE UNIQUE INDEX idx_insights_dedup_key ON insights(project, dedup_key) WHERE dedup_key IS NOT NULL;
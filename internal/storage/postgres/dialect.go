@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+// dialect is PostgreSQL's sqlite.Dialect.
+type dialect struct{}
+
+// Tables lists the tables of the connection's current schema.
+func (dialect) Tables(ctx context.Context, q sqlite.Querier) ([]string, error) {
+	return queryStrings(ctx, q, `SELECT table_name::text FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`)
+}
+
+// TableColumns leaves out generated columns, which PostgreSQL won't take
+// on insert: the millisecond twins and search_docs.tsv. It also leaves
+// out messages.rowid, which stands in for SQLite's implicit rowid and is
+// always numbered by the database.
+func (dialect) TableColumns(ctx context.Context, q sqlite.Querier, table string) ([]string, error) {
+	cols, err := queryStrings(ctx, q, `SELECT column_name::text FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ?
+		  AND is_generated = 'NEVER' AND COALESCE(identity_generation, '') <> 'ALWAYS'
+		  AND NOT (table_name = 'messages' AND column_name = 'rowid')
+		ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("columns of %s: %w", table, err)
+	}
+	return cols, nil
+}
+
+// writeOrder names the columns that number a table's rows as they are
+// written, standing in for SQLite's rowid.
+var writeOrder = map[string]string{
+	"events":        "cursor",
+	"messages":      "rowid",
+	"task_comments": "seq",
+	"search_docs":   "id",
+}
+
+// InsertionOrder falls back to physical order for tables without a
+// numbering column, which only their own writes can reorder.
+func (dialect) InsertionOrder(table string) string {
+	if col, ok := writeOrder[table]; ok {
+		return col
+	}
+	return "ctid"
+}
+
+// TableSizes reports each table's total relation size, indexes and TOAST
+// included.
+func (dialect) TableSizes(ctx context.Context, q sqlite.Querier) ([]core.TableSize, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT c.relname::text, pg_total_relation_size(c.oid)
+		 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE n.nspname = current_schema() AND c.relkind = 'r'
+		 ORDER BY 2 DESC, 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []core.TableSize
+	for rows.Next() {
+		var t core.TableSize
+		if err := rows.Scan(&t.Table, &t.Bytes); err != nil {
+			return nil, fmt.Errorf("scan table size: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (dialect) DBSizeBytes(ctx context.Context, q sqlite.Querier) (int64, error) {
+	var size int64
+	if err := q.QueryRowContext(ctx, `SELECT pg_database_size(current_database())`).Scan(&size); err != nil {
+		return 0, fmt.Errorf("database size: %w", err)
+	}
+	return size, nil
+}
+
+// Search matches search_docs.tsv against every term of the query, a
+// trailing * matching as a prefix. Score is ts_rank, which weighs title
+// matches above body matches.
+func (dialect) Search(ctx context.Context, q sqlite.Querier, query core.SearchQuery) ([]core.SearchResult, error) {
+	var parts []string
+	var args []any
+	for _, term := range strings.Fields(query.Query) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+		if prefix {
+			parts = append(parts, `to_tsquery('english', quote_literal(?) || ':*')`)
+		} else {
+			parts = append(parts, `plainto_tsquery('english', ?)`)
+		}
+		args = append(args, term)
+	}
+	if len(parts) == 0 {
+		return []core.SearchResult{}, nil
+	}
+
+	sqlText := `WITH q AS (SELECT ` + strings.Join(parts, " && ") + ` AS query)
+	SELECT d.kind, d.entity_id, d.project, d.title,
+		ts_headline('english', CASE WHEN d.body = '' THEN d.title ELSE d.body END, q.query,
+			'StartSel=[, StopSel=], MaxWords=12, MinWords=4, MaxFragments=1, FragmentDelimiter=…'),
+		ts_rank(d.tsv, q.query)
+	 FROM search_docs d, q
+	 WHERE numnode(q.query) > 0 AND d.tsv @@ q.query`
+	if query.Project != "" {
+		sqlText += " AND d.project = ?"
+		args = append(args, query.Project)
+	}
+	if len(query.Kinds) > 0 {
+		sqlText += " AND d.kind IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(query.Kinds)), ", ") + ")"
+		for _, k := range query.Kinds {
+			args = append(args, string(k))
+		}
+	}
+	sqlText += " ORDER BY 6 DESC, d.id LIMIT ?"
+	args = append(args, query.Limit)
+
+	rows, err := q.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+	out := []core.SearchResult{}
+	for rows.Next() {
+		var r core.SearchResult
+		var kind string
+		if err := rows.Scan(&kind, &r.ID, &r.Project, &r.Title, &r.Snippet, &r.Score); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		r.Kind = core.SearchKind(kind)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// OpenCold opens the SQLite file at path, so archives have the same
+// format whichever backend wrote them.
+func (d dialect) OpenCold(ctx context.Context, _ sqlite.Querier, path string) (sqlite.ColdArchive, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	return &fileCold{d: d, db: db}, nil
+}
+
+// Close closes db; PostgreSQL needs nothing flushed first.
+func (dialect) Close(db *sql.DB) error {
+	return db.Close()
+}
+
+// fileCold is a cold archive in a SQLite file of its own. Its tables are
+// untyped, so values keep the types PostgreSQL returned.
+type fileCold struct {
+	d  dialect
+	db *sql.DB
+}
+
+func (c *fileCold) Save(ctx context.Context, tx sqlite.Querier, table string, columns []string, project string) error {
+	list := strings.Join(columns, ", ")
+	if _, err := c.db.ExecContext(ctx, `CREATE TABLE `+table+` (`+list+`)`); err != nil {
+		return err
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+list+` FROM `+table+` WHERE project = ? ORDER BY `+c.d.InsertionOrder(table), project)
+	if err != nil {
+		return err
+	}
+	return copyRows(ctx, rows, c.db, table, columns)
+}
+
+// Restore copies every archived table back by the columns it shares with
+// the live table, so columns added since archival take their defaults
+// and ones PostgreSQL generates are left to it.
+func (c *fileCold) Restore(ctx context.Context, tx sqlite.Querier, project string) error {
+	names, err := queryStrings(ctx, c.db, `SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return fmt.Errorf("list archived tables: %w", err)
+	}
+	for _, name := range names {
+		live, err := c.d.TableColumns(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		archived, err := queryStrings(ctx, c.db, `SELECT name FROM pragma_table_info(?)`, name)
+		if err != nil {
+			return fmt.Errorf("columns of archived %s: %w", name, err)
+		}
+		var cols []string
+		for _, col := range archived {
+			if slices.Contains(live, col) {
+				cols = append(cols, col)
+			}
+		}
+		if err := c.restoreTable(ctx, tx, name, cols, project); err != nil {
+			return fmt.Errorf("restore %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (c *fileCold) restoreTable(ctx context.Context, tx sqlite.Querier, table string, columns []string, project string) error {
+	list := strings.Join(columns, ", ")
+	rows, err := c.db.QueryContext(ctx, `SELECT `+list+` FROM `+table+` WHERE project = ? ORDER BY rowid`, project)
+	if err != nil {
+		return err
+	}
+	return copyRows(ctx, rows, tx, table, columns)
+}
+
+func (c *fileCold) Close() error {
+	return c.db.Close()
+}
+
+// copyRows inserts rows, whose columns are columns, into table through to,
+// and closes them.
+func copyRows(ctx context.Context, rows *sql.Rows, to sqlite.Querier, table string, columns []string) error {
+	defer rows.Close()
+	insert := `INSERT INTO ` + table + ` (` + strings.Join(columns, ", ") + `) VALUES (` +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + `)`
+	vals := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		if _, err := to.ExecContext(ctx, insert, vals...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// queryStrings runs a query returning one text column.
+func queryStrings(ctx context.Context, q sqlite.Querier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
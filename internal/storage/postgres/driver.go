@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// connector opens connections that speak the Store's SQLite dialect of
+// SQL: ? placeholders and integer booleans.
+type connector struct {
+	inner driver.Connector
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.inner.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc.(*stdlib.Conn)}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return c.inner.Driver()
+}
+
+// conn runs each query with its ? placeholders rewritten to $n and its
+// arguments sent as parameters of unknown type, over the extended
+// protocol. The server types an unknown parameter by the column it meets,
+// as SQLite's untyped values are, where a typed one would not be: a
+// string compared to a BIGINT column, say.
+type conn struct {
+	*stdlib.Conn
+}
+
+// CheckNamedValue converts arguments as database/sql does for drivers
+// that don't check their own, then into the text PostgreSQL reads them
+// from: booleans as the integers the Store's columns hold them as, and
+// times as RFC 3339 like the Store's own timestamps.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	switch tv := v.(type) {
+	case bool:
+		v = int64(0)
+		if tv {
+			v = int64(1)
+		}
+	case time.Time:
+		v = tv.Format(time.RFC3339Nano)
+	}
+	nv.Value = v
+	return nil
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return nil, errors.New("postgres: prepared statements are not supported")
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query, err := rebind(query, len(args))
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.ExecContext(ctx, query, args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, err := rebind(query, len(args))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.Conn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return intRows{rows}, nil
+}
+
+// rebind rewrites the n ? placeholders in query to $1, $2 and so on,
+// leaving alone any inside string constants (standard, E-prefixed escape and
+// $tag$ dollar-quoted), quoted identifiers and comments.
+func rebind(query string, n int) (string, error) {
+	if n == 0 {
+		return query, nil
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 2*n)
+	params := 0
+	for i := 0; i < len(query); {
+		end := skipQuoted(query, i)
+		if end > i {
+			b.WriteString(query[i:end])
+			i = end
+			continue
+		}
+		if query[i] == '?' {
+			params++
+			if params > n {
+				return "", fmt.Errorf("postgres: query has more placeholders than its %d args", n)
+			}
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(params))
+		} else {
+			b.WriteByte(query[i])
+		}
+		i++
+	}
+	if params != n {
+		return "", fmt.Errorf("postgres: query has %d placeholders for %d args", params, n)
+	}
+	return b.String(), nil
+}
+
+// skipQuoted returns where the string constant, quoted identifier or
+// comment starting at query[i] ends, or i if none starts there. One left
+// open runs to the end of query.
+func skipQuoted(query string, i int) int {
+	rest := query[i:]
+	switch {
+	case rest[0] == '\'' && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i == 1 || !isIdentByte(query[i-2])):
+		// An E'' string, in which a backslash escapes the next byte.
+		for j := 1; j < len(rest); j++ {
+			switch rest[j] {
+			case '\\':
+				j++
+			case '\'':
+				if j+1 < len(rest) && rest[j+1] == '\'' {
+					j++
+					continue
+				}
+				return i + j + 1
+			}
+		}
+		return len(query)
+	case rest[0] == '\'' || rest[0] == '"':
+		// A doubled quote is the quote itself, which scanning on from the
+		// second quote as a new string handles.
+		if end := strings.IndexByte(rest[1:], rest[0]); end >= 0 {
+			return i + end + 2
+		}
+		return len(query)
+	case strings.HasPrefix(rest, "--"):
+		if end := strings.IndexByte(rest, '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(rest, "/*"):
+		// Block comments nest.
+		depth := 0
+		for j := 0; j+1 < len(rest); j++ {
+			switch rest[j : j+2] {
+			case "/*":
+				depth++
+				j++
+			case "*/":
+				depth--
+				j++
+				if depth == 0 {
+					return i + j + 1
+				}
+			}
+		}
+		return len(query)
+	case rest[0] == '$' && (i == 0 || !isIdentByte(query[i-1])):
+		// A $tag$ string, tag empty or an identifier; $1 is a parameter.
+		tagEnd := 1
+		for tagEnd < len(rest) && isIdentByte(rest[tagEnd]) && !(tagEnd == 1 && rest[tagEnd] >= '0' && rest[tagEnd] <= '9') {
+			tagEnd++
+		}
+		if tagEnd >= len(rest) || rest[tagEnd] != '$' {
+			return i
+		}
+		tag := rest[:tagEnd+1]
+		if end := strings.Index(rest[len(tag):], tag); end >= 0 {
+			return i + len(tag) + end + len(tag)
+		}
+		return len(query)
+	}
+	return i
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// intRows returns booleans, such as an EXISTS, as integers, which is how
+// SQLite returns them and how the Store scans them.
+type intRows struct {
+	driver.Rows
+}
+
+func (r intRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		if b, ok := v.(bool); ok {
+			dest[i] = int64(0)
+			if b {
+				dest[i] = int64(1)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		n     int
+		want  string
+	}{
+		{`SELECT 1`, 0, `SELECT 1`},
+		{`SELECT * FROM t WHERE a = ? AND b = ?`, 2, `SELECT * FROM t WHERE a = $1 AND b = $2`},
+		{`SELECT '?', "a?b", ? FROM t`, 1, `SELECT '?', "a?b", $1 FROM t`},
+		{`SELECT 'it''s ?', ?`, 1, `SELECT 'it''s ?', $1`},
+		{`SELECT E'\'?', ?`, 1, `SELECT E'\'?', $1`},
+		{`SELECT e'a\\', ?`, 1, `SELECT e'a\\', $1`},
+		{`SELECT TYPE'?', ?`, 1, `SELECT TYPE'?', $1`},
+		{"SELECT ? -- what?\n, ?", 2, "SELECT $1 -- what?\n, $2"},
+		{`SELECT ? /* a /* nested? */ still? */ , ?`, 2, `SELECT $1 /* a /* nested? */ still? */ , $2`},
+		{`SELECT $$?$$, $q$ ? $ ? $q$, ?`, 1, `SELECT $$?$$, $q$ ? $ ? $q$, $1`},
+		{`SELECT a$b, ?`, 1, `SELECT a$b, $1`},
+	}
+	for _, tt := range tests {
+		got, err := rebind(tt.query, tt.n)
+		if err != nil {
+			t.Errorf("rebind(%q): %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRebindCountMismatch(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		n     int
+	}{
+		{`SELECT ?, ?`, 1},
+		{`SELECT ?`, 2},
+		{`SELECT '?'`, 1},
+		{`SELECT ? /* ?`, 2},
+	} {
+		if got, err := rebind(tt.query, tt.n); err == nil {
+			t.Errorf("rebind(%q, %d) = %q, want an error", tt.query, tt.n, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+)
+
+var (
+	//go:embed schema.sql
+	schema string
+	//go:embed 002_more_millis.sql
+	moreMillis string
+	//go:embed 003_ordered_cursors.sql
+	orderedCursors string
+	//go:embed 004_title_keys.sql
+	titleKeys string
+)
+
+// migrations are the schema changes in order; migrations[i] takes a
+// database to version i+1. Append new ones, never edit applied ones.
+var migrations = []string{
+	schema,
+	moreMillis,
+	orderedCursors,
+	titleKeys,
+}
+
+// migrateLockKey is the session advisory lock held while migrating, so
+// replicas starting together apply each migration once.
+const migrateLockKey = 0x696e746573
+
+// migrate brings db's schema up to date.
+func migrate(ctx context.Context, db *sql.DB) error {
+	c, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if _, err := c.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_lock(%d)", migrateLockKey)); err != nil {
+		return fmt.Errorf("migration lock: %w", err)
+	}
+	defer c.ExecContext(context.Background(), fmt.Sprintf("SELECT pg_advisory_unlock(%d)", migrateLockKey))
+
+	if _, err := c.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`); err != nil {
+		return fmt.Errorf("schema_migrations: %w", err)
+	}
+	var version int
+	if err := c.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than this build supports (%d)", version, len(migrations))
+	}
+	for v := version + 1; v <= len(migrations); v++ {
+		tx, err := c.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[v-1]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d: %w", v, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, v); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("record migration %d: %w", v, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", v, err)
+		}
+	}
+	return nil
+}
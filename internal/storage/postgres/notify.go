@@ -0,0 +1,289 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+// Event fan-out
+//
+// Each server broadcasts its events to its own WebSocket, gRPC and
+// long-poll clients. With several servers on one database, a Notifier on
+// each also publishes them with NOTIFY on fanoutChannel, and a Listener on
+// each delivers the other servers' events to its local clients. Events
+// are best effort, as they are locally: a server that is reconnecting
+// misses what was sent meanwhile, and clients catch up by cursor.
+
+const fanoutChannel = "intermute_events"
+
+// maxNotifyPayload keeps a NOTIFY under PostgreSQL's 8000-byte limit.
+// Larger events are stored in fanout_payloads and sent by id.
+const maxNotifyPayload = 7900
+
+// fanoutPayloadTTL is how long a stored payload is kept for listeners.
+const fanoutPayloadTTL = 5 * time.Minute
+
+// processOrigin tags the events this process publishes, so its Listener
+// can skip them: local clients already have them.
+var processOrigin = uuid.NewString()
+
+// fanoutMessage is one NOTIFY payload. Ref, when set, is the
+// fanout_payloads row holding the rest.
+type fanoutMessage struct {
+	Origin  string         `json:"origin"`
+	Project string         `json:"project,omitempty"`
+	Agent   string         `json:"agent,omitempty"`
+	Event   map[string]any `json:"event,omitempty"`
+	Ref     int64          `json:"ref,omitempty"`
+}
+
+// Notifier is a Broadcaster publishing events to the other servers. It
+// queues them for a background goroutine, so a slow database doesn't
+// hold up the request that broadcast; when the queue is full, events are
+// dropped and logged.
+type Notifier struct {
+	db     *sql.DB
+	origin string
+	queue  chan fanoutMessage
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNotifier connects to the database at dsn. Call Start to begin
+// publishing.
+func NewNotifier(dsn string) (*Notifier, error) {
+	cfg, err := connConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db := stdlib.OpenDB(*cfg)
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	return &Notifier{
+		db:     db,
+		origin: processOrigin,
+		queue:  make(chan fanoutMessage, 1024),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Broadcast implements sqlite.Broadcaster. Only map events, the form
+// every broadcaster is sent, are published.
+func (n *Notifier) Broadcast(project, agent string, event any) {
+	m, ok := event.(map[string]any)
+	if !ok {
+		return
+	}
+	select {
+	case n.queue <- fanoutMessage{Origin: n.origin, Project: project, Agent: agent, Event: m}:
+	default:
+		log.Printf("postgres fan-out: queue full, dropping %v event", m["type"])
+	}
+}
+
+// Start publishes queued events until Stop, and deletes stored payloads
+// once listeners have had time to read them.
+func (n *Notifier) Start(ctx context.Context) {
+	ctx, n.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(n.done)
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				n.drain()
+				return
+			case msg := <-n.queue:
+				if err := n.publish(ctx, msg); err != nil {
+					log.Printf("postgres fan-out: publish %v event: %v", msg.Event["type"], err)
+				}
+			case <-ticker.C:
+				if _, err := n.db.ExecContext(ctx,
+					`DELETE FROM fanout_payloads WHERE created_at < now() - $1 * interval '1 second'`,
+					int64(fanoutPayloadTTL/time.Second)); err != nil {
+					log.Printf("postgres fan-out: clean payloads: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// drain publishes what is still queued when the Notifier stops.
+func (n *Notifier) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for {
+		select {
+		case msg := <-n.queue:
+			if err := n.publish(ctx, msg); err != nil {
+				log.Printf("postgres fan-out: publish %v event: %v", msg.Event["type"], err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Stop publishes the events already queued and closes the connection.
+func (n *Notifier) Stop() {
+	if n.cancel != nil {
+		n.cancel()
+		<-n.done
+	}
+	n.db.Close()
+}
+
+func (n *Notifier) publish(ctx context.Context, msg fanoutMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		var ref int64
+		if err := n.db.QueryRowContext(ctx,
+			`INSERT INTO fanout_payloads (payload) VALUES ($1) RETURNING id`, string(payload),
+		).Scan(&ref); err != nil {
+			return fmt.Errorf("store payload: %w", err)
+		}
+		if payload, err = json.Marshal(fanoutMessage{Origin: msg.Origin, Ref: ref}); err != nil {
+			return err
+		}
+	}
+	_, err = n.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, fanoutChannel, string(payload))
+	return err
+}
+
+// Listener delivers the events other servers publish to local
+// broadcasters, reconnecting when its connection drops.
+type Listener struct {
+	cfg    *pgx.ConnConfig
+	bus    sqlite.Broadcaster
+	conn   *pgx.Conn
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewListener connects to the database at dsn and starts listening, so
+// events published once it returns are not missed. Call Start to begin
+// delivering them to bus.
+func NewListener(dsn string, bus sqlite.Broadcaster) (*Listener, error) {
+	cfg, err := connConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	l := &Listener{cfg: cfg, bus: bus, done: make(chan struct{})}
+	if l.conn, err = l.listen(context.Background()); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Listener) listen(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.ConnectConfig(ctx, l.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+fanoutChannel); err != nil {
+		conn.Close(context.Background())
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	return conn, nil
+}
+
+// Start delivers events until Stop.
+func (l *Listener) Start(ctx context.Context) {
+	ctx, l.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(l.done)
+		for {
+			err := l.receive(ctx)
+			l.conn.Close(context.Background())
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("postgres fan-out: %v; reconnecting", err)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+				if l.conn, err = l.listen(ctx); err == nil {
+					break
+				}
+				log.Printf("postgres fan-out: %v", err)
+			}
+		}
+	}()
+}
+
+// Stop ends delivery and closes the connection.
+func (l *Listener) Stop() {
+	if l.cancel == nil {
+		l.conn.Close(context.Background())
+		return
+	}
+	l.cancel()
+	<-l.done
+}
+
+// receive delivers notifications until the connection fails.
+func (l *Listener) receive(ctx context.Context) error {
+	for {
+		n, err := l.conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		msg, err := l.decode(ctx, n.Payload)
+		if err != nil {
+			log.Printf("postgres fan-out: %v", err)
+			continue
+		}
+		if msg.Origin == processOrigin || msg.Event == nil {
+			continue
+		}
+		l.bus.Broadcast(msg.Project, msg.Agent, msg.Event)
+	}
+}
+
+// decode parses payload, reading it from fanout_payloads when it was
+// sent by reference. Numbers stay json.Number, so cursors keep every
+// digit when they are encoded again for clients.
+func (l *Listener) decode(ctx context.Context, payload string) (fanoutMessage, error) {
+	var msg fanoutMessage
+	if err := unmarshal(payload, &msg); err != nil {
+		return msg, fmt.Errorf("decode event: %w", err)
+	}
+	if msg.Ref == 0 || msg.Origin == processOrigin {
+		return msg, nil
+	}
+	if err := l.conn.QueryRow(ctx, `SELECT payload FROM fanout_payloads WHERE id = $1`, msg.Ref).Scan(&payload); err != nil {
+		return msg, fmt.Errorf("read payload %d: %w", msg.Ref, err)
+	}
+	msg = fanoutMessage{}
+	if err := unmarshal(payload, &msg); err != nil {
+		return msg, fmt.Errorf("decode event: %w", err)
+	}
+	return msg, nil
+}
+
+func unmarshal(payload string, msg *fanoutMessage) error {
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	return dec.Decode(msg)
+}
@@ -0,0 +1,66 @@
+// Package postgres stores intermute's data in PostgreSQL, so several
+// servers can share one database.
+//
+// The store is sqlite.Store running over a pool of PostgreSQL
+// connections: the schema mirrors SQLite's table for table, the
+// connections rebind the Store's ? placeholders, and Dialect covers the
+// rest. Every transaction runs SERIALIZABLE, so the Store's
+// check-then-write transactions, written for SQLite's single writer,
+// behave as if they ran one at a time; one that loses a conflict fails
+// with a serialization error, which sqlite.ResilientStore retries. Events one
+// server broadcasts reach the others' WebSocket, gRPC and long-poll
+// clients through LISTEN/NOTIFY (see Notifier and Listener).
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+// maxOpenConns bounds each server's pool of connections.
+const maxOpenConns = 16
+
+// Open connects to the PostgreSQL database at dsn, a URL or key=value
+// connection string, and brings its schema up to date.
+func Open(dsn string) (*sqlite.Store, error) {
+	cfg, err := connConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector{inner: stdlib.GetConnector(*cfg)})
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	return sqlite.Open(db, dialect{}), nil
+}
+
+// connConfig parses dsn for sessions that send query arguments as untyped
+// text parameters (see conn), run transactions SERIALIZABLE, and use UTC,
+// which the millisecond columns assume for timestamps without an offset.
+// standard_conforming_strings is pinned on, as rebind assumes backslashes
+// in standard strings are literal.
+func connConfig(dsn string) (*pgx.ConnConfig, error) {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse postgres dsn: %w", err)
+	}
+	cfg.DefaultQueryExecMode = pgx.QueryExecModeExec
+	cfg.RuntimeParams["timezone"] = "UTC"
+	cfg.RuntimeParams["standard_conforming_strings"] = "on"
+	cfg.RuntimeParams["default_transaction_isolation"] = "serializable"
+	return cfg, nil
+}
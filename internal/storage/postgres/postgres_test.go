@@ -0,0 +1,401 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
+)
+
+// testDSN is the database the tests run against, from
+// INTERMUTE_TEST_POSTGRES_DSN; they are skipped without one.
+func testDSN(t *testing.T) string {
+	t.Helper()
+	dsn := os.Getenv("INTERMUTE_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("INTERMUTE_TEST_POSTGRES_DSN not set")
+	}
+	return dsn
+}
+
+// newTestSchema creates an empty schema, dropped when t ends, and returns
+// a DSN whose connections use it.
+func newTestSchema(t *testing.T) string {
+	t.Helper()
+	dsn := testDSN(t)
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer conn.Close(ctx)
+	schemaName := "intermute_test_" + uuid.NewString()[:8]
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schemaName); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(ctx, dsn)
+		if err != nil {
+			t.Errorf("connect: %v", err)
+			return
+		}
+		defer conn.Close(ctx)
+		if _, err := conn.Exec(ctx, "DROP SCHEMA "+schemaName+" CASCADE"); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+	return withSearchPath(t, dsn, schemaName)
+}
+
+func withSearchPath(t *testing.T, dsn, schemaName string) string {
+	t.Helper()
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return fmt.Sprintf("%s search_path=%s", dsn, schemaName)
+	}
+	q := u.Query()
+	q.Set("search_path", schemaName)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func newTestStore(t *testing.T, dsn string) *sqlite.Store {
+	t.Helper()
+	st, err := Open(dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestConformance(t *testing.T) {
+	testDSN(t)
+	storagetest.Run(t, func(t *testing.T) storage.DomainStore {
+		return newTestStore(t, newTestSchema(t))
+	})
+}
+
+func TestOpenIsIdempotent(t *testing.T) {
+	dsn := newTestSchema(t)
+	newTestStore(t, dsn)
+	st := newTestStore(t, dsn)
+	if _, err := st.CreateProject(context.Background(), core.Project{Name: "p"}); err != nil {
+		t.Fatalf("create project after reopening: %v", err)
+	}
+}
+
+// TestArgumentsAreNotSQL stores values that would break out of a quoted
+// literal and checks they come back as they went in.
+func TestArgumentsAreNotSQL(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, newTestSchema(t))
+	for _, title := range []string{
+		`x'); DROP TABLE specs; --`,
+		`\'; DROP TABLE specs; --`,
+		`E'\'' || pg_sleep(1) || '`,
+		`$$; DROP TABLE specs; $$`,
+		`? /* ? */ -- ?`,
+	} {
+		spec, err := st.CreateSpec(ctx, core.Spec{Project: "p", Title: title, Status: core.SpecStatusDraft})
+		if err != nil {
+			t.Fatalf("create spec %q: %v", title, err)
+		}
+		got, err := st.GetSpec(ctx, "p", spec.ID)
+		if err != nil || got.Title != title {
+			t.Fatalf("spec titled %q read back as %q, %v", title, got.Title, err)
+		}
+	}
+
+	data := make([]byte, 256)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	blob, err := st.PutBlob(ctx, core.Blob{Project: "p"}, data)
+	if err != nil {
+		t.Fatalf("put blob: %v", err)
+	}
+	_, got, err := st.GetBlob(ctx, "p", blob.ID)
+	if err != nil || string(got) != string(data) {
+		t.Fatalf("blob read back as %x, %v", got, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, newTestSchema(t))
+	spec, err := st.CreateSpec(ctx, core.Spec{Project: "p", Title: "Retry budgets", Vision: "Bound retries per request", Status: core.SpecStatusDraft})
+	if err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	for _, q := range []string{"retry", "retr*", "budgets request"} {
+		results, err := st.Search(ctx, core.SearchQuery{Query: q, Project: "p"})
+		if err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+		if len(results) != 1 || results[0].ID != spec.ID || results[0].Score <= 0 || results[0].Snippet == "" {
+			t.Fatalf("search %q = %+v", q, results)
+		}
+	}
+	results, err := st.Search(ctx, core.SearchQuery{Query: "retry", Project: "other"})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("search other project = %+v, %v", results, err)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t, newTestSchema(t))
+	spec, err := st.CreateSpec(ctx, core.Spec{Project: "p", Title: "Spec", Status: core.SpecStatusDraft})
+	if err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	path := t.TempDir() + "/p.db"
+	if _, err := st.ArchiveProject(ctx, "p", path); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := st.GetSpec(ctx, "p", spec.ID); err == nil {
+		t.Fatal("spec still readable after archiving")
+	}
+	if _, err := st.ReactivateProject(ctx, "p"); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	got, err := st.GetSpec(ctx, "p", spec.ID)
+	if err != nil || got.Title != "Spec" {
+		t.Fatalf("spec after reactivation = %+v, %v", got, err)
+	}
+}
+
+// TestConcurrentClaims claims tasks from several connections at once
+// through ResilientStore, which retries the transactions that lose a
+// serialization conflict.
+func TestConcurrentClaims(t *testing.T) {
+	ctx := context.Background()
+	st := sqlite.NewResilient(newTestStore(t, newTestSchema(t)))
+	const tasks, workers = 20, 8
+	for range tasks {
+		if _, err := st.CreateTask(ctx, core.Task{Project: "p", Title: "work", Status: core.TaskStatusPending}); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	var mu sync.Mutex
+	owners := map[string]string{}
+	var wg sync.WaitGroup
+	for w := range workers {
+		agent := fmt.Sprintf("worker-%d", w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				claimed, err := st.ClaimTasks(ctx, core.TaskClaim{Project: "p", Agent: agent, Limit: 3})
+				if err != nil {
+					t.Errorf("claim: %v", err)
+					return
+				}
+				if len(claimed) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, task := range claimed {
+					if prev, ok := owners[task.ID]; ok {
+						t.Errorf("task %s claimed by %s and %s", task.ID, prev, agent)
+					}
+					owners[task.ID] = agent
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(owners) != tasks {
+		t.Fatalf("claimed %d tasks, want %d", len(owners), tasks)
+	}
+}
+
+// TestConcurrentUniqueTitles creates the same task from two stores at once,
+// as two replicas would, and checks exactly one create lands.
+func TestConcurrentUniqueTitles(t *testing.T) {
+	ctx := context.Background()
+	dsn := newTestSchema(t)
+	replicas := []*sqlite.ResilientStore{
+		sqlite.NewResilient(newTestStore(t, dsn)),
+		sqlite.NewResilient(newTestStore(t, dsn)),
+	}
+	if _, err := replicas[0].SetFeatureFlag(ctx, core.FeatureFlag{Project: "p", Name: core.FlagUniqueTitles, Enabled: true}); err != nil {
+		t.Fatalf("set flag: %v", err)
+	}
+	const workers = 8
+	var created, duplicates atomic.Int32
+	var wg sync.WaitGroup
+	for w := range workers {
+		st := replicas[w%len(replicas)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := st.CreateTask(ctx, core.Task{Project: "p", StoryID: "s", Title: "Deploy", Status: core.TaskStatusPending})
+			switch {
+			case err == nil:
+				created.Add(1)
+			case errors.Is(err, core.ErrDuplicateTitle):
+				duplicates.Add(1)
+			default:
+				t.Errorf("create task: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if created.Load() != 1 || duplicates.Load() != workers-1 {
+		t.Fatalf("%d created and %d duplicates, want 1 and %d", created.Load(), duplicates.Load(), workers-1)
+	}
+}
+
+// TestCursorsCommitInOrder holds a transaction open after it appends an
+// event and checks that a concurrent append waits for it, so a reader
+// never sees a later cursor before an earlier one.
+func TestCursorsCommitInOrder(t *testing.T) {
+	ctx := context.Background()
+	raw := newTestStore(t, newTestSchema(t))
+	st := sqlite.NewResilient(raw)
+	message := func(id string) core.Event {
+		return core.Event{Type: core.EventMessageCreated, Project: "p", Message: core.Message{
+			ID: id, ThreadID: id, Project: "p", From: "alice", To: []string{"bob"}, Body: id,
+		}}
+	}
+
+	first := make(chan uint64)
+	release := make(chan struct{})
+	txDone := make(chan error)
+	go func() {
+		txDone <- raw.RunInTx(ctx, func(tx storage.DomainStore) bool {
+			cursor, err := tx.AppendEvent(ctx, message("m1"))
+			if err != nil {
+				t.Errorf("append in transaction: %v", err)
+				close(first)
+				return false
+			}
+			first <- cursor
+			<-release
+			return true
+		})
+	}()
+	c1, ok := <-first
+	if !ok {
+		t.FailNow()
+	}
+
+	second := make(chan uint64, 1)
+	go func() {
+		cursor, err := st.AppendEvent(ctx, message("m2"))
+		if err != nil {
+			t.Errorf("concurrent append: %v", err)
+		}
+		second <- cursor
+	}()
+	select {
+	case c2 := <-second:
+		close(release)
+		t.Fatalf("append got cursor %d while cursor %d was uncommitted", c2, c1)
+	case <-time.After(300 * time.Millisecond):
+	}
+	close(release)
+	if err := <-txDone; err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if c2 := <-second; c2 <= c1 {
+		t.Fatalf("second cursor %d, want after %d", c2, c1)
+	}
+
+	inbox, err := st.InboxSince(ctx, "p", "bob", 0, 10)
+	if err != nil || len(inbox) != 2 || inbox[0].ID != "m1" || inbox[1].ID != "m2" {
+		t.Fatalf("inbox = %+v, %v", inbox, err)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	dsn := newTestSchema(t)
+	newTestStore(t, dsn) // migrates the fanout_payloads table
+
+	var got recorder
+	l, err := NewListener(dsn, &got)
+	if err != nil {
+		t.Fatalf("listener: %v", err)
+	}
+	l.Start(context.Background())
+	defer l.Stop()
+
+	n, err := NewNotifier(dsn)
+	if err != nil {
+		t.Fatalf("notifier: %v", err)
+	}
+	// Events from a notifier in the same process as the listener are
+	// skipped; pose as another server.
+	n.origin = "other"
+	n.Start(context.Background())
+	defer n.Stop()
+
+	big := make([]byte, 10000)
+	for i := range big {
+		big[i] = 'x'
+	}
+	n.Broadcast("p", "a", map[string]any{"type": "message.created", "cursor": 7})
+	n.Broadcast("p", "b", map[string]any{"type": "message.created", "body": string(big)})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for got.len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	events := got.all()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if e := events[0]; e.project != "p" || e.agent != "a" || e.event["type"] != "message.created" || fmt.Sprint(e.event["cursor"]) != "7" {
+		t.Fatalf("first event = %+v", e)
+	}
+	if e := events[1]; e.agent != "b" || e.event["body"] != string(big) {
+		t.Fatalf("large event lost its body")
+	}
+}
+
+// recorder is a Broadcaster remembering what it was sent.
+type recorder struct {
+	mu     sync.Mutex
+	events []recorded
+}
+
+type recorded struct {
+	project, agent string
+	event          map[string]any
+}
+
+func (r *recorder) Broadcast(project, agent string, event any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, _ := event.(map[string]any)
+	r.events = append(r.events, recorded{project, agent, m})
+}
+
+func (r *recorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.events)
+}
+
+func (r *recorder) all() []recorded {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recorded(nil), r.events...)
+}
@@ -0,0 +1,788 @@
+-- PostgreSQL schema, version 1: the SQLite schema after its migrations,
+-- with the same tables and columns so the shared Store queries run
+-- unchanged. Text columns use the "C" collation to sort byte-wise like
+-- SQLite; integer millisecond twins (see sqlite/timestamps.go) are
+-- generated columns rather than trigger-maintained.
+
+-- intermute_ms converts an RFC 3339 timestamp to Unix milliseconds,
+-- rounding the fraction of a second as unixMillis does. Like SQLite's
+-- strftime it yields NULL for NULL, empty or unparseable text.
+CREATE OR REPLACE FUNCTION intermute_ms(ts text) RETURNS bigint
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+  IF ts IS NULL OR ts = '' THEN
+    RETURN NULL;
+  END IF;
+  RETURN (extract(epoch FROM regexp_replace(ts, '\.\d+', '')::timestamptz) * 1000)::bigint
+    + round(COALESCE(('0' || substring(ts FROM '\.\d+'))::numeric, 0) * 1000)::bigint;
+EXCEPTION WHEN others THEN
+  RETURN NULL;
+END
+$$;
+
+-- SQLite's JSON functions, over the JSON text the Store keeps in TEXT
+-- columns. Anything but an array has no elements and no length.
+CREATE OR REPLACE FUNCTION intermute_json_array(doc text) RETURNS jsonb
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+  IF jsonb_typeof(doc::jsonb) = 'array' THEN
+    RETURN doc::jsonb;
+  END IF;
+  RETURN NULL;
+EXCEPTION WHEN others THEN
+  RETURN NULL;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION json_each(doc text) RETURNS TABLE (value text)
+LANGUAGE sql IMMUTABLE AS $$
+  SELECT jsonb_array_elements_text(COALESCE(intermute_json_array(doc), '[]'::jsonb))
+$$;
+
+CREATE OR REPLACE FUNCTION json_array_length(doc text) RETURNS bigint
+LANGUAGE sql IMMUTABLE AS $$
+  SELECT jsonb_array_length(intermute_json_array(doc))::bigint
+$$;
+
+-- json_extract supports the $.a.b object paths the Store uses.
+CREATE OR REPLACE FUNCTION json_extract(doc text, path text) RETURNS text
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+  RETURN doc::jsonb #>> string_to_array(substr(path, 3), '.');
+EXCEPTION WHEN others THEN
+  RETURN NULL;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS events (
+  cursor BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  id TEXT COLLATE "C" NOT NULL,
+  type TEXT COLLATE "C" NOT NULL,
+  agent TEXT COLLATE "C",
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  message_id TEXT COLLATE "C",
+  thread_id TEXT COLLATE "C",
+  from_agent TEXT COLLATE "C",
+  to_json TEXT COLLATE "C",
+  body TEXT COLLATE "C",
+  actor TEXT COLLATE "C" NOT NULL DEFAULT '',
+  request_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  correlation_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  causation_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  entity_type TEXT COLLATE "C" NOT NULL DEFAULT '',
+  entity_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  data TEXT COLLATE "C" NOT NULL DEFAULT '',
+  created_at TEXT COLLATE "C" NOT NULL,
+  created_ms BIGINT GENERATED ALWAYS AS (intermute_ms(created_at)) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_id ON events(id);
+CREATE INDEX IF NOT EXISTS idx_events_entity ON events(project, entity_type, entity_id, cursor);
+CREATE INDEX IF NOT EXISTS idx_events_created_ms ON events(project, created_ms);
+
+CREATE TABLE IF NOT EXISTS messages (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  message_id TEXT COLLATE "C" NOT NULL,
+  thread_id TEXT COLLATE "C",
+  from_agent TEXT COLLATE "C",
+  to_json TEXT COLLATE "C",
+  cc_json TEXT COLLATE "C",
+  bcc_json TEXT COLLATE "C",
+  subject TEXT COLLATE "C",
+  body TEXT COLLATE "C",
+  importance TEXT COLLATE "C",
+  ack_required BIGINT NOT NULL DEFAULT 0,
+  topic TEXT COLLATE "C" NOT NULL DEFAULT '',
+  transport TEXT COLLATE "C" NOT NULL DEFAULT 'async',
+  in_reply_to TEXT COLLATE "C" NOT NULL DEFAULT '',
+  groups_json TEXT COLLATE "C" NOT NULL DEFAULT '{}',
+  attachments_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  route_json TEXT COLLATE "C" NOT NULL DEFAULT '',
+  ack_policy TEXT COLLATE "C" NOT NULL DEFAULT '',
+  quorum_reached_at TEXT COLLATE "C",
+  created_at TEXT COLLATE "C" NOT NULL,
+  created_ms BIGINT GENERATED ALWAYS AS (intermute_ms(created_at)) STORED,
+  -- SQLite's implicit rowid, which topic listings page by.
+  rowid BIGINT GENERATED ALWAYS AS IDENTITY,
+  PRIMARY KEY (project, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_project_topic ON messages(project, topic, rowid);
+CREATE INDEX IF NOT EXISTS idx_messages_thread ON messages(project, thread_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_created_ms ON messages(project, created_ms);
+
+CREATE TABLE IF NOT EXISTS inbox_index (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  agent TEXT COLLATE "C" NOT NULL,
+  cursor BIGINT NOT NULL,
+  message_id TEXT COLLATE "C" NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_agent_cursor ON inbox_index(project, agent, cursor);
+
+CREATE TABLE IF NOT EXISTS message_recipients (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  message_id TEXT COLLATE "C" NOT NULL,
+  agent_id TEXT COLLATE "C" NOT NULL,
+  kind TEXT COLLATE "C" NOT NULL DEFAULT 'to',
+  read_at TEXT COLLATE "C",
+  ack_at TEXT COLLATE "C",
+  injected_at TEXT COLLATE "C",
+  snoozed_until TEXT COLLATE "C",
+  PRIMARY KEY (project, message_id, agent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipients_agent ON message_recipients(project, agent_id);
+
+CREATE TABLE IF NOT EXISTS pending_pokes (
+  project TEXT COLLATE "C" NOT NULL,
+  recipient TEXT COLLATE "C" NOT NULL,
+  message_id TEXT COLLATE "C" NOT NULL,
+  sender TEXT COLLATE "C" NOT NULL,
+  body TEXT COLLATE "C" NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL,
+  surfaced_at TEXT COLLATE "C",
+  PRIMARY KEY (project, recipient, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_pokes_unread ON pending_pokes(project, recipient, surfaced_at);
+
+CREATE TABLE IF NOT EXISTS config (
+  id BIGINT PRIMARY KEY CHECK (id = 1),
+  live_transport_enabled BIGINT NOT NULL DEFAULT 1
+);
+
+INSERT INTO config (id, live_transport_enabled) VALUES (1, 1) ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS file_reservations (
+  id TEXT COLLATE "C" PRIMARY KEY,
+  agent_id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL,
+  path_pattern TEXT COLLATE "C" NOT NULL,
+  exclusive BIGINT NOT NULL DEFAULT 1,
+  reason TEXT COLLATE "C",
+  created_at TEXT COLLATE "C" NOT NULL,
+  expires_at TEXT COLLATE "C" NOT NULL,
+  released_at TEXT COLLATE "C",
+  created_ms BIGINT GENERATED ALWAYS AS (intermute_ms(created_at)) STORED,
+  expires_ms BIGINT GENERATED ALWAYS AS (intermute_ms(expires_at)) STORED,
+  released_ms BIGINT GENERATED ALWAYS AS (intermute_ms(released_at)) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_project ON file_reservations(project);
+CREATE INDEX IF NOT EXISTS idx_reservations_agent ON file_reservations(agent_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_active ON file_reservations(project, expires_at) WHERE released_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reservations_active_ms ON file_reservations(project, expires_ms) WHERE released_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS reservation_takeovers (
+  id TEXT COLLATE "C" PRIMARY KEY,
+  project TEXT COLLATE "C" NOT NULL,
+  reservation_id TEXT COLLATE "C" NOT NULL,
+  holder TEXT COLLATE "C" NOT NULL,
+  requester TEXT COLLATE "C" NOT NULL,
+  reason TEXT COLLATE "C" NOT NULL DEFAULT '',
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'pending',
+  requested_at TEXT COLLATE "C" NOT NULL,
+  deadline TEXT COLLATE "C" NOT NULL,
+  resolved_at TEXT COLLATE "C",
+  new_reservation_id TEXT COLLATE "C" NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_takeovers_reservation ON reservation_takeovers(reservation_id, requested_at);
+CREATE INDEX IF NOT EXISTS idx_takeovers_pending ON reservation_takeovers(deadline) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS agents (
+  id TEXT COLLATE "C" PRIMARY KEY,
+  session_id TEXT COLLATE "C",
+  name TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C",
+  token TEXT COLLATE "C",
+  capabilities_json TEXT COLLATE "C",
+  metadata_json TEXT COLLATE "C",
+  status TEXT COLLATE "C",
+  contact_policy TEXT COLLATE "C" NOT NULL DEFAULT 'open',
+  focus_state TEXT COLLATE "C" NOT NULL DEFAULT 'unknown',
+  focus_state_updated TEXT COLLATE "C" NOT NULL DEFAULT '',
+  live_contact_policy TEXT COLLATE "C" NOT NULL DEFAULT 'contacts_only',
+  created_at TEXT COLLATE "C" NOT NULL,
+  last_seen TEXT COLLATE "C" NOT NULL,
+  last_seen_ms BIGINT GENERATED ALWAYS AS (intermute_ms(last_seen)) STORED
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agents_session_id ON agents(session_id) WHERE session_id IS NOT NULL AND session_id != '';
+CREATE INDEX IF NOT EXISTS idx_agents_token ON agents(token) WHERE token IS NOT NULL AND token != '';
+CREATE INDEX IF NOT EXISTS idx_agents_last_seen_ms ON agents(last_seen_ms);
+
+CREATE TABLE IF NOT EXISTS contact_groups (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  name TEXT COLLATE "C" NOT NULL,
+  description TEXT COLLATE "C" NOT NULL DEFAULT '',
+  team BIGINT NOT NULL DEFAULT 0,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, name)
+);
+
+CREATE TABLE IF NOT EXISTS contact_group_members (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  group_name TEXT COLLATE "C" NOT NULL,
+  agent_id TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, group_name, agent_id)
+);
+
+CREATE TABLE IF NOT EXISTS agent_contacts (
+  agent_id TEXT COLLATE "C" NOT NULL,
+  contact_agent_id TEXT COLLATE "C" NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (agent_id, contact_agent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_agent ON agent_contacts(agent_id);
+
+CREATE TABLE IF NOT EXISTS thread_index (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  thread_id TEXT COLLATE "C" NOT NULL,
+  agent TEXT COLLATE "C" NOT NULL,
+  last_cursor BIGINT NOT NULL,
+  message_count BIGINT NOT NULL DEFAULT 1,
+  last_message_from TEXT COLLATE "C" NOT NULL DEFAULT '',
+  last_message_body TEXT COLLATE "C" NOT NULL DEFAULT '',
+  last_message_at TEXT COLLATE "C" NOT NULL DEFAULT '',
+  unread_count BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (project, thread_id, agent)
+);
+
+CREATE INDEX IF NOT EXISTS idx_thread_agent_cursor ON thread_index(project, agent, last_cursor DESC);
+
+-- Domain tables for Autarch integration
+
+CREATE TABLE IF NOT EXISTS specs (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  title TEXT COLLATE "C" NOT NULL,
+  vision TEXT COLLATE "C",
+  users TEXT COLLATE "C",
+  problem TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'draft',
+  version BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  short_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  last_reviewed_at TEXT COLLATE "C",
+  stale_notified_at TEXT COLLATE "C",
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_specs_status ON specs(project, status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_specs_short_id ON specs(project, short_id) WHERE short_id != '';
+
+CREATE TABLE IF NOT EXISTS epics (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  spec_id TEXT COLLATE "C",
+  title TEXT COLLATE "C" NOT NULL,
+  description TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'open',
+  version BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  short_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_epics_spec ON epics(project, spec_id);
+CREATE INDEX IF NOT EXISTS idx_epics_status ON epics(project, status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_epics_short_id ON epics(project, short_id) WHERE short_id != '';
+
+CREATE TABLE IF NOT EXISTS stories (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  epic_id TEXT COLLATE "C" NOT NULL,
+  title TEXT COLLATE "C" NOT NULL,
+  acceptance_criteria_json TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'todo',
+  priority TEXT COLLATE "C" NOT NULL DEFAULT 'medium',
+  rank TEXT COLLATE "C" NOT NULL DEFAULT '',
+  version BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  short_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stories_epic ON stories(project, epic_id);
+CREATE INDEX IF NOT EXISTS idx_stories_status ON stories(project, status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stories_short_id ON stories(project, short_id) WHERE short_id != '';
+CREATE INDEX IF NOT EXISTS idx_stories_rank ON stories(project, epic_id, rank);
+
+CREATE TABLE IF NOT EXISTS tasks (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  story_id TEXT COLLATE "C",
+  title TEXT COLLATE "C" NOT NULL,
+  agent TEXT COLLATE "C",
+  session_id TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'pending',
+  priority TEXT COLLATE "C" NOT NULL DEFAULT 'medium',
+  due_at TEXT COLLATE "C",
+  version BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  short_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  capabilities_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  expected_paths_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  lease_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  blocked_reason TEXT COLLATE "C" NOT NULL DEFAULT '',
+  blocked_detail TEXT COLLATE "C" NOT NULL DEFAULT '',
+  unblock_json TEXT COLLATE "C" NOT NULL DEFAULT '',
+  created_ms BIGINT GENERATED ALWAYS AS (intermute_ms(created_at)) STORED,
+  updated_ms BIGINT GENERATED ALWAYS AS (intermute_ms(updated_at)) STORED,
+  due_ms BIGINT GENERATED ALWAYS AS (intermute_ms(due_at)) STORED,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_story ON tasks(project, story_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(project, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_agent ON tasks(project, agent);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_short_id ON tasks(project, short_id) WHERE short_id != '';
+CREATE INDEX IF NOT EXISTS idx_tasks_lease ON tasks(project, lease_id) WHERE lease_id != '';
+CREATE INDEX IF NOT EXISTS idx_tasks_unblock ON tasks(project) WHERE status = 'blocked' AND unblock_json != '';
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_ms ON tasks(project, updated_ms);
+
+-- A batch claim's shared lease: while it is live the claimed tasks stay
+-- with the agent; when it expires, the ones still running go back to
+-- pending. tasks.lease_id points here.
+CREATE TABLE IF NOT EXISTS task_leases (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL,
+  agent TEXT COLLATE "C" NOT NULL,
+  expires_at TEXT COLLATE "C" NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL,
+  expires_ms BIGINT GENERATED ALWAYS AS (intermute_ms(expires_at)) STORED,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_leases_expires ON task_leases(expires_at);
+CREATE INDEX IF NOT EXISTS idx_task_leases_expires_ms ON task_leases(expires_ms);
+
+CREATE TABLE IF NOT EXISTS insights (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  spec_id TEXT COLLATE "C",
+  source TEXT COLLATE "C" NOT NULL,
+  category TEXT COLLATE "C" NOT NULL,
+  title TEXT COLLATE "C" NOT NULL,
+  body TEXT COLLATE "C",
+  url TEXT COLLATE "C",
+  score DOUBLE PRECISION NOT NULL DEFAULT 0,
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'new',
+  version BIGINT NOT NULL DEFAULT 1,
+  content_hash TEXT COLLATE "C" NOT NULL DEFAULT '',
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL DEFAULT '',
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_insights_spec ON insights(project, spec_id);
+CREATE INDEX IF NOT EXISTS idx_insights_category ON insights(project, category);
+CREATE INDEX IF NOT EXISTS idx_insights_source ON insights(project, source);
+CREATE INDEX IF NOT EXISTS idx_insights_content_hash ON insights(project, content_hash);
+
+CREATE TABLE IF NOT EXISTS insight_rules (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  name TEXT COLLATE "C" NOT NULL,
+  category TEXT COLLATE "C" NOT NULL DEFAULT '',
+  source TEXT COLLATE "C" NOT NULL DEFAULT '',
+  min_score DOUBLE PRECISION,
+  spec_id TEXT COLLATE "C" NOT NULL DEFAULT '',
+  notify_agent TEXT COLLATE "C" NOT NULL DEFAULT '',
+  enabled BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE TABLE IF NOT EXISTS lifecycle_hooks (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  name TEXT COLLATE "C" NOT NULL,
+  entity_type TEXT COLLATE "C" NOT NULL,
+  from_status TEXT COLLATE "C" NOT NULL DEFAULT '',
+  to_status TEXT COLLATE "C" NOT NULL,
+  actions_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  enabled BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE TABLE IF NOT EXISTS lifecycle_hook_runs (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  hook_id TEXT COLLATE "C" NOT NULL,
+  hook_name TEXT COLLATE "C" NOT NULL,
+  entity_type TEXT COLLATE "C" NOT NULL,
+  entity_id TEXT COLLATE "C" NOT NULL,
+  from_status TEXT COLLATE "C" NOT NULL DEFAULT '',
+  to_status TEXT COLLATE "C" NOT NULL,
+  results_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  ok BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lifecycle_hook_runs_hook ON lifecycle_hook_runs(project, hook_id, created_at);
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  name TEXT COLLATE "C" NOT NULL DEFAULT '',
+  kind TEXT COLLATE "C" NOT NULL,
+  cadence TEXT COLLATE "C" NOT NULL,
+  hour BIGINT NOT NULL DEFAULT 0,
+  weekday BIGINT NOT NULL DEFAULT 0,
+  recipients_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  enabled BIGINT NOT NULL DEFAULT 1,
+  last_run_at TEXT COLLATE "C",
+  next_run_at TEXT COLLATE "C" NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_due ON report_schedules(enabled, next_run_at);
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  name TEXT COLLATE "C" NOT NULL,
+  enabled BIGINT NOT NULL DEFAULT 0,
+  description TEXT COLLATE "C" NOT NULL DEFAULT '',
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, name)
+);
+
+CREATE TABLE IF NOT EXISTS agent_capabilities (
+  project TEXT COLLATE "C" NOT NULL,
+  name TEXT COLLATE "C" NOT NULL,
+  description TEXT COLLATE "C" NOT NULL DEFAULT '',
+  aliases_json TEXT COLLATE "C" NOT NULL DEFAULT '[]',
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, name)
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  name TEXT COLLATE "C" NOT NULL,
+  agent TEXT COLLATE "C" NOT NULL,
+  task_id TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'running',
+  version BIGINT NOT NULL DEFAULT 1,
+  started_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  heartbeat_at TEXT COLLATE "C",
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(project, status);
+CREATE INDEX IF NOT EXISTS idx_sessions_agent ON sessions(project, agent);
+
+-- CUJ (Critical User Journey) tables
+
+CREATE TABLE IF NOT EXISTS cujs (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  spec_id TEXT COLLATE "C" NOT NULL,
+  title TEXT COLLATE "C" NOT NULL,
+  persona TEXT COLLATE "C",
+  priority TEXT COLLATE "C" NOT NULL DEFAULT 'medium',
+  entry_point TEXT COLLATE "C",
+  exit_point TEXT COLLATE "C",
+  steps_json TEXT COLLATE "C",
+  success_criteria_json TEXT COLLATE "C",
+  error_recovery_json TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'draft',
+  version BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cujs_spec ON cujs(project, spec_id);
+CREATE INDEX IF NOT EXISTS idx_cujs_status ON cujs(project, status);
+CREATE INDEX IF NOT EXISTS idx_cujs_priority ON cujs(project, priority);
+
+CREATE TABLE IF NOT EXISTS cuj_feature_links (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  cuj_id TEXT COLLATE "C" NOT NULL,
+  feature_id TEXT COLLATE "C" NOT NULL,
+  linked_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, cuj_id, feature_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cuj_links_cuj ON cuj_feature_links(project, cuj_id);
+CREATE INDEX IF NOT EXISTS idx_cuj_links_feature ON cuj_feature_links(project, feature_id);
+
+-- Immutable published spec snapshots
+
+CREATE TABLE IF NOT EXISTS spec_published_versions (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  spec_id TEXT COLLATE "C" NOT NULL,
+  number BIGINT NOT NULL,
+  snapshot_json TEXT COLLATE "C" NOT NULL,
+  published_by TEXT COLLATE "C" NOT NULL DEFAULT '',
+  published_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, spec_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS spec_revisions (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  spec_id TEXT COLLATE "C" NOT NULL,
+  version BIGINT NOT NULL,
+  snapshot_json TEXT COLLATE "C" NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, spec_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS goals (
+  id TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  title TEXT COLLATE "C" NOT NULL,
+  description TEXT COLLATE "C",
+  period TEXT COLLATE "C",
+  key_results_json TEXT COLLATE "C",
+  status TEXT COLLATE "C" NOT NULL DEFAULT 'active',
+  version BIGINT NOT NULL DEFAULT 1,
+  created_at TEXT COLLATE "C" NOT NULL,
+  updated_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_status ON goals(project, status);
+
+CREATE TABLE IF NOT EXISTS goal_links (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  goal_id TEXT COLLATE "C" NOT NULL,
+  entity_type TEXT COLLATE "C" NOT NULL,
+  entity_id TEXT COLLATE "C" NOT NULL,
+  linked_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, goal_id, entity_type, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_links_entity ON goal_links(project, entity_type, entity_id);
+
+-- Window identity persistence (maps tmux window UUID to stable agent ID)
+
+CREATE TABLE IF NOT EXISTS window_identities (
+  id TEXT COLLATE "C" PRIMARY KEY,
+  project TEXT COLLATE "C" NOT NULL,
+  window_uuid TEXT COLLATE "C" NOT NULL,
+  agent_id TEXT COLLATE "C" NOT NULL,
+  display_name TEXT COLLATE "C" NOT NULL,
+  tmux_target TEXT COLLATE "C" NOT NULL DEFAULT '',
+  created_at TEXT COLLATE "C" NOT NULL,
+  last_active_at TEXT COLLATE "C" NOT NULL,
+  expires_at TEXT COLLATE "C",
+  UNIQUE(project, window_uuid)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_window_project ON window_identities(project, window_uuid);
+
+-- Archived projects. archive_path is set when the project's rows were
+-- moved out to a cold archive database; row_count is how many moved.
+
+CREATE TABLE IF NOT EXISTS project_archives (
+  project TEXT COLLATE "C" PRIMARY KEY,
+  archived_at TEXT COLLATE "C" NOT NULL,
+  archive_path TEXT COLLATE "C" NOT NULL DEFAULT '',
+  row_count BIGINT NOT NULL DEFAULT 0
+);
+
+-- Registered projects. Whether one is archived is project_archives' to
+-- say; the row stays here either way.
+
+CREATE TABLE IF NOT EXISTS projects (
+  name TEXT COLLATE "C" PRIMARY KEY,
+  display_name TEXT COLLATE "C" NOT NULL DEFAULT '',
+  description TEXT COLLATE "C" NOT NULL DEFAULT '',
+  created_at TEXT COLLATE "C" NOT NULL,
+  spec_stale_days BIGINT NOT NULL DEFAULT 0,
+  short_id_sequences_json TEXT COLLATE "C" NOT NULL DEFAULT ''
+);
+
+-- Per-project counters behind short IDs (SPEC-12, TASK-348); last is the
+-- most recently issued number for the prefix. A project's custom prefixes
+-- and starting numbers are in projects.short_id_sequences_json.
+
+CREATE TABLE IF NOT EXISTS id_sequences (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  prefix TEXT COLLATE "C" NOT NULL,
+  last BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (project, prefix)
+);
+
+-- Per-project retention for messages and events; zero disables a bound.
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+  project TEXT COLLATE "C" PRIMARY KEY,
+  max_age_days BIGINT NOT NULL DEFAULT 0,
+  max_rows BIGINT NOT NULL DEFAULT 0,
+  updated_at TEXT COLLATE "C" NOT NULL
+);
+
+-- Responses to POSTs and PATCHes sent with an Idempotency-Key, replayed on retry.
+-- The sweeper forgets them after core.IdempotencyKeyTTL.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  key TEXT COLLATE "C" NOT NULL,
+  request_hash TEXT COLLATE "C" NOT NULL,
+  status BIGINT NOT NULL,
+  content_type TEXT COLLATE "C" NOT NULL DEFAULT '',
+  headers_json TEXT COLLATE "C" NOT NULL DEFAULT '{}',
+  body BYTEA,
+  created_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at);
+
+-- Insights merged into another by MergeInsights. GetInsight on a merged
+-- ID returns the insight it went into.
+CREATE TABLE IF NOT EXISTS insight_merges (
+  project TEXT COLLATE "C" NOT NULL,
+  merged_id TEXT COLLATE "C" NOT NULL,
+  into_id TEXT COLLATE "C" NOT NULL,
+  merged_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, merged_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_insight_merges_into ON insight_merges(project, into_id);
+
+-- Progress notes agents record against a task. seq orders a task's
+-- comments and is the cursor their listing pages by.
+CREATE TABLE IF NOT EXISTS task_comments (
+  seq BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  id TEXT COLLATE "C" NOT NULL UNIQUE,
+  project TEXT COLLATE "C" NOT NULL,
+  task_id TEXT COLLATE "C" NOT NULL,
+  author TEXT COLLATE "C" NOT NULL DEFAULT '',
+  body TEXT COLLATE "C" NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments(project, task_id, seq);
+
+-- Payloads too large for a message body. Messages refer to them by ID in
+-- attachments_json; the bytes are only read when a blob is fetched.
+CREATE TABLE IF NOT EXISTS blobs (
+  project TEXT COLLATE "C" NOT NULL,
+  id TEXT COLLATE "C" NOT NULL,
+  sha256 TEXT COLLATE "C" NOT NULL,
+  size BIGINT NOT NULL,
+  content_type TEXT COLLATE "C" NOT NULL DEFAULT '',
+  data BYTEA NOT NULL,
+  created_at TEXT COLLATE "C" NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blobs_sha256 ON blobs(project, sha256);
+
+-- Full-text search over specs, stories, insights and messages, kept in
+-- step with them by triggers. tsv weights titles above bodies, as
+-- ranking in SQLite's search_fts does by the title column.
+CREATE TABLE IF NOT EXISTS search_docs (
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  kind TEXT COLLATE "C" NOT NULL,
+  project TEXT COLLATE "C" NOT NULL DEFAULT '',
+  entity_id TEXT COLLATE "C" NOT NULL,
+  title TEXT COLLATE "C" NOT NULL DEFAULT '',
+  body TEXT COLLATE "C" NOT NULL DEFAULT '',
+  tsv tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', body), 'B')
+  ) STORED,
+  UNIQUE (kind, project, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_docs_tsv ON search_docs USING GIN (tsv);
+
+CREATE OR REPLACE FUNCTION search_specs_sync() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    DELETE FROM search_docs WHERE kind = 'spec' AND project = old.project AND entity_id = old.id;
+    RETURN old;
+  END IF;
+  INSERT INTO search_docs (kind, project, entity_id, title, body)
+  VALUES ('spec', new.project, new.id, new.title, COALESCE(new.vision, ''))
+  ON CONFLICT (kind, project, entity_id) DO UPDATE SET title = excluded.title, body = excluded.body;
+  RETURN new;
+END
+$$;
+
+DROP TRIGGER IF EXISTS search_specs_sync ON specs;
+CREATE TRIGGER search_specs_sync AFTER INSERT OR UPDATE OR DELETE ON specs
+FOR EACH ROW EXECUTE FUNCTION search_specs_sync();
+
+CREATE OR REPLACE FUNCTION search_stories_sync() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    DELETE FROM search_docs WHERE kind = 'story' AND project = old.project AND entity_id = old.id;
+    RETURN old;
+  END IF;
+  INSERT INTO search_docs (kind, project, entity_id, title, body)
+  VALUES ('story', new.project, new.id, new.title, COALESCE(new.acceptance_criteria_json, ''))
+  ON CONFLICT (kind, project, entity_id) DO UPDATE SET title = excluded.title, body = excluded.body;
+  RETURN new;
+END
+$$;
+
+DROP TRIGGER IF EXISTS search_stories_sync ON stories;
+CREATE TRIGGER search_stories_sync AFTER INSERT OR UPDATE OR DELETE ON stories
+FOR EACH ROW EXECUTE FUNCTION search_stories_sync();
+
+CREATE OR REPLACE FUNCTION search_insights_sync() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    DELETE FROM search_docs WHERE kind = 'insight' AND project = old.project AND entity_id = old.id;
+    RETURN old;
+  END IF;
+  INSERT INTO search_docs (kind, project, entity_id, title, body)
+  VALUES ('insight', new.project, new.id, new.title, COALESCE(new.body, ''))
+  ON CONFLICT (kind, project, entity_id) DO UPDATE SET title = excluded.title, body = excluded.body;
+  RETURN new;
+END
+$$;
+
+DROP TRIGGER IF EXISTS search_insights_sync ON insights;
+CREATE TRIGGER search_insights_sync AFTER INSERT OR UPDATE OR DELETE ON insights
+FOR EACH ROW EXECUTE FUNCTION search_insights_sync();
+
+CREATE OR REPLACE FUNCTION search_messages_sync() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    DELETE FROM search_docs WHERE kind = 'message' AND project = old.project AND entity_id = old.message_id;
+    RETURN old;
+  END IF;
+  INSERT INTO search_docs (kind, project, entity_id, title, body)
+  VALUES ('message', new.project, new.message_id, COALESCE(new.subject, ''), COALESCE(new.body, ''))
+  ON CONFLICT (kind, project, entity_id) DO UPDATE SET title = excluded.title, body = excluded.body;
+  RETURN new;
+END
+$$;
+
+DROP TRIGGER IF EXISTS search_messages_sync ON messages;
+CREATE TRIGGER search_messages_sync AFTER INSERT OR UPDATE OR DELETE ON messages
+FOR EACH ROW EXECUTE FUNCTION search_messages_sync();
+
+-- Event fan-out payloads too large for a NOTIFY, which carries only the
+-- id. Listeners read them back; rows older than a minute are swept.
+CREATE TABLE IF NOT EXISTS fanout_payloads (
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  payload TEXT COLLATE "C" NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+// Where the schemas differ by design.
+var (
+	// sqliteOnly are SQLite's full-text index and its internals; PostgreSQL
+	// indexes search_docs itself.
+	sqliteOnly = []string{"search_fts", "search_fts_config", "search_fts_content", "search_fts_data", "search_fts_docsize", "search_fts_idx"}
+	// postgresOnly are the migration log and the payloads of NOTIFYs too
+	// big to send inline (see Notifier).
+	postgresOnly = []string{"schema_migrations", "fanout_payloads"}
+	// postgresOnlyColumns stand in for SQLite's implicit messages.rowid and
+	// for what search_fts holds.
+	postgresOnlyColumns = []string{"messages.rowid", "search_docs.title", "search_docs.body", "search_docs.tsv"}
+)
+
+// TestSchemaMatchesSQLite checks that a migrated PostgreSQL schema has the
+// tables and columns a new SQLite database gets, so a change to one schema
+// can't ship without the other.
+func TestSchemaMatchesSQLite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "intermute.db")
+	st, err := sqlite.New(path)
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	st.Close()
+	lite, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer lite.Close()
+	rows, err := lite.QueryContext(ctx, `SELECT m.name, p.name FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'`)
+	if err != nil {
+		t.Fatalf("sqlite columns: %v", err)
+	}
+	want := map[string][]string{}
+	for rows.Next() {
+		var table, col string
+		if err := rows.Scan(&table, &col); err != nil {
+			t.Fatalf("scan sqlite column: %v", err)
+		}
+		want[table] = append(want[table], col)
+	}
+	rows.Close()
+
+	dsn := newTestSchema(t)
+	newTestStore(t, dsn)
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer conn.Close(ctx)
+	pgRows, err := conn.Query(ctx, `SELECT table_name::text, column_name::text FROM information_schema.columns
+		WHERE table_schema = current_schema()`)
+	if err != nil {
+		t.Fatalf("postgres columns: %v", err)
+	}
+	got := map[string][]string{}
+	for pgRows.Next() {
+		var table, col string
+		if err := pgRows.Scan(&table, &col); err != nil {
+			t.Fatalf("scan postgres column: %v", err)
+		}
+		got[table] = append(got[table], col)
+	}
+	if err := pgRows.Err(); err != nil {
+		t.Fatalf("postgres columns: %v", err)
+	}
+
+	for table, cols := range want {
+		if slices.Contains(sqliteOnly, table) {
+			continue
+		}
+		if _, ok := got[table]; !ok {
+			t.Errorf("table %s is missing from postgres", table)
+			continue
+		}
+		for _, col := range cols {
+			if !slices.Contains(got[table], col) {
+				t.Errorf("column %s.%s is missing from postgres", table, col)
+			}
+		}
+	}
+	for table, cols := range got {
+		if slices.Contains(postgresOnly, table) {
+			continue
+		}
+		if _, ok := want[table]; !ok {
+			t.Errorf("table %s is missing from sqlite", table)
+			continue
+		}
+		for _, col := range cols {
+			if !slices.Contains(want[table], col) && !slices.Contains(postgresOnlyColumns, table+"."+col) {
+				t.Errorf("column %s.%s is missing from sqlite", table, col)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QueryStats accumulates the SQL a backend runs on behalf of one request,
+// so the HTTP layer can spot N+1 patterns per route. Backends record into
+// the stats found on the context they are handed; queries issued without
+// that context are not counted.
+type QueryStats struct {
+	// Route names the request the queries belong to, for slow-query logs.
+	Route string
+
+	mu    sync.Mutex
+	count int
+	total time.Duration
+}
+
+type queryStatsKey struct{}
+
+// WithQueryStats attaches fresh QueryStats for route to ctx.
+func WithQueryStats(ctx context.Context, route string) (context.Context, *QueryStats) {
+	qs := &QueryStats{Route: route}
+	return context.WithValue(ctx, queryStatsKey{}, qs), qs
+}
+
+// QueryStatsFromContext returns the stats attached to ctx, or nil.
+func QueryStatsFromContext(ctx context.Context) *QueryStats {
+	if ctx == nil {
+		return nil
+	}
+	qs, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return qs
+}
+
+// Record adds one query that took d. It is safe on a nil receiver.
+func (q *QueryStats) Record(d time.Duration) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.count++
+	q.total += d
+	q.mu.Unlock()
+}
+
+// Totals returns the number of queries recorded and their summed duration.
+func (q *QueryStats) Totals() (count int, total time.Duration) {
+	if q == nil {
+		return 0, 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count, q.total
+}
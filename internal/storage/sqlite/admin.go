@@ -0,0 +1,242 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// adminOverviewQuery computes per-project activity in a single statement.
+// The project set is the union of every table that carries a project
+// column operators care about, so a project with only reservations or only
+// specs still shows up. Archived projects are included and flagged, even
+// when their rows have moved to a cold archive.
+const adminOverviewQuery = `
+WITH projects AS (
+  SELECT project FROM agents WHERE project IS NOT NULL
+  UNION SELECT project FROM sessions
+  UNION SELECT project FROM tasks
+  UNION SELECT project FROM specs
+  UNION SELECT project FROM epics
+  UNION SELECT project FROM file_reservations
+  UNION SELECT project FROM messages
+  UNION SELECT project FROM project_archives
+)
+SELECT p.project,
+  (SELECT COUNT(*) FROM agents a WHERE a.project = p.project),
+  (SELECT COUNT(*) FROM agents a WHERE a.project = p.project AND a.last_seen_ms >= ?),
+  (SELECT COUNT(*) FROM sessions s WHERE s.project = p.project AND s.status IN ('running', 'idle')),
+  (SELECT COUNT(*) FROM epics e WHERE e.project = p.project AND e.status != 'done'),
+  (SELECT COUNT(*) FROM tasks t WHERE t.project = p.project AND t.status != 'done'),
+  (SELECT COUNT(*) FROM tasks t WHERE t.project = p.project AND t.status = 'running'),
+  (SELECT COUNT(*) FROM file_reservations r WHERE r.project = p.project AND r.released_at IS NULL AND r.expires_ms > ?),
+  (SELECT COUNT(*) FROM messages m WHERE m.project = p.project),
+  (SELECT MAX(ts) FROM (
+     SELECT MAX(created_at) AS ts FROM events e WHERE e.project = p.project
+     UNION ALL SELECT MAX(last_seen) FROM agents a WHERE a.project = p.project
+     UNION ALL SELECT MAX(updated_at) FROM tasks t WHERE t.project = p.project
+     UNION ALL SELECT MAX(updated_at) FROM sessions s WHERE s.project = p.project
+  )),
+  EXISTS (SELECT 1 FROM project_archives pa WHERE pa.project = p.project)
+FROM projects p
+ORDER BY p.project`
+
+// AdminOverview reports per-project counts and last activity across the
+// whole database, plus the on-disk size of the database.
+func (s *Store) AdminOverview(ctx context.Context) (core.AdminOverview, error) {
+	now := clock.Now().UTC()
+	rows, err := s.db.QueryContext(ctx, adminOverviewQuery,
+		unixMillis(now.Add(-core.SessionStaleThreshold)), unixMillis(now))
+	if err != nil {
+		return core.AdminOverview{}, fmt.Errorf("admin overview: %w", err)
+	}
+	defer rows.Close()
+
+	out := core.AdminOverview{Projects: []core.ProjectStats{}, GeneratedAt: now}
+	for rows.Next() {
+		var ps core.ProjectStats
+		var last sql.NullString
+		if err := rows.Scan(&ps.Project, &ps.Agents, &ps.ActiveAgents, &ps.ActiveSessions,
+			&ps.OpenEpics, &ps.OpenTasks, &ps.InProgressTasks, &ps.ActiveReservations, &ps.Messages, &last, &ps.Archived); err != nil {
+			return core.AdminOverview{}, fmt.Errorf("scan admin overview: %w", err)
+		}
+		if last.Valid && last.String != "" {
+			if t, err := time.Parse(time.RFC3339Nano, last.String); err == nil {
+				ps.LastActivity = &t
+			}
+		}
+		out.Projects = append(out.Projects, ps)
+	}
+	if err := rows.Err(); err != nil {
+		return core.AdminOverview{}, err
+	}
+
+	size, err := s.dbSizeBytes(ctx)
+	if err != nil {
+		return core.AdminOverview{}, err
+	}
+	out.DBSizeBytes = size
+	return out, nil
+}
+
+// DBSizeBytes returns the size of the main database file.
+func (s *Store) DBSizeBytes(ctx context.Context) (int64, error) {
+	return s.dbSizeBytes(ctx)
+}
+
+// pruneCandidate is a retention policy StorageReport can estimate: where
+// selects the rows it would delete from table.
+type pruneCandidate struct {
+	policy      string
+	description string
+	table       string
+	where       string
+	args        func(now time.Time) []any
+}
+
+var pruneCandidates = []pruneCandidate{
+	{
+		policy:      "events_30d",
+		description: "Delete event log rows older than 30 days (cursors before the cutoff can no longer be replayed)",
+		table:       "events",
+		where:       "created_ms < ?",
+		args: func(now time.Time) []any {
+			return []any{unixMillis(now.AddDate(0, 0, -30))}
+		},
+	},
+	{
+		policy:      "messages_90d",
+		description: "Delete messages older than 90 days along with their recipients",
+		table:       "messages",
+		where:       "created_ms < ?",
+		args: func(now time.Time) []any {
+			return []any{unixMillis(now.AddDate(0, 0, -90))}
+		},
+	},
+	{
+		policy:      "reservations_7d",
+		description: "Delete file reservations released or expired more than 7 days ago",
+		table:       "file_reservations",
+		where:       "COALESCE(released_ms, expires_ms) < ?",
+		args: func(now time.Time) []any {
+			return []any{unixMillis(now.AddDate(0, 0, -7))}
+		},
+	},
+}
+
+// StorageReport sizes every table (on SQLite, via the dbstat virtual table)
+// and estimates what each known retention policy would reclaim.
+func (s *Store) StorageReport(ctx context.Context) (core.StorageReport, error) {
+	now := clock.Now().UTC()
+	out := core.StorageReport{Tables: []core.TableSize{}, Suggestions: []core.PruneSuggestion{}, GeneratedAt: now}
+
+	size, err := s.dbSizeBytes(ctx)
+	if err != nil {
+		return core.StorageReport{}, err
+	}
+	out.DBSizeBytes = size
+
+	tables, err := s.dialectOf().TableSizes(ctx, s.db)
+	if err != nil {
+		return core.StorageReport{}, fmt.Errorf("table sizes: %w", err)
+	}
+	bytesByTable := map[string]int64{}
+	for _, t := range tables {
+		bytesByTable[t.Table] = t.Bytes
+		out.Tables = append(out.Tables, t)
+	}
+
+	for _, c := range pruneCandidates {
+		var total, matching int64
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+c.table).Scan(&total); err != nil {
+			return core.StorageReport{}, fmt.Errorf("count %s: %w", c.table, err)
+		}
+		if total == 0 {
+			continue
+		}
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+c.table+` WHERE `+c.where, c.args(now)...).Scan(&matching); err != nil {
+			return core.StorageReport{}, fmt.Errorf("count %s: %w", c.policy, err)
+		}
+		if matching == 0 {
+			continue
+		}
+		out.Suggestions = append(out.Suggestions, core.PruneSuggestion{
+			Policy:         c.policy,
+			Description:    c.description,
+			Table:          c.table,
+			Rows:           matching,
+			EstimatedBytes: bytesByTable[c.table] * matching / total,
+		})
+	}
+	slices.SortStableFunc(out.Suggestions, func(a, b core.PruneSuggestion) int {
+		return cmp.Compare(b.EstimatedBytes, a.EstimatedBytes)
+	})
+	return out, nil
+}
+
+// dbSizeBytes returns the dialect's database size: on SQLite,
+// page_count * page_size.
+func (s *Store) dbSizeBytes(ctx context.Context) (int64, error) {
+	return s.dialectOf().DBSizeBytes(ctx, s.db)
+}
+
+// DomainMetrics snapshots the coordination gauges exported on /metrics.
+// Agents count as stale once their heartbeat is older than
+// core.SessionStaleThreshold.
+func (s *Store) DomainMetrics(ctx context.Context) (core.DomainMetrics, error) {
+	now := clock.Now().UTC()
+	var out core.DomainMetrics
+	var err error
+
+	out.TasksByStatus, err = s.labeledCounts(ctx,
+		`SELECT project, status, COUNT(*) FROM tasks GROUP BY project, status ORDER BY project, status`)
+	if err != nil {
+		return core.DomainMetrics{}, fmt.Errorf("tasks by status: %w", err)
+	}
+	out.StaleAgents, err = s.labeledCounts(ctx,
+		`SELECT COALESCE(project, ''), '', COUNT(*) FROM agents WHERE last_seen_ms < ? GROUP BY project ORDER BY project`,
+		unixMillis(now.Add(-core.SessionStaleThreshold)))
+	if err != nil {
+		return core.DomainMetrics{}, fmt.Errorf("stale agents: %w", err)
+	}
+	out.ActiveReservations, err = s.labeledCounts(ctx,
+		`SELECT project, '', COUNT(*) FROM file_reservations
+		 WHERE released_at IS NULL AND expires_ms > ? GROUP BY project ORDER BY project`,
+		unixMillis(now))
+	if err != nil {
+		return core.DomainMetrics{}, fmt.Errorf("active reservations: %w", err)
+	}
+	out.UnackedMessages, err = s.labeledCounts(ctx,
+		`SELECT r.project, '', COUNT(*) FROM message_recipients r
+		 JOIN messages m ON m.project = r.project AND m.message_id = r.message_id
+		 WHERE m.ack_required = 1 AND r.ack_at IS NULL
+		 GROUP BY r.project ORDER BY r.project`)
+	if err != nil {
+		return core.DomainMetrics{}, fmt.Errorf("unacked messages: %w", err)
+	}
+	return out, nil
+}
+
+// labeledCounts runs a (project, status, count) query.
+func (s *Store) labeledCounts(ctx context.Context, query string, args ...any) ([]core.LabeledCount, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []core.LabeledCount{}
+	for rows.Next() {
+		var c core.LabeledCount
+		if err := rows.Scan(&c.Project, &c.Status, &c.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
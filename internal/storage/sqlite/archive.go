@@ -0,0 +1,329 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Project archival
+//
+// Archiving a project hides it from default listings. With a cold path, the
+// project's rows in every project-scoped table are also copied into a
+// separate SQLite file (one table per source table, same columns) and
+// deleted from the hot database, taking their index entries with them.
+// Reactivation copies them back and removes the file.
+//
+// On SQLite the cold file is ATTACHed to the store's single connection for
+// the duration of the move; archiveMu keeps two moves from sharing the
+// alias.
+
+var archiveMu sync.Mutex
+
+// projectTable is a table with a project column, and its columns.
+type projectTable struct {
+	name    string
+	columns []string
+}
+
+// unarchivedTables are never moved: project_archives itself, the search
+// index (triggers drop and rebuild its entries as rows move) and the
+// short-ID counters.
+var unarchivedTables = []string{"project_archives", "search_docs", "id_sequences"}
+
+// projectTables lists the tables that have a project column, with the
+// columns an insert may set.
+func (s *Store) projectTables(ctx context.Context, tx dbTx) ([]projectTable, error) {
+	d := s.dialectOf()
+	names, err := d.Tables(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	var out []projectTable
+	for _, name := range names {
+		if slices.Contains(unarchivedTables, name) {
+			continue
+		}
+		cols, err := d.TableColumns(ctx, tx, name)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(cols, "project") {
+			out = append(out, projectTable{name: name, columns: cols})
+		}
+	}
+	return out, nil
+}
+
+// tableNames lists the ordinary tables of SQLite schema schemaName ("main"
+// or "cold"), in name order.
+func tableNames(ctx context.Context, q Querier, schemaName string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name FROM `+schemaName+`.sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND sql NOT LIKE 'CREATE VIRTUAL TABLE%'
+		ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func tableColumns(ctx context.Context, q Querier, schemaName, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name FROM pragma_table_info(?, ?)`, table, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// OpenCold attaches path as schema "cold" until the archive is closed.
+func (sqliteDialect) OpenCold(ctx context.Context, q Querier, path string) (ColdArchive, error) {
+	if _, err := q.ExecContext(ctx, `ATTACH DATABASE ? AS cold`, path); err != nil {
+		return nil, fmt.Errorf("attach archive: %w", err)
+	}
+	return attachedCold{q: q}, nil
+}
+
+// attachedCold is a cold file ATTACHed as schema "cold".
+type attachedCold struct {
+	q Querier
+}
+
+func (attachedCold) Save(ctx context.Context, tx Querier, table string, _ []string, project string) error {
+	_, err := tx.ExecContext(ctx,
+		`CREATE TABLE cold.`+table+` AS SELECT * FROM main.`+table+` WHERE project = ?`, project)
+	return err
+}
+
+// Restore copies every cold table back into main by column name, so
+// columns added by migrations since archival take their defaults.
+func (attachedCold) Restore(ctx context.Context, tx Querier, project string) error {
+	names, err := tableNames(ctx, tx, "cold")
+	if err != nil {
+		return fmt.Errorf("list archived tables: %w", err)
+	}
+	for _, name := range names {
+		cols, err := tableColumns(ctx, tx, "cold", name)
+		if err != nil {
+			return err
+		}
+		list := strings.Join(cols, ", ")
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO main.`+name+` (`+list+`) SELECT `+list+` FROM cold.`+name+` WHERE project = ?`, project,
+		); err != nil {
+			return fmt.Errorf("restore %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (c attachedCold) Close() error {
+	_, err := c.q.ExecContext(context.Background(), `DETACH DATABASE cold`)
+	return err
+}
+
+// ArchiveProject marks project archived. With a non-empty coldPath its rows
+// are moved into a new SQLite file at that path. Returns
+// core.ErrAlreadyExists if the project is already archived.
+func (s *Store) ArchiveProject(ctx context.Context, project, coldPath string) (core.ProjectArchive, error) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	if _, err := s.getProjectArchive(ctx, project); err == nil {
+		return core.ProjectArchive{}, core.ErrAlreadyExists
+	} else if !errors.Is(err, core.ErrNotFound) {
+		return core.ProjectArchive{}, err
+	}
+
+	archive := core.ProjectArchive{Project: project, ArchivedAt: time.Now().UTC(), ArchivePath: coldPath}
+	if coldPath != "" {
+		if _, err := os.Stat(coldPath); err == nil {
+			return core.ProjectArchive{}, fmt.Errorf("archive file %s already exists", coldPath)
+		}
+		if err := os.MkdirAll(filepath.Dir(coldPath), 0755); err != nil {
+			return core.ProjectArchive{}, fmt.Errorf("create archive dir: %w", err)
+		}
+		cold, err := s.dialectOf().OpenCold(ctx, s.db, coldPath)
+		if err != nil {
+			return core.ProjectArchive{}, err
+		}
+		moved, err := s.moveProjectRows(ctx, project, archive, cold)
+		if closeErr := cold.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("close archive: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(coldPath)
+			return core.ProjectArchive{}, err
+		}
+		archive.Rows = moved
+		return archive, nil
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO project_archives (project, archived_at, archive_path, row_count) VALUES (?, ?, '', 0)`,
+		project, archive.ArchivedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		return core.ProjectArchive{}, fmt.Errorf("archive project: %w", err)
+	}
+	return archive, nil
+}
+
+// moveProjectRows copies project's rows into cold, deletes them and
+// records the archive, in one transaction.
+func (s *Store) moveProjectRows(ctx context.Context, project string, archive core.ProjectArchive, cold ColdArchive) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin archive: %w", err)
+	}
+	defer tx.Rollback()
+
+	tables, err := s.projectTables(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	var moved int64
+	for _, t := range tables {
+		if err := cold.Save(ctx, tx, t.name, t.columns, project); err != nil {
+			return 0, fmt.Errorf("archive %s: %w", t.name, err)
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM `+t.name+` WHERE project = ?`, project)
+		if err != nil {
+			return 0, fmt.Errorf("clear %s: %w", t.name, err)
+		}
+		n, _ := res.RowsAffected()
+		moved += n
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO project_archives (project, archived_at, archive_path, row_count) VALUES (?, ?, ?, ?)`,
+		project, archive.ArchivedAt.Format(time.RFC3339Nano), archive.ArchivePath, moved,
+	); err != nil {
+		return 0, fmt.Errorf("archive project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit archive: %w", err)
+	}
+	return moved, nil
+}
+
+// ReactivateProject un-archives project, re-importing its rows from the cold
+// archive (and then deleting the file) if it had one. Returns
+// core.ErrNotFound if the project isn't archived.
+func (s *Store) ReactivateProject(ctx context.Context, project string) (core.ProjectArchive, error) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	archive, err := s.getProjectArchive(ctx, project)
+	if err != nil {
+		return core.ProjectArchive{}, err
+	}
+	if archive.ArchivePath == "" {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM project_archives WHERE project = ?`, project); err != nil {
+			return core.ProjectArchive{}, fmt.Errorf("reactivate project: %w", err)
+		}
+		return archive, nil
+	}
+
+	if _, err := os.Stat(archive.ArchivePath); err != nil {
+		return core.ProjectArchive{}, fmt.Errorf("archive file: %w", err)
+	}
+	cold, err := s.dialectOf().OpenCold(ctx, s.db, archive.ArchivePath)
+	if err != nil {
+		return core.ProjectArchive{}, err
+	}
+	err = s.restoreProjectRows(ctx, project, cold)
+	if closeErr := cold.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close archive: %w", closeErr)
+	}
+	if err != nil {
+		return core.ProjectArchive{}, err
+	}
+	if err := os.Remove(archive.ArchivePath); err != nil {
+		return core.ProjectArchive{}, fmt.Errorf("remove archive file: %w", err)
+	}
+	return archive, nil
+}
+
+// restoreProjectRows copies project's rows back from cold and drops its
+// archive record, in one transaction.
+func (s *Store) restoreProjectRows(ctx context.Context, project string, cold ColdArchive) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin reactivate: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := cold.Restore(ctx, tx, project); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM project_archives WHERE project = ?`, project); err != nil {
+		return fmt.Errorf("reactivate project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit reactivate: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) getProjectArchive(ctx context.Context, project string) (core.ProjectArchive, error) {
+	var a core.ProjectArchive
+	var archivedAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT project, archived_at, archive_path, row_count FROM project_archives WHERE project = ?`, project,
+	).Scan(&a.Project, &archivedAt, &a.ArchivePath, &a.Rows)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ProjectArchive{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.ProjectArchive{}, fmt.Errorf("get project archive: %w", err)
+	}
+	a.ArchivedAt, _ = time.Parse(time.RFC3339Nano, archivedAt)
+	return a, nil
+}
+
+// ListProjectArchives returns archived projects, most recently archived
+// first.
+func (s *Store) ListProjectArchives(ctx context.Context) ([]core.ProjectArchive, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT project, archived_at, archive_path, row_count FROM project_archives ORDER BY archived_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list project archives: %w", err)
+	}
+	defer rows.Close()
+	out := []core.ProjectArchive{}
+	for rows.Next() {
+		var a core.ProjectArchive
+		var archivedAt string
+		if err := rows.Scan(&a.Project, &archivedAt, &a.ArchivePath, &a.Rows); err != nil {
+			return nil, fmt.Errorf("scan project archive: %w", err)
+		}
+		a.ArchivedAt, _ = time.Parse(time.RFC3339Nano, archivedAt)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func migrateMessageAttachments(db *sql.DB) error {
+	if !tableExists(db, "messages") {
+		return nil
+	}
+	if !tableHasColumn(db, "messages", "attachments_json") {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN attachments_json TEXT NOT NULL DEFAULT '[]'`); err != nil {
+			return fmt.Errorf("add attachments_json column: %w", err)
+		}
+	}
+	return nil
+}
+
+// PutBlob stores data under blob.Project, filling in the blob's ID, size,
+// hash and creation time. Bytes the project already has come back as the
+// existing blob.
+func (s *Store) PutBlob(ctx context.Context, blob core.Blob, data []byte) (core.Blob, error) {
+	sum := sha256.Sum256(data)
+	blob.SHA256 = hex.EncodeToString(sum[:])
+	blob.Size = int64(len(data))
+	if existing, err := s.blobInfo(ctx, `project = ? AND sha256 = ?`, blob.Project, blob.SHA256); err == nil {
+		return existing, nil
+	} else if !errors.Is(err, core.ErrNotFound) {
+		return core.Blob{}, err
+	}
+	blob.ID = uuid.NewString()
+	blob.CreatedAt = clock.Now().UTC()
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO blobs (project, id, sha256, size, content_type, data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		blob.Project, blob.ID, blob.SHA256, blob.Size, blob.ContentType, data, blob.CreatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		return core.Blob{}, fmt.Errorf("put blob: %w", err)
+	}
+	return blob, nil
+}
+
+// GetBlob returns a blob and its bytes, or core.ErrNotFound.
+func (s *Store) GetBlob(ctx context.Context, project, id string) (core.Blob, []byte, error) {
+	blob := core.Blob{Project: project, ID: id}
+	var data []byte
+	var createdAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT sha256, size, content_type, data, created_at FROM blobs WHERE project = ? AND id = ?`, project, id,
+	).Scan(&blob.SHA256, &blob.Size, &blob.ContentType, &data, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Blob{}, nil, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Blob{}, nil, fmt.Errorf("get blob: %w", err)
+	}
+	blob.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	return blob, data, nil
+}
+
+// BlobInfo returns a blob without its bytes, or core.ErrNotFound.
+func (s *Store) BlobInfo(ctx context.Context, project, id string) (core.Blob, error) {
+	return s.blobInfo(ctx, `project = ? AND id = ?`, project, id)
+}
+
+func (s *Store) blobInfo(ctx context.Context, where string, args ...any) (core.Blob, error) {
+	var blob core.Blob
+	var createdAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT project, id, sha256, size, content_type, created_at FROM blobs WHERE `+where, args...,
+	).Scan(&blob.Project, &blob.ID, &blob.SHA256, &blob.Size, &blob.ContentType, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Blob{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Blob{}, fmt.Errorf("blob info: %w", err)
+	}
+	blob.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	return blob, nil
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Capability registry operations
+
+// SetCapability creates or replaces a capability in its project's registry.
+func (s *Store) SetCapability(ctx context.Context, c core.Capability) (core.Capability, error) {
+	if c.Aliases == nil {
+		c.Aliases = []string{}
+	}
+	aliasesJSON, err := json.Marshal(c.Aliases)
+	if err != nil {
+		return core.Capability{}, fmt.Errorf("marshal aliases: %w", err)
+	}
+	c.UpdatedAt = clock.Now().UTC()
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO agent_capabilities (project, name, description, aliases_json, updated_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(project, name) DO UPDATE SET description = excluded.description, aliases_json = excluded.aliases_json, updated_at = excluded.updated_at`,
+		c.Project, c.Name, c.Description, string(aliasesJSON), c.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.Capability{}, fmt.Errorf("set capability: %w", err)
+	}
+	return c, nil
+}
+
+// ListCapabilities returns a project's registry ordered by name.
+func (s *Store) ListCapabilities(ctx context.Context, project string) ([]core.Capability, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT project, name, description, aliases_json, updated_at FROM agent_capabilities WHERE project = ? ORDER BY name`,
+		project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list capabilities: %w", err)
+	}
+	defer rows.Close()
+
+	var caps []core.Capability
+	for rows.Next() {
+		var c core.Capability
+		var aliasesJSON, updatedAt string
+		if err := rows.Scan(&c.Project, &c.Name, &c.Description, &aliasesJSON, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan capability: %w", err)
+		}
+		_ = json.Unmarshal([]byte(aliasesJSON), &c.Aliases)
+		if c.Aliases == nil {
+			c.Aliases = []string{}
+		}
+		c.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+		caps = append(caps, c)
+	}
+	return caps, rows.Err()
+}
+
+func (s *Store) DeleteCapability(ctx context.Context, project, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM agent_capabilities WHERE project = ? AND name = ?`, project, name)
+	if err != nil {
+		return fmt.Errorf("delete capability: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
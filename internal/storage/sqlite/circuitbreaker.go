@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -42,6 +43,7 @@ type CircuitBreaker struct {
 	resetTimeout time.Duration
 	lastFailure  time.Time
 	nowFunc      func() time.Time // for testing
+	onChange     func(from, to BreakerState)
 }
 
 // NewCircuitBreaker creates a circuit breaker with the given threshold and reset timeout.
@@ -53,6 +55,23 @@ func NewCircuitBreaker(threshold int, resetTimeout time.Duration) *CircuitBreake
 	}
 }
 
+// OnStateChange calls fn, outside the breaker's lock, after each state
+// change. Call before the breaker is used.
+func (cb *CircuitBreaker) OnStateChange(fn func(from, to BreakerState)) {
+	cb.onChange = fn
+}
+
+// setState moves the breaker to to, returning a func that reports the
+// change, to be called once cb.mu is released. cb.mu must be held.
+func (cb *CircuitBreaker) setState(to BreakerState) func() {
+	from := cb.state
+	cb.state = to
+	if from == to || cb.onChange == nil {
+		return func() {}
+	}
+	return func() { cb.onChange(from, to) }
+}
+
 // Execute runs fn through the circuit breaker. Returns ErrCircuitOpen if the
 // breaker is open and the reset timeout hasn't elapsed.
 func (cb *CircuitBreaker) Execute(fn func() error) error {
@@ -62,33 +81,40 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 		cb.mu.Unlock()
 		err := fn()
 		cb.mu.Lock()
-		if err != nil {
+		report := func() {}
+		switch {
+		case canceled(err):
+			// The caller gave up; that says nothing about the database.
+		case err != nil:
 			cb.failures++
 			if cb.failures >= cb.threshold {
-				cb.state = StateOpen
+				report = cb.setState(StateOpen)
 				cb.lastFailure = cb.nowFunc()
 			}
-		} else {
+		default:
 			cb.failures = 0
 		}
 		cb.mu.Unlock()
+		report()
 		return err
 
 	case StateOpen:
 		if cb.nowFunc().Sub(cb.lastFailure) >= cb.resetTimeout {
 			// Transition to half-open: allow one probe request
-			cb.state = StateHalfOpen
+			report := cb.setState(StateHalfOpen)
 			cb.mu.Unlock()
+			report()
 			err := fn()
 			cb.mu.Lock()
 			if err != nil {
-				cb.state = StateOpen
+				report = cb.setState(StateOpen)
 				cb.lastFailure = cb.nowFunc()
 			} else {
-				cb.state = StateClosed
+				report = cb.setState(StateClosed)
 				cb.failures = 0
 			}
 			cb.mu.Unlock()
+			report()
 			return err
 		}
 		cb.mu.Unlock()
@@ -105,6 +131,11 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 	}
 }
 
+// canceled reports whether err came from a cancelled or expired context.
+func canceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
 // State returns the current breaker state.
 func (cb *CircuitBreaker) State() BreakerState {
 	cb.mu.Lock()
@@ -1,10 +1,13 @@
 package sqlite
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
 )
 
 func TestBreakerStartsClosed(t *testing.T) {
@@ -124,3 +127,76 @@ func TestBreakerConcurrentAccess(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestResilientWriteFailuresLeaveReadsUp(t *testing.T) {
+	ctx := context.Background()
+	rs := NewResilientWithSettings(NewSQLiteTest(t), 2, 30*time.Second)
+	task, err := rs.CreateTask(ctx, core.Task{Project: "p", Title: "t", Status: core.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := task
+	stale.Version = task.Version + 5
+	for range 2 {
+		if _, err := rs.UpdateTask(ctx, stale); err == nil {
+			t.Fatal("stale update succeeded")
+		}
+	}
+
+	if _, err := rs.CreateTask(ctx, core.Task{Project: "p", Title: "u"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("write with writes breaker open: %v, want ErrCircuitOpen", err)
+	}
+	if _, err := rs.GetTask(ctx, "p", task.ID); err != nil {
+		t.Fatalf("read with writes breaker open: %v", err)
+	}
+	states := rs.CircuitBreakerStates()
+	if states[BreakerWrites] != "open" || states[BreakerReads] != "closed" || states[BreakerSweeps] != "closed" {
+		t.Fatalf("states = %v", states)
+	}
+	if got := rs.CircuitBreakerState(); got != "open" {
+		t.Fatalf("overall state = %q, want open", got)
+	}
+}
+
+type systemRecorder struct {
+	mu     sync.Mutex
+	events []core.SystemEvent
+}
+
+func (r *systemRecorder) NotifySystem(ev core.SystemEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func TestResilientReportsBreakerChanges(t *testing.T) {
+	ctx := context.Background()
+	rs := NewResilientWithSettings(NewSQLiteTest(t), 2, 30*time.Second)
+	rec := &systemRecorder{}
+	rs.SetSystemNotifier(rec)
+	now := time.Now()
+	rs.writes.nowFunc = func() time.Time { return now }
+
+	task, err := rs.CreateTask(ctx, core.Task{Project: "p", Title: "t", Status: core.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := task
+	stale.Version = task.Version + 5
+	for range 3 {
+		_, _ = rs.UpdateTask(ctx, stale)
+	}
+	now = now.Add(31 * time.Second)
+	if _, err := rs.CreateTask(ctx, core.Task{Project: "p", Title: "u"}); err != nil {
+		t.Fatalf("probe write: %v", err)
+	}
+
+	if len(rec.events) != 2 {
+		t.Fatalf("events = %+v, want open then closed", rec.events)
+	}
+	for i, want := range []core.EventType{core.EventSystemCircuitOpen, core.EventSystemCircuitClosed} {
+		if ev := rec.events[i]; ev.Type != want || ev.Data["breaker"] != BreakerWrites {
+			t.Fatalf("events[%d] = %+v, want %s for writes", i, ev, want)
+		}
+	}
+}
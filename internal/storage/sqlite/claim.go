@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func migrateTaskCapabilities(db *sql.DB) error {
+	if !tableExists(db, "tasks") {
+		return nil
+	}
+	if !tableHasColumn(db, "tasks", "capabilities_json") {
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN capabilities_json TEXT NOT NULL DEFAULT '[]'`); err != nil {
+			return fmt.Errorf("add capabilities_json column: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClaimTasks assigns up to claim.Limit of the oldest pending tasks that
+// claim.Agent can do to it, moving them to running. Selection and
+// assignment happen in one transaction, so concurrent claims never get the
+// same task. Returns the claimed tasks, oldest first; none is not an error.
+// With claim.LeaseID set, the lease is created with the claim and every
+// claimed task is put under it; a claim that finds nothing creates none.
+func (s *Store) ClaimTasks(ctx context.Context, claim core.TaskClaim) ([]core.Task, error) {
+	limit := claim.Limit
+	if limit <= 0 {
+		limit = 1
+	}
+
+	query := `SELECT id FROM tasks WHERE project = ? AND status = ?`
+	args := []any{claim.Project, string(core.TaskStatusPending)}
+	if !claim.Steal {
+		query += ` AND (COALESCE(agent, '') = '' OR agent = ?)`
+		args = append(args, claim.Agent)
+	}
+	if len(claim.Capabilities) == 0 {
+		query += ` AND json_array_length(capabilities_json) = 0`
+	} else {
+		query += ` AND NOT EXISTS (SELECT 1 FROM json_each(tasks.capabilities_json)
+			WHERE lower(trim(json_each.value)) NOT IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(claim.Capabilities)), ", ") + `))`
+		for _, c := range claim.Capabilities {
+			args = append(args, core.FoldCapability(c))
+		}
+	}
+	query += ` ORDER BY created_at, id LIMIT ?`
+	args = append(args, limit)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin claim tasks: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select claimable tasks: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan claimable task: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	if claim.LeaseID != "" && len(ids) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO task_leases (id, project, agent, expires_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+			claim.LeaseID, claim.Project, claim.Agent, claim.LeaseExpiresAt.UTC().Format(time.RFC3339Nano), now,
+		); err != nil {
+			return nil, fmt.Errorf("create task lease: %w", err)
+		}
+	}
+	claimed := make([]core.Task, 0, len(ids))
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE tasks SET agent = ?, status = ?, lease_id = ?, version = version + 1, updated_at = ? WHERE project = ? AND id = ?`,
+			claim.Agent, string(core.TaskStatusRunning), claim.LeaseID, now, claim.Project, id,
+		); err != nil {
+			return nil, fmt.Errorf("claim task %s: %w", id, err)
+		}
+		task, err := scanTask(tx.QueryRowContext(ctx,
+			`SELECT `+taskColumns+`
+			 FROM tasks WHERE project = ? AND id = ?`, claim.Project, id))
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, task)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit claim tasks: %w", err)
+	}
+	return claimed, nil
+}
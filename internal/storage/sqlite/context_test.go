@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestCancelledContextAbortsListQueries(t *testing.T) {
+	st := NewSQLiteTest(t)
+	ctx := context.Background()
+	for i := 0; i < 200; i++ {
+		if _, err := st.CreateTask(ctx, core.Task{Project: "proj", Title: fmt.Sprintf("task %d", i), Status: core.TaskStatusPending}); err != nil {
+			t.Fatalf("create task %d: %v", i, err)
+		}
+	}
+	if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Project: "proj", Message: core.Message{
+		ID: "m1", ThreadID: "t1", Project: "proj", From: "alice", To: []string{"bob"}, Body: "hi",
+	}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	expired, cancelExpired := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"cancelled", cancelled, context.Canceled},
+		{"deadline", expired, context.DeadlineExceeded},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := st.ListTasks(tc.ctx, "proj", "", ""); !errors.Is(err, tc.want) {
+				t.Fatalf("ListTasks err = %v, want %v", err, tc.want)
+			}
+			if _, err := st.ListSpecs(tc.ctx, "proj", ""); !errors.Is(err, tc.want) {
+				t.Fatalf("ListSpecs err = %v, want %v", err, tc.want)
+			}
+			if _, err := st.InboxSince(tc.ctx, "proj", "bob", 0, 0); !errors.Is(err, tc.want) {
+				t.Fatalf("InboxSince err = %v, want %v", err, tc.want)
+			}
+			if _, err := st.ListThreads(tc.ctx, "proj", "bob", 0, 50); !errors.Is(err, tc.want) {
+				t.Fatalf("ListThreads err = %v, want %v", err, tc.want)
+			}
+			if _, err := st.ListAgents(tc.ctx, "proj", nil); !errors.Is(err, tc.want) {
+				t.Fatalf("ListAgents err = %v, want %v", err, tc.want)
+			}
+			if _, err := st.CreateTask(tc.ctx, core.Task{Project: "proj", Title: "late", Status: core.TaskStatusPending}); !errors.Is(err, tc.want) {
+				t.Fatalf("CreateTask err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	// The aborted writes left nothing behind, and the store still works.
+	tasks, err := st.ListTasks(ctx, "proj", "", "")
+	if err != nil {
+		t.Fatalf("list after cancel: %v", err)
+	}
+	if len(tasks) != 200 {
+		t.Fatalf("tasks = %d, want 200", len(tasks))
+	}
+}
+
+func TestResilientStoreIgnoresCancellationForBreaker(t *testing.T) {
+	st := NewSQLiteTest(t)
+	rs := NewResilient(st)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 10; i++ {
+		if _, err := rs.ListTasks(cancelled, "proj", "", ""); !errors.Is(err, context.Canceled) {
+			t.Fatalf("ListTasks err = %v, want context.Canceled", err)
+		}
+	}
+	if _, err := rs.ListTasks(context.Background(), "proj", "", ""); err != nil {
+		t.Fatalf("ListTasks after cancellations: %v", err)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"github.com/mistakeknot/intermute/internal/clock"
 )
 
 // CoordinationBridge mirrors reservations to Intercore's coordination_locks table.
@@ -89,7 +91,7 @@ func (b *CoordinationBridge) MirrorRelease(id string) {
 		return
 	}
 	_, err := b.db.Exec(`UPDATE coordination_locks SET released_at = ? WHERE id = ? AND released_at IS NULL`,
-		time.Now().Unix(), id)
+		clock.Now().Unix(), id)
 	if err != nil {
 		log.Printf("coordination bridge: mirror release %s: %v", id, err)
 	}
@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Dialect is what the Store needs from a database other than SQLite.
+//
+// Everything else the Store runs is SQL that SQLite and the other database
+// share: the other database's driver rebinds ? placeholders, and its schema
+// defines the SQLite functions the queries call (json_each, json_extract,
+// json_array_length). What can't be shared — catalog lookups, sizing,
+// full-text search and cold archives — goes through the dialect.
+// internal/storage/postgres provides the PostgreSQL one; New and NewInMemory
+// use SQLite's.
+type Dialect interface {
+	// Tables lists the ordinary tables, excluding full-text index internals.
+	Tables(ctx context.Context, q Querier) ([]string, error)
+	// TableColumns lists the columns of table an insert may set, in
+	// declaration order.
+	TableColumns(ctx context.Context, q Querier, table string) ([]string, error)
+	// InsertionOrder is an ORDER BY expression listing table's rows in the
+	// order they were written.
+	InsertionOrder(table string) string
+	// TableSizes reports the bytes each table takes, its indexes included,
+	// largest first.
+	TableSizes(ctx context.Context, q Querier) ([]core.TableSize, error)
+	// DBSizeBytes reports the size of the whole database.
+	DBSizeBytes(ctx context.Context, q Querier) (int64, error)
+	// Search runs q, whose Limit is already clamped, against the full-text
+	// index, best match first.
+	Search(ctx context.Context, q Querier, query core.SearchQuery) ([]core.SearchResult, error)
+	// OpenCold opens the cold archive file at path, creating it if needed.
+	OpenCold(ctx context.Context, q Querier, path string) (ColdArchive, error)
+	// Close releases db when the Store closes.
+	Close(db *sql.DB) error
+}
+
+// ColdArchive is an archived project's rows, in a SQLite file of one table
+// per source table with the same columns.
+type ColdArchive interface {
+	// Save copies project's rows of table, whose columns are columns, from
+	// tx into the archive.
+	Save(ctx context.Context, tx Querier, table string, columns []string, project string) error
+	// Restore copies every archived row of project back through tx, by
+	// column name.
+	Restore(ctx context.Context, tx Querier, project string) error
+	// Close releases the archive file.
+	Close() error
+}
+
+// Querier runs queries. *sql.DB, *sql.Tx and the Store's own handles
+// satisfy it.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Open returns a Store over db, a database whose schema is already in
+// place, that runs the database-specific parts of its work through d.
+// Unlike New, it leaves db's connection pool as the caller set it up: the
+// database must isolate the Store's transactions from each other itself.
+func Open(db *sql.DB, d Dialect) *Store {
+	return &Store{db: &queryLogger{inner: db}, dialect: d}
+}
+
+// dialectOf returns s's dialect, SQLite's when none was given.
+func (s *Store) dialectOf() Dialect {
+	if s.dialect == nil {
+		return sqliteDialect{}
+	}
+	return s.dialect
+}
+
+// sqliteDialect is SQLite's own Dialect.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Tables(ctx context.Context, q Querier) ([]string, error) {
+	return tableNames(ctx, q, "main")
+}
+
+func (sqliteDialect) TableColumns(ctx context.Context, q Querier, table string) ([]string, error) {
+	return tableColumns(ctx, q, "main", table)
+}
+
+func (sqliteDialect) InsertionOrder(string) string {
+	return "rowid"
+}
+
+// TableSizes reads the dbstat virtual table, which reports indexes
+// separately; they are folded into their table.
+func (sqliteDialect) TableSizes(ctx context.Context, q Querier) ([]core.TableSize, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT COALESCE(m.tbl_name, d.name) AS tbl, SUM(d.pgsize) AS bytes
+		 FROM dbstat d LEFT JOIN sqlite_schema m ON m.name = d.name
+		 GROUP BY tbl ORDER BY bytes DESC, tbl`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []core.TableSize
+	for rows.Next() {
+		var t core.TableSize
+		if err := rows.Scan(&t.Table, &t.Bytes); err != nil {
+			return nil, fmt.Errorf("scan table size: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// DBSizeBytes returns page_count * page_size, which tracks the main
+// database file (excluding any uncheckpointed WAL).
+func (sqliteDialect) DBSizeBytes(ctx context.Context, q Querier) (int64, error) {
+	var pages, pageSize int64
+	if err := q.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("page_count: %w", err)
+	}
+	if err := q.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("page_size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// Close checkpoints the WAL before closing db.
+func (sqliteDialect) Close(db *sql.DB) error {
+	_, _ = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	return db.Close()
+}
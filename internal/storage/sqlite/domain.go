@@ -4,21 +4,56 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
 	"github.com/mistakeknot/intermute/internal/core"
 )
 
+// entityAbsentTx returns core.ErrAlreadyExists if table already has id in
+// project, so a create with a caller-supplied ID fails cleanly rather than
+// on the primary key.
+func entityAbsentTx(ctx context.Context, tx dbTx, table, project, id string) error {
+	var exists int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM `+table+` WHERE project = ? AND id = ?`, project, id).Scan(&exists)
+	if err == nil {
+		return core.ErrAlreadyExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("check %s id: %w", table, err)
+	}
+	return nil
+}
+
+// insertedOrExists checks an INSERT ... ON CONFLICT (project, id) DO
+// NOTHING, returning core.ErrAlreadyExists when the ID was taken.
+func insertedOrExists(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return core.ErrAlreadyExists
+	}
+	return nil
+}
+
 // Spec operations
 
-func (s *Store) CreateSpec(_ context.Context, spec core.Spec) (core.Spec, error) {
+func (s *Store) CreateSpec(ctx context.Context, spec core.Spec) (core.Spec, error) {
 	if spec.ID == "" {
 		spec.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	if spec.CreatedAt.IsZero() {
 		spec.CreatedAt = now
 	}
@@ -29,30 +64,55 @@ func (s *Store) CreateSpec(_ context.Context, spec core.Spec) (core.Spec, error)
 		spec.Status = core.SpecStatusDraft
 	}
 	spec.Version = 1
+	spec.LastReviewedAt = nil
+	spec.Stale = false
+	if spec.Status == core.SpecStatusValidated {
+		reviewed := spec.UpdatedAt
+		spec.LastReviewedAt = &reviewed
+	}
 
-	_, err := s.db.Exec(
-		`INSERT INTO specs (id, project, title, vision, users, problem, status, version, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		spec.ID, spec.Project, spec.Title, spec.Vision, spec.Users, spec.Problem,
-		string(spec.Status), spec.Version, spec.CreatedAt.Format(time.RFC3339Nano), spec.UpdatedAt.Format(time.RFC3339Nano),
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Spec{}, fmt.Errorf("begin create spec: %w", err)
+	}
+	defer tx.Rollback()
+	if err := entityAbsentTx(ctx, tx, "specs", spec.Project, spec.ID); err != nil {
+		return core.Spec{}, err
+	}
+	if spec.ShortID, err = nextShortIDTx(ctx, tx, spec.Project, "spec"); err != nil {
+		return core.Spec{}, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO specs (id, project, title, vision, users, problem, status, version, created_at, updated_at, short_id, last_reviewed_at, title_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+titleKeySQL+`)`,
+		append([]any{spec.ID, spec.Project, spec.Title, spec.Vision, spec.Users, spec.Problem,
+			string(spec.Status), spec.Version, spec.CreatedAt.Format(time.RFC3339Nano), spec.UpdatedAt.Format(time.RFC3339Nano), spec.ShortID,
+			nullableTime(spec.LastReviewedAt)}, titleKeyArgs("spec", spec.Project, spec.Title)...)...,
 	)
 	if err != nil {
-		return core.Spec{}, fmt.Errorf("create spec: %w", err)
+		return core.Spec{}, fmt.Errorf("create spec: %w", titleConflict(err))
+	}
+	if err := insertSpecRevisionTx(ctx, tx, spec); err != nil {
+		return core.Spec{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Spec{}, fmt.Errorf("commit create spec: %w", err)
 	}
 	return spec, nil
 }
 
-func (s *Store) GetSpec(_ context.Context, project, id string) (core.Spec, error) {
-	row := s.db.QueryRow(
-		`SELECT id, project, title, vision, users, problem, status, version, created_at, updated_at
-		 FROM specs WHERE project = ? AND id = ?`,
+const specColumns = `id, project, title, vision, users, problem, status, version, created_at, updated_at, short_id, last_reviewed_at`
+
+func (s *Store) GetSpec(ctx context.Context, project, id string) (core.Spec, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+specColumns+` FROM specs WHERE project = ? AND id = ?`,
 		project, id,
 	)
 	return scanSpec(row)
 }
 
-func (s *Store) ListSpecs(_ context.Context, project string, status string) ([]core.Spec, error) {
-	query := `SELECT id, project, title, vision, users, problem, status, version, created_at, updated_at FROM specs`
+func (s *Store) ListSpecs(ctx context.Context, project string, status string) ([]core.Spec, error) {
+	query := `SELECT ` + specColumns + ` FROM specs`
 	var args []any
 	if project != "" {
 		query += " WHERE project = ?"
@@ -65,9 +125,9 @@ func (s *Store) ListSpecs(_ context.Context, project string, status string) ([]c
 		query += " WHERE status = ?"
 		args = append(args, status)
 	}
-	query += " ORDER BY updated_at DESC"
+	query += " ORDER BY updated_ms DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list specs: %w", err)
 	}
@@ -84,41 +144,213 @@ func (s *Store) ListSpecs(_ context.Context, project string, status string) ([]c
 	return specs, rows.Err()
 }
 
-func (s *Store) UpdateSpec(_ context.Context, spec core.Spec) (core.Spec, error) {
-	spec.UpdatedAt = time.Now().UTC()
+func (s *Store) UpdateSpec(ctx context.Context, spec core.Spec) (core.Spec, error) {
+	spec.UpdatedAt = clock.Now().UTC()
 	expectedVersion := spec.Version
 	spec.Version++
-	res, err := s.db.Exec(
-		`UPDATE specs SET title = ?, vision = ?, users = ?, problem = ?, status = ?, version = ?, updated_at = ?
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Spec{}, fmt.Errorf("begin update spec: %w", err)
+	}
+	defer tx.Rollback()
+	// Moving a spec to validated reviews it; SET expressions see the old
+	// status.
+	updatedAt := spec.UpdatedAt.Format(time.RFC3339Nano)
+	validating := spec.Status == core.SpecStatusValidated
+	validated := string(core.SpecStatusValidated)
+	res, err := tx.ExecContext(ctx,
+		`UPDATE specs SET title = ?, vision = ?, users = ?, problem = ?, status = ?, version = ?, updated_at = ?,
+		        last_reviewed_at = CASE WHEN ? = 1 AND status != ? THEN ? ELSE last_reviewed_at END,
+		        stale_notified_at = CASE WHEN ? = 1 AND status != ? THEN NULL ELSE stale_notified_at END,
+		        `+updateTitleKeySQL+`
 		 WHERE project = ? AND id = ? AND version = ?`,
-		spec.Title, spec.Vision, spec.Users, spec.Problem, string(spec.Status), spec.Version,
-		spec.UpdatedAt.Format(time.RFC3339Nano), spec.Project, spec.ID, expectedVersion,
+		slices.Concat([]any{spec.Title, spec.Vision, spec.Users, spec.Problem, string(spec.Status), spec.Version, updatedAt,
+			validating, validated, updatedAt, validating, validated},
+			updateTitleKeyArgs("spec", spec.Project, spec.Title),
+			[]any{spec.Project, spec.ID, expectedVersion})...,
 	)
 	if err != nil {
-		return core.Spec{}, fmt.Errorf("update spec: %w", err)
+		return core.Spec{}, fmt.Errorf("update spec: %w", titleConflict(err))
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
 		return core.Spec{}, core.ErrConcurrentModification
 	}
+	// UPDATE doesn't return created_at; the revision snapshot needs it.
+	// short_id and last_reviewed_at aren't set by the caller.
+	var createdAt string
+	var reviewedAt sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT created_at, short_id, last_reviewed_at FROM specs WHERE project = ? AND id = ?`, spec.Project, spec.ID).Scan(&createdAt, &spec.ShortID, &reviewedAt); err == nil {
+		spec.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		spec.LastReviewedAt = parseNullableTime(reviewedAt)
+	}
+	spec.Stale = false
+	if err := insertSpecRevisionTx(ctx, tx, spec); err != nil {
+		return core.Spec{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Spec{}, fmt.Errorf("commit update spec: %w", err)
+	}
 	return spec, nil
 }
 
-func (s *Store) DeleteSpec(_ context.Context, project, id string) error {
-	_, err := s.db.Exec(`DELETE FROM specs WHERE project = ? AND id = ?`, project, id)
+func (s *Store) DeleteSpec(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM specs WHERE project = ? AND id = ?`, project, id)
 	if err != nil {
 		return fmt.Errorf("delete spec: %w", err)
 	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM spec_revisions WHERE project = ? AND spec_id = ?`, project, id); err != nil {
+		return fmt.Errorf("delete spec revisions: %w", err)
+	}
 	return nil
 }
 
+// insertSpecRevisionTx records spec as it stands at spec.Version, so any
+// two versions can later be compared.
+func insertSpecRevisionTx(ctx context.Context, tx dbTx, spec core.Spec) error {
+	snapshot, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("marshal spec revision: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO spec_revisions (project, spec_id, version, snapshot_json, created_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (project, spec_id, version) DO UPDATE SET snapshot_json = excluded.snapshot_json, created_at = excluded.created_at`,
+		spec.Project, spec.ID, spec.Version, string(snapshot), spec.UpdatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		return fmt.Errorf("insert spec revision: %w", err)
+	}
+	return nil
+}
+
+// GetSpecRevision returns the spec as it was at version. Versions written
+// before revisions were recorded only resolve if they are current.
+func (s *Store) GetSpecRevision(ctx context.Context, project, specID string, version int64) (core.Spec, error) {
+	var snapshot string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT snapshot_json FROM spec_revisions WHERE project = ? AND spec_id = ? AND version = ?`,
+		project, specID, version,
+	).Scan(&snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		current, err := s.GetSpec(ctx, project, specID)
+		if err != nil || current.Version != version {
+			return core.Spec{}, core.ErrNotFound
+		}
+		return current, nil
+	}
+	if err != nil {
+		return core.Spec{}, fmt.Errorf("get spec revision: %w", err)
+	}
+	var spec core.Spec
+	if err := json.Unmarshal([]byte(snapshot), &spec); err != nil {
+		return core.Spec{}, fmt.Errorf("decode spec revision: %w", err)
+	}
+	return spec, nil
+}
+
+// PublishSpec freezes the current spec and its CUJs as the next published
+// version. Snapshots are stored as JSON so later schema or content changes
+// never alter what a permalink returns.
+func (s *Store) PublishSpec(ctx context.Context, project, specID, publishedBy string) (core.PublishedSpec, error) {
+	spec, err := s.GetSpec(ctx, project, specID)
+	if err != nil {
+		return core.PublishedSpec{}, err
+	}
+	cujs, err := s.ListCUJs(ctx, project, specID)
+	if err != nil {
+		return core.PublishedSpec{}, err
+	}
+	if cujs == nil {
+		cujs = []core.CriticalUserJourney{}
+	}
+	pub := core.PublishedSpec{
+		Project:     project,
+		SpecID:      specID,
+		Spec:        spec,
+		CUJs:        cujs,
+		PublishedBy: publishedBy,
+		PublishedAt: clock.Now().UTC(),
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.PublishedSpec{}, fmt.Errorf("begin publish spec: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(number), 0) + 1 FROM spec_published_versions WHERE project = ? AND spec_id = ?`,
+		project, specID,
+	).Scan(&pub.Number); err != nil {
+		return core.PublishedSpec{}, fmt.Errorf("next published number: %w", err)
+	}
+	snapshot, err := json.Marshal(pub)
+	if err != nil {
+		return core.PublishedSpec{}, fmt.Errorf("marshal snapshot: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO spec_published_versions (project, spec_id, number, snapshot_json, published_by, published_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		project, specID, pub.Number, string(snapshot), publishedBy, pub.PublishedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		return core.PublishedSpec{}, fmt.Errorf("insert published spec: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return core.PublishedSpec{}, err
+	}
+	return pub, nil
+}
+
+func (s *Store) GetPublishedSpec(ctx context.Context, project, specID string, number int) (core.PublishedSpec, error) {
+	var snapshot string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT snapshot_json FROM spec_published_versions WHERE project = ? AND spec_id = ? AND number = ?`,
+		project, specID, number,
+	).Scan(&snapshot)
+	if err != nil {
+		return core.PublishedSpec{}, fmt.Errorf("get published spec: %w", err)
+	}
+	var pub core.PublishedSpec
+	if err := json.Unmarshal([]byte(snapshot), &pub); err != nil {
+		return core.PublishedSpec{}, fmt.Errorf("decode published spec: %w", err)
+	}
+	return pub, nil
+}
+
+// ListPublishedSpecs returns every published version of a spec, oldest first.
+func (s *Store) ListPublishedSpecs(ctx context.Context, project, specID string) ([]core.PublishedSpec, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT snapshot_json FROM spec_published_versions WHERE project = ? AND spec_id = ? ORDER BY number`,
+		project, specID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list published specs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []core.PublishedSpec
+	for rows.Next() {
+		var snapshot string
+		if err := rows.Scan(&snapshot); err != nil {
+			return nil, fmt.Errorf("scan published spec: %w", err)
+		}
+		var pub core.PublishedSpec
+		if err := json.Unmarshal([]byte(snapshot), &pub); err != nil {
+			log.Printf("WARN: corrupt snapshot_json for spec %s: %v", specID, err)
+			continue
+		}
+		out = append(out, pub)
+	}
+	return out, rows.Err()
+}
+
 // Epic operations
 
-func (s *Store) CreateEpic(_ context.Context, epic core.Epic) (core.Epic, error) {
+func (s *Store) CreateEpic(ctx context.Context, epic core.Epic) (core.Epic, error) {
 	if epic.ID == "" {
 		epic.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	if epic.CreatedAt.IsZero() {
 		epic.CreatedAt = now
 	}
@@ -130,29 +362,44 @@ func (s *Store) CreateEpic(_ context.Context, epic core.Epic) (core.Epic, error)
 	}
 	epic.Version = 1
 
-	_, err := s.db.Exec(
-		`INSERT INTO epics (id, project, spec_id, title, description, status, version, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		epic.ID, epic.Project, epic.SpecID, epic.Title, epic.Description,
-		string(epic.Status), epic.Version, epic.CreatedAt.Format(time.RFC3339Nano), epic.UpdatedAt.Format(time.RFC3339Nano),
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Epic{}, fmt.Errorf("begin create epic: %w", err)
+	}
+	defer tx.Rollback()
+	if err := entityAbsentTx(ctx, tx, "epics", epic.Project, epic.ID); err != nil {
+		return core.Epic{}, err
+	}
+	if epic.ShortID, err = nextShortIDTx(ctx, tx, epic.Project, "epic"); err != nil {
+		return core.Epic{}, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO epics (id, project, spec_id, title, description, status, version, created_at, updated_at, short_id, title_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+titleKeySQL+`)`,
+		append([]any{epic.ID, epic.Project, epic.SpecID, epic.Title, epic.Description,
+			string(epic.Status), epic.Version, epic.CreatedAt.Format(time.RFC3339Nano), epic.UpdatedAt.Format(time.RFC3339Nano), epic.ShortID},
+			titleKeyArgs("epic", epic.Project, epic.Title)...)...,
 	)
 	if err != nil {
-		return core.Epic{}, fmt.Errorf("create epic: %w", err)
+		return core.Epic{}, fmt.Errorf("create epic: %w", titleConflict(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Epic{}, fmt.Errorf("commit create epic: %w", err)
 	}
 	return epic, nil
 }
 
-func (s *Store) GetEpic(_ context.Context, project, id string) (core.Epic, error) {
-	row := s.db.QueryRow(
-		`SELECT id, project, spec_id, title, description, status, version, created_at, updated_at
+func (s *Store) GetEpic(ctx context.Context, project, id string) (core.Epic, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, project, spec_id, title, description, status, version, created_at, updated_at, short_id
 		 FROM epics WHERE project = ? AND id = ?`,
 		project, id,
 	)
 	return scanEpic(row)
 }
 
-func (s *Store) ListEpics(_ context.Context, project, specID string) ([]core.Epic, error) {
-	query := `SELECT id, project, spec_id, title, description, status, version, created_at, updated_at FROM epics`
+func (s *Store) ListEpics(ctx context.Context, project, specID string) ([]core.Epic, error) {
+	query := `SELECT id, project, spec_id, title, description, status, version, created_at, updated_at, short_id FROM epics`
 	var args []any
 	if project != "" {
 		query += " WHERE project = ?"
@@ -165,9 +412,9 @@ func (s *Store) ListEpics(_ context.Context, project, specID string) ([]core.Epi
 		query += " WHERE spec_id = ?"
 		args = append(args, specID)
 	}
-	query += " ORDER BY updated_at DESC"
+	query += " ORDER BY updated_ms DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list epics: %w", err)
 	}
@@ -184,28 +431,33 @@ func (s *Store) ListEpics(_ context.Context, project, specID string) ([]core.Epi
 	return epics, rows.Err()
 }
 
-func (s *Store) UpdateEpic(_ context.Context, epic core.Epic) (core.Epic, error) {
-	epic.UpdatedAt = time.Now().UTC()
+func (s *Store) UpdateEpic(ctx context.Context, epic core.Epic) (core.Epic, error) {
+	epic.UpdatedAt = clock.Now().UTC()
 	expectedVersion := epic.Version
 	epic.Version++
-	res, err := s.db.Exec(
-		`UPDATE epics SET spec_id = ?, title = ?, description = ?, status = ?, version = ?, updated_at = ?
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE epics SET spec_id = ?, title = ?, description = ?, status = ?, version = ?, updated_at = ?,
+		   `+updateTitleKeySQL+`
 		 WHERE project = ? AND id = ? AND version = ?`,
-		epic.SpecID, epic.Title, epic.Description, string(epic.Status), epic.Version,
-		epic.UpdatedAt.Format(time.RFC3339Nano), epic.Project, epic.ID, expectedVersion,
+		slices.Concat([]any{epic.SpecID, epic.Title, epic.Description, string(epic.Status), epic.Version,
+			epic.UpdatedAt.Format(time.RFC3339Nano)},
+			updateTitleKeyArgs("epic", epic.Project, epic.Title),
+			[]any{epic.Project, epic.ID, expectedVersion})...,
 	)
 	if err != nil {
-		return core.Epic{}, fmt.Errorf("update epic: %w", err)
+		return core.Epic{}, fmt.Errorf("update epic: %w", titleConflict(err))
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
 		return core.Epic{}, core.ErrConcurrentModification
 	}
+	// short_id never changes, but callers needn't send it back.
+	_ = s.db.QueryRowContext(ctx, `SELECT short_id FROM epics WHERE project = ? AND id = ?`, epic.Project, epic.ID).Scan(&epic.ShortID)
 	return epic, nil
 }
 
-func (s *Store) DeleteEpic(_ context.Context, project, id string) error {
-	_, err := s.db.Exec(`DELETE FROM epics WHERE project = ? AND id = ?`, project, id)
+func (s *Store) DeleteEpic(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM epics WHERE project = ? AND id = ?`, project, id)
 	if err != nil {
 		return fmt.Errorf("delete epic: %w", err)
 	}
@@ -214,11 +466,11 @@ func (s *Store) DeleteEpic(_ context.Context, project, id string) error {
 
 // Story operations
 
-func (s *Store) CreateStory(_ context.Context, story core.Story) (core.Story, error) {
+func (s *Store) CreateStory(ctx context.Context, story core.Story) (core.Story, error) {
 	if story.ID == "" {
 		story.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	if story.CreatedAt.IsZero() {
 		story.CreatedAt = now
 	}
@@ -228,34 +480,56 @@ func (s *Store) CreateStory(_ context.Context, story core.Story) (core.Story, er
 	if story.Status == "" {
 		story.Status = core.StoryStatusTodo
 	}
+	if story.Priority == "" {
+		story.Priority = core.PriorityMedium
+	}
 	story.Version = 1
 
 	acJSON, err := json.Marshal(story.AcceptanceCriteria)
 	if err != nil {
 		return core.Story{}, fmt.Errorf("marshal acceptance_criteria: %w", err)
 	}
-	if _, err := s.db.Exec(
-		`INSERT INTO stories (id, project, epic_id, title, acceptance_criteria_json, status, version, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		story.ID, story.Project, story.EpicID, story.Title, string(acJSON),
-		string(story.Status), story.Version, story.CreatedAt.Format(time.RFC3339Nano), story.UpdatedAt.Format(time.RFC3339Nano),
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Story{}, fmt.Errorf("begin create story: %w", err)
+	}
+	defer tx.Rollback()
+	if err := entityAbsentTx(ctx, tx, "stories", story.Project, story.ID); err != nil {
+		return core.Story{}, err
+	}
+	if story.ShortID, err = nextShortIDTx(ctx, tx, story.Project, "story"); err != nil {
+		return core.Story{}, err
+	}
+	if story.Rank, err = lastStoryRankTx(ctx, tx, story.Project, story.EpicID, ""); err != nil {
+		return core.Story{}, err
+	}
+	story.Rank = rankAfter(story.Rank)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO stories (id, project, epic_id, title, acceptance_criteria_json, status, priority, rank, version, created_at, updated_at, short_id, title_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+titleKeySQL+`)`,
+		append([]any{story.ID, story.Project, story.EpicID, story.Title, string(acJSON),
+			string(story.Status), string(story.Priority), story.Rank, story.Version, story.CreatedAt.Format(time.RFC3339Nano), story.UpdatedAt.Format(time.RFC3339Nano), story.ShortID},
+			titleKeyArgs("story", story.Project, story.Title)...)...,
 	); err != nil {
-		return core.Story{}, fmt.Errorf("create story: %w", err)
+		return core.Story{}, fmt.Errorf("create story: %w", titleConflict(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Story{}, fmt.Errorf("commit create story: %w", err)
 	}
 	return story, nil
 }
 
-func (s *Store) GetStory(_ context.Context, project, id string) (core.Story, error) {
-	row := s.db.QueryRow(
-		`SELECT id, project, epic_id, title, acceptance_criteria_json, status, version, created_at, updated_at
+func (s *Store) GetStory(ctx context.Context, project, id string) (core.Story, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, project, epic_id, title, acceptance_criteria_json, status, priority, version, created_at, updated_at, short_id, rank
 		 FROM stories WHERE project = ? AND id = ?`,
 		project, id,
 	)
 	return scanStory(row)
 }
 
-func (s *Store) ListStories(_ context.Context, project, epicID string) ([]core.Story, error) {
-	query := `SELECT id, project, epic_id, title, acceptance_criteria_json, status, version, created_at, updated_at FROM stories`
+func (s *Store) ListStories(ctx context.Context, project, epicID string) ([]core.Story, error) {
+	query := `SELECT id, project, epic_id, title, acceptance_criteria_json, status, priority, version, created_at, updated_at, short_id, rank FROM stories`
 	var args []any
 	if project != "" {
 		query += " WHERE project = ?"
@@ -268,9 +542,9 @@ func (s *Store) ListStories(_ context.Context, project, epicID string) ([]core.S
 		query += " WHERE epic_id = ?"
 		args = append(args, epicID)
 	}
-	query += " ORDER BY updated_at DESC"
+	query += " ORDER BY rank, created_at"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list stories: %w", err)
 	}
@@ -287,32 +561,53 @@ func (s *Store) ListStories(_ context.Context, project, epicID string) ([]core.S
 	return stories, rows.Err()
 }
 
-func (s *Store) UpdateStory(_ context.Context, story core.Story) (core.Story, error) {
-	story.UpdatedAt = time.Now().UTC()
+func (s *Store) UpdateStory(ctx context.Context, story core.Story) (core.Story, error) {
+	if story.Priority == "" {
+		// Older clients don't send priority; keep the stored one.
+		var p string
+		if err := s.db.QueryRowContext(ctx, `SELECT priority FROM stories WHERE project = ? AND id = ?`, story.Project, story.ID).Scan(&p); err == nil {
+			story.Priority = core.Priority(p)
+		}
+	}
+	story.UpdatedAt = clock.Now().UTC()
 	expectedVersion := story.Version
 	story.Version++
 	acJSON, err := json.Marshal(story.AcceptanceCriteria)
 	if err != nil {
 		return core.Story{}, fmt.Errorf("marshal acceptance_criteria: %w", err)
 	}
-	res, err := s.db.Exec(
-		`UPDATE stories SET epic_id = ?, title = ?, acceptance_criteria_json = ?, status = ?, version = ?, updated_at = ?
+	// A story moved to another epic goes to the end of it.
+	var lastRank string
+	_ = s.db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(rank), '') FROM stories WHERE project = ? AND epic_id = ? AND id != ?`,
+		story.Project, story.EpicID, story.ID,
+	).Scan(&lastRank)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE stories SET rank = CASE WHEN epic_id = ? THEN rank ELSE ? END,
+		   epic_id = ?, title = ?, acceptance_criteria_json = ?, status = ?, priority = ?, version = ?, updated_at = ?,
+		   `+updateTitleKeySQL+`
 		 WHERE project = ? AND id = ? AND version = ?`,
-		story.EpicID, story.Title, string(acJSON), string(story.Status), story.Version,
-		story.UpdatedAt.Format(time.RFC3339Nano), story.Project, story.ID, expectedVersion,
+		slices.Concat([]any{story.EpicID, rankAfter(lastRank),
+			story.EpicID, story.Title, string(acJSON), string(story.Status), string(story.Priority), story.Version,
+			story.UpdatedAt.Format(time.RFC3339Nano)},
+			updateTitleKeyArgs("story", story.Project, story.Title),
+			[]any{story.Project, story.ID, expectedVersion})...,
 	)
 	if err != nil {
-		return core.Story{}, fmt.Errorf("update story: %w", err)
+		return core.Story{}, fmt.Errorf("update story: %w", titleConflict(err))
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
 		return core.Story{}, core.ErrConcurrentModification
 	}
+	// short_id and rank aren't written here, but callers needn't send
+	// them back.
+	_ = s.db.QueryRowContext(ctx, `SELECT short_id, rank FROM stories WHERE project = ? AND id = ?`, story.Project, story.ID).Scan(&story.ShortID, &story.Rank)
 	return story, nil
 }
 
-func (s *Store) DeleteStory(_ context.Context, project, id string) error {
-	_, err := s.db.Exec(`DELETE FROM stories WHERE project = ? AND id = ?`, project, id)
+func (s *Store) DeleteStory(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM stories WHERE project = ? AND id = ?`, project, id)
 	if err != nil {
 		return fmt.Errorf("delete story: %w", err)
 	}
@@ -321,11 +616,15 @@ func (s *Store) DeleteStory(_ context.Context, project, id string) error {
 
 // Task operations
 
-func (s *Store) CreateTask(_ context.Context, task core.Task) (core.Task, error) {
+// taskColumns are the tasks columns scanTask reads, in order.
+const taskColumns = `id, project, story_id, title, agent, session_id, status, priority, due_at, version, created_at, updated_at, short_id, capabilities_json, expected_paths_json,
+	blocked_reason, blocked_detail, unblock_json`
+
+func (s *Store) CreateTask(ctx context.Context, task core.Task) (core.Task, error) {
 	if task.ID == "" {
 		task.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	if task.CreatedAt.IsZero() {
 		task.CreatedAt = now
 	}
@@ -335,31 +634,55 @@ func (s *Store) CreateTask(_ context.Context, task core.Task) (core.Task, error)
 	if task.Status == "" {
 		task.Status = core.TaskStatusPending
 	}
+	if task.Priority == "" {
+		task.Priority = core.PriorityMedium
+	}
+	if task.Status != core.TaskStatusBlocked {
+		clearBlock(&task)
+	}
 	task.Version = 1
 
-	_, err := s.db.Exec(
-		`INSERT INTO tasks (id, project, story_id, title, agent, session_id, status, version, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		task.ID, task.Project, task.StoryID, task.Title, task.Agent, task.SessionID,
-		string(task.Status), task.Version, task.CreatedAt.Format(time.RFC3339Nano), task.UpdatedAt.Format(time.RFC3339Nano),
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Task{}, fmt.Errorf("begin create task: %w", err)
+	}
+	defer tx.Rollback()
+	if err := entityAbsentTx(ctx, tx, "tasks", task.Project, task.ID); err != nil {
+		return core.Task{}, err
+	}
+	if task.ShortID, err = nextShortIDTx(ctx, tx, task.Project, "task"); err != nil {
+		return core.Task{}, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO tasks (id, project, story_id, title, agent, session_id, status, priority, due_at, version, created_at, updated_at, short_id, capabilities_json, expected_paths_json,
+		   blocked_reason, blocked_detail, unblock_json, title_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+titleKeySQL+`)`,
+		append([]any{task.ID, task.Project, task.StoryID, task.Title, task.Agent, task.SessionID,
+			string(task.Status), string(task.Priority), nullableTime(task.DueAt), task.Version, task.CreatedAt.Format(time.RFC3339Nano), task.UpdatedAt.Format(time.RFC3339Nano), task.ShortID,
+			stringListJSON(task.Capabilities), stringListJSON(task.ExpectedPaths),
+			string(task.BlockedReason), task.BlockedDetail, unblockJSON(task.UnblockWhen)},
+			titleKeyArgs("task", task.Project, task.Title)...)...,
 	)
 	if err != nil {
-		return core.Task{}, fmt.Errorf("create task: %w", err)
+		return core.Task{}, fmt.Errorf("create task: %w", titleConflict(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Task{}, fmt.Errorf("commit create task: %w", err)
 	}
 	return task, nil
 }
 
-func (s *Store) GetTask(_ context.Context, project, id string) (core.Task, error) {
-	row := s.db.QueryRow(
-		`SELECT id, project, story_id, title, agent, session_id, status, version, created_at, updated_at
+func (s *Store) GetTask(ctx context.Context, project, id string) (core.Task, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+taskColumns+`
 		 FROM tasks WHERE project = ? AND id = ?`,
 		project, id,
 	)
 	return scanTask(row)
 }
 
-func (s *Store) ListTasks(_ context.Context, project, status, agent string) ([]core.Task, error) {
-	query := `SELECT id, project, story_id, title, agent, session_id, status, version, created_at, updated_at FROM tasks WHERE 1=1`
+func (s *Store) ListTasks(ctx context.Context, project, status, agent string) ([]core.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE 1=1`
 	var args []any
 	if project != "" {
 		query += " AND project = ?"
@@ -373,9 +696,9 @@ func (s *Store) ListTasks(_ context.Context, project, status, agent string) ([]c
 		query += " AND agent = ?"
 		args = append(args, agent)
 	}
-	query += " ORDER BY updated_at DESC"
+	query += " ORDER BY updated_ms DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list tasks: %w", err)
 	}
@@ -389,70 +712,134 @@ func (s *Store) ListTasks(_ context.Context, project, status, agent string) ([]c
 		}
 		tasks = append(tasks, task)
 	}
-	return tasks, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.fillCommentCounts(ctx, project, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
-func (s *Store) UpdateTask(_ context.Context, task core.Task) (core.Task, error) {
-	task.UpdatedAt = time.Now().UTC()
+func (s *Store) UpdateTask(ctx context.Context, task core.Task) (core.Task, error) {
+	if task.Priority == "" {
+		// Older clients don't send priority; keep the stored one.
+		var p string
+		if err := s.db.QueryRowContext(ctx, `SELECT priority FROM tasks WHERE project = ? AND id = ?`, task.Project, task.ID).Scan(&p); err == nil {
+			task.Priority = core.Priority(p)
+		}
+	}
+	if task.Status != core.TaskStatusBlocked {
+		clearBlock(&task)
+	} else if task.BlockedReason == "" {
+		// Older clients don't send the reason; keep the stored one.
+		var reason, detail, cond string
+		if err := s.db.QueryRowContext(ctx, `SELECT blocked_reason, blocked_detail, unblock_json FROM tasks WHERE project = ? AND id = ?`, task.Project, task.ID).Scan(&reason, &detail, &cond); err == nil {
+			task.BlockedReason = core.BlockedReason(reason)
+			task.BlockedDetail = detail
+			task.UnblockWhen = parseUnblock(task.ID, cond)
+		}
+	}
+	task.UpdatedAt = clock.Now().UTC()
 	expectedVersion := task.Version
 	task.Version++
-	res, err := s.db.Exec(
-		`UPDATE tasks SET story_id = ?, title = ?, agent = ?, session_id = ?, status = ?, version = ?, updated_at = ?
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE tasks SET story_id = ?, title = ?, agent = ?, session_id = ?, status = ?, priority = ?, due_at = ?, version = ?, updated_at = ?,
+		   capabilities_json = COALESCE(?, capabilities_json), expected_paths_json = COALESCE(?, expected_paths_json),
+		   blocked_reason = ?, blocked_detail = ?, unblock_json = ?,
+		   lease_id = CASE WHEN COALESCE(agent, '') = ? THEN lease_id ELSE '' END,
+		   `+updateTitleKeySQL+`
 		 WHERE project = ? AND id = ? AND version = ?`,
-		task.StoryID, task.Title, task.Agent, task.SessionID, string(task.Status), task.Version,
-		task.UpdatedAt.Format(time.RFC3339Nano), task.Project, task.ID, expectedVersion,
+		slices.Concat([]any{task.StoryID, task.Title, task.Agent, task.SessionID, string(task.Status), string(task.Priority), nullableTime(task.DueAt), task.Version,
+			task.UpdatedAt.Format(time.RFC3339Nano), nullableStringList(task.Capabilities), nullableStringList(task.ExpectedPaths),
+			string(task.BlockedReason), task.BlockedDetail, unblockJSON(task.UnblockWhen), task.Agent},
+			updateTitleKeyArgs("task", task.Project, task.Title),
+			[]any{task.Project, task.ID, expectedVersion})...,
 	)
 	if err != nil {
-		return core.Task{}, fmt.Errorf("update task: %w", err)
+		return core.Task{}, fmt.Errorf("update task: %w", titleConflict(err))
 	}
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
 		return core.Task{}, core.ErrConcurrentModification
 	}
+	// short_id never changes, and a nil Capabilities or ExpectedPaths kept
+	// the stored ones (older clients don't send them); either way, return
+	// what's stored.
+	var capsJSON, pathsJSON string
+	if err := s.db.QueryRowContext(ctx, `SELECT short_id, capabilities_json, expected_paths_json FROM tasks WHERE project = ? AND id = ?`, task.Project, task.ID).Scan(&task.ShortID, &capsJSON, &pathsJSON); err == nil {
+		task.Capabilities = parseStringList(task.ID, "capabilities_json", capsJSON)
+		task.ExpectedPaths = parseStringList(task.ID, "expected_paths_json", pathsJSON)
+	}
 	return task, nil
 }
 
-func (s *Store) DeleteTask(_ context.Context, project, id string) error {
-	_, err := s.db.Exec(`DELETE FROM tasks WHERE project = ? AND id = ?`, project, id)
+func (s *Store) DeleteTask(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE project = ? AND id = ?`, project, id)
 	if err != nil {
 		return fmt.Errorf("delete task: %w", err)
 	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_comments WHERE project = ? AND task_id = ?`, project, id); err != nil {
+		return fmt.Errorf("delete task comments: %w", err)
+	}
 	return nil
 }
 
 // Insight operations
 
-func (s *Store) CreateInsight(_ context.Context, insight core.Insight) (core.Insight, error) {
+func (s *Store) CreateInsight(ctx context.Context, insight core.Insight) (core.Insight, error) {
 	if insight.ID == "" {
 		insight.ID = uuid.NewString()
 	}
 	if insight.CreatedAt.IsZero() {
-		insight.CreatedAt = time.Now().UTC()
+		insight.CreatedAt = clock.Now().UTC()
 	}
+	if insight.Status == "" {
+		insight.Status = core.InsightStatusNew
+	}
+	insight.UpdatedAt = insight.CreatedAt
+	insight.Version = 1
+	insight.ContentHash = core.InsightContentHash(insight.Source, insight.Title)
 
-	_, err := s.db.Exec(
-		`INSERT INTO insights (id, project, spec_id, source, category, title, body, url, score, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO insights (id, project, spec_id, source, category, title, body, url, score, status, version, content_hash, created_at, updated_at, dedup_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN `+flagEnabledSQL+` THEN ? END)
+		 ON CONFLICT (project, id) DO NOTHING`,
 		insight.ID, insight.Project, insight.SpecID, insight.Source, insight.Category,
-		insight.Title, insight.Body, insight.URL, insight.Score, insight.CreatedAt.Format(time.RFC3339Nano),
+		insight.Title, insight.Body, insight.URL, insight.Score, string(insight.Status), insight.Version, insight.ContentHash,
+		insight.CreatedAt.Format(time.RFC3339Nano), insight.UpdatedAt.Format(time.RFC3339Nano),
+		core.FlagDedupInsights, core.FlagDedupInsights, insight.Project, insight.Project, insight.ContentHash,
 	)
-	if err != nil {
+	// dedup_key is the content hash while the project deduplicates
+	// insights, and its unique index turns away a copy of an existing one.
+	if err != nil && strings.Contains(err.Error(), "dedup_key") {
+		return core.Insight{}, core.ErrDuplicateInsight
+	}
+	if err := insertedOrExists(res, err); err != nil {
 		return core.Insight{}, fmt.Errorf("create insight: %w", err)
 	}
 	return insight, nil
 }
 
-func (s *Store) GetInsight(_ context.Context, project, id string) (core.Insight, error) {
-	row := s.db.QueryRow(
-		`SELECT id, project, spec_id, source, category, title, body, url, score, created_at
-		 FROM insights WHERE project = ? AND id = ?`,
+// GetInsight returns the insight, or for an ID merged away by
+// MergeInsights, the insight it was merged into.
+func (s *Store) GetInsight(ctx context.Context, project, id string) (core.Insight, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+insightColumns+` FROM insights WHERE project = ? AND id = ?`,
 		project, id,
 	)
-	return scanInsight(row)
+	insight, err := scanInsight(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		var into string
+		if s.db.QueryRowContext(ctx, `SELECT into_id FROM insight_merges WHERE project = ? AND merged_id = ?`, project, id).Scan(&into) == nil {
+			return scanInsight(s.db.QueryRowContext(ctx, `SELECT `+insightColumns+` FROM insights WHERE project = ? AND id = ?`, project, into))
+		}
+	}
+	return insight, err
 }
 
-func (s *Store) ListInsights(_ context.Context, project, specID, category string) ([]core.Insight, error) {
-	query := `SELECT id, project, spec_id, source, category, title, body, url, score, created_at FROM insights WHERE 1=1`
+func (s *Store) ListInsights(ctx context.Context, project, specID, category string) ([]core.Insight, error) {
+	query := `SELECT ` + insightColumns + ` FROM insights WHERE 1=1`
 	var args []any
 	if project != "" {
 		query += " AND project = ?"
@@ -468,7 +855,7 @@ func (s *Store) ListInsights(_ context.Context, project, specID, category string
 	}
 	query += " ORDER BY score DESC, created_at DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list insights: %w", err)
 	}
@@ -485,10 +872,47 @@ func (s *Store) ListInsights(_ context.Context, project, specID, category string
 	return insights, rows.Err()
 }
 
-func (s *Store) LinkInsightToSpec(_ context.Context, project, insightID, specID string) error {
-	_, err := s.db.Exec(
-		`UPDATE insights SET spec_id = ? WHERE project = ? AND id = ?`,
-		specID, project, insightID,
+func (s *Store) UpdateInsight(ctx context.Context, insight core.Insight) (core.Insight, error) {
+	if insight.Status == "" {
+		// Older clients don't send status; keep the stored one.
+		var st string
+		if err := s.db.QueryRowContext(ctx, `SELECT status FROM insights WHERE project = ? AND id = ?`, insight.Project, insight.ID).Scan(&st); err == nil {
+			insight.Status = core.InsightStatus(st)
+		}
+	}
+	insight.UpdatedAt = clock.Now().UTC()
+	insight.ContentHash = core.InsightContentHash(insight.Source, insight.Title)
+	expectedVersion := insight.Version
+	insight.Version++
+	// An edit that changes the content drops the dedup key: the insight no
+	// longer stands for what it was created as.
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE insights SET spec_id = ?, source = ?, category = ?, title = ?, body = ?, url = ?, score = ?, status = ?, version = ?, content_hash = ?, updated_at = ?,
+		   dedup_key = CASE WHEN content_hash = ? THEN dedup_key END
+		 WHERE project = ? AND id = ? AND version = ?`,
+		insight.SpecID, insight.Source, insight.Category, insight.Title, insight.Body, insight.URL, insight.Score,
+		string(insight.Status), insight.Version, insight.ContentHash, insight.UpdatedAt.Format(time.RFC3339Nano), insight.ContentHash,
+		insight.Project, insight.ID, expectedVersion,
+	)
+	if err != nil {
+		return core.Insight{}, fmt.Errorf("update insight: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return core.Insight{}, core.ErrConcurrentModification
+	}
+	// created_at isn't part of the update; report the stored one.
+	var createdAt string
+	if err := s.db.QueryRowContext(ctx, `SELECT created_at FROM insights WHERE project = ? AND id = ?`, insight.Project, insight.ID).Scan(&createdAt); err == nil {
+		insight.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	}
+	return insight, nil
+}
+
+func (s *Store) LinkInsightToSpec(ctx context.Context, project, insightID, specID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE insights SET spec_id = ?, version = version + 1, updated_at = ? WHERE project = ? AND id = ?`,
+		specID, clock.Now().UTC().Format(time.RFC3339Nano), project, insightID,
 	)
 	if err != nil {
 		return fmt.Errorf("link insight: %w", err)
@@ -496,21 +920,102 @@ func (s *Store) LinkInsightToSpec(_ context.Context, project, insightID, specID
 	return nil
 }
 
-func (s *Store) DeleteInsight(_ context.Context, project, id string) error {
-	_, err := s.db.Exec(`DELETE FROM insights WHERE project = ? AND id = ?`, project, id)
+func (s *Store) DeleteInsight(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM insights WHERE project = ? AND id = ?`, project, id)
 	if err != nil {
 		return fmt.Errorf("delete insight: %w", err)
 	}
 	return nil
 }
 
+// Insight routing rule operations
+
+func (s *Store) CreateInsightRule(ctx context.Context, rule core.InsightRule) (core.InsightRule, error) {
+	if rule.ID == "" {
+		rule.ID = uuid.NewString()
+	}
+	now := clock.Now().UTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO insight_rules (id, project, name, category, source, min_score, spec_id, notify_agent, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Project, rule.Name, rule.Category, rule.Source, nullableFloat(rule.MinScore),
+		rule.SpecID, rule.NotifyAgent, boolToInt(rule.Enabled), now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.InsightRule{}, fmt.Errorf("create insight rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *Store) GetInsightRule(ctx context.Context, project, id string) (core.InsightRule, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, project, name, category, source, min_score, spec_id, notify_agent, enabled, created_at, updated_at
+		 FROM insight_rules WHERE project = ? AND id = ?`,
+		project, id,
+	)
+	return scanInsightRule(row)
+}
+
+// ListInsightRules returns a project's rules in creation order, which is
+// the order they are evaluated in.
+func (s *Store) ListInsightRules(ctx context.Context, project string) ([]core.InsightRule, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, project, name, category, source, min_score, spec_id, notify_agent, enabled, created_at, updated_at
+		 FROM insight_rules WHERE project = ? ORDER BY created_at, id`,
+		project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list insight rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []core.InsightRule
+	for rows.Next() {
+		rule, err := scanInsightRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (s *Store) UpdateInsightRule(ctx context.Context, rule core.InsightRule) (core.InsightRule, error) {
+	existing, err := s.GetInsightRule(ctx, rule.Project, rule.ID)
+	if err != nil {
+		return core.InsightRule{}, err
+	}
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = clock.Now().UTC()
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE insight_rules SET name = ?, category = ?, source = ?, min_score = ?, spec_id = ?, notify_agent = ?, enabled = ?, updated_at = ?
+		 WHERE project = ? AND id = ?`,
+		rule.Name, rule.Category, rule.Source, nullableFloat(rule.MinScore), rule.SpecID, rule.NotifyAgent,
+		boolToInt(rule.Enabled), rule.UpdatedAt.Format(time.RFC3339Nano), rule.Project, rule.ID,
+	)
+	if err != nil {
+		return core.InsightRule{}, fmt.Errorf("update insight rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *Store) DeleteInsightRule(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM insight_rules WHERE project = ? AND id = ?`, project, id)
+	if err != nil {
+		return fmt.Errorf("delete insight rule: %w", err)
+	}
+	return nil
+}
+
 // Session operations
 
-func (s *Store) CreateSession(_ context.Context, session core.Session) (core.Session, error) {
+func (s *Store) CreateSession(ctx context.Context, session core.Session) (core.Session, error) {
 	if session.ID == "" {
 		session.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	if session.StartedAt.IsZero() {
 		session.StartedAt = now
 	}
@@ -520,12 +1025,13 @@ func (s *Store) CreateSession(_ context.Context, session core.Session) (core.Ses
 	if session.Status == "" {
 		session.Status = core.SessionStatusRunning
 	}
+	session.Version = 1
 
-	_, err := s.db.Exec(
-		`INSERT INTO sessions (id, project, name, agent, task_id, status, started_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (id, project, name, agent, task_id, status, version, started_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		session.ID, session.Project, session.Name, session.Agent, session.TaskID,
-		string(session.Status), session.StartedAt.Format(time.RFC3339Nano), session.UpdatedAt.Format(time.RFC3339Nano),
+		string(session.Status), session.Version, session.StartedAt.Format(time.RFC3339Nano), session.UpdatedAt.Format(time.RFC3339Nano),
 	)
 	if err != nil {
 		return core.Session{}, fmt.Errorf("create session: %w", err)
@@ -533,17 +1039,17 @@ func (s *Store) CreateSession(_ context.Context, session core.Session) (core.Ses
 	return session, nil
 }
 
-func (s *Store) GetSession(_ context.Context, project, id string) (core.Session, error) {
-	row := s.db.QueryRow(
-		`SELECT id, project, name, agent, task_id, status, started_at, updated_at
+func (s *Store) GetSession(ctx context.Context, project, id string) (core.Session, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+sessionColumns+`
 		 FROM sessions WHERE project = ? AND id = ?`,
 		project, id,
 	)
 	return scanSession(row)
 }
 
-func (s *Store) ListSessions(_ context.Context, project, status string) ([]core.Session, error) {
-	query := `SELECT id, project, name, agent, task_id, status, started_at, updated_at FROM sessions WHERE 1=1`
+func (s *Store) ListSessions(ctx context.Context, project, status string) ([]core.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE 1=1`
 	var args []any
 	if project != "" {
 		query += " AND project = ?"
@@ -555,7 +1061,7 @@ func (s *Store) ListSessions(_ context.Context, project, status string) ([]core.
 	}
 	query += " ORDER BY started_at DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list sessions: %w", err)
 	}
@@ -572,22 +1078,28 @@ func (s *Store) ListSessions(_ context.Context, project, status string) ([]core.
 	return sessions, rows.Err()
 }
 
-func (s *Store) UpdateSession(_ context.Context, session core.Session) (core.Session, error) {
-	session.UpdatedAt = time.Now().UTC()
-	_, err := s.db.Exec(
-		`UPDATE sessions SET name = ?, agent = ?, task_id = ?, status = ?, updated_at = ?
-		 WHERE project = ? AND id = ?`,
-		session.Name, session.Agent, session.TaskID, string(session.Status),
-		session.UpdatedAt.Format(time.RFC3339Nano), session.Project, session.ID,
+func (s *Store) UpdateSession(ctx context.Context, session core.Session) (core.Session, error) {
+	session.UpdatedAt = clock.Now().UTC()
+	expectedVersion := session.Version
+	session.Version++
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET name = ?, agent = ?, task_id = ?, status = ?, version = ?, updated_at = ?
+		 WHERE project = ? AND id = ? AND version = ?`,
+		session.Name, session.Agent, session.TaskID, string(session.Status), session.Version,
+		session.UpdatedAt.Format(time.RFC3339Nano), session.Project, session.ID, expectedVersion,
 	)
 	if err != nil {
 		return core.Session{}, fmt.Errorf("update session: %w", err)
 	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return core.Session{}, core.ErrConcurrentModification
+	}
 	return session, nil
 }
 
-func (s *Store) DeleteSession(_ context.Context, project, id string) error {
-	_, err := s.db.Exec(`DELETE FROM sessions WHERE project = ? AND id = ?`, project, id)
+func (s *Store) DeleteSession(ctx context.Context, project, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE project = ? AND id = ?`, project, id)
 	if err != nil {
 		return fmt.Errorf("delete session: %w", err)
 	}
@@ -600,15 +1112,36 @@ type scanner interface {
 	Scan(dest ...any) error
 }
 
+// nullableTime formats an optional timestamp for a nullable TEXT column.
+func nullableTime(t *time.Time) sql.NullString {
+	if t == nil || t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(time.RFC3339Nano), Valid: true}
+}
+
+// parseNullableTime is the inverse of nullableTime.
+func parseNullableTime(ns sql.NullString) *time.Time {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, ns.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
 func scanSpec(row scanner) (core.Spec, error) {
 	var s core.Spec
-	var vision, users, problem sql.NullString
+	var vision, users, problem, reviewedAt sql.NullString
 	var createdAt, updatedAt, status string
 	var version int64
-	err := row.Scan(&s.ID, &s.Project, &s.Title, &vision, &users, &problem, &status, &version, &createdAt, &updatedAt)
+	err := row.Scan(&s.ID, &s.Project, &s.Title, &vision, &users, &problem, &status, &version, &createdAt, &updatedAt, &s.ShortID, &reviewedAt)
 	if err != nil {
 		return core.Spec{}, fmt.Errorf("scan spec: %w", err)
 	}
+	s.LastReviewedAt = parseNullableTime(reviewedAt)
 	s.Vision = vision.String
 	s.Users = users.String
 	s.Problem = problem.String
@@ -628,7 +1161,7 @@ func scanEpic(row scanner) (core.Epic, error) {
 	var specID, description sql.NullString
 	var createdAt, updatedAt, status string
 	var version int64
-	err := row.Scan(&e.ID, &e.Project, &specID, &e.Title, &description, &status, &version, &createdAt, &updatedAt)
+	err := row.Scan(&e.ID, &e.Project, &specID, &e.Title, &description, &status, &version, &createdAt, &updatedAt, &e.ShortID)
 	if err != nil {
 		return core.Epic{}, fmt.Errorf("scan epic: %w", err)
 	}
@@ -648,9 +1181,9 @@ func scanEpicRow(rows *sql.Rows) (core.Epic, error) {
 func scanStory(row scanner) (core.Story, error) {
 	var s core.Story
 	var acJSON sql.NullString
-	var createdAt, updatedAt, status string
+	var createdAt, updatedAt, status, priority string
 	var version int64
-	err := row.Scan(&s.ID, &s.Project, &s.EpicID, &s.Title, &acJSON, &status, &version, &createdAt, &updatedAt)
+	err := row.Scan(&s.ID, &s.Project, &s.EpicID, &s.Title, &acJSON, &status, &priority, &version, &createdAt, &updatedAt, &s.ShortID, &s.Rank)
 	if err != nil {
 		return core.Story{}, fmt.Errorf("scan story: %w", err)
 	}
@@ -660,6 +1193,7 @@ func scanStory(row scanner) (core.Story, error) {
 		}
 	}
 	s.Status = core.StoryStatus(status)
+	s.Priority = core.Priority(priority)
 	s.Version = version
 	s.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
 	s.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
@@ -672,10 +1206,11 @@ func scanStoryRow(rows *sql.Rows) (core.Story, error) {
 
 func scanTask(row scanner) (core.Task, error) {
 	var t core.Task
-	var storyID, agent, sessionID sql.NullString
-	var createdAt, updatedAt, status string
+	var storyID, agent, sessionID, dueAt sql.NullString
+	var createdAt, updatedAt, status, priority, capsJSON, pathsJSON, blockedReason, unblock string
 	var version int64
-	err := row.Scan(&t.ID, &t.Project, &storyID, &t.Title, &agent, &sessionID, &status, &version, &createdAt, &updatedAt)
+	err := row.Scan(&t.ID, &t.Project, &storyID, &t.Title, &agent, &sessionID, &status, &priority, &dueAt, &version, &createdAt, &updatedAt, &t.ShortID, &capsJSON, &pathsJSON,
+		&blockedReason, &t.BlockedDetail, &unblock)
 	if err != nil {
 		return core.Task{}, fmt.Errorf("scan task: %w", err)
 	}
@@ -683,6 +1218,12 @@ func scanTask(row scanner) (core.Task, error) {
 	t.Agent = agent.String
 	t.SessionID = sessionID.String
 	t.Status = core.TaskStatus(status)
+	t.Priority = core.Priority(priority)
+	t.DueAt = parseNullableTime(dueAt)
+	t.Capabilities = parseStringList(t.ID, "capabilities_json", capsJSON)
+	t.ExpectedPaths = parseStringList(t.ID, "expected_paths_json", pathsJSON)
+	t.BlockedReason = core.BlockedReason(blockedReason)
+	t.UnblockWhen = parseUnblock(t.ID, unblock)
 	t.Version = version
 	t.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
 	t.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
@@ -693,18 +1234,23 @@ func scanTaskRow(rows *sql.Rows) (core.Task, error) {
 	return scanTask(rows)
 }
 
+// insightColumns are the columns scanInsight reads, in order.
+const insightColumns = `id, project, spec_id, source, category, title, body, url, score, status, version, content_hash, created_at, updated_at`
+
 func scanInsight(row scanner) (core.Insight, error) {
 	var i core.Insight
 	var specID, body, url sql.NullString
-	var createdAt string
-	err := row.Scan(&i.ID, &i.Project, &specID, &i.Source, &i.Category, &i.Title, &body, &url, &i.Score, &createdAt)
+	var createdAt, updatedAt, status string
+	err := row.Scan(&i.ID, &i.Project, &specID, &i.Source, &i.Category, &i.Title, &body, &url, &i.Score, &status, &i.Version, &i.ContentHash, &createdAt, &updatedAt)
 	if err != nil {
 		return core.Insight{}, fmt.Errorf("scan insight: %w", err)
 	}
 	i.SpecID = specID.String
 	i.Body = body.String
 	i.URL = url.String
+	i.Status = core.InsightStatus(status)
 	i.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	i.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
 	return i, nil
 }
 
@@ -712,11 +1258,14 @@ func scanInsightRow(rows *sql.Rows) (core.Insight, error) {
 	return scanInsight(rows)
 }
 
+// sessionColumns is the column list scanSession reads.
+const sessionColumns = `id, project, name, agent, task_id, status, version, started_at, updated_at, heartbeat_at`
+
 func scanSession(row scanner) (core.Session, error) {
 	var s core.Session
-	var taskID sql.NullString
+	var taskID, heartbeatAt sql.NullString
 	var startedAt, updatedAt, status string
-	err := row.Scan(&s.ID, &s.Project, &s.Name, &s.Agent, &taskID, &status, &startedAt, &updatedAt)
+	err := row.Scan(&s.ID, &s.Project, &s.Name, &s.Agent, &taskID, &status, &s.Version, &startedAt, &updatedAt, &heartbeatAt)
 	if err != nil {
 		return core.Session{}, fmt.Errorf("scan session: %w", err)
 	}
@@ -724,6 +1273,10 @@ func scanSession(row scanner) (core.Session, error) {
 	s.Status = core.SessionStatus(status)
 	s.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
 	s.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	if heartbeatAt.Valid {
+		t, _ := time.Parse(time.RFC3339Nano, heartbeatAt.String)
+		s.HeartbeatAt = &t
+	}
 	return s, nil
 }
 
@@ -733,11 +1286,11 @@ func scanSessionRow(rows *sql.Rows) (core.Session, error) {
 
 // CUJ (Critical User Journey) operations
 
-func (s *Store) CreateCUJ(_ context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
+func (s *Store) CreateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
 	if cuj.ID == "" {
 		cuj.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	if cuj.CreatedAt.IsZero() {
 		cuj.CreatedAt = now
 	}
@@ -767,21 +1320,23 @@ func (s *Store) CreateCUJ(_ context.Context, cuj core.CriticalUserJourney) (core
 		return core.CriticalUserJourney{}, fmt.Errorf("marshal error_recovery: %w", err)
 	}
 
-	if _, err := s.db.Exec(
+	res, err := s.db.ExecContext(ctx,
 		`INSERT INTO cujs (id, project, spec_id, title, persona, priority, entry_point, exit_point,
 		 steps_json, success_criteria_json, error_recovery_json, status, version, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (project, id) DO NOTHING`,
 		cuj.ID, cuj.Project, cuj.SpecID, cuj.Title, cuj.Persona, string(cuj.Priority),
 		cuj.EntryPoint, cuj.ExitPoint, string(stepsJSON), string(successJSON), string(errorJSON),
 		string(cuj.Status), cuj.Version, cuj.CreatedAt.Format(time.RFC3339Nano), cuj.UpdatedAt.Format(time.RFC3339Nano),
-	); err != nil {
+	)
+	if err := insertedOrExists(res, err); err != nil {
 		return core.CriticalUserJourney{}, fmt.Errorf("create cuj: %w", err)
 	}
 	return cuj, nil
 }
 
-func (s *Store) GetCUJ(_ context.Context, project, id string) (core.CriticalUserJourney, error) {
-	row := s.db.QueryRow(
+func (s *Store) GetCUJ(ctx context.Context, project, id string) (core.CriticalUserJourney, error) {
+	row := s.db.QueryRowContext(ctx,
 		`SELECT id, project, spec_id, title, persona, priority, entry_point, exit_point,
 		 steps_json, success_criteria_json, error_recovery_json, status, version, created_at, updated_at
 		 FROM cujs WHERE project = ? AND id = ?`,
@@ -790,7 +1345,7 @@ func (s *Store) GetCUJ(_ context.Context, project, id string) (core.CriticalUser
 	return scanCUJ(row)
 }
 
-func (s *Store) ListCUJs(_ context.Context, project, specID string) ([]core.CriticalUserJourney, error) {
+func (s *Store) ListCUJs(ctx context.Context, project, specID string) ([]core.CriticalUserJourney, error) {
 	query := `SELECT id, project, spec_id, title, persona, priority, entry_point, exit_point,
 		steps_json, success_criteria_json, error_recovery_json, status, version, created_at, updated_at
 		FROM cujs`
@@ -806,9 +1361,9 @@ func (s *Store) ListCUJs(_ context.Context, project, specID string) ([]core.Crit
 		query += " WHERE spec_id = ?"
 		args = append(args, specID)
 	}
-	query += " ORDER BY priority ASC, updated_at DESC"
+	query += " ORDER BY priority ASC, updated_ms DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list cujs: %w", err)
 	}
@@ -825,8 +1380,8 @@ func (s *Store) ListCUJs(_ context.Context, project, specID string) ([]core.Crit
 	return cujs, rows.Err()
 }
 
-func (s *Store) UpdateCUJ(_ context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
-	cuj.UpdatedAt = time.Now().UTC()
+func (s *Store) UpdateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
+	cuj.UpdatedAt = clock.Now().UTC()
 	expectedVersion := cuj.Version
 	cuj.Version++
 
@@ -843,7 +1398,7 @@ func (s *Store) UpdateCUJ(_ context.Context, cuj core.CriticalUserJourney) (core
 		return core.CriticalUserJourney{}, fmt.Errorf("marshal error_recovery: %w", err)
 	}
 
-	res, err := s.db.Exec(
+	res, err := s.db.ExecContext(ctx,
 		`UPDATE cujs SET spec_id = ?, title = ?, persona = ?, priority = ?, entry_point = ?, exit_point = ?,
 		 steps_json = ?, success_criteria_json = ?, error_recovery_json = ?, status = ?, version = ?, updated_at = ?
 		 WHERE project = ? AND id = ? AND version = ?`,
@@ -861,27 +1416,27 @@ func (s *Store) UpdateCUJ(_ context.Context, cuj core.CriticalUserJourney) (core
 	return cuj, nil
 }
 
-func (s *Store) DeleteCUJ(_ context.Context, project, id string) error {
-	tx, err := s.db.Begin()
+func (s *Store) DeleteCUJ(ctx context.Context, project, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin delete cuj: %w", err)
 	}
 	defer tx.Rollback()
 
 	// Delete feature links first
-	if _, err := tx.Exec(`DELETE FROM cuj_feature_links WHERE project = ? AND cuj_id = ?`, project, id); err != nil {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cuj_feature_links WHERE project = ? AND cuj_id = ?`, project, id); err != nil {
 		return fmt.Errorf("delete cuj links: %w", err)
 	}
 	// Delete CUJ
-	if _, err := tx.Exec(`DELETE FROM cujs WHERE project = ? AND id = ?`, project, id); err != nil {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cujs WHERE project = ? AND id = ?`, project, id); err != nil {
 		return fmt.Errorf("delete cuj: %w", err)
 	}
 	return tx.Commit()
 }
 
-func (s *Store) LinkCUJToFeature(_ context.Context, project, cujID, featureID string) error {
-	now := time.Now().UTC()
-	_, err := s.db.Exec(
+func (s *Store) LinkCUJToFeature(ctx context.Context, project, cujID, featureID string) error {
+	now := clock.Now().UTC()
+	_, err := s.db.ExecContext(ctx,
 		`INSERT INTO cuj_feature_links (project, cuj_id, feature_id, linked_at)
 		 VALUES (?, ?, ?, ?)
 		 ON CONFLICT(project, cuj_id, feature_id) DO UPDATE SET linked_at = excluded.linked_at`,
@@ -893,8 +1448,8 @@ func (s *Store) LinkCUJToFeature(_ context.Context, project, cujID, featureID st
 	return nil
 }
 
-func (s *Store) UnlinkCUJFromFeature(_ context.Context, project, cujID, featureID string) error {
-	_, err := s.db.Exec(
+func (s *Store) UnlinkCUJFromFeature(ctx context.Context, project, cujID, featureID string) error {
+	_, err := s.db.ExecContext(ctx,
 		`DELETE FROM cuj_feature_links WHERE project = ? AND cuj_id = ? AND feature_id = ?`,
 		project, cujID, featureID,
 	)
@@ -904,8 +1459,8 @@ func (s *Store) UnlinkCUJFromFeature(_ context.Context, project, cujID, featureI
 	return nil
 }
 
-func (s *Store) GetCUJFeatureLinks(_ context.Context, project, cujID string) ([]core.CUJFeatureLink, error) {
-	rows, err := s.db.Query(
+func (s *Store) GetCUJFeatureLinks(ctx context.Context, project, cujID string) ([]core.CUJFeatureLink, error) {
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT project, cuj_id, feature_id, linked_at FROM cuj_feature_links WHERE project = ? AND cuj_id = ?`,
 		project, cujID,
 	)
@@ -976,3 +1531,261 @@ func scanCUJ(row scanner) (core.CriticalUserJourney, error) {
 func scanCUJRow(rows *sql.Rows) (core.CriticalUserJourney, error) {
 	return scanCUJ(rows)
 }
+
+// Goal operations
+
+func (s *Store) CreateGoal(ctx context.Context, goal core.Goal) (core.Goal, error) {
+	if goal.ID == "" {
+		goal.ID = uuid.NewString()
+	}
+	now := clock.Now().UTC()
+	if goal.CreatedAt.IsZero() {
+		goal.CreatedAt = now
+	}
+	if goal.UpdatedAt.IsZero() {
+		goal.UpdatedAt = now
+	}
+	if goal.Status == "" {
+		goal.Status = core.GoalStatusActive
+	}
+	goal.Version = 1
+
+	krJSON, err := json.Marshal(goal.KeyResults)
+	if err != nil {
+		return core.Goal{}, fmt.Errorf("marshal key_results: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO goals (id, project, title, description, period, key_results_json, status, version, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (project, id) DO NOTHING`,
+		goal.ID, goal.Project, goal.Title, goal.Description, goal.Period, string(krJSON),
+		string(goal.Status), goal.Version, goal.CreatedAt.Format(time.RFC3339Nano), goal.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	if err := insertedOrExists(res, err); err != nil {
+		return core.Goal{}, fmt.Errorf("create goal: %w", err)
+	}
+	return goal, nil
+}
+
+func (s *Store) GetGoal(ctx context.Context, project, id string) (core.Goal, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, project, title, description, period, key_results_json, status, version, created_at, updated_at
+		 FROM goals WHERE project = ? AND id = ?`,
+		project, id,
+	)
+	return scanGoal(row)
+}
+
+func (s *Store) ListGoals(ctx context.Context, project, status string) ([]core.Goal, error) {
+	query := `SELECT id, project, title, description, period, key_results_json, status, version, created_at, updated_at FROM goals WHERE 1=1`
+	var args []any
+	if project != "" {
+		query += " AND project = ?"
+		args = append(args, project)
+	}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY updated_ms DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []core.Goal
+	for rows.Next() {
+		goal, err := scanGoalRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+	return goals, rows.Err()
+}
+
+func (s *Store) UpdateGoal(ctx context.Context, goal core.Goal) (core.Goal, error) {
+	goal.UpdatedAt = clock.Now().UTC()
+	expectedVersion := goal.Version
+	goal.Version++
+	krJSON, err := json.Marshal(goal.KeyResults)
+	if err != nil {
+		return core.Goal{}, fmt.Errorf("marshal key_results: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE goals SET title = ?, description = ?, period = ?, key_results_json = ?, status = ?, version = ?, updated_at = ?
+		 WHERE project = ? AND id = ? AND version = ?`,
+		goal.Title, goal.Description, goal.Period, string(krJSON), string(goal.Status), goal.Version,
+		goal.UpdatedAt.Format(time.RFC3339Nano), goal.Project, goal.ID, expectedVersion,
+	)
+	if err != nil {
+		return core.Goal{}, fmt.Errorf("update goal: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return core.Goal{}, core.ErrConcurrentModification
+	}
+	return goal, nil
+}
+
+func (s *Store) DeleteGoal(ctx context.Context, project, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin delete goal: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM goal_links WHERE project = ? AND goal_id = ?`, project, id); err != nil {
+		return fmt.Errorf("delete goal links: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM goals WHERE project = ? AND id = ?`, project, id); err != nil {
+		return fmt.Errorf("delete goal: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *Store) LinkGoal(ctx context.Context, project, goalID, entityType, entityID string) error {
+	now := clock.Now().UTC()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO goal_links (project, goal_id, entity_type, entity_id, linked_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(project, goal_id, entity_type, entity_id) DO UPDATE SET linked_at = excluded.linked_at`,
+		project, goalID, entityType, entityID, now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("link goal: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) UnlinkGoal(ctx context.Context, project, goalID, entityType, entityID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM goal_links WHERE project = ? AND goal_id = ? AND entity_type = ? AND entity_id = ?`,
+		project, goalID, entityType, entityID,
+	)
+	if err != nil {
+		return fmt.Errorf("unlink goal: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetGoalLinks(ctx context.Context, project, goalID string) ([]core.GoalLink, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT project, goal_id, entity_type, entity_id, linked_at FROM goal_links
+		 WHERE project = ? AND goal_id = ? ORDER BY linked_at ASC`,
+		project, goalID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get goal links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []core.GoalLink
+	for rows.Next() {
+		var l core.GoalLink
+		var linkedAt string
+		if err := rows.Scan(&l.Project, &l.GoalID, &l.EntityType, &l.EntityID, &linkedAt); err != nil {
+			return nil, fmt.Errorf("scan goal link: %w", err)
+		}
+		l.LinkedAt, _ = time.Parse(time.RFC3339Nano, linkedAt)
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+func scanGoal(row scanner) (core.Goal, error) {
+	var g core.Goal
+	var description, period, krJSON sql.NullString
+	var createdAt, updatedAt, status string
+	var version int64
+	err := row.Scan(&g.ID, &g.Project, &g.Title, &description, &period, &krJSON, &status, &version, &createdAt, &updatedAt)
+	if err != nil {
+		return core.Goal{}, fmt.Errorf("scan goal: %w", err)
+	}
+	g.Description = description.String
+	g.Period = period.String
+	if krJSON.Valid {
+		if err := json.Unmarshal([]byte(krJSON.String), &g.KeyResults); err != nil {
+			log.Printf("WARN: corrupt key_results_json for goal %s: %v", g.ID, err)
+		}
+	}
+	g.Status = core.GoalStatus(status)
+	g.Version = version
+	g.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	g.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	return g, nil
+}
+
+func scanGoalRow(rows *sql.Rows) (core.Goal, error) {
+	return scanGoal(rows)
+}
+
+func scanInsightRule(row scanner) (core.InsightRule, error) {
+	var r core.InsightRule
+	var minScore sql.NullFloat64
+	var enabled int
+	var createdAt, updatedAt string
+	err := row.Scan(&r.ID, &r.Project, &r.Name, &r.Category, &r.Source, &minScore,
+		&r.SpecID, &r.NotifyAgent, &enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return core.InsightRule{}, fmt.Errorf("scan insight rule: %w", err)
+	}
+	if minScore.Valid {
+		v := minScore.Float64
+		r.MinScore = &v
+	}
+	r.Enabled = enabled != 0
+	r.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	r.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	return r, nil
+}
+
+func nullableFloat(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// stringListJSON encodes a task's string list (capabilities, expected
+// paths) for storage.
+func stringListJSON(list []string) string {
+	if len(list) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(list)
+	return string(data)
+}
+
+// nullableStringList is stringListJSON for updates, where nil means "keep
+// the stored list".
+func nullableStringList(list []string) any {
+	if list == nil {
+		return nil
+	}
+	return stringListJSON(list)
+}
+
+func parseStringList(taskID, column, raw string) []string {
+	var list []string
+	if raw == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		log.Printf("WARN: corrupt %s for task %s: %v", column, taskID, err)
+		return nil
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
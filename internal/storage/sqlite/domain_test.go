@@ -321,6 +321,85 @@ func TestSessionCRUD(t *testing.T) {
 	}
 }
 
+func TestGoalCRUDAndLinks(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := store.CreateGoal(ctx, core.Goal{
+		Project: "test-project",
+		Title:   "Grow adoption",
+		Period:  "2026-Q3",
+		KeyResults: []core.KeyResult{
+			{Description: "Weekly active projects", Target: 50, Current: 10},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateGoal: %v", err)
+	}
+	if created.Status != core.GoalStatusActive {
+		t.Errorf("status = %v, want %v", created.Status, core.GoalStatusActive)
+	}
+
+	fetched, err := store.GetGoal(ctx, "test-project", created.ID)
+	if err != nil {
+		t.Fatalf("GetGoal: %v", err)
+	}
+	if len(fetched.KeyResults) != 1 || fetched.KeyResults[0].Target != 50 {
+		t.Errorf("key_results = %+v, want one KR with target 50", fetched.KeyResults)
+	}
+
+	// Update with optimistic locking
+	fetched.Status = core.GoalStatusAchieved
+	updated, err := store.UpdateGoal(ctx, fetched)
+	if err != nil {
+		t.Fatalf("UpdateGoal: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("version = %d, want 2", updated.Version)
+	}
+	if _, err := store.UpdateGoal(ctx, fetched); err != core.ErrConcurrentModification {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	goals, err := store.ListGoals(ctx, "test-project", "achieved")
+	if err != nil {
+		t.Fatalf("ListGoals: %v", err)
+	}
+	if len(goals) != 1 {
+		t.Errorf("len(goals) = %d, want 1", len(goals))
+	}
+
+	// Links are idempotent and removed with the goal
+	for i := 0; i < 2; i++ {
+		if err := store.LinkGoal(ctx, "test-project", created.ID, core.GoalLinkSpec, "spec-1"); err != nil {
+			t.Fatalf("LinkGoal: %v", err)
+		}
+	}
+	if err := store.LinkGoal(ctx, "test-project", created.ID, core.GoalLinkEpic, "epic-1"); err != nil {
+		t.Fatalf("LinkGoal: %v", err)
+	}
+	links, err := store.GetGoalLinks(ctx, "test-project", created.ID)
+	if err != nil {
+		t.Fatalf("GetGoalLinks: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("len(links) = %d, want 2", len(links))
+	}
+	if err := store.UnlinkGoal(ctx, "test-project", created.ID, core.GoalLinkEpic, "epic-1"); err != nil {
+		t.Fatalf("UnlinkGoal: %v", err)
+	}
+	if err := store.DeleteGoal(ctx, "test-project", created.ID); err != nil {
+		t.Fatalf("DeleteGoal: %v", err)
+	}
+	links, _ = store.GetGoalLinks(ctx, "test-project", created.ID)
+	if len(links) != 0 {
+		t.Errorf("len(links) after delete = %d, want 0", len(links))
+	}
+}
+
 // Optimistic locking tests
 
 func TestSpecOptimisticLocking(t *testing.T) {
@@ -531,6 +610,103 @@ func TestCUJOptimisticLocking(t *testing.T) {
 	}
 }
 
+func TestSessionOptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := store.CreateSession(ctx, core.Session{
+		Project: "test-project",
+		Name:    "tmux-main",
+		Agent:   "claude",
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("version = %d, want 1", created.Version)
+	}
+
+	created.Status = core.SessionStatusIdle
+	updated, err := store.UpdateSession(ctx, created)
+	if err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("version = %d, want 2", updated.Version)
+	}
+
+	// Stale update should fail
+	created.Status = core.SessionStatusError
+	_, err = store.UpdateSession(ctx, created)
+	if err != core.ErrConcurrentModification {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	fetched, err := store.GetSession(ctx, "test-project", created.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if fetched.Version != 2 || fetched.Status != core.SessionStatusIdle {
+		t.Errorf("fetched = v%d %s, want v2 idle", fetched.Version, fetched.Status)
+	}
+}
+
+func TestInsightOptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := store.CreateInsight(ctx, core.Insight{
+		Project:  "test-project",
+		Source:   "pollard",
+		Category: "competitor",
+		Title:    "Insight",
+		Score:    0.5,
+	})
+	if err != nil {
+		t.Fatalf("CreateInsight: %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("version = %d, want 1", created.Version)
+	}
+
+	created.Score = 0.9
+	updated, err := store.UpdateInsight(ctx, created)
+	if err != nil {
+		t.Fatalf("UpdateInsight: %v", err)
+	}
+	if updated.Version != 2 || updated.UpdatedAt.Before(created.CreatedAt) {
+		t.Errorf("updated = v%d at %v", updated.Version, updated.UpdatedAt)
+	}
+
+	// Stale update should fail
+	created.Title = "Stale Insight"
+	_, err = store.UpdateInsight(ctx, created)
+	if err != core.ErrConcurrentModification {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+
+	// Linking to a spec is a modification too.
+	if err := store.LinkInsightToSpec(ctx, "test-project", created.ID, "spec-1"); err != nil {
+		t.Fatalf("LinkInsightToSpec: %v", err)
+	}
+	fetched, err := store.GetInsight(ctx, "test-project", created.ID)
+	if err != nil {
+		t.Fatalf("GetInsight: %v", err)
+	}
+	if fetched.Version != 3 || fetched.Score != 0.9 || fetched.SpecID != "spec-1" {
+		t.Errorf("fetched = v%d score %v spec %q, want v3 0.9 spec-1", fetched.Version, fetched.Score, fetched.SpecID)
+	}
+	if _, err := store.UpdateInsight(ctx, updated); err != core.ErrConcurrentModification {
+		t.Errorf("update after link: expected ErrConcurrentModification, got %v", err)
+	}
+}
+
 func TestVersionPersistedInGet(t *testing.T) {
 	ctx := context.Background()
 	store, err := NewInMemory()
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Domain event log
+//
+// Domain events share the events table with message events but carry an
+// entity_type/entity_id pair; idx_events_entity keeps single-entity reads
+// off the rest of the log.
+
+// domainEventWhere builds the WHERE clause shared by ListDomainEvents and
+// CountDomainEvents.
+func domainEventWhere(f core.EventFilter) (string, []any) {
+	conds := []string{"entity_type != ''"}
+	var args []any
+	if f.Project != "" {
+		conds = append(conds, "project = ?")
+		args = append(args, f.Project)
+	}
+	if f.EntityType != "" {
+		conds = append(conds, "entity_type = ?")
+		args = append(args, f.EntityType)
+	}
+	if f.EntityID != "" {
+		conds = append(conds, "entity_id = ?")
+		args = append(args, f.EntityID)
+	}
+	if f.After > 0 {
+		conds = append(conds, "cursor > ?")
+		args = append(args, f.After)
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ListDomainEvents returns persisted domain events matching f in cursor
+// order.
+func (s *Store) ListDomainEvents(ctx context.Context, f core.EventFilter) ([]core.Event, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	where, args := domainEventWhere(f)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT cursor, id, type, project, actor, request_id, correlation_id, causation_id,
+		        entity_type, entity_id, data, created_at FROM events`+
+			where+` ORDER BY cursor ASC LIMIT ?`,
+		append(args, limit)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list domain events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []core.Event
+	for rows.Next() {
+		var ev core.Event
+		var typ, createdAt string
+		if err := rows.Scan(&ev.Cursor, &ev.ID, &typ, &ev.Project, &ev.Actor,
+			&ev.Trace.RequestID, &ev.Trace.CorrelationID, &ev.Trace.CausationID, &ev.EntityType, &ev.EntityID, &ev.Data, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan domain event: %w", err)
+		}
+		ev.Type = core.EventType(typ)
+		ev.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		out = append(out, ev)
+	}
+	return out, rows.Err()
+}
+
+// CountDomainEvents counts persisted domain events matching f; Limit is
+// ignored.
+func (s *Store) CountDomainEvents(ctx context.Context, f core.EventFilter) (int, error) {
+	where, args := domainEventWhere(f)
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events`+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count domain events: %w", err)
+	}
+	return n, nil
+}
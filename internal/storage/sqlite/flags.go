@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Feature flag operations
+
+// SetFeatureFlag creates or replaces a flag.
+func (s *Store) SetFeatureFlag(ctx context.Context, flag core.FeatureFlag) (core.FeatureFlag, error) {
+	flag.UpdatedAt = clock.Now().UTC()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO feature_flags (project, name, enabled, description, updated_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(project, name) DO UPDATE SET enabled = excluded.enabled, description = excluded.description, updated_at = excluded.updated_at`,
+		flag.Project, flag.Name, boolToInt(flag.Enabled), flag.Description, flag.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.FeatureFlag{}, fmt.Errorf("set feature flag: %w", err)
+	}
+	return flag, nil
+}
+
+// ListFeatureFlags returns every flag, server-wide defaults first.
+func (s *Store) ListFeatureFlags(ctx context.Context) ([]core.FeatureFlag, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT project, name, enabled, description, updated_at FROM feature_flags ORDER BY project, name`)
+	if err != nil {
+		return nil, fmt.Errorf("list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []core.FeatureFlag
+	for rows.Next() {
+		var f core.FeatureFlag
+		var enabled int
+		var updatedAt string
+		if err := rows.Scan(&f.Project, &f.Name, &enabled, &f.Description, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan feature flag: %w", err)
+		}
+		f.Enabled = enabled != 0
+		f.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
+
+// EffectiveFeatureFlags resolves every flag for project: the project's own
+// setting where it has one, else the server-wide default.
+func (s *Store) EffectiveFeatureFlags(ctx context.Context, project string) (map[string]bool, error) {
+	// Ordering by project puts the '' defaults first so project rows
+	// overwrite them.
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, enabled FROM feature_flags WHERE project IN ('', ?) ORDER BY project`,
+		project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("effective feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := map[string]bool{}
+	for rows.Next() {
+		var name string
+		var enabled int
+		if err := rows.Scan(&name, &enabled); err != nil {
+			return nil, fmt.Errorf("scan feature flag: %w", err)
+		}
+		flags[name] = enabled != 0
+	}
+	return flags, rows.Err()
+}
+
+// flagEnabledSQL is an SQL condition that holds when either of the flags
+// named by its first two arguments is on for the project given as its third
+// and fourth, resolved as EffectiveFeatureFlags does. A write that depends
+// on a flag reads it in the same statement, so the two can't disagree.
+const flagEnabledSQL = `EXISTS (SELECT 1 FROM feature_flags f
+	WHERE f.name IN (?, ?) AND f.enabled != 0
+	  AND (f.project = ? OR (f.project = '' AND NOT EXISTS (
+	    SELECT 1 FROM feature_flags p WHERE p.project = ? AND p.name = f.name))))`
+
+func (s *Store) DeleteFeatureFlag(ctx context.Context, project, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM feature_flags WHERE project = ? AND name = ?`, project, name)
+	if err != nil {
+		return fmt.Errorf("delete feature flag: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
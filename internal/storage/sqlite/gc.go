@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// CollectGarbage removes index rows, in every project, that point at
+// messages or threads that no longer exist, and recounts per-thread unread
+// counts that have drifted. Retention purges clean up after themselves;
+// this catches whatever else left rows behind. Pending pokes aren't index
+// rows: they carry their own body, and deferred live pokes never have a
+// messages row, so they're left alone.
+func (s *Store) CollectGarbage(ctx context.Context) (core.GCRun, error) {
+	run := core.GCRun{RanAt: clock.Now().UTC()}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return run, fmt.Errorf("begin gc: %w", err)
+	}
+	defer tx.Rollback()
+
+	exec := func(what, query string) (int64, error) {
+		res, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return 0, fmt.Errorf("gc %s: %w", what, err)
+		}
+		n, _ := res.RowsAffected()
+		return n, nil
+	}
+	orphaned := func(table string) string {
+		return `DELETE FROM ` + table + ` WHERE NOT EXISTS (
+			SELECT 1 FROM messages m WHERE m.project = ` + table + `.project AND m.message_id = ` + table + `.message_id)`
+	}
+
+	if run.Recipients, err = exec("recipients", orphaned("message_recipients")); err != nil {
+		return run, err
+	}
+	if run.InboxRows, err = exec("inbox", orphaned("inbox_index")); err != nil {
+		return run, err
+	}
+	if run.Threads, err = exec("threads",
+		`DELETE FROM thread_index WHERE NOT EXISTS (
+			SELECT 1 FROM messages m WHERE m.project = thread_index.project AND m.thread_id = thread_index.thread_id)`); err != nil {
+		return run, err
+	}
+	if run.UnreadRecounted, err = exec("thread unread",
+		`UPDATE thread_index SET unread_count = `+threadUnreadCount+` WHERE unread_count != `+threadUnreadCount); err != nil {
+		return run, err
+	}
+	if err := tx.Commit(); err != nil {
+		return run, fmt.Errorf("commit gc: %w", err)
+	}
+	return run, nil
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestCollectGarbageRemovesOrphanedIndexRows(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	send := func(id, thread string) {
+		t.Helper()
+		if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Project: "proj", Message: core.Message{
+			ID: id, ThreadID: thread, Project: "proj", From: "alice", To: []string{"bob"}, Body: id,
+		}}); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+	}
+	send("m1", "t1")
+	send("m2", "t2")
+	send("m3", "t2")
+	// A deferred live poke has no messages row, and must survive.
+	if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventPeerWindowPoke, Project: "proj", Message: core.Message{
+		ID: "p1", Project: "proj", From: "alice", To: []string{"bob"}, Body: "ping",
+		Metadata: map[string]string{"poke_result": core.PokeResultDeferred},
+	}}); err != nil {
+		t.Fatalf("append poke: %v", err)
+	}
+
+	// Nothing to collect yet.
+	run, err := st.CollectGarbage(ctx)
+	if err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if run.Reclaimed() != 0 || run.UnreadRecounted != 0 {
+		t.Fatalf("clean gc = %+v", run)
+	}
+
+	// Delete messages behind the index's back.
+	if _, err := st.db.Exec(`DELETE FROM messages WHERE message_id IN ('m1', 'm2')`); err != nil {
+		t.Fatalf("delete messages: %v", err)
+	}
+	run, err = st.CollectGarbage(ctx)
+	if err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	// t1 is indexed for alice and bob; t2 survives with one unread message.
+	if run.Recipients != 2 || run.InboxRows != 2 || run.Threads != 2 || run.UnreadRecounted != 1 {
+		t.Fatalf("gc = %+v", run)
+	}
+	if got := threadUnread(t, st, "bob", "t2"); got != 1 {
+		t.Fatalf("bob t2 unread = %d, want 1", got)
+	}
+	if pokes, err := st.ListPendingPokes(ctx, "proj", "bob"); err != nil || len(pokes) != 1 {
+		t.Fatalf("pending pokes after gc = %v, %v; want the deferred one", pokes, err)
+	}
+
+	run, err = st.CollectGarbage(ctx)
+	if err != nil {
+		t.Fatalf("gc again: %v", err)
+	}
+	if run.Reclaimed() != 0 || run.UnreadRecounted != 0 {
+		t.Fatalf("second gc = %+v", run)
+	}
+}
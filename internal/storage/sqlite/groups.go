@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Contact group operations
+
+func (s *Store) CreateContactGroup(ctx context.Context, group core.ContactGroup) (core.ContactGroup, error) {
+	now := clock.Now().UTC()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.ContactGroup{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM contact_groups WHERE project = ? AND name = ?`, group.Project, group.Name).Scan(&exists)
+	if err == nil {
+		return core.ContactGroup{}, core.ErrAlreadyExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return core.ContactGroup{}, fmt.Errorf("create contact group: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO contact_groups (project, name, description, team, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		group.Project, group.Name, group.Description, boolToInt(group.Team), now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+	); err != nil {
+		return core.ContactGroup{}, fmt.Errorf("create contact group: %w", err)
+	}
+	if err := replaceGroupMembersTx(ctx, tx, group.Project, group.Name, group.Members); err != nil {
+		return core.ContactGroup{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.ContactGroup{}, fmt.Errorf("commit: %w", err)
+	}
+	group.Members = sortedMembers(group.Members)
+	return group, nil
+}
+
+func (s *Store) GetContactGroup(ctx context.Context, project, name string) (core.ContactGroup, error) {
+	var g core.ContactGroup
+	var createdAt, updatedAt string
+	var team int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT project, name, description, team, created_at, updated_at FROM contact_groups WHERE project = ? AND name = ?`,
+		project, name,
+	).Scan(&g.Project, &g.Name, &g.Description, &team, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ContactGroup{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.ContactGroup{}, fmt.Errorf("get contact group: %w", err)
+	}
+	g.Team = team == 1
+	g.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	g.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	members, err := s.groupMembers(ctx, project, name)
+	if err != nil {
+		return core.ContactGroup{}, err
+	}
+	g.Members = members
+	return g, nil
+}
+
+func (s *Store) ListContactGroups(ctx context.Context, project string) ([]core.ContactGroup, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM contact_groups WHERE project = ? ORDER BY name`, project)
+	if err != nil {
+		return nil, fmt.Errorf("list contact groups: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan contact group: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	groups := make([]core.ContactGroup, 0, len(names))
+	for _, name := range names {
+		g, err := s.GetContactGroup(ctx, project, name)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// UpdateContactGroup replaces a group's description, team flag and
+// membership.
+func (s *Store) UpdateContactGroup(ctx context.Context, group core.ContactGroup) (core.ContactGroup, error) {
+	now := clock.Now().UTC()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.ContactGroup{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE contact_groups SET description = ?, team = ?, updated_at = ? WHERE project = ? AND name = ?`,
+		group.Description, boolToInt(group.Team), now.Format(time.RFC3339Nano), group.Project, group.Name,
+	)
+	if err != nil {
+		return core.ContactGroup{}, fmt.Errorf("update contact group: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ContactGroup{}, core.ErrNotFound
+	}
+	if err := replaceGroupMembersTx(ctx, tx, group.Project, group.Name, group.Members); err != nil {
+		return core.ContactGroup{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.ContactGroup{}, fmt.Errorf("commit: %w", err)
+	}
+	return s.GetContactGroup(ctx, group.Project, group.Name)
+}
+
+func (s *Store) DeleteContactGroup(ctx context.Context, project, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `DELETE FROM contact_groups WHERE project = ? AND name = ?`, project, name)
+	if err != nil {
+		return fmt.Errorf("delete contact group: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM contact_group_members WHERE project = ? AND group_name = ?`, project, name); err != nil {
+		return fmt.Errorf("delete contact group members: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *Store) AddContactGroupMember(ctx context.Context, project, name, agentID string) error {
+	var exists int
+	if err := s.db.QueryRowContext(ctx, `SELECT 1 FROM contact_groups WHERE project = ? AND name = ?`, project, name).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return core.ErrNotFound
+		}
+		return fmt.Errorf("add contact group member: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO contact_group_members (project, group_name, agent_id) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+		project, name, agentID,
+	); err != nil {
+		return fmt.Errorf("add contact group member: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) RemoveContactGroupMember(ctx context.Context, project, name, agentID string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`DELETE FROM contact_group_members WHERE project = ? AND group_name = ? AND agent_id = ?`,
+		project, name, agentID,
+	); err != nil {
+		return fmt.Errorf("remove contact group member: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) groupMembers(ctx context.Context, project, name string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT agent_id FROM contact_group_members WHERE project = ? AND group_name = ? ORDER BY agent_id`,
+		project, name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list contact group members: %w", err)
+	}
+	defer rows.Close()
+	members := []string{}
+	for rows.Next() {
+		var agent string
+		if err := rows.Scan(&agent); err != nil {
+			return nil, fmt.Errorf("scan contact group member: %w", err)
+		}
+		members = append(members, agent)
+	}
+	return members, rows.Err()
+}
+
+func replaceGroupMembersTx(ctx context.Context, tx dbTx, project, name string, members []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM contact_group_members WHERE project = ? AND group_name = ?`, project, name); err != nil {
+		return fmt.Errorf("clear contact group members: %w", err)
+	}
+	for _, agent := range members {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO contact_group_members (project, group_name, agent_id) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+			project, name, agent,
+		); err != nil {
+			return fmt.Errorf("insert contact group member %s: %w", agent, err)
+		}
+	}
+	return nil
+}
+
+// sortedMembers returns members deduplicated and sorted, matching the order
+// groupMembers reads them back in.
+func sortedMembers(members []string) []string {
+	out := core.Message{To: members}.Recipients()
+	if out == nil {
+		return []string{}
+	}
+	slices.Sort(out)
+	return out
+}
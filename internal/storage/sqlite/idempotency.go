@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Idempotency key operations
+
+func migrateIdempotencyHeaders(db *sql.DB) error {
+	if !tableExists(db, "idempotency_keys") || tableHasColumn(db, "idempotency_keys", "headers_json") {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE idempotency_keys ADD COLUMN headers_json TEXT NOT NULL DEFAULT '{}'`); err != nil {
+		return fmt.Errorf("add idempotency_keys.headers_json: %w", err)
+	}
+	return nil
+}
+
+// GetIdempotentResponse returns the response stored for project's key, or
+// core.ErrNotFound.
+func (s *Store) GetIdempotentResponse(ctx context.Context, project, key string) (core.IdempotentResponse, error) {
+	var out core.IdempotentResponse
+	var headers, createdAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT project, key, request_hash, status, content_type, headers_json, body, created_at
+		 FROM idempotency_keys WHERE project = ? AND key = ?`, project, key,
+	).Scan(&out.Project, &out.Key, &out.RequestHash, &out.Status, &out.ContentType, &headers, &out.Body, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.IdempotentResponse{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.IdempotentResponse{}, fmt.Errorf("get idempotent response: %w", err)
+	}
+	if err := json.Unmarshal([]byte(headers), &out.Header); err != nil {
+		return core.IdempotentResponse{}, fmt.Errorf("decode idempotent response headers: %w", err)
+	}
+	out.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	return out, nil
+}
+
+// SaveIdempotentResponse stores resp under its key. The first response
+// saved for a key wins.
+func (s *Store) SaveIdempotentResponse(ctx context.Context, resp core.IdempotentResponse) error {
+	if resp.CreatedAt.IsZero() {
+		resp.CreatedAt = clock.Now().UTC()
+	}
+	if resp.Header == nil {
+		resp.Header = map[string][]string{}
+	}
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("encode idempotent response headers: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (project, key, request_hash, status, content_type, headers_json, body, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		resp.Project, resp.Key, resp.RequestHash, resp.Status, resp.ContentType, string(headers), resp.Body,
+		resp.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("save idempotent response: %w", err)
+	}
+	return nil
+}
+
+// PruneIdempotencyKeys forgets keys stored before cutoff and returns how
+// many were removed.
+func (s *Store) PruneIdempotencyKeys(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE created_ms < ?`, unixMillis(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("prune idempotency keys: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
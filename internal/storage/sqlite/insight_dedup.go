@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Insight deduplication
+
+// migrateInsightContentHash adds insights.content_hash and fills it in for
+// existing rows.
+func migrateInsightContentHash(db *sql.DB) error {
+	if !tableExists(db, "insights") {
+		return nil
+	}
+	if !tableHasColumn(db, "insights", "content_hash") {
+		if _, err := db.Exec(`ALTER TABLE insights ADD COLUMN content_hash TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add insights.content_hash: %w", err)
+		}
+	}
+	rows, err := db.Query(`SELECT project, id, source, title FROM insights WHERE content_hash = ''`)
+	if err != nil {
+		return fmt.Errorf("list insights to hash: %w", err)
+	}
+	var pending [][3]string
+	for rows.Next() {
+		var project, id, source, title string
+		if err := rows.Scan(&project, &id, &source, &title); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan insight to hash: %w", err)
+		}
+		pending = append(pending, [3]string{project, id, core.InsightContentHash(source, title)})
+	}
+	rows.Close()
+	for _, p := range pending {
+		if _, err := db.Exec(`UPDATE insights SET content_hash = ? WHERE project = ? AND id = ?`, p[2], p[0], p[1]); err != nil {
+			return fmt.Errorf("backfill insight content_hash: %w", err)
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_insights_content_hash ON insights(project, content_hash)`); err != nil {
+		return fmt.Errorf("create idx_insights_content_hash: %w", err)
+	}
+	return nil
+}
+
+// migrateInsightDedupKey adds insights.dedup_key and the unique index over
+// it. CreateInsight sets it to the content hash while the project
+// deduplicates insights, so of two concurrent creates of the same insight
+// only one can land; rows written before it have no key.
+func migrateInsightDedupKey(db *sql.DB) error {
+	if !tableExists(db, "insights") {
+		return nil
+	}
+	if !tableHasColumn(db, "insights", "dedup_key") {
+		if _, err := db.Exec(`ALTER TABLE insights ADD COLUMN dedup_key TEXT`); err != nil {
+			return fmt.Errorf("add insights.dedup_key: %w", err)
+		}
+	}
+	if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_insights_dedup_key ON insights(project, dedup_key) WHERE dedup_key IS NOT NULL`); err != nil {
+		return fmt.Errorf("create idx_insights_dedup_key: %w", err)
+	}
+	return nil
+}
+
+// FindInsightByHash returns the oldest insight in project with the given
+// core.InsightContentHash, or core.ErrNotFound.
+func (s *Store) FindInsightByHash(ctx context.Context, project, hash string) (core.Insight, error) {
+	insight, err := scanInsight(s.db.QueryRowContext(ctx,
+		`SELECT `+insightColumns+` FROM insights WHERE project = ? AND content_hash = ?
+		 ORDER BY created_at, id LIMIT 1`, project, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Insight{}, core.ErrNotFound
+	}
+	return insight, err
+}
+
+// MergeInsights folds duplicates into the insight targetID and deletes
+// them, in one transaction. The target keeps its own fields, taking the
+// highest score and, where it has none, the first duplicate's spec link,
+// URL and body. Each duplicate's ID (and any IDs previously merged into
+// it) then resolves to the target through GetInsight. core.ErrNotFound if
+// the target or any duplicate doesn't exist.
+func (s *Store) MergeInsights(ctx context.Context, project, targetID string, duplicates []string) (core.Insight, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Insight{}, fmt.Errorf("begin merge insights: %w", err)
+	}
+	defer tx.Rollback()
+
+	get := func(id string) (core.Insight, error) {
+		insight, err := scanInsight(tx.QueryRowContext(ctx,
+			`SELECT `+insightColumns+` FROM insights WHERE project = ? AND id = ?`, project, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return core.Insight{}, core.ErrNotFound
+		}
+		return insight, err
+	}
+	target, err := get(targetID)
+	if err != nil {
+		return core.Insight{}, err
+	}
+	for _, id := range duplicates {
+		if id == targetID {
+			continue
+		}
+		dup, err := get(id)
+		if err != nil {
+			return core.Insight{}, err
+		}
+		target.Score = max(target.Score, dup.Score)
+		if target.SpecID == "" {
+			target.SpecID = dup.SpecID
+		}
+		if target.URL == "" {
+			target.URL = dup.URL
+		}
+		if target.Body == "" {
+			target.Body = dup.Body
+		}
+	}
+
+	now := clock.Now().UTC()
+	target.Version++
+	target.UpdatedAt = now
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE insights SET spec_id = ?, url = ?, body = ?, score = ?, version = ?, updated_at = ?
+		 WHERE project = ? AND id = ?`,
+		target.SpecID, target.URL, target.Body, target.Score, target.Version, now.Format(time.RFC3339Nano),
+		project, targetID,
+	); err != nil {
+		return core.Insight{}, fmt.Errorf("update merged insight: %w", err)
+	}
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(duplicates))) {
+		if id == targetID {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM insights WHERE project = ? AND id = ?`, project, id); err != nil {
+			return core.Insight{}, fmt.Errorf("delete merged insight: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE insight_merges SET into_id = ? WHERE project = ? AND into_id = ?`, targetID, project, id); err != nil {
+			return core.Insight{}, fmt.Errorf("repoint insight merges: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO insight_merges (project, merged_id, into_id, merged_at) VALUES (?, ?, ?, ?)
+			 ON CONFLICT (project, merged_id) DO UPDATE SET into_id = excluded.into_id, merged_at = excluded.merged_at`,
+			project, id, targetID, now.Format(time.RFC3339Nano)); err != nil {
+			return core.Insight{}, fmt.Errorf("record insight merge: %w", err)
+		}
+	}
+	// A target that was itself merged away earlier is live again.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM insight_merges WHERE project = ? AND merged_id = ?`, project, targetID); err != nil {
+		return core.Insight{}, fmt.Errorf("clear insight merge: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Insight{}, fmt.Errorf("commit merge insights: %w", err)
+	}
+	return target, nil
+}
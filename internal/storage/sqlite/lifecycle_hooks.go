@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Lifecycle hook operations
+
+const lifecycleHookColumns = `id, project, name, entity_type, from_status, to_status, actions_json, enabled, created_at, updated_at`
+
+func (s *Store) CreateLifecycleHook(ctx context.Context, hook core.LifecycleHook) (core.LifecycleHook, error) {
+	if hook.ID == "" {
+		hook.ID = uuid.NewString()
+	}
+	now := clock.Now().UTC()
+	hook.CreatedAt = now
+	hook.UpdatedAt = now
+	actions, err := json.Marshal(hook.Actions)
+	if err != nil {
+		return core.LifecycleHook{}, fmt.Errorf("marshal hook actions: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO lifecycle_hooks (`+lifecycleHookColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		hook.ID, hook.Project, hook.Name, hook.EntityType, hook.FromStatus, hook.ToStatus, string(actions),
+		boolToInt(hook.Enabled), now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.LifecycleHook{}, fmt.Errorf("create lifecycle hook: %w", err)
+	}
+	return hook, nil
+}
+
+func (s *Store) GetLifecycleHook(ctx context.Context, project, id string) (core.LifecycleHook, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+lifecycleHookColumns+` FROM lifecycle_hooks WHERE project = ? AND id = ?`, project, id)
+	hook, err := scanLifecycleHook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.LifecycleHook{}, core.ErrNotFound
+	}
+	return hook, err
+}
+
+// ListLifecycleHooks returns a project's hooks in creation order, which is
+// the order they fire in.
+func (s *Store) ListLifecycleHooks(ctx context.Context, project string) ([]core.LifecycleHook, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+lifecycleHookColumns+` FROM lifecycle_hooks WHERE project = ? ORDER BY created_at, id`,
+		project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list lifecycle hooks: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []core.LifecycleHook
+	for rows.Next() {
+		hook, err := scanLifecycleHook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+func (s *Store) UpdateLifecycleHook(ctx context.Context, hook core.LifecycleHook) (core.LifecycleHook, error) {
+	existing, err := s.GetLifecycleHook(ctx, hook.Project, hook.ID)
+	if err != nil {
+		return core.LifecycleHook{}, err
+	}
+	hook.CreatedAt = existing.CreatedAt
+	hook.UpdatedAt = clock.Now().UTC()
+	actions, err := json.Marshal(hook.Actions)
+	if err != nil {
+		return core.LifecycleHook{}, fmt.Errorf("marshal hook actions: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE lifecycle_hooks SET name = ?, entity_type = ?, from_status = ?, to_status = ?, actions_json = ?, enabled = ?, updated_at = ?
+		 WHERE project = ? AND id = ?`,
+		hook.Name, hook.EntityType, hook.FromStatus, hook.ToStatus, string(actions), boolToInt(hook.Enabled),
+		hook.UpdatedAt.Format(time.RFC3339Nano), hook.Project, hook.ID,
+	)
+	if err != nil {
+		return core.LifecycleHook{}, fmt.Errorf("update lifecycle hook: %w", err)
+	}
+	return hook, nil
+}
+
+// DeleteLifecycleHook deletes a hook; its runs stay in the audit log.
+func (s *Store) DeleteLifecycleHook(ctx context.Context, project, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM lifecycle_hooks WHERE project = ? AND id = ?`, project, id)
+	if err != nil {
+		return fmt.Errorf("delete lifecycle hook: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// RecordHookRun appends run to the hook audit log.
+func (s *Store) RecordHookRun(ctx context.Context, run core.HookRun) (core.HookRun, error) {
+	if run.ID == "" {
+		run.ID = uuid.NewString()
+	}
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = clock.Now().UTC()
+	}
+	results, err := json.Marshal(run.Results)
+	if err != nil {
+		return core.HookRun{}, fmt.Errorf("marshal hook results: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO lifecycle_hook_runs (id, project, hook_id, hook_name, entity_type, entity_id, from_status, to_status, results_json, ok, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.Project, run.HookID, run.HookName, run.EntityType, run.EntityID, run.FromStatus, run.ToStatus,
+		string(results), boolToInt(run.OK), run.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.HookRun{}, fmt.Errorf("record hook run: %w", err)
+	}
+	return run, nil
+}
+
+// ListHookRuns returns a project's most recent hook runs, newest first,
+// only hookID's when it is set.
+func (s *Store) ListHookRuns(ctx context.Context, project, hookID string, limit int) ([]core.HookRun, error) {
+	query := `SELECT id, project, hook_id, hook_name, entity_type, entity_id, from_status, to_status, results_json, ok, created_at
+		FROM lifecycle_hook_runs WHERE project = ?`
+	args := []any{project}
+	if hookID != "" {
+		query += ` AND hook_id = ?`
+		args = append(args, hookID)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY created_at DESC, id LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("list hook runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []core.HookRun
+	for rows.Next() {
+		var run core.HookRun
+		var results, createdAt string
+		var ok int
+		if err := rows.Scan(&run.ID, &run.Project, &run.HookID, &run.HookName, &run.EntityType, &run.EntityID,
+			&run.FromStatus, &run.ToStatus, &results, &ok, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan hook run: %w", err)
+		}
+		_ = json.Unmarshal([]byte(results), &run.Results)
+		run.OK = ok != 0
+		run.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func scanLifecycleHook(row scanner) (core.LifecycleHook, error) {
+	var h core.LifecycleHook
+	var actions, createdAt, updatedAt string
+	var enabled int
+	err := row.Scan(&h.ID, &h.Project, &h.Name, &h.EntityType, &h.FromStatus, &h.ToStatus, &actions,
+		&enabled, &createdAt, &updatedAt)
+	if err != nil {
+		return core.LifecycleHook{}, fmt.Errorf("scan lifecycle hook: %w", err)
+	}
+	if err := json.Unmarshal([]byte(actions), &h.Actions); err != nil {
+		return core.LifecycleHook{}, fmt.Errorf("decode hook actions: %w", err)
+	}
+	h.Enabled = enabled != 0
+	h.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	h.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	return h, nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// lookupSource is a table LookupEntity searches, with the SQL expressions
+// for the entity's title and short ID.
+type lookupSource struct {
+	entityType string
+	table      string
+	title      string
+	shortID    string
+}
+
+var lookupSources = []lookupSource{
+	{"spec", "specs", "title", "short_id"},
+	{"epic", "epics", "title", "short_id"},
+	{"story", "stories", "title", "short_id"},
+	{"task", "tasks", "title", "short_id"},
+	{"insight", "insights", "title", "''"},
+	{"session", "sessions", "name", "''"},
+	{"cuj", "cujs", "title", "''"},
+	{"goal", "goals", "title", "''"},
+}
+
+// LookupEntity finds every entity in project whose ID is ref, or whose
+// short ID is ref when it looks like one, under any prefix. IDs are only unique per table, so
+// there can be more than one. An empty project searches all projects.
+func (s *Store) LookupEntity(ctx context.Context, project, ref string) ([]core.EntityRef, error) {
+	var parts []string
+	var args []any
+	for _, src := range lookupSources {
+		where := "id = ?"
+		args = append(args, ref)
+		if normalized := core.ParseShortID(ref); normalized != "" && src.shortID != "''" {
+			where = "(id = ? OR short_id = ?)"
+			args = append(args, normalized)
+		}
+		sel := fmt.Sprintf(`SELECT '%s', id, %s, project, %s, status, updated_at FROM %s WHERE %s`,
+			src.entityType, src.shortID, src.title, src.table, where)
+		if project != "" {
+			sel += " AND project = ?"
+			args = append(args, project)
+		}
+		parts = append(parts, sel)
+	}
+	rows, err := s.db.QueryContext(ctx, strings.Join(parts, " UNION ALL ")+" LIMIT 20", args...)
+	if err != nil {
+		return nil, fmt.Errorf("lookup entity: %w", err)
+	}
+	defer rows.Close()
+	out := []core.EntityRef{}
+	for rows.Next() {
+		var e core.EntityRef
+		var updatedAt string
+		if err := rows.Scan(&e.Type, &e.ID, &e.ShortID, &e.Project, &e.Title, &e.Status, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan entity ref: %w", err)
+		}
+		e.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// projectSourceTables are the tables whose rows belong to a project: the
+// projects they name are backfilled into projects on upgrade, and a project
+// with rows in any of them can't be deleted.
+var projectSourceTables = []string{
+	"agents", "messages",
+	"specs", "epics", "stories", "tasks",
+	"insights", "cujs", "goals",
+}
+
+// migrateProjects registers every project already named by existing rows
+// (and every archived project) when the projects table is empty, which is
+// only the case on the first start after it was added: deleting the last
+// project requires that none of those rows remain.
+func migrateProjects(db *sql.DB) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM projects`).Scan(&n); err != nil {
+		return fmt.Errorf("count projects: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	var parts []string
+	for _, t := range projectSourceTables {
+		if tableExists(db, t) {
+			parts = append(parts, `SELECT project, created_at FROM `+t+` WHERE COALESCE(project, '') != ''`)
+		}
+	}
+	parts = append(parts, `SELECT project, archived_at FROM project_archives`)
+	_, err := db.Exec(`INSERT OR IGNORE INTO projects (name, created_at)
+		SELECT project, MIN(created_at) FROM (` + strings.Join(parts, " UNION ALL ") + `) GROUP BY project`)
+	if err != nil {
+		return fmt.Errorf("backfill projects: %w", err)
+	}
+	return nil
+}
+
+const projectColumns = `p.name, p.display_name, p.description, p.created_at, a.archived_at, p.spec_stale_days, p.short_id_sequences_json`
+
+const projectFrom = ` FROM projects p LEFT JOIN project_archives a ON a.project = p.name`
+
+func scanProject(row scanner) (core.Project, error) {
+	var p core.Project
+	var createdAt string
+	var archivedAt sql.NullString
+	var sequences string
+	if err := row.Scan(&p.Name, &p.DisplayName, &p.Description, &createdAt, &archivedAt, &p.SpecStaleDays, &sequences); err != nil {
+		return core.Project{}, err
+	}
+	p.ShortIDSequences = decodeShortIDSequences(sequences)
+	p.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	if archivedAt.Valid {
+		at, _ := time.Parse(time.RFC3339Nano, archivedAt.String)
+		p.Archived = true
+		p.ArchivedAt = &at
+	}
+	return p, nil
+}
+
+// CreateProject registers p. A project by that name already registered is
+// core.ErrAlreadyExists.
+func (s *Store) CreateProject(ctx context.Context, p core.Project) (core.Project, error) {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO projects (name, display_name, description, created_at, spec_stale_days, short_id_sequences_json) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (name) DO NOTHING`,
+		p.Name, p.DisplayName, p.Description, p.CreatedAt.UTC().Format(time.RFC3339Nano), p.SpecStaleDays, encodeShortIDSequences(p.ShortIDSequences),
+	)
+	if err != nil {
+		return core.Project{}, fmt.Errorf("create project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.Project{}, core.ErrAlreadyExists
+	}
+	return s.GetProject(ctx, p.Name)
+}
+
+// GetProject returns the registered project name, or core.ErrNotFound.
+func (s *Store) GetProject(ctx context.Context, name string) (core.Project, error) {
+	p, err := scanProject(s.db.QueryRowContext(ctx, `SELECT `+projectColumns+projectFrom+` WHERE p.name = ?`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Project{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Project{}, fmt.Errorf("get project: %w", err)
+	}
+	return p, nil
+}
+
+// CheckProject reports whether name is registered and, if so, archived.
+// Entity creation calls it on every request, so an unknown project isn't
+// an error: through ResilientStore it would count toward tripping the
+// breaker.
+func (s *Store) CheckProject(ctx context.Context, name string) (registered, archived bool, err error) {
+	var archivedAt sql.NullString
+	err = s.db.QueryRowContext(ctx, `SELECT a.archived_at`+projectFrom+` WHERE p.name = ?`, name).Scan(&archivedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("check project: %w", err)
+	}
+	return true, archivedAt.Valid, nil
+}
+
+// ListProjects returns registered projects by name. Archived ones are
+// left out unless includeArchived.
+func (s *Store) ListProjects(ctx context.Context, includeArchived bool) ([]core.Project, error) {
+	query := `SELECT ` + projectColumns + projectFrom
+	if !includeArchived {
+		query += ` WHERE a.project IS NULL`
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY p.name`)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+	out := []core.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// UpdateProject replaces a registered project's display name, description,
+// spec staleness window and short ID sequences.
+func (s *Store) UpdateProject(ctx context.Context, p core.Project) (core.Project, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE projects SET display_name = ?, description = ?, spec_stale_days = ?, short_id_sequences_json = ? WHERE name = ?`,
+		p.DisplayName, p.Description, p.SpecStaleDays, encodeShortIDSequences(p.ShortIDSequences), p.Name,
+	)
+	if err != nil {
+		return core.Project{}, fmt.Errorf("update project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.Project{}, core.ErrNotFound
+	}
+	return s.GetProject(ctx, p.Name)
+}
+
+// DeleteProject unregisters name. A project that still has agents,
+// messages or domain entities, here or in a cold archive, is
+// core.ErrProjectNotEmpty; archive it instead.
+func (s *Store) DeleteProject(ctx context.Context, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin delete project: %w", err)
+	}
+	defer tx.Rollback()
+	// Rows moved out to a cold archive still belong to the project.
+	var archived int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM project_archives WHERE project = ? AND archive_path != ''`, name).Scan(&archived); err != nil {
+		return fmt.Errorf("check project archive: %w", err)
+	}
+	if archived > 0 {
+		return core.ErrProjectNotEmpty
+	}
+	for _, t := range projectSourceTables {
+		var n int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+t+` WHERE project = ?`, name).Scan(&n); err != nil {
+			return fmt.Errorf("check %s: %w", t, err)
+		}
+		if n > 0 {
+			return core.ErrProjectNotEmpty
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM project_archives WHERE project = ?`, name); err != nil {
+		return fmt.Errorf("delete project archive: %w", err)
+	}
+	return tx.Commit()
+}
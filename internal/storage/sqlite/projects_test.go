@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestProjectsBackfilledOnUpgrade(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "projects.db")
+	store, err := New(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Rows written before the registry existed name their project only.
+	if _, err := store.CreateSpec(ctx, core.Spec{Project: "legacy", Title: "Old"}); err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	if registered, _, err := store.CheckProject(ctx, "legacy"); err != nil || registered {
+		t.Fatalf("before reopen: registered = %v, %v", registered, err)
+	}
+	store.Close()
+
+	store, err = New(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	p, err := store.GetProject(ctx, "legacy")
+	if err != nil || p.CreatedAt.IsZero() {
+		t.Fatalf("backfilled project = %+v, %v", p, err)
+	}
+	if err := store.DeleteProject(ctx, "legacy"); !errors.Is(err, core.ErrProjectNotEmpty) {
+		t.Fatalf("delete non-empty = %v, want ErrProjectNotEmpty", err)
+	}
+	if _, err := store.CreateProject(ctx, core.Project{Name: "legacy"}); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Fatalf("re-create = %v, want ErrAlreadyExists", err)
+	}
+}
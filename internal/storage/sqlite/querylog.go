@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"sync/atomic"
 	"time"
+
+	"github.com/mistakeknot/intermute/internal/storage"
 )
 
 const slowQueryThreshold = 100 * time.Millisecond
@@ -18,83 +21,126 @@ type dbHandle interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRow(query string, args ...any) *sql.Row
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
-	Begin() (*sql.Tx, error)
-	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	Begin() (dbTx, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (dbTx, error)
 	Close() error
 }
 
-// queryLogger wraps a *sql.DB and logs queries that exceed the slow query threshold.
+// dbTx is the interface satisfied by *sql.Tx and by the savepoints a
+// transaction-bound Store opens in place of nested transactions (see
+// RunInTx).
+type dbTx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+// queryLogger wraps a *sql.DB and logs queries that exceed the slow query
+// threshold. Queries run with a request context also count toward that
+// request's storage.QueryStats, and their slow-query lines name its route.
 type queryLogger struct {
 	inner *sql.DB
+	// slow overrides slowQueryThreshold when positive, in nanoseconds.
+	slow atomic.Int64
+}
+
+func (q *queryLogger) threshold() time.Duration {
+	if d := time.Duration(q.slow.Load()); d > 0 {
+		return d
+	}
+	return slowQueryThreshold
+}
+
+// observe records a finished query against ctx's stats and logs it if slow.
+func (q *queryLogger) observe(ctx context.Context, start time.Time, query string) {
+	d := time.Since(start)
+	qs := storage.QueryStatsFromContext(ctx)
+	qs.Record(d)
+	if d < q.threshold() {
+		return
+	}
+	if qs != nil && qs.Route != "" {
+		log.Printf("SLOW QUERY (%s) [%s]: %s", d.Round(time.Millisecond), qs.Route, truncateQuery(query))
+		return
+	}
+	log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
 }
 
 func (q *queryLogger) Exec(query string, args ...any) (sql.Result, error) {
 	start := time.Now()
 	result, err := q.inner.Exec(query, args...)
-	if d := time.Since(start); d >= slowQueryThreshold {
-		log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
-	}
+	q.observe(context.Background(), start, query)
 	return result, err
 }
 
 func (q *queryLogger) Query(query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
 	rows, err := q.inner.Query(query, args...)
-	if d := time.Since(start); d >= slowQueryThreshold {
-		log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
-	}
+	q.observe(context.Background(), start, query)
 	return rows, err
 }
 
 func (q *queryLogger) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	start := time.Now()
 	result, err := q.inner.ExecContext(ctx, query, args...)
-	if d := time.Since(start); d >= slowQueryThreshold {
-		log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
-	}
+	q.observe(ctx, start, query)
 	return result, err
 }
 
 func (q *queryLogger) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
 	rows, err := q.inner.QueryContext(ctx, query, args...)
-	if d := time.Since(start); d >= slowQueryThreshold {
-		log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
-	}
+	q.observe(ctx, start, query)
 	return rows, err
 }
 
 func (q *queryLogger) QueryRow(query string, args ...any) *sql.Row {
 	start := time.Now()
 	row := q.inner.QueryRow(query, args...)
-	d := time.Since(start)
-	if d >= slowQueryThreshold {
-		log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
-	}
+	q.observe(context.Background(), start, query)
 	return row
 }
 
 func (q *queryLogger) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
 	start := time.Now()
 	row := q.inner.QueryRowContext(ctx, query, args...)
-	if d := time.Since(start); d >= slowQueryThreshold {
-		log.Printf("SLOW QUERY (%s): %s", d.Round(time.Millisecond), truncateQuery(query))
-	}
+	q.observe(ctx, start, query)
 	return row
 }
 
-func (q *queryLogger) Begin() (*sql.Tx, error) {
-	return q.inner.Begin()
+func (q *queryLogger) Begin() (dbTx, error) {
+	tx, err := q.inner.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
 
-func (q *queryLogger) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
-	return q.inner.BeginTx(ctx, opts)
+func (q *queryLogger) BeginTx(ctx context.Context, opts *sql.TxOptions) (dbTx, error) {
+	tx, err := q.inner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
 
 func (q *queryLogger) Close() error {
 	return q.inner.Close()
 }
 
+// SetSlowQueryThreshold changes how long a query may take before it is
+// logged. Non-positive values restore the 100ms default.
+func (s *Store) SetSlowQueryThreshold(d time.Duration) {
+	if ql, ok := s.db.(*queryLogger); ok {
+		ql.slow.Store(int64(d))
+	}
+}
+
 func truncateQuery(s string) string {
 	if len(s) > 200 {
 		return s[:200] + "..."
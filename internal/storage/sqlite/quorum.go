@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Ack quorums
+//
+// A message's ack_policy says how many of its message_recipients rows need
+// an ack_at. quorum_reached_at records the first time that held, so the
+// quorum is announced exactly once however many acks race to complete it.
+
+func migrateMessageAckPolicy(db *sql.DB) error {
+	if !tableExists(db, "messages") {
+		return nil
+	}
+	if !tableHasColumn(db, "messages", "ack_policy") {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN ack_policy TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add ack_policy column: %w", err)
+		}
+	}
+	if !tableHasColumn(db, "messages", "quorum_reached_at") {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN quorum_reached_at TEXT`); err != nil {
+			return fmt.Errorf("add quorum_reached_at column: %w", err)
+		}
+	}
+	return nil
+}
+
+// AckQuorum returns the ack progress of a message against its ack policy.
+// The first call to find the quorum met records it and reports reached;
+// every later call reports false. A message without a policy has a zero
+// Policy and is never reached. Returns core.ErrNotFound for an unknown
+// message.
+func (s *Store) AckQuorum(ctx context.Context, project, messageID string) (core.AckProgress, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.AckProgress{}, false, fmt.Errorf("begin ack quorum: %w", err)
+	}
+	defer tx.Rollback()
+
+	progress := core.AckProgress{MessageID: messageID}
+	var policy string
+	var reachedAt sql.NullString
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(ack_policy, ''), quorum_reached_at FROM messages WHERE project = ? AND message_id = ?`,
+		project, messageID,
+	).Scan(&policy, &reachedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return core.AckProgress{}, false, core.ErrNotFound
+		}
+		return core.AckProgress{}, false, fmt.Errorf("ack quorum: %w", err)
+	}
+	progress.Policy = core.AckPolicy(policy)
+	if progress.Policy == "" {
+		return progress, false, nil
+	}
+	if reachedAt.Valid {
+		t, _ := time.Parse(time.RFC3339Nano, reachedAt.String)
+		progress.ReachedAt = &t
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT agent_id, ack_at FROM message_recipients WHERE project = ? AND message_id = ?
+		 ORDER BY ack_at IS NULL, ack_at, agent_id`,
+		project, messageID,
+	)
+	if err != nil {
+		return core.AckProgress{}, false, fmt.Errorf("query ack quorum: %w", err)
+	}
+	for rows.Next() {
+		var agentID string
+		var ackAt sql.NullString
+		if err := rows.Scan(&agentID, &ackAt); err != nil {
+			rows.Close()
+			return core.AckProgress{}, false, fmt.Errorf("scan ack quorum: %w", err)
+		}
+		progress.Recipients++
+		if ackAt.Valid {
+			progress.Acked = append(progress.Acked, agentID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return core.AckProgress{}, false, fmt.Errorf("rows: %w", err)
+	}
+	progress.Required = progress.Policy.Required(progress.Recipients)
+
+	if progress.ReachedAt != nil || !progress.Reached() {
+		return progress, false, nil
+	}
+	now := clock.Now().UTC()
+	res, err := tx.ExecContext(ctx,
+		`UPDATE messages SET quorum_reached_at = ? WHERE project = ? AND message_id = ? AND quorum_reached_at IS NULL`,
+		now.Format(time.RFC3339Nano), project, messageID,
+	)
+	if err != nil {
+		return core.AckProgress{}, false, fmt.Errorf("record quorum: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return progress, false, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return core.AckProgress{}, false, fmt.Errorf("commit ack quorum: %w", err)
+	}
+	progress.ReachedAt = &now
+	return progress, true, nil
+}
@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestAckQuorumReachedOnce(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	msg := core.Message{ID: "m1", Project: "proj", From: "lead", To: []string{"a", "b"}, CC: []string{"c"}, Body: "ship?", AckPolicy: core.AckPolicyAll}
+	if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Message: msg}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	for i, agent := range []string{"b", "a", "c"} {
+		if err := st.MarkAck(ctx, "proj", "m1", agent); err != nil {
+			t.Fatalf("ack %s: %v", agent, err)
+		}
+		progress, reached, err := st.AckQuorum(ctx, "proj", "m1")
+		if err != nil {
+			t.Fatalf("quorum: %v", err)
+		}
+		if progress.Required != 3 || len(progress.Acked) != i+1 || progress.Acked[0] != "b" {
+			t.Fatalf("after %s: %+v", agent, progress)
+		}
+		if reached != (agent == "c") {
+			t.Fatalf("after %s: reached = %v", agent, reached)
+		}
+	}
+	if progress, reached, _ := st.AckQuorum(ctx, "proj", "m1"); reached || progress.ReachedAt == nil {
+		t.Fatalf("repeat check: reached = %v, progress %+v", reached, progress)
+	}
+
+	got, err := st.GetMessage(ctx, "proj", "m1")
+	if err != nil || got.AckPolicy != core.AckPolicyAll {
+		t.Fatalf("GetMessage policy = %q, %v", got.AckPolicy, err)
+	}
+	if _, _, err := st.AckQuorum(ctx, "proj", "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("missing message err = %v", err)
+	}
+}
@@ -230,3 +230,70 @@ func TestConcurrentInboxReads(t *testing.T) {
 		t.Fatalf("expected %d messages, got %d", msgsToWrite, len(msgs))
 	}
 }
+
+// TestInMemoryConcurrentAccess runs mixed concurrent writes and reads against
+// NewInMemory, which must behave like one database however the pool hands
+// out connections.
+func TestInMemoryConcurrentAccess(t *testing.T) {
+	st := NewSQLiteTest(t)
+	t.Cleanup(func() { _ = st.Close() })
+	ctx := context.Background()
+	const workers = 8
+	const msgsPerWorker = 10
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func(workerID int) {
+			defer wg.Done()
+			for j := 0; j < msgsPerWorker; j++ {
+				if _, err := st.AppendEvent(ctx, core.Event{
+					Type:    core.EventMessageCreated,
+					Project: "mem-proj",
+					Message: core.Message{
+						From: fmt.Sprintf("worker-%d", workerID),
+						To:   []string{"inbox-agent"},
+						Body: fmt.Sprintf("msg-%d-%d", workerID, j),
+					},
+				}); err != nil {
+					t.Errorf("worker %d msg %d: %v", workerID, j, err)
+				}
+			}
+		}(i)
+		go func(workerID int) {
+			defer wg.Done()
+			if _, err := st.RegisterAgent(ctx, core.Agent{
+				Name:    fmt.Sprintf("agent-%d", workerID),
+				Project: "mem-proj",
+			}); err != nil {
+				t.Errorf("register %d: %v", workerID, err)
+			}
+			if _, err := st.InboxSince(ctx, "mem-proj", "inbox-agent", 0, 0); err != nil {
+				t.Errorf("inbox %d: %v", workerID, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	msgs, err := st.InboxSince(ctx, "mem-proj", "inbox-agent", 0, 0)
+	if err != nil {
+		t.Fatalf("inbox: %v", err)
+	}
+	if len(msgs) != workers*msgsPerWorker {
+		t.Fatalf("expected %d messages, got %d", workers*msgsPerWorker, len(msgs))
+	}
+	agents, err := st.ListAgents(ctx, "mem-proj", nil)
+	if err != nil {
+		t.Fatalf("list agents: %v", err)
+	}
+	if len(agents) != workers {
+		t.Fatalf("expected %d agents, got %d", workers, len(agents))
+	}
+
+	// A second in-memory store must not see the first one's data.
+	other := NewSQLiteTest(t)
+	t.Cleanup(func() { _ = other.Close() })
+	if agents, err := other.ListAgents(ctx, "mem-proj", nil); err != nil || len(agents) != 0 {
+		t.Fatalf("expected isolated store, got %d agents (err %v)", len(agents), err)
+	}
+}
@@ -0,0 +1,210 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// indexTables are the tables derived from the events log that
+// RebuildIndexes regenerates.
+var indexTables = []string{"inbox_index", "message_recipients", "thread_index"}
+
+// ErrUnindexedMessages is returned by RebuildIndexes, unless forced, when
+// some messages can be rebuilt from neither their events nor their
+// surviving inbox rows and would drop out of every inbox.
+var ErrUnindexedMessages = errors.New("messages have no message.created event or inbox row to rebuild from")
+
+// IndexRebuild is the outcome of RebuildIndexes.
+type IndexRebuild struct {
+	// Replayed is how many message.created and message.unsnoozed events
+	// the tables were rebuilt from.
+	Replayed int `json:"replayed"`
+	// Recovered is how many messages whose message.created event is gone
+	// (retention trims events sooner than messages) were rebuilt from the
+	// messages table, at the cursors of their surviving inbox rows.
+	Recovered int `json:"recovered"`
+	// Before and After are each index table's row count.
+	Before map[string]int64 `json:"before"`
+	After  map[string]int64 `json:"after"`
+	// Unindexed counts messages with neither a message.created event nor
+	// an inbox row, so no cursor to deliver them at: they're left out of
+	// inboxes, recipients and threads.
+	Unindexed int64 `json:"unindexed"`
+}
+
+// RebuildIndexes regenerates inbox_index, message_recipients and
+// thread_index in one transaction, for when they've been damaged or lost.
+// Every message.created event is delivered again as appendEventTx first
+// did, and message.unsnoozed events move inbox rows to their wake cursor.
+// Messages whose event retention has already trimmed are delivered again
+// from the messages table, at the cursors their inbox rows had. The read,
+// ack, injected and snooze state of recipient rows that survive is kept,
+// and unread counts are recounted from it.
+//
+// Messages with neither an event nor an inbox row can't be placed in any
+// inbox. Unless force, RebuildIndexes then changes nothing and returns
+// ErrUnindexedMessages, with Unindexed counting them.
+func (s *Store) RebuildIndexes(ctx context.Context, force bool) (IndexRebuild, error) {
+	out := IndexRebuild{Before: map[string]int64{}, After: map[string]int64{}}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return out, fmt.Errorf("begin rebuild: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := func(into map[string]int64) error {
+		for _, table := range indexTables {
+			var n int64
+			if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&n); err != nil {
+				return fmt.Errorf("count %s: %w", table, err)
+			}
+			into[table] = n
+		}
+		return nil
+	}
+	if err := count(out.Before); err != nil {
+		return out, err
+	}
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS rebuild_recipients`,
+		`CREATE TEMP TABLE rebuild_recipients AS SELECT * FROM message_recipients`,
+		`DROP TABLE IF EXISTS rebuild_inbox`,
+		`CREATE TEMP TABLE rebuild_inbox AS SELECT * FROM inbox_index`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return out, fmt.Errorf("snapshot indexes: %w", err)
+		}
+	}
+
+	// Messages whose message.created event is gone.
+	const eventless = `NOT EXISTS (
+		SELECT 1 FROM events e WHERE e.project = m.project AND e.message_id = m.message_id AND e.type = ?)`
+	const inboxed = `EXISTS (
+		SELECT 1 FROM rebuild_inbox o WHERE o.project = m.project AND o.message_id = m.message_id)`
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM messages m WHERE `+eventless+` AND NOT `+inboxed,
+		string(core.EventMessageCreated),
+	).Scan(&out.Unindexed); err != nil {
+		return out, fmt.Errorf("count unindexed messages: %w", err)
+	}
+	if out.Unindexed > 0 && !force {
+		return out, fmt.Errorf("%w: %d", ErrUnindexedMessages, out.Unindexed)
+	}
+
+	type replay struct {
+		msg       core.Message
+		cursor    int64
+		typ       string
+		agent     string
+		at        time.Time
+		recovered bool
+	}
+	var replays []replay
+	collect := func(recovered bool, query string, args ...any) error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("query messages to index: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			r := replay{recovered: recovered}
+			var at string
+			r.msg, err = scanMessageRow(rows, &r.typ, &r.agent, &at)
+			if err != nil {
+				return fmt.Errorf("scan message to index: %w", err)
+			}
+			r.cursor = int64(r.msg.Cursor)
+			r.at, _ = time.Parse(time.RFC3339Nano, at)
+			replays = append(replays, r)
+		}
+		return rows.Err()
+	}
+	const messageColumns = `m.project, m.message_id, m.thread_id, m.from_agent, m.to_json,
+		   COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
+		   m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, '')`
+	if err := collect(false,
+		`SELECT e.cursor, `+messageColumns+`, e.type, COALESCE(e.agent, ''), e.created_at
+		 FROM events e
+		 JOIN messages m ON m.project = e.project AND m.message_id = e.message_id
+		 WHERE e.type IN (?, ?)`,
+		string(core.EventMessageCreated), string(core.EventMessageUnsnoozed)); err != nil {
+		return out, err
+	}
+	if err := collect(true,
+		`SELECT (SELECT MIN(o.cursor) FROM rebuild_inbox o WHERE o.project = m.project AND o.message_id = m.message_id),
+		   `+messageColumns+`, ?, '', m.created_at
+		 FROM messages m WHERE `+eventless+` AND `+inboxed,
+		string(core.EventMessageCreated), string(core.EventMessageCreated)); err != nil {
+		return out, err
+	}
+	// Thread rows keep the last message they see, so go in cursor order.
+	slices.SortStableFunc(replays, func(a, b replay) int { return cmp.Compare(a.cursor, b.cursor) })
+
+	for _, stmt := range []string{
+		`DELETE FROM inbox_index`,
+		`DELETE FROM message_recipients`,
+		`DELETE FROM thread_index`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return out, fmt.Errorf("clear indexes: %w", err)
+		}
+	}
+	for _, r := range replays {
+		if r.typ == string(core.EventMessageUnsnoozed) {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE inbox_index SET cursor = ? WHERE project = ? AND agent = ? AND message_id = ?`,
+				r.cursor, r.msg.Project, r.agent, r.msg.ID,
+			); err != nil {
+				return out, fmt.Errorf("move inbox row: %w", err)
+			}
+			out.Replayed++
+			continue
+		}
+		if err := s.indexMessageTx(ctx, tx, r.msg.Project, r.agent, r.cursor, r.msg, r.at); err != nil {
+			return out, fmt.Errorf("replay %s: %w", r.msg.ID, err)
+		}
+		if !r.recovered {
+			out.Replayed++
+			continue
+		}
+		// Each agent's row goes back to its own cursor: a wake may have
+		// moved it past the others.
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE inbox_index SET cursor = o.cursor FROM rebuild_inbox o
+			 WHERE o.project = inbox_index.project AND o.agent = inbox_index.agent AND o.message_id = inbox_index.message_id
+			   AND inbox_index.project = ? AND inbox_index.message_id = ?`,
+			r.msg.Project, r.msg.ID,
+		); err != nil {
+			return out, fmt.Errorf("restore inbox cursors of %s: %w", r.msg.ID, err)
+		}
+		out.Recovered++
+	}
+	for _, stmt := range []string{
+		`UPDATE message_recipients SET read_at = o.read_at, ack_at = o.ack_at,
+		   injected_at = o.injected_at, snoozed_until = o.snoozed_until
+		 FROM rebuild_recipients o
+		 WHERE o.project = message_recipients.project AND o.message_id = message_recipients.message_id
+		   AND o.agent_id = message_recipients.agent_id`,
+		`DROP TABLE rebuild_recipients`,
+		`DROP TABLE rebuild_inbox`,
+		`UPDATE thread_index SET unread_count = ` + threadUnreadCount,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return out, fmt.Errorf("restore recipient state: %w", err)
+		}
+	}
+
+	if err := count(out.After); err != nil {
+		return out, err
+	}
+	if err := tx.Commit(); err != nil {
+		return out, fmt.Errorf("commit rebuild: %w", err)
+	}
+	return out, nil
+}
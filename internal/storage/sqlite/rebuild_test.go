@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// dumpIndexes renders every row of the index tables, in a stable order.
+func dumpIndexes(t *testing.T, st *Store) []string {
+	t.Helper()
+	var out []string
+	for _, q := range []string{
+		`SELECT project, agent, cursor, message_id FROM inbox_index ORDER BY project, agent, message_id`,
+		`SELECT project, message_id, agent_id, kind, read_at, ack_at, injected_at, snoozed_until
+		 FROM message_recipients ORDER BY project, message_id, agent_id`,
+		`SELECT project, thread_id, agent, last_cursor, message_count, last_message_from,
+		   last_message_body, last_message_at, unread_count
+		 FROM thread_index ORDER BY project, thread_id, agent`,
+	} {
+		rows, err := st.db.Query(q)
+		if err != nil {
+			t.Fatalf("dump: %v", err)
+		}
+		cols, _ := rows.Columns()
+		for rows.Next() {
+			vals := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range vals {
+				ptrs[i] = &vals[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			out = append(out, fmt.Sprint(vals...))
+		}
+		rows.Close()
+	}
+	return out
+}
+
+func TestRebuildIndexesRestoresDerivedTables(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	t.Cleanup(clock.Reset)
+	send := func(msg core.Message) {
+		t.Helper()
+		msg.Project = "proj"
+		msg.Body = msg.ID
+		if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Project: "proj", Message: msg}); err != nil {
+			t.Fatalf("append %s: %v", msg.ID, err)
+		}
+	}
+	send(core.Message{ID: "m1", ThreadID: "t1", From: "alice", To: []string{"bob"}, CC: []string{"carol"}})
+	send(core.Message{ID: "m2", ThreadID: "t1", From: "bob", To: []string{"alice"}, BCC: []string{"dave"}})
+	send(core.Message{ID: "m3", From: "alice", To: []string{"bob", "carol"}})
+	if err := st.MarkRead(ctx, "proj", "m1", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.MarkAck(ctx, "proj", "m3", "carol"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SnoozeMessage(ctx, "proj", "m3", "bob", clock.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(2 * time.Hour)
+	if _, err := st.WakeSnoozed(ctx, "proj", "bob", clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	want := dumpIndexes(t, st)
+
+	// Lose the inbox and threads outright and a recipient row, and corrupt
+	// another's kind.
+	for _, stmt := range []string{
+		`DELETE FROM inbox_index`,
+		`DELETE FROM thread_index`,
+		`DELETE FROM message_recipients WHERE message_id = 'm2'`,
+		`UPDATE message_recipients SET kind = 'bcc' WHERE message_id = 'm1' AND agent_id = 'carol'`,
+	} {
+		if _, err := st.db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	got, err := st.RebuildIndexes(ctx, false)
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if got.Replayed != 4 || got.Unindexed != 0 {
+		t.Fatalf("rebuild = %+v, want 3 messages and a wake replayed", got)
+	}
+	if got.Before["inbox_index"] != 0 || got.After["inbox_index"] != 6 {
+		t.Fatalf("inbox counts before %d after %d, want 0 and 6", got.Before["inbox_index"], got.After["inbox_index"])
+	}
+	if after := dumpIndexes(t, st); !slices.Equal(after, want) {
+		t.Fatalf("rebuilt indexes:\n%v\nwant:\n%v", after, want)
+	}
+
+	// A second rebuild is a no-op.
+	if _, err := st.RebuildIndexes(ctx, false); err != nil {
+		t.Fatalf("rebuild again: %v", err)
+	}
+	if after := dumpIndexes(t, st); !slices.Equal(after, want) {
+		t.Fatalf("indexes after a second rebuild:\n%v\nwant:\n%v", after, want)
+	}
+}
+
+func TestRebuildIndexesRecoversMessagesWithoutEvents(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Project: "proj", Message: core.Message{
+			ID: id, ThreadID: "t1", Project: "proj", From: "alice", To: []string{"bob"}, Body: id,
+		}}); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+	}
+	if err := st.MarkRead(ctx, "proj", "m1", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	want := dumpIndexes(t, st)
+
+	// Retention trimmed m1's and m2's events; the thread index was lost.
+	for _, stmt := range []string{
+		`DELETE FROM events WHERE message_id IN ('m1', 'm2')`,
+		`DELETE FROM thread_index`,
+	} {
+		if _, err := st.db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	got, err := st.RebuildIndexes(ctx, false)
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if got.Replayed != 1 || got.Recovered != 2 || got.Unindexed != 0 {
+		t.Fatalf("rebuild = %+v, want 1 replayed and 2 recovered", got)
+	}
+	if after := dumpIndexes(t, st); !slices.Equal(after, want) {
+		t.Fatalf("rebuilt indexes:\n%v\nwant:\n%v", after, want)
+	}
+
+	// Without its inbox rows too, m2 has nothing to be placed by.
+	if _, err := st.db.Exec(`DELETE FROM inbox_index WHERE message_id = 'm2'`); err != nil {
+		t.Fatal(err)
+	}
+	before := dumpIndexes(t, st)
+	got, err = st.RebuildIndexes(ctx, false)
+	if !errors.Is(err, ErrUnindexedMessages) || got.Unindexed != 1 {
+		t.Fatalf("rebuild = %+v, %v; want ErrUnindexedMessages for one message", got, err)
+	}
+	if after := dumpIndexes(t, st); !slices.Equal(after, before) {
+		t.Fatalf("refused rebuild changed the indexes:\n%v\nwant:\n%v", after, before)
+	}
+	if got, err = st.RebuildIndexes(ctx, true); err != nil || got.Recovered != 1 || got.Unindexed != 1 {
+		t.Fatalf("forced rebuild = %+v, %v", got, err)
+	}
+}
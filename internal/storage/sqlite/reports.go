@@ -0,0 +1,176 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Report schedule operations
+
+const reportScheduleColumns = `id, project, name, kind, cadence, hour, weekday, recipients_json, enabled,
+	last_run_at, next_run_at, created_at, updated_at`
+
+func (s *Store) CreateReportSchedule(ctx context.Context, sched core.ReportSchedule) (core.ReportSchedule, error) {
+	if sched.ID == "" {
+		sched.ID = uuid.NewString()
+	}
+	now := clock.Now().UTC()
+	sched.CreatedAt = now
+	sched.UpdatedAt = now
+	if sched.NextRunAt.IsZero() {
+		sched.NextRunAt = sched.NextRunAfter(now)
+	}
+	recipients, err := json.Marshal(sched.Recipients)
+	if err != nil {
+		return core.ReportSchedule{}, fmt.Errorf("marshal recipients: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO report_schedules (`+reportScheduleColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sched.ID, sched.Project, sched.Name, string(sched.Kind), string(sched.Cadence), sched.Hour, int(sched.Weekday),
+		string(recipients), boolToInt(sched.Enabled), nullableTime(sched.LastRunAt),
+		sched.NextRunAt.UTC().Format(time.RFC3339Nano), now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.ReportSchedule{}, fmt.Errorf("create report schedule: %w", err)
+	}
+	return sched, nil
+}
+
+func (s *Store) GetReportSchedule(ctx context.Context, project, id string) (core.ReportSchedule, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+reportScheduleColumns+` FROM report_schedules WHERE project = ? AND id = ?`,
+		project, id,
+	)
+	sched, err := scanReportSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ReportSchedule{}, core.ErrNotFound
+	}
+	return sched, err
+}
+
+func (s *Store) ListReportSchedules(ctx context.Context, project string) ([]core.ReportSchedule, error) {
+	return s.queryReportSchedules(ctx,
+		`SELECT `+reportScheduleColumns+` FROM report_schedules WHERE project = ? ORDER BY created_at, id`,
+		project,
+	)
+}
+
+// DueReportSchedules returns enabled schedules across all projects whose
+// next run is at or before now, oldest first.
+func (s *Store) DueReportSchedules(ctx context.Context, now time.Time) ([]core.ReportSchedule, error) {
+	return s.queryReportSchedules(ctx,
+		`SELECT `+reportScheduleColumns+` FROM report_schedules
+		 WHERE enabled = 1 AND next_run_ms <= ? ORDER BY next_run_ms, id`,
+		unixMillis(now),
+	)
+}
+
+// UpdateReportSchedule replaces a schedule's settings. The next run is
+// recomputed from the new cadence; run history is kept.
+func (s *Store) UpdateReportSchedule(ctx context.Context, sched core.ReportSchedule) (core.ReportSchedule, error) {
+	existing, err := s.GetReportSchedule(ctx, sched.Project, sched.ID)
+	if err != nil {
+		return core.ReportSchedule{}, err
+	}
+	now := clock.Now().UTC()
+	sched.CreatedAt = existing.CreatedAt
+	sched.LastRunAt = existing.LastRunAt
+	sched.UpdatedAt = now
+	sched.NextRunAt = sched.NextRunAfter(now)
+	recipients, err := json.Marshal(sched.Recipients)
+	if err != nil {
+		return core.ReportSchedule{}, fmt.Errorf("marshal recipients: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE report_schedules SET name = ?, kind = ?, cadence = ?, hour = ?, weekday = ?, recipients_json = ?,
+		 enabled = ?, next_run_at = ?, updated_at = ?
+		 WHERE project = ? AND id = ?`,
+		sched.Name, string(sched.Kind), string(sched.Cadence), sched.Hour, int(sched.Weekday), string(recipients),
+		boolToInt(sched.Enabled), sched.NextRunAt.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+		sched.Project, sched.ID,
+	)
+	if err != nil {
+		return core.ReportSchedule{}, fmt.Errorf("update report schedule: %w", err)
+	}
+	return sched, nil
+}
+
+// MarkReportRun records that a schedule ran at ranAt and is next due at next.
+func (s *Store) MarkReportRun(ctx context.Context, project, id string, ranAt, next time.Time) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE report_schedules SET last_run_at = ?, next_run_at = ? WHERE project = ? AND id = ?`,
+		ranAt.UTC().Format(time.RFC3339Nano), next.UTC().Format(time.RFC3339Nano), project, id,
+	)
+	if err != nil {
+		return fmt.Errorf("mark report run: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) DeleteReportSchedule(ctx context.Context, project, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM report_schedules WHERE project = ? AND id = ?`, project, id)
+	if err != nil {
+		return fmt.Errorf("delete report schedule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) queryReportSchedules(ctx context.Context, query string, args ...any) ([]core.ReportSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list report schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var out []core.ReportSchedule
+	for rows.Next() {
+		sched, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sched)
+	}
+	return out, rows.Err()
+}
+
+func scanReportSchedule(row scanner) (core.ReportSchedule, error) {
+	var r core.ReportSchedule
+	var kind, cadence, recipients string
+	var weekday, enabled int
+	var lastRun sql.NullString
+	var nextRun, createdAt, updatedAt string
+	err := row.Scan(&r.ID, &r.Project, &r.Name, &kind, &cadence, &r.Hour, &weekday, &recipients, &enabled,
+		&lastRun, &nextRun, &createdAt, &updatedAt)
+	if err != nil {
+		return core.ReportSchedule{}, fmt.Errorf("scan report schedule: %w", err)
+	}
+	r.Kind = core.ReportKind(kind)
+	r.Cadence = core.ReportCadence(cadence)
+	r.Weekday = time.Weekday(weekday)
+	r.Enabled = enabled != 0
+	_ = json.Unmarshal([]byte(recipients), &r.Recipients)
+	if lastRun.Valid {
+		if t, err := time.Parse(time.RFC3339Nano, lastRun.String); err == nil {
+			r.LastRunAt = &t
+		}
+	}
+	r.NextRunAt, _ = time.Parse(time.RFC3339Nano, nextRun)
+	r.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	r.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	return r, nil
+}
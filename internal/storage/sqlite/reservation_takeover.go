@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+const takeoverColumns = `id, project, reservation_id, holder, requester, reason, status, requested_at, deadline, resolved_at, new_reservation_id`
+
+// RequestReservationTakeover records t.Requester's request to take over
+// reservation t.ReservationID, answerable by its holder until t.Deadline.
+// The reservation must be active and held by someone else
+// (core.ErrNotFound otherwise); one request per reservation can be pending
+// at a time (core.ErrTakeoverPending).
+func (s *Store) RequestReservationTakeover(ctx context.Context, t core.ReservationTakeover) (core.ReservationTakeover, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("begin takeover request: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := clock.Now().UTC()
+	var project, holder string
+	err = tx.QueryRowContext(ctx,
+		`SELECT project, agent_id FROM file_reservations WHERE id = ? AND released_at IS NULL AND expires_ms > ?`,
+		t.ReservationID, unixMillis(now),
+	).Scan(&project, &holder)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && holder == t.Requester) {
+		return core.ReservationTakeover{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("get reservation for takeover: %w", err)
+	}
+	var pending int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM reservation_takeovers WHERE reservation_id = ? AND status = ?`,
+		t.ReservationID, string(core.TakeoverPending),
+	).Scan(&pending); err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("check pending takeover: %w", err)
+	}
+	if pending > 0 {
+		return core.ReservationTakeover{}, core.ErrTakeoverPending
+	}
+
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	t.Project = project
+	t.Holder = holder
+	t.Status = core.TakeoverPending
+	t.RequestedAt = now
+	t.ResolvedAt = nil
+	t.NewReservationID = ""
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO reservation_takeovers (id, project, reservation_id, holder, requester, reason, status, requested_at, deadline)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Project, t.ReservationID, t.Holder, t.Requester, t.Reason, string(t.Status),
+		t.RequestedAt.Format(time.RFC3339Nano), t.Deadline.UTC().Format(time.RFC3339Nano),
+	); err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("insert takeover: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("commit takeover request: %w", err)
+	}
+	return t, nil
+}
+
+// LatestReservationTakeover returns the most recent takeover request for a
+// reservation, or core.ErrNotFound when there has been none.
+func (s *Store) LatestReservationTakeover(ctx context.Context, reservationID string) (core.ReservationTakeover, error) {
+	return scanTakeover(s.db.QueryRowContext(ctx,
+		`SELECT `+takeoverColumns+` FROM reservation_takeovers
+		 WHERE reservation_id = ? ORDER BY requested_at DESC, id DESC LIMIT 1`,
+		reservationID,
+	))
+}
+
+// RespondReservationTakeover records the holder's answer to the pending
+// takeover of a reservation: accept hands the reservation over now, decline
+// keeps it. core.ErrNotFound when nothing is pending.
+func (s *Store) RespondReservationTakeover(ctx context.Context, reservationID string, accept bool) (core.ReservationTakeover, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("begin takeover response: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := scanTakeover(tx.QueryRowContext(ctx,
+		`SELECT `+takeoverColumns+` FROM reservation_takeovers WHERE reservation_id = ? AND status = ?`,
+		reservationID, string(core.TakeoverPending),
+	))
+	if err != nil {
+		return core.ReservationTakeover{}, err
+	}
+	var mirror *core.Reservation
+	if accept {
+		if mirror, err = transferReservationTx(ctx, tx, &t); err != nil {
+			return core.ReservationTakeover{}, err
+		}
+	} else if err := resolveTakeoverTx(ctx, tx, &t, core.TakeoverDeclined); err != nil {
+		return core.ReservationTakeover{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("commit takeover response: %w", err)
+	}
+	s.mirrorTransfer(t, mirror)
+	return t, nil
+}
+
+// ResolveReservationTakeovers transfers every reservation whose pending
+// takeover passed its deadline by now without an answer from the holder.
+// Requests for reservations that ended in the meantime lapse instead.
+func (s *Store) ResolveReservationTakeovers(ctx context.Context, now time.Time) ([]core.ReservationTakeover, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin resolve takeovers: %w", err)
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+takeoverColumns+` FROM reservation_takeovers WHERE status = ? AND deadline_ms <= ? ORDER BY deadline_ms`,
+		string(core.TakeoverPending), unixMillis(now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list due takeovers: %w", err)
+	}
+	var due []core.ReservationTakeover
+	for rows.Next() {
+		t, err := scanTakeover(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	mirrors := make([]*core.Reservation, len(due))
+	for i := range due {
+		if mirrors[i], err = transferReservationTx(ctx, tx, &due[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit resolve takeovers: %w", err)
+	}
+	for i, t := range due {
+		s.mirrorTransfer(t, mirrors[i])
+	}
+	return due, nil
+}
+
+// transferReservationTx releases t's reservation and grants the same
+// pattern to the requester for the holder's original TTL. A reservation
+// that is no longer active makes the takeover lapse; it returns the new
+// reservation, or nil when nothing moved.
+func transferReservationTx(ctx context.Context, tx dbTx, t *core.ReservationTakeover) (*core.Reservation, error) {
+	now := clock.Now().UTC()
+	var (
+		old                  core.Reservation
+		exclusive            int
+		reason               sql.NullString
+		createdAt, expiresAt string
+	)
+	err := tx.QueryRowContext(ctx,
+		`UPDATE file_reservations SET released_at = ?
+		 WHERE id = ? AND agent_id = ? AND released_at IS NULL AND expires_ms > ?
+		 RETURNING path_pattern, exclusive, reason, created_at, expires_at`,
+		now.Format(time.RFC3339Nano), t.ReservationID, t.Holder, unixMillis(now),
+	).Scan(&old.PathPattern, &exclusive, &reason, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, resolveTakeoverTx(ctx, tx, t, core.TakeoverLapsed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("release reservation for takeover: %w", err)
+	}
+	old.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	old.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+	ttl := old.ExpiresAt.Sub(old.CreatedAt)
+	if ttl > MaxReservationTTL {
+		ttl = MaxReservationTTL
+	}
+
+	next := &core.Reservation{
+		ID:          uuid.NewString(),
+		AgentID:     t.Requester,
+		Project:     t.Project,
+		PathPattern: old.PathPattern,
+		Exclusive:   exclusive == 1,
+		Reason:      reason.String,
+		TTL:         ttl,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO file_reservations (id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		next.ID, next.AgentID, next.Project, next.PathPattern, exclusive, next.Reason,
+		next.CreatedAt.Format(time.RFC3339Nano), next.ExpiresAt.Format(time.RFC3339Nano),
+	); err != nil {
+		return nil, fmt.Errorf("insert transferred reservation: %w", err)
+	}
+	t.NewReservationID = next.ID
+	return next, resolveTakeoverTx(ctx, tx, t, core.TakeoverTransferred)
+}
+
+func resolveTakeoverTx(ctx context.Context, tx dbTx, t *core.ReservationTakeover, status core.TakeoverStatus) error {
+	now := clock.Now().UTC()
+	t.Status = status
+	t.ResolvedAt = &now
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE reservation_takeovers SET status = ?, resolved_at = ?, new_reservation_id = ? WHERE id = ?`,
+		string(status), now.Format(time.RFC3339Nano), t.NewReservationID, t.ID,
+	); err != nil {
+		return fmt.Errorf("resolve takeover: %w", err)
+	}
+	return nil
+}
+
+// mirrorTransfer dual-writes a committed transfer to Intercore.
+func (s *Store) mirrorTransfer(t core.ReservationTakeover, next *core.Reservation) {
+	if s.bridge == nil || next == nil {
+		return
+	}
+	s.bridge.MirrorRelease(t.ReservationID)
+	s.bridge.MirrorReserve(next.ID, next.AgentID, next.Project, next.PathPattern, next.Exclusive, next.Reason,
+		int(next.TTL.Seconds()), next.CreatedAt, next.ExpiresAt)
+}
+
+func scanTakeover(row scanner) (core.ReservationTakeover, error) {
+	var (
+		t                     core.ReservationTakeover
+		status                string
+		requestedAt, deadline string
+		resolvedAt            sql.NullString
+	)
+	err := row.Scan(&t.ID, &t.Project, &t.ReservationID, &t.Holder, &t.Requester, &t.Reason, &status,
+		&requestedAt, &deadline, &resolvedAt, &t.NewReservationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.ReservationTakeover{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.ReservationTakeover{}, fmt.Errorf("scan takeover: %w", err)
+	}
+	t.Status = core.TakeoverStatus(status)
+	t.RequestedAt, _ = time.Parse(time.RFC3339Nano, requestedAt)
+	t.Deadline, _ = time.Parse(time.RFC3339Nano, deadline)
+	if resolvedAt.Valid {
+		ts, _ := time.Parse(time.RFC3339Nano, resolvedAt.String)
+		t.ResolvedAt = &ts
+	}
+	return t, nil
+}
@@ -14,25 +14,101 @@ var _ storage.DomainStore = (*ResilientStore)(nil)
 // ResilientStore wraps every method of *Store with CircuitBreaker + RetryOnDBLock
 // to provide resilience against transient SQLite errors (database-is-locked,
 // connection failures, etc.).
+//
+// Reads, writes and sweeps each go through their own breaker, so a failing
+// write path or sweep opens only its breaker and reads keep being served.
 type ResilientStore struct {
-	inner *Store
-	cb    *CircuitBreaker
+	inner  *Store
+	reads  *CircuitBreaker
+	writes *CircuitBreaker
+	sweeps *CircuitBreaker
 }
 
+// Breaker categories, as reported by CircuitBreakerStates.
+const (
+	BreakerReads  = "reads"
+	BreakerWrites = "writes"
+	BreakerSweeps = "sweeps"
+)
+
 // NewResilient creates a ResilientStore with default circuit breaker settings
-// (threshold=5, resetTimeout=30s).
+// (threshold=5, resetTimeout=30s) for each category.
 func NewResilient(inner *Store) *ResilientStore {
-	return &ResilientStore{inner: inner, cb: NewCircuitBreaker(5, 30*time.Second)}
+	return NewResilientWithSettings(inner, 5, 30*time.Second)
+}
+
+// NewResilientWithSettings creates a ResilientStore whose per-category
+// breakers all use threshold and resetTimeout.
+func NewResilientWithSettings(inner *Store, threshold int, resetTimeout time.Duration) *ResilientStore {
+	return &ResilientStore{
+		inner:  inner,
+		reads:  NewCircuitBreaker(threshold, resetTimeout),
+		writes: NewCircuitBreaker(threshold, resetTimeout),
+		sweeps: NewCircuitBreaker(threshold, resetTimeout),
+	}
 }
 
-// NewResilientWithBreaker creates a ResilientStore with a custom circuit breaker.
+// NewResilientWithBreaker creates a ResilientStore with a custom circuit
+// breaker shared by every category, so any failure counts against all of
+// them.
 func NewResilientWithBreaker(inner *Store, cb *CircuitBreaker) *ResilientStore {
-	return &ResilientStore{inner: inner, cb: cb}
+	return &ResilientStore{inner: inner, reads: cb, writes: cb, sweeps: cb}
 }
 
-// CircuitBreakerState returns the current state of the circuit breaker as a string.
+// CircuitBreakerState returns the worst state across the breakers as a
+// string: "open" if any is open, else "half_open" if any is probing.
 func (r *ResilientStore) CircuitBreakerState() string {
-	return r.cb.State().String()
+	worst := StateClosed
+	for _, cb := range []*CircuitBreaker{r.reads, r.writes, r.sweeps} {
+		switch st := cb.State(); {
+		case st == StateOpen:
+			return st.String()
+		case st == StateHalfOpen:
+			worst = st
+		}
+	}
+	return worst.String()
+}
+
+// CircuitBreakerStates returns each category's breaker state, keyed by
+// BreakerReads, BreakerWrites and BreakerSweeps.
+func (r *ResilientStore) CircuitBreakerStates() map[string]string {
+	return map[string]string{
+		BreakerReads:  r.reads.State().String(),
+		BreakerWrites: r.writes.State().String(),
+		BreakerSweeps: r.sweeps.State().String(),
+	}
+}
+
+// SetSystemNotifier reports each breaker tripping and recovering to n as
+// system.circuit_open and system.circuit_closed events naming the
+// breaker's category ("all" for one shared by every category). Call
+// before the store is used.
+func (r *ResilientStore) SetSystemNotifier(n SystemNotifier) {
+	if r.reads == r.writes && r.writes == r.sweeps {
+		r.reads.OnStateChange(breakerNotifier(n, "all"))
+		return
+	}
+	r.reads.OnStateChange(breakerNotifier(n, BreakerReads))
+	r.writes.OnStateChange(breakerNotifier(n, BreakerWrites))
+	r.sweeps.OnStateChange(breakerNotifier(n, BreakerSweeps))
+}
+
+// breakerNotifier turns a breaker's state changes into system events.
+// Half-open probes are not reported, nor is a failed probe reopening it.
+func breakerNotifier(n SystemNotifier, breaker string) func(from, to BreakerState) {
+	return func(from, to BreakerState) {
+		var typ core.EventType
+		switch {
+		case from == StateClosed && to == StateOpen:
+			typ = core.EventSystemCircuitOpen
+		case to == StateClosed:
+			typ = core.EventSystemCircuitClosed
+		default:
+			return
+		}
+		n.NotifySystem(core.SystemEvent{Type: typ, At: time.Now().UTC(), Data: map[string]any{"breaker": breaker}})
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -41,7 +117,7 @@ func (r *ResilientStore) CircuitBreakerState() string {
 
 func (r *ResilientStore) AppendEvent(ctx context.Context, ev storage.Event) (uint64, error) {
 	var result uint64
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.AppendEvent(ctx, ev)
@@ -51,9 +127,46 @@ func (r *ResilientStore) AppendEvent(ctx context.Context, ev storage.Event) (uin
 	return result, err
 }
 
+func (r *ResilientStore) PutBlob(ctx context.Context, blob core.Blob, data []byte) (core.Blob, error) {
+	var result core.Blob
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.PutBlob(ctx, blob, data)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetBlob(ctx context.Context, project, id string) (core.Blob, []byte, error) {
+	var result core.Blob
+	var data []byte
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, data, innerErr = r.inner.GetBlob(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, data, err
+}
+
+func (r *ResilientStore) BlobInfo(ctx context.Context, project, id string) (core.Blob, error) {
+	var result core.Blob
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.BlobInfo(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 func (r *ResilientStore) AppendEvents(ctx context.Context, evs ...storage.Event) ([]uint64, error) {
 	var result []uint64
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.AppendEvents(ctx, evs...)
@@ -65,7 +178,7 @@ func (r *ResilientStore) AppendEvents(ctx context.Context, evs ...storage.Event)
 
 func (r *ResilientStore) InboxSince(ctx context.Context, project, agent string, cursor uint64, limit int) ([]core.Message, error) {
 	var result []core.Message
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.InboxSince(ctx, project, agent, cursor, limit)
@@ -77,7 +190,7 @@ func (r *ResilientStore) InboxSince(ctx context.Context, project, agent string,
 
 func (r *ResilientStore) ThreadMessages(ctx context.Context, project, threadID string, cursor uint64) ([]core.Message, error) {
 	var result []core.Message
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ThreadMessages(ctx, project, threadID, cursor)
@@ -87,9 +200,21 @@ func (r *ResilientStore) ThreadMessages(ctx context.Context, project, threadID s
 	return result, err
 }
 
+func (r *ResilientStore) GetMessage(ctx context.Context, project, messageID string) (core.Message, error) {
+	var result core.Message
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetMessage(ctx, project, messageID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 func (r *ResilientStore) ListThreads(ctx context.Context, project, agent string, cursor uint64, limit int) ([]storage.ThreadSummary, error) {
 	var result []storage.ThreadSummary
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListThreads(ctx, project, agent, cursor, limit)
@@ -101,7 +226,7 @@ func (r *ResilientStore) ListThreads(ctx context.Context, project, agent string,
 
 func (r *ResilientStore) RegisterAgent(ctx context.Context, agent core.Agent) (core.Agent, error) {
 	var result core.Agent
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.RegisterAgent(ctx, agent)
@@ -113,7 +238,7 @@ func (r *ResilientStore) RegisterAgent(ctx context.Context, agent core.Agent) (c
 
 func (r *ResilientStore) Heartbeat(ctx context.Context, project, agentID string) (core.Agent, error) {
 	var result core.Agent
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.Heartbeat(ctx, project, agentID)
@@ -125,7 +250,7 @@ func (r *ResilientStore) Heartbeat(ctx context.Context, project, agentID string)
 
 func (r *ResilientStore) UpdateAgentMetadata(ctx context.Context, agentID string, meta map[string]string) (core.Agent, error) {
 	var result core.Agent
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpdateAgentMetadata(ctx, agentID, meta)
@@ -137,7 +262,7 @@ func (r *ResilientStore) UpdateAgentMetadata(ctx context.Context, agentID string
 
 func (r *ResilientStore) ListAgents(ctx context.Context, project string, capabilities []string) ([]core.Agent, error) {
 	var result []core.Agent
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListAgents(ctx, project, capabilities)
@@ -148,15 +273,27 @@ func (r *ResilientStore) ListAgents(ctx context.Context, project string, capabil
 }
 
 func (r *ResilientStore) MarkRead(ctx context.Context, project, messageID, agentID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.MarkRead(ctx, project, messageID, agentID)
 		})
 	})
 }
 
+func (r *ResilientStore) MarkThreadRead(ctx context.Context, project, threadID, agentID string) (int, error) {
+	var n int
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var err error
+			n, err = r.inner.MarkThreadRead(ctx, project, threadID, agentID)
+			return err
+		})
+	})
+	return n, err
+}
+
 func (r *ResilientStore) MarkAck(ctx context.Context, project, messageID, agentID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.MarkAck(ctx, project, messageID, agentID)
 		})
@@ -165,7 +302,7 @@ func (r *ResilientStore) MarkAck(ctx context.Context, project, messageID, agentI
 
 func (r *ResilientStore) RecipientStatus(ctx context.Context, project, messageID string) (map[string]*core.RecipientStatus, error) {
 	var result map[string]*core.RecipientStatus
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.RecipientStatus(ctx, project, messageID)
@@ -175,9 +312,24 @@ func (r *ResilientStore) RecipientStatus(ctx context.Context, project, messageID
 	return result, err
 }
 
+func (r *ResilientStore) AckQuorum(ctx context.Context, project, messageID string) (core.AckProgress, bool, error) {
+	var (
+		progress core.AckProgress
+		reached  bool
+	)
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			progress, reached, innerErr = r.inner.AckQuorum(ctx, project, messageID)
+			return innerErr
+		})
+	})
+	return progress, reached, err
+}
+
 func (r *ResilientStore) InboxCounts(ctx context.Context, project, agentID string) (int, int, error) {
 	var total, unread int
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			total, unread, innerErr = r.inner.InboxCounts(ctx, project, agentID)
@@ -189,7 +341,7 @@ func (r *ResilientStore) InboxCounts(ctx context.Context, project, agentID strin
 
 func (r *ResilientStore) InboxStaleAcks(ctx context.Context, project, agentID string, ttlSeconds, limit int) ([]core.StaleAck, error) {
 	var result []core.StaleAck
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.InboxStaleAcks(ctx, project, agentID, ttlSeconds, limit)
@@ -199,9 +351,33 @@ func (r *ResilientStore) InboxStaleAcks(ctx context.Context, project, agentID st
 	return result, err
 }
 
+func (r *ResilientStore) CurrentCursor(ctx context.Context) (uint64, error) {
+	var result uint64
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CurrentCursor(ctx)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) InboxUnread(ctx context.Context, project, agentID string, importance []string, limit int) ([]core.Message, error) {
+	var result []core.Message
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.InboxUnread(ctx, project, agentID, importance, limit)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 func (r *ResilientStore) Reserve(ctx context.Context, res core.Reservation) (*core.Reservation, error) {
 	var result *core.Reservation
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.Reserve(ctx, res)
@@ -213,7 +389,7 @@ func (r *ResilientStore) Reserve(ctx context.Context, res core.Reservation) (*co
 
 func (r *ResilientStore) GetReservation(ctx context.Context, id string) (*core.Reservation, error) {
 	var result *core.Reservation
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetReservation(ctx, id)
@@ -224,7 +400,7 @@ func (r *ResilientStore) GetReservation(ctx context.Context, id string) (*core.R
 }
 
 func (r *ResilientStore) ReleaseReservation(ctx context.Context, id, agentID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.ReleaseReservation(ctx, id, agentID)
 		})
@@ -233,7 +409,7 @@ func (r *ResilientStore) ReleaseReservation(ctx context.Context, id, agentID str
 
 func (r *ResilientStore) ActiveReservations(ctx context.Context, project string) ([]core.Reservation, error) {
 	var result []core.Reservation
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ActiveReservations(ctx, project)
@@ -245,7 +421,7 @@ func (r *ResilientStore) ActiveReservations(ctx context.Context, project string)
 
 func (r *ResilientStore) AgentReservations(ctx context.Context, agentID string) ([]core.Reservation, error) {
 	var result []core.Reservation
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.AgentReservations(ctx, agentID)
@@ -260,7 +436,7 @@ func (r *ResilientStore) AgentReservations(ctx context.Context, agentID string)
 // ---------------------------------------------------------------------------
 
 func (r *ResilientStore) SetContactPolicy(ctx context.Context, agentID string, policy core.ContactPolicy) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.SetContactPolicy(ctx, agentID, policy)
 		})
@@ -269,7 +445,7 @@ func (r *ResilientStore) SetContactPolicy(ctx context.Context, agentID string, p
 
 func (r *ResilientStore) GetContactPolicy(ctx context.Context, agentID string) (core.ContactPolicy, error) {
 	var result core.ContactPolicy
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetContactPolicy(ctx, agentID)
@@ -280,7 +456,7 @@ func (r *ResilientStore) GetContactPolicy(ctx context.Context, agentID string) (
 }
 
 func (r *ResilientStore) SetAgentFocusState(ctx context.Context, agentID, state string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.SetAgentFocusState(ctx, agentID, state)
 		})
@@ -292,7 +468,7 @@ func (r *ResilientStore) GetAgentFocusState(ctx context.Context, agentID string)
 		state     string
 		updatedAt time.Time
 	)
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			state, updatedAt, innerErr = r.inner.GetAgentFocusState(ctx, agentID)
@@ -304,7 +480,7 @@ func (r *ResilientStore) GetAgentFocusState(ctx context.Context, agentID string)
 
 func (r *ResilientStore) GetLiveContactPolicy(ctx context.Context, agentID string) (core.ContactPolicy, error) {
 	var result core.ContactPolicy
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetLiveContactPolicy(ctx, agentID)
@@ -315,7 +491,7 @@ func (r *ResilientStore) GetLiveContactPolicy(ctx context.Context, agentID strin
 }
 
 func (r *ResilientStore) SetLiveContactPolicy(ctx context.Context, agentID string, policy core.ContactPolicy) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.SetLiveContactPolicy(ctx, agentID, policy)
 		})
@@ -324,7 +500,7 @@ func (r *ResilientStore) SetLiveContactPolicy(ctx context.Context, agentID strin
 
 func (r *ResilientStore) ListPendingPokes(ctx context.Context, project, recipient string) ([]storage.PendingPoke, error) {
 	var result []storage.PendingPoke
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListPendingPokes(ctx, project, recipient)
@@ -335,7 +511,7 @@ func (r *ResilientStore) ListPendingPokes(ctx context.Context, project, recipien
 }
 
 func (r *ResilientStore) MarkPokeSurfaced(ctx context.Context, project, recipient, messageID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.MarkPokeSurfaced(ctx, project, recipient, messageID)
 		})
@@ -343,7 +519,7 @@ func (r *ResilientStore) MarkPokeSurfaced(ctx context.Context, project, recipien
 }
 
 func (r *ResilientStore) MarkMessageInjected(ctx context.Context, project, messageID, recipient string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.MarkMessageInjected(ctx, project, messageID, recipient)
 		})
@@ -352,7 +528,7 @@ func (r *ResilientStore) MarkMessageInjected(ctx context.Context, project, messa
 
 func (r *ResilientStore) LiveTransportEnabled(ctx context.Context) (bool, error) {
 	var result bool
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.LiveTransportEnabled(ctx)
@@ -363,7 +539,7 @@ func (r *ResilientStore) LiveTransportEnabled(ctx context.Context) (bool, error)
 }
 
 func (r *ResilientStore) SetLiveTransportEnabled(ctx context.Context, enabled bool) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.SetLiveTransportEnabled(ctx, enabled)
 		})
@@ -372,7 +548,7 @@ func (r *ResilientStore) SetLiveTransportEnabled(ctx context.Context, enabled bo
 
 func (r *ResilientStore) UpsertWindowIdentityWithToken(ctx context.Context, wi core.WindowIdentity, token string) (*core.WindowIdentity, error) {
 	var result *core.WindowIdentity
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpsertWindowIdentityWithToken(ctx, wi, token)
@@ -383,7 +559,7 @@ func (r *ResilientStore) UpsertWindowIdentityWithToken(ctx context.Context, wi c
 }
 
 func (r *ResilientStore) AddContact(ctx context.Context, agentID, contactAgentID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.AddContact(ctx, agentID, contactAgentID)
 		})
@@ -391,7 +567,7 @@ func (r *ResilientStore) AddContact(ctx context.Context, agentID, contactAgentID
 }
 
 func (r *ResilientStore) RemoveContact(ctx context.Context, agentID, contactAgentID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.RemoveContact(ctx, agentID, contactAgentID)
 		})
@@ -400,7 +576,7 @@ func (r *ResilientStore) RemoveContact(ctx context.Context, agentID, contactAgen
 
 func (r *ResilientStore) ListContacts(ctx context.Context, agentID string) ([]string, error) {
 	var result []string
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListContacts(ctx, agentID)
@@ -412,7 +588,7 @@ func (r *ResilientStore) ListContacts(ctx context.Context, agentID string) ([]st
 
 func (r *ResilientStore) IsContact(ctx context.Context, agentID, senderID string) (bool, error) {
 	var result bool
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.IsContact(ctx, agentID, senderID)
@@ -422,9 +598,157 @@ func (r *ResilientStore) IsContact(ctx context.Context, agentID, senderID string
 	return result, err
 }
 
+func (r *ResilientStore) SnoozeMessage(ctx context.Context, project, messageID, agentID string, until time.Time) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.SnoozeMessage(ctx, project, messageID, agentID, until)
+		})
+	})
+}
+
+func (r *ResilientStore) UnsnoozeMessage(ctx context.Context, project, messageID, agentID string) (core.SnoozeWake, error) {
+	var result core.SnoozeWake
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UnsnoozeMessage(ctx, project, messageID, agentID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListSnoozed(ctx context.Context, project, agentID string) ([]core.SnoozedMessage, error) {
+	var result []core.SnoozedMessage
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListSnoozed(ctx, project, agentID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) WakeSnoozed(ctx context.Context, project, agentID string, now time.Time) ([]core.SnoozeWake, error) {
+	var result []core.SnoozeWake
+	err := r.sweeps.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.WakeSnoozed(ctx, project, agentID, now)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) CreateContactGroup(ctx context.Context, group core.ContactGroup) (core.ContactGroup, error) {
+	var result core.ContactGroup
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateContactGroup(ctx, group)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetContactGroup(ctx context.Context, project, name string) (core.ContactGroup, error) {
+	var result core.ContactGroup
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetContactGroup(ctx, project, name)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListContactGroups(ctx context.Context, project string) ([]core.ContactGroup, error) {
+	var result []core.ContactGroup
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListContactGroups(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateContactGroup(ctx context.Context, group core.ContactGroup) (core.ContactGroup, error) {
+	var result core.ContactGroup
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateContactGroup(ctx, group)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteContactGroup(ctx context.Context, project, name string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteContactGroup(ctx, project, name)
+		})
+	})
+}
+
+func (r *ResilientStore) AddContactGroupMember(ctx context.Context, project, name, agentID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.AddContactGroupMember(ctx, project, name, agentID)
+		})
+	})
+}
+
+func (r *ResilientStore) RemoveContactGroupMember(ctx context.Context, project, name, agentID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.RemoveContactGroupMember(ctx, project, name, agentID)
+		})
+	})
+}
+
+func (r *ResilientStore) SetCapability(ctx context.Context, c core.Capability) (core.Capability, error) {
+	var result core.Capability
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.SetCapability(ctx, c)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListCapabilities(ctx context.Context, project string) ([]core.Capability, error) {
+	var result []core.Capability
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListCapabilities(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteCapability(ctx context.Context, project, name string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteCapability(ctx, project, name)
+		})
+	})
+}
+
 func (r *ResilientStore) HasReservationOverlap(ctx context.Context, project, agentA, agentB string) (bool, error) {
 	var result bool
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.HasReservationOverlap(ctx, project, agentA, agentB)
@@ -436,7 +760,7 @@ func (r *ResilientStore) HasReservationOverlap(ctx context.Context, project, age
 
 func (r *ResilientStore) IsThreadParticipant(ctx context.Context, project, threadID, agent string) (bool, error) {
 	var result bool
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.IsThreadParticipant(ctx, project, threadID, agent)
@@ -448,7 +772,7 @@ func (r *ResilientStore) IsThreadParticipant(ctx context.Context, project, threa
 
 func (r *ResilientStore) TopicMessages(ctx context.Context, project, topic string, cursor uint64, limit int) ([]core.Message, error) {
 	var result []core.Message
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.TopicMessages(ctx, project, topic, cursor, limit)
@@ -466,7 +790,7 @@ func (r *ResilientStore) TopicMessages(ctx context.Context, project, topic strin
 
 func (r *ResilientStore) CreateSpec(ctx context.Context, spec core.Spec) (core.Spec, error) {
 	var result core.Spec
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.CreateSpec(ctx, spec)
@@ -478,7 +802,7 @@ func (r *ResilientStore) CreateSpec(ctx context.Context, spec core.Spec) (core.S
 
 func (r *ResilientStore) GetSpec(ctx context.Context, project, id string) (core.Spec, error) {
 	var result core.Spec
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetSpec(ctx, project, id)
@@ -490,7 +814,7 @@ func (r *ResilientStore) GetSpec(ctx context.Context, project, id string) (core.
 
 func (r *ResilientStore) ListSpecs(ctx context.Context, project, status string) ([]core.Spec, error) {
 	var result []core.Spec
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListSpecs(ctx, project, status)
@@ -502,7 +826,7 @@ func (r *ResilientStore) ListSpecs(ctx context.Context, project, status string)
 
 func (r *ResilientStore) UpdateSpec(ctx context.Context, spec core.Spec) (core.Spec, error) {
 	var result core.Spec
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpdateSpec(ctx, spec)
@@ -512,19 +836,79 @@ func (r *ResilientStore) UpdateSpec(ctx context.Context, spec core.Spec) (core.S
 	return result, err
 }
 
+func (r *ResilientStore) ReviewSpec(ctx context.Context, project, id string) (core.Spec, error) {
+	var result core.Spec
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ReviewSpec(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 func (r *ResilientStore) DeleteSpec(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.DeleteSpec(ctx, project, id)
 		})
 	})
 }
 
+func (r *ResilientStore) PublishSpec(ctx context.Context, project, specID, publishedBy string) (core.PublishedSpec, error) {
+	var result core.PublishedSpec
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.PublishSpec(ctx, project, specID, publishedBy)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetPublishedSpec(ctx context.Context, project, specID string, number int) (core.PublishedSpec, error) {
+	var result core.PublishedSpec
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetPublishedSpec(ctx, project, specID, number)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListPublishedSpecs(ctx context.Context, project, specID string) ([]core.PublishedSpec, error) {
+	var result []core.PublishedSpec
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListPublishedSpecs(ctx, project, specID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetSpecRevision(ctx context.Context, project, specID string, version int64) (core.Spec, error) {
+	var result core.Spec
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetSpecRevision(ctx, project, specID, version)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 // Epic operations
 
 func (r *ResilientStore) CreateEpic(ctx context.Context, epic core.Epic) (core.Epic, error) {
 	var result core.Epic
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.CreateEpic(ctx, epic)
@@ -536,7 +920,7 @@ func (r *ResilientStore) CreateEpic(ctx context.Context, epic core.Epic) (core.E
 
 func (r *ResilientStore) GetEpic(ctx context.Context, project, id string) (core.Epic, error) {
 	var result core.Epic
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetEpic(ctx, project, id)
@@ -548,7 +932,7 @@ func (r *ResilientStore) GetEpic(ctx context.Context, project, id string) (core.
 
 func (r *ResilientStore) ListEpics(ctx context.Context, project, specID string) ([]core.Epic, error) {
 	var result []core.Epic
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListEpics(ctx, project, specID)
@@ -560,7 +944,7 @@ func (r *ResilientStore) ListEpics(ctx context.Context, project, specID string)
 
 func (r *ResilientStore) UpdateEpic(ctx context.Context, epic core.Epic) (core.Epic, error) {
 	var result core.Epic
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpdateEpic(ctx, epic)
@@ -571,7 +955,7 @@ func (r *ResilientStore) UpdateEpic(ctx context.Context, epic core.Epic) (core.E
 }
 
 func (r *ResilientStore) DeleteEpic(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.DeleteEpic(ctx, project, id)
 		})
@@ -582,7 +966,7 @@ func (r *ResilientStore) DeleteEpic(ctx context.Context, project, id string) err
 
 func (r *ResilientStore) CreateStory(ctx context.Context, story core.Story) (core.Story, error) {
 	var result core.Story
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.CreateStory(ctx, story)
@@ -594,7 +978,7 @@ func (r *ResilientStore) CreateStory(ctx context.Context, story core.Story) (cor
 
 func (r *ResilientStore) GetStory(ctx context.Context, project, id string) (core.Story, error) {
 	var result core.Story
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetStory(ctx, project, id)
@@ -606,7 +990,7 @@ func (r *ResilientStore) GetStory(ctx context.Context, project, id string) (core
 
 func (r *ResilientStore) ListStories(ctx context.Context, project, epicID string) ([]core.Story, error) {
 	var result []core.Story
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListStories(ctx, project, epicID)
@@ -618,7 +1002,7 @@ func (r *ResilientStore) ListStories(ctx context.Context, project, epicID string
 
 func (r *ResilientStore) UpdateStory(ctx context.Context, story core.Story) (core.Story, error) {
 	var result core.Story
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpdateStory(ctx, story)
@@ -628,8 +1012,20 @@ func (r *ResilientStore) UpdateStory(ctx context.Context, story core.Story) (cor
 	return result, err
 }
 
+func (r *ResilientStore) RankStory(ctx context.Context, project, id, after, before string) (core.Story, error) {
+	var result core.Story
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.RankStory(ctx, project, id, after, before)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 func (r *ResilientStore) DeleteStory(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.DeleteStory(ctx, project, id)
 		})
@@ -640,7 +1036,7 @@ func (r *ResilientStore) DeleteStory(ctx context.Context, project, id string) er
 
 func (r *ResilientStore) CreateTask(ctx context.Context, task core.Task) (core.Task, error) {
 	var result core.Task
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.CreateTask(ctx, task)
@@ -652,7 +1048,7 @@ func (r *ResilientStore) CreateTask(ctx context.Context, task core.Task) (core.T
 
 func (r *ResilientStore) GetTask(ctx context.Context, project, id string) (core.Task, error) {
 	var result core.Task
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.GetTask(ctx, project, id)
@@ -664,7 +1060,7 @@ func (r *ResilientStore) GetTask(ctx context.Context, project, id string) (core.
 
 func (r *ResilientStore) ListTasks(ctx context.Context, project, status, agent string) ([]core.Task, error) {
 	var result []core.Task
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListTasks(ctx, project, status, agent)
@@ -674,9 +1070,21 @@ func (r *ResilientStore) ListTasks(ctx context.Context, project, status, agent s
 	return result, err
 }
 
+func (r *ResilientStore) QueryTasks(ctx context.Context, q core.TaskQuery) ([]core.Task, error) {
+	var result []core.Task
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.QueryTasks(ctx, q)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
 func (r *ResilientStore) UpdateTask(ctx context.Context, task core.Task) (core.Task, error) {
 	var result core.Task
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpdateTask(ctx, task)
@@ -687,222 +1095,818 @@ func (r *ResilientStore) UpdateTask(ctx context.Context, task core.Task) (core.T
 }
 
 func (r *ResilientStore) DeleteTask(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.DeleteTask(ctx, project, id)
 		})
 	})
 }
 
-// Insight operations
-
-func (r *ResilientStore) CreateInsight(ctx context.Context, insight core.Insight) (core.Insight, error) {
-	var result core.Insight
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) CreateTaskComment(ctx context.Context, comment core.TaskComment) (core.TaskComment, error) {
+	var result core.TaskComment
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.CreateInsight(ctx, insight)
+			result, innerErr = r.inner.CreateTaskComment(ctx, comment)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) GetInsight(ctx context.Context, project, id string) (core.Insight, error) {
-	var result core.Insight
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) ListTaskComments(ctx context.Context, project, taskID string, after uint64, limit int) ([]core.TaskComment, error) {
+	var result []core.TaskComment
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.GetInsight(ctx, project, id)
+			result, innerErr = r.inner.ListTaskComments(ctx, project, taskID, after, limit)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) ListInsights(ctx context.Context, project, specID, category string) ([]core.Insight, error) {
-	var result []core.Insight
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) DeleteTaskComment(ctx context.Context, project, taskID, id string) error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			var innerErr error
-			result, innerErr = r.inner.ListInsights(ctx, project, specID, category)
-			return innerErr
+			return r.inner.DeleteTaskComment(ctx, project, taskID, id)
 		})
 	})
-	return result, err
 }
 
-func (r *ResilientStore) LinkInsightToSpec(ctx context.Context, project, insightID, specID string) error {
-	return r.cb.Execute(func() error {
+func (r *ResilientStore) ClaimTasks(ctx context.Context, claim core.TaskClaim) ([]core.Task, error) {
+	var result []core.Task
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			return r.inner.LinkInsightToSpec(ctx, project, insightID, specID)
+			var innerErr error
+			result, innerErr = r.inner.ClaimTasks(ctx, claim)
+			return innerErr
 		})
 	})
+	return result, err
 }
 
-func (r *ResilientStore) DeleteInsight(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+func (r *ResilientStore) SessionHistory(ctx context.Context, project string, since, until time.Time) ([]core.SessionRecord, error) {
+	var result []core.SessionRecord
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			return r.inner.DeleteInsight(ctx, project, id)
+			var innerErr error
+			result, innerErr = r.inner.SessionHistory(ctx, project, since, until)
+			return innerErr
 		})
 	})
+	return result, err
 }
 
-// Session operations
-
-func (r *ResilientStore) CreateSession(ctx context.Context, session core.Session) (core.Session, error) {
-	var result core.Session
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) UnblockReadyTasks(ctx context.Context, project, externalRef string) ([]core.Task, error) {
+	var result []core.Task
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.CreateSession(ctx, session)
+			result, innerErr = r.inner.UnblockReadyTasks(ctx, project, externalRef)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) GetSession(ctx context.Context, project, id string) (core.Session, error) {
+func (r *ResilientStore) RenewTaskLease(ctx context.Context, project, id string, expiresAt time.Time) (core.TaskLease, error) {
+	var result core.TaskLease
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.RenewTaskLease(ctx, project, id, expiresAt)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ReleaseTaskLease(ctx context.Context, project, id string) ([]core.Task, error) {
+	var result []core.Task
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ReleaseTaskLease(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) TaskConflicts(ctx context.Context, project, taskID string) ([]core.TaskConflict, error) {
+	var result []core.TaskConflict
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.TaskConflicts(ctx, project, taskID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+// Insight operations
+
+func (r *ResilientStore) CreateInsight(ctx context.Context, insight core.Insight) (core.Insight, error) {
+	var result core.Insight
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateInsight(ctx, insight)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetInsight(ctx context.Context, project, id string) (core.Insight, error) {
+	var result core.Insight
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetInsight(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListInsights(ctx context.Context, project, specID, category string) ([]core.Insight, error) {
+	var result []core.Insight
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListInsights(ctx, project, specID, category)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateInsight(ctx context.Context, insight core.Insight) (core.Insight, error) {
+	var result core.Insight
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateInsight(ctx, insight)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) LinkInsightToSpec(ctx context.Context, project, insightID, specID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.LinkInsightToSpec(ctx, project, insightID, specID)
+		})
+	})
+}
+
+func (r *ResilientStore) FindInsightByHash(ctx context.Context, project, hash string) (core.Insight, error) {
+	var result core.Insight
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.FindInsightByHash(ctx, project, hash)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) MergeInsights(ctx context.Context, project, targetID string, duplicates []string) (core.Insight, error) {
+	var result core.Insight
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.MergeInsights(ctx, project, targetID, duplicates)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteInsight(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteInsight(ctx, project, id)
+		})
+	})
+}
+
+// Insight routing rule operations
+
+func (r *ResilientStore) CreateInsightRule(ctx context.Context, rule core.InsightRule) (core.InsightRule, error) {
+	var result core.InsightRule
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateInsightRule(ctx, rule)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetInsightRule(ctx context.Context, project, id string) (core.InsightRule, error) {
+	var result core.InsightRule
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetInsightRule(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListInsightRules(ctx context.Context, project string) ([]core.InsightRule, error) {
+	var result []core.InsightRule
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListInsightRules(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateInsightRule(ctx context.Context, rule core.InsightRule) (core.InsightRule, error) {
+	var result core.InsightRule
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateInsightRule(ctx, rule)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteInsightRule(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteInsightRule(ctx, project, id)
+		})
+	})
+}
+
+// Report schedule operations
+
+func (r *ResilientStore) CreateReportSchedule(ctx context.Context, sched core.ReportSchedule) (core.ReportSchedule, error) {
+	var result core.ReportSchedule
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateReportSchedule(ctx, sched)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) CreateLifecycleHook(ctx context.Context, hook core.LifecycleHook) (core.LifecycleHook, error) {
+	var result core.LifecycleHook
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateLifecycleHook(ctx, hook)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetLifecycleHook(ctx context.Context, project, id string) (core.LifecycleHook, error) {
+	var result core.LifecycleHook
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetLifecycleHook(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListLifecycleHooks(ctx context.Context, project string) ([]core.LifecycleHook, error) {
+	var result []core.LifecycleHook
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListLifecycleHooks(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateLifecycleHook(ctx context.Context, hook core.LifecycleHook) (core.LifecycleHook, error) {
+	var result core.LifecycleHook
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateLifecycleHook(ctx, hook)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteLifecycleHook(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteLifecycleHook(ctx, project, id)
+		})
+	})
+}
+
+func (r *ResilientStore) RecordHookRun(ctx context.Context, run core.HookRun) (core.HookRun, error) {
+	var result core.HookRun
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.RecordHookRun(ctx, run)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListHookRuns(ctx context.Context, project, hookID string, limit int) ([]core.HookRun, error) {
+	var result []core.HookRun
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListHookRuns(ctx, project, hookID, limit)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetReportSchedule(ctx context.Context, project, id string) (core.ReportSchedule, error) {
+	var result core.ReportSchedule
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetReportSchedule(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListReportSchedules(ctx context.Context, project string) ([]core.ReportSchedule, error) {
+	var result []core.ReportSchedule
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListReportSchedules(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateReportSchedule(ctx context.Context, sched core.ReportSchedule) (core.ReportSchedule, error) {
+	var result core.ReportSchedule
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateReportSchedule(ctx, sched)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteReportSchedule(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteReportSchedule(ctx, project, id)
+		})
+	})
+}
+
+func (r *ResilientStore) DueReportSchedules(ctx context.Context, now time.Time) ([]core.ReportSchedule, error) {
+	var result []core.ReportSchedule
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.DueReportSchedules(ctx, now)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) MarkReportRun(ctx context.Context, project, id string, ranAt, next time.Time) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.MarkReportRun(ctx, project, id, ranAt, next)
+		})
+	})
+}
+
+// Session operations
+
+func (r *ResilientStore) CreateSession(ctx context.Context, session core.Session) (core.Session, error) {
 	var result core.Session
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateSession(ctx, session)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetSession(ctx context.Context, project, id string) (core.Session, error) {
+	var result core.Session
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetSession(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListSessions(ctx context.Context, project, status string) ([]core.Session, error) {
+	var result []core.Session
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListSessions(ctx, project, status)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateSession(ctx context.Context, session core.Session) (core.Session, error) {
+	var result core.Session
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateSession(ctx, session)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteSession(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteSession(ctx, project, id)
+		})
+	})
+}
+
+func (r *ResilientStore) HeartbeatSession(ctx context.Context, project, id string) (core.Session, error) {
+	var result core.Session
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.HeartbeatSession(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+// CUJ (Critical User Journey) operations
+
+func (r *ResilientStore) CreateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
+	var result core.CriticalUserJourney
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateCUJ(ctx, cuj)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetCUJ(ctx context.Context, project, id string) (core.CriticalUserJourney, error) {
+	var result core.CriticalUserJourney
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetCUJ(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListCUJs(ctx context.Context, project, specID string) ([]core.CriticalUserJourney, error) {
+	var result []core.CriticalUserJourney
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListCUJs(ctx, project, specID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
+	var result core.CriticalUserJourney
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateCUJ(ctx, cuj)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteCUJ(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteCUJ(ctx, project, id)
+		})
+	})
+}
+
+func (r *ResilientStore) LinkCUJToFeature(ctx context.Context, project, cujID, featureID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.LinkCUJToFeature(ctx, project, cujID, featureID)
+		})
+	})
+}
+
+func (r *ResilientStore) UnlinkCUJFromFeature(ctx context.Context, project, cujID, featureID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.UnlinkCUJFromFeature(ctx, project, cujID, featureID)
+		})
+	})
+}
+
+func (r *ResilientStore) GetCUJFeatureLinks(ctx context.Context, project, cujID string) ([]core.CUJFeatureLink, error) {
+	var result []core.CUJFeatureLink
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetCUJFeatureLinks(ctx, project, cujID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+// Goal (OKR) operations
+
+func (r *ResilientStore) CreateGoal(ctx context.Context, goal core.Goal) (core.Goal, error) {
+	var result core.Goal
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateGoal(ctx, goal)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetGoal(ctx context.Context, project, id string) (core.Goal, error) {
+	var result core.Goal
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetGoal(ctx, project, id)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListGoals(ctx context.Context, project, status string) ([]core.Goal, error) {
+	var result []core.Goal
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListGoals(ctx, project, status)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateGoal(ctx context.Context, goal core.Goal) (core.Goal, error) {
+	var result core.Goal
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateGoal(ctx, goal)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteGoal(ctx context.Context, project, id string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteGoal(ctx, project, id)
+		})
+	})
+}
+
+func (r *ResilientStore) LinkGoal(ctx context.Context, project, goalID, entityType, entityID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.LinkGoal(ctx, project, goalID, entityType, entityID)
+		})
+	})
+}
+
+func (r *ResilientStore) UnlinkGoal(ctx context.Context, project, goalID, entityType, entityID string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.UnlinkGoal(ctx, project, goalID, entityType, entityID)
+		})
+	})
+}
+
+func (r *ResilientStore) GetGoalLinks(ctx context.Context, project, goalID string) ([]core.GoalLink, error) {
+	var result []core.GoalLink
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetGoalLinks(ctx, project, goalID)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+// Feature flag operations
+
+func (r *ResilientStore) SetFeatureFlag(ctx context.Context, flag core.FeatureFlag) (core.FeatureFlag, error) {
+	var result core.FeatureFlag
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.GetSession(ctx, project, id)
+			result, innerErr = r.inner.SetFeatureFlag(ctx, flag)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) ListSessions(ctx context.Context, project, status string) ([]core.Session, error) {
-	var result []core.Session
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) ListFeatureFlags(ctx context.Context) ([]core.FeatureFlag, error) {
+	var result []core.FeatureFlag
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.ListSessions(ctx, project, status)
+			result, innerErr = r.inner.ListFeatureFlags(ctx)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) UpdateSession(ctx context.Context, session core.Session) (core.Session, error) {
-	var result core.Session
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) EffectiveFeatureFlags(ctx context.Context, project string) (map[string]bool, error) {
+	var result map[string]bool
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.UpdateSession(ctx, session)
+			result, innerErr = r.inner.EffectiveFeatureFlags(ctx, project)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) DeleteSession(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+func (r *ResilientStore) DeleteFeatureFlag(ctx context.Context, project, name string) error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			return r.inner.DeleteSession(ctx, project, id)
+			return r.inner.DeleteFeatureFlag(ctx, project, name)
 		})
 	})
 }
 
-// CUJ (Critical User Journey) operations
-
-func (r *ResilientStore) CreateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
-	var result core.CriticalUserJourney
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) AdminOverview(ctx context.Context) (core.AdminOverview, error) {
+	var result core.AdminOverview
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.CreateCUJ(ctx, cuj)
+			result, innerErr = r.inner.AdminOverview(ctx)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) GetCUJ(ctx context.Context, project, id string) (core.CriticalUserJourney, error) {
-	var result core.CriticalUserJourney
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) TableCounts(ctx context.Context) (map[string]int64, error) {
+	var result map[string]int64
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.GetCUJ(ctx, project, id)
+			result, innerErr = r.inner.TableCounts(ctx)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) ListCUJs(ctx context.Context, project, specID string) ([]core.CriticalUserJourney, error) {
-	var result []core.CriticalUserJourney
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) StorageReport(ctx context.Context) (core.StorageReport, error) {
+	var result core.StorageReport
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.ListCUJs(ctx, project, specID)
+			result, innerErr = r.inner.StorageReport(ctx)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) UpdateCUJ(ctx context.Context, cuj core.CriticalUserJourney) (core.CriticalUserJourney, error) {
-	var result core.CriticalUserJourney
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) DBSizeBytes(ctx context.Context) (int64, error) {
+	var result int64
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.UpdateCUJ(ctx, cuj)
+			result, innerErr = r.inner.DBSizeBytes(ctx)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-func (r *ResilientStore) DeleteCUJ(ctx context.Context, project, id string) error {
-	return r.cb.Execute(func() error {
+func (r *ResilientStore) DomainMetrics(ctx context.Context) (core.DomainMetrics, error) {
+	var result core.DomainMetrics
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			return r.inner.DeleteCUJ(ctx, project, id)
+			var innerErr error
+			result, innerErr = r.inner.DomainMetrics(ctx)
+			return innerErr
 		})
 	})
+	return result, err
 }
 
-func (r *ResilientStore) LinkCUJToFeature(ctx context.Context, project, cujID, featureID string) error {
-	return r.cb.Execute(func() error {
+// ---------------------------------------------------------------------------
+// Concrete *Store methods (not part of interfaces)
+// ---------------------------------------------------------------------------
+
+// CheckConflicts wraps the Store's conflict check with CB+retry (F4 sprint).
+func (r *ResilientStore) CheckConflicts(ctx context.Context, project, pathPattern string, exclusive bool) ([]core.ConflictDetail, error) {
+	var result []core.ConflictDetail
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			return r.inner.LinkCUJToFeature(ctx, project, cujID, featureID)
+			var innerErr error
+			result, innerErr = r.inner.CheckConflicts(ctx, project, pathPattern, exclusive)
+			return innerErr
 		})
 	})
+	return result, err
 }
 
-func (r *ResilientStore) UnlinkCUJFromFeature(ctx context.Context, project, cujID, featureID string) error {
-	return r.cb.Execute(func() error {
+func (r *ResilientStore) RequestReservationTakeover(ctx context.Context, t core.ReservationTakeover) (core.ReservationTakeover, error) {
+	var result core.ReservationTakeover
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
-			return r.inner.UnlinkCUJFromFeature(ctx, project, cujID, featureID)
+			var innerErr error
+			result, innerErr = r.inner.RequestReservationTakeover(ctx, t)
+			return innerErr
 		})
 	})
+	return result, err
 }
 
-func (r *ResilientStore) GetCUJFeatureLinks(ctx context.Context, project, cujID string) ([]core.CUJFeatureLink, error) {
-	var result []core.CUJFeatureLink
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) LatestReservationTakeover(ctx context.Context, reservationID string) (core.ReservationTakeover, error) {
+	var result core.ReservationTakeover
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.GetCUJFeatureLinks(ctx, project, cujID)
+			result, innerErr = r.inner.LatestReservationTakeover(ctx, reservationID)
 			return innerErr
 		})
 	})
 	return result, err
 }
 
-// ---------------------------------------------------------------------------
-// Concrete *Store methods (not part of interfaces)
-// ---------------------------------------------------------------------------
-
-// CheckConflicts wraps the Store's conflict check with CB+retry (F4 sprint).
-func (r *ResilientStore) CheckConflicts(ctx context.Context, project, pathPattern string, exclusive bool) ([]core.ConflictDetail, error) {
-	var result []core.ConflictDetail
-	err := r.cb.Execute(func() error {
+func (r *ResilientStore) RespondReservationTakeover(ctx context.Context, reservationID string, accept bool) (core.ReservationTakeover, error) {
+	var result core.ReservationTakeover
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
-			result, innerErr = r.inner.CheckConflicts(ctx, project, pathPattern, exclusive)
+			result, innerErr = r.inner.RespondReservationTakeover(ctx, reservationID, accept)
 			return innerErr
 		})
 	})
@@ -912,7 +1916,7 @@ func (r *ResilientStore) CheckConflicts(ctx context.Context, project, pathPatter
 // SweepExpired wraps the Store's expiration sweep with CB+retry (F3 sprint).
 func (r *ResilientStore) SweepExpired(ctx context.Context, expiredBefore time.Time, heartbeatAfter time.Time) ([]core.Reservation, error) {
 	var result []core.Reservation
-	err := r.cb.Execute(func() error {
+	err := r.sweeps.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.SweepExpired(ctx, expiredBefore, heartbeatAfter)
@@ -928,7 +1932,7 @@ func (r *ResilientStore) SweepExpired(ctx context.Context, expiredBefore time.Ti
 
 func (r *ResilientStore) UpsertWindowIdentity(ctx context.Context, wi core.WindowIdentity) (*core.WindowIdentity, error) {
 	var result *core.WindowIdentity
-	err := r.cb.Execute(func() error {
+	err := r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.UpsertWindowIdentity(ctx, wi)
@@ -940,7 +1944,7 @@ func (r *ResilientStore) UpsertWindowIdentity(ctx context.Context, wi core.Windo
 
 func (r *ResilientStore) ListWindowIdentities(ctx context.Context, project string) ([]core.WindowIdentity, error) {
 	var result []core.WindowIdentity
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.ListWindowIdentities(ctx, project)
@@ -951,7 +1955,7 @@ func (r *ResilientStore) ListWindowIdentities(ctx context.Context, project strin
 }
 
 func (r *ResilientStore) ExpireWindowIdentity(ctx context.Context, project, windowUUID string) error {
-	return r.cb.Execute(func() error {
+	return r.writes.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			return r.inner.ExpireWindowIdentity(ctx, project, windowUUID)
 		})
@@ -960,7 +1964,7 @@ func (r *ResilientStore) ExpireWindowIdentity(ctx context.Context, project, wind
 
 func (r *ResilientStore) LookupWindowIdentity(ctx context.Context, project, windowUUID string) (*core.WindowIdentity, error) {
 	var result *core.WindowIdentity
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.LookupWindowIdentity(ctx, project, windowUUID)
@@ -973,7 +1977,7 @@ func (r *ResilientStore) LookupWindowIdentity(ctx context.Context, project, wind
 // AgentForToken returns the agent ID bound to the given registration token.
 func (r *ResilientStore) AgentForToken(ctx context.Context, token string) (string, error) {
 	var result string
-	err := r.cb.Execute(func() error {
+	err := r.reads.Execute(func() error {
 		return RetryOnDBLock(func() error {
 			var innerErr error
 			result, innerErr = r.inner.AgentForToken(ctx, token)
@@ -987,3 +1991,304 @@ func (r *ResilientStore) AgentForToken(ctx context.Context, token string) (strin
 func (r *ResilientStore) Close() error {
 	return r.inner.Close()
 }
+
+func (r *ResilientStore) ListDomainEvents(ctx context.Context, filter core.EventFilter) ([]core.Event, error) {
+	var result []core.Event
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListDomainEvents(ctx, filter)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) CountDomainEvents(ctx context.Context, filter core.EventFilter) (int, error) {
+	var result int
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CountDomainEvents(ctx, filter)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ArchiveProject(ctx context.Context, project, coldPath string) (core.ProjectArchive, error) {
+	var result core.ProjectArchive
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ArchiveProject(ctx, project, coldPath)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ReactivateProject(ctx context.Context, project string) (core.ProjectArchive, error) {
+	var result core.ProjectArchive
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ReactivateProject(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListProjectArchives(ctx context.Context) ([]core.ProjectArchive, error) {
+	var result []core.ProjectArchive
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListProjectArchives(ctx)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) SetRetentionPolicy(ctx context.Context, policy core.RetentionPolicy) (core.RetentionPolicy, error) {
+	var result core.RetentionPolicy
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.SetRetentionPolicy(ctx, policy)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetRetentionPolicy(ctx context.Context, project string) (core.RetentionPolicy, error) {
+	var result core.RetentionPolicy
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetRetentionPolicy(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ListRetentionPolicies(ctx context.Context) ([]core.RetentionPolicy, error) {
+	var result []core.RetentionPolicy
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListRetentionPolicies(ctx)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteRetentionPolicy(ctx context.Context, project string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteRetentionPolicy(ctx, project)
+		})
+	})
+}
+
+func (r *ResilientStore) PurgeRetention(ctx context.Context, project string) ([]core.RetentionRun, error) {
+	var result []core.RetentionRun
+	err := r.sweeps.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.PurgeRetention(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) CollectGarbage(ctx context.Context) (core.GCRun, error) {
+	var result core.GCRun
+	err := r.sweeps.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CollectGarbage(ctx)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetIdempotentResponse(ctx context.Context, project, key string) (core.IdempotentResponse, error) {
+	var result core.IdempotentResponse
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetIdempotentResponse(ctx, project, key)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) SaveIdempotentResponse(ctx context.Context, resp core.IdempotentResponse) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.SaveIdempotentResponse(ctx, resp)
+		})
+	})
+}
+
+// RunInTx passes through the circuit breaker once for the whole
+// transaction and is never retried: fn may have done more than write to
+// the database.
+// RunInTx retries the whole transaction, fn included, when it fails to
+// begin or commit for a lock conflict, so fn must be safe to run again.
+func (r *ResilientStore) RunInTx(ctx context.Context, fn func(tx storage.DomainStore) bool) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.RunInTx(ctx, fn)
+		})
+	})
+}
+
+func (r *ResilientStore) CreateProject(ctx context.Context, p core.Project) (core.Project, error) {
+	var result core.Project
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.CreateProject(ctx, p)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) GetProject(ctx context.Context, name string) (core.Project, error) {
+	var result core.Project
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.GetProject(ctx, name)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) CheckProject(ctx context.Context, name string) (bool, bool, error) {
+	var registered, archived bool
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			registered, archived, innerErr = r.inner.CheckProject(ctx, name)
+			return innerErr
+		})
+	})
+	return registered, archived, err
+}
+
+func (r *ResilientStore) ListProjects(ctx context.Context, includeArchived bool) ([]core.Project, error) {
+	var result []core.Project
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ListProjects(ctx, includeArchived)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) UpdateProject(ctx context.Context, p core.Project) (core.Project, error) {
+	var result core.Project
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.UpdateProject(ctx, p)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) DeleteProject(ctx context.Context, name string) error {
+	return r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			return r.inner.DeleteProject(ctx, name)
+		})
+	})
+}
+
+func (r *ResilientStore) ExportProject(ctx context.Context, project string) (core.ProjectSnapshot, error) {
+	var result core.ProjectSnapshot
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ExportProject(ctx, project)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ImportProject(ctx context.Context, project string, snap core.ProjectSnapshot) (core.ProjectImport, error) {
+	var result core.ProjectImport
+	err := r.writes.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ImportProject(ctx, project, snap)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) Search(ctx context.Context, q core.SearchQuery) ([]core.SearchResult, error) {
+	var result []core.SearchResult
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.Search(ctx, q)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) FindByTitle(ctx context.Context, scope core.TitleScope) (string, error) {
+	var result string
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.FindByTitle(ctx, scope)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) ResolveShortID(ctx context.Context, project, entityType, ref string) (string, error) {
+	var result string
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.ResolveShortID(ctx, project, entityType, ref)
+			return innerErr
+		})
+	})
+	return result, err
+}
+
+func (r *ResilientStore) LookupEntity(ctx context.Context, project, ref string) ([]core.EntityRef, error) {
+	var result []core.EntityRef
+	err := r.reads.Execute(func() error {
+		return RetryOnDBLock(func() error {
+			var innerErr error
+			result, innerErr = r.inner.LookupEntity(ctx, project, ref)
+			return innerErr
+		})
+	})
+	return result, err
+}
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Retention policy operations
+
+// SetRetentionPolicy creates or replaces project's retention policy.
+func (s *Store) SetRetentionPolicy(ctx context.Context, policy core.RetentionPolicy) (core.RetentionPolicy, error) {
+	policy.UpdatedAt = clock.Now().UTC()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO retention_policies (project, max_age_days, max_rows, updated_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(project) DO UPDATE SET max_age_days = excluded.max_age_days, max_rows = excluded.max_rows, updated_at = excluded.updated_at`,
+		policy.Project, policy.MaxAgeDays, policy.MaxRows, policy.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return core.RetentionPolicy{}, fmt.Errorf("set retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (s *Store) GetRetentionPolicy(ctx context.Context, project string) (core.RetentionPolicy, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT project, max_age_days, max_rows, updated_at FROM retention_policies WHERE project = ?`, project)
+	policy, err := scanRetentionPolicy(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.RetentionPolicy{}, core.ErrNotFound
+	}
+	return policy, err
+}
+
+// ListRetentionPolicies returns every project's policy, by project.
+func (s *Store) ListRetentionPolicies(ctx context.Context) ([]core.RetentionPolicy, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT project, max_age_days, max_rows, updated_at FROM retention_policies ORDER BY project`)
+	if err != nil {
+		return nil, fmt.Errorf("list retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []core.RetentionPolicy
+	for rows.Next() {
+		policy, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// DeleteRetentionPolicy removes project's policy, so its history is kept
+// indefinitely again.
+func (s *Store) DeleteRetentionPolicy(ctx context.Context, project string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM retention_policies WHERE project = ?`, project)
+	if err != nil {
+		return fmt.Errorf("delete retention policy: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeRetention applies the retention policy of project, or of every
+// project with one when project is "", and reports what each purge
+// removed. Projects without a policy are never touched.
+func (s *Store) PurgeRetention(ctx context.Context, project string) ([]core.RetentionRun, error) {
+	var policies []core.RetentionPolicy
+	if project == "" {
+		all, err := s.ListRetentionPolicies(ctx)
+		if err != nil {
+			return nil, err
+		}
+		policies = all
+	} else {
+		policy, err := s.GetRetentionPolicy(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		policies = []core.RetentionPolicy{policy}
+	}
+
+	now := clock.Now().UTC()
+	runs := []core.RetentionRun{}
+	for _, policy := range policies {
+		run, err := s.purgeProject(ctx, policy, now)
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// purgeProject deletes the messages and events policy no longer keeps, the
+// pending pokes of those messages, then the recipient, inbox and thread rows
+// that pointed at the deleted messages, in one transaction. Deferred live
+// pokes have no messages row and are kept. Event cursors are never reused, so
+// surviving cursors stay valid.
+func (s *Store) purgeProject(ctx context.Context, policy core.RetentionPolicy, now time.Time) (core.RetentionRun, error) {
+	run := core.RetentionRun{Project: policy.Project, RanAt: now}
+	// A NULL cutoff matches nothing; a limit of MaxInt64 keeps every row.
+	var cutoff *int64
+	if policy.MaxAgeDays > 0 {
+		ms := unixMillis(now.AddDate(0, 0, -policy.MaxAgeDays))
+		cutoff = &ms
+	}
+	keep := math.MaxInt64
+	if policy.MaxRows > 0 {
+		keep = policy.MaxRows
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return run, fmt.Errorf("begin retention: %w", err)
+	}
+	defer tx.Rollback()
+
+	exec := func(what, query string, args ...any) (int64, error) {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("retention %s: %w", what, err)
+		}
+		n, _ := res.RowsAffected()
+		return n, nil
+	}
+	p := policy.Project
+	const orphaned = `message_id NOT IN (SELECT message_id FROM messages WHERE project = ?)`
+	const expired = `project = ? AND (created_ms < ? OR message_id NOT IN (
+		SELECT message_id FROM messages WHERE project = ? ORDER BY created_ms DESC, message_id DESC LIMIT ?))`
+
+	if _, err = exec("pokes",
+		`DELETE FROM pending_pokes WHERE project = ? AND message_id IN (SELECT message_id FROM messages WHERE `+expired+`)`,
+		p, p, cutoff, p, keep); err != nil {
+		return run, err
+	}
+	if run.MessagesDeleted, err = exec("messages", `DELETE FROM messages WHERE `+expired, p, cutoff, p, keep); err != nil {
+		return run, err
+	}
+	if run.EventsDeleted, err = exec("events",
+		`DELETE FROM events WHERE project = ? AND (created_ms < ? OR cursor NOT IN (
+			SELECT cursor FROM events WHERE project = ? ORDER BY cursor DESC LIMIT ?))`,
+		p, cutoff, p, keep); err != nil {
+		return run, err
+	}
+	if _, err = exec("recipients", `DELETE FROM message_recipients WHERE project = ? AND `+orphaned, p, p); err != nil {
+		return run, err
+	}
+	if run.InboxRows, err = exec("inbox", `DELETE FROM inbox_index WHERE project = ? AND `+orphaned, p, p); err != nil {
+		return run, err
+	}
+	if run.Threads, err = exec("threads",
+		`DELETE FROM thread_index WHERE project = ? AND NOT EXISTS (
+			SELECT 1 FROM messages m WHERE m.project = thread_index.project AND m.thread_id = thread_index.thread_id)`,
+		p); err != nil {
+		return run, err
+	}
+	// Unread messages may have gone with the rest; recount what's left.
+	if _, err = exec("thread unread", `UPDATE thread_index SET unread_count = `+threadUnreadCount+` WHERE project = ?`, p); err != nil {
+		return run, err
+	}
+	if err := tx.Commit(); err != nil {
+		return run, fmt.Errorf("commit retention: %w", err)
+	}
+	return run, nil
+}
+
+func scanRetentionPolicy(row scanner) (core.RetentionPolicy, error) {
+	var p core.RetentionPolicy
+	var updatedAt string
+	if err := row.Scan(&p.Project, &p.MaxAgeDays, &p.MaxRows, &updatedAt); err != nil {
+		return core.RetentionPolicy{}, fmt.Errorf("scan retention policy: %w", err)
+	}
+	p.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	return p, nil
+}
@@ -23,7 +23,8 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
-// RetryOnDBLock retries fn on "database is locked" errors using default config.
+// RetryOnDBLock retries fn on "database is locked" errors, and their
+// PostgreSQL counterparts (see isDBLocked), using default config.
 func RetryOnDBLock(fn func() error) error {
 	return retryOnDBLockInternal(DefaultRetryConfig(), fn, time.Sleep)
 }
@@ -58,6 +59,13 @@ func retryOnDBLockInternal(cfg RetryConfig, fn func() error, sleepFn func(time.D
 	return err
 }
 
+// isDBLocked reports whether err is a conflict with another writer that
+// running fn again can get past: SQLite's "database is locked", or, for a
+// Store opened over PostgreSQL, a serialization failure or deadlock
+// (SQLSTATE 40001 and 40P01).
 func isDBLocked(err error) bool {
-	return strings.Contains(strings.ToLower(err.Error()), "database is locked")
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "(sqlstate 40001)") ||
+		strings.Contains(msg, "(sqlstate 40p01)")
 }
@@ -24,6 +24,25 @@ func TestRetrySucceedsOnTransientLock(t *testing.T) {
 	}
 }
 
+func TestRetryOnPostgresConflicts(t *testing.T) {
+	for _, msg := range []string{
+		"commit: ERROR: could not serialize access due to read/write dependencies among transactions (SQLSTATE 40001)",
+		"claim tasks: ERROR: deadlock detected (SQLSTATE 40P01)",
+	} {
+		calls := 0
+		err := retryOnDBLockInternal(DefaultRetryConfig(), func() error {
+			calls++
+			if calls == 1 {
+				return errors.New(msg)
+			}
+			return nil
+		}, func(d time.Duration) {})
+		if err != nil || calls != 2 {
+			t.Fatalf("%q: err %v after %d calls, want success on the retry", msg, err, calls)
+		}
+	}
+}
+
 func TestRetryNoRetryOnOtherErrors(t *testing.T) {
 	calls := 0
 	err := retryOnDBLockInternal(DefaultRetryConfig(), func() error {
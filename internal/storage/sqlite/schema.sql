@@ -9,9 +9,19 @@ CREATE TABLE IF NOT EXISTS events (
   from_agent TEXT,
   to_json TEXT,
   body TEXT,
-  created_at TEXT NOT NULL
+  actor TEXT NOT NULL DEFAULT '',
+  request_id TEXT NOT NULL DEFAULT '',
+  correlation_id TEXT NOT NULL DEFAULT '',
+  causation_id TEXT NOT NULL DEFAULT '',
+  entity_type TEXT NOT NULL DEFAULT '',
+  entity_id TEXT NOT NULL DEFAULT '',
+  data TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL,
+  created_ms INTEGER
 );
 
+CREATE INDEX IF NOT EXISTS idx_events_id ON events(id);
+
 CREATE TABLE IF NOT EXISTS messages (
   project TEXT NOT NULL DEFAULT '',
   message_id TEXT NOT NULL,
@@ -26,7 +36,14 @@ CREATE TABLE IF NOT EXISTS messages (
   ack_required INTEGER NOT NULL DEFAULT 0,
   topic TEXT NOT NULL DEFAULT '',
   transport TEXT NOT NULL DEFAULT 'async',
+  in_reply_to TEXT NOT NULL DEFAULT '',
+  groups_json TEXT NOT NULL DEFAULT '{}',
+  attachments_json TEXT NOT NULL DEFAULT '[]',
+  route_json TEXT NOT NULL DEFAULT '',
+  ack_policy TEXT NOT NULL DEFAULT '',
+  quorum_reached_at TEXT,
   created_at TEXT NOT NULL,
+  created_ms INTEGER,
   PRIMARY KEY (project, message_id)
 );
 
@@ -49,6 +66,7 @@ CREATE TABLE IF NOT EXISTS message_recipients (
   read_at TEXT,
   ack_at TEXT,
   injected_at TEXT,
+  snoozed_until TEXT,
   PRIMARY KEY (project, message_id, agent_id)
 );
 
@@ -84,13 +102,33 @@ CREATE TABLE IF NOT EXISTS file_reservations (
   reason TEXT,
   created_at TEXT NOT NULL,
   expires_at TEXT NOT NULL,
-  released_at TEXT
+  released_at TEXT,
+  created_ms INTEGER,
+  expires_ms INTEGER,
+  released_ms INTEGER
 );
 
 CREATE INDEX IF NOT EXISTS idx_reservations_project ON file_reservations(project);
 CREATE INDEX IF NOT EXISTS idx_reservations_agent ON file_reservations(agent_id);
 CREATE INDEX IF NOT EXISTS idx_reservations_active ON file_reservations(project, expires_at) WHERE released_at IS NULL;
 
+CREATE TABLE IF NOT EXISTS reservation_takeovers (
+  id TEXT PRIMARY KEY,
+  project TEXT NOT NULL,
+  reservation_id TEXT NOT NULL,
+  holder TEXT NOT NULL,
+  requester TEXT NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'pending',
+  requested_at TEXT NOT NULL,
+  deadline TEXT NOT NULL,
+  resolved_at TEXT,
+  new_reservation_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_takeovers_reservation ON reservation_takeovers(reservation_id, requested_at);
+CREATE INDEX IF NOT EXISTS idx_takeovers_pending ON reservation_takeovers(deadline) WHERE status = 'pending';
+
 CREATE TABLE IF NOT EXISTS agents (
   id TEXT PRIMARY KEY,
   session_id TEXT,
@@ -105,7 +143,25 @@ CREATE TABLE IF NOT EXISTS agents (
   focus_state_updated TEXT NOT NULL DEFAULT '',
   live_contact_policy TEXT NOT NULL DEFAULT 'contacts_only',
   created_at TEXT NOT NULL,
-  last_seen TEXT NOT NULL
+  last_seen TEXT NOT NULL,
+  last_seen_ms INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS contact_groups (
+  project TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  team INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, name)
+);
+
+CREATE TABLE IF NOT EXISTS contact_group_members (
+  project TEXT NOT NULL DEFAULT '',
+  group_name TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  PRIMARY KEY (project, group_name, agent_id)
 );
 
 CREATE TABLE IF NOT EXISTS agent_contacts (
@@ -126,6 +182,7 @@ CREATE TABLE IF NOT EXISTS thread_index (
   last_message_from TEXT NOT NULL DEFAULT '',
   last_message_body TEXT NOT NULL DEFAULT '',
   last_message_at TEXT NOT NULL DEFAULT '',
+  unread_count INTEGER NOT NULL DEFAULT 0,
   PRIMARY KEY (project, thread_id, agent)
 );
 
@@ -148,6 +205,10 @@ CREATE TABLE IF NOT EXISTS specs (
   version INTEGER NOT NULL DEFAULT 1,
   created_at TEXT NOT NULL,
   updated_at TEXT NOT NULL,
+  short_id TEXT NOT NULL DEFAULT '',
+  last_reviewed_at TEXT,
+  stale_notified_at TEXT,
+  title_key TEXT,
   PRIMARY KEY (project, id)
 );
 
@@ -163,6 +224,8 @@ CREATE TABLE IF NOT EXISTS epics (
   version INTEGER NOT NULL DEFAULT 1,
   created_at TEXT NOT NULL,
   updated_at TEXT NOT NULL,
+  short_id TEXT NOT NULL DEFAULT '',
+  title_key TEXT,
   PRIMARY KEY (project, id)
 );
 
@@ -176,9 +239,13 @@ CREATE TABLE IF NOT EXISTS stories (
   title TEXT NOT NULL,
   acceptance_criteria_json TEXT,
   status TEXT NOT NULL DEFAULT 'todo',
+  priority TEXT NOT NULL DEFAULT 'medium',
+  rank TEXT NOT NULL DEFAULT '',
   version INTEGER NOT NULL DEFAULT 1,
   created_at TEXT NOT NULL,
   updated_at TEXT NOT NULL,
+  short_id TEXT NOT NULL DEFAULT '',
+  title_key TEXT,
   PRIMARY KEY (project, id)
 );
 
@@ -193,9 +260,22 @@ CREATE TABLE IF NOT EXISTS tasks (
   agent TEXT,
   session_id TEXT,
   status TEXT NOT NULL DEFAULT 'pending',
+  priority TEXT NOT NULL DEFAULT 'medium',
+  due_at TEXT,
   version INTEGER NOT NULL DEFAULT 1,
   created_at TEXT NOT NULL,
   updated_at TEXT NOT NULL,
+  short_id TEXT NOT NULL DEFAULT '',
+  capabilities_json TEXT NOT NULL DEFAULT '[]',
+  expected_paths_json TEXT NOT NULL DEFAULT '[]',
+  lease_id TEXT NOT NULL DEFAULT '',
+  blocked_reason TEXT NOT NULL DEFAULT '',
+  blocked_detail TEXT NOT NULL DEFAULT '',
+  unblock_json TEXT NOT NULL DEFAULT '',
+  created_ms INTEGER,
+  updated_ms INTEGER,
+  due_ms INTEGER,
+  title_key TEXT,
   PRIMARY KEY (project, id)
 );
 
@@ -203,6 +283,21 @@ CREATE INDEX IF NOT EXISTS idx_tasks_story ON tasks(project, story_id);
 CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(project, status);
 CREATE INDEX IF NOT EXISTS idx_tasks_agent ON tasks(project, agent);
 
+-- A batch claim's shared lease: while it is live the claimed tasks stay
+-- with the agent; when it expires, the ones still running go back to
+-- pending. tasks.lease_id points here.
+CREATE TABLE IF NOT EXISTS task_leases (
+  id TEXT NOT NULL,
+  project TEXT NOT NULL,
+  agent TEXT NOT NULL,
+  expires_at TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  expires_ms INTEGER,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_leases_expires ON task_leases(expires_at);
+
 CREATE TABLE IF NOT EXISTS insights (
   id TEXT NOT NULL,
   project TEXT NOT NULL DEFAULT '',
@@ -213,7 +308,12 @@ CREATE TABLE IF NOT EXISTS insights (
   body TEXT,
   url TEXT,
   score REAL NOT NULL DEFAULT 0,
+  status TEXT NOT NULL DEFAULT 'new',
+  version INTEGER NOT NULL DEFAULT 1,
+  content_hash TEXT NOT NULL DEFAULT '',
+  dedup_key TEXT,
   created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL DEFAULT '',
   PRIMARY KEY (project, id)
 );
 
@@ -221,6 +321,89 @@ CREATE INDEX IF NOT EXISTS idx_insights_spec ON insights(project, spec_id);
 CREATE INDEX IF NOT EXISTS idx_insights_category ON insights(project, category);
 CREATE INDEX IF NOT EXISTS idx_insights_source ON insights(project, source);
 
+CREATE TABLE IF NOT EXISTS insight_rules (
+  id TEXT NOT NULL,
+  project TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  category TEXT NOT NULL DEFAULT '',
+  source TEXT NOT NULL DEFAULT '',
+  min_score REAL,
+  spec_id TEXT NOT NULL DEFAULT '',
+  notify_agent TEXT NOT NULL DEFAULT '',
+  enabled INTEGER NOT NULL DEFAULT 1,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE TABLE IF NOT EXISTS lifecycle_hooks (
+  id TEXT NOT NULL,
+  project TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  entity_type TEXT NOT NULL,
+  from_status TEXT NOT NULL DEFAULT '',
+  to_status TEXT NOT NULL,
+  actions_json TEXT NOT NULL DEFAULT '[]',
+  enabled INTEGER NOT NULL DEFAULT 1,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE TABLE IF NOT EXISTS lifecycle_hook_runs (
+  id TEXT NOT NULL,
+  project TEXT NOT NULL DEFAULT '',
+  hook_id TEXT NOT NULL,
+  hook_name TEXT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  from_status TEXT NOT NULL DEFAULT '',
+  to_status TEXT NOT NULL,
+  results_json TEXT NOT NULL DEFAULT '[]',
+  ok INTEGER NOT NULL DEFAULT 1,
+  created_at TEXT NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lifecycle_hook_runs_hook ON lifecycle_hook_runs(project, hook_id, created_at);
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+  id TEXT NOT NULL,
+  project TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL DEFAULT '',
+  kind TEXT NOT NULL,
+  cadence TEXT NOT NULL,
+  hour INTEGER NOT NULL DEFAULT 0,
+  weekday INTEGER NOT NULL DEFAULT 0,
+  recipients_json TEXT NOT NULL DEFAULT '[]',
+  enabled INTEGER NOT NULL DEFAULT 1,
+  last_run_at TEXT,
+  next_run_at TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_due ON report_schedules(enabled, next_run_at);
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+  project TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  enabled INTEGER NOT NULL DEFAULT 0,
+  description TEXT NOT NULL DEFAULT '',
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, name)
+);
+
+CREATE TABLE IF NOT EXISTS agent_capabilities (
+  project TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  aliases_json TEXT NOT NULL DEFAULT '[]',
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, name)
+);
+
 CREATE TABLE IF NOT EXISTS sessions (
   id TEXT NOT NULL,
   project TEXT NOT NULL DEFAULT '',
@@ -228,8 +411,10 @@ CREATE TABLE IF NOT EXISTS sessions (
   agent TEXT NOT NULL,
   task_id TEXT,
   status TEXT NOT NULL DEFAULT 'running',
+  version INTEGER NOT NULL DEFAULT 1,
   started_at TEXT NOT NULL,
   updated_at TEXT NOT NULL,
+  heartbeat_at TEXT,
   PRIMARY KEY (project, id)
 );
 
@@ -272,6 +457,56 @@ CREATE TABLE IF NOT EXISTS cuj_feature_links (
 CREATE INDEX IF NOT EXISTS idx_cuj_links_cuj ON cuj_feature_links(project, cuj_id);
 CREATE INDEX IF NOT EXISTS idx_cuj_links_feature ON cuj_feature_links(project, feature_id);
 
+-- Goals (OKRs) and their links to specs/epics
+
+-- Immutable published spec snapshots
+
+CREATE TABLE IF NOT EXISTS spec_published_versions (
+  project TEXT NOT NULL DEFAULT '',
+  spec_id TEXT NOT NULL,
+  number INTEGER NOT NULL,
+  snapshot_json TEXT NOT NULL,
+  published_by TEXT NOT NULL DEFAULT '',
+  published_at TEXT NOT NULL,
+  PRIMARY KEY (project, spec_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS spec_revisions (
+  project TEXT NOT NULL DEFAULT '',
+  spec_id TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  snapshot_json TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  PRIMARY KEY (project, spec_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS goals (
+  id TEXT NOT NULL,
+  project TEXT NOT NULL DEFAULT '',
+  title TEXT NOT NULL,
+  description TEXT,
+  period TEXT,
+  key_results_json TEXT,
+  status TEXT NOT NULL DEFAULT 'active',
+  version INTEGER NOT NULL DEFAULT 1,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_status ON goals(project, status);
+
+CREATE TABLE IF NOT EXISTS goal_links (
+  project TEXT NOT NULL DEFAULT '',
+  goal_id TEXT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  linked_at TEXT NOT NULL,
+  PRIMARY KEY (project, goal_id, entity_type, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_links_entity ON goal_links(project, entity_type, entity_id);
+
 -- Window identity persistence (maps tmux window UUID to stable agent ID)
 
 CREATE TABLE IF NOT EXISTS window_identities (
@@ -289,3 +524,103 @@ CREATE TABLE IF NOT EXISTS window_identities (
 
 CREATE UNIQUE INDEX IF NOT EXISTS uq_window_project
   ON window_identities(project, window_uuid);
+
+-- Archived projects. archive_path is set when the project's rows were
+-- moved out to a cold archive database; row_count is how many moved.
+
+CREATE TABLE IF NOT EXISTS project_archives (
+  project TEXT PRIMARY KEY,
+  archived_at TEXT NOT NULL,
+  archive_path TEXT NOT NULL DEFAULT '',
+  row_count INTEGER NOT NULL DEFAULT 0
+);
+
+-- Registered projects. Whether one is archived is project_archives' to
+-- say; the row stays here either way.
+
+CREATE TABLE IF NOT EXISTS projects (
+  name TEXT PRIMARY KEY,
+  display_name TEXT NOT NULL DEFAULT '',
+  description TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL,
+  spec_stale_days INTEGER NOT NULL DEFAULT 0,
+  short_id_sequences_json TEXT NOT NULL DEFAULT ''
+);
+
+-- Per-project counters behind short IDs (SPEC-12, TASK-348); last is the
+-- most recently issued number for the prefix. A project's custom prefixes
+-- and starting numbers are in projects.short_id_sequences_json.
+
+CREATE TABLE IF NOT EXISTS id_sequences (
+  project TEXT NOT NULL DEFAULT '',
+  prefix TEXT NOT NULL,
+  last INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (project, prefix)
+);
+
+-- Per-project retention for messages and events; zero disables a bound.
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+  project TEXT PRIMARY KEY,
+  max_age_days INTEGER NOT NULL DEFAULT 0,
+  max_rows INTEGER NOT NULL DEFAULT 0,
+  updated_at TEXT NOT NULL
+);
+
+-- Responses to POSTs and PATCHes sent with an Idempotency-Key, replayed on retry.
+-- The sweeper forgets them after core.IdempotencyKeyTTL.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  project TEXT NOT NULL DEFAULT '',
+  key TEXT NOT NULL,
+  request_hash TEXT NOT NULL,
+  status INTEGER NOT NULL,
+  content_type TEXT NOT NULL DEFAULT '',
+  headers_json TEXT NOT NULL DEFAULT '{}',
+  body BLOB,
+  created_at TEXT NOT NULL,
+  PRIMARY KEY (project, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at);
+
+-- Insights merged into another by MergeInsights. GetInsight on a merged
+-- ID returns the insight it went into.
+CREATE TABLE IF NOT EXISTS insight_merges (
+  project TEXT NOT NULL,
+  merged_id TEXT NOT NULL,
+  into_id TEXT NOT NULL,
+  merged_at TEXT NOT NULL,
+  PRIMARY KEY (project, merged_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_insight_merges_into ON insight_merges(project, into_id);
+
+-- Progress notes agents record against a task. seq orders a task's
+-- comments and is the cursor their listing pages by.
+CREATE TABLE IF NOT EXISTS task_comments (
+  seq INTEGER PRIMARY KEY AUTOINCREMENT,
+  id TEXT NOT NULL UNIQUE,
+  project TEXT NOT NULL,
+  task_id TEXT NOT NULL,
+  author TEXT NOT NULL DEFAULT '',
+  body TEXT NOT NULL,
+  created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments(project, task_id, seq);
+
+-- Payloads too large for a message body. Messages refer to them by ID in
+-- attachments_json; the bytes are only read when a blob is fetched.
+CREATE TABLE IF NOT EXISTS blobs (
+  project TEXT NOT NULL,
+  id TEXT NOT NULL,
+  sha256 TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  content_type TEXT NOT NULL DEFAULT '',
+  data BLOB NOT NULL,
+  created_at TEXT NOT NULL,
+  PRIMARY KEY (project, id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blobs_sha256 ON blobs(project, sha256);
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Full-text search
+//
+// search_fts is an FTS5 index over the searchable text of specs, stories,
+// insights and messages. search_docs maps each FTS rowid to the entity it
+// indexes; its INTEGER PRIMARY KEY keeps rowids stable across VACUUM, which
+// an external-content index keyed on the source tables' implicit rowids
+// wouldn't. Triggers on the source tables keep both in step, so every write
+// path (including project archival) is indexed without help from Go code.
+
+// searchSource describes how one entity kind feeds the index.
+type searchSource struct {
+	kind  core.SearchKind
+	table string
+	id    string // ID column
+	title string // SQL expression over the row, prefixed new./old. at use
+	body  string
+}
+
+var searchSources = []searchSource{
+	{core.SearchKindSpec, "specs", "id", "title", "COALESCE(%[1]s.vision, '')"},
+	{core.SearchKindStory, "stories", "id", "title", "COALESCE(%[1]s.acceptance_criteria_json, '')"},
+	{core.SearchKindInsight, "insights", "id", "title", "COALESCE(%[1]s.body, '')"},
+	{core.SearchKindMessage, "messages", "message_id", "COALESCE(%[1]s.subject, '')", "COALESCE(%[1]s.body, '')"},
+}
+
+// expr qualifies a title/body expression with row ("new" or "old").
+func (src searchSource) expr(e, row string) string {
+	if !strings.Contains(e, "%") {
+		return row + "." + e
+	}
+	return fmt.Sprintf(e, row)
+}
+
+// docRowid selects the search_docs id (the FTS rowid) for row's entity.
+func (src searchSource) docRowid(row string) string {
+	return fmt.Sprintf(`(SELECT id FROM search_docs WHERE kind = '%s' AND project = %s.project AND entity_id = %s.%s)`,
+		src.kind, row, row, src.id)
+}
+
+// triggers returns the insert, update and delete triggers for src.
+func (src searchSource) triggers() []string {
+	t, k := src.table, src.kind
+	return []string{
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%[1]s_ai AFTER INSERT ON %[1]s BEGIN
+  INSERT INTO search_docs (kind, project, entity_id) VALUES ('%[2]s', new.project, new.%[3]s);
+  INSERT INTO search_fts (rowid, title, body) VALUES (%[4]s, %[5]s, %[6]s);
+END`, t, k, src.id, src.docRowid("new"), src.expr(src.title, "new"), src.expr(src.body, "new")),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%[1]s_au AFTER UPDATE ON %[1]s BEGIN
+  UPDATE search_fts SET title = %[2]s, body = %[3]s WHERE rowid = %[4]s;
+END`, t, src.expr(src.title, "new"), src.expr(src.body, "new"), src.docRowid("new")),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%[1]s_ad AFTER DELETE ON %[1]s BEGIN
+  DELETE FROM search_fts WHERE rowid = %[2]s;
+  DELETE FROM search_docs WHERE kind = '%[3]s' AND project = old.project AND entity_id = old.%[4]s;
+END`, t, src.docRowid("old"), k, src.id),
+	}
+}
+
+// backfill indexes every existing row of src.
+func (src searchSource) backfill() []string {
+	return []string{
+		fmt.Sprintf(`INSERT INTO search_docs (kind, project, entity_id) SELECT '%s', project, %s FROM %s`,
+			src.kind, src.id, src.table),
+		fmt.Sprintf(`INSERT INTO search_fts (rowid, title, body)
+  SELECT d.id, %s, %s FROM search_docs d JOIN %s src ON src.project = d.project AND src.%s = d.entity_id
+  WHERE d.kind = '%s'`, src.expr(src.title, "src"), src.expr(src.body, "src"), src.table, src.id, src.kind),
+	}
+}
+
+// migrateSearchIndex creates the search tables and triggers, indexing
+// existing rows the first time.
+func migrateSearchIndex(db *sql.DB) error {
+	fresh := !tableExists(db, "search_docs")
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS search_docs (
+  id INTEGER PRIMARY KEY,
+  kind TEXT NOT NULL,
+  project TEXT NOT NULL DEFAULT '',
+  entity_id TEXT NOT NULL,
+  UNIQUE (kind, project, entity_id)
+)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(title, body, tokenize = 'porter unicode61')`,
+	}
+	for _, src := range searchSources {
+		stmts = append(stmts, src.triggers()...)
+	}
+	if fresh {
+		for _, src := range searchSources {
+			stmts = append(stmts, src.backfill()...)
+		}
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin search index: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("search index: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ftsQuery turns free text into an FTS5 query: each whitespace-separated
+// term is quoted, so punctuation and FTS operators match literally, and the
+// terms are ANDed. A trailing * on a term keeps prefix matching.
+func ftsQuery(q string) string {
+	var terms []string
+	for _, term := range strings.Fields(q) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Search returns the entities whose indexed text matches q.Query, best
+// match first; higher scores are better.
+func (s *Store) Search(ctx context.Context, q core.SearchQuery) ([]core.SearchResult, error) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	if q.Limit > 100 {
+		q.Limit = 100
+	}
+	return s.dialectOf().Search(ctx, s.db, q)
+}
+
+// Search runs q against search_fts. Score is the negated bm25 rank.
+func (sqliteDialect) Search(ctx context.Context, db Querier, q core.SearchQuery) ([]core.SearchResult, error) {
+	match := ftsQuery(q.Query)
+	if match == "" {
+		return []core.SearchResult{}, nil
+	}
+
+	query := `SELECT d.kind, d.entity_id, d.project, f.title,
+		snippet(search_fts, -1, '[', ']', '…', 12), -bm25(search_fts)
+	 FROM search_fts f JOIN search_docs d ON d.id = f.rowid
+	 WHERE search_fts MATCH ?`
+	args := []any{match}
+	if q.Project != "" {
+		query += " AND d.project = ?"
+		args = append(args, q.Project)
+	}
+	if len(q.Kinds) > 0 {
+		query += " AND d.kind IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(q.Kinds)), ", ") + ")"
+		for _, k := range q.Kinds {
+			args = append(args, string(k))
+		}
+	}
+	query += " ORDER BY bm25(search_fts) LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+	out := []core.SearchResult{}
+	for rows.Next() {
+		var r core.SearchResult
+		var kind string
+		if err := rows.Scan(&kind, &r.ID, &r.Project, &r.Title, &r.Snippet, &r.Score); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		r.Kind = core.SearchKind(kind)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
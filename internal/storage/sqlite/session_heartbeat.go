@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Session heartbeats
+//
+// A session that has heartbeated is expected to keep doing so. When its
+// last heartbeat falls behind the sweeper's grace period while it is still
+// running or bound to a task, StopStaleSessions marks it idle (error if it
+// held a task: it died mid-work), releases its tasks and records
+// session.stopped so orchestrators can respawn it.
+
+func migrateSessionHeartbeats(db *sql.DB) error {
+	if !tableExists(db, "sessions") {
+		return nil
+	}
+	if !tableHasColumn(db, "sessions", "heartbeat_at") {
+		if _, err := db.Exec(`ALTER TABLE sessions ADD COLUMN heartbeat_at TEXT`); err != nil {
+			return fmt.Errorf("add heartbeat_at column: %w", err)
+		}
+	}
+	return nil
+}
+
+// HeartbeatSession records that a session is alive. It leaves the status
+// and version alone, so heartbeats don't race optimistic updates. Returns
+// core.ErrNotFound for an unknown session.
+func (s *Store) HeartbeatSession(ctx context.Context, project, id string) (core.Session, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET heartbeat_at = ? WHERE project = ? AND id = ?`,
+		clock.Now().UTC().Format(time.RFC3339Nano), project, id,
+	)
+	if err != nil {
+		return core.Session{}, fmt.Errorf("heartbeat session: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.Session{}, core.ErrNotFound
+	}
+	return s.GetSession(ctx, project, id)
+}
+
+// StopStaleSessions stops every session whose last heartbeat is before
+// heartbeatBefore and that is still running or bound to a task. Each one's
+// tasks lose their session, and running ones go back to pending unassigned.
+func (s *Store) StopStaleSessions(ctx context.Context, heartbeatBefore time.Time) ([]core.StoppedSession, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin stop stale sessions: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions
+		 WHERE heartbeat_ms < ?
+		   AND (status = ? OR COALESCE(task_id, '') != '')
+		 ORDER BY project, heartbeat_ms`,
+		unixMillis(heartbeatBefore), string(core.SessionStatusRunning),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list stale sessions: %w", err)
+	}
+	var stale []core.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stale = append(stale, session)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := clock.Now().UTC()
+	var stopped []core.StoppedSession
+	for _, session := range stale {
+		out, err := s.stopSessionTx(ctx, tx, session, now)
+		if err != nil {
+			return nil, err
+		}
+		stopped = append(stopped, out)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit stop stale sessions: %w", err)
+	}
+	return stopped, nil
+}
+
+// stopSessionTx marks session idle or error, releases its tasks and
+// appends its session.stopped event.
+func (s *Store) stopSessionTx(ctx context.Context, tx dbTx, session core.Session, now time.Time) (core.StoppedSession, error) {
+	stamp := now.Format(time.RFC3339Nano)
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE tasks SET session_id = '', version = version + 1, updated_at = ?,
+		        agent = CASE WHEN status = ? THEN '' ELSE agent END,
+		        status = CASE WHEN status = ? THEN ? ELSE status END
+		 WHERE project = ? AND session_id = ?
+		 RETURNING id`,
+		stamp, string(core.TaskStatusRunning), string(core.TaskStatusRunning), string(core.TaskStatusPending),
+		session.Project, session.ID,
+	)
+	if err != nil {
+		return core.StoppedSession{}, fmt.Errorf("release session tasks: %w", err)
+	}
+	out := core.StoppedSession{EventID: uuid.NewString()}
+	for rows.Next() {
+		var taskID string
+		if err := rows.Scan(&taskID); err != nil {
+			rows.Close()
+			return core.StoppedSession{}, fmt.Errorf("scan released task: %w", err)
+		}
+		out.Tasks = append(out.Tasks, taskID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return core.StoppedSession{}, err
+	}
+
+	status := core.SessionStatusIdle
+	if session.TaskID != "" || len(out.Tasks) > 0 {
+		status = core.SessionStatusError
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE sessions SET status = ?, task_id = '', version = version + 1, updated_at = ?
+		 WHERE project = ? AND id = ?`,
+		string(status), stamp, session.Project, session.ID,
+	); err != nil {
+		return core.StoppedSession{}, fmt.Errorf("stop session: %w", err)
+	}
+	session.Status = status
+	session.TaskID = ""
+	session.Version++
+	session.UpdatedAt = now
+	out.Session = session
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return core.StoppedSession{}, err
+	}
+	out.Cursor, err = s.appendEventTx(ctx, tx, core.Event{
+		ID:         out.EventID,
+		Type:       core.EventSessionStopped,
+		Project:    session.Project,
+		EntityType: core.EventSessionStopped.EntityType(),
+		EntityID:   session.ID,
+		Data:       string(data),
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return core.StoppedSession{}, fmt.Errorf("record session stopped: %w", err)
+	}
+	return out, nil
+}
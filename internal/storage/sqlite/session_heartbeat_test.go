@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestStopStaleSessions(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(clock.Reset)
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(name, taskID string) core.Session {
+		t.Helper()
+		s, err := store.CreateSession(ctx, core.Session{Project: "p", Name: name, Agent: name, TaskID: taskID})
+		if err != nil {
+			t.Fatalf("create session %s: %v", name, err)
+		}
+		return s
+	}
+	task, err := store.CreateTask(ctx, core.Task{Project: "p", Title: "build", Status: core.TaskStatusPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker := create("worker", task.ID)
+	task.Agent, task.SessionID, task.Status = "worker", worker.ID, core.TaskStatusRunning
+	if _, err := store.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("bind task: %v", err)
+	}
+	idler := create("idler", "")
+	silent := create("silent", "")
+	fresh := create("fresh", "")
+	for _, s := range []core.Session{worker, idler} {
+		got, err := store.HeartbeatSession(ctx, "p", s.ID)
+		if err != nil || got.HeartbeatAt == nil || got.Version != s.Version {
+			t.Fatalf("heartbeat %s = %+v, %v", s.Name, got, err)
+		}
+	}
+	if _, err := store.HeartbeatSession(ctx, "p", "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("heartbeat missing err = %v", err)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if _, err := store.HeartbeatSession(ctx, "p", fresh.ID); err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := store.StopStaleSessions(ctx, clock.Now().Add(-5*time.Minute))
+	if err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	byName := map[string]core.StoppedSession{}
+	for _, st := range stopped {
+		byName[st.Session.Name] = st
+	}
+	if len(stopped) != 2 || byName["worker"].Session.Status != core.SessionStatusError || byName["idler"].Session.Status != core.SessionStatusIdle {
+		t.Fatalf("stopped = %+v, want worker errored and idler idle (silent never heartbeated)", stopped)
+	}
+	if ts := byName["worker"].Tasks; len(ts) != 1 || ts[0] != task.ID || byName["worker"].Cursor == 0 {
+		t.Fatalf("worker stop = %+v, want its task released and an event cursor", byName["worker"])
+	}
+
+	got, err := store.GetTask(ctx, "p", task.ID)
+	if err != nil || got.Status != core.TaskStatusPending || got.Agent != "" || got.SessionID != "" {
+		t.Fatalf("released task = %+v, %v", got, err)
+	}
+	if s, _ := store.GetSession(ctx, "p", silent.ID); s.Status != core.SessionStatusRunning {
+		t.Fatalf("silent session = %+v, want untouched", s)
+	}
+	events, err := store.ListDomainEvents(ctx, core.EventFilter{Project: "p", EntityType: "session", EntityID: worker.ID})
+	if err != nil || len(events) != 1 || events[0].Type != core.EventSessionStopped {
+		t.Fatalf("worker events = %+v, %v", events, err)
+	}
+
+	// Already stopped sessions are not stopped again.
+	if again, err := store.StopStaleSessions(ctx, clock.Now().Add(-5*time.Minute)); err != nil || len(again) != 0 {
+		t.Fatalf("second stop = %+v, %v", again, err)
+	}
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// SessionHistory returns a record for every session in project started in
+// [since, until), oldest first. Sessions are found through their
+// session.started events, so ones deleted since are still counted; the
+// matching session.stopped event ends them, and a session still in the
+// sessions table reports its current status. Live sessions older than the
+// event log are included from the sessions table alone.
+func (s *Store) SessionHistory(ctx context.Context, project string, since, until time.Time) ([]core.SessionRecord, error) {
+	from, to := unixMillis(since), unixMillis(until)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT e.entity_id, COALESCE(json_extract(e.data, '$.agent'), ''), e.created_at,
+		        stop.created_at, COALESCE(stop.data, ''), live.status
+		 FROM events e
+		 LEFT JOIN events stop ON stop.cursor = (
+		   SELECT MIN(x.cursor) FROM events x
+		   WHERE x.project = e.project AND x.entity_type = 'session' AND x.entity_id = e.entity_id
+		     AND x.type = ? AND x.cursor > e.cursor)
+		 LEFT JOIN sessions live ON live.project = e.project AND live.id = e.entity_id
+		 WHERE e.project = ? AND e.entity_type = 'session' AND e.type = ?
+		   AND e.created_ms >= ? AND e.created_ms < ?
+		 ORDER BY e.cursor`,
+		string(core.EventSessionStopped), project, string(core.EventSessionStarted), from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("session history: %w", err)
+	}
+	var out []core.SessionRecord
+	seen := map[string]bool{}
+	for rows.Next() {
+		var rec core.SessionRecord
+		var startedAt string
+		var endedAt, liveStatus sql.NullString
+		var stopData string
+		if err := rows.Scan(&rec.ID, &rec.Agent, &startedAt, &endedAt, &stopData, &liveStatus); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan session history: %w", err)
+		}
+		rec.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
+		if endedAt.Valid {
+			at, _ := time.Parse(time.RFC3339Nano, endedAt.String)
+			rec.EndedAt = &at
+			var last struct {
+				Status core.SessionStatus `json:"status"`
+			}
+			if json.Unmarshal([]byte(stopData), &last) == nil {
+				rec.Status = last.Status
+			}
+		}
+		if liveStatus.Valid {
+			rec.Status = core.SessionStatus(liveStatus.String)
+		}
+		seen[rec.ID] = true
+		out = append(out, rec)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("session history: %w", err)
+	}
+
+	// started_at has no millisecond twin, so the range is checked here.
+	live, err := s.db.QueryContext(ctx,
+		`SELECT id, agent, status, started_at FROM sessions WHERE project = ? ORDER BY started_at`,
+		project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("session history: %w", err)
+	}
+	for live.Next() {
+		var rec core.SessionRecord
+		var status, startedAt string
+		if err := live.Scan(&rec.ID, &rec.Agent, &status, &startedAt); err != nil {
+			live.Close()
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		if seen[rec.ID] {
+			continue
+		}
+		rec.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
+		if ms := unixMillis(rec.StartedAt); ms < from || ms >= to {
+			continue
+		}
+		rec.Status = core.SessionStatus(status)
+		out = append(out, rec)
+	}
+	live.Close()
+	if err := live.Err(); err != nil {
+		return nil, fmt.Errorf("session history: %w", err)
+	}
+
+	done := map[string]int{}
+	counts, err := s.db.QueryContext(ctx,
+		`SELECT session_id, COUNT(*) FROM tasks
+		 WHERE project = ? AND status = ? AND COALESCE(session_id, '') != ''
+		 GROUP BY session_id`,
+		project, string(core.TaskStatusDone),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("session tasks: %w", err)
+	}
+	defer counts.Close()
+	for counts.Next() {
+		var id string
+		var n int
+		if err := counts.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan session tasks: %w", err)
+		}
+		done[id] = n
+	}
+	if err := counts.Err(); err != nil {
+		return nil, fmt.Errorf("session tasks: %w", err)
+	}
+	for i := range out {
+		out[i].TasksDone = done[out[i].ID]
+	}
+	return out, nil
+}
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Short IDs
+//
+// Specs, epics, stories and tasks get a per-project sequential short ID
+// ("TASK-348") when created, stored in their short_id column. id_sequences
+// holds the last number issued per project and prefix, so numbers are never
+// reused after a delete. It stays in the hot database when a project is
+// archived: rows created meanwhile keep counting up instead of colliding
+// with the archived ones on reactivation.
+//
+// A project may set its own prefix and starting number per entity type
+// (core.ShortIDSequences), kept as JSON in projects.short_id_sequences_json.
+// Counters are per prefix, so a prefix that's changed and later changed
+// back picks up where it left off.
+
+// shortIDTables maps an entity type with short IDs to its table.
+var shortIDTables = map[string]string{
+	"spec":  "specs",
+	"epic":  "epics",
+	"story": "stories",
+	"task":  "tasks",
+}
+
+// nextShortIDTx issues the next short ID for entityType in project, under
+// the project's sequence for it. Unregistered projects use the defaults.
+func nextShortIDTx(ctx context.Context, tx dbTx, project, entityType string) (string, error) {
+	var raw string
+	err := tx.QueryRowContext(ctx, `SELECT short_id_sequences_json FROM projects WHERE name = ?`, project).Scan(&raw)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("load %s short id sequence: %w", entityType, err)
+	}
+	seq := decodeShortIDSequences(raw).For(entityType)
+	var n int64
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO id_sequences (project, prefix, last) VALUES (?, ?, ?)
+		 ON CONFLICT (project, prefix) DO UPDATE SET last = CASE
+		   WHEN id_sequences.last + 1 > excluded.last THEN id_sequences.last + 1 ELSE excluded.last END
+		 RETURNING last`,
+		project, seq.Prefix, seq.Start,
+	).Scan(&n)
+	if err != nil {
+		return "", fmt.Errorf("next %s short id: %w", entityType, err)
+	}
+	return fmt.Sprintf("%s-%d", seq.Prefix, n), nil
+}
+
+// encodeShortIDSequences is the short_id_sequences_json of seqs: "" when
+// there are none.
+func encodeShortIDSequences(seqs core.ShortIDSequences) string {
+	if len(seqs) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(seqs)
+	return string(b)
+}
+
+func decodeShortIDSequences(raw string) core.ShortIDSequences {
+	if raw == "" {
+		return nil
+	}
+	var seqs core.ShortIDSequences
+	_ = json.Unmarshal([]byte(raw), &seqs)
+	return seqs
+}
+
+// ResolveShortID returns the ID of the entityType entity with short ID ref
+// in project, or "" if there is none. With an empty project the short ID
+// resolves only if exactly one project has it. Any prefix resolves, so
+// short IDs issued before a project changed its prefixes still do.
+func (s *Store) ResolveShortID(ctx context.Context, project, entityType, ref string) (string, error) {
+	table, ok := shortIDTables[entityType]
+	shortID := core.ParseShortID(ref)
+	if !ok || shortID == "" {
+		return "", nil
+	}
+	query := `SELECT id FROM ` + table + ` WHERE short_id = ?`
+	args := []any{shortID}
+	if project != "" {
+		query += ` AND project = ?`
+		args = append(args, project)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` LIMIT 2`, args...)
+	if err != nil {
+		return "", fmt.Errorf("resolve short id: %w", err)
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return "", fmt.Errorf("scan short id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(ids) != 1 {
+		return "", nil
+	}
+	return ids[0], nil
+}
+
+// migrateShortIDs adds short_id to databases created before short IDs,
+// numbering existing rows per project in creation order, and projects'
+// sequence configuration to databases created before it.
+func migrateShortIDs(db *sql.DB) error {
+	if tableExists(db, "projects") && !tableHasColumn(db, "projects", "short_id_sequences_json") {
+		if _, err := db.Exec(`ALTER TABLE projects ADD COLUMN short_id_sequences_json TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add short_id_sequences_json column: %w", err)
+		}
+	}
+	for entityType, table := range shortIDTables {
+		if !tableExists(db, table) {
+			continue
+		}
+		if !tableHasColumn(db, table, "short_id") {
+			if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN short_id TEXT NOT NULL DEFAULT ''`); err != nil {
+				return fmt.Errorf("add %s.short_id column: %w", table, err)
+			}
+			prefix := core.ShortIDPrefix(entityType)
+			if _, err := db.Exec(`UPDATE `+table+` SET short_id = ? || '-' || r.n
+				FROM (SELECT project, id, ROW_NUMBER() OVER (PARTITION BY project ORDER BY created_at, id) AS n FROM `+table+`) r
+				WHERE `+table+`.project = r.project AND `+table+`.id = r.id`, prefix); err != nil {
+				return fmt.Errorf("backfill %s short ids: %w", table, err)
+			}
+			if _, err := db.Exec(`INSERT INTO id_sequences (project, prefix, last)
+				SELECT project, ?, COUNT(*) FROM `+table+` WHERE true GROUP BY project
+				ON CONFLICT (project, prefix) DO UPDATE SET last = max(last, excluded.last)`, prefix); err != nil {
+				return fmt.Errorf("seed %s short id sequence: %w", table, err)
+			}
+		}
+		if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_` + table + `_short_id ON ` + table + `(project, short_id) WHERE short_id != ''`); err != nil {
+			return fmt.Errorf("create %s short id index: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Project snapshots
+//
+// A snapshot is every row the project has in snapshotTables, keyed by
+// column name, with the project column left out so it can be restored under
+// any name. Like reactivation, import goes by column name: columns added by
+// migrations since the export take their defaults, and columns this server
+// doesn't know are rejected.
+//
+// Inbox cursors are event cursors, which are local to a server. Import
+// records one message.created event per distinct cursor in the snapshot's
+// inbox, in the original order, and rewrites inbox_index and thread_index
+// to the new cursors so agents' inboxes read the same afterwards.
+
+// snapshotTables lists the tables a snapshot carries, parents first.
+var snapshotTables = []string{
+	"specs", "spec_revisions", "spec_published_versions",
+	"epics", "stories", "tasks", "task_comments",
+	"cujs", "cuj_feature_links",
+	"insights",
+	"goals", "goal_links",
+	"messages", "message_recipients", "inbox_index", "thread_index",
+	"id_sequences",
+}
+
+// ExportProject dumps project's rows from every snapshot table. A project
+// with no rows exports as a snapshot of empty tables.
+func (s *Store) ExportProject(ctx context.Context, project string) (core.ProjectSnapshot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.ProjectSnapshot{}, fmt.Errorf("begin export: %w", err)
+	}
+	defer tx.Rollback()
+
+	snap := core.ProjectSnapshot{
+		Version:    core.ProjectSnapshotVersion,
+		Project:    project,
+		ExportedAt: time.Now().UTC(),
+		Tables:     make(map[string][]map[string]any, len(snapshotTables)),
+	}
+	for _, table := range snapshotTables {
+		cols, err := s.dialectOf().TableColumns(ctx, tx, table)
+		if err != nil {
+			return core.ProjectSnapshot{}, err
+		}
+		rows, err := tx.QueryContext(ctx,
+			`SELECT `+strings.Join(cols, ", ")+` FROM `+table+` WHERE project = ? ORDER BY `+s.dialectOf().InsertionOrder(table), project)
+		if err != nil {
+			return core.ProjectSnapshot{}, fmt.Errorf("export %s: %w", table, err)
+		}
+		out := []map[string]any{}
+		for rows.Next() {
+			vals := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range vals {
+				ptrs[i] = &vals[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return core.ProjectSnapshot{}, fmt.Errorf("scan %s: %w", table, err)
+			}
+			row := make(map[string]any, len(cols)-1)
+			for i, c := range cols {
+				if c == "project" {
+					continue
+				}
+				if b, ok := vals[i].([]byte); ok {
+					vals[i] = string(b)
+				}
+				row[c] = vals[i]
+			}
+			out = append(out, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return core.ProjectSnapshot{}, err
+		}
+		snap.Tables[table] = out
+	}
+	return snap, nil
+}
+
+// ImportProject restores snap into project in one transaction. Returns
+// core.ErrAlreadyExists if project already has rows in any snapshot table,
+// and an error wrapping core.ErrInvalidSnapshot if snap can't be restored
+// here.
+func (s *Store) ImportProject(ctx context.Context, project string, snap core.ProjectSnapshot) (core.ProjectImport, error) {
+	if snap.Version != core.ProjectSnapshotVersion {
+		return core.ProjectImport{}, fmt.Errorf("%w: version %d (want %d)", core.ErrInvalidSnapshot, snap.Version, core.ProjectSnapshotVersion)
+	}
+	known := make(map[string]bool, len(snapshotTables))
+	for _, t := range snapshotTables {
+		known[t] = true
+	}
+	for t := range snap.Tables {
+		if !known[t] {
+			return core.ProjectImport{}, fmt.Errorf("%w: unknown table %q", core.ErrInvalidSnapshot, t)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.ProjectImport{}, fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range snapshotTables {
+		var n int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table+` WHERE project = ?`, project).Scan(&n); err != nil {
+			return core.ProjectImport{}, fmt.Errorf("check %s: %w", table, err)
+		}
+		if n > 0 {
+			return core.ProjectImport{}, core.ErrAlreadyExists
+		}
+	}
+
+	// Old inbox cursor → cursor of the event recorded for it here.
+	cursors := map[int64]int64{}
+	var lastCursor int64
+	if inbox := snap.Tables["inbox_index"]; len(inbox) > 0 {
+		var old []int64
+		messageOf := map[int64]string{}
+		for _, row := range inbox {
+			c, ok := snapshotInt(row["cursor"])
+			if !ok {
+				return core.ProjectImport{}, fmt.Errorf("%w: inbox_index row without a cursor", core.ErrInvalidSnapshot)
+			}
+			if _, seen := messageOf[c]; !seen {
+				old = append(old, c)
+			}
+			messageOf[c], _ = row["message_id"].(string)
+		}
+		sort.Slice(old, func(i, j int) bool { return old[i] < old[j] })
+		// Messages go in first so each event can copy its message's fields.
+		if _, err := s.importTable(ctx, tx, project, "messages", snap.Tables["messages"], nil); err != nil {
+			return core.ProjectImport{}, err
+		}
+		for _, c := range old {
+			var cursor int64
+			err := tx.QueryRowContext(ctx,
+				`INSERT INTO events (id, type, project, message_id, thread_id, from_agent, to_json, body, created_at)
+				 SELECT ?, ?, project, message_id, thread_id, from_agent, to_json, body, created_at
+				 FROM messages WHERE project = ? AND message_id = ?
+				 RETURNING cursor`,
+				uuid.NewString(), string(core.EventMessageCreated), project, messageOf[c],
+			).Scan(&cursor)
+			if errors.Is(err, sql.ErrNoRows) {
+				return core.ProjectImport{}, fmt.Errorf("%w: inbox entry for unknown message %q", core.ErrInvalidSnapshot, messageOf[c])
+			}
+			if err != nil {
+				return core.ProjectImport{}, fmt.Errorf("record message event: %w", err)
+			}
+			cursors[c] = cursor
+			lastCursor = cursor
+		}
+	}
+	remap := func(row map[string]any, col string) {
+		c, _ := snapshotInt(row[col])
+		if mapped, ok := cursors[c]; ok {
+			row[col] = mapped
+		} else {
+			row[col] = lastCursor
+		}
+	}
+
+	result := core.ProjectImport{Project: project, Rows: make(map[string]int64, len(snapshotTables))}
+	for _, table := range snapshotTables {
+		var fix func(map[string]any)
+		switch table {
+		case "messages":
+			if len(cursors) > 0 {
+				result.Rows[table] = int64(len(snap.Tables[table]))
+				continue // already in
+			}
+		case "inbox_index":
+			fix = func(row map[string]any) { remap(row, "cursor") }
+		case "thread_index":
+			fix = func(row map[string]any) { remap(row, "last_cursor") }
+		case "task_comments":
+			// Rows go in in their original order, so fresh cursors keep it.
+			fix = func(row map[string]any) { delete(row, "seq") }
+		}
+		n, err := s.importTable(ctx, tx, project, table, snap.Tables[table], fix)
+		if err != nil {
+			return core.ProjectImport{}, err
+		}
+		result.Rows[table] = n
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO projects (name, created_at) VALUES (?, ?) ON CONFLICT DO NOTHING`,
+		project, time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return core.ProjectImport{}, fmt.Errorf("register project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return core.ProjectImport{}, fmt.Errorf("commit import: %w", err)
+	}
+	return result, nil
+}
+
+// importTable inserts rows into table under project, after applying fix (if
+// set) to each.
+func (s *Store) importTable(ctx context.Context, tx dbTx, project, table string, rows []map[string]any, fix func(map[string]any)) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	cols, err := s.dialectOf().TableColumns(ctx, tx, table)
+	if err != nil {
+		return 0, err
+	}
+	have := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		have[c] = true
+	}
+	for _, row := range rows {
+		if fix != nil {
+			row = maps.Clone(row) // keep snap intact for a retried import
+			fix(row)
+		}
+		names := make([]string, 0, len(row)+1)
+		args := make([]any, 0, len(row)+1)
+		names = append(names, "project")
+		args = append(args, project)
+		keys := make([]string, 0, len(row))
+		for k := range row {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if k == "project" || isMillisTwin(table, k) {
+				continue
+			}
+			if !have[k] {
+				return 0, fmt.Errorf("%w: unknown column %s.%s", core.ErrInvalidSnapshot, table, k)
+			}
+			v, err := snapshotValue(row[k])
+			if err != nil {
+				return 0, fmt.Errorf("%w: %s.%s: %v", core.ErrInvalidSnapshot, table, k, err)
+			}
+			names = append(names, k)
+			args = append(args, v)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+table+` (`+strings.Join(names, ", ")+`) VALUES (`+strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")+`)`,
+			args...,
+		); err != nil {
+			return 0, fmt.Errorf("import %s: %w", table, err)
+		}
+	}
+	return int64(len(rows)), nil
+}
+
+// snapshotValue converts a decoded JSON value to one SQLite can store.
+// Whole numbers go in as integers whether or not the decoder used
+// json.Number.
+func snapshotValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string, int64:
+		return v, nil
+	case bool:
+		return boolToInt(v), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+		return v, nil
+	}
+	return nil, errors.New("unsupported value type")
+}
+
+func snapshotInt(v any) (int64, bool) {
+	n, err := snapshotValue(v)
+	i, ok := n.(int64)
+	return i, err == nil && ok
+}
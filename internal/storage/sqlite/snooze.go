@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Snooze operations
+//
+// A snoozed message stays in the recipient's inbox_index but is hidden from
+// inbox reads and unread counts until snoozed_until passes. Waking it moves
+// the inbox row to a fresh cursor so cursor-based pollers see it again.
+
+// SnoozeMessage hides a message from agentID's inbox until until and marks
+// it unread. Returns core.ErrNotFound if agentID isn't a recipient.
+func (s *Store) SnoozeMessage(ctx context.Context, project, messageID, agentID string, until time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin snooze: %w", err)
+	}
+	defer tx.Rollback()
+	var wasRead bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT read_at IS NOT NULL FROM message_recipients WHERE project = ? AND message_id = ? AND agent_id = ?`,
+		project, messageID, agentID,
+	).Scan(&wasRead); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return core.ErrNotFound
+		}
+		return fmt.Errorf("snooze message: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE message_recipients SET snoozed_until = ?, read_at = NULL
+		 WHERE project = ? AND message_id = ? AND agent_id = ?`,
+		until.UTC().Format(time.RFC3339Nano), project, messageID, agentID,
+	); err != nil {
+		return fmt.Errorf("snooze message: %w", err)
+	}
+	if wasRead {
+		if err := adjustThreadUnread(ctx, tx, project, messageID, agentID, 1); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit snooze: %w", err)
+	}
+	return nil
+}
+
+// ListSnoozed returns agentID's currently snoozed messages, soonest first.
+func (s *Store) ListSnoozed(ctx context.Context, project, agentID string) ([]core.SnoozedMessage, error) {
+	now := unixMillis(clock.Now())
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT i.cursor, i.project, m.message_id, m.thread_id, m.from_agent, m.to_json,
+			COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
+			m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, ''),
+			r.snoozed_until
+		 FROM message_recipients r
+		 JOIN inbox_index i ON i.project = r.project AND i.message_id = r.message_id AND i.agent = r.agent_id
+		 JOIN messages m ON m.project = r.project AND m.message_id = r.message_id
+		 WHERE r.project = ? AND r.agent_id = ? AND r.snoozed_ms > ?
+		 ORDER BY r.snoozed_ms ASC`,
+		project, agentID, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list snoozed: %w", err)
+	}
+	defer rows.Close()
+
+	var out []core.SnoozedMessage
+	for rows.Next() {
+		var until string
+		msg, err := scanMessageRow(rows, &until)
+		if err != nil {
+			return nil, fmt.Errorf("scan snoozed: %w", err)
+		}
+		parsed, _ := time.Parse(time.RFC3339Nano, until)
+		out = append(out, core.SnoozedMessage{Message: msg, Agent: agentID, Until: parsed})
+	}
+	return out, rows.Err()
+}
+
+// UnsnoozeMessage wakes one snoozed message immediately. Returns
+// core.ErrNotFound if it isn't snoozed for agentID.
+func (s *Store) UnsnoozeMessage(ctx context.Context, project, messageID, agentID string) (core.SnoozeWake, error) {
+	wakes, err := s.wake(ctx,
+		`SELECT project, agent_id, message_id FROM message_recipients
+		 WHERE project = ? AND message_id = ? AND agent_id = ? AND snoozed_until IS NOT NULL`,
+		project, messageID, agentID,
+	)
+	if err != nil {
+		return core.SnoozeWake{}, err
+	}
+	if len(wakes) == 0 {
+		return core.SnoozeWake{}, core.ErrNotFound
+	}
+	return wakes[0], nil
+}
+
+// WakeSnoozed re-delivers every snooze that has ended by now. Empty project
+// or agentID match all.
+func (s *Store) WakeSnoozed(ctx context.Context, project, agentID string, now time.Time) ([]core.SnoozeWake, error) {
+	query := `SELECT project, agent_id, message_id FROM message_recipients
+		 WHERE snoozed_ms <= ?`
+	args := []any{unixMillis(now)}
+	if project != "" {
+		query += " AND project = ?"
+		args = append(args, project)
+	}
+	if agentID != "" {
+		query += " AND agent_id = ?"
+		args = append(args, agentID)
+	}
+	return s.wake(ctx, query, args...)
+}
+
+// wake re-delivers the (project, agent_id, message_id) rows selected by
+// query: each gets a message.unsnoozed event whose cursor becomes the
+// recipient's new inbox position, and its snooze is cleared.
+func (s *Store) wake(ctx context.Context, query string, args ...any) ([]core.SnoozeWake, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin wake: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query due snoozes: %w", err)
+	}
+	var wakes []core.SnoozeWake
+	for rows.Next() {
+		var w core.SnoozeWake
+		if err := rows.Scan(&w.Project, &w.Agent, &w.MessageID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan due snooze: %w", err)
+		}
+		wakes = append(wakes, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(wakes) == 0 {
+		return nil, nil
+	}
+
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	for i, w := range wakes {
+		wakes[i].EventID = uuid.NewString()
+		var cursor int64
+		if err := tx.QueryRowContext(ctx,
+			`INSERT INTO events (id, type, agent, project, message_id, created_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING cursor`,
+			wakes[i].EventID, string(core.EventMessageUnsnoozed), w.Agent, w.Project, w.MessageID, now,
+		).Scan(&cursor); err != nil {
+			return nil, fmt.Errorf("insert unsnooze event: %w", err)
+		}
+		wakes[i].Cursor = uint64(cursor)
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE inbox_index SET cursor = ? WHERE project = ? AND agent = ? AND message_id = ?`,
+			cursor, w.Project, w.Agent, w.MessageID,
+		); err != nil {
+			return nil, fmt.Errorf("move inbox row: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE message_recipients SET snoozed_until = NULL WHERE project = ? AND message_id = ? AND agent_id = ?`,
+			w.Project, w.MessageID, w.Agent,
+		); err != nil {
+			return nil, fmt.Errorf("clear snooze: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit wake: %w", err)
+	}
+	return wakes, nil
+}
@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Spec freshness
+//
+// A validated spec is reviewed when it moves to validated and whenever
+// ReviewSpec marks it reviewed. Once it goes longer than its project's
+// staleness window without a review, MarkStaleSpecs records spec.stale for
+// it, once: stale_notified_at holds it back until the next review.
+
+func migrateSpecFreshness(db *sql.DB) error {
+	if tableExists(db, "specs") {
+		for _, col := range []string{"last_reviewed_at", "stale_notified_at"} {
+			if tableHasColumn(db, "specs", col) {
+				continue
+			}
+			if _, err := db.Exec(`ALTER TABLE specs ADD COLUMN ` + col + ` TEXT`); err != nil {
+				return fmt.Errorf("add %s column: %w", col, err)
+			}
+		}
+	}
+	if tableExists(db, "projects") && !tableHasColumn(db, "projects", "spec_stale_days") {
+		if _, err := db.Exec(`ALTER TABLE projects ADD COLUMN spec_stale_days INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add spec_stale_days column: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReviewSpec records that a spec was reviewed now, restarting its
+// staleness window. Like a heartbeat it leaves the version alone, so a
+// review doesn't conflict with an edit in flight. Returns core.ErrNotFound
+// for an unknown spec.
+func (s *Store) ReviewSpec(ctx context.Context, project, id string) (core.Spec, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE specs SET last_reviewed_at = ?, stale_notified_at = NULL WHERE project = ? AND id = ?`,
+		clock.Now().UTC().Format(time.RFC3339Nano), project, id,
+	)
+	if err != nil {
+		return core.Spec{}, fmt.Errorf("review spec: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.Spec{}, core.ErrNotFound
+	}
+	return s.GetSpec(ctx, project, id)
+}
+
+// MarkStaleSpecs finds the validated specs that have gone unreviewed for
+// longer than their project's window as of now and haven't been reported
+// since their last review, and appends a spec.stale event for each.
+func (s *Store) MarkStaleSpecs(ctx context.Context, now time.Time) ([]core.StaleSpec, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin mark stale specs: %w", err)
+	}
+	defer tx.Rollback()
+
+	windows, err := specStaleWindowsTx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+specColumns+` FROM specs
+		 WHERE status = ? AND stale_notified_at IS NULL
+		 ORDER BY project, id`,
+		string(core.SpecStatusValidated),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list validated specs: %w", err)
+	}
+	var stale []core.Spec
+	for rows.Next() {
+		spec, err := scanSpec(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if spec.IsStale(now, core.SpecStaleWindow(windows[spec.Project])) {
+			stale = append(stale, spec)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var out []core.StaleSpec
+	for _, spec := range stale {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE specs SET stale_notified_at = ? WHERE project = ? AND id = ?`,
+			now.UTC().Format(time.RFC3339Nano), spec.Project, spec.ID,
+		); err != nil {
+			return nil, fmt.Errorf("mark spec stale: %w", err)
+		}
+		spec.Stale = true
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return nil, err
+		}
+		marked := core.StaleSpec{Spec: spec, EventID: uuid.NewString()}
+		marked.Cursor, err = s.appendEventTx(ctx, tx, core.Event{
+			ID:         marked.EventID,
+			Type:       core.EventSpecStale,
+			Project:    spec.Project,
+			EntityType: core.EventSpecStale.EntityType(),
+			EntityID:   spec.ID,
+			Data:       string(data),
+			CreatedAt:  now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("record spec stale: %w", err)
+		}
+		out = append(out, marked)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit mark stale specs: %w", err)
+	}
+	return out, nil
+}
+
+// specStaleWindowsTx maps each project with its own staleness window to
+// its spec_stale_days; projects missing from the map use the default.
+func specStaleWindowsTx(ctx context.Context, tx dbTx) (map[string]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name, spec_stale_days FROM projects WHERE spec_stale_days > 0`)
+	if err != nil {
+		return nil, fmt.Errorf("list spec stale windows: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var name string
+		var days int
+		if err := rows.Scan(&name, &days); err != nil {
+			return nil, fmt.Errorf("scan spec stale window: %w", err)
+		}
+		out[name] = days
+	}
+	return out, rows.Err()
+}
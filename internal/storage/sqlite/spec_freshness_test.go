@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestMarkStaleSpecs(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(clock.Reset)
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateProject(ctx, core.Project{Name: "fast", SpecStaleDays: 7}); err != nil {
+		t.Fatal(err)
+	}
+	create := func(project, title string, status core.SpecStatus) core.Spec {
+		t.Helper()
+		s, err := store.CreateSpec(ctx, core.Spec{Project: project, Title: title, Status: status})
+		if err != nil {
+			t.Fatalf("create spec %s: %v", title, err)
+		}
+		return s
+	}
+	weekly := create("fast", "weekly", core.SpecStatusValidated)
+	if weekly.LastReviewedAt == nil {
+		t.Fatalf("validated spec created without last_reviewed_at: %+v", weekly)
+	}
+	quarterly := create("slow", "quarterly", core.SpecStatusValidated)
+	draft := create("fast", "draft", core.SpecStatusDraft)
+	if draft.LastReviewedAt != nil {
+		t.Fatalf("draft spec has last_reviewed_at: %+v", draft)
+	}
+
+	clock.Advance(8 * 24 * time.Hour)
+	stale, err := store.MarkStaleSpecs(ctx, clock.Now())
+	if err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+	if len(stale) != 1 || stale[0].Spec.ID != weekly.ID || !stale[0].Spec.Stale || stale[0].Cursor == 0 {
+		t.Fatalf("stale = %+v, want only the 7-day project's spec", stale)
+	}
+	if again, err := store.MarkStaleSpecs(ctx, clock.Now()); err != nil || len(again) != 0 {
+		t.Fatalf("second mark = %+v, %v; want nothing until the next review", again, err)
+	}
+	events, err := store.ListDomainEvents(ctx, core.EventFilter{Project: "fast", EntityType: "spec", EntityID: weekly.ID})
+	if err != nil || len(events) != 1 || events[0].Type != core.EventSpecStale {
+		t.Fatalf("weekly events = %+v, %v", events, err)
+	}
+
+	reviewed, err := store.ReviewSpec(ctx, "fast", weekly.ID)
+	if err != nil || reviewed.Version != weekly.Version || reviewed.LastReviewedAt.Before(weekly.UpdatedAt.Add(8*24*time.Hour)) {
+		t.Fatalf("review = %+v, %v; want last_reviewed_at moved to now and version unchanged", reviewed, err)
+	}
+	if _, err := store.ReviewSpec(ctx, "fast", "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("review missing err = %v", err)
+	}
+
+	// Leaving validated and coming back is a fresh review.
+	clock.Advance(100 * 24 * time.Hour)
+	quarterly.Status = core.SpecStatusResearch
+	if quarterly, err = store.UpdateSpec(ctx, quarterly); err != nil {
+		t.Fatal(err)
+	}
+	quarterly.Status = core.SpecStatusValidated
+	if quarterly, err = store.UpdateSpec(ctx, quarterly); err != nil {
+		t.Fatal(err)
+	}
+	if !quarterly.LastReviewedAt.Equal(quarterly.UpdatedAt) {
+		t.Fatalf("revalidated spec = %+v, want last_reviewed_at = updated_at", quarterly)
+	}
+	stale, err = store.MarkStaleSpecs(ctx, clock.Now())
+	if err != nil || len(stale) != 1 || stale[0].Spec.ID != weekly.ID {
+		t.Fatalf("stale after review lapsed = %+v, %v; want weekly again", stale, err)
+	}
+}
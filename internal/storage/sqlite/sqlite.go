@@ -7,16 +7,20 @@ import (
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	_ "modernc.org/sqlite"
 
 	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
 	"github.com/mistakeknot/intermute/internal/core"
 	"github.com/mistakeknot/intermute/internal/glob"
 	"github.com/mistakeknot/intermute/internal/storage"
@@ -39,8 +43,9 @@ const (
 )
 
 type Store struct {
-	db     dbHandle
-	bridge *CoordinationBridge
+	db      dbHandle
+	bridge  *CoordinationBridge
+	dialect Dialect // nil for SQLite; see dialectOf
 }
 
 func New(path string) (*Store, error) {
@@ -85,12 +90,31 @@ func (s *Store) Bridge() *CoordinationBridge {
 	return s.bridge
 }
 
+// memDBSeq names each in-memory database so stores opened in the same
+// process never share state.
+var memDBSeq atomic.Uint64
+
+// NewInMemory opens an ephemeral store for tests and throwaway deployments.
+// A bare ":memory:" DSN gives every pooled connection its own empty
+// database, so the store names a shared-cache memory database instead and,
+// like New, serializes access through a single connection. That connection
+// is never recycled: the database lives exactly as long as it does.
 func NewInMemory() (*Store, error) {
-	db, err := sql.Open("sqlite", ":memory:")
+	dsn := fmt.Sprintf("file:intermute-mem-%d?mode=memory&cache=shared", memDBSeq.Add(1))
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
 	if err := applySchema(db); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 	return &Store{db: &queryLogger{inner: db}}, nil
@@ -103,24 +127,60 @@ func applySchema(db *sql.DB) error {
 	if err := migrateMessages(db); err != nil {
 		return err
 	}
+	if err := migrateEventActor(db); err != nil {
+		return err
+	}
+	if err := migrateEventEntity(db); err != nil {
+		return err
+	}
+	if err := migrateEventTrace(db); err != nil {
+		return err
+	}
 	if err := migrateInboxIndex(db); err != nil {
 		return err
 	}
 	if err := migrateThreadIndex(db); err != nil {
 		return err
 	}
+	if err := migrateThreadUnread(db); err != nil {
+		return err
+	}
 	if err := migrateMessagesMetadata(db); err != nil {
 		return err
 	}
 	if err := migrateMessageTransport(db); err != nil {
 		return err
 	}
+	if err := migrateMessageInReplyTo(db); err != nil {
+		return err
+	}
+	if err := migrateMessageGroups(db); err != nil {
+		return err
+	}
 	if err := migrateMessageRecipientsInjectedAt(db); err != nil {
 		return err
 	}
+	if err := migrateMessageRecipientsSnoozedUntil(db); err != nil {
+		return err
+	}
 	if err := migrateDomainVersions(db); err != nil {
 		return err
 	}
+	if err := migrateInsightUpdatedAt(db); err != nil {
+		return err
+	}
+	if err := migrateInsightStatus(db); err != nil {
+		return err
+	}
+	if err := migrateInsightContentHash(db); err != nil {
+		return err
+	}
+	if err := migrateTaskDueAt(db); err != nil {
+		return err
+	}
+	if err := migratePriority(db); err != nil {
+		return err
+	}
 	if err := migrateAgentSessionID(db); err != nil {
 		return err
 	}
@@ -148,6 +208,60 @@ func applySchema(db *sql.DB) error {
 	if err := migrateConfigTable(db); err != nil {
 		return err
 	}
+	if err := migrateSearchIndex(db); err != nil {
+		return err
+	}
+	if err := migrateShortIDs(db); err != nil {
+		return err
+	}
+	if err := migrateTaskCapabilities(db); err != nil {
+		return err
+	}
+	if err := migrateTaskLeases(db); err != nil {
+		return err
+	}
+	if err := migrateSessionHeartbeats(db); err != nil {
+		return err
+	}
+	if err := migrateContactGroupTeam(db); err != nil {
+		return err
+	}
+	if err := migrateTaskExpectedPaths(db); err != nil {
+		return err
+	}
+	if err := migrateStoryRank(db); err != nil {
+		return err
+	}
+	if err := migrateTaskBlocks(db); err != nil {
+		return err
+	}
+	if err := migrateProjects(db); err != nil {
+		return err
+	}
+	if err := migrateUnixMillis(db); err != nil {
+		return err
+	}
+	if err := migrateMessageAttachments(db); err != nil {
+		return err
+	}
+	if err := migrateMessageRoute(db); err != nil {
+		return err
+	}
+	if err := migrateMessageAckPolicy(db); err != nil {
+		return err
+	}
+	if err := migrateSpecFreshness(db); err != nil {
+		return err
+	}
+	if err := migrateIdempotencyHeaders(db); err != nil {
+		return err
+	}
+	if err := migrateTitleKeys(db); err != nil {
+		return err
+	}
+	if err := migrateInsightDedupKey(db); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -248,9 +362,17 @@ func (s *Store) AppendEvents(ctx context.Context, evs ...core.Event) ([]uint64,
 	}
 	defer tx.Rollback()
 
+	actor := core.ActorFromContext(ctx)
+	trace := core.TraceFromContext(ctx)
 	cursors := make([]uint64, 0, len(evs))
 	for _, ev := range evs {
-		cursor, err := s.appendEventTx(tx, ev)
+		if ev.Actor == "" {
+			ev.Actor = actor
+		}
+		if ev.Trace == (core.Trace{}) {
+			ev.Trace = trace
+		}
+		cursor, err := s.appendEventTx(ctx, tx, ev)
 		if err != nil {
 			return nil, err
 		}
@@ -263,12 +385,24 @@ func (s *Store) AppendEvents(ctx context.Context, evs ...core.Event) ([]uint64,
 	return cursors, nil
 }
 
-func (s *Store) appendEventTx(tx *sql.Tx, ev core.Event) (uint64, error) {
+// appendEventTx inserts ev and its side effects. An event whose ID is
+// already recorded is not applied again; its original cursor is returned,
+// so a retried append is idempotent.
+func (s *Store) appendEventTx(ctx context.Context, tx dbTx, ev core.Event) (uint64, error) {
 	if ev.ID == "" {
 		ev.ID = uuid.NewString()
+	} else {
+		var existing uint64
+		err := tx.QueryRowContext(ctx, `SELECT cursor FROM events WHERE id = ?`, ev.ID).Scan(&existing)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("check event id: %w", err)
+		}
 	}
 	if ev.CreatedAt.IsZero() {
-		ev.CreatedAt = time.Now().UTC()
+		ev.CreatedAt = clock.Now().UTC()
 	}
 	project := strings.TrimSpace(ev.Project)
 	if project == "" {
@@ -284,70 +418,25 @@ func (s *Store) appendEventTx(tx *sql.Tx, ev core.Event) (uint64, error) {
 		return 0, fmt.Errorf("marshal recipients: %w", err)
 	}
 
-	res, err := tx.Exec(
-		`INSERT INTO events (id, type, agent, project, message_id, thread_id, from_agent, to_json, body, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		ev.ID, string(ev.Type), ev.Agent, project, ev.Message.ID, ev.Message.ThreadID, ev.Message.From, string(toJSON), ev.Message.Body, ev.CreatedAt.Format(time.RFC3339Nano),
-	)
-	if err != nil {
+	var cursor int64
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO events (id, type, agent, project, message_id, thread_id, from_agent, to_json, body, actor,
+		   request_id, correlation_id, causation_id, entity_type, entity_id, data, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING cursor`,
+		ev.ID, string(ev.Type), ev.Agent, project, ev.Message.ID, ev.Message.ThreadID, ev.Message.From, string(toJSON), ev.Message.Body, ev.Actor,
+		ev.Trace.RequestID, ev.Trace.CorrelationID, ev.Trace.CausationID,
+		ev.EntityType, ev.EntityID, ev.Data, ev.CreatedAt.Format(time.RFC3339Nano),
+	).Scan(&cursor); err != nil {
 		return 0, fmt.Errorf("insert event: %w", err)
 	}
-	cursor, err := res.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("cursor: %w", err)
-	}
 
 	if ev.Type == core.EventMessageCreated {
-		if err := s.upsertMessageTx(tx, project, ev.Message); err != nil {
-			return 0, err
-		}
-		recipients := ev.Message.To
-		if len(recipients) == 0 && ev.Agent != "" {
-			recipients = []string{ev.Agent}
-		}
-		for _, agent := range recipients {
-			if _, err := tx.Exec(
-				`INSERT INTO inbox_index (project, agent, cursor, message_id) VALUES (?, ?, ?, ?)`,
-				project, agent, cursor, ev.Message.ID,
-			); err != nil {
-				return 0, fmt.Errorf("insert inbox: %w", err)
-			}
-		}
-		// Insert into message_recipients for per-recipient tracking
-		if err := s.insertRecipientsTx(tx, project, ev.Message.ID, ev.Message.To, "to"); err != nil {
+		if err := s.upsertMessageTx(ctx, tx, project, ev.Message); err != nil {
 			return 0, err
 		}
-		if err := s.insertRecipientsTx(tx, project, ev.Message.ID, ev.Message.CC, "cc"); err != nil {
+		if err := s.indexMessageTx(ctx, tx, project, ev.Agent, cursor, ev.Message, ev.CreatedAt); err != nil {
 			return 0, err
 		}
-		if err := s.insertRecipientsTx(tx, project, ev.Message.ID, ev.Message.BCC, "bcc"); err != nil {
-			return 0, err
-		}
-		// Update thread_index if message has a thread ID
-		if ev.Message.ThreadID != "" {
-			participants := append([]string{ev.Message.From}, recipients...)
-			lastBody := ev.Message.Body
-			if len(lastBody) > 200 {
-				lastBody = lastBody[:200]
-			}
-			for _, agent := range participants {
-				if _, err := tx.Exec(
-					`INSERT INTO thread_index (project, thread_id, agent, last_cursor, message_count,
-					   last_message_from, last_message_body, last_message_at)
-					 VALUES (?, ?, ?, ?, 1, ?, ?, ?)
-					 ON CONFLICT(project, thread_id, agent) DO UPDATE SET
-					   last_cursor = excluded.last_cursor,
-					   message_count = thread_index.message_count + 1,
-					   last_message_from = excluded.last_message_from,
-					   last_message_body = excluded.last_message_body,
-					   last_message_at = excluded.last_message_at`,
-					project, ev.Message.ThreadID, agent, cursor,
-					ev.Message.From, lastBody, ev.CreatedAt.Format(time.RFC3339Nano),
-				); err != nil {
-					return 0, fmt.Errorf("upsert thread_index: %w", err)
-				}
-			}
-		}
 	}
 
 	if ev.Type == core.EventPeerWindowPoke {
@@ -358,10 +447,10 @@ func (s *Store) appendEventTx(tx *sql.Tx, ev core.Event) (uint64, error) {
 		switch result {
 		case core.PokeResultDeferred:
 			for _, rcpt := range ev.Message.To {
-				if _, err := tx.Exec(
-					`INSERT OR IGNORE INTO pending_pokes
+				if _, err := tx.ExecContext(ctx,
+					`INSERT INTO pending_pokes
 					 (project, recipient, message_id, sender, body, created_at, surfaced_at)
-					 VALUES (?, ?, ?, ?, ?, ?, NULL)`,
+					 VALUES (?, ?, ?, ?, ?, ?, NULL) ON CONFLICT DO NOTHING`,
 					project, rcpt, ev.Message.ID, ev.Message.From, ev.Message.Body, ev.CreatedAt.Format(time.RFC3339Nano),
 				); err != nil {
 					return 0, fmt.Errorf("stage pending poke: %w", err)
@@ -369,7 +458,7 @@ func (s *Store) appendEventTx(tx *sql.Tx, ev core.Event) (uint64, error) {
 			}
 		case core.PokeResultInjected:
 			for _, rcpt := range ev.Message.To {
-				if _, err := tx.Exec(
+				if _, err := tx.ExecContext(ctx,
 					`UPDATE message_recipients SET injected_at = ?
 					 WHERE project = ? AND message_id = ? AND agent_id = ?`,
 					ev.CreatedAt.Format(time.RFC3339Nano), project, ev.Message.ID, rcpt,
@@ -382,12 +471,77 @@ func (s *Store) appendEventTx(tx *sql.Tx, ev core.Event) (uint64, error) {
 	return uint64(cursor), nil
 }
 
-func (s *Store) upsertMessageTx(tx *sql.Tx, project string, msg core.Message) error {
+// indexMessageTx delivers a message.created event's message: an
+// inbox_index row per recipient (the event's agent when it has none), its
+// message_recipients rows, and its thread_index rows when it's threaded.
+// RebuildIndexes replays it over the events table.
+func (s *Store) indexMessageTx(ctx context.Context, tx dbTx, project, eventAgent string, cursor int64, msg core.Message, at time.Time) error {
+	recipients := msg.Recipients()
+	if len(recipients) == 0 && eventAgent != "" {
+		recipients = []string{eventAgent}
+	}
+	for _, agent := range recipients {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO inbox_index (project, agent, cursor, message_id) VALUES (?, ?, ?, ?)`,
+			project, agent, cursor, msg.ID,
+		); err != nil {
+			return fmt.Errorf("insert inbox: %w", err)
+		}
+	}
+	// Insert into message_recipients for per-recipient tracking
+	if err := s.insertRecipientsTx(ctx, tx, project, msg.ID, msg.To, "to"); err != nil {
+		return err
+	}
+	if err := s.insertRecipientsTx(ctx, tx, project, msg.ID, msg.CC, "cc"); err != nil {
+		return err
+	}
+	if err := s.insertRecipientsTx(ctx, tx, project, msg.ID, msg.BCC, "bcc"); err != nil {
+		return err
+	}
+	// Update thread_index if message has a thread ID
+	if msg.ThreadID != "" {
+		participants := recipients
+		if !slices.Contains(recipients, msg.From) {
+			participants = append([]string{msg.From}, recipients...)
+		}
+		lastBody := msg.Body
+		if len(lastBody) > 200 {
+			lastBody = lastBody[:200]
+		}
+		addressed := msg.Recipients()
+		for _, agent := range participants {
+			// Only recipients have a message_recipients row to read.
+			unread := 0
+			if slices.Contains(addressed, agent) {
+				unread = 1
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO thread_index (project, thread_id, agent, last_cursor, message_count,
+				   last_message_from, last_message_body, last_message_at, unread_count)
+				 VALUES (?, ?, ?, ?, 1, ?, ?, ?, ?)
+				 ON CONFLICT(project, thread_id, agent) DO UPDATE SET
+				   last_cursor = excluded.last_cursor,
+				   message_count = thread_index.message_count + 1,
+				   last_message_from = excluded.last_message_from,
+				   last_message_body = excluded.last_message_body,
+				   last_message_at = excluded.last_message_at,
+				   unread_count = thread_index.unread_count + excluded.unread_count`,
+				project, msg.ThreadID, agent, cursor,
+				msg.From, lastBody, at.Format(time.RFC3339Nano), unread,
+			); err != nil {
+				return fmt.Errorf("upsert thread_index: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Store) upsertMessageTx(ctx context.Context, tx dbTx, project string, msg core.Message) error {
 	if project == "" {
 		project = msg.Project
 	}
 	if msg.CreatedAt.IsZero() {
-		msg.CreatedAt = time.Now().UTC()
+		msg.CreatedAt = clock.Now().UTC()
 	}
 	toJSON, err := json.Marshal(msg.To)
 	if err != nil {
@@ -401,17 +555,35 @@ func (s *Store) upsertMessageTx(tx *sql.Tx, project string, msg core.Message) er
 	if err != nil {
 		return fmt.Errorf("marshal bcc: %w", err)
 	}
+	groupsJSON := []byte("{}")
+	if len(msg.Groups) > 0 {
+		if groupsJSON, err = json.Marshal(msg.Groups); err != nil {
+			return fmt.Errorf("marshal groups: %w", err)
+		}
+	}
+	attachmentsJSON := []byte("[]")
+	if len(msg.Attachments) > 0 {
+		if attachmentsJSON, err = json.Marshal(msg.Attachments); err != nil {
+			return fmt.Errorf("marshal attachments: %w", err)
+		}
+	}
+	var routeJSON []byte
+	if msg.Route != nil {
+		if routeJSON, err = json.Marshal(msg.Route); err != nil {
+			return fmt.Errorf("marshal route: %w", err)
+		}
+	}
 	ackRequired := 0
 	if msg.AckRequired {
 		ackRequired = 1
 	}
 	topic := strings.ToLower(strings.TrimSpace(msg.Topic))
 	transport := string(core.TransportOrDefault(msg.Transport))
-	if _, err := tx.Exec(
-		`INSERT INTO messages (project, message_id, thread_id, from_agent, to_json, cc_json, bcc_json, subject, body, importance, ack_required, topic, transport, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(project, message_id) DO UPDATE SET thread_id=excluded.thread_id, from_agent=excluded.from_agent, to_json=excluded.to_json, cc_json=excluded.cc_json, bcc_json=excluded.bcc_json, subject=excluded.subject, body=excluded.body, importance=excluded.importance, ack_required=excluded.ack_required, topic=excluded.topic, transport=excluded.transport`,
-		project, msg.ID, msg.ThreadID, msg.From, string(toJSON), string(ccJSON), string(bccJSON), msg.Subject, msg.Body, msg.Importance, ackRequired, topic, transport, msg.CreatedAt.Format(time.RFC3339Nano),
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO messages (project, message_id, thread_id, from_agent, to_json, cc_json, bcc_json, subject, body, importance, ack_required, topic, transport, in_reply_to, groups_json, attachments_json, route_json, ack_policy, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(project, message_id) DO UPDATE SET thread_id=excluded.thread_id, from_agent=excluded.from_agent, to_json=excluded.to_json, cc_json=excluded.cc_json, bcc_json=excluded.bcc_json, subject=excluded.subject, body=excluded.body, importance=excluded.importance, ack_required=excluded.ack_required, topic=excluded.topic, transport=excluded.transport, in_reply_to=excluded.in_reply_to, groups_json=excluded.groups_json, attachments_json=excluded.attachments_json, route_json=excluded.route_json, ack_policy=excluded.ack_policy`,
+		project, msg.ID, msg.ThreadID, msg.From, string(toJSON), string(ccJSON), string(bccJSON), msg.Subject, msg.Body, msg.Importance, ackRequired, topic, transport, msg.InReplyTo, string(groupsJSON), string(attachmentsJSON), string(routeJSON), string(msg.AckPolicy), msg.CreatedAt.Format(time.RFC3339Nano),
 	); err != nil {
 		return fmt.Errorf("upsert message: %w", err)
 	}
@@ -419,19 +591,22 @@ func (s *Store) upsertMessageTx(tx *sql.Tx, project string, msg core.Message) er
 }
 
 // scanMessageRow scans a single row from a messages query into a core.Message.
-// The query must SELECT exactly 15 columns in this order:
+// The query must SELECT exactly 20 columns in this order:
 // cursor, project, message_id, thread_id, from_agent, to_json, cc_json, bcc_json,
-// subject, body, importance, ack_required, topic, transport, created_at.
-func scanMessageRow(rows *sql.Rows) (core.Message, error) {
+// subject, body, importance, ack_required, topic, transport, created_at, in_reply_to,
+// groups_json, attachments_json, route_json, ack_policy. Any extra destinations scan
+// the columns that follow.
+func scanMessageRow(rows *sql.Rows, extra ...any) (core.Message, error) {
 	var (
 		cur                                                                                   int64
 		proj                                                                                  string
 		msgID, threadID, fromAgent, toJSON, ccJSON, bccJSON, subject, body, importance, topic string
-		transport                                                                             string
+		transport, inReplyTo, groupsJSON, attachmentsJSON, routeJSON, ackPolicy               string
 		ackRequired                                                                           int
 		createdAt                                                                             string
 	)
-	if err := rows.Scan(&cur, &proj, &msgID, &threadID, &fromAgent, &toJSON, &ccJSON, &bccJSON, &subject, &body, &importance, &ackRequired, &topic, &transport, &createdAt); err != nil {
+	dest := []any{&cur, &proj, &msgID, &threadID, &fromAgent, &toJSON, &ccJSON, &bccJSON, &subject, &body, &importance, &ackRequired, &topic, &transport, &createdAt, &inReplyTo, &groupsJSON, &attachmentsJSON, &routeJSON, &ackPolicy}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
 		return core.Message{}, err
 	}
 	var to, cc, bcc []string
@@ -444,6 +619,23 @@ func scanMessageRow(rows *sql.Rows) (core.Message, error) {
 	if err := json.Unmarshal([]byte(bccJSON), &bcc); err != nil {
 		log.Printf("WARN: corrupt bcc_json for message %s: %v", msgID, err)
 	}
+	var groups map[string][]string
+	if err := json.Unmarshal([]byte(groupsJSON), &groups); err != nil {
+		log.Printf("WARN: corrupt groups_json for message %s: %v", msgID, err)
+	}
+	if len(groups) == 0 {
+		groups = nil
+	}
+	var attachments []core.Attachment
+	if err := json.Unmarshal([]byte(attachmentsJSON), &attachments); err != nil {
+		log.Printf("WARN: corrupt attachments_json for message %s: %v", msgID, err)
+	}
+	var route *core.MessageRoute
+	if routeJSON != "" {
+		if err := json.Unmarshal([]byte(routeJSON), &route); err != nil {
+			log.Printf("WARN: corrupt route_json for message %s: %v", msgID, err)
+		}
+	}
 	parsed, _ := time.Parse(time.RFC3339Nano, createdAt)
 	return core.Message{
 		ID:          msgID,
@@ -457,8 +649,13 @@ func scanMessageRow(rows *sql.Rows) (core.Message, error) {
 		Topic:       topic,
 		Body:        body,
 		Importance:  importance,
+		InReplyTo:   inReplyTo,
+		Groups:      groups,
+		Attachments: attachments,
+		Route:       route,
 		Transport:   core.TransportOrDefault(core.TransportMode(transport)),
 		AckRequired: ackRequired == 1,
+		AckPolicy:   core.AckPolicy(ackPolicy),
 		CreatedAt:   parsed,
 		Cursor:      uint64(cur),
 	}, nil
@@ -480,7 +677,7 @@ func collectMessages(rows *sql.Rows) ([]core.Message, error) {
 	return msgs, nil
 }
 
-func (s *Store) InboxSince(_ context.Context, project, agent string, cursor uint64, limit int) ([]core.Message, error) {
+func (s *Store) InboxSince(ctx context.Context, project, agent string, cursor uint64, limit int) ([]core.Message, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -489,18 +686,20 @@ func (s *Store) InboxSince(_ context.Context, project, agent string, cursor uint
 	}
 	query := `SELECT i.cursor, i.project, m.message_id, m.thread_id, m.from_agent, m.to_json,
 		COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
-		m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at
+		m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, '')
 	 FROM inbox_index i
 	 JOIN messages m ON m.project = i.project AND m.message_id = i.message_id
-	 WHERE i.agent = ? AND i.cursor > ?`
-	args := []any{agent, cursor}
+	 WHERE ` + addressedTo("i.agent", "i.project") + ` AND i.cursor > ?
+	   AND NOT EXISTS (SELECT 1 FROM message_recipients r
+	     WHERE r.project = i.project AND r.message_id = i.message_id AND r.agent_id = i.agent AND r.snoozed_ms > ?)`
+	args := []any{agent, agent, cursor, unixMillis(clock.Now())}
 	if project != "" {
 		query += " AND i.project = ?"
 		args = append(args, project)
 	}
 	query += " ORDER BY i.cursor ASC LIMIT ?"
 	args = append(args, limit)
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query inbox: %w", err)
 	}
@@ -512,16 +711,26 @@ func (s *Store) InboxSince(_ context.Context, project, agent string, cursor uint
 	return msgs, nil
 }
 
-func (s *Store) ThreadMessages(_ context.Context, project, threadID string, cursor uint64) ([]core.Message, error) {
+// CurrentCursor returns the highest event cursor assigned so far, or 0 when
+// no events exist. Clients resume inbox streaming from this value.
+func (s *Store) CurrentCursor(ctx context.Context) (uint64, error) {
+	var cursor int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(cursor), 0) FROM events`).Scan(&cursor); err != nil {
+		return 0, fmt.Errorf("current cursor: %w", err)
+	}
+	return uint64(cursor), nil
+}
+
+func (s *Store) ThreadMessages(ctx context.Context, project, threadID string, cursor uint64) ([]core.Message, error) {
 	query := `SELECT MAX(i.cursor) AS cursor, m.project, m.message_id, m.thread_id, m.from_agent, m.to_json,
 		COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
-		m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at
+		m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, '')
 	 FROM inbox_index i
 	 JOIN messages m ON m.project = i.project AND m.message_id = i.message_id
 	 WHERE m.project = ? AND m.thread_id = ? AND i.cursor > ?
-	 GROUP BY m.message_id
+	 GROUP BY m.project, m.message_id
 	 ORDER BY m.created_at ASC`
-	rows, err := s.db.Query(query, project, threadID, cursor)
+	rows, err := s.db.QueryContext(ctx, query, project, threadID, cursor)
 	if err != nil {
 		return nil, fmt.Errorf("query thread: %w", err)
 	}
@@ -533,12 +742,35 @@ func (s *Store) ThreadMessages(_ context.Context, project, threadID string, curs
 	return msgs, nil
 }
 
-func (s *Store) ListThreads(_ context.Context, project, agent string, cursor uint64, limit int) ([]storage.ThreadSummary, error) {
+// GetMessage returns a single message by ID. Cursor is the message's first
+// inbox cursor, or 0 if it was never delivered.
+func (s *Store) GetMessage(ctx context.Context, project, messageID string) (core.Message, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT COALESCE((SELECT MIN(i.cursor) FROM inbox_index i WHERE i.project = m.project AND i.message_id = m.message_id), 0),
+		m.project, m.message_id, COALESCE(m.thread_id, ''), m.from_agent, m.to_json,
+		COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
+		m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, '')
+	 FROM messages m
+	 WHERE m.project = ? AND m.message_id = ?`, project, messageID)
+	if err != nil {
+		return core.Message{}, fmt.Errorf("query message: %w", err)
+	}
+	defer rows.Close()
+	msgs, err := collectMessages(rows)
+	if err != nil {
+		return core.Message{}, fmt.Errorf("message: %w", err)
+	}
+	if len(msgs) == 0 {
+		return core.Message{}, core.ErrNotFound
+	}
+	return msgs[0], nil
+}
+
+func (s *Store) ListThreads(ctx context.Context, project, agent string, cursor uint64, limit int) ([]storage.ThreadSummary, error) {
 	if limit <= 0 {
 		limit = 50
 	}
 	query := `SELECT thread_id, last_cursor, message_count,
-	   last_message_from, last_message_body, last_message_at
+	   last_message_from, last_message_body, last_message_at, unread_count
 	 FROM thread_index
 	 WHERE project = ? AND agent = ?`
 	args := []any{project, agent}
@@ -551,7 +783,7 @@ func (s *Store) ListThreads(_ context.Context, project, agent string, cursor uin
 	 ORDER BY last_cursor DESC
 	 LIMIT ?`
 	args = append(args, limit)
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query threads: %w", err)
 	}
@@ -562,9 +794,9 @@ func (s *Store) ListThreads(_ context.Context, project, agent string, cursor uin
 		var (
 			threadID, lastFrom, lastBody, lastAt string
 			lastCursor                           int64
-			messageCount                         int
+			messageCount, unread                 int
 		)
-		if err := rows.Scan(&threadID, &lastCursor, &messageCount, &lastFrom, &lastBody, &lastAt); err != nil {
+		if err := rows.Scan(&threadID, &lastCursor, &messageCount, &lastFrom, &lastBody, &lastAt, &unread); err != nil {
 			return nil, fmt.Errorf("scan thread: %w", err)
 		}
 		parsed, _ := time.Parse(time.RFC3339Nano, lastAt)
@@ -575,6 +807,7 @@ func (s *Store) ListThreads(_ context.Context, project, agent string, cursor uin
 			LastFrom:     lastFrom,
 			LastBody:     lastBody,
 			LastAt:       parsed,
+			Unread:       unread,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -583,7 +816,7 @@ func (s *Store) ListThreads(_ context.Context, project, agent string, cursor uin
 	return out, nil
 }
 
-func (s *Store) TopicMessages(_ context.Context, project, topic string, cursor uint64, limit int) ([]core.Message, error) {
+func (s *Store) TopicMessages(ctx context.Context, project, topic string, cursor uint64, limit int) ([]core.Message, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -591,10 +824,10 @@ func (s *Store) TopicMessages(_ context.Context, project, topic string, cursor u
 		limit = 1000
 	}
 	topic = strings.ToLower(strings.TrimSpace(topic))
-	rows, err := s.db.Query(
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT m.rowid, m.project, m.message_id, m.thread_id, m.from_agent, m.to_json,
 			COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
-			m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at
+			m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, '')
 		 FROM messages m
 		 WHERE m.project = ? AND m.topic = ? AND m.rowid > ?
 		 ORDER BY m.rowid ASC LIMIT ?`,
@@ -747,7 +980,10 @@ func migrateThreadIndex(db *sql.DB) error {
 	if err != nil {
 		return fmt.Errorf("backfill thread_index: %w", err)
 	}
-	return nil
+	if !tableHasColumn(db, "thread_index", "unread_count") {
+		return nil // migrateThreadUnread adds and fills it
+	}
+	return backfillThreadUnread(db)
 }
 
 func tableExists(db *sql.DB, table string) bool {
@@ -810,8 +1046,8 @@ func tableHasCompositePK(db *sql.DB, table string) bool {
 	return hasProjectPK && hasMessagePK
 }
 
-func (s *Store) RegisterAgent(_ context.Context, agent core.Agent) (core.Agent, error) {
-	now := time.Now().UTC()
+func (s *Store) RegisterAgent(ctx context.Context, agent core.Agent) (core.Agent, error) {
+	now := clock.Now().UTC()
 	if agent.CreatedAt.IsZero() {
 		agent.CreatedAt = now
 	}
@@ -852,25 +1088,25 @@ func (s *Store) RegisterAgent(_ context.Context, agent core.Agent) (core.Agent,
 			return core.Agent{}, fmt.Errorf("%w: %q", core.ErrInvalidSessionID, agent.SessionID)
 		}
 
-		tx, err := s.db.BeginTx(context.Background(), nil)
+		tx, err := s.db.BeginTx(ctx, nil)
 		if err != nil {
 			return core.Agent{}, fmt.Errorf("begin session reuse tx: %w", err)
 		}
 		defer func() { _ = tx.Rollback() }()
 
 		var existingID, existingLastSeen string
-		err = tx.QueryRow(`SELECT id, last_seen FROM agents WHERE session_id = ?`, agent.SessionID).Scan(&existingID, &existingLastSeen)
+		err = tx.QueryRowContext(ctx, `SELECT id, last_seen FROM agents WHERE session_id = ?`, agent.SessionID).Scan(&existingID, &existingLastSeen)
 		if err == nil {
 			// Found existing agent with this session_id
 			lastSeen, _ := time.Parse(time.RFC3339Nano, existingLastSeen)
-			if time.Since(lastSeen) < core.SessionStaleThreshold {
+			if clock.Since(lastSeen) < core.SessionStaleThreshold {
 				return core.Agent{}, core.ErrActiveSessionConflict
 			}
 			// Agent is stale — check for active reservations
 			var activeCount int
-			err = tx.QueryRow(
-				`SELECT COUNT(*) FROM file_reservations WHERE agent_id = ? AND released_at IS NULL AND expires_at > ?`,
-				existingID, now.Format(time.RFC3339Nano),
+			err = tx.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM file_reservations WHERE agent_id = ? AND released_at IS NULL AND expires_ms > ?`,
+				existingID, unixMillis(now),
 			).Scan(&activeCount)
 			if err != nil {
 				return core.Agent{}, fmt.Errorf("check active reservations: %w", err)
@@ -879,7 +1115,7 @@ func (s *Store) RegisterAgent(_ context.Context, agent core.Agent) (core.Agent,
 				return core.Agent{}, core.ErrActiveSessionConflict
 			}
 			// Reuse the existing agent: update its fields and token, keep its ID
-			if _, err := tx.Exec(
+			if _, err := tx.ExecContext(ctx,
 				`UPDATE agents SET name=?, token=?, capabilities_json=?, metadata_json=?, status=?, contact_policy=?, focus_state=?, focus_state_updated=?, live_contact_policy=?, last_seen=? WHERE id=?`,
 				agent.Name, agent.Token, string(capsJSON), string(metaJSON), agent.Status,
 				string(agent.ContactPolicy), agent.FocusState, agent.FocusStateUpdated.Format(time.RFC3339Nano),
@@ -900,7 +1136,7 @@ func (s *Store) RegisterAgent(_ context.Context, agent core.Agent) (core.Agent,
 		if agent.ID == "" {
 			agent.ID = uuid.NewString()
 		}
-		if _, err := tx.Exec(
+		if _, err := tx.ExecContext(ctx,
 			`INSERT INTO agents (id, session_id, name, project, token, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen)
 			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			agent.ID, agent.SessionID, agent.Name, agent.Project, agent.Token, string(capsJSON), string(metaJSON), agent.Status,
@@ -922,7 +1158,7 @@ func (s *Store) RegisterAgent(_ context.Context, agent core.Agent) (core.Agent,
 	if agent.SessionID == "" {
 		agent.SessionID = uuid.NewString()
 	}
-	if _, err := s.db.Exec(
+	if _, err := s.db.ExecContext(ctx,
 		`INSERT INTO agents (id, session_id, name, project, token, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(id) DO UPDATE SET session_id=excluded.session_id, name=excluded.name, project=excluded.project,
@@ -939,8 +1175,8 @@ func (s *Store) RegisterAgent(_ context.Context, agent core.Agent) (core.Agent,
 	return agent, nil
 }
 
-func (s *Store) Heartbeat(_ context.Context, project, agentID string) (core.Agent, error) {
-	now := time.Now().UTC()
+func (s *Store) Heartbeat(ctx context.Context, project, agentID string) (core.Agent, error) {
+	now := clock.Now().UTC()
 	var query string
 	var args []any
 	if project != "" {
@@ -950,7 +1186,7 @@ func (s *Store) Heartbeat(_ context.Context, project, agentID string) (core.Agen
 		query = `UPDATE agents SET last_seen=? WHERE id=?`
 		args = []any{now.Format(time.RFC3339Nano), agentID}
 	}
-	res, err := s.db.Exec(query, args...)
+	res, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return core.Agent{}, fmt.Errorf("heartbeat: %w", err)
 	}
@@ -959,7 +1195,7 @@ func (s *Store) Heartbeat(_ context.Context, project, agentID string) (core.Agen
 		return core.Agent{}, fmt.Errorf("agent not found")
 	}
 
-	row := s.db.QueryRow(`SELECT id, session_id, name, project, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen FROM agents WHERE id=?`, agentID)
+	row := s.db.QueryRowContext(ctx, `SELECT id, session_id, name, project, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen FROM agents WHERE id=?`, agentID)
 	var (
 		id, sessionID, name, proj, capsJSON, metaJSON, status, contactPolicy, focusState, focusStateUpdated, liveContactPolicy, createdAt, lastSeen string
 	)
@@ -995,7 +1231,7 @@ func (s *Store) Heartbeat(_ context.Context, project, agentID string) (core.Agen
 	}, nil
 }
 
-func (s *Store) ListAgents(_ context.Context, project string, capabilities []string) ([]core.Agent, error) {
+func (s *Store) ListAgents(ctx context.Context, project string, capabilities []string) ([]core.Agent, error) {
 	query := `SELECT id, session_id, name, project, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen
 		FROM agents`
 	var conditions []string
@@ -1021,7 +1257,7 @@ func (s *Store) ListAgents(_ context.Context, project string, capabilities []str
 	}
 	query += " ORDER BY last_seen DESC"
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query agents: %w", err)
 	}
@@ -1069,12 +1305,12 @@ func (s *Store) ListAgents(_ context.Context, project string, capabilities []str
 	return out, nil
 }
 
-func (s *Store) UpdateAgentMetadata(_ context.Context, agentID string, meta map[string]string) (core.Agent, error) {
-	now := time.Now().UTC()
+func (s *Store) UpdateAgentMetadata(ctx context.Context, agentID string, meta map[string]string) (core.Agent, error) {
+	now := clock.Now().UTC()
 
 	// Read existing metadata
 	var existingMetaJSON string
-	err := s.db.QueryRow(`SELECT metadata_json FROM agents WHERE id=?`, agentID).Scan(&existingMetaJSON)
+	err := s.db.QueryRowContext(ctx, `SELECT metadata_json FROM agents WHERE id=?`, agentID).Scan(&existingMetaJSON)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return core.Agent{}, fmt.Errorf("agent not found")
@@ -1097,7 +1333,7 @@ func (s *Store) UpdateAgentMetadata(_ context.Context, agentID string, meta map[
 	}
 
 	// Update metadata + last_seen (free heartbeat)
-	res, err := s.db.Exec(
+	res, err := s.db.ExecContext(ctx,
 		`UPDATE agents SET metadata_json=?, last_seen=? WHERE id=?`,
 		string(mergedJSON), now.Format(time.RFC3339Nano), agentID,
 	)
@@ -1110,7 +1346,7 @@ func (s *Store) UpdateAgentMetadata(_ context.Context, agentID string, meta map[
 	}
 
 	// Fetch and return updated agent
-	row := s.db.QueryRow(`SELECT id, session_id, name, project, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen FROM agents WHERE id=?`, agentID)
+	row := s.db.QueryRowContext(ctx, `SELECT id, session_id, name, project, capabilities_json, metadata_json, status, contact_policy, focus_state, focus_state_updated, live_contact_policy, created_at, last_seen FROM agents WHERE id=?`, agentID)
 	var (
 		id, sessionID, name, proj, capsJSON, metaJSON, status, contactPolicy, focusState, focusStateUpdated, liveContactPolicy, createdAt, lastSeen string
 	)
@@ -1148,8 +1384,8 @@ func (s *Store) UpdateAgentMetadata(_ context.Context, agentID string, meta map[
 
 // --- Contact policy methods ---
 
-func (s *Store) SetContactPolicy(_ context.Context, agentID string, policy core.ContactPolicy) error {
-	res, err := s.db.Exec(`UPDATE agents SET contact_policy=? WHERE id=?`, string(policy), agentID)
+func (s *Store) SetContactPolicy(ctx context.Context, agentID string, policy core.ContactPolicy) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE agents SET contact_policy=? WHERE id=?`, string(policy), agentID)
 	if err != nil {
 		return fmt.Errorf("set contact policy: %w", err)
 	}
@@ -1160,9 +1396,9 @@ func (s *Store) SetContactPolicy(_ context.Context, agentID string, policy core.
 	return nil
 }
 
-func (s *Store) GetContactPolicy(_ context.Context, agentID string) (core.ContactPolicy, error) {
+func (s *Store) GetContactPolicy(ctx context.Context, agentID string) (core.ContactPolicy, error) {
 	var policy string
-	err := s.db.QueryRow(`SELECT contact_policy FROM agents WHERE id=?`, agentID).Scan(&policy)
+	err := s.db.QueryRowContext(ctx, `SELECT contact_policy FROM agents WHERE id=?`, agentID).Scan(&policy)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return core.PolicyOpen, nil
@@ -1175,12 +1411,12 @@ func (s *Store) GetContactPolicy(_ context.Context, agentID string) (core.Contac
 	return core.ContactPolicy(policy), nil
 }
 
-func (s *Store) SetAgentFocusState(_ context.Context, agentID, state string) error {
+func (s *Store) SetAgentFocusState(ctx context.Context, agentID, state string) error {
 	if state == "" {
 		state = core.FocusStateUnknown
 	}
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	res, err := s.db.Exec(`UPDATE agents SET focus_state=?, focus_state_updated=? WHERE id=?`, state, now, agentID)
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	res, err := s.db.ExecContext(ctx, `UPDATE agents SET focus_state=?, focus_state_updated=? WHERE id=?`, state, now, agentID)
 	if err != nil {
 		return fmt.Errorf("set agent focus state: %w", err)
 	}
@@ -1191,10 +1427,10 @@ func (s *Store) SetAgentFocusState(_ context.Context, agentID, state string) err
 	return nil
 }
 
-func (s *Store) GetAgentFocusState(_ context.Context, agentID string) (string, time.Time, error) {
+func (s *Store) GetAgentFocusState(ctx context.Context, agentID string) (string, time.Time, error) {
 	var state string
 	var updatedStr string
-	err := s.db.QueryRow(`SELECT focus_state, focus_state_updated FROM agents WHERE id=?`, agentID).Scan(&state, &updatedStr)
+	err := s.db.QueryRowContext(ctx, `SELECT focus_state, focus_state_updated FROM agents WHERE id=?`, agentID).Scan(&state, &updatedStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return core.FocusStateUnknown, time.Time{}, nil
@@ -1208,7 +1444,7 @@ func (s *Store) GetAgentFocusState(_ context.Context, agentID string) (string, t
 	if err != nil {
 		return core.FocusStateUnknown, time.Time{}, nil
 	}
-	if time.Since(updatedAt) > StalenessFocusThreshold {
+	if clock.Since(updatedAt) > StalenessFocusThreshold {
 		return core.FocusStateUnknown, updatedAt, nil
 	}
 	if state == "" {
@@ -1217,9 +1453,9 @@ func (s *Store) GetAgentFocusState(_ context.Context, agentID string) (string, t
 	return state, updatedAt, nil
 }
 
-func (s *Store) GetLiveContactPolicy(_ context.Context, agentID string) (core.ContactPolicy, error) {
+func (s *Store) GetLiveContactPolicy(ctx context.Context, agentID string) (core.ContactPolicy, error) {
 	var policy string
-	err := s.db.QueryRow(`SELECT live_contact_policy FROM agents WHERE id=?`, agentID).Scan(&policy)
+	err := s.db.QueryRowContext(ctx, `SELECT live_contact_policy FROM agents WHERE id=?`, agentID).Scan(&policy)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return core.PolicyContactsOnly, nil
@@ -1232,8 +1468,8 @@ func (s *Store) GetLiveContactPolicy(_ context.Context, agentID string) (core.Co
 	return core.ContactPolicy(policy), nil
 }
 
-func (s *Store) SetLiveContactPolicy(_ context.Context, agentID string, policy core.ContactPolicy) error {
-	res, err := s.db.Exec(`UPDATE agents SET live_contact_policy=? WHERE id=?`, string(policy), agentID)
+func (s *Store) SetLiveContactPolicy(ctx context.Context, agentID string, policy core.ContactPolicy) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE agents SET live_contact_policy=? WHERE id=?`, string(policy), agentID)
 	if err != nil {
 		return fmt.Errorf("set live contact policy: %w", err)
 	}
@@ -1244,8 +1480,8 @@ func (s *Store) SetLiveContactPolicy(_ context.Context, agentID string, policy c
 	return nil
 }
 
-func (s *Store) ListPendingPokes(_ context.Context, project, recipient string) ([]storage.PendingPoke, error) {
-	rows, err := s.db.Query(
+func (s *Store) ListPendingPokes(ctx context.Context, project, recipient string) ([]storage.PendingPoke, error) {
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT message_id, sender, body, created_at FROM pending_pokes
 		 WHERE project = ? AND recipient = ? AND surfaced_at IS NULL
 		 ORDER BY created_at ASC`,
@@ -1272,11 +1508,11 @@ func (s *Store) ListPendingPokes(_ context.Context, project, recipient string) (
 	return out, nil
 }
 
-func (s *Store) MarkPokeSurfaced(_ context.Context, project, recipient, messageID string) error {
-	_, err := s.db.Exec(
+func (s *Store) MarkPokeSurfaced(ctx context.Context, project, recipient, messageID string) error {
+	_, err := s.db.ExecContext(ctx,
 		`UPDATE pending_pokes SET surfaced_at = ?
 		 WHERE project = ? AND recipient = ? AND message_id = ? AND surfaced_at IS NULL`,
-		time.Now().UTC().Format(time.RFC3339Nano), project, recipient, messageID,
+		clock.Now().UTC().Format(time.RFC3339Nano), project, recipient, messageID,
 	)
 	if err != nil {
 		return fmt.Errorf("mark poke surfaced: %w", err)
@@ -1284,11 +1520,11 @@ func (s *Store) MarkPokeSurfaced(_ context.Context, project, recipient, messageI
 	return nil
 }
 
-func (s *Store) MarkMessageInjected(_ context.Context, project, messageID, recipient string) error {
-	_, err := s.db.Exec(
+func (s *Store) MarkMessageInjected(ctx context.Context, project, messageID, recipient string) error {
+	_, err := s.db.ExecContext(ctx,
 		`UPDATE message_recipients SET injected_at = ?
 		 WHERE project = ? AND message_id = ? AND agent_id = ?`,
-		time.Now().UTC().Format(time.RFC3339Nano), project, messageID, recipient,
+		clock.Now().UTC().Format(time.RFC3339Nano), project, messageID, recipient,
 	)
 	if err != nil {
 		return fmt.Errorf("mark message injected: %w", err)
@@ -1296,9 +1532,9 @@ func (s *Store) MarkMessageInjected(_ context.Context, project, messageID, recip
 	return nil
 }
 
-func (s *Store) LiveTransportEnabled(_ context.Context) (bool, error) {
+func (s *Store) LiveTransportEnabled(ctx context.Context) (bool, error) {
 	var enabled int
-	err := s.db.QueryRow(`SELECT live_transport_enabled FROM config WHERE id = 1`).Scan(&enabled)
+	err := s.db.QueryRowContext(ctx, `SELECT live_transport_enabled FROM config WHERE id = 1`).Scan(&enabled)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return true, nil
@@ -1308,21 +1544,21 @@ func (s *Store) LiveTransportEnabled(_ context.Context) (bool, error) {
 	return enabled == 1, nil
 }
 
-func (s *Store) SetLiveTransportEnabled(_ context.Context, enabled bool) error {
+func (s *Store) SetLiveTransportEnabled(ctx context.Context, enabled bool) error {
 	value := 0
 	if enabled {
 		value = 1
 	}
-	if _, err := s.db.Exec(`UPDATE config SET live_transport_enabled = ? WHERE id = 1`, value); err != nil {
+	if _, err := s.db.ExecContext(ctx, `UPDATE config SET live_transport_enabled = ? WHERE id = 1`, value); err != nil {
 		return fmt.Errorf("set feature flag: %w", err)
 	}
 	return nil
 }
 
-func (s *Store) AddContact(_ context.Context, agentID, contactAgentID string) error {
-	_, err := s.db.Exec(
-		`INSERT OR IGNORE INTO agent_contacts (agent_id, contact_agent_id, created_at) VALUES (?, ?, ?)`,
-		agentID, contactAgentID, time.Now().UTC().Format(time.RFC3339Nano),
+func (s *Store) AddContact(ctx context.Context, agentID, contactAgentID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO agent_contacts (agent_id, contact_agent_id, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+		agentID, contactAgentID, clock.Now().UTC().Format(time.RFC3339Nano),
 	)
 	if err != nil {
 		return fmt.Errorf("add contact: %w", err)
@@ -1330,16 +1566,16 @@ func (s *Store) AddContact(_ context.Context, agentID, contactAgentID string) er
 	return nil
 }
 
-func (s *Store) RemoveContact(_ context.Context, agentID, contactAgentID string) error {
-	_, err := s.db.Exec(`DELETE FROM agent_contacts WHERE agent_id=? AND contact_agent_id=?`, agentID, contactAgentID)
+func (s *Store) RemoveContact(ctx context.Context, agentID, contactAgentID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM agent_contacts WHERE agent_id=? AND contact_agent_id=?`, agentID, contactAgentID)
 	if err != nil {
 		return fmt.Errorf("remove contact: %w", err)
 	}
 	return nil
 }
 
-func (s *Store) ListContacts(_ context.Context, agentID string) ([]string, error) {
-	rows, err := s.db.Query(`SELECT contact_agent_id FROM agent_contacts WHERE agent_id=?`, agentID)
+func (s *Store) ListContacts(ctx context.Context, agentID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT contact_agent_id FROM agent_contacts WHERE agent_id=?`, agentID)
 	if err != nil {
 		return nil, fmt.Errorf("list contacts: %w", err)
 	}
@@ -1358,9 +1594,9 @@ func (s *Store) ListContacts(_ context.Context, agentID string) ([]string, error
 	return out, nil
 }
 
-func (s *Store) IsContact(_ context.Context, agentID, senderID string) (bool, error) {
+func (s *Store) IsContact(ctx context.Context, agentID, senderID string) (bool, error) {
 	var exists int
-	err := s.db.QueryRow(
+	err := s.db.QueryRowContext(ctx,
 		`SELECT 1 FROM agent_contacts WHERE agent_id=? AND contact_agent_id=? LIMIT 1`,
 		agentID, senderID,
 	).Scan(&exists)
@@ -1373,17 +1609,17 @@ func (s *Store) IsContact(_ context.Context, agentID, senderID string) (bool, er
 	return true, nil
 }
 
-func (s *Store) HasReservationOverlap(_ context.Context, project, agentA, agentB string) (bool, error) {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
+func (s *Store) HasReservationOverlap(ctx context.Context, project, agentA, agentB string) (bool, error) {
+	now := unixMillis(clock.Now())
 	// Fetch active reservations for both agents
-	reservationsA, err := s.activeReservationPatterns(project, agentA, now)
+	reservationsA, err := s.activeReservationPatterns(ctx, project, agentA, now)
 	if err != nil {
 		return false, err
 	}
 	if len(reservationsA) == 0 {
 		return false, nil
 	}
-	reservationsB, err := s.activeReservationPatterns(project, agentB, now)
+	reservationsB, err := s.activeReservationPatterns(ctx, project, agentB, now)
 	if err != nil {
 		return false, err
 	}
@@ -1398,10 +1634,10 @@ func (s *Store) HasReservationOverlap(_ context.Context, project, agentA, agentB
 	return false, nil
 }
 
-func (s *Store) activeReservationPatterns(project, agentID, now string) ([]string, error) {
-	rows, err := s.db.Query(
+func (s *Store) activeReservationPatterns(ctx context.Context, project, agentID string, now int64) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT path_pattern FROM file_reservations
-		 WHERE project=? AND agent_id=? AND released_at IS NULL AND expires_at > ?`,
+		 WHERE project=? AND agent_id=? AND released_at IS NULL AND expires_ms > ?`,
 		project, agentID, now,
 	)
 	if err != nil {
@@ -1422,9 +1658,9 @@ func (s *Store) activeReservationPatterns(project, agentID, now string) ([]strin
 	return patterns, nil
 }
 
-func (s *Store) IsThreadParticipant(_ context.Context, project, threadID, agent string) (bool, error) {
+func (s *Store) IsThreadParticipant(ctx context.Context, project, threadID, agent string) (bool, error) {
 	var exists int
-	err := s.db.QueryRow(
+	err := s.db.QueryRowContext(ctx,
 		`SELECT 1 FROM thread_index WHERE project=? AND thread_id=? AND agent=? LIMIT 1`,
 		project, threadID, agent,
 	).Scan(&exists)
@@ -1479,6 +1715,103 @@ func migrateMessageTransport(db *sql.DB) error {
 	return nil
 }
 
+func migrateEventActor(db *sql.DB) error {
+	if !tableExists(db, "events") {
+		return nil
+	}
+	if !tableHasColumn(db, "events", "actor") {
+		if _, err := db.Exec(`ALTER TABLE events ADD COLUMN actor TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add actor column: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateEventTrace adds the request, correlation and causation IDs
+// events are stamped with (see core.Trace).
+func migrateEventTrace(db *sql.DB) error {
+	if !tableExists(db, "events") {
+		return nil
+	}
+	for _, col := range []string{"request_id", "correlation_id", "causation_id"} {
+		if tableHasColumn(db, "events", col) {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE events ADD COLUMN ` + col + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add %s column: %w", col, err)
+		}
+	}
+	return nil
+}
+
+// migrateEventEntity adds the entity columns domain events are persisted
+// with, and the index that serves per-entity reads without a log scan.
+func migrateEventEntity(db *sql.DB) error {
+	if !tableExists(db, "events") {
+		return nil
+	}
+	for _, col := range []string{"entity_type", "entity_id", "data"} {
+		if tableHasColumn(db, "events", col) {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE events ADD COLUMN ` + col + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add %s column: %w", col, err)
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_events_entity ON events(project, entity_type, entity_id, cursor)`); err != nil {
+		return fmt.Errorf("create idx_events_entity: %w", err)
+	}
+	return nil
+}
+
+func migrateMessageInReplyTo(db *sql.DB) error {
+	if !tableExists(db, "messages") {
+		return nil
+	}
+	if !tableHasColumn(db, "messages", "in_reply_to") {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN in_reply_to TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add in_reply_to column: %w", err)
+		}
+	}
+	return nil
+}
+
+func migrateMessageGroups(db *sql.DB) error {
+	if !tableExists(db, "messages") {
+		return nil
+	}
+	if !tableHasColumn(db, "messages", "groups_json") {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN groups_json TEXT NOT NULL DEFAULT '{}'`); err != nil {
+			return fmt.Errorf("add groups_json column: %w", err)
+		}
+	}
+	return nil
+}
+
+func migrateMessageRoute(db *sql.DB) error {
+	if !tableExists(db, "messages") {
+		return nil
+	}
+	if !tableHasColumn(db, "messages", "route_json") {
+		if _, err := db.Exec(`ALTER TABLE messages ADD COLUMN route_json TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add route_json column: %w", err)
+		}
+	}
+	return nil
+}
+
+func migrateMessageRecipientsSnoozedUntil(db *sql.DB) error {
+	if !tableExists(db, "message_recipients") {
+		return nil
+	}
+	if !tableHasColumn(db, "message_recipients", "snoozed_until") {
+		if _, err := db.Exec(`ALTER TABLE message_recipients ADD COLUMN snoozed_until TEXT`); err != nil {
+			return fmt.Errorf("add snoozed_until column: %w", err)
+		}
+	}
+	return nil
+}
+
 func migrateMessageRecipientsInjectedAt(db *sql.DB) error {
 	if !tableExists(db, "message_recipients") {
 		return nil
@@ -1524,9 +1857,9 @@ func migrateConfigTable(db *sql.DB) error {
 }
 
 // insertRecipientsTx adds recipients to the message_recipients table within a transaction
-func (s *Store) insertRecipientsTx(tx *sql.Tx, project, messageID string, agents []string, kind string) error {
+func (s *Store) insertRecipientsTx(ctx context.Context, tx dbTx, project, messageID string, agents []string, kind string) error {
 	for _, agent := range agents {
-		if _, err := tx.Exec(
+		if _, err := tx.ExecContext(ctx,
 			`INSERT INTO message_recipients (project, message_id, agent_id, kind)
 			 VALUES (?, ?, ?, ?)
 			 ON CONFLICT(project, message_id, agent_id) DO NOTHING`,
@@ -1538,10 +1871,17 @@ func (s *Store) insertRecipientsTx(tx *sql.Tx, project, messageID string, agents
 	return nil
 }
 
-// MarkRead marks a message as read by a specific recipient
-func (s *Store) MarkRead(_ context.Context, project, messageID, agentID string) error {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	res, err := s.db.Exec(
+// MarkRead marks a message as read by a specific recipient. A team member
+// marks the team's copy read when it isn't a recipient itself.
+func (s *Store) MarkRead(ctx context.Context, project, messageID, agentID string) error {
+	agentID = s.recipientRow(ctx, project, messageID, agentID)
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin mark read: %w", err)
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx,
 		`UPDATE message_recipients SET read_at = ? WHERE project = ? AND message_id = ? AND agent_id = ? AND read_at IS NULL`,
 		now, project, messageID, agentID,
 	)
@@ -1549,10 +1889,18 @@ func (s *Store) MarkRead(_ context.Context, project, messageID, agentID string)
 		return fmt.Errorf("mark read: %w", err)
 	}
 	rows, _ := res.RowsAffected()
+	if rows > 0 {
+		if err := adjustThreadUnread(ctx, tx, project, messageID, agentID, -1); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mark read: %w", err)
+	}
 	if rows == 0 {
 		// Either already read or not a recipient - check if recipient exists
 		var exists int
-		s.db.QueryRow(`SELECT 1 FROM message_recipients WHERE project = ? AND message_id = ? AND agent_id = ?`,
+		s.db.QueryRowContext(ctx, `SELECT 1 FROM message_recipients WHERE project = ? AND message_id = ? AND agent_id = ?`,
 			project, messageID, agentID).Scan(&exists)
 		if exists == 0 {
 			return fmt.Errorf("agent %s is not a recipient of message %s", agentID, messageID)
@@ -1561,10 +1909,12 @@ func (s *Store) MarkRead(_ context.Context, project, messageID, agentID string)
 	return nil
 }
 
-// MarkAck marks a message as acknowledged by a specific recipient
-func (s *Store) MarkAck(_ context.Context, project, messageID, agentID string) error {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	res, err := s.db.Exec(
+// MarkAck marks a message as acknowledged by a specific recipient, or by a
+// member on behalf of a recipient team.
+func (s *Store) MarkAck(ctx context.Context, project, messageID, agentID string) error {
+	agentID = s.recipientRow(ctx, project, messageID, agentID)
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	res, err := s.db.ExecContext(ctx,
 		`UPDATE message_recipients SET ack_at = ? WHERE project = ? AND message_id = ? AND agent_id = ? AND ack_at IS NULL`,
 		now, project, messageID, agentID,
 	)
@@ -1574,7 +1924,7 @@ func (s *Store) MarkAck(_ context.Context, project, messageID, agentID string) e
 	rows, _ := res.RowsAffected()
 	if rows == 0 {
 		var exists int
-		s.db.QueryRow(`SELECT 1 FROM message_recipients WHERE project = ? AND message_id = ? AND agent_id = ?`,
+		s.db.QueryRowContext(ctx, `SELECT 1 FROM message_recipients WHERE project = ? AND message_id = ? AND agent_id = ?`,
 			project, messageID, agentID).Scan(&exists)
 		if exists == 0 {
 			return fmt.Errorf("agent %s is not a recipient of message %s", agentID, messageID)
@@ -1584,8 +1934,8 @@ func (s *Store) MarkAck(_ context.Context, project, messageID, agentID string) e
 }
 
 // RecipientStatus returns the read/ack status for all recipients of a message
-func (s *Store) RecipientStatus(_ context.Context, project, messageID string) (map[string]*core.RecipientStatus, error) {
-	rows, err := s.db.Query(
+func (s *Store) RecipientStatus(ctx context.Context, project, messageID string) (map[string]*core.RecipientStatus, error) {
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT agent_id, kind, read_at, ack_at FROM message_recipients WHERE project = ? AND message_id = ?`,
 		project, messageID,
 	)
@@ -1625,25 +1975,26 @@ func (s *Store) RecipientStatus(_ context.Context, project, messageID string) (m
 
 // InboxCounts returns the total and unread message counts for an agent.
 // Both counts are read within a single transaction for snapshot consistency.
-func (s *Store) InboxCounts(_ context.Context, project, agentID string) (total int, unread int, err error) {
-	tx, err := s.db.Begin()
+func (s *Store) InboxCounts(ctx context.Context, project, agentID string) (total int, unread int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, 0, fmt.Errorf("begin inbox counts: %w", err)
 	}
 	defer tx.Rollback()
 
 	// Total count from inbox_index
-	if err := tx.QueryRow(
-		`SELECT COUNT(*) FROM inbox_index WHERE project = ? AND agent = ?`,
-		project, agentID,
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM inbox_index i WHERE i.project = ? AND `+addressedTo("i.agent", "i.project"),
+		project, agentID, agentID,
 	).Scan(&total); err != nil {
 		return 0, 0, fmt.Errorf("count total: %w", err)
 	}
 
 	// Unread count from message_recipients (where read_at IS NULL)
-	if err := tx.QueryRow(
-		`SELECT COUNT(*) FROM message_recipients WHERE project = ? AND agent_id = ? AND read_at IS NULL`,
-		project, agentID,
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM message_recipients r WHERE r.project = ? AND `+addressedTo("r.agent_id", "r.project")+` AND r.read_at IS NULL
+		   AND (r.snoozed_ms IS NULL OR r.snoozed_ms <= ?)`,
+		project, agentID, agentID, unixMillis(clock.Now()),
 	).Scan(&unread); err != nil {
 		return 0, 0, fmt.Errorf("count unread: %w", err)
 	}
@@ -1652,7 +2003,7 @@ func (s *Store) InboxCounts(_ context.Context, project, agentID string) (total i
 }
 
 // InboxStaleAcks returns messages requiring ack that haven't been acked within ttlSeconds.
-func (s *Store) InboxStaleAcks(_ context.Context, project, agentID string, ttlSeconds, limit int) ([]core.StaleAck, error) {
+func (s *Store) InboxStaleAcks(ctx context.Context, project, agentID string, ttlSeconds, limit int) ([]core.StaleAck, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -1667,19 +2018,20 @@ func (s *Store) InboxStaleAcks(_ context.Context, project, agentID string, ttlSe
 		r.kind, r.read_at
 	 FROM message_recipients r
 	 JOIN messages m ON m.project = r.project AND m.message_id = r.message_id
-	 WHERE r.project = ? AND r.agent_id = ?
+	 WHERE r.project = ? AND ` + addressedTo("r.agent_id", "r.project") + `
 	   AND m.ack_required = 1
 	   AND r.ack_at IS NULL
-	   AND (strftime('%s', 'now') - strftime('%s', m.created_at)) >= ?
+	   AND m.created_ms <= ?
 	 ORDER BY m.created_at ASC
 	 LIMIT ?`
-	rows, err := s.db.Query(query, project, agentID, ttlSeconds, limit)
+	now := clock.Now().UTC()
+	cutoff := unixMillis(now.Add(-time.Duration(ttlSeconds) * time.Second))
+	rows, err := s.db.QueryContext(ctx, query, project, agentID, agentID, cutoff, limit)
 	if err != nil {
 		return nil, fmt.Errorf("query stale acks: %w", err)
 	}
 	defer rows.Close()
 
-	now := time.Now().UTC()
 	var out []core.StaleAck
 	for rows.Next() {
 		var (
@@ -1732,12 +2084,54 @@ func (s *Store) InboxStaleAcks(_ context.Context, project, agentID string, ttlSe
 	return out, nil
 }
 
+// InboxUnread returns messages the agent has not yet marked read, newest first.
+// When importance is non-empty only messages with one of those importance
+// levels are returned.
+func (s *Store) InboxUnread(ctx context.Context, project, agentID string, importance []string, limit int) ([]core.Message, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	query := `SELECT i.cursor, i.project, m.message_id, m.thread_id, m.from_agent, m.to_json,
+		COALESCE(m.cc_json, '[]'), COALESCE(m.bcc_json, '[]'), COALESCE(m.subject, ''),
+		m.body, COALESCE(m.importance, ''), COALESCE(m.ack_required, 0), COALESCE(m.topic, ''), COALESCE(m.transport, 'async'), m.created_at, COALESCE(m.in_reply_to, ''), COALESCE(m.groups_json, '{}'), COALESCE(m.attachments_json, '[]'), COALESCE(m.route_json, ''), COALESCE(m.ack_policy, '')
+	 FROM inbox_index i
+	 JOIN messages m ON m.project = i.project AND m.message_id = i.message_id
+	 JOIN message_recipients r ON r.project = i.project AND r.message_id = i.message_id AND r.agent_id = i.agent
+	 WHERE ` + addressedTo("i.agent", "i.project") + ` AND r.read_at IS NULL AND (r.snoozed_ms IS NULL OR r.snoozed_ms <= ?)`
+	args := []any{agentID, agentID, unixMillis(clock.Now())}
+	if project != "" {
+		query += " AND i.project = ?"
+		args = append(args, project)
+	}
+	if len(importance) > 0 {
+		query += " AND m.importance IN (?" + strings.Repeat(", ?", len(importance)-1) + ")"
+		for _, imp := range importance {
+			args = append(args, imp)
+		}
+	}
+	query += " ORDER BY i.cursor DESC LIMIT ?"
+	args = append(args, limit)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query unread inbox: %w", err)
+	}
+	defer rows.Close()
+	msgs, err := collectMessages(rows)
+	if err != nil {
+		return nil, fmt.Errorf("unread inbox: %w", err)
+	}
+	return msgs, nil
+}
+
 // Reserve creates a new file reservation
-func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservation, error) {
+func (s *Store) Reserve(ctx context.Context, r core.Reservation) (*core.Reservation, error) {
 	if r.ID == "" {
 		r.ID = uuid.NewString()
 	}
-	now := time.Now().UTC()
+	now := clock.Now().UTC()
 	r.CreatedAt = now
 	if r.TTL == 0 {
 		r.TTL = 30 * time.Minute // Default TTL
@@ -1765,7 +2159,7 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 
 	// IMMEDIATE transaction: acquires write lock immediately so per-agent count
 	// check is not vulnerable to TOCTOU with concurrent Reserve() calls.
-	tx, err := s.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		return nil, fmt.Errorf("begin reservation tx: %w", err)
 	}
@@ -1774,16 +2168,16 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 	}()
 
 	// Sweep expired reservations (opportunistic cleanup, same transaction)
-	_, _ = tx.Exec(
-		`UPDATE file_reservations SET released_at = ? WHERE project = ? AND released_at IS NULL AND expires_at <= ?`,
-		now.Format(time.RFC3339Nano), r.Project, now.Format(time.RFC3339Nano),
+	_, _ = tx.ExecContext(ctx,
+		`UPDATE file_reservations SET released_at = ? WHERE project = ? AND released_at IS NULL AND expires_ms <= ?`,
+		now.Format(time.RFC3339Nano), r.Project, unixMillis(now),
 	)
 
 	// Per-agent limit check
 	var activeCount int
-	err = tx.QueryRow(
-		`SELECT COUNT(*) FROM file_reservations WHERE agent_id = ? AND project = ? AND released_at IS NULL AND expires_at > ?`,
-		r.AgentID, r.Project, now.Format(time.RFC3339Nano),
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM file_reservations WHERE agent_id = ? AND project = ? AND released_at IS NULL AND expires_ms > ?`,
+		r.AgentID, r.Project, unixMillis(now),
 	).Scan(&activeCount)
 	if err != nil {
 		return nil, fmt.Errorf("count agent reservations: %w", err)
@@ -1793,12 +2187,19 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 			r.AgentID, activeCount, MaxReservationsPerAgent)
 	}
 
-	activeRows, err := tx.Query(
+	// Reservations held by the requester's team, or by members of the
+	// requesting team, are the same holder's.
+	allies, err := teamAlliesTx(ctx, tx, r.Project, r.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	activeRows, err := tx.QueryContext(ctx,
 		`SELECT r.id, r.agent_id, COALESCE(a.name, r.agent_id), r.path_pattern, r.exclusive, r.reason, r.expires_at
 		 FROM file_reservations r
 		 LEFT JOIN agents a ON r.agent_id = a.id
-		 WHERE r.project = ? AND r.released_at IS NULL AND r.expires_at > ? AND r.agent_id != ?`,
-		r.Project, now.Format(time.RFC3339Nano), r.AgentID,
+		 WHERE r.project = ? AND r.released_at IS NULL AND r.expires_ms > ? AND r.agent_id != ?`,
+		r.Project, unixMillis(now), r.AgentID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query active reservations: %w", err)
@@ -1823,6 +2224,9 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 		if !r.Exclusive && existingExcl == 0 {
 			continue
 		}
+		if allies[existingAgentID] {
+			continue
+		}
 		overlap, err := glob.PatternsOverlap(r.PathPattern, existingPattern)
 		if err != nil {
 			return nil, fmt.Errorf("check reservation overlap against %q: %w", existingPattern, err)
@@ -1834,6 +2238,7 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 				AgentID:       existingAgentID,
 				AgentName:     existingName,
 				Pattern:       existingPattern,
+				Exclusive:     existingExcl == 1,
 				Reason:        existingReason.String,
 				ExpiresAt:     expiresAt,
 			})
@@ -1849,7 +2254,7 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 		return nil, &core.ConflictError{Conflicts: conflicts}
 	}
 
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(ctx,
 		`INSERT INTO file_reservations (id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		r.ID, r.AgentID, r.Project, r.PathPattern, exclusive, r.Reason,
@@ -1873,14 +2278,14 @@ func (s *Store) Reserve(_ context.Context, r core.Reservation) (*core.Reservatio
 }
 
 // GetReservation returns a reservation by ID
-func (s *Store) GetReservation(_ context.Context, id string) (*core.Reservation, error) {
+func (s *Store) GetReservation(ctx context.Context, id string) (*core.Reservation, error) {
 	var (
 		res                  core.Reservation
 		exclusive            int
 		createdAt, expiresAt string
 		releasedAt           sql.NullString
 	)
-	err := s.db.QueryRow(
+	err := s.db.QueryRowContext(ctx,
 		`SELECT id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at, released_at
 		 FROM file_reservations
 		 WHERE id = ?`,
@@ -1906,9 +2311,9 @@ func (s *Store) GetReservation(_ context.Context, id string) (*core.Reservation,
 }
 
 // ReleaseReservation marks a reservation as released, enforcing agent ownership atomically
-func (s *Store) ReleaseReservation(_ context.Context, id, agentID string) error {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	res, err := s.db.Exec(
+func (s *Store) ReleaseReservation(ctx context.Context, id, agentID string) error {
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	res, err := s.db.ExecContext(ctx,
 		`UPDATE file_reservations SET released_at = ? WHERE id = ? AND agent_id = ? AND released_at IS NULL`,
 		now, id, agentID,
 	)
@@ -1929,13 +2334,13 @@ func (s *Store) ReleaseReservation(_ context.Context, id, agentID string) error
 }
 
 // ActiveReservations returns all non-expired, non-released reservations for a project
-func (s *Store) ActiveReservations(_ context.Context, project string) ([]core.Reservation, error) {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	rows, err := s.db.Query(
+func (s *Store) ActiveReservations(ctx context.Context, project string) ([]core.Reservation, error) {
+	now := unixMillis(clock.Now())
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at
 		 FROM file_reservations
-		 WHERE project = ? AND released_at IS NULL AND expires_at > ?
-		 ORDER BY created_at DESC`,
+		 WHERE project = ? AND released_at IS NULL AND expires_ms > ?
+		 ORDER BY created_ms DESC`,
 		project, now,
 	)
 	if err != nil {
@@ -1947,8 +2352,8 @@ func (s *Store) ActiveReservations(_ context.Context, project string) ([]core.Re
 }
 
 // AgentReservations returns all reservations held by an agent (including expired but not released)
-func (s *Store) AgentReservations(_ context.Context, agentID string) ([]core.Reservation, error) {
-	rows, err := s.db.Query(
+func (s *Store) AgentReservations(ctx context.Context, agentID string) ([]core.Reservation, error) {
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at, released_at
 		 FROM file_reservations
 		 WHERE agent_id = ?
@@ -2030,18 +2435,18 @@ func (s *Store) scanReservationsWithRelease(rows *sql.Rows) ([]core.Reservation,
 }
 
 // CheckConflicts returns active reservations that would conflict with the given pattern.
-func (s *Store) CheckConflicts(_ context.Context, project, pathPattern string, exclusive bool) ([]core.ConflictDetail, error) {
+func (s *Store) CheckConflicts(ctx context.Context, project, pathPattern string, exclusive bool) ([]core.ConflictDetail, error) {
 	if err := glob.ValidateComplexity(pathPattern); err != nil {
 		return nil, fmt.Errorf("invalid pattern %q: %w", pathPattern, err)
 	}
 
-	now := time.Now().UTC()
-	rows, err := s.db.Query(
+	now := clock.Now().UTC()
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT r.id, r.agent_id, COALESCE(a.name, r.agent_id), r.path_pattern, r.exclusive, r.reason, r.expires_at
 		 FROM file_reservations r
 		 LEFT JOIN agents a ON r.agent_id = a.id
-		 WHERE r.project = ? AND r.released_at IS NULL AND r.expires_at > ?`,
-		project, now.Format(time.RFC3339Nano),
+		 WHERE r.project = ? AND r.released_at IS NULL AND r.expires_ms > ?`,
+		project, unixMillis(now),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query active reservations: %w", err)
@@ -2073,6 +2478,7 @@ func (s *Store) CheckConflicts(_ context.Context, project, pathPattern string, e
 				AgentID:       agentID,
 				AgentName:     name,
 				Pattern:       pattern,
+				Exclusive:     excl == 1,
 				Reason:        reasonNull.String,
 				ExpiresAt:     expiresAt,
 			})
@@ -2083,17 +2489,17 @@ func (s *Store) CheckConflicts(_ context.Context, project, pathPattern string, e
 
 // SweepExpired deletes unreleased reservations that have expired and whose
 // owning agent has not heartbeated recently. Returns deleted reservations.
-func (s *Store) SweepExpired(_ context.Context, expiredBefore time.Time, heartbeatAfter time.Time) ([]core.Reservation, error) {
-	rows, err := s.db.Query(
+func (s *Store) SweepExpired(ctx context.Context, expiredBefore time.Time, heartbeatAfter time.Time) ([]core.Reservation, error) {
+	rows, err := s.db.QueryContext(ctx,
 		`DELETE FROM file_reservations
 		 WHERE released_at IS NULL
-		   AND expires_at < ?
+		   AND expires_ms < ?
 		   AND agent_id NOT IN (
-		     SELECT id FROM agents WHERE last_seen > ?
+		     SELECT id FROM agents WHERE last_seen_ms > ?
 		   )
 		 RETURNING id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at`,
-		expiredBefore.Format(time.RFC3339Nano),
-		heartbeatAfter.Format(time.RFC3339Nano),
+		unixMillis(expiredBefore),
+		unixMillis(heartbeatAfter),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("sweep expired reservations: %w", err)
@@ -2103,6 +2509,44 @@ func (s *Store) SweepExpired(_ context.Context, expiredBefore time.Time, heartbe
 	return s.scanReservations(rows)
 }
 
+// ReleaseStaleReservations releases every active reservation, whatever its
+// TTL, whose agent hasn't heartbeated since heartbeatBefore (or isn't
+// registered), returning what it released. A team's reservations stay while
+// any member is heartbeating.
+func (s *Store) ReleaseStaleReservations(ctx context.Context, heartbeatBefore time.Time) ([]core.Reservation, error) {
+	now := clock.Now().UTC()
+	rows, err := s.db.QueryContext(ctx,
+		`UPDATE file_reservations SET released_at = ?
+		 WHERE released_at IS NULL
+		   AND expires_ms > ?
+		   AND agent_id NOT IN (
+		     SELECT id FROM agents WHERE last_seen_ms > ?
+		   )
+		   AND agent_id NOT IN (
+		     SELECT '@' || tm.group_name FROM contact_group_members tm
+		     JOIN contact_groups tg ON tg.project = tm.project AND tg.name = tm.group_name
+		     JOIN agents a ON a.id = tm.agent_id
+		     WHERE tg.team = 1 AND tm.project = file_reservations.project AND a.last_seen_ms > ?
+		   )
+		 RETURNING id, agent_id, project, path_pattern, exclusive, reason, created_at, expires_at`,
+		now.Format(time.RFC3339Nano), unixMillis(now), unixMillis(heartbeatBefore), unixMillis(heartbeatBefore),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("release stale reservations: %w", err)
+	}
+	released, err := s.scanReservations(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if s.bridge != nil {
+		for _, r := range released {
+			s.bridge.MirrorRelease(r.ID)
+		}
+	}
+	return released, nil
+}
+
 // Ping verifies the DB is reachable and responsive by running a trivial
 // query. Returns an error if the DB cannot be queried within ctx's deadline.
 // Used by handleHealth so /health reflects actual DB liveness, not just
@@ -2115,18 +2559,18 @@ func (s *Store) Ping(ctx context.Context) error {
 	return fmt.Errorf("store backed by unexpected handle type")
 }
 
-// Close checkpoints the WAL and closes the database connection.
+// Close checkpoints the WAL (on SQLite) and closes the database connection.
 func (s *Store) Close() error {
 	if ql, ok := s.db.(*queryLogger); ok {
-		_, _ = ql.inner.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
-		return ql.inner.Close()
+		return s.dialectOf().Close(ql.inner)
 	}
 	return nil
 }
 
-// migrateDomainVersions adds version columns to domain tables (specs, epics, stories, tasks)
+// migrateDomainVersions adds version columns to domain tables (specs, epics,
+// stories, tasks, insights, sessions)
 func migrateDomainVersions(db *sql.DB) error {
-	tables := []string{"specs", "epics", "stories", "tasks"}
+	tables := []string{"specs", "epics", "stories", "tasks", "insights", "sessions"}
 	for _, table := range tables {
 		if !tableExists(db, table) {
 			continue
@@ -2141,6 +2585,59 @@ func migrateDomainVersions(db *sql.DB) error {
 	return nil
 }
 
+// migrateInsightUpdatedAt adds updated_at to insights, backfilled from
+// created_at so existing rows sort sensibly.
+func migrateInsightUpdatedAt(db *sql.DB) error {
+	if !tableExists(db, "insights") || tableHasColumn(db, "insights", "updated_at") {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE insights ADD COLUMN updated_at TEXT NOT NULL DEFAULT ''`); err != nil {
+		return fmt.Errorf("add insights.updated_at: %w", err)
+	}
+	if _, err := db.Exec(`UPDATE insights SET updated_at = created_at`); err != nil {
+		return fmt.Errorf("backfill insights.updated_at: %w", err)
+	}
+	return nil
+}
+
+// migrateInsightStatus adds the triage status to insights; existing rows
+// start as new.
+func migrateInsightStatus(db *sql.DB) error {
+	if !tableExists(db, "insights") || tableHasColumn(db, "insights", "status") {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE insights ADD COLUMN status TEXT NOT NULL DEFAULT 'new'`); err != nil {
+		return fmt.Errorf("add insights.status: %w", err)
+	}
+	return nil
+}
+
+// migrateTaskDueAt adds the optional due_at deadline column to tasks.
+func migrateTaskDueAt(db *sql.DB) error {
+	if !tableExists(db, "tasks") {
+		return nil
+	}
+	if !tableHasColumn(db, "tasks", "due_at") {
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN due_at TEXT`); err != nil {
+			return fmt.Errorf("add due_at column: %w", err)
+		}
+	}
+	return nil
+}
+
+// migratePriority adds the priority column to stories and tasks.
+func migratePriority(db *sql.DB) error {
+	for _, table := range []string{"stories", "tasks"} {
+		if !tableExists(db, table) || tableHasColumn(db, table, "priority") {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN priority TEXT NOT NULL DEFAULT 'medium'`); err != nil {
+			return fmt.Errorf("add %s.priority column: %w", table, err)
+		}
+	}
+	return nil
+}
+
 // migrateWindowIdentities creates the window_identities table if it doesn't exist.
 func migrateWindowIdentities(db *sql.DB) error {
 	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS window_identities (
@@ -2234,8 +2731,8 @@ func (s *Store) UpsertWindowIdentityWithToken(ctx context.Context, wi core.Windo
 	return result, nil
 }
 
-func upsertWindowIdentityTx(ctx context.Context, tx *sql.Tx, wi core.WindowIdentity) (*core.WindowIdentity, error) {
-	now := time.Now().UTC()
+func upsertWindowIdentityTx(ctx context.Context, tx dbTx, wi core.WindowIdentity) (*core.WindowIdentity, error) {
+	now := clock.Now().UTC()
 	if wi.ID == "" {
 		wi.ID = uuid.NewString()
 	}
@@ -2267,8 +2764,8 @@ func upsertWindowIdentityTx(ctx context.Context, tx *sql.Tx, wi core.WindowIdent
 	// Read back the actual row within the same transaction for atomicity.
 	row := tx.QueryRowContext(ctx, `SELECT id, project, window_uuid, agent_id, display_name, tmux_target, created_at, last_active_at, expires_at
 		FROM window_identities
-		WHERE project = ? AND window_uuid = ? AND (expires_at IS NULL OR expires_at > ?)`,
-		wi.Project, wi.WindowUUID, now.Format(time.RFC3339Nano))
+		WHERE project = ? AND window_uuid = ? AND (expires_ms IS NULL OR expires_ms > ?)`,
+		wi.Project, wi.WindowUUID, unixMillis(now))
 
 	result, err := scanWindowIdentityRow(row)
 	if err != nil {
@@ -2279,10 +2776,10 @@ func upsertWindowIdentityTx(ctx context.Context, tx *sql.Tx, wi core.WindowIdent
 
 // ListWindowIdentities returns non-expired window identities for a project.
 func (s *Store) ListWindowIdentities(ctx context.Context, project string) ([]core.WindowIdentity, error) {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	rows, err := s.db.Query(`SELECT id, project, window_uuid, agent_id, display_name, tmux_target, created_at, last_active_at, expires_at
+	now := unixMillis(clock.Now())
+	rows, err := s.db.QueryContext(ctx, `SELECT id, project, window_uuid, agent_id, display_name, tmux_target, created_at, last_active_at, expires_at
 		FROM window_identities
-		WHERE project = ? AND (expires_at IS NULL OR expires_at > ?)
+		WHERE project = ? AND (expires_ms IS NULL OR expires_ms > ?)
 		ORDER BY last_active_at DESC`, project, now)
 	if err != nil {
 		return nil, fmt.Errorf("list window identities: %w", err)
@@ -2303,8 +2800,8 @@ func (s *Store) ListWindowIdentities(ctx context.Context, project string) ([]cor
 // ExpireWindowIdentity sets expires_at = now for a window identity.
 // Uses Go-formatted RFC3339Nano timestamp for consistency with other timestamp storage.
 func (s *Store) ExpireWindowIdentity(ctx context.Context, project, windowUUID string) error {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	_, err := s.db.Exec(`UPDATE window_identities SET expires_at = ?
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	_, err := s.db.ExecContext(ctx, `UPDATE window_identities SET expires_at = ?
 		WHERE project = ? AND window_uuid = ?`, now, project, windowUUID)
 	if err != nil {
 		return fmt.Errorf("expire window identity: %w", err)
@@ -2314,10 +2811,10 @@ func (s *Store) ExpireWindowIdentity(ctx context.Context, project, windowUUID st
 
 // LookupWindowIdentity finds a non-expired window identity by (project, window_uuid).
 func (s *Store) LookupWindowIdentity(ctx context.Context, project, windowUUID string) (*core.WindowIdentity, error) {
-	now := time.Now().UTC().Format(time.RFC3339Nano)
-	row := s.db.QueryRow(`SELECT id, project, window_uuid, agent_id, display_name, tmux_target, created_at, last_active_at, expires_at
+	now := unixMillis(clock.Now())
+	row := s.db.QueryRowContext(ctx, `SELECT id, project, window_uuid, agent_id, display_name, tmux_target, created_at, last_active_at, expires_at
 		FROM window_identities
-		WHERE project = ? AND window_uuid = ? AND (expires_at IS NULL OR expires_at > ?)`,
+		WHERE project = ? AND window_uuid = ? AND (expires_ms IS NULL OR expires_ms > ?)`,
 		project, windowUUID, now)
 
 	wi, err := scanWindowIdentityRow(row)
@@ -2412,12 +2909,12 @@ func generateAgentToken() (string, error) {
 }
 
 // AgentForToken returns the agent ID bound to the given registration token.
-func (s *Store) AgentForToken(_ context.Context, token string) (string, error) {
+func (s *Store) AgentForToken(ctx context.Context, token string) (string, error) {
 	if token == "" {
 		return "", fmt.Errorf("empty token")
 	}
 	var agentID string
-	err := s.db.QueryRow(`SELECT id FROM agents WHERE token = ?`, token).Scan(&agentID)
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM agents WHERE token = ?`, token).Scan(&agentID)
 	if err == sql.ErrNoRows {
 		return "", fmt.Errorf("token not found")
 	}
@@ -1,14 +1,20 @@
 package sqlite
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
 )
 
 func TestWindowIdentityTmuxTarget(t *testing.T) {
@@ -719,6 +725,50 @@ func TestReservationExpiry(t *testing.T) {
 	}
 }
 
+func TestReleaseStaleReservations(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+
+	stale, err := st.RegisterAgent(ctx, core.Agent{Name: "stale", Project: "autarch"})
+	if err != nil {
+		t.Fatalf("register stale: %v", err)
+	}
+	fresh, err := st.RegisterAgent(ctx, core.Agent{Name: "fresh", Project: "autarch"})
+	if err != nil {
+		t.Fatalf("register fresh: %v", err)
+	}
+	old := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339Nano)
+	if _, err := st.db.Exec(`UPDATE agents SET last_seen = ? WHERE id = ?`, old, stale.ID); err != nil {
+		t.Fatalf("age agent: %v", err)
+	}
+
+	staleRes, err := st.Reserve(ctx, core.Reservation{AgentID: stale.ID, Project: "autarch", PathPattern: "a/*.go", Exclusive: true, TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("reserve stale: %v", err)
+	}
+	if _, err := st.Reserve(ctx, core.Reservation{AgentID: fresh.ID, Project: "autarch", PathPattern: "b/*.go", Exclusive: true, TTL: time.Hour}); err != nil {
+		t.Fatalf("reserve fresh: %v", err)
+	}
+
+	released, err := st.ReleaseStaleReservations(ctx, time.Now().UTC().Add(-5*time.Minute))
+	if err != nil {
+		t.Fatalf("release stale: %v", err)
+	}
+	if len(released) != 1 || released[0].ID != staleRes.ID || released[0].PathPattern != "a/*.go" {
+		t.Fatalf("released = %+v, want only the stale agent's reservation", released)
+	}
+
+	active, _ := st.ActiveReservations(ctx, "autarch")
+	if len(active) != 1 || active[0].AgentID != fresh.ID {
+		t.Fatalf("active = %+v, want only the fresh agent's reservation", active)
+	}
+
+	// Already released: a second pass finds nothing.
+	if again, _ := st.ReleaseStaleReservations(ctx, time.Now().UTC().Add(-5*time.Minute)); len(again) != 0 {
+		t.Fatalf("second pass released %d", len(again))
+	}
+}
+
 func TestRecipientTracking(t *testing.T) {
 	ctx := context.Background()
 	st := NewSQLiteTest(t)
@@ -828,6 +878,42 @@ func TestMessageWithMetadata(t *testing.T) {
 	}
 }
 
+func TestRecipientFanOutDeduped(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+
+	msg := core.Message{
+		ID:       "m1",
+		Project:  "proj",
+		ThreadID: "t1",
+		From:     "alice",
+		To:       []string{"bob", "alice"},
+		CC:       []string{"bob", "charlie"},
+		BCC:      []string{"charlie", "dave"},
+		Body:     "hi",
+	}
+	if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Message: msg}); err != nil {
+		t.Fatalf("append event: %v", err)
+	}
+
+	for _, agent := range []string{"alice", "bob", "charlie", "dave"} {
+		msgs, err := st.InboxSince(ctx, "proj", agent, 0, 0)
+		if err != nil {
+			t.Fatalf("inbox %s: %v", agent, err)
+		}
+		if len(msgs) != 1 {
+			t.Errorf("%s: expected 1 inbox entry, got %d", agent, len(msgs))
+		}
+		threads, err := st.ListThreads(ctx, "proj", agent, 0, 10)
+		if err != nil {
+			t.Fatalf("threads %s: %v", agent, err)
+		}
+		if len(threads) != 1 || threads[0].MessageCount != 1 {
+			t.Errorf("%s: expected one thread with 1 message, got %+v", agent, threads)
+		}
+	}
+}
+
 func TestSQLiteThreadBackfillIncludesSender(t *testing.T) {
 	ctx := context.Background()
 	dir := t.TempDir()
@@ -1414,3 +1500,371 @@ func TestMessageTransportRoundTrip(t *testing.T) {
 		t.Fatalf("transport = %q, want %q", msgs[0].Transport, core.TransportBoth)
 	}
 }
+
+func TestSnoozeAndWake(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+
+	msg := core.Message{ID: "m1", Project: "proj", From: "alice", To: []string{"bob"}, Body: "later"}
+	cursor, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Message: msg})
+	if err != nil {
+		t.Fatalf("append event: %v", err)
+	}
+
+	now := time.Now().UTC()
+	if err := st.SnoozeMessage(ctx, "proj", "m1", "carol", now.Add(time.Hour)); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for non-recipient, got %v", err)
+	}
+	if err := st.SnoozeMessage(ctx, "proj", "m1", "bob", now.Add(time.Hour)); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+
+	msgs, err := st.InboxSince(ctx, "proj", "bob", 0, 0)
+	if err != nil {
+		t.Fatalf("inbox: %v", err)
+	}
+	if len(msgs) != 0 {
+		t.Fatalf("snoozed message should be hidden, got %d", len(msgs))
+	}
+	_, unread, err := st.InboxCounts(ctx, "proj", "bob")
+	if err != nil {
+		t.Fatalf("counts: %v", err)
+	}
+	if unread != 0 {
+		t.Errorf("expected 0 unread while snoozed, got %d", unread)
+	}
+	snoozed, err := st.ListSnoozed(ctx, "proj", "bob")
+	if err != nil {
+		t.Fatalf("list snoozed: %v", err)
+	}
+	if len(snoozed) != 1 || snoozed[0].Message.ID != "m1" {
+		t.Fatalf("unexpected snoozed list: %+v", snoozed)
+	}
+
+	// Nothing is due yet.
+	wakes, err := st.WakeSnoozed(ctx, "", "", now)
+	if err != nil {
+		t.Fatalf("wake: %v", err)
+	}
+	if len(wakes) != 0 {
+		t.Fatalf("expected no wakes before deadline, got %+v", wakes)
+	}
+
+	wakes, err = st.WakeSnoozed(ctx, "", "", now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("wake: %v", err)
+	}
+	if len(wakes) != 1 || wakes[0].Agent != "bob" || wakes[0].Cursor <= cursor {
+		t.Fatalf("unexpected wakes: %+v (original cursor %d)", wakes, cursor)
+	}
+
+	// The message is back, unread, past the cursor bob had already read to.
+	msgs, err = st.InboxSince(ctx, "proj", "bob", cursor, 0)
+	if err != nil {
+		t.Fatalf("inbox: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != "m1" {
+		t.Fatalf("expected woken message after cursor, got %+v", msgs)
+	}
+	if snoozed, _ := st.ListSnoozed(ctx, "proj", "bob"); len(snoozed) != 0 {
+		t.Errorf("expected empty snoozed list after wake, got %d", len(snoozed))
+	}
+}
+
+func TestAppendEventRecordsActor(t *testing.T) {
+	st := NewSQLiteTest(t)
+	ctx := core.WithActor(context.Background(), "agent-a")
+
+	msg := core.Message{ID: "m1", Project: "proj", From: "agent-a", To: []string{"bob"}, Body: "hi"}
+	if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Message: msg}); err != nil {
+		t.Fatalf("append event: %v", err)
+	}
+	if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageRead, Actor: "bob", Project: "proj", Message: core.Message{ID: "m1"}}); err != nil {
+		t.Fatalf("append event: %v", err)
+	}
+
+	rows, err := st.db.Query(`SELECT actor FROM events ORDER BY cursor`)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	var actors []string
+	for rows.Next() {
+		var actor string
+		if err := rows.Scan(&actor); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		actors = append(actors, actor)
+	}
+	if len(actors) != 2 || actors[0] != "agent-a" || actors[1] != "bob" {
+		t.Fatalf("expected actors [agent-a bob], got %v", actors)
+	}
+}
+
+func TestAppendEventIdempotentByID(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+
+	ev := core.Event{ID: "ev-1", Type: core.EventMessageCreated, Message: core.Message{ID: "m1", Project: "proj", From: "alice", To: []string{"bob"}, Body: "hi"}}
+	first, err := st.AppendEvent(ctx, ev)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	again, err := st.AppendEvent(ctx, ev)
+	if err != nil {
+		t.Fatalf("retry append: %v", err)
+	}
+	if again != first {
+		t.Fatalf("expected retry to return cursor %d, got %d", first, again)
+	}
+	msgs, err := st.InboxSince(ctx, "proj", "bob", 0, 0)
+	if err != nil {
+		t.Fatalf("inbox: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 inbox message after retry, got %d", len(msgs))
+	}
+}
+
+func TestQueryStatsAndSlowQueryRoute(t *testing.T) {
+	st := NewSQLiteTest(t)
+	st.SetSlowQueryThreshold(time.Nanosecond)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ctx, qs := storage.WithQueryStats(context.Background(), "/api/admin/overview")
+	if _, err := st.AdminOverview(ctx); err != nil {
+		t.Fatalf("overview: %v", err)
+	}
+	count, total := qs.Totals()
+	if count < 2 || total <= 0 {
+		t.Fatalf("stats = %d queries in %v, want at least 2", count, total)
+	}
+	if !strings.Contains(buf.String(), "[/api/admin/overview]") {
+		t.Fatalf("slow query log missing route:\n%s", buf.String())
+	}
+
+	// Queries outside a request leave the stats alone.
+	if _, err := st.ListAgents(context.Background(), "p", nil); err != nil {
+		t.Fatalf("list agents: %v", err)
+	}
+	if again, _ := qs.Totals(); again != count {
+		t.Fatalf("count changed to %d by an unrelated query", again)
+	}
+}
+
+func TestMigrateEventEntity(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "legacy-events.db"))
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE events (
+		cursor INTEGER PRIMARY KEY AUTOINCREMENT,
+		id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		agent TEXT,
+		project TEXT NOT NULL DEFAULT '',
+		message_id TEXT,
+		thread_id TEXT,
+		from_agent TEXT,
+		to_json TEXT,
+		body TEXT,
+		created_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		t.Fatalf("create legacy events: %v", err)
+	}
+	if err := applySchema(db); err != nil {
+		t.Fatalf("applySchema: %v", err)
+	}
+	for _, col := range []string{"entity_type", "entity_id", "data"} {
+		if !tableHasColumn(db, "events", col) {
+			t.Fatalf("expected migrateEventEntity to add %s", col)
+		}
+	}
+	var plan string
+	if err := db.QueryRow(`EXPLAIN QUERY PLAN SELECT cursor FROM events
+		WHERE project = 'p' AND entity_type = 'task' AND entity_id = 't' AND cursor > 0`).Scan(new(int), new(int), new(int), &plan); err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if !strings.Contains(plan, "idx_events_entity") {
+		t.Fatalf("entity read doesn't use idx_events_entity: %s", plan)
+	}
+}
+
+func TestListDomainEventsSkipsMessages(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	if _, err := st.AppendEvent(ctx, core.Event{
+		Type:    core.EventMessageCreated,
+		Project: "p",
+		Message: core.Message{ID: "m1", From: "a", To: []string{"b"}, Body: "hi"},
+	}); err != nil {
+		t.Fatalf("append message: %v", err)
+	}
+	for _, typ := range []core.EventType{core.EventTaskCreated, core.EventTaskAssigned} {
+		if _, err := st.AppendEvent(ctx, core.Event{
+			Type:       typ,
+			Project:    "p",
+			EntityType: typ.EntityType(),
+			EntityID:   "t1",
+			Data:       `{"id":"t1"}`,
+		}); err != nil {
+			t.Fatalf("append %s: %v", typ, err)
+		}
+	}
+
+	all, err := st.ListDomainEvents(ctx, core.EventFilter{Project: "p"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 2 || all[0].Type != core.EventTaskCreated || all[1].EntityType != "task" || all[1].Data != `{"id":"t1"}` {
+		t.Fatalf("domain events = %+v", all)
+	}
+	n, err := st.CountDomainEvents(ctx, core.EventFilter{Project: "p", EntityType: "task", EntityID: "t1", After: all[0].Cursor})
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("count after first cursor = %d, want 1", n)
+	}
+}
+
+func TestMigrateSearchIndexBackfills(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "legacy-search.db"))
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := db.Exec(`INSERT INTO specs (id, project, title, vision, status, created_at, updated_at)
+		VALUES ('s1', 'p', 'Legacy spec', 'indexed after upgrade', 'draft', ?, ?)`, now, now); err != nil {
+		t.Fatalf("seed spec: %v", err)
+	}
+
+	if err := applySchema(db); err != nil {
+		t.Fatalf("applySchema: %v", err)
+	}
+	st := &Store{db: &queryLogger{inner: db}}
+	results, err := st.Search(context.Background(), core.SearchQuery{Project: "p", Query: "upgrade"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "s1" || results[0].Kind != core.SearchKindSpec {
+		t.Fatalf("results = %+v, want backfilled s1", results)
+	}
+
+	// Re-applying the schema must not index the row twice.
+	if err := applySchema(db); err != nil {
+		t.Fatalf("re-apply: %v", err)
+	}
+	if results, _ = st.Search(context.Background(), core.SearchQuery{Project: "p", Query: "upgrade"}); len(results) != 1 {
+		t.Fatalf("results after re-apply = %+v", results)
+	}
+}
+
+func TestMigrateShortIDsBackfills(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "legacy-short-ids.db"))
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE tasks (
+		id TEXT NOT NULL,
+		project TEXT NOT NULL DEFAULT '',
+		story_id TEXT,
+		title TEXT NOT NULL,
+		agent TEXT,
+		session_id TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		priority TEXT NOT NULL DEFAULT 'medium',
+		due_at TEXT,
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL,
+		PRIMARY KEY (project, id)
+	)`)
+	if err != nil {
+		t.Fatalf("create legacy tasks: %v", err)
+	}
+	for _, row := range [][]string{
+		{"t-b", "p", "2026-01-02T00:00:00Z"},
+		{"t-a", "p", "2026-01-01T00:00:00Z"},
+		{"t-c", "q", "2026-01-03T00:00:00Z"},
+	} {
+		if _, err := db.Exec(`INSERT INTO tasks (id, project, title, created_at, updated_at) VALUES (?, ?, 'x', ?, ?)`,
+			row[0], row[1], row[2], row[2]); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	if err := applySchema(db); err != nil {
+		t.Fatalf("applySchema: %v", err)
+	}
+	st := &Store{db: &queryLogger{inner: db}}
+	for id, want := range map[string]string{"t-a": "TASK-1", "t-b": "TASK-2"} {
+		task, err := st.GetTask(ctx, "p", id)
+		if err != nil {
+			t.Fatalf("get %s: %v", id, err)
+		}
+		if task.ShortID != want {
+			t.Fatalf("%s short id = %q, want %q", id, task.ShortID, want)
+		}
+	}
+
+	// New tasks continue each project's sequence.
+	created, err := st.CreateTask(ctx, core.Task{Project: "p", Title: "new"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if created.ShortID != "TASK-3" {
+		t.Fatalf("new short id = %q, want TASK-3", created.ShortID)
+	}
+	if created, _ = st.CreateTask(ctx, core.Task{Project: "q", Title: "new"}); created.ShortID != "TASK-2" {
+		t.Fatalf("q short id = %q, want TASK-2", created.ShortID)
+	}
+}
+
+func TestResolveShortID(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	a, err := st.CreateSpec(ctx, core.Spec{Project: "a", Title: "one"})
+	if err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	if _, err := st.CreateSpec(ctx, core.Spec{Project: "b", Title: "one"}); err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+	onlyA, err := st.CreateSpec(ctx, core.Spec{Project: "a", Title: "two"})
+	if err != nil {
+		t.Fatalf("create spec: %v", err)
+	}
+
+	cases := []struct {
+		project, ref, want string
+	}{
+		{"a", "SPEC-1", a.ID},
+		{"a", "spec-1", a.ID},
+		{"", "SPEC-1", ""}, // in both projects
+		{"", "SPEC-2", onlyA.ID},
+		{"a", "SPEC-9", ""},
+		{"a", "TASK-1", ""},
+		{"a", a.ID, ""},
+	}
+	for _, tc := range cases {
+		got, err := st.ResolveShortID(ctx, tc.project, "spec", tc.ref)
+		if err != nil {
+			t.Fatalf("resolve %q in %q: %v", tc.ref, tc.project, err)
+		}
+		if got != tc.want {
+			t.Errorf("resolve %q in %q = %q, want %q", tc.ref, tc.project, got, tc.want)
+		}
+	}
+}
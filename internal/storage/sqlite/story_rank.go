@@ -0,0 +1,263 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Story ranks are lexorank-style keys: base-36 strings compared bytewise,
+// so a story can move between two neighbours by taking a key between
+// theirs without renumbering the rest of the epic. Keys never end in '0',
+// which keeps a key strictly between any two others available.
+
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// New stories are appended rankStep apart in the first rankWidth digits,
+// leaving room for tens of thousands of appends before keys grow and for
+// moves between any two stories.
+const (
+	rankWidth = 6
+	rankStep  = 36 * 36 * 36
+	rankFirst = "1"
+)
+
+// rankAfter returns a key after last, the highest key in the epic ("" for
+// an empty epic).
+func rankAfter(last string) string {
+	if last == "" {
+		return rankFirst
+	}
+	head := last
+	if len(head) > rankWidth {
+		head = head[:rankWidth]
+	}
+	head += strings.Repeat("0", rankWidth-len(head))
+	n, err := strconv.ParseUint(head, 36, 64)
+	if err == nil && n+rankStep < pow36(rankWidth) {
+		next := strconv.FormatUint(n+rankStep, 36)
+		next = strings.Repeat("0", rankWidth-len(next)) + next
+		return strings.TrimRight(next, "0")
+	}
+	return rankBetween(last, "")
+}
+
+func pow36(n int) uint64 {
+	p := uint64(1)
+	for range n {
+		p *= 36
+	}
+	return p
+}
+
+// rankBetween returns a key strictly between a and b, where "" stands for
+// the start (a) or end (b) of the epic. a must sort before b.
+func rankBetween(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, reading missing digits of a as '0'.
+		n := 0
+		for n < len(b) {
+			ca := byte('0')
+			if n < len(a) {
+				ca = a[n]
+			}
+			if ca != b[n] {
+				break
+			}
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + rankBetween(rest, b[n:])
+		}
+	}
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(rankDigits, a[0])
+	}
+	db := len(rankDigits)
+	if b != "" {
+		db = strings.IndexByte(rankDigits, b[0])
+	}
+	if db-da > 1 {
+		return string(rankDigits[(da+db+1)/2])
+	}
+	// Adjacent first digits: b's first digit alone sorts between them when
+	// b goes on, otherwise extend a.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(rankDigits[da]) + rankBetween(rest, "")
+}
+
+// migrateStoryRank adds the rank column and ranks existing stories by
+// creation time within each epic.
+func migrateStoryRank(db *sql.DB) error {
+	if !tableExists(db, "stories") {
+		return nil
+	}
+	if !tableHasColumn(db, "stories", "rank") {
+		if _, err := db.Exec(`ALTER TABLE stories ADD COLUMN rank TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add rank column: %w", err)
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_stories_rank ON stories(project, epic_id, rank)`); err != nil {
+		return fmt.Errorf("create idx_stories_rank: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT project, epic_id, id FROM stories WHERE rank = '' ORDER BY project, epic_id, created_at, id`)
+	if err != nil {
+		return fmt.Errorf("list unranked stories: %w", err)
+	}
+	type unranked struct{ project, epicID, id string }
+	var pending []unranked
+	for rows.Next() {
+		var u unranked
+		if err := rows.Scan(&u.project, &u.epicID, &u.id); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan unranked story: %w", err)
+		}
+		pending = append(pending, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin rank backfill: %w", err)
+	}
+	defer tx.Rollback()
+	var project, epicID, last string
+	for i, u := range pending {
+		if i == 0 || u.project != project || u.epicID != epicID {
+			project, epicID = u.project, u.epicID
+			if last, err = lastStoryRankTx(context.Background(), tx, project, epicID, ""); err != nil {
+				return err
+			}
+		}
+		last = rankAfter(last)
+		if _, err := tx.Exec(`UPDATE stories SET rank = ? WHERE project = ? AND id = ?`, last, u.project, u.id); err != nil {
+			return fmt.Errorf("backfill story rank: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// lastStoryRankTx returns the highest rank in an epic, ignoring story
+// excludeID, or "" when it has no ranked stories.
+func lastStoryRankTx(ctx context.Context, tx dbTx, project, epicID, excludeID string) (string, error) {
+	var last string
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(rank), '') FROM stories WHERE project = ? AND epic_id = ? AND id != ?`,
+		project, epicID, excludeID,
+	).Scan(&last)
+	if err != nil {
+		return "", fmt.Errorf("last story rank: %w", err)
+	}
+	return last, nil
+}
+
+// RankStory moves story id within its epic to just after story after and
+// just before story before; either may be empty, but not both. Only the
+// moved story is rewritten. Ranking doesn't bump the version, so it never
+// conflicts with concurrent edits.
+func (s *Store) RankStory(ctx context.Context, project, id, after, before string) (core.Story, error) {
+	if after == "" && before == "" {
+		return core.Story{}, core.ErrInvalidRank
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Story{}, fmt.Errorf("begin rank story: %w", err)
+	}
+	defer tx.Rollback()
+
+	var epicID string
+	err = tx.QueryRowContext(ctx, `SELECT epic_id FROM stories WHERE project = ? AND id = ?`, project, id).Scan(&epicID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Story{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Story{}, fmt.Errorf("rank story: %w", err)
+	}
+	neighbour := func(other string) (string, error) {
+		if other == id {
+			return "", core.ErrInvalidRank
+		}
+		var rank string
+		err := tx.QueryRowContext(ctx, `SELECT rank FROM stories WHERE project = ? AND epic_id = ? AND id = ?`, project, epicID, other).Scan(&rank)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", core.ErrInvalidRank
+		}
+		return rank, err
+	}
+
+	var lo, hi string
+	if after != "" {
+		if lo, err = neighbour(after); err != nil {
+			return core.Story{}, err
+		}
+	}
+	if before != "" {
+		if hi, err = neighbour(before); err != nil {
+			return core.Story{}, err
+		}
+	}
+	switch {
+	case before == "":
+		// Keep the story directly after lo: stop short of lo's successor.
+		err = tx.QueryRowContext(ctx,
+			`SELECT COALESCE(MIN(rank), '') FROM stories WHERE project = ? AND epic_id = ? AND id != ? AND rank > ?`,
+			project, epicID, id, lo,
+		).Scan(&hi)
+	case after == "":
+		err = tx.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(rank), '') FROM stories WHERE project = ? AND epic_id = ? AND id != ? AND rank < ?`,
+			project, epicID, id, hi,
+		).Scan(&lo)
+	case lo >= hi:
+		return core.Story{}, core.ErrInvalidRank
+	}
+	if err != nil {
+		return core.Story{}, fmt.Errorf("rank story neighbours: %w", err)
+	}
+
+	rank := rankBetween(lo, hi)
+	now := clock.Now().UTC()
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE stories SET rank = ?, updated_at = ? WHERE project = ? AND id = ?`,
+		rank, now.Format(time.RFC3339Nano), project, id,
+	); err != nil {
+		return core.Story{}, fmt.Errorf("rank story: %w", err)
+	}
+	story, err := scanStory(tx.QueryRowContext(ctx,
+		`SELECT id, project, epic_id, title, acceptance_criteria_json, status, priority, version, created_at, updated_at, short_id, rank
+		 FROM stories WHERE project = ? AND id = ?`,
+		project, id,
+	))
+	if err != nil {
+		return core.Story{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.Story{}, fmt.Errorf("commit rank story: %w", err)
+	}
+	return story, nil
+}
@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestRankBetween(t *testing.T) {
+	cases := [][2]string{
+		{"", ""}, {"", "1"}, {"1", ""}, {"1", "2"}, {"1", "11"},
+		{"a", "b"}, {"az", "b"}, {"1", "101"}, {"zz", ""}, {"", "01"},
+	}
+	for _, c := range cases {
+		got := rankBetween(c[0], c[1])
+		if got <= c[0] || (c[1] != "" && got >= c[1]) || got[len(got)-1] == '0' {
+			t.Errorf("rankBetween(%q, %q) = %q", c[0], c[1], got)
+		}
+	}
+
+	// Repeatedly inserting at the same spot keeps finding room.
+	lo, hi := "1", "2"
+	for i := 0; i < 200; i++ {
+		mid := rankBetween(lo, hi)
+		if mid <= lo || mid >= hi {
+			t.Fatalf("step %d: rankBetween(%q, %q) = %q", i, lo, hi, mid)
+		}
+		hi = mid
+	}
+
+	// Appends stay short.
+	last := ""
+	for i := 0; i < 1000; i++ {
+		next := rankAfter(last)
+		if next <= last || len(next) > rankWidth {
+			t.Fatalf("append %d: rankAfter(%q) = %q", i, last, next)
+		}
+		last = next
+	}
+}
+
+func TestStoryRankBackfill(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "stories.db")
+	store, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epic, _ := store.CreateEpic(ctx, core.Epic{Project: "p", Title: "Epic"})
+	for _, title := range []string{"a", "b", "c"} {
+		if _, err := store.CreateStory(ctx, core.Story{Project: "p", EpicID: epic.ID, Title: title}); err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+	}
+	// Stories from before ranks existed are ranked by creation time on
+	// the next open.
+	if _, err := store.db.Exec(`UPDATE stories SET rank = ''`); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	store, err = New(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	stories, err := store.ListStories(ctx, "p", epic.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var got string
+	for _, s := range stories {
+		if s.Rank == "" {
+			t.Fatalf("story %s left unranked", s.Title)
+		}
+		got += s.Title
+	}
+	if got != "abc" {
+		t.Fatalf("backfilled order = %s", got)
+	}
+}
+
+func TestRankStory(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	epic, _ := store.CreateEpic(ctx, core.Epic{Project: "p", Title: "Epic"})
+	other, _ := store.CreateEpic(ctx, core.Epic{Project: "p", Title: "Other"})
+	var ids []string
+	for _, title := range []string{"a", "b", "c", "d"} {
+		story, err := store.CreateStory(ctx, core.Story{Project: "p", EpicID: epic.ID, Title: title})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		ids = append(ids, story.ID)
+	}
+	order := func() string {
+		t.Helper()
+		stories, err := store.ListStories(ctx, "p", epic.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		var out string
+		for _, s := range stories {
+			out += s.Title
+		}
+		return out
+	}
+	if got := order(); got != "abcd" {
+		t.Fatalf("initial order = %s", got)
+	}
+
+	moved, err := store.RankStory(ctx, "p", ids[3], ids[0], "")
+	if err != nil {
+		t.Fatalf("rank d after a: %v", err)
+	}
+	if moved.Version != 1 {
+		t.Fatalf("ranking bumped version to %d", moved.Version)
+	}
+	if got := order(); got != "adbc" {
+		t.Fatalf("after moving d = %s", got)
+	}
+	if _, err := store.RankStory(ctx, "p", ids[2], "", ids[0]); err != nil {
+		t.Fatalf("rank c before a: %v", err)
+	}
+	if _, err := store.RankStory(ctx, "p", ids[1], ids[0], ids[3]); err != nil {
+		t.Fatalf("rank b between a and d: %v", err)
+	}
+	if got := order(); got != "cabd" {
+		t.Fatalf("after moves = %s", got)
+	}
+
+	if _, err := store.RankStory(ctx, "p", ids[1], ids[3], ids[0]); !errors.Is(err, core.ErrInvalidRank) {
+		t.Fatalf("reversed neighbours: expected ErrInvalidRank, got %v", err)
+	}
+	elsewhere, _ := store.CreateStory(ctx, core.Story{Project: "p", EpicID: other.ID, Title: "x"})
+	if _, err := store.RankStory(ctx, "p", ids[1], elsewhere.ID, ""); !errors.Is(err, core.ErrInvalidRank) {
+		t.Fatalf("neighbour in another epic: expected ErrInvalidRank, got %v", err)
+	}
+	if _, err := store.RankStory(ctx, "p", "missing", ids[0], ""); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("missing story: expected ErrNotFound, got %v", err)
+	}
+
+	// Moving a story to another epic puts it last there.
+	elsewhere.EpicID = epic.ID
+	if _, err := store.UpdateStory(ctx, elsewhere); err != nil {
+		t.Fatalf("move epic: %v", err)
+	}
+	if got := order(); got != "cabdx" {
+		t.Fatalf("after epic move = %s", got)
+	}
+}
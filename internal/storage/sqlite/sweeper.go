@@ -3,8 +3,10 @@ package sqlite
 import (
 	"context"
 	"log"
+	"sync/atomic"
 	"time"
 
+	"github.com/mistakeknot/intermute/internal/clock"
 	"github.com/mistakeknot/intermute/internal/core"
 )
 
@@ -13,15 +15,26 @@ type Broadcaster interface {
 	Broadcast(project, agent string, event any)
 }
 
+// SystemNotifier receives operational events for the hub's system topic.
+type SystemNotifier interface {
+	NotifySystem(ev core.SystemEvent)
+}
+
 // Sweeper runs a background goroutine that periodically cleans up expired
-// reservations held by inactive agents.
+// reservations held by inactive agents and re-delivers messages whose
+// snooze has ended. With SetReleaseStale, it also releases live
+// reservations as soon as their agent's heartbeat is older than the grace
+// period.
 type Sweeper struct {
-	store    *Store
-	bus      Broadcaster
-	interval time.Duration
-	grace    time.Duration // heartbeat grace period
-	cancel   context.CancelFunc
-	done     chan struct{}
+	store        *Store
+	bus          Broadcaster
+	system       SystemNotifier
+	interval     time.Duration
+	grace        time.Duration // heartbeat grace period
+	releaseStale bool
+	cancel       context.CancelFunc
+	done         chan struct{}
+	swept        atomic.Uint64 // reservations removed since start
 }
 
 // NewSweeper creates a new Sweeper. Call Start() to begin sweeping.
@@ -35,6 +48,31 @@ func NewSweeper(store *Store, bus Broadcaster, interval, grace time.Duration) *S
 	}
 }
 
+// SetReleaseStale makes each sweep release the reservations of agents whose
+// heartbeat is older than the grace period, regardless of TTL. Call before
+// Start.
+func (sw *Sweeper) SetReleaseStale(on bool) {
+	sw.releaseStale = on
+}
+
+// SetSystemNotifier reports each failed sweep step to n as a
+// system.sweep_failed event. Call before Start.
+func (sw *Sweeper) SetSystemNotifier(n SystemNotifier) {
+	sw.system = n
+}
+
+// fail logs a sweep step's error and reports it to the system notifier.
+func (sw *Sweeper) fail(step string, err error) {
+	log.Printf("sweeper: %s: %v", step, err)
+	if sw.system != nil {
+		sw.system.NotifySystem(core.SystemEvent{
+			Type: core.EventSystemSweepFailed,
+			At:   time.Now().UTC(),
+			Data: map[string]any{"step": step, "error": err.Error()},
+		})
+	}
+}
+
 // Start launches the background sweep goroutine.
 func (sw *Sweeper) Start(ctx context.Context) {
 	ctx, sw.cancel = context.WithCancel(ctx)
@@ -43,7 +81,7 @@ func (sw *Sweeper) Start(ctx context.Context) {
 		defer close(sw.done)
 
 		// Startup sweep: only clean reservations expired >5min ago
-		sw.runSweep(ctx, time.Now().UTC().Add(-5*time.Minute))
+		sw.pass(ctx, clock.Now().UTC().Add(-5*time.Minute))
 
 		ticker := time.NewTicker(sw.interval)
 		defer ticker.Stop()
@@ -53,7 +91,7 @@ func (sw *Sweeper) Start(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				sw.runSweep(ctx, time.Now().UTC())
+				sw.pass(ctx, clock.Now().UTC())
 			}
 		}
 	}()
@@ -67,12 +105,45 @@ func (sw *Sweeper) Stop() {
 	<-sw.done
 }
 
+// RunOnce runs one sweep pass now, as the ticker would. Used to apply an
+// advanced dev clock without waiting for the next tick.
+func (sw *Sweeper) RunOnce(ctx context.Context) {
+	sw.pass(ctx, clock.Now().UTC())
+}
+
+// pass cleans reservations that expired before expiredBefore, wakes due
+// snoozes, returns the tasks of lapsed claim leases, stops sessions whose
+// heartbeats lapsed, transfers reservations whose takeover went
+// unanswered, reports validated specs that have gone stale, forgets
+// expired idempotency keys and, if enabled, releases stale agents'
+// reservations. Last, it unblocks tasks whose condition those changes (or
+// anything since the previous pass) cleared.
+func (sw *Sweeper) pass(ctx context.Context, expiredBefore time.Time) {
+	sw.runSweep(ctx, expiredBefore)
+	sw.runWake(ctx, clock.Now().UTC())
+	sw.runLeaseExpiry(ctx, clock.Now().UTC())
+	sw.runStaleSessions(ctx)
+	sw.runTakeovers(ctx, clock.Now().UTC())
+	sw.runStaleSpecs(ctx, clock.Now().UTC())
+	sw.runIdempotencyPrune(ctx, clock.Now().UTC())
+	if sw.releaseStale {
+		sw.runReleaseStale(ctx)
+	}
+	sw.runUnblock(ctx)
+}
+
+// SweptTotal returns how many reservations the sweeper has removed or
+// released since it was created.
+func (sw *Sweeper) SweptTotal() uint64 {
+	return sw.swept.Load()
+}
+
 func (sw *Sweeper) runSweep(ctx context.Context, expiredBefore time.Time) {
-	heartbeatAfter := time.Now().UTC().Add(-sw.grace)
+	heartbeatAfter := clock.Now().UTC().Add(-sw.grace)
 
 	deleted, err := sw.store.SweepExpired(ctx, expiredBefore, heartbeatAfter)
 	if err != nil {
-		log.Printf("sweeper: %v", err)
+		sw.fail("sweep expired", err)
 		return
 	}
 
@@ -80,6 +151,7 @@ func (sw *Sweeper) runSweep(ctx context.Context, expiredBefore time.Time) {
 		return
 	}
 
+	sw.swept.Add(uint64(len(deleted)))
 	log.Printf("sweeper: cleaned %d expired reservation(s)", len(deleted))
 
 	if sw.bus != nil {
@@ -90,7 +162,185 @@ func (sw *Sweeper) runSweep(ctx context.Context, expiredBefore time.Time) {
 				"reservation_id": r.ID,
 				"agent_id":       r.AgentID,
 				"path_pattern":   r.PathPattern,
+				"reason":         "ttl",
 			})
 		}
 	}
 }
+
+func (sw *Sweeper) runReleaseStale(ctx context.Context) {
+	released, err := sw.store.ReleaseStaleReservations(ctx, clock.Now().UTC().Add(-sw.grace))
+	if err != nil {
+		sw.fail("release stale", err)
+		return
+	}
+	if len(released) == 0 {
+		return
+	}
+
+	sw.swept.Add(uint64(len(released)))
+	log.Printf("sweeper: released %d reservation(s) held by stale agents", len(released))
+
+	if sw.bus != nil {
+		for _, r := range released {
+			sw.bus.Broadcast(r.Project, "", map[string]any{
+				"type":           string(core.EventReservationExpired),
+				"project":        r.Project,
+				"reservation_id": r.ID,
+				"agent_id":       r.AgentID,
+				"path_pattern":   r.PathPattern,
+				"reason":         "agent_stale",
+			})
+		}
+	}
+}
+
+func (sw *Sweeper) runWake(ctx context.Context, now time.Time) {
+	wakes, err := sw.store.WakeSnoozed(ctx, "", "", now)
+	if err != nil {
+		sw.fail("wake snoozed", err)
+		return
+	}
+	if sw.bus == nil {
+		return
+	}
+	for _, w := range wakes {
+		sw.bus.Broadcast(w.Project, w.Agent, map[string]any{
+			"type":       string(core.EventMessageUnsnoozed),
+			"project":    w.Project,
+			"message_id": w.MessageID,
+			"cursor":     w.Cursor,
+			"agent":      w.Agent,
+		})
+	}
+}
+
+func (sw *Sweeper) runLeaseExpiry(ctx context.Context, now time.Time) {
+	returned, err := sw.store.ExpireTaskLeases(ctx, now)
+	if err != nil {
+		sw.fail("expire task leases", err)
+		return
+	}
+	if len(returned) == 0 {
+		return
+	}
+	log.Printf("sweeper: returned %d task(s) from expired claim leases", len(returned))
+	if sw.bus == nil {
+		return
+	}
+	for _, t := range returned {
+		sw.bus.Broadcast(t.Project, "", map[string]any{
+			"type":      string(core.EventTaskLeaseExpired),
+			"project":   t.Project,
+			"entity_id": t.ID,
+			"data":      t,
+		})
+	}
+}
+
+func (sw *Sweeper) runStaleSessions(ctx context.Context) {
+	stopped, err := sw.store.StopStaleSessions(ctx, clock.Now().UTC().Add(-sw.grace))
+	if err != nil {
+		sw.fail("stop stale sessions", err)
+		return
+	}
+	if len(stopped) == 0 {
+		return
+	}
+	log.Printf("sweeper: stopped %d session(s) whose heartbeats lapsed", len(stopped))
+	if sw.bus == nil {
+		return
+	}
+	for _, st := range stopped {
+		sw.bus.Broadcast(st.Session.Project, "", map[string]any{
+			"type":           string(core.EventSessionStopped),
+			"event_id":       st.EventID,
+			"cursor":         st.Cursor,
+			"project":        st.Session.Project,
+			"entity_id":      st.Session.ID,
+			"data":           st.Session,
+			"reason":         "heartbeat_timeout",
+			"released_tasks": st.Tasks,
+		})
+	}
+}
+
+func (sw *Sweeper) runTakeovers(ctx context.Context, now time.Time) {
+	resolved, err := sw.store.ResolveReservationTakeovers(ctx, now)
+	if err != nil {
+		sw.fail("resolve takeovers", err)
+		return
+	}
+	if len(resolved) == 0 {
+		return
+	}
+	log.Printf("sweeper: resolved %d unanswered reservation takeover(s)", len(resolved))
+	if sw.bus == nil {
+		return
+	}
+	for _, t := range resolved {
+		if t.Status != core.TakeoverTransferred {
+			continue
+		}
+		sw.bus.Broadcast(t.Project, "", map[string]any{
+			"type":           string(core.EventReservationTransferred),
+			"project":        t.Project,
+			"reservation_id": t.ReservationID,
+			"takeover":       t,
+		})
+	}
+}
+
+func (sw *Sweeper) runStaleSpecs(ctx context.Context, now time.Time) {
+	stale, err := sw.store.MarkStaleSpecs(ctx, now)
+	if err != nil {
+		sw.fail("mark stale specs", err)
+		return
+	}
+	if len(stale) == 0 {
+		return
+	}
+	log.Printf("sweeper: %d validated spec(s) went stale", len(stale))
+	if sw.bus == nil {
+		return
+	}
+	for _, st := range stale {
+		sw.bus.Broadcast(st.Spec.Project, "", map[string]any{
+			"type":      string(core.EventSpecStale),
+			"event_id":  st.EventID,
+			"cursor":    st.Cursor,
+			"project":   st.Spec.Project,
+			"entity_id": st.Spec.ID,
+			"data":      st.Spec,
+		})
+	}
+}
+
+func (sw *Sweeper) runIdempotencyPrune(ctx context.Context, now time.Time) {
+	if _, err := sw.store.PruneIdempotencyKeys(ctx, now.Add(-core.IdempotencyKeyTTL)); err != nil {
+		sw.fail("prune idempotency keys", err)
+	}
+}
+
+func (sw *Sweeper) runUnblock(ctx context.Context) {
+	unblocked, err := sw.store.UnblockReadyTasks(ctx, "", "")
+	if err != nil {
+		sw.fail("unblock tasks", err)
+		return
+	}
+	if len(unblocked) == 0 {
+		return
+	}
+	log.Printf("sweeper: unblocked %d task(s)", len(unblocked))
+	if sw.bus == nil {
+		return
+	}
+	for _, t := range unblocked {
+		sw.bus.Broadcast(t.Project, "", map[string]any{
+			"type":      string(core.EventTaskUnblocked),
+			"project":   t.Project,
+			"entity_id": t.ID,
+			"data":      t,
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func migrateTaskBlocks(db *sql.DB) error {
+	if !tableExists(db, "tasks") {
+		return nil
+	}
+	for _, col := range []string{"blocked_reason", "blocked_detail", "unblock_json"} {
+		if tableHasColumn(db, "tasks", col) {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN ` + col + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add %s column: %w", col, err)
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_unblock ON tasks(project) WHERE status = 'blocked' AND unblock_json != ''`); err != nil {
+		return fmt.Errorf("create idx_tasks_unblock: %w", err)
+	}
+	return nil
+}
+
+// clearBlock drops the blocked reason and condition of a task that isn't
+// blocked.
+func clearBlock(t *core.Task) {
+	t.BlockedReason = ""
+	t.BlockedDetail = ""
+	t.UnblockWhen = nil
+}
+
+func unblockJSON(c *core.UnblockCondition) string {
+	if c == nil || *c == (core.UnblockCondition{}) {
+		return ""
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+func parseUnblock(taskID, raw string) *core.UnblockCondition {
+	if raw == "" {
+		return nil
+	}
+	var c core.UnblockCondition
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		log.Printf("WARN: corrupt unblock_json for task %s: %v", taskID, err)
+		return nil
+	}
+	return &c
+}
+
+// UnblockReadyTasks puts back to work the blocked tasks in project (every
+// project when empty) whose unblock condition has cleared: the task they
+// wait on is done or gone, no other agent holds a reservation
+// overlapping their path pattern, or their external ref is externalRef.
+// A task goes back to running if it has an agent, else to pending, with
+// its blocked reason and condition cleared. Returns the unblocked tasks.
+func (s *Store) UnblockReadyTasks(ctx context.Context, project, externalRef string) ([]core.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE status = ? AND unblock_json != ''`
+	args := []any{string(core.TaskStatusBlocked)}
+	if project != "" {
+		query += ` AND project = ?`
+		args = append(args, project)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list blocked tasks: %w", err)
+	}
+	var blocked []core.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		blocked = append(blocked, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var out []core.Task
+	for _, t := range blocked {
+		clear, err := s.unblockCleared(ctx, t, externalRef)
+		if err != nil {
+			return out, err
+		}
+		if !clear {
+			continue
+		}
+		t.Status = core.TaskStatusPending
+		if t.Agent != "" {
+			t.Status = core.TaskStatusRunning
+		}
+		clearBlock(&t)
+		t.UpdatedAt = clock.Now().UTC()
+		res, err := s.db.ExecContext(ctx,
+			`UPDATE tasks SET status = ?, blocked_reason = '', blocked_detail = '', unblock_json = '', version = version + 1, updated_at = ?
+			 WHERE project = ? AND id = ? AND version = ?`,
+			string(t.Status), t.UpdatedAt.Format(time.RFC3339Nano), t.Project, t.ID, t.Version,
+		)
+		if err != nil {
+			return out, fmt.Errorf("unblock task: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue // changed since we read it; the next pass sees the new state
+		}
+		t.Version++
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// unblockCleared reports whether t's unblock condition has cleared.
+func (s *Store) unblockCleared(ctx context.Context, t core.Task, externalRef string) (bool, error) {
+	c := t.UnblockWhen
+	switch {
+	case c == nil:
+		return false, nil
+	case c.TaskID != "":
+		var status string
+		err := s.db.QueryRowContext(ctx, `SELECT status FROM tasks WHERE project = ? AND id = ?`, t.Project, c.TaskID).Scan(&status)
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("check blocking task: %w", err)
+		}
+		return status == string(core.TaskStatusDone), nil
+	case c.PathPattern != "":
+		conflicts, err := s.CheckConflicts(ctx, t.Project, c.PathPattern, true)
+		if err != nil {
+			return false, nil // an unusable pattern never clears
+		}
+		for _, cd := range conflicts {
+			if t.Agent == "" || (cd.AgentID != t.Agent && cd.AgentName != t.Agent) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case c.ExternalRef != "":
+		return externalRef != "" && c.ExternalRef == externalRef, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// CreateTaskComment records comment against its task. Returns
+// core.ErrNotFound if the task doesn't exist.
+func (s *Store) CreateTaskComment(ctx context.Context, comment core.TaskComment) (core.TaskComment, error) {
+	if comment.ID == "" {
+		comment.ID = uuid.NewString()
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = clock.Now().UTC()
+	}
+	var seq int64
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO task_comments (id, project, task_id, author, body, created_at)
+		 SELECT ?, ?, ?, ?, ?, ?
+		 WHERE EXISTS (SELECT 1 FROM tasks WHERE project = ? AND id = ?)
+		 RETURNING seq`,
+		comment.ID, comment.Project, comment.TaskID, comment.Author, comment.Body,
+		comment.CreatedAt.Format(time.RFC3339Nano), comment.Project, comment.TaskID,
+	).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.TaskComment{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.TaskComment{}, fmt.Errorf("create task comment: %w", err)
+	}
+	comment.Cursor = uint64(seq)
+	return comment, nil
+}
+
+// ListTaskComments returns up to limit of a task's comments with a cursor
+// after after, oldest first.
+func (s *Store) ListTaskComments(ctx context.Context, project, taskID string, after uint64, limit int) ([]core.TaskComment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT seq, id, author, body, created_at FROM task_comments
+		 WHERE project = ? AND task_id = ? AND seq > ?
+		 ORDER BY seq LIMIT ?`,
+		project, taskID, after, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list task comments: %w", err)
+	}
+	defer rows.Close()
+	comments := []core.TaskComment{}
+	for rows.Next() {
+		c := core.TaskComment{Project: project, TaskID: taskID}
+		var createdAt string
+		if err := rows.Scan(&c.Cursor, &c.ID, &c.Author, &c.Body, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan task comment: %w", err)
+		}
+		c.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// DeleteTaskComment removes one of a task's comments. Returns
+// core.ErrNotFound if the task has no comment with that ID.
+func (s *Store) DeleteTaskComment(ctx context.Context, project, taskID, id string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM task_comments WHERE project = ? AND task_id = ? AND id = ?`, project, taskID, id)
+	if err != nil {
+		return fmt.Errorf("delete task comment: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// fillCommentCounts sets CommentCount on tasks, which all belong to
+// project unless project is "".
+func (s *Store) fillCommentCounts(ctx context.Context, project string, tasks []core.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	query := `SELECT project, task_id, COUNT(*) FROM task_comments`
+	var args []any
+	if project != "" {
+		query += ` WHERE project = ?`
+		args = append(args, project)
+	}
+	query += ` GROUP BY project, task_id`
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("count task comments: %w", err)
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var p, id string
+		var n int
+		if err := rows.Scan(&p, &id, &n); err != nil {
+			return fmt.Errorf("scan task comment count: %w", err)
+		}
+		counts[p+"\x00"+id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].CommentCount = counts[tasks[i].Project+"\x00"+tasks[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/glob"
+)
+
+func migrateTaskExpectedPaths(db *sql.DB) error {
+	if !tableExists(db, "tasks") {
+		return nil
+	}
+	if !tableHasColumn(db, "tasks", "expected_paths_json") {
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN expected_paths_json TEXT NOT NULL DEFAULT '[]'`); err != nil {
+			return fmt.Errorf("add expected_paths_json column: %w", err)
+		}
+	}
+	return nil
+}
+
+// TaskConflicts cross-references the expected paths of project's pending
+// and running tasks and returns every pair that overlaps, oldest task
+// first. With taskID set, only that task's conflicts are returned, with it
+// as TaskID. Tasks without expected paths never conflict.
+func (s *Store) TaskConflicts(ctx context.Context, project, taskID string) ([]core.TaskConflict, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+taskColumns+`
+		 FROM tasks
+		 WHERE project = ? AND status IN (?, ?) AND json_array_length(expected_paths_json) > 0
+		 ORDER BY created_at, id`,
+		project, string(core.TaskStatusPending), string(core.TaskStatusRunning),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("task conflicts: %w", err)
+	}
+	var tasks []core.Task
+	for rows.Next() {
+		task, err := scanTaskRow(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	conflicts := []core.TaskConflict{}
+	for i, a := range tasks {
+		for j, b := range tasks {
+			if taskID != "" {
+				if a.ID != taskID || i == j {
+					continue
+				}
+			} else if j <= i {
+				continue
+			}
+			overlaps := pathOverlaps(a.ExpectedPaths, b.ExpectedPaths)
+			if len(overlaps) == 0 {
+				continue
+			}
+			conflicts = append(conflicts, core.TaskConflict{
+				TaskID:      a.ID,
+				TaskTitle:   a.Title,
+				TaskStatus:  a.Status,
+				OtherID:     b.ID,
+				OtherTitle:  b.Title,
+				OtherStatus: b.Status,
+				Concurrent:  a.Status == core.TaskStatusRunning && b.Status == core.TaskStatusRunning,
+				Overlaps:    overlaps,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// pathOverlaps returns each pair of patterns from a and b that can match a
+// common path. Patterns the glob engine rejects are skipped.
+func pathOverlaps(a, b []string) []core.TaskPathOverlap {
+	var out []core.TaskPathOverlap
+	for _, pa := range a {
+		for _, pb := range b {
+			if overlap, err := glob.PatternsOverlap(pa, pb); err == nil && overlap {
+				out = append(out, core.TaskPathOverlap{Path: pa, OtherPath: pb})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func migrateTaskLeases(db *sql.DB) error {
+	if !tableExists(db, "tasks") {
+		return nil
+	}
+	if !tableHasColumn(db, "tasks", "lease_id") {
+		if _, err := db.Exec(`ALTER TABLE tasks ADD COLUMN lease_id TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add lease_id column: %w", err)
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_lease ON tasks(project, lease_id) WHERE lease_id != ''`); err != nil {
+		return fmt.Errorf("create idx_tasks_lease: %w", err)
+	}
+	return nil
+}
+
+// RenewTaskLease pushes a live lease's expiry to expiresAt. A lease that
+// already lapsed (or was released) is core.ErrNotFound: its tasks may
+// belong to someone else by now.
+func (s *Store) RenewTaskLease(ctx context.Context, project, id string, expiresAt time.Time) (core.TaskLease, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.TaskLease{}, fmt.Errorf("begin renew task lease: %w", err)
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx,
+		`UPDATE task_leases SET expires_at = ? WHERE project = ? AND id = ? AND expires_ms > ?`,
+		expiresAt.UTC().Format(time.RFC3339Nano), project, id, unixMillis(clock.Now()),
+	)
+	if err != nil {
+		return core.TaskLease{}, fmt.Errorf("renew task lease: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return core.TaskLease{}, core.ErrNotFound
+	}
+	lease, err := getTaskLeaseTx(ctx, tx, project, id)
+	if err != nil {
+		return core.TaskLease{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return core.TaskLease{}, fmt.Errorf("commit renew task lease: %w", err)
+	}
+	return lease, nil
+}
+
+// ReleaseTaskLease ends a lease early, returning its still-running tasks
+// to pending as if it had expired. Returns those tasks.
+func (s *Store) ReleaseTaskLease(ctx context.Context, project, id string) ([]core.Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin release task lease: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := getTaskLeaseTx(ctx, tx, project, id); err != nil {
+		return nil, err
+	}
+	returned, err := endTaskLeaseTx(ctx, tx, project, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit release task lease: %w", err)
+	}
+	return returned, nil
+}
+
+// ExpireTaskLeases ends every lease that expired before now and returns
+// the tasks that went back to pending.
+func (s *Store) ExpireTaskLeases(ctx context.Context, now time.Time) ([]core.Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin expire task leases: %w", err)
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, `SELECT project, id FROM task_leases WHERE expires_ms <= ?`, unixMillis(now))
+	if err != nil {
+		return nil, fmt.Errorf("list expired task leases: %w", err)
+	}
+	var expired [][2]string
+	for rows.Next() {
+		var project, id string
+		if err := rows.Scan(&project, &id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan expired task lease: %w", err)
+		}
+		expired = append(expired, [2]string{project, id})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var returned []core.Task
+	for _, lease := range expired {
+		tasks, err := endTaskLeaseTx(ctx, tx, lease[0], lease[1])
+		if err != nil {
+			return nil, err
+		}
+		returned = append(returned, tasks...)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit expire task leases: %w", err)
+	}
+	return returned, nil
+}
+
+// endTaskLeaseTx deletes a lease, unassigns its still-running tasks back
+// to pending and detaches the rest.
+func endTaskLeaseTx(ctx context.Context, tx dbTx, project, id string) ([]core.Task, error) {
+	now := clock.Now().UTC().Format(time.RFC3339Nano)
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE tasks SET status = ?, agent = '', lease_id = '', version = version + 1, updated_at = ?
+		 WHERE project = ? AND lease_id = ? AND status = ?
+		 RETURNING id`,
+		string(core.TaskStatusPending), now, project, id, string(core.TaskStatusRunning),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("return leased tasks: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var taskID string
+		if err := rows.Scan(&taskID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan returned task: %w", err)
+		}
+		ids = append(ids, taskID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET lease_id = '' WHERE project = ? AND lease_id = ?`, project, id); err != nil {
+		return nil, fmt.Errorf("detach leased tasks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_leases WHERE project = ? AND id = ?`, project, id); err != nil {
+		return nil, fmt.Errorf("delete task lease: %w", err)
+	}
+
+	returned := make([]core.Task, 0, len(ids))
+	for _, taskID := range ids {
+		task, err := scanTask(tx.QueryRowContext(ctx,
+			`SELECT `+taskColumns+`
+			 FROM tasks WHERE project = ? AND id = ?`, project, taskID))
+		if err != nil {
+			return nil, err
+		}
+		returned = append(returned, task)
+	}
+	return returned, nil
+}
+
+func getTaskLeaseTx(ctx context.Context, tx dbTx, project, id string) (core.TaskLease, error) {
+	lease := core.TaskLease{ID: id, Project: project, TaskIDs: []string{}}
+	var expiresAt string
+	err := tx.QueryRowContext(ctx,
+		`SELECT agent, expires_at FROM task_leases WHERE project = ? AND id = ?`, project, id,
+	).Scan(&lease.Agent, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.TaskLease{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.TaskLease{}, fmt.Errorf("get task lease: %w", err)
+	}
+	lease.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM tasks WHERE project = ? AND lease_id = ? ORDER BY created_at, id`, project, id)
+	if err != nil {
+		return core.TaskLease{}, fmt.Errorf("list leased tasks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var taskID string
+		if err := rows.Scan(&taskID); err != nil {
+			return core.TaskLease{}, fmt.Errorf("scan leased task: %w", err)
+		}
+		lease.TaskIDs = append(lease.TaskIDs, taskID)
+	}
+	return lease, rows.Err()
+}
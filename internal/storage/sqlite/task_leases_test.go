@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestTaskLeaseLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"a", "b", "c"} {
+		if _, err := store.CreateTask(ctx, core.Task{Project: "p", Title: title, Status: core.TaskStatusPending}); err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+	}
+
+	now := time.Now().UTC()
+	claimed, err := store.ClaimTasks(ctx, core.TaskClaim{
+		Project: "p", Agent: "worker", Limit: 10,
+		LeaseID: "lease-1", LeaseExpiresAt: now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(claimed) != 3 {
+		t.Fatalf("claimed %d tasks, want all 3", len(claimed))
+	}
+
+	// Finishing one task takes it out of the lease's hands.
+	done := claimed[0]
+	done.Status = core.TaskStatusDone
+	if _, err := store.UpdateTask(ctx, done); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	lease, err := store.RenewTaskLease(ctx, "p", "lease-1", now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if len(lease.TaskIDs) != 3 || lease.Agent != "worker" || !lease.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("renewed lease = %+v", lease)
+	}
+
+	// Nothing lapses before the renewed expiry.
+	if returned, err := store.ExpireTaskLeases(ctx, now.Add(30*time.Minute)); err != nil || len(returned) != 0 {
+		t.Fatalf("early expiry returned %+v, %v", returned, err)
+	}
+
+	returned, err := store.ExpireTaskLeases(ctx, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	if len(returned) != 2 {
+		t.Fatalf("returned %d tasks, want the 2 unfinished", len(returned))
+	}
+	for _, task := range returned {
+		if task.Status != core.TaskStatusPending || task.Agent != "" {
+			t.Fatalf("returned task = %+v", task)
+		}
+	}
+	got, err := store.GetTask(ctx, "p", done.ID)
+	if err != nil || got.Status != core.TaskStatusDone || got.Agent != "worker" {
+		t.Fatalf("finished task = %+v, %v", got, err)
+	}
+
+	if _, err := store.RenewTaskLease(ctx, "p", "lease-1", now.Add(3*time.Hour)); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("renew after expiry: %v", err)
+	}
+	if _, err := store.ReleaseTaskLease(ctx, "p", "lease-1"); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("release after expiry: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// taskSortExprs are the ORDER BY expressions for each sort field. Time
+// fields use their integer twins (see timestamps.go).
+var taskSortExprs = map[core.TaskSortField]string{
+	core.TaskSortCreatedAt: "created_ms",
+	core.TaskSortUpdatedAt: "updated_ms",
+	core.TaskSortDueAt:     "due_ms",
+	core.TaskSortPriority:  "CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END",
+	core.TaskSortTitle:     "lower(title)",
+}
+
+// QueryTasks lists the tasks matching q, in q's order.
+func (s *Store) QueryTasks(ctx context.Context, q core.TaskQuery) ([]core.Task, error) {
+	var where []string
+	var args []any
+	in := func(col, op string, n int, value func(i int) any) {
+		if n == 0 {
+			return
+		}
+		where = append(where, col+" "+op+" ("+strings.TrimSuffix(strings.Repeat("?, ", n), ", ")+")")
+		for i := range n {
+			args = append(args, value(i))
+		}
+	}
+	bound := func(col, op string, t time.Time) {
+		if !t.IsZero() {
+			where = append(where, col+" "+op+" ?")
+			args = append(args, unixMillis(t))
+		}
+	}
+	if q.Project != "" {
+		where = append(where, "project = ?")
+		args = append(args, q.Project)
+	}
+	in("status", "IN", len(q.Statuses), func(i int) any { return string(q.Statuses[i]) })
+	in("status", "NOT IN", len(q.ExcludeStatuses), func(i int) any { return string(q.ExcludeStatuses[i]) })
+	in("agent", "IN", len(q.Agents), func(i int) any { return q.Agents[i] })
+	in("story_id", "IN", len(q.StoryIDs), func(i int) any { return q.StoryIDs[i] })
+	in("priority", "IN", len(q.Priorities), func(i int) any { return string(q.Priorities[i]) })
+	bound("created_ms", ">", q.CreatedAfter)
+	bound("created_ms", "<", q.CreatedBefore)
+	bound("updated_ms", ">", q.UpdatedAfter)
+	bound("updated_ms", "<", q.UpdatedBefore)
+	bound("due_ms", ">", q.DueAfter)
+	bound("due_ms", "<", q.DueBefore)
+
+	query := `SELECT ` + taskColumns + ` FROM tasks`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	sorts := q.Sort
+	if len(sorts) == 0 {
+		sorts = []core.TaskSort{{Field: core.TaskSortUpdatedAt, Desc: true}}
+	}
+	var order []string
+	for _, sort := range sorts {
+		expr, ok := taskSortExprs[sort.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot sort by %q", core.ErrInvalidTaskQuery, sort.Field)
+		}
+		if sort.Field == core.TaskSortDueAt {
+			order = append(order, "due_ms IS NULL")
+		}
+		if sort.Desc {
+			expr += " DESC"
+		}
+		order = append(order, expr)
+	}
+	query += " ORDER BY " + strings.Join(order, ", ") + ", id"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	defer rows.Close()
+	var tasks []core.Task
+	for rows.Next() {
+		task, err := scanTaskRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.fillCommentCounts(ctx, q.Project, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Teams
+//
+// A team is a contact group with team = 1. Messages and reservations
+// addressed to "@name" keep that address as their recipient or holder, and
+// the queries below resolve it against the group's membership at read time,
+// so members added later see earlier team mail and any member can release
+// the team's reservations.
+
+// teamAddressPrefix marks a recipient or reservation holder as a team.
+const teamAddressPrefix = "@"
+
+// teamAddressesOf selects the addresses of the teams agent (bound to the
+// second ?) belongs to in the project named by projectCol.
+const teamAddressesOf = `SELECT '@' || tm.group_name FROM contact_group_members tm
+	 JOIN contact_groups tg ON tg.project = tm.project AND tg.name = tm.group_name
+	 WHERE tg.team = 1 AND tm.project = %s AND tm.agent_id = ?`
+
+// addressedTo is a SQL condition matching col against an agent or any team
+// it belongs to. It takes the agent ID twice.
+func addressedTo(col, projectCol string) string {
+	return fmt.Sprintf(`(%[1]s = ? OR %[1]s IN (`+teamAddressesOf+`))`, col, projectCol)
+}
+
+func migrateContactGroupTeam(db *sql.DB) error {
+	if tableHasColumn(db, "contact_groups", "team") {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE contact_groups ADD COLUMN team INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return fmt.Errorf("add contact_groups.team: %w", err)
+	}
+	return nil
+}
+
+// recipientRow picks which message_recipients row agentID acts on: its own
+// if it has one, else that of a team it belongs to. Falls back to agentID
+// so callers report "not a recipient" as before.
+func (s *Store) recipientRow(ctx context.Context, project, messageID, agentID string) string {
+	var row string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT agent_id FROM message_recipients r
+		 WHERE r.project = ? AND r.message_id = ? AND `+addressedTo("r.agent_id", "r.project")+`
+		 ORDER BY r.agent_id = ? DESC LIMIT 1`,
+		project, messageID, agentID, agentID, agentID,
+	).Scan(&row)
+	if err != nil {
+		return agentID
+	}
+	return row
+}
+
+// teamAlliesTx returns the holders whose reservations never conflict with
+// holder's: for a team, its members; for an agent, its teams.
+func teamAlliesTx(ctx context.Context, tx dbTx, project, holder string) (map[string]bool, error) {
+	var rows *sql.Rows
+	var err error
+	if name, ok := strings.CutPrefix(holder, teamAddressPrefix); ok {
+		rows, err = tx.QueryContext(ctx,
+			`SELECT tm.agent_id FROM contact_group_members tm
+			 JOIN contact_groups tg ON tg.project = tm.project AND tg.name = tm.group_name
+			 WHERE tg.team = 1 AND tm.project = ? AND tm.group_name = ?`,
+			project, name,
+		)
+	} else {
+		rows, err = tx.QueryContext(ctx, fmt.Sprintf(teamAddressesOf, "?"), project, holder)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("team allies: %w", err)
+	}
+	defer rows.Close()
+	allies := map[string]bool{}
+	for rows.Next() {
+		var ally string
+		if err := rows.Scan(&ally); err != nil {
+			return nil, fmt.Errorf("scan team ally: %w", err)
+		}
+		allies[ally] = true
+	}
+	return allies, rows.Err()
+}
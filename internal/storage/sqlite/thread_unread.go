@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/clock"
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Per-thread unread counts
+//
+// thread_index.unread_count is the number of messages in the thread that
+// the row's agent received and hasn't read, snoozed ones included. It is
+// bumped on delivery and kept in step by MarkRead, SnoozeMessage and
+// MarkThreadRead, so ListThreads never has to count message_recipients.
+
+// threadUnreadCount recomputes a thread_index row's unread_count from
+// message_recipients, for backfills and after retention deletes messages.
+const threadUnreadCount = `(SELECT COUNT(*) FROM message_recipients r
+	JOIN messages m ON m.project = r.project AND m.message_id = r.message_id
+	WHERE r.project = thread_index.project AND r.agent_id = thread_index.agent
+	  AND m.thread_id = thread_index.thread_id AND r.read_at IS NULL)`
+
+func migrateThreadUnread(db *sql.DB) error {
+	if !tableExists(db, "thread_index") || tableHasColumn(db, "thread_index", "unread_count") {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE thread_index ADD COLUMN unread_count INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return fmt.Errorf("add unread_count column: %w", err)
+	}
+	return backfillThreadUnread(db)
+}
+
+func backfillThreadUnread(db *sql.DB) error {
+	if _, err := db.Exec(`UPDATE thread_index SET unread_count = ` + threadUnreadCount); err != nil {
+		return fmt.Errorf("backfill thread unread counts: %w", err)
+	}
+	return nil
+}
+
+// adjustThreadUnread adds delta to agentID's unread count for the thread
+// messageID belongs to. Messages without a thread have no row to touch.
+func adjustThreadUnread(ctx context.Context, tx dbTx, project, messageID, agentID string, delta int) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE thread_index SET unread_count = CASE WHEN unread_count + ? > 0 THEN unread_count + ? ELSE 0 END
+		 WHERE project = ? AND agent = ? AND thread_id = (
+		   SELECT thread_id FROM messages WHERE project = ? AND message_id = ?)`,
+		delta, delta, project, agentID, project, messageID,
+	); err != nil {
+		return fmt.Errorf("adjust thread unread: %w", err)
+	}
+	return nil
+}
+
+// MarkThreadRead marks every message in threadID that agentID received as
+// read and clears its unread count for the thread, returning how many
+// messages were newly read. Returns core.ErrNotFound if agentID isn't a
+// participant in the thread.
+func (s *Store) MarkThreadRead(ctx context.Context, project, threadID, agentID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin mark thread read: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE thread_index SET unread_count = 0 WHERE project = ? AND thread_id = ? AND agent = ?`,
+		project, threadID, agentID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("clear thread unread: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, core.ErrNotFound
+	}
+	res, err = tx.ExecContext(ctx,
+		`UPDATE message_recipients SET read_at = ?
+		 WHERE project = ? AND agent_id = ? AND read_at IS NULL AND message_id IN (
+		   SELECT message_id FROM messages WHERE project = ? AND thread_id = ?)`,
+		clock.Now().UTC().Format(time.RFC3339Nano), project, agentID, project, threadID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("mark thread read: %w", err)
+	}
+	read, _ := res.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit mark thread read: %w", err)
+	}
+	return int(read), nil
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func threadUnread(t *testing.T, st *Store, agent, threadID string) int {
+	t.Helper()
+	threads, err := st.ListThreads(context.Background(), "proj", agent, 0, 50)
+	if err != nil {
+		t.Fatalf("list threads: %v", err)
+	}
+	for _, th := range threads {
+		if th.ThreadID == threadID {
+			return th.Unread
+		}
+	}
+	t.Fatalf("%s has no thread %s", agent, threadID)
+	return 0
+}
+
+func TestThreadUnreadCounts(t *testing.T) {
+	ctx := context.Background()
+	st := NewSQLiteTest(t)
+	send := func(id, thread, from, to string) {
+		t.Helper()
+		if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Project: "proj", Message: core.Message{
+			ID: id, ThreadID: thread, Project: "proj", From: from, To: []string{to}, Body: id,
+		}}); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+	}
+	send("m1", "t1", "alice", "bob")
+	send("m2", "t1", "alice", "bob")
+	send("m3", "t1", "bob", "alice")
+	send("m4", "t2", "alice", "bob")
+
+	if got := threadUnread(t, st, "bob", "t1"); got != 2 {
+		t.Fatalf("bob t1 unread = %d, want 2", got)
+	}
+	if got := threadUnread(t, st, "alice", "t1"); got != 1 {
+		t.Fatalf("alice t1 unread = %d, want 1 (her own messages don't count)", got)
+	}
+
+	if err := st.MarkRead(ctx, "proj", "m1", "bob"); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	if err := st.MarkRead(ctx, "proj", "m1", "bob"); err != nil {
+		t.Fatalf("mark read again: %v", err)
+	}
+	if got := threadUnread(t, st, "bob", "t1"); got != 1 {
+		t.Fatalf("bob t1 unread after read = %d, want 1", got)
+	}
+
+	// Snoozing a read message makes it unread again.
+	if err := st.SnoozeMessage(ctx, "proj", "m1", "bob", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if got := threadUnread(t, st, "bob", "t1"); got != 2 {
+		t.Fatalf("bob t1 unread after snooze = %d, want 2", got)
+	}
+
+	n, err := st.MarkThreadRead(ctx, "proj", "t1", "bob")
+	if err != nil {
+		t.Fatalf("mark thread read: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("marked %d read, want 2", n)
+	}
+	if got := threadUnread(t, st, "bob", "t1"); got != 0 {
+		t.Fatalf("bob t1 unread after mark thread read = %d, want 0", got)
+	}
+	if got := threadUnread(t, st, "bob", "t2"); got != 1 {
+		t.Fatalf("bob t2 unread = %d, want 1 (other threads untouched)", got)
+	}
+	if got := threadUnread(t, st, "alice", "t1"); got != 1 {
+		t.Fatalf("alice t1 unread = %d, want 1 (other agents untouched)", got)
+	}
+	status, _ := st.RecipientStatus(ctx, "proj", "m2")
+	if status["bob"] == nil || status["bob"].ReadAt == nil {
+		t.Fatal("m2 not marked read for bob")
+	}
+
+	if _, err := st.MarkThreadRead(ctx, "proj", "t1", "carol"); !errors.Is(err, core.ErrNotFound) {
+		t.Fatalf("mark thread read by non-participant: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestThreadUnreadMigrationBackfills(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "intermute.db")
+	st, err := New(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, id := range []string{"m1", "m2"} {
+		if _, err := st.AppendEvent(ctx, core.Event{Type: core.EventMessageCreated, Project: "proj", Message: core.Message{
+			ID: id, ThreadID: "t1", Project: "proj", From: "alice", To: []string{"bob"}, Body: id,
+		}}); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+	}
+	if err := st.MarkRead(ctx, "proj", "m1", "bob"); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	// Roll the table back to its shape before unread counts.
+	if _, err := st.db.Exec(`ALTER TABLE thread_index DROP COLUMN unread_count`); err != nil {
+		t.Fatalf("drop column: %v", err)
+	}
+	st.Close()
+
+	st, err = New(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer st.Close()
+	if got := threadUnread(t, st, "bob", "t1"); got != 1 {
+		t.Fatalf("backfilled unread = %d, want 1", got)
+	}
+}
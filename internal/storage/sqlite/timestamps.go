@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Integer timestamps
+//
+// Timestamps are stored as RFC 3339 text, which compares correctly as a
+// string only when every value has the same width: RFC3339Nano drops
+// trailing zeros, so "...:05Z" sorts after "...:05.5Z". The columns range
+// queries filter or sort on therefore have an INTEGER unix-millis twin
+// (created_at → created_ms, expires_at → expires_ms, last_seen →
+// last_seen_ms) that those queries use instead.
+//
+// The text columns stay the source of truth and are what rows are read
+// back from. Triggers derive the integer column from the text one on every
+// insert and update, so writers, archive restores and snapshot imports
+// don't need to know about it; migrateUnixMillis backfills older databases.
+
+// millisColumn is a text timestamp column with an integer twin.
+type millisColumn struct {
+	table, text, millis string
+}
+
+var millisColumns = []millisColumn{
+	{"messages", "created_at", "created_ms"},
+	{"events", "created_at", "created_ms"},
+	{"file_reservations", "created_at", "created_ms"},
+	{"file_reservations", "expires_at", "expires_ms"},
+	{"file_reservations", "released_at", "released_ms"},
+	{"agents", "last_seen", "last_seen_ms"},
+	{"task_leases", "expires_at", "expires_ms"},
+	{"tasks", "created_at", "created_ms"},
+	{"tasks", "updated_at", "updated_ms"},
+	{"tasks", "due_at", "due_ms"},
+	{"specs", "updated_at", "updated_ms"},
+	{"epics", "updated_at", "updated_ms"},
+	{"cujs", "updated_at", "updated_ms"},
+	{"goals", "updated_at", "updated_ms"},
+	{"sessions", "heartbeat_at", "heartbeat_ms"},
+	{"message_recipients", "snoozed_until", "snoozed_ms"},
+	{"reservation_takeovers", "deadline", "deadline_ms"},
+	{"report_schedules", "next_run_at", "next_run_ms"},
+	{"idempotency_keys", "created_at", "created_ms"},
+	{"window_identities", "expires_at", "expires_ms"},
+}
+
+// isMillisTwin reports whether column of table is an integer twin, which
+// the database derives from its text column rather than taking on insert.
+func isMillisTwin(table, column string) bool {
+	for _, c := range millisColumns {
+		if c.table == table && c.millis == column {
+			return true
+		}
+	}
+	return false
+}
+
+// millisIndexes replace the text-column indexes for the range queries.
+var millisIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_messages_created_ms ON messages(project, created_ms)`,
+	`CREATE INDEX IF NOT EXISTS idx_events_created_ms ON events(project, created_ms)`,
+	`CREATE INDEX IF NOT EXISTS idx_reservations_active_ms ON file_reservations(project, expires_ms) WHERE released_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_task_leases_expires_ms ON task_leases(expires_ms)`,
+	`CREATE INDEX IF NOT EXISTS idx_tasks_updated_ms ON tasks(project, updated_ms)`,
+	`CREATE INDEX IF NOT EXISTS idx_agents_last_seen_ms ON agents(last_seen_ms)`,
+	`CREATE INDEX IF NOT EXISTS idx_sessions_heartbeat_ms ON sessions(heartbeat_ms) WHERE heartbeat_ms IS NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_recipients_snoozed_ms ON message_recipients(snoozed_ms) WHERE snoozed_ms IS NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_takeovers_pending_ms ON reservation_takeovers(deadline_ms) WHERE status = 'pending'`,
+	`CREATE INDEX IF NOT EXISTS idx_report_schedules_due_ms ON report_schedules(enabled, next_run_ms)`,
+	`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_ms ON idempotency_keys(created_ms)`,
+}
+
+// unixMillis is t as the integer the triggers store for it. SQLite rounds
+// fractional seconds to the nearest millisecond, so this does too:
+// truncating would put a bound taken from the same instant a millisecond
+// off the stored value.
+func unixMillis(t time.Time) int64 {
+	return (t.UnixNano() + 500_000) / 1_000_000
+}
+
+// millisExpr converts the RFC 3339 text in col to unix millis in SQL. It
+// is NULL for an empty or malformed value.
+func millisExpr(col string) string {
+	return fmt.Sprintf(`(CAST(strftime('%%s', %[1]s) AS INTEGER) * 1000 + CAST(substr(strftime('%%f', %[1]s), 4) AS INTEGER))`, col)
+}
+
+func migrateUnixMillis(db *sql.DB) error {
+	for _, c := range millisColumns {
+		if !tableExists(db, c.table) {
+			continue
+		}
+		if !tableHasColumn(db, c.table, c.millis) {
+			if _, err := db.Exec(`ALTER TABLE ` + c.table + ` ADD COLUMN ` + c.millis + ` INTEGER`); err != nil {
+				return fmt.Errorf("add %s.%s: %w", c.table, c.millis, err)
+			}
+		}
+		if _, err := db.Exec(`UPDATE ` + c.table + ` SET ` + c.millis + ` = ` + millisExpr(c.text) +
+			` WHERE ` + c.millis + ` IS NULL AND ` + c.text + ` IS NOT NULL`); err != nil {
+			return fmt.Errorf("backfill %s.%s: %w", c.table, c.millis, err)
+		}
+		set := `UPDATE ` + c.table + ` SET ` + c.millis + ` = ` + millisExpr("NEW."+c.text) + ` WHERE rowid = NEW.rowid;`
+		for _, trigger := range []string{
+			`CREATE TRIGGER IF NOT EXISTS trg_` + c.table + `_` + c.millis + `_insert
+			 AFTER INSERT ON ` + c.table + ` BEGIN ` + set + ` END`,
+			`CREATE TRIGGER IF NOT EXISTS trg_` + c.table + `_` + c.millis + `_update
+			 AFTER UPDATE OF ` + c.text + ` ON ` + c.table + ` BEGIN ` + set + ` END`,
+		} {
+			if _, err := db.Exec(trigger); err != nil {
+				return fmt.Errorf("create %s.%s trigger: %w", c.table, c.millis, err)
+			}
+		}
+	}
+	for _, idx := range millisIndexes {
+		if _, err := db.Exec(idx); err != nil {
+			return fmt.Errorf("create unix-millis index: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMigrateUnixMillisBackfills(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "legacy-leases.db"))
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE task_leases (
+		id TEXT NOT NULL,
+		project TEXT NOT NULL,
+		agent TEXT NOT NULL,
+		expires_at TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		PRIMARY KEY (project, id)
+	)`)
+	if err != nil {
+		t.Fatalf("create legacy task_leases: %v", err)
+	}
+	// "...:05Z" sorts after "...:05.5Z" as text though it is earlier.
+	base := time.Date(2026, 3, 1, 12, 0, 5, 0, time.UTC)
+	for id, at := range map[string]time.Time{"whole": base, "half": base.Add(500 * time.Millisecond)} {
+		if _, err := db.Exec(`INSERT INTO task_leases (id, project, agent, expires_at, created_at) VALUES (?, 'p', 'a', ?, ?)`,
+			id, at.Format(time.RFC3339Nano), at.Format(time.RFC3339Nano)); err != nil {
+			t.Fatalf("seed lease: %v", err)
+		}
+	}
+
+	if err := applySchema(db); err != nil {
+		t.Fatalf("applySchema: %v", err)
+	}
+	var ms int64
+	if err := db.QueryRow(`SELECT expires_ms FROM task_leases WHERE id = 'half'`).Scan(&ms); err != nil || ms != base.UnixMilli()+500 {
+		t.Fatalf("backfilled expires_ms = %d, %v; want %d", ms, err, base.UnixMilli()+500)
+	}
+
+	st := &Store{db: &queryLogger{inner: db}}
+	if _, err := st.ExpireTaskLeases(context.Background(), base.Add(200*time.Millisecond)); err != nil {
+		t.Fatalf("expire leases: %v", err)
+	}
+	var left []string
+	rows, err := db.Query(`SELECT id FROM task_leases ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var id string
+		_ = rows.Scan(&id)
+		left = append(left, id)
+	}
+	rows.Close()
+	if len(left) != 1 || left[0] != "half" {
+		t.Fatalf("leases left = %v, want [half]", left)
+	}
+
+	later := base.Add(time.Hour)
+	if _, err := db.Exec(`UPDATE task_leases SET expires_at = ? WHERE id = 'half'`, later.Format(time.RFC3339Nano)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT expires_ms FROM task_leases WHERE id = 'half'`).Scan(&ms); err != nil || ms != later.UnixMilli() {
+		t.Fatalf("expires_ms after update = %d, %v; want %d", ms, err, later.UnixMilli())
+	}
+}
+
+func TestUnixMillisMatchesSQLite(t *testing.T) {
+	st := NewSQLiteTest(t)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, ns := range []int{0, 1, 499_999, 500_000, 999_999_999, 123_456_789} {
+		at := base.Add(time.Duration(ns))
+		var got int64
+		if err := st.db.QueryRow(`SELECT `+millisExpr("?1"), at.Format(time.RFC3339Nano)).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if want := unixMillis(at); got != want {
+			t.Fatalf("%s: sqlite = %d, unixMillis = %d", at.Format(time.RFC3339Nano), got, want)
+		}
+	}
+}
+
+// BenchmarkTimestampRange compares the range filters list and sweep
+// queries use, on the RFC 3339 text column and on its integer twin.
+func BenchmarkTimestampRange(b *testing.B) {
+	st, err := New(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer st.Close()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tx, err := st.db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := range 20000 {
+		at := base.Add(time.Duration(i) * 137 * time.Second).Format(time.RFC3339Nano)
+		project := fmt.Sprintf("p%d", i%4)
+		if _, err := tx.Exec(`INSERT INTO events (id, type, project, created_at) VALUES (?, 'task.updated', ?, ?)`,
+			fmt.Sprint(i), project, at); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tx.Exec(`INSERT INTO file_reservations (id, agent_id, project, path_pattern, created_at, expires_at)
+			VALUES (?, 'a', ?, 'x/*', ?, ?)`, fmt.Sprint(i), project, at, at); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	from, to := base.Add(100*24*time.Hour), base.Add(110*24*time.Hour)
+	now := base.Add(300 * 24 * time.Hour)
+
+	run := func(b *testing.B, query string, args ...any) {
+		for b.Loop() {
+			var n int
+			if err := st.db.QueryRow(query, args...).Scan(&n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("list/text", func(b *testing.B) {
+		run(b, `SELECT COUNT(*) FROM events WHERE project = 'p1' AND created_at >= ? AND created_at < ?`,
+			from.Format(time.RFC3339Nano), to.Format(time.RFC3339Nano))
+	})
+	b.Run("list/millis", func(b *testing.B) {
+		run(b, `SELECT COUNT(*) FROM events WHERE project = 'p1' AND created_ms >= ? AND created_ms < ?`,
+			unixMillis(from), unixMillis(to))
+	})
+	b.Run("sweep/text", func(b *testing.B) {
+		run(b, `SELECT COUNT(*) FROM file_reservations WHERE project = 'p2' AND released_at IS NULL AND expires_at <= ?`,
+			now.Format(time.RFC3339Nano))
+	})
+	b.Run("sweep/millis", func(b *testing.B) {
+		run(b, `SELECT COUNT(*) FROM file_reservations WHERE project = 'p2' AND released_at IS NULL AND expires_ms <= ?`,
+			unixMillis(now))
+	})
+}
+
+func TestHeartbeatCutoffsCompareMillis(t *testing.T) {
+	st := NewSQLiteTest(t)
+	ctx := context.Background()
+	// The agent was last seen at "...:05Z", which sorts after the
+	// "...:05.5Z" cutoff as text though it is earlier.
+	seen := time.Date(2026, 3, 1, 12, 0, 5, 0, time.UTC)
+	expired := seen.Add(-time.Hour).Format(time.RFC3339Nano)
+	if _, err := st.db.Exec(`INSERT INTO agents (id, name, project, created_at, last_seen) VALUES ('a1', 'a1', 'p', ?, ?)`,
+		seen.Format(time.RFC3339Nano), seen.Format(time.RFC3339Nano)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.db.Exec(`INSERT INTO file_reservations (id, agent_id, project, path_pattern, reason, created_at, expires_at, released_at)
+		VALUES ('r1', 'a1', 'p', 'a/*', '', ?, ?, NULL), ('r2', 'a1', 'p', 'b/*', '', ?, ?, ?)`,
+		expired, expired, expired, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	swept, err := st.SweepExpired(ctx, seen, seen.Add(500*time.Millisecond))
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if len(swept) != 1 || swept[0].ID != "r1" {
+		t.Fatalf("swept = %+v, want r1: the agent hasn't heartbeated since the cutoff", swept)
+	}
+
+	var ms sql.NullInt64
+	if err := st.db.QueryRow(`SELECT released_ms FROM file_reservations WHERE id = 'r2'`).Scan(&ms); err != nil || ms.Int64 != seen.Add(-time.Hour).UnixMilli() {
+		t.Fatalf("released_ms = %v, %v; want %d", ms, err, seen.Add(-time.Hour).UnixMilli())
+	}
+}
+
+func TestSweepCutoffsCompareMillis(t *testing.T) {
+	st := NewSQLiteTest(t)
+	ctx := context.Background()
+	// Each row is due at "...:05Z", which sorts after the "...:05.5Z"
+	// cutoff as text though it is earlier.
+	at := time.Date(2026, 3, 1, 12, 0, 5, 0, time.UTC)
+	cutoff := at.Add(500 * time.Millisecond)
+	ts := at.Format(time.RFC3339Nano)
+	for _, q := range []string{
+		`INSERT INTO sessions (id, project, name, agent, status, started_at, updated_at, heartbeat_at) VALUES ('s1', 'p', 's1', 'a', 'running', ?1, ?1, ?1)`,
+		`INSERT INTO idempotency_keys (project, key, request_hash, status, created_at) VALUES ('p', 'k1', 'h', 200, ?1)`,
+		`INSERT INTO report_schedules (id, project, kind, cadence, next_run_at, created_at, updated_at) VALUES ('r1', 'p', 'standup', 'daily', ?1, ?1, ?1)`,
+	} {
+		if _, err := st.db.Exec(q, ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stopped, err := st.StopStaleSessions(ctx, cutoff); err != nil || len(stopped) != 1 {
+		t.Errorf("stale sessions = %+v, %v; want s1", stopped, err)
+	}
+	if n, err := st.PruneIdempotencyKeys(ctx, cutoff); err != nil || n != 1 {
+		t.Errorf("pruned keys = %d, %v; want 1", n, err)
+	}
+	if due, err := st.DueReportSchedules(ctx, cutoff); err != nil || len(due) != 1 {
+		t.Errorf("due schedules = %+v, %v; want r1", due, err)
+	}
+}
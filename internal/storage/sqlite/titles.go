@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// titleTables maps an entity type to its table and parent column.
+var titleTables = map[string]struct{ table, parent string }{
+	"spec":  {"specs", ""},
+	"epic":  {"epics", "spec_id"},
+	"story": {"stories", "epic_id"},
+	"task":  {"tasks", "story_id"},
+}
+
+// titleKeySQL is the value written to an entity's title_key column: its
+// title as FindByTitle compares it when the project enforces unique titles
+// for the entity type, else NULL. The unique index over title_key in each
+// scope (see migrateTitleKeys) then rejects a duplicate that concurrent
+// writers, each checking FindByTitle first, would both let through. Its
+// arguments come from titleKeyArgs.
+const titleKeySQL = `CASE WHEN ` + flagEnabledSQL + ` THEN lower(trim(?)) END`
+
+// titleKeyArgs returns titleKeySQL's arguments.
+func titleKeyArgs(entityType, project, title string) []any {
+	return []any{core.FlagUniqueTitles, core.FlagUniqueTitles + "." + entityType, project, project, title}
+}
+
+// updateTitleKeySQL is the title_key assignment for an update. An
+// unchanged title keeps its key, so editing an entity that predates the
+// flag doesn't fail on a duplicate nobody is introducing. Its arguments
+// are the new title followed by titleKeyArgs.
+const updateTitleKeySQL = `title_key = CASE WHEN title = ? THEN title_key ELSE ` + titleKeySQL + ` END`
+
+// updateTitleKeyArgs returns updateTitleKeySQL's arguments.
+func updateTitleKeyArgs(entityType, project, title string) []any {
+	return append([]any{title}, titleKeyArgs(entityType, project, title)...)
+}
+
+// titleConflict turns a violation of a title_key index into
+// core.ErrDuplicateTitle, passing other errors through.
+func titleConflict(err error) error {
+	if err != nil && strings.Contains(err.Error(), "title_key") {
+		return core.ErrDuplicateTitle
+	}
+	return err
+}
+
+// migrateTitleKeys adds title_key to the titled tables along with the
+// unique index over it in each scope. Rows written before it have no key,
+// so only later writes are held to it.
+func migrateTitleKeys(db *sql.DB) error {
+	for _, t := range titleTables {
+		if !tableExists(db, t.table) {
+			continue
+		}
+		if !tableHasColumn(db, t.table, "title_key") {
+			if _, err := db.Exec(`ALTER TABLE ` + t.table + ` ADD COLUMN title_key TEXT`); err != nil {
+				return fmt.Errorf("add %s.title_key: %w", t.table, err)
+			}
+		}
+		cols := "project, title_key"
+		if t.parent != "" {
+			cols = "project, COALESCE(" + t.parent + ", ''), title_key"
+		}
+		if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_` + t.table + `_title_key ON ` + t.table + `(` + cols + `) WHERE title_key IS NOT NULL`); err != nil {
+			return fmt.Errorf("create idx_%s_title_key: %w", t.table, err)
+		}
+	}
+	return nil
+}
+
+// FindByTitle returns the ID of an entity in scope whose title matches
+// scope.Title, ignoring case and surrounding whitespace, or "" if there is
+// none. A miss is the common case, so it isn't an error: through
+// ResilientStore it would count toward tripping the breaker.
+func (s *Store) FindByTitle(ctx context.Context, scope core.TitleScope) (string, error) {
+	t, ok := titleTables[scope.EntityType]
+	if !ok {
+		return "", fmt.Errorf("find by title: unknown entity type %q", scope.EntityType)
+	}
+	query := `SELECT id FROM ` + t.table + ` WHERE project = ? AND lower(trim(title)) = lower(trim(?)) AND id != ?`
+	args := []any{scope.Project, scope.Title, scope.ExcludeID}
+	if t.parent != "" {
+		query += ` AND COALESCE(` + t.parent + `, '') = ?`
+		args = append(args, scope.ParentID)
+	}
+	var id string
+	err := s.db.QueryRowContext(ctx, query+` ORDER BY created_at LIMIT 1`, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("find by title: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// RunInTx runs fn against a copy of the store bound to one transaction,
+// committing if fn returns true and rolling back otherwise. Store methods
+// that open their own transaction get a savepoint inside it instead. A
+// SQLite store has a single connection, so other callers wait until fn
+// returns: keep fn short. The returned error reports only database failures, not
+// a rollback fn asked for.
+func (s *Store) RunInTx(ctx context.Context, fn func(tx storage.DomainStore) (commit bool)) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	// Releases the connection if fn panics; a no-op once committed.
+	defer tx.Rollback()
+	bound := &Store{db: &txHandle{tx: tx}, bridge: s.bridge, dialect: s.dialect}
+	if !fn(bound) {
+		if err := tx.Rollback(); err != nil {
+			return fmt.Errorf("rollback: %w", err)
+		}
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// txHandle runs a transaction-bound Store's queries on its transaction and
+// turns the transactions its methods begin into savepoints.
+type txHandle struct {
+	tx         dbTx
+	savepoints int
+}
+
+func (h *txHandle) Exec(query string, args ...any) (sql.Result, error) {
+	return h.tx.Exec(query, args...)
+}
+
+func (h *txHandle) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return h.tx.ExecContext(ctx, query, args...)
+}
+
+func (h *txHandle) Query(query string, args ...any) (*sql.Rows, error) {
+	return h.tx.Query(query, args...)
+}
+
+func (h *txHandle) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return h.tx.QueryContext(ctx, query, args...)
+}
+
+func (h *txHandle) QueryRow(query string, args ...any) *sql.Row {
+	return h.tx.QueryRow(query, args...)
+}
+
+func (h *txHandle) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return h.tx.QueryRowContext(ctx, query, args...)
+}
+
+func (h *txHandle) Begin() (dbTx, error) {
+	return h.BeginTx(context.Background(), nil)
+}
+
+func (h *txHandle) BeginTx(ctx context.Context, _ *sql.TxOptions) (dbTx, error) {
+	h.savepoints++
+	sp := &savepoint{txHandle: h, name: fmt.Sprintf("sp%d", h.savepoints)}
+	if _, err := h.tx.ExecContext(ctx, "SAVEPOINT "+sp.name); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// Close is a no-op: the transaction belongs to RunInTx.
+func (h *txHandle) Close() error {
+	return nil
+}
+
+// savepoint stands in for a nested transaction. Like *sql.Tx, Rollback
+// after Commit is a no-op returning sql.ErrTxDone, so the usual
+// defer tx.Rollback() works.
+type savepoint struct {
+	*txHandle
+	name string
+	done bool
+}
+
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.tx.Exec("RELEASE " + sp.name)
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	if _, err := sp.tx.Exec("ROLLBACK TO " + sp.name); err != nil {
+		return err
+	}
+	_, err := sp.tx.Exec("RELEASE " + sp.name)
+	return err
+}
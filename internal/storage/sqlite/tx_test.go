@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+func TestRunInTxReleasesConnectionOnPanic(t *testing.T) {
+	st := NewSQLiteTest(t)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("RunInTx swallowed the panic")
+			}
+		}()
+		_ = st.RunInTx(context.Background(), func(tx storage.DomainStore) bool {
+			if _, err := tx.CreateSpec(context.Background(), core.Spec{Project: "proj", Title: "Lost"}); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			panic("handler bug")
+		})
+	}()
+
+	// The store has one connection: a leaked transaction would hang this.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	specs, err := st.ListSpecs(ctx, "proj", "")
+	if err != nil {
+		t.Fatalf("list after panic: %v", err)
+	}
+	if len(specs) != 0 {
+		t.Fatalf("panicked transaction kept %d specs", len(specs))
+	}
+}
@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+func TestVerifyBackup(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "backup.db")
+	st, err := New(path)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if _, err := st.CreateTask(ctx, core.Task{Project: "p", Title: "t", Status: core.TaskStatusPending}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	st.Close()
+	before, _ := os.ReadFile(path)
+
+	report, err := VerifyBackup(ctx, path)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !report.Restorable() {
+		t.Fatalf("report = %+v, want restorable", report)
+	}
+	if report.Counts["tasks"] != 1 {
+		t.Errorf("tasks = %d, want 1", report.Counts["tasks"])
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("verify modified the backup")
+	}
+}
+
+func TestVerifyBackupRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "garbage.db")
+	if err := os.WriteFile(path, []byte("not a database at all, just some bytes"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	report, err := VerifyBackup(context.Background(), path)
+	if err == nil && report.Restorable() {
+		t.Fatalf("garbage file passed verification: %+v", report)
+	}
+}
@@ -2,10 +2,14 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mistakeknot/intermute/internal/clock"
 	"github.com/mistakeknot/intermute/internal/core"
 )
 
@@ -19,6 +23,7 @@ type ThreadSummary struct {
 	LastFrom     string
 	LastBody     string
 	LastAt       time.Time
+	Unread       int // messages in the thread the agent received and hasn't read
 }
 
 type PendingPoke struct {
@@ -33,18 +38,30 @@ type Store interface {
 	AppendEvents(ctx context.Context, evs ...Event) ([]uint64, error)
 	InboxSince(ctx context.Context, project, agent string, cursor uint64, limit int) ([]core.Message, error)
 	ThreadMessages(ctx context.Context, project, threadID string, cursor uint64) ([]core.Message, error)
+	GetMessage(ctx context.Context, project, messageID string) (core.Message, error)
 	ListThreads(ctx context.Context, project, agent string, cursor uint64, limit int) ([]ThreadSummary, error)
 	RegisterAgent(ctx context.Context, agent core.Agent) (core.Agent, error)
 	Heartbeat(ctx context.Context, project, agentID string) (core.Agent, error)
 	ListAgents(ctx context.Context, project string, capabilities []string) ([]core.Agent, error)
 	// Per-recipient tracking
 	MarkRead(ctx context.Context, project, messageID, agentID string) error
+	MarkThreadRead(ctx context.Context, project, threadID, agentID string) (int, error)
 	MarkAck(ctx context.Context, project, messageID, agentID string) error
 	RecipientStatus(ctx context.Context, project, messageID string) (map[string]*core.RecipientStatus, error)
+	// Ack progress against a message's ack policy; reached is true only for
+	// the call that first finds the quorum met
+	AckQuorum(ctx context.Context, project, messageID string) (progress core.AckProgress, reached bool, err error)
+	// Per-recipient snoozing
+	SnoozeMessage(ctx context.Context, project, messageID, agentID string, until time.Time) error
+	UnsnoozeMessage(ctx context.Context, project, messageID, agentID string) (core.SnoozeWake, error)
+	ListSnoozed(ctx context.Context, project, agentID string) ([]core.SnoozedMessage, error)
+	WakeSnoozed(ctx context.Context, project, agentID string, now time.Time) ([]core.SnoozeWake, error)
 	// Inbox counts
 	InboxCounts(ctx context.Context, project, agentID string) (total int, unread int, err error)
 	// Stale ack queries
 	InboxStaleAcks(ctx context.Context, project, agentID string, ttlSeconds, limit int) ([]core.StaleAck, error)
+	// Unread inbox messages, optionally restricted to the given importance levels (newest first)
+	InboxUnread(ctx context.Context, project, agentID string, importance []string, limit int) ([]core.Message, error)
 	// Agent metadata merge (PATCH semantics: incoming keys overwrite, absent keys preserved)
 	UpdateAgentMetadata(ctx context.Context, agentID string, meta map[string]string) (core.Agent, error)
 	// Contact policy
@@ -54,6 +71,18 @@ type Store interface {
 	RemoveContact(ctx context.Context, agentID, contactAgentID string) error
 	ListContacts(ctx context.Context, agentID string) ([]string, error)
 	IsContact(ctx context.Context, agentID, senderID string) (bool, error)
+	// Contact groups
+	CreateContactGroup(ctx context.Context, group core.ContactGroup) (core.ContactGroup, error)
+	GetContactGroup(ctx context.Context, project, name string) (core.ContactGroup, error)
+	ListContactGroups(ctx context.Context, project string) ([]core.ContactGroup, error)
+	UpdateContactGroup(ctx context.Context, group core.ContactGroup) (core.ContactGroup, error)
+	DeleteContactGroup(ctx context.Context, project, name string) error
+	AddContactGroupMember(ctx context.Context, project, name, agentID string) error
+	RemoveContactGroupMember(ctx context.Context, project, name, agentID string) error
+	// Capability registry
+	SetCapability(ctx context.Context, c core.Capability) (core.Capability, error)
+	ListCapabilities(ctx context.Context, project string) ([]core.Capability, error)
+	DeleteCapability(ctx context.Context, project, name string) error
 	HasReservationOverlap(ctx context.Context, project, agentA, agentB string) (bool, error)
 	IsThreadParticipant(ctx context.Context, project, threadID, agent string) (bool, error)
 	// Topic-based message discovery
@@ -65,6 +94,10 @@ type Store interface {
 	ActiveReservations(ctx context.Context, project string) ([]core.Reservation, error)
 	AgentReservations(ctx context.Context, agentID string) ([]core.Reservation, error)
 	CheckConflicts(ctx context.Context, project, pathPattern string, exclusive bool) ([]core.ConflictDetail, error)
+	// Reservation takeovers
+	RequestReservationTakeover(ctx context.Context, t core.ReservationTakeover) (core.ReservationTakeover, error)
+	LatestReservationTakeover(ctx context.Context, reservationID string) (core.ReservationTakeover, error)
+	RespondReservationTakeover(ctx context.Context, reservationID string, accept bool) (core.ReservationTakeover, error)
 	// Window identity persistence
 	UpsertWindowIdentity(ctx context.Context, wi core.WindowIdentity) (*core.WindowIdentity, error)
 	ListWindowIdentities(ctx context.Context, project string) ([]core.WindowIdentity, error)
@@ -87,6 +120,12 @@ type Store interface {
 	SetLiveTransportEnabled(ctx context.Context, enabled bool) error
 	// Agent token verification
 	AgentForToken(ctx context.Context, token string) (agentID string, err error)
+	// CurrentCursor returns the highest event cursor assigned so far
+	CurrentCursor(ctx context.Context) (uint64, error)
+	// Message attachment blobs
+	PutBlob(ctx context.Context, blob core.Blob, data []byte) (core.Blob, error)
+	GetBlob(ctx context.Context, project, id string) (core.Blob, []byte, error)
+	BlobInfo(ctx context.Context, project, id string) (core.Blob, error)
 }
 
 // InMemory is a minimal in-memory store for tests.
@@ -96,6 +135,12 @@ type InMemory struct {
 	inbox       map[string]map[string][]core.Message
 	messages    map[string]map[string]core.Message      // project -> messageID -> message
 	threadIndex map[string]map[string]map[string]uint64 // project -> threadID -> agent -> lastCursor
+	blobs       map[string]inMemoryBlob                 // project + "/" + id -> blob
+}
+
+type inMemoryBlob struct {
+	info core.Blob
+	data []byte
 }
 
 func NewInMemory() *InMemory {
@@ -104,6 +149,7 @@ func NewInMemory() *InMemory {
 		inbox:       make(map[string]map[string][]core.Message),
 		messages:    make(map[string]map[string]core.Message),
 		threadIndex: make(map[string]map[string]map[string]uint64),
+		blobs:       make(map[string]inMemoryBlob),
 	}
 }
 
@@ -116,7 +162,7 @@ func (m *InMemory) AppendEvent(_ context.Context, ev Event) (uint64, error) {
 	if project == "" {
 		project = ev.Project
 	}
-	recipients := ev.Message.To
+	recipients := ev.Message.Recipients()
 	if len(recipients) == 0 && ev.Agent != "" {
 		recipients = []string{ev.Agent}
 	}
@@ -185,6 +231,18 @@ func (m *InMemory) InboxSince(_ context.Context, project, agent string, cursor u
 	return out, nil
 }
 
+func (m *InMemory) CurrentCursor(_ context.Context) (uint64, error) {
+	return m.cursor, nil
+}
+
+func (m *InMemory) GetMessage(_ context.Context, project, messageID string) (core.Message, error) {
+	msg, ok := m.messages[project][messageID]
+	if !ok {
+		return core.Message{}, core.ErrNotFound
+	}
+	return msg, nil
+}
+
 func (m *InMemory) ThreadMessages(_ context.Context, project, threadID string, cursor uint64) ([]core.Message, error) {
 	var out []core.Message
 	projectMsgs := m.messages[project]
@@ -220,13 +278,16 @@ func (m *InMemory) ListThreads(_ context.Context, project, agent string, cursor
 		}
 		// Find last message in thread
 		var lastMsg core.Message
-		var count int
+		var count, unread int
 		for _, msg := range m.messages[project] {
 			if msg.ThreadID == threadID {
 				count++
 				if lastMsg.Cursor < msg.Cursor {
 					lastMsg = msg
 				}
+				if slices.Contains(msg.Recipients(), agent) {
+					unread++
+				}
 			}
 		}
 		out = append(out, ThreadSummary{
@@ -236,6 +297,7 @@ func (m *InMemory) ListThreads(_ context.Context, project, agent string, cursor
 			LastFrom:     lastMsg.From,
 			LastBody:     lastMsg.Body,
 			LastAt:       lastMsg.CreatedAt,
+			Unread:       unread,
 		})
 	}
 	// Sort by last cursor descending
@@ -260,7 +322,7 @@ func (m *InMemory) RegisterAgent(_ context.Context, agent core.Agent) (core.Agen
 		}
 		for _, existing := range m.agents {
 			if existing.SessionID == agent.SessionID {
-				if time.Since(existing.LastSeen) < core.SessionStaleThreshold {
+				if clock.Since(existing.LastSeen) < core.SessionStaleThreshold {
 					return core.Agent{}, core.ErrActiveSessionConflict
 				}
 				// Reuse: update existing agent
@@ -268,7 +330,7 @@ func (m *InMemory) RegisterAgent(_ context.Context, agent core.Agent) (core.Agen
 				existing.Capabilities = agent.Capabilities
 				existing.Metadata = agent.Metadata
 				existing.Status = agent.Status
-				existing.LastSeen = time.Now().UTC()
+				existing.LastSeen = clock.Now().UTC()
 				m.agents[existing.ID] = existing
 				return existing, nil
 			}
@@ -305,7 +367,7 @@ func (m *InMemory) Heartbeat(_ context.Context, project, agentID string) (core.A
 	if project != "" && agent.Project != project {
 		return core.Agent{}, fmt.Errorf("agent not found")
 	}
-	agent.LastSeen = time.Now().UTC()
+	agent.LastSeen = clock.Now().UTC()
 	m.agents[agentID] = agent
 	return agent, nil
 }
@@ -341,6 +403,14 @@ func (m *InMemory) MarkRead(_ context.Context, project, messageID, agentID strin
 	return nil // In-memory store doesn't track per-recipient status
 }
 
+// MarkThreadRead marks a thread read by a participant (stub for in-memory store)
+func (m *InMemory) MarkThreadRead(_ context.Context, project, threadID, agentID string) (int, error) {
+	if _, ok := m.threadIndex[project][threadID][agentID]; !ok {
+		return 0, core.ErrNotFound
+	}
+	return 0, nil // In-memory store doesn't track per-recipient status
+}
+
 // MarkAck marks a message as acknowledged by a specific recipient (stub for in-memory store)
 func (m *InMemory) MarkAck(_ context.Context, project, messageID, agentID string) error {
 	return nil // In-memory store doesn't track per-recipient status
@@ -351,6 +421,15 @@ func (m *InMemory) RecipientStatus(_ context.Context, project, messageID string)
 	return make(map[string]*core.RecipientStatus), nil // In-memory store doesn't track per-recipient status
 }
 
+// AckQuorum returns the message's ack policy with no acks (stub for in-memory store)
+func (m *InMemory) AckQuorum(_ context.Context, project, messageID string) (core.AckProgress, bool, error) {
+	msg, ok := m.messages[project][messageID]
+	if !ok {
+		return core.AckProgress{}, false, core.ErrNotFound
+	}
+	return core.AckProgress{MessageID: messageID, Policy: msg.AckPolicy}, false, nil
+}
+
 // InboxCounts returns total and unread counts (stub for in-memory store)
 func (m *InMemory) InboxCounts(_ context.Context, project, agentID string) (int, int, error) {
 	msgs := m.inbox[project][agentID]
@@ -362,6 +441,23 @@ func (m *InMemory) InboxStaleAcks(_ context.Context, project, agentID string, tt
 	return nil, nil // In-memory store doesn't track per-recipient status
 }
 
+// InboxUnread returns inbox messages matching importance, newest first. In-memory
+// doesn't track read status, so every inbox message counts as unread.
+func (m *InMemory) InboxUnread(_ context.Context, project, agentID string, importance []string, limit int) ([]core.Message, error) {
+	msgs := m.inbox[project][agentID]
+	var out []core.Message
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if len(importance) > 0 && !slices.Contains(importance, msgs[i].Importance) {
+			continue
+		}
+		out = append(out, msgs[i])
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
 // UpdateAgentMetadata merges metadata keys into an existing agent (stub for in-memory store)
 func (m *InMemory) UpdateAgentMetadata(_ context.Context, agentID string, meta map[string]string) (core.Agent, error) {
 	agent, ok := m.agents[agentID]
@@ -374,7 +470,7 @@ func (m *InMemory) UpdateAgentMetadata(_ context.Context, agentID string, meta m
 	for k, v := range meta {
 		agent.Metadata[k] = v
 	}
-	agent.LastSeen = time.Now().UTC()
+	agent.LastSeen = clock.Now().UTC()
 	m.agents[agentID] = agent
 	return agent, nil
 }
@@ -414,6 +510,70 @@ func (m *InMemory) ListContacts(_ context.Context, _ string) ([]string, error) {
 // IsContact checks contact relationship (stub for in-memory store)
 func (m *InMemory) IsContact(_ context.Context, _, _ string) (bool, error) { return false, nil }
 
+// SnoozeMessage snoozes a message (stub for in-memory store)
+func (m *InMemory) SnoozeMessage(_ context.Context, _, _, _ string, _ time.Time) error {
+	return core.ErrNotFound
+}
+
+// UnsnoozeMessage wakes a snoozed message (stub for in-memory store)
+func (m *InMemory) UnsnoozeMessage(_ context.Context, _, _, _ string) (core.SnoozeWake, error) {
+	return core.SnoozeWake{}, core.ErrNotFound
+}
+
+// ListSnoozed lists snoozed messages (stub for in-memory store)
+func (m *InMemory) ListSnoozed(_ context.Context, _, _ string) ([]core.SnoozedMessage, error) {
+	return nil, nil
+}
+
+// WakeSnoozed wakes due snoozes (stub for in-memory store)
+func (m *InMemory) WakeSnoozed(_ context.Context, _, _ string, _ time.Time) ([]core.SnoozeWake, error) {
+	return nil, nil
+}
+
+// CreateContactGroup creates a contact group (stub for in-memory store)
+func (m *InMemory) CreateContactGroup(_ context.Context, group core.ContactGroup) (core.ContactGroup, error) {
+	return group, nil
+}
+
+// GetContactGroup returns a contact group (stub for in-memory store)
+func (m *InMemory) GetContactGroup(_ context.Context, _, _ string) (core.ContactGroup, error) {
+	return core.ContactGroup{}, core.ErrNotFound
+}
+
+// ListContactGroups lists contact groups (stub for in-memory store)
+func (m *InMemory) ListContactGroups(_ context.Context, _ string) ([]core.ContactGroup, error) {
+	return nil, nil
+}
+
+// UpdateContactGroup updates a contact group (stub for in-memory store)
+func (m *InMemory) UpdateContactGroup(_ context.Context, _ core.ContactGroup) (core.ContactGroup, error) {
+	return core.ContactGroup{}, core.ErrNotFound
+}
+
+// DeleteContactGroup deletes a contact group (stub for in-memory store)
+func (m *InMemory) DeleteContactGroup(_ context.Context, _, _ string) error { return core.ErrNotFound }
+
+// AddContactGroupMember adds a group member (stub for in-memory store)
+func (m *InMemory) AddContactGroupMember(_ context.Context, _, _, _ string) error {
+	return core.ErrNotFound
+}
+
+// RemoveContactGroupMember removes a group member (stub for in-memory store)
+func (m *InMemory) RemoveContactGroupMember(_ context.Context, _, _, _ string) error { return nil }
+
+// SetCapability registers a capability (stub for in-memory store)
+func (m *InMemory) SetCapability(_ context.Context, c core.Capability) (core.Capability, error) {
+	return c, nil
+}
+
+// ListCapabilities lists a project's capability registry (stub for in-memory store)
+func (m *InMemory) ListCapabilities(_ context.Context, _ string) ([]core.Capability, error) {
+	return nil, nil
+}
+
+// DeleteCapability removes a capability (stub for in-memory store)
+func (m *InMemory) DeleteCapability(_ context.Context, _, _ string) error { return core.ErrNotFound }
+
 // HasReservationOverlap checks file reservation overlap (stub for in-memory store)
 func (m *InMemory) HasReservationOverlap(_ context.Context, _, _, _ string) (bool, error) {
 	return false, nil
@@ -477,6 +637,21 @@ func (m *InMemory) CheckConflicts(_ context.Context, project, pathPattern string
 	return nil, nil // In-memory store doesn't track reservations
 }
 
+// RequestReservationTakeover requests a takeover (stub for in-memory store)
+func (m *InMemory) RequestReservationTakeover(_ context.Context, t core.ReservationTakeover) (core.ReservationTakeover, error) {
+	return core.ReservationTakeover{}, core.ErrNotFound // In-memory store doesn't track reservations
+}
+
+// LatestReservationTakeover returns a reservation's takeover (stub for in-memory store)
+func (m *InMemory) LatestReservationTakeover(_ context.Context, reservationID string) (core.ReservationTakeover, error) {
+	return core.ReservationTakeover{}, core.ErrNotFound
+}
+
+// RespondReservationTakeover answers a takeover (stub for in-memory store)
+func (m *InMemory) RespondReservationTakeover(_ context.Context, reservationID string, accept bool) (core.ReservationTakeover, error) {
+	return core.ReservationTakeover{}, core.ErrNotFound
+}
+
 // UpsertWindowIdentity upserts a window identity (stub for in-memory store)
 func (m *InMemory) UpsertWindowIdentity(_ context.Context, wi core.WindowIdentity) (*core.WindowIdentity, error) {
 	return &wi, nil
@@ -558,3 +733,34 @@ func (m *InMemory) LiveTransportEnabled(_ context.Context) (bool, error) {
 func (m *InMemory) SetLiveTransportEnabled(_ context.Context, _ bool) error {
 	return nil
 }
+
+func (m *InMemory) PutBlob(_ context.Context, blob core.Blob, data []byte) (core.Blob, error) {
+	sum := sha256.Sum256(data)
+	blob.SHA256 = hex.EncodeToString(sum[:])
+	for _, b := range m.blobs {
+		if b.info.Project == blob.Project && b.info.SHA256 == blob.SHA256 {
+			return b.info, nil
+		}
+	}
+	blob.ID = uuid.NewString()
+	blob.Size = int64(len(data))
+	blob.CreatedAt = clock.Now().UTC()
+	m.blobs[blob.Project+"/"+blob.ID] = inMemoryBlob{info: blob, data: slices.Clone(data)}
+	return blob, nil
+}
+
+func (m *InMemory) GetBlob(_ context.Context, project, id string) (core.Blob, []byte, error) {
+	b, ok := m.blobs[project+"/"+id]
+	if !ok {
+		return core.Blob{}, nil, core.ErrNotFound
+	}
+	return b.info, b.data, nil
+}
+
+func (m *InMemory) BlobInfo(_ context.Context, project, id string) (core.Blob, error) {
+	b, ok := m.blobs[project+"/"+id]
+	if !ok {
+		return core.Blob{}, core.ErrNotFound
+	}
+	return b.info, nil
+}
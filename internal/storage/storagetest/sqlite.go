@@ -0,0 +1,19 @@
+package storagetest
+
+import (
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+// NewSQLite returns a fresh in-memory SQLite store for tests outside the
+// storage packages. The store is closed when t finishes.
+func NewSQLite(t *testing.T) *sqlite.Store {
+	t.Helper()
+	st, err := sqlite.NewInMemory()
+	if err != nil {
+		t.Fatalf("sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
@@ -0,0 +1,604 @@
+// Package storagetest is a conformance suite for storage backends. Every
+// DomainStore implementation runs the same behavioral assertions so that
+// handlers can rely on one set of semantics regardless of the backend.
+//
+// A backend's test file calls Run with a factory that returns a fresh,
+// empty store:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.DomainStore {
+//			return newStore(t)
+//		})
+//	}
+//
+// Tests elsewhere that just need a working store use NewSQLite rather than
+// opening and closing one themselves.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+)
+
+// Factory returns a fresh, empty store. It is called once per subtest and
+// should register any cleanup with t.
+type Factory func(t *testing.T) storage.DomainStore
+
+// Run executes the full conformance suite against stores from newStore.
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, st storage.DomainStore)
+	}{
+		{"SpecCRUD", testSpecCRUD},
+		{"TaskCRUD", testTaskCRUD},
+		{"OptimisticLocking", testOptimisticLocking},
+		{"DuplicateIDs", testDuplicateIDs},
+		{"UniqueTitles", testUniqueTitles},
+		{"InsightDedup", testInsightDedup},
+		{"ProjectIsolation", testProjectIsolation},
+		{"Reservations", testReservations},
+		{"InboxSemantics", testInboxSemantics},
+		{"ThreadMessages", testThreadMessages},
+		{"AgentsAndHeartbeat", testAgentsAndHeartbeat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+const project = "conformance"
+
+func testSpecCRUD(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	created, err := st.CreateSpec(ctx, core.Spec{
+		Project: project,
+		Title:   "Spec",
+		Vision:  "vision",
+		Status:  core.SpecStatusDraft,
+	})
+	if err != nil {
+		t.Fatalf("CreateSpec: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatal("expected ID to be assigned")
+	}
+	if created.Version != 1 {
+		t.Errorf("version = %d, want 1", created.Version)
+	}
+	if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+		t.Error("expected timestamps to be set")
+	}
+
+	fetched, err := st.GetSpec(ctx, project, created.ID)
+	if err != nil {
+		t.Fatalf("GetSpec: %v", err)
+	}
+	if fetched.Title != "Spec" || fetched.Vision != "vision" || fetched.Version != 1 {
+		t.Errorf("fetched = %+v", fetched)
+	}
+
+	if specs, err := st.ListSpecs(ctx, project, string(core.SpecStatusDraft)); err != nil || len(specs) != 1 {
+		t.Fatalf("ListSpecs(draft) = %d specs, err %v; want 1", len(specs), err)
+	}
+	if specs, err := st.ListSpecs(ctx, project, string(core.SpecStatusValidated)); err != nil || len(specs) != 0 {
+		t.Fatalf("ListSpecs(validated) = %d specs, err %v; want 0", len(specs), err)
+	}
+
+	fetched.Status = core.SpecStatusValidated
+	fetched.Vision = "updated"
+	updated, err := st.UpdateSpec(ctx, fetched)
+	if err != nil {
+		t.Fatalf("UpdateSpec: %v", err)
+	}
+	if updated.Version != 2 || updated.Vision != "updated" {
+		t.Errorf("updated = %+v, want version 2 and new vision", updated)
+	}
+
+	if err := st.DeleteSpec(ctx, project, created.ID); err != nil {
+		t.Fatalf("DeleteSpec: %v", err)
+	}
+	if _, err := st.GetSpec(ctx, project, created.ID); err == nil {
+		t.Fatal("expected GetSpec to fail after delete")
+	}
+	if specs, err := st.ListSpecs(ctx, project, ""); err != nil || len(specs) != 0 {
+		t.Fatalf("ListSpecs after delete = %d specs, err %v; want 0", len(specs), err)
+	}
+}
+
+func testTaskCRUD(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	created, err := st.CreateTask(ctx, core.Task{Project: project, Title: "Task", Status: core.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	created.Agent = "agent-a"
+	created.Status = core.TaskStatusRunning
+	updated, err := st.UpdateTask(ctx, created)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if updated.Agent != "agent-a" || updated.Status != core.TaskStatusRunning {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	if tasks, err := st.ListTasks(ctx, project, string(core.TaskStatusRunning), ""); err != nil || len(tasks) != 1 {
+		t.Fatalf("ListTasks(running) = %d tasks, err %v; want 1", len(tasks), err)
+	}
+	if tasks, err := st.ListTasks(ctx, project, "", "agent-a"); err != nil || len(tasks) != 1 {
+		t.Fatalf("ListTasks(agent-a) = %d tasks, err %v; want 1", len(tasks), err)
+	}
+	if tasks, err := st.ListTasks(ctx, project, "", "agent-b"); err != nil || len(tasks) != 0 {
+		t.Fatalf("ListTasks(agent-b) = %d tasks, err %v; want 0", len(tasks), err)
+	}
+
+	if err := st.DeleteTask(ctx, project, created.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := st.GetTask(ctx, project, created.ID); err == nil {
+		t.Fatal("expected GetTask to fail after delete")
+	}
+}
+
+// testOptimisticLocking checks that updates carrying a stale version are
+// rejected with core.ErrConcurrentModification and leave the entity as the
+// winning writer left it.
+func testOptimisticLocking(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+
+	spec, err := st.CreateSpec(ctx, core.Spec{Project: project, Title: "v1", Status: core.SpecStatusDraft})
+	if err != nil {
+		t.Fatalf("CreateSpec: %v", err)
+	}
+	winner, loser := spec, spec
+	winner.Title = "winner"
+	if _, err := st.UpdateSpec(ctx, winner); err != nil {
+		t.Fatalf("first UpdateSpec: %v", err)
+	}
+	loser.Title = "loser"
+	if _, err := st.UpdateSpec(ctx, loser); !errors.Is(err, core.ErrConcurrentModification) {
+		t.Fatalf("stale UpdateSpec err = %v, want ErrConcurrentModification", err)
+	}
+	got, err := st.GetSpec(ctx, project, spec.ID)
+	if err != nil {
+		t.Fatalf("GetSpec: %v", err)
+	}
+	if got.Title != "winner" || got.Version != 2 {
+		t.Errorf("spec = %q v%d, want winner v2", got.Title, got.Version)
+	}
+
+	task, err := st.CreateTask(ctx, core.Task{Project: project, Title: "task", Status: core.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	first := task
+	first.Status = core.TaskStatusRunning
+	if _, err := st.UpdateTask(ctx, first); err != nil {
+		t.Fatalf("first UpdateTask: %v", err)
+	}
+	if _, err := st.UpdateTask(ctx, task); !errors.Is(err, core.ErrConcurrentModification) {
+		t.Fatalf("stale UpdateTask err = %v, want ErrConcurrentModification", err)
+	}
+}
+
+// testDuplicateIDs checks that creating an entity with an ID already used
+// in its project fails with core.ErrAlreadyExists and changes nothing, while
+// the same ID is free in another project.
+func testDuplicateIDs(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	const id = "11111111-2222-3333-4444-555555555555"
+
+	spec, err := st.CreateSpec(ctx, core.Spec{ID: id, Project: project, Title: "first", Status: core.SpecStatusDraft})
+	if err != nil {
+		t.Fatalf("CreateSpec: %v", err)
+	}
+	if _, err := st.CreateSpec(ctx, core.Spec{ID: id, Project: project, Title: "second", Status: core.SpecStatusDraft}); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Fatalf("duplicate CreateSpec err = %v, want ErrAlreadyExists", err)
+	}
+	if got, err := st.GetSpec(ctx, project, id); err != nil || got.Title != "first" || got.ShortID != spec.ShortID {
+		t.Errorf("spec after duplicate = %+v, %v; want the first unchanged", got, err)
+	}
+	if _, err := st.CreateSpec(ctx, core.Spec{ID: id, Project: "conformance-other", Title: "elsewhere", Status: core.SpecStatusDraft}); err != nil {
+		t.Errorf("CreateSpec in another project: %v", err)
+	}
+
+	if _, err := st.CreateTask(ctx, core.Task{ID: id, Project: project, Title: "task", Status: core.TaskStatusPending}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := st.CreateTask(ctx, core.Task{ID: id, Project: project, Title: "again", Status: core.TaskStatusPending}); !errors.Is(err, core.ErrAlreadyExists) {
+		t.Fatalf("duplicate CreateTask err = %v, want ErrAlreadyExists", err)
+	}
+
+	for _, create := range []struct {
+		name string
+		fn   func(title string) error
+	}{
+		{"CreateInsight", func(title string) error {
+			_, err := st.CreateInsight(ctx, core.Insight{ID: id, Project: project, Source: "conformance", Title: title})
+			return err
+		}},
+		{"CreateCUJ", func(title string) error {
+			_, err := st.CreateCUJ(ctx, core.CriticalUserJourney{ID: id, Project: project, Title: title})
+			return err
+		}},
+		{"CreateGoal", func(title string) error {
+			_, err := st.CreateGoal(ctx, core.Goal{ID: id, Project: project, Title: title})
+			return err
+		}},
+	} {
+		if err := create.fn("first"); err != nil {
+			t.Fatalf("%s: %v", create.name, err)
+		}
+		if err := create.fn("second"); !errors.Is(err, core.ErrAlreadyExists) {
+			t.Fatalf("duplicate %s err = %v, want ErrAlreadyExists", create.name, err)
+		}
+	}
+}
+
+// testUniqueTitles checks that, while a project enforces unique titles,
+// the store itself rejects a duplicate with core.ErrDuplicateTitle, with no
+// FindByTitle check in front of it.
+func testUniqueTitles(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	const other = "conformance-other"
+	setFlag := func(project, name string, enabled bool) {
+		t.Helper()
+		if _, err := st.SetFeatureFlag(ctx, core.FeatureFlag{Project: project, Name: name, Enabled: enabled}); err != nil {
+			t.Fatalf("SetFeatureFlag %s/%s: %v", project, name, err)
+		}
+	}
+	createTask := func(project, storyID, title string) (core.Task, error) {
+		return st.CreateTask(ctx, core.Task{Project: project, StoryID: storyID, Title: title, Status: core.TaskStatusPending})
+	}
+
+	// Written before the flag, so held to nothing.
+	legacy, err := createTask(project, "", "Ship it")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := createTask(project, "", "Ship it"); err != nil {
+		t.Fatalf("duplicate CreateTask without the flag: %v", err)
+	}
+
+	setFlag(project, core.FlagUniqueTitles+".task", true)
+	first, err := createTask(project, "story-1", "Write docs")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := createTask(project, "story-1", "  write DOCS "); !errors.Is(err, core.ErrDuplicateTitle) {
+		t.Fatalf("duplicate CreateTask err = %v, want ErrDuplicateTitle", err)
+	}
+	if _, err := createTask(project, "story-2", "Write docs"); err != nil {
+		t.Errorf("CreateTask under another story: %v", err)
+	}
+	second, err := createTask(project, "story-1", "Review docs")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	second.Title = "Write docs"
+	if _, err := st.UpdateTask(ctx, second); !errors.Is(err, core.ErrDuplicateTitle) {
+		t.Fatalf("renaming UpdateTask err = %v, want ErrDuplicateTitle", err)
+	}
+	first.Status = core.TaskStatusRunning
+	if _, err := st.UpdateTask(ctx, first); err != nil {
+		t.Errorf("UpdateTask keeping its title: %v", err)
+	}
+	legacy.Status = core.TaskStatusRunning
+	if _, err := st.UpdateTask(ctx, legacy); err != nil {
+		t.Errorf("UpdateTask of a duplicate from before the flag: %v", err)
+	}
+	if _, err := st.CreateSpec(ctx, core.Spec{Project: project, Title: "Write docs", Status: core.SpecStatusDraft}); err != nil {
+		t.Errorf("CreateSpec with only tasks held to unique titles: %v", err)
+	}
+
+	// A server-wide default applies to projects without their own setting.
+	setFlag("", core.FlagUniqueTitles, true)
+	setFlag(other, core.FlagUniqueTitles, false)
+	for _, p := range []string{project, other} {
+		if _, err := st.CreateSpec(ctx, core.Spec{Project: p, Title: "Roadmap", Status: core.SpecStatusDraft}); err != nil {
+			t.Fatalf("CreateSpec in %s: %v", p, err)
+		}
+	}
+	if _, err := st.CreateSpec(ctx, core.Spec{Project: project, Title: "roadmap", Status: core.SpecStatusDraft}); !errors.Is(err, core.ErrDuplicateTitle) {
+		t.Errorf("duplicate CreateSpec under the default err = %v, want ErrDuplicateTitle", err)
+	}
+	if _, err := st.CreateSpec(ctx, core.Spec{Project: other, Title: "roadmap", Status: core.SpecStatusDraft}); err != nil {
+		t.Errorf("duplicate CreateSpec in a project that opted out: %v", err)
+	}
+}
+
+// testInsightDedup checks that, while a project deduplicates insights, the
+// store itself rejects a second insight with the same content with
+// core.ErrDuplicateInsight.
+func testInsightDedup(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	if _, err := st.SetFeatureFlag(ctx, core.FeatureFlag{Project: project, Name: core.FlagDedupInsights, Enabled: true}); err != nil {
+		t.Fatalf("SetFeatureFlag: %v", err)
+	}
+	first, err := st.CreateInsight(ctx, core.Insight{Project: project, Source: "review", Category: "ux", Title: "Slow search"})
+	if err != nil {
+		t.Fatalf("CreateInsight: %v", err)
+	}
+	if _, err := st.CreateInsight(ctx, core.Insight{Project: project, Source: "review", Category: "ux", Title: "Slow search"}); !errors.Is(err, core.ErrDuplicateInsight) {
+		t.Fatalf("duplicate CreateInsight err = %v, want ErrDuplicateInsight", err)
+	}
+
+	// Once the first is edited to say something else, the content is free.
+	first.Title = "Slow search on mobile"
+	if _, err := st.UpdateInsight(ctx, first); err != nil {
+		t.Fatalf("UpdateInsight: %v", err)
+	}
+	if _, err := st.CreateInsight(ctx, core.Insight{Project: project, Source: "review", Category: "ux", Title: "Slow search"}); err != nil {
+		t.Errorf("CreateInsight after the original was edited: %v", err)
+	}
+}
+
+// testProjectIsolation checks that nothing written under one project is
+// visible from another.
+func testProjectIsolation(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	const other = "conformance-other"
+
+	spec, err := st.CreateSpec(ctx, core.Spec{Project: project, Title: "mine", Status: core.SpecStatusDraft})
+	if err != nil {
+		t.Fatalf("CreateSpec: %v", err)
+	}
+	if _, err := st.GetSpec(ctx, other, spec.ID); err == nil {
+		t.Error("spec readable from another project")
+	}
+	if specs, err := st.ListSpecs(ctx, other, ""); err != nil || len(specs) != 0 {
+		t.Errorf("ListSpecs(other) = %d specs, err %v; want 0", len(specs), err)
+	}
+	if err := st.DeleteSpec(ctx, other, spec.ID); err == nil {
+		if _, err := st.GetSpec(ctx, project, spec.ID); err != nil {
+			t.Error("DeleteSpec from another project removed the spec")
+		}
+	}
+
+	if _, err := st.CreateTask(ctx, core.Task{Project: project, Title: "t", Status: core.TaskStatusPending}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if tasks, err := st.ListTasks(ctx, other, "", ""); err != nil || len(tasks) != 0 {
+		t.Errorf("ListTasks(other) = %d tasks, err %v; want 0", len(tasks), err)
+	}
+
+	if _, err := st.RegisterAgent(ctx, core.Agent{Name: "a", Project: project}); err != nil {
+		t.Fatalf("RegisterAgent: %v", err)
+	}
+	if agents, err := st.ListAgents(ctx, other, nil); err != nil || len(agents) != 0 {
+		t.Errorf("ListAgents(other) = %d agents, err %v; want 0", len(agents), err)
+	}
+
+	sendMessage(t, st, project, "sender", "shared-name", "", "hello")
+	if msgs, err := st.InboxSince(ctx, other, "shared-name", 0, 0); err != nil || len(msgs) != 0 {
+		t.Errorf("InboxSince(other) = %d messages, err %v; want 0", len(msgs), err)
+	}
+}
+
+func testReservations(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+
+	held, err := st.Reserve(ctx, core.Reservation{
+		AgentID:     "agent-a",
+		Project:     project,
+		PathPattern: "pkg/events/*.go",
+		Exclusive:   true,
+		Reason:      "refactor",
+	})
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if held.ID == "" || !held.IsActive() {
+		t.Fatalf("reservation = %+v, want active with ID", held)
+	}
+
+	_, err = st.Reserve(ctx, core.Reservation{
+		AgentID:     "agent-b",
+		Project:     project,
+		PathPattern: "pkg/events/reconcile.go",
+		Exclusive:   true,
+	})
+	var conflict *core.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("overlapping Reserve err = %v, want *core.ConflictError", err)
+	}
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].ReservationID != held.ID {
+		t.Errorf("conflicts = %+v, want the held reservation", conflict.Conflicts)
+	}
+
+	// The same pattern in another project doesn't conflict.
+	if _, err := st.Reserve(ctx, core.Reservation{
+		AgentID:     "agent-b",
+		Project:     project + "-other",
+		PathPattern: "pkg/events/*.go",
+		Exclusive:   true,
+	}); err != nil {
+		t.Fatalf("Reserve in other project: %v", err)
+	}
+
+	// Shared reservations coexist.
+	for _, agent := range []string{"agent-c", "agent-d"} {
+		if _, err := st.Reserve(ctx, core.Reservation{AgentID: agent, Project: project, PathPattern: "docs/*.md"}); err != nil {
+			t.Fatalf("shared Reserve by %s: %v", agent, err)
+		}
+	}
+
+	active, err := st.ActiveReservations(ctx, project)
+	if err != nil {
+		t.Fatalf("ActiveReservations: %v", err)
+	}
+	if len(active) != 3 {
+		t.Errorf("active reservations = %d, want 3", len(active))
+	}
+
+	if err := st.ReleaseReservation(ctx, held.ID, "agent-b"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("release by non-owner err = %v, want ErrNotFound", err)
+	}
+	if err := st.ReleaseReservation(ctx, held.ID, "agent-a"); err != nil {
+		t.Fatalf("ReleaseReservation: %v", err)
+	}
+	if err := st.ReleaseReservation(ctx, held.ID, "agent-a"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("double release err = %v, want ErrNotFound", err)
+	}
+	got, err := st.GetReservation(ctx, held.ID)
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if got.IsActive() {
+		t.Error("released reservation still active")
+	}
+
+	if _, err := st.Reserve(ctx, core.Reservation{
+		AgentID:     "agent-b",
+		Project:     project,
+		PathPattern: "pkg/events/reconcile.go",
+		Exclusive:   true,
+	}); err != nil {
+		t.Fatalf("Reserve after release: %v", err)
+	}
+}
+
+// testInboxSemantics checks recipient routing, cursor ordering and limits.
+func testInboxSemantics(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+
+	var cursors []uint64
+	for i := range 5 {
+		cursors = append(cursors, sendMessage(t, st, project, "sender", "bob", "", fmt.Sprintf("m%d", i)))
+	}
+	for i := 1; i < len(cursors); i++ {
+		if cursors[i] <= cursors[i-1] {
+			t.Fatalf("cursors not increasing: %v", cursors)
+		}
+	}
+	sendMessage(t, st, project, "sender", "carol", "", "not for bob")
+
+	msgs, err := st.InboxSince(ctx, project, "bob", 0, 0)
+	if err != nil {
+		t.Fatalf("InboxSince: %v", err)
+	}
+	if len(msgs) != 5 {
+		t.Fatalf("bob inbox = %d messages, want 5", len(msgs))
+	}
+	for i, m := range msgs {
+		if m.Body != fmt.Sprintf("m%d", i) {
+			t.Errorf("msgs[%d].Body = %q, want m%d (oldest first)", i, m.Body, i)
+		}
+		if m.Cursor != cursors[i] {
+			t.Errorf("msgs[%d].Cursor = %d, want %d", i, m.Cursor, cursors[i])
+		}
+	}
+
+	since, err := st.InboxSince(ctx, project, "bob", cursors[2], 0)
+	if err != nil {
+		t.Fatalf("InboxSince cursor: %v", err)
+	}
+	if len(since) != 2 || since[0].Body != "m3" {
+		t.Errorf("InboxSince(%d) = %d messages, want m3 and m4", cursors[2], len(since))
+	}
+
+	limited, err := st.InboxSince(ctx, project, "bob", 0, 2)
+	if err != nil {
+		t.Fatalf("InboxSince limit: %v", err)
+	}
+	if len(limited) != 2 {
+		t.Errorf("InboxSince limit 2 = %d messages", len(limited))
+	}
+
+	total, unread, err := st.InboxCounts(ctx, project, "bob")
+	if err != nil {
+		t.Fatalf("InboxCounts: %v", err)
+	}
+	if total != 5 || unread != 5 {
+		t.Errorf("counts = %d/%d, want 5/5", total, unread)
+	}
+	if err := st.MarkRead(ctx, project, msgs[0].ID, "bob"); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if _, unread, err = st.InboxCounts(ctx, project, "bob"); err != nil || unread != 4 {
+		t.Errorf("unread after MarkRead = %d, err %v; want 4", unread, err)
+	}
+}
+
+func testThreadMessages(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	sendMessage(t, st, project, "alice", "bob", "thread-1", "question")
+	sendMessage(t, st, project, "bob", "alice", "thread-1", "answer")
+	sendMessage(t, st, project, "alice", "bob", "thread-2", "elsewhere")
+
+	msgs, err := st.ThreadMessages(ctx, project, "thread-1", 0)
+	if err != nil {
+		t.Fatalf("ThreadMessages: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Body != "question" || msgs[1].Body != "answer" {
+		t.Fatalf("thread-1 = %+v, want question then answer", msgs)
+	}
+	if ok, err := st.IsThreadParticipant(ctx, project, "thread-1", "bob"); err != nil || !ok {
+		t.Errorf("bob participant = %v, err %v; want true", ok, err)
+	}
+	if ok, err := st.IsThreadParticipant(ctx, project, "thread-1", "carol"); err != nil || ok {
+		t.Errorf("carol participant = %v, err %v; want false", ok, err)
+	}
+}
+
+func testAgentsAndHeartbeat(t *testing.T, st storage.DomainStore) {
+	ctx := context.Background()
+	agent, err := st.RegisterAgent(ctx, core.Agent{
+		Name:         "worker",
+		Project:      project,
+		Capabilities: []string{"go"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterAgent: %v", err)
+	}
+	if agent.ID == "" {
+		t.Fatal("expected agent ID")
+	}
+
+	beat, err := st.Heartbeat(ctx, project, agent.ID)
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if beat.LastSeen.Before(agent.LastSeen) {
+		t.Errorf("heartbeat moved last_seen backwards: %v < %v", beat.LastSeen, agent.LastSeen)
+	}
+	if _, err := st.Heartbeat(ctx, project, "missing"); err == nil {
+		t.Error("expected Heartbeat for unknown agent to fail")
+	}
+
+	if agents, err := st.ListAgents(ctx, project, []string{"go"}); err != nil || len(agents) != 1 {
+		t.Errorf("ListAgents(go) = %d agents, err %v; want 1", len(agents), err)
+	}
+	if agents, err := st.ListAgents(ctx, project, []string{"rust"}); err != nil || len(agents) != 0 {
+		t.Errorf("ListAgents(rust) = %d agents, err %v; want 0", len(agents), err)
+	}
+}
+
+// sendMessage appends a message.created event and returns its cursor.
+func sendMessage(t *testing.T, st storage.Store, project, from, to, threadID, body string) uint64 {
+	t.Helper()
+	cursor, err := st.AppendEvent(context.Background(), core.Event{
+		Type:    core.EventMessageCreated,
+		Project: project,
+		Message: core.Message{
+			ID:       fmt.Sprintf("%s-%s-%s", from, to, body),
+			ThreadID: threadID,
+			Project:  project,
+			From:     from,
+			To:       []string{to},
+			Body:     body,
+		},
+	})
+	if err != nil {
+		t.Fatalf("AppendEvent: %v", err)
+	}
+	return cursor
+}
@@ -0,0 +1,49 @@
+package storagetest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermute/internal/core"
+	"github.com/mistakeknot/intermute/internal/storage"
+	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+)
+
+func TestConformanceInMemory(t *testing.T) {
+	Run(t, func(t *testing.T) storage.DomainStore {
+		return NewSQLite(t)
+	})
+}
+
+func TestConformanceFile(t *testing.T) {
+	Run(t, func(t *testing.T) storage.DomainStore {
+		st, err := sqlite.New(filepath.Join(t.TempDir(), "conformance.db"))
+		if err != nil {
+			t.Fatalf("new sqlite: %v", err)
+		}
+		t.Cleanup(func() { _ = st.Close() })
+		return st
+	})
+}
+
+func TestConformanceResilient(t *testing.T) {
+	Run(t, func(t *testing.T) storage.DomainStore {
+		return sqlite.NewResilient(NewSQLite(t))
+	})
+}
+
+func TestNewSQLiteIsIsolated(t *testing.T) {
+	a, b := NewSQLite(t), NewSQLite(t)
+	ctx := context.Background()
+	if _, err := a.CreateProject(ctx, core.Project{Name: "only-in-a"}); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	projects, err := b.ListProjects(ctx, true)
+	if err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("second store sees %d projects from the first", len(projects))
+	}
+}
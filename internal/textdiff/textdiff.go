@@ -0,0 +1,137 @@
+// Package textdiff renders line-based unified diffs of short documents.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is how many unchanged lines surround each hunk.
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	a, b int // 0-based line index in a and b before this op
+}
+
+// Unified returns a unified diff of a and b labelled fromLabel and toLabel,
+// or "" when they are equal. It uses a quadratic LCS, which is fine for
+// spec-sized text but not for large files.
+func Unified(a, b, fromLabel, toLabel string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for start := 0; start < len(ops); {
+		// Find the next change and the extent of its hunk, merging changes
+		// whose context would overlap.
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != opEqual {
+				last = i
+				continue
+			}
+			if i-last > 2*contextLines {
+				break
+			}
+		}
+		lo := max(first-contextLines, start)
+		hi := min(last+contextLines+1, len(ops))
+		writeHunk(&out, ops[lo:hi])
+		start = hi
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []op) {
+	var aLen, bLen int
+	for _, o := range ops {
+		if o.kind != opInsert {
+			aLen++
+		}
+		if o.kind != opDelete {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(ops[0].a, aLen), hunkRange(ops[0].b, bLen))
+	for _, o := range ops {
+		out.WriteByte(byte(o.kind))
+		out.WriteString(o.line)
+		out.WriteByte('\n')
+	}
+}
+
+// hunkRange formats a start,count pair; an empty range names the line
+// before it, as diff(1) does.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes an edit script from a to b via longest common
+// subsequence, preferring deletions before insertions.
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i], i, j})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{opDelete, a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,35 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	if got := Unified("same\n", "same\n", "a", "b"); got != "" {
+		t.Fatalf("expected no diff for equal input, got %q", got)
+	}
+
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\nnine\nten\neleven\n"
+	want := `--- v1
++++ v2
+@@ -2,9 +2,10 @@
+ two
+ three
+ four
+-five
++FIVE
+ six
+ seven
+ eight
+ nine
+ ten
++eleven
+`
+	if got := Unified(a, b, "v1", "v2"); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	want = "--- v1\n+++ v2\n@@ -0,0 +1 @@\n+new\n"
+	if got := Unified("", "new", "v1", "v2"); got != want {
+		t.Fatalf("unexpected diff from empty:\n%q\nwant:\n%q", got, want)
+	}
+}
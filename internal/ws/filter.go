@@ -0,0 +1,143 @@
+package ws
+
+import (
+	"path"
+	"sync/atomic"
+
+	"github.com/mistakeknot/intermute/internal/core"
+)
+
+// Event filtering
+//
+// A connection gets every event for its (project, agent) until it sends a
+// subscribe frame:
+//
+//	{"type": "subscribe", "events": ["task.*", "spec.updated"], "entity_ids": ["..."]}
+//
+// events are globs over the event type ("*" does not cross a "."); with
+// entity_ids set, only events whose entity_id is listed are delivered.
+// Either list may be empty to leave that dimension open. The hub answers
+// with {"type": "subscribed", ...} once the filter is in effect, or an
+// {"type": "error"} frame for an invalid pattern. {"type": "unsubscribe"}
+// goes back to receiving everything.
+//
+// A subscribe frame may also pin "schema_version" (see core.EventAtVersion),
+// as the ?schema_version= connect parameter does; events are down-converted
+// to it. Unsubscribing keeps the pinned version.
+
+// clientFrame is a frame sent by a client.
+type clientFrame struct {
+	Type          string   `json:"type"`
+	Events        []string `json:"events,omitempty"`
+	EntityIDs     []string `json:"entity_ids,omitempty"`
+	SchemaVersion int      `json:"schema_version,omitempty"`
+}
+
+// eventFilter decides which events a connection receives. A nil filter
+// matches everything.
+type eventFilter struct {
+	events    []string
+	entityIDs map[string]bool
+}
+
+// newEventFilter validates the frame's patterns and builds its filter.
+// Returns nil when the frame doesn't narrow anything.
+func newEventFilter(f clientFrame) (*eventFilter, error) {
+	for _, p := range f.Events {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, err
+		}
+	}
+	if len(f.Events) == 0 && len(f.EntityIDs) == 0 {
+		return nil, nil
+	}
+	filter := &eventFilter{events: f.Events}
+	if len(f.EntityIDs) > 0 {
+		filter.entityIDs = make(map[string]bool, len(f.EntityIDs))
+		for _, id := range f.EntityIDs {
+			filter.entityIDs[id] = true
+		}
+	}
+	return filter, nil
+}
+
+func (f *eventFilter) matches(event any) bool {
+	if f == nil {
+		return true
+	}
+	m, ok := event.(map[string]any)
+	if !ok {
+		return true
+	}
+	if len(f.events) > 0 {
+		typ, _ := m["type"].(string)
+		matched := false
+		for _, p := range f.events {
+			// path.Match treats "/" as the separator; event types use ".".
+			if ok, _ := path.Match(dotsToSlashes(p), dotsToSlashes(typ)); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.entityIDs != nil {
+		id, _ := m["entity_id"].(string)
+		if !f.entityIDs[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func dotsToSlashes(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c == '.' {
+			b[i] = '/'
+		}
+	}
+	return string(b)
+}
+
+// subscription is one connection's current filter and pinned schema
+// version, swapped by its read loop while Broadcast reads them.
+type subscription struct {
+	filter  atomic.Pointer[eventFilter]
+	version atomic.Int64 // 0 is core.EventSchemaVersion
+}
+
+// schemaVersion is the event schema version the connection is pinned to.
+func (s *subscription) schemaVersion() int {
+	if v := s.version.Load(); v != 0 {
+		return int(v)
+	}
+	return core.EventSchemaVersion
+}
+
+func (s *subscription) matches(event any) bool {
+	if s == nil {
+		return true
+	}
+	return s.filter.Load().matches(event)
+}
+
+// Filter matches events the way a WebSocket subscribe frame does, for
+// subscribers outside the hub such as gRPC streams. The zero Filter
+// matches everything.
+type Filter struct {
+	f *eventFilter
+}
+
+// NewFilter validates events' patterns and builds their filter.
+func NewFilter(events, entityIDs []string) (Filter, error) {
+	f, err := newEventFilter(clientFrame{Events: events, EntityIDs: entityIDs})
+	return Filter{f}, err
+}
+
+// Matches reports whether event passes the filter.
+func (f Filter) Matches(event any) bool {
+	return f.f.matches(event)
+}
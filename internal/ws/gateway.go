@@ -2,27 +2,40 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
 )
 
 const writeTimeout = 5 * time.Second
 
+// dedupeWindow is how many recent (target, event_id) pairs the hub
+// remembers to drop repeated broadcasts of the same event.
+const dedupeWindow = 4096
+
 type Hub struct {
 	mu       sync.RWMutex
-	conns    map[string]map[string]map[*websocket.Conn]struct{}
+	conns    map[string]map[string]map[*websocket.Conn]*subscription
 	numConns int // total connection count for pre-allocation
 	snapPool sync.Pool
+	recent   recentIDs
+	system   map[*websocket.Conn]*subscription // system topic connections
 }
 
 func NewHub() *Hub {
-	h := &Hub{conns: make(map[string]map[string]map[*websocket.Conn]struct{})}
+	h := &Hub{
+		conns:  make(map[string]map[string]map[*websocket.Conn]*subscription),
+		system: make(map[*websocket.Conn]*subscription),
+		recent: recentIDs{seen: make(map[string]struct{}, dedupeWindow), ring: make([]string, dedupeWindow)},
+	}
 	h.snapPool.New = func() any {
 		return &snapBuf{entries: make([]connEntry, 0, 16)}
 	}
@@ -35,14 +48,25 @@ type snapBuf struct {
 	entries []connEntry
 }
 
+// Handler serves agent connections on /ws/agents/{agent} and the system
+// topic on /ws/system.
 func (h *Hub) Handler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == systemPath {
+			h.serveSystem(w, r)
+			return
+		}
 		path := strings.TrimPrefix(r.URL.Path, "/ws/agents/")
 		agent := strings.Trim(path, "/")
 		if agent == "" {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		version, err := core.ParseEventSchemaVersion(r.URL.Query().Get("schema_version"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 		requestedProject := strings.TrimSpace(r.URL.Query().Get("project"))
 		info, _ := auth.FromContext(r.Context())
 		project := info.Project
@@ -63,34 +87,93 @@ func (h *Hub) Handler() http.HandlerFunc {
 			return
 		}
 
-		h.add(project, agent, conn)
+		sub := h.add(project, agent, conn, version)
 		defer h.remove(project, agent, conn)
+		h.readFrames(r.Context(), conn, sub)
+	}
+}
 
-		ctx := r.Context()
-		for {
-			var v any
-			if err := wsjson.Read(ctx, conn, &v); err != nil {
-				return
+// readFrames applies the client's frames to sub until the connection ends.
+func (h *Hub) readFrames(ctx context.Context, conn *websocket.Conn, sub *subscription) {
+	for {
+		var raw json.RawMessage
+		if err := wsjson.Read(ctx, conn, &raw); err != nil {
+			return
+		}
+		var frame clientFrame
+		if json.Unmarshal(raw, &frame) == nil {
+			h.handleFrame(ctx, conn, sub, frame)
+		}
+	}
+}
+
+// handleFrame applies a client's subscribe/unsubscribe frame. Other frame
+// types are ignored. A subscribe frame with an unsupported schema_version
+// changes nothing.
+func (h *Hub) handleFrame(ctx context.Context, conn *websocket.Conn, sub *subscription, frame clientFrame) {
+	var reply map[string]any
+	switch frame.Type {
+	case "subscribe":
+		filter, err := newEventFilter(frame)
+		if err != nil {
+			reply = map[string]any{"type": "error", "error": "invalid event pattern: " + err.Error()}
+			break
+		}
+		if v := frame.SchemaVersion; v != 0 {
+			if v < core.MinEventSchemaVersion || v > core.EventSchemaVersion {
+				reply = map[string]any{"type": "error", "error": fmt.Sprintf("%v: %d", core.ErrUnsupportedSchemaVersion, v)}
+				break
 			}
+			sub.version.Store(int64(v))
 		}
+		sub.filter.Store(filter)
+		reply = map[string]any{"type": "subscribed", "events": frame.Events, "entity_ids": frame.EntityIDs, "schema_version": sub.schemaVersion()}
+	case "unsubscribe":
+		sub.filter.Store(nil)
+		reply = map[string]any{"type": "subscribed"}
+	default:
+		return
 	}
+	wctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+	_ = wsjson.Write(wctx, conn, reply)
 }
 
 type connEntry struct {
 	conn    *websocket.Conn
+	sub     *subscription
 	project string
 	agent   string
 }
 
+// Broadcast writes event to every connection for (project, agent) whose
+// subscription matches it, at the connection's schema version. Events
+// carrying an "event_id" already broadcast to the same target are dropped,
+// so a retried broadcast is delivered once.
 func (h *Hub) Broadcast(project, agent string, event any) {
+	if m, ok := event.(map[string]any); ok {
+		if id, _ := m["event_id"].(string); id != "" && !h.recent.add(project+"\x00"+agent+"\x00"+id) {
+			return
+		}
+	}
 	buf := h.snapshot(project, agent)
 	if len(buf.entries) == 0 {
 		h.putSnapshot(buf)
 		return
 	}
+	rendered := make(map[int]any, 1)
 	for _, e := range buf.entries {
+		if !e.sub.matches(event) {
+			continue
+		}
+		v := e.sub.schemaVersion()
+		payload, ok := rendered[v]
+		if !ok {
+			payload = core.EventAtVersion(event, v)
+			rendered[v] = payload
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
-		err := wsjson.Write(ctx, e.conn, event)
+		err := wsjson.Write(ctx, e.conn, payload)
 		cancel()
 		if err != nil {
 			go func(e connEntry) {
@@ -114,17 +197,17 @@ func (h *Hub) snapshot(project, agent string) *snapBuf {
 		buf.entries = make([]connEntry, 0, h.numConns)
 	}
 
-	collectAgent := func(proj string, m map[string]map[*websocket.Conn]struct{}, target string) {
+	collectAgent := func(proj string, m map[string]map[*websocket.Conn]*subscription, target string) {
 		if target == "" {
 			for agentName, conns := range m {
-				for conn := range conns {
-					buf.entries = append(buf.entries, connEntry{conn: conn, project: proj, agent: agentName})
+				for conn, sub := range conns {
+					buf.entries = append(buf.entries, connEntry{conn: conn, sub: sub, project: proj, agent: agentName})
 				}
 			}
 			return
 		}
-		for conn := range m[target] {
-			buf.entries = append(buf.entries, connEntry{conn: conn, project: proj, agent: target})
+		for conn, sub := range m[target] {
+			buf.entries = append(buf.entries, connEntry{conn: conn, sub: sub, project: proj, agent: target})
 		}
 	}
 	if project != "" {
@@ -151,21 +234,24 @@ func (h *Hub) putSnapshot(buf *snapBuf) {
 	h.snapPool.Put(buf)
 }
 
-func (h *Hub) add(project, agent string, conn *websocket.Conn) {
+func (h *Hub) add(project, agent string, conn *websocket.Conn, version int) *subscription {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	perProject, ok := h.conns[project]
 	if !ok {
-		perProject = make(map[string]map[*websocket.Conn]struct{})
+		perProject = make(map[string]map[*websocket.Conn]*subscription)
 		h.conns[project] = perProject
 	}
 	perAgent, ok := perProject[agent]
 	if !ok {
-		perAgent = make(map[*websocket.Conn]struct{})
+		perAgent = make(map[*websocket.Conn]*subscription)
 		perProject[agent] = perAgent
 	}
-	perAgent[conn] = struct{}{}
+	sub := &subscription{}
+	sub.version.Store(int64(version))
+	perAgent[conn] = sub
 	h.numConns++
+	return sub
 }
 
 func (h *Hub) remove(project, agent string, conn *websocket.Conn) {
@@ -188,3 +274,28 @@ func (h *Hub) remove(project, agent string, conn *websocket.Conn) {
 		delete(h.conns, project)
 	}
 }
+
+// recentIDs is a fixed-size set of the most recently added keys; the
+// oldest key is evicted once the ring is full.
+type recentIDs struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+	ring []string
+	next int
+}
+
+// add records key and reports whether it was new.
+func (r *recentIDs) add(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.seen[key]; ok {
+		return false
+	}
+	if old := r.ring[r.next]; old != "" {
+		delete(r.seen, old)
+	}
+	r.ring[r.next] = key
+	r.next = (r.next + 1) % len(r.ring)
+	r.seen[key] = struct{}{}
+	return true
+}
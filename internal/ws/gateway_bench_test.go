@@ -13,7 +13,7 @@ func populateHub(h *Hub, n int) {
 	for i := 0; i < n; i++ {
 		// new(websocket.Conn) creates a distinct pointer — snapshot only
 		// reads map keys, never dereferences the conn.
-		h.add("proj", "agent", new(websocket.Conn))
+		h.add("proj", "agent", new(websocket.Conn), 0)
 	}
 }
 
@@ -24,7 +24,7 @@ func populateHubSpread(h *Hub, n int) {
 	for i := 0; i < n; i++ {
 		p := fmt.Sprintf("proj-%d", i%projects)
 		a := fmt.Sprintf("agent-%d", i%agents)
-		h.add(p, a, new(websocket.Conn))
+		h.add(p, a, new(websocket.Conn), 0)
 	}
 }
 
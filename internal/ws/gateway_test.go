@@ -13,17 +13,15 @@ import (
 	"time"
 
 	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
 	httpapi "github.com/mistakeknot/intermute/internal/http"
-	"github.com/mistakeknot/intermute/internal/storage/sqlite"
+	"github.com/mistakeknot/intermute/internal/storage/storagetest"
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
 )
 
 func TestWSAuthRejection(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	ring := auth.NewKeyring(true, map[string]string{"secret-a": "proj-a", "secret-b": "proj-b"})
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
@@ -99,10 +97,7 @@ func TestWSAuthRejection(t *testing.T) {
 }
 
 func TestWSReceivesMessageEvents(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -182,10 +177,7 @@ func sendMsg(t *testing.T, srvURL, project, from string, to []string, body strin
 }
 
 func TestWSMultiSubscriberFanout(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -212,10 +204,7 @@ func TestWSMultiSubscriberFanout(t *testing.T) {
 }
 
 func TestWSProjectIsolation(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -242,17 +231,14 @@ func TestWSProjectIsolation(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
 	var noop map[string]any
-	err = wsjson.Read(ctx, connB, &noop)
+	err := wsjson.Read(ctx, connB, &noop)
 	if err == nil {
 		t.Fatal("agent-b in proj-b should NOT have received a proj-a event")
 	}
 }
 
 func TestWSSubscriptionCleanup(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -270,10 +256,7 @@ func TestWSSubscriptionCleanup(t *testing.T) {
 }
 
 func TestWSAgentTargetedDelivery(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -298,17 +281,14 @@ func TestWSAgentTargetedDelivery(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
 	var noop map[string]any
-	err = wsjson.Read(ctx, connA, &noop)
+	err := wsjson.Read(ctx, connA, &noop)
 	if err == nil {
 		t.Fatal("agent-a should NOT have received a message targeted to agent-b")
 	}
 }
 
 func TestWSConcurrentBroadcast(t *testing.T) {
-	st, err := sqlite.NewInMemory()
-	if err != nil {
-		t.Fatalf("sqlite: %v", err)
-	}
+	st := storagetest.NewSQLite(t)
 	hub := NewHub()
 	svc := httpapi.NewService(st).WithBroadcaster(hub)
 	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
@@ -353,3 +333,204 @@ func TestWSConcurrentBroadcast(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestWSDuplicateEventIDDeliveredOnce(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	hub := NewHub()
+	svc := httpapi.NewService(st).WithBroadcaster(hub)
+	srv := httptest.NewServer(httpapi.NewRouter(svc, hub.Handler(), auth.Middleware(nil)))
+	defer srv.Close()
+
+	conn := dialWS(t, srv, "agent-a", "proj-x")
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	sendMsg(t, srv.URL, "proj-x", "sender", []string{"agent-a"}, "once")
+	ev := readWSEvent(t, conn, 2*time.Second)
+	id, _ := ev["event_id"].(string)
+	if id == "" {
+		t.Fatalf("expected event_id in payload, got %v", ev)
+	}
+
+	// A retried broadcast of the same event is dropped; a new one is not.
+	hub.Broadcast("proj-x", "agent-a", map[string]any{"type": "message.created", "event_id": id})
+	hub.Broadcast("proj-x", "agent-a", map[string]any{"type": "message.created", "event_id": "fresh"})
+	if next := readWSEvent(t, conn, 2*time.Second); next["event_id"] != "fresh" {
+		t.Fatalf("expected only the fresh event after the duplicate, got %v", next)
+	}
+}
+
+func TestWSSubscribeFilter(t *testing.T) {
+	hub := NewHub()
+	srv := httptest.NewServer(hub.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/agents/tui?project=proj", nil)
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	readType := func() map[string]any {
+		t.Helper()
+		var ev map[string]any
+		if err := wsjson.Read(ctx, conn, &ev); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return ev
+	}
+
+	if err := wsjson.Write(ctx, conn, map[string]any{"type": "subscribe", "events": []string{"task.["}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if ev := readType(); ev["type"] != "error" {
+		t.Fatalf("bad pattern reply = %v", ev)
+	}
+
+	if err := wsjson.Write(ctx, conn, map[string]any{"type": "subscribe", "events": []string{"task.*", "spec.updated"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if ev := readType(); ev["type"] != "subscribed" {
+		t.Fatalf("subscribe reply = %v", ev)
+	}
+
+	for _, typ := range []string{"message.created", "spec.created", "task.assigned", "spec.updated", "task.completed"} {
+		hub.Broadcast("proj", "", map[string]any{"type": typ, "entity_id": "e-" + typ})
+	}
+	for _, want := range []string{"task.assigned", "spec.updated", "task.completed"} {
+		if ev := readType(); ev["type"] != want {
+			t.Fatalf("got %v, want %s", ev["type"], want)
+		}
+	}
+
+	// Narrow to one entity.
+	if err := wsjson.Write(ctx, conn, map[string]any{"type": "subscribe", "events": []string{"task.*"}, "entity_ids": []string{"t-2"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if ev := readType(); ev["type"] != "subscribed" {
+		t.Fatalf("subscribe reply = %v", ev)
+	}
+	hub.Broadcast("proj", "", map[string]any{"type": "task.updated", "entity_id": "t-1"})
+	hub.Broadcast("proj", "", map[string]any{"type": "task.updated", "entity_id": "t-2"})
+	if ev := readType(); ev["entity_id"] != "t-2" {
+		t.Fatalf("got %v, want t-2 only", ev)
+	}
+
+	if err := wsjson.Write(ctx, conn, map[string]any{"type": "unsubscribe"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if ev := readType(); ev["type"] != "subscribed" {
+		t.Fatalf("unsubscribe reply = %v", ev)
+	}
+	hub.Broadcast("proj", "", map[string]any{"type": "message.created"})
+	if ev := readType(); ev["type"] != "message.created" {
+		t.Fatalf("after unsubscribe got %v", ev)
+	}
+}
+
+func TestWSSchemaVersionPinning(t *testing.T) {
+	hub := NewHub()
+	srv := httptest.NewServer(hub.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	base := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/agents/tui?project=proj"
+	if _, resp, err := websocket.Dial(ctx, base+"&schema_version=9", nil); err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unsupported version dial: err %v", err)
+	}
+	current, _, err := websocket.Dial(ctx, base, nil)
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	defer current.Close(websocket.StatusNormalClosure, "")
+	pinned, _, err := websocket.Dial(ctx, base+"&schema_version=1", nil)
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	defer pinned.Close(websocket.StatusNormalClosure, "")
+
+	read := func(conn *websocket.Conn) map[string]any {
+		t.Helper()
+		var ev map[string]any
+		if err := wsjson.Read(ctx, conn, &ev); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return ev
+	}
+
+	event := map[string]any{"type": "task.updated", "entity_id": "t-1"}
+	hub.Broadcast("proj", "", event)
+	if ev := read(current); ev["schema_version"] != float64(core.EventSchemaVersion) || ev["entity_type"] != "task" {
+		t.Fatalf("current event = %v", ev)
+	}
+	if ev := read(pinned); len(ev) != 2 || ev["type"] != "task.updated" {
+		t.Fatalf("version 1 event = %v, want the legacy envelope", ev)
+	}
+	if len(event) != 2 {
+		t.Fatalf("broadcast event was modified: %v", event)
+	}
+
+	// A subscribe frame moves the pin; an unsupported one is refused.
+	if err := wsjson.Write(ctx, pinned, map[string]any{"type": "subscribe", "schema_version": 7}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if ev := read(pinned); ev["type"] != "error" {
+		t.Fatalf("unsupported version reply = %v", ev)
+	}
+	if err := wsjson.Write(ctx, pinned, map[string]any{"type": "subscribe", "schema_version": 2}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if ev := read(pinned); ev["type"] != "subscribed" || ev["schema_version"] != float64(2) {
+		t.Fatalf("subscribe reply = %v", ev)
+	}
+	hub.Broadcast("proj", "", map[string]any{"type": "spec.created", "entity_id": "s-1"})
+	if ev := read(pinned); ev["schema_version"] != float64(2) || ev["entity_type"] != "spec" {
+		t.Fatalf("re-pinned event = %v", ev)
+	}
+}
+
+func TestWSSystemTopic(t *testing.T) {
+	st := storagetest.NewSQLite(t)
+	hub := NewHub()
+	ring := auth.NewKeyring(true, map[string]string{"secret-a": "proj-a"})
+	router := httpapi.NewRouter(httpapi.NewService(st).WithBroadcaster(hub), hub.Handler(), auth.Middleware(ring))
+
+	req := httptest.NewRequest(http.MethodGet, "/ws/system", nil)
+	req.RemoteAddr = "203.0.113.10:9999"
+	req.Header.Set("Authorization", "Bearer secret-a")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("project key on /ws/system: got %d, want 403", rr.Code)
+	}
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/system", nil)
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	if err := wsjson.Write(ctx, conn, map[string]any{"type": "subscribe", "events": []string{"system.circuit_*"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var ev map[string]any
+	if err := wsjson.Read(ctx, conn, &ev); err != nil || ev["type"] != "subscribed" {
+		t.Fatalf("subscribe reply = %v, %v", ev, err)
+	}
+
+	hub.Broadcast("proj-a", "", map[string]any{"type": "task.created"})
+	hub.NotifySystem(core.SystemEvent{Type: core.EventSystemSweepFailed})
+	hub.NotifySystem(core.SystemEvent{Type: core.EventSystemCircuitOpen, Data: map[string]any{"breaker": "writes"}})
+	if err := wsjson.Read(ctx, conn, &ev); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	data, _ := ev["data"].(map[string]any)
+	if ev["type"] != string(core.EventSystemCircuitOpen) || ev["topic"] != "system" || ev["at"] == "" || data["breaker"] != "writes" {
+		t.Fatalf("system event = %v", ev)
+	}
+}
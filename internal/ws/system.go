@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mistakeknot/intermute/internal/auth"
+	"github.com/mistakeknot/intermute/internal/core"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+// System topic
+//
+// Operational events (a storage circuit breaker tripping, a failed sweep,
+// the database crossing a size threshold) belong to no project, so they
+// go to a topic of their own: /ws/system. Like the admin endpoints it is
+// closed to project API keys. Each event arrives as
+//
+//	{"type": "system.circuit_open", "topic": "system", "at": "...", "data": {...}}
+//
+// and subscribe frames narrow it the same way as on an agent connection.
+
+const systemPath = "/ws/system"
+
+func (h *Hub) serveSystem(w http.ResponseWriter, r *http.Request) {
+	if info, _ := auth.FromContext(r.Context()); info.Mode == auth.ModeAPIKey {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	sub := &subscription{}
+	h.mu.Lock()
+	h.system[conn] = sub
+	h.mu.Unlock()
+	defer h.removeSystem(conn)
+	h.readFrames(r.Context(), conn, sub)
+}
+
+func (h *Hub) removeSystem(conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.system, conn)
+}
+
+// NotifySystem sends ev to every system topic connection whose
+// subscription matches it. A zero At is stamped with the current time.
+func (h *Hub) NotifySystem(ev core.SystemEvent) {
+	if ev.At.IsZero() {
+		ev.At = time.Now().UTC()
+	}
+	frame := map[string]any{
+		"type":  string(ev.Type),
+		"topic": "system",
+		"at":    ev.At,
+		"data":  ev.Data,
+	}
+	h.mu.RLock()
+	entries := make([]connEntry, 0, len(h.system))
+	for conn, sub := range h.system {
+		entries = append(entries, connEntry{conn: conn, sub: sub})
+	}
+	h.mu.RUnlock()
+	for _, e := range entries {
+		if !e.sub.matches(frame) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		err := wsjson.Write(ctx, e.conn, frame)
+		cancel()
+		if err != nil {
+			go func(conn *websocket.Conn) {
+				conn.Close(websocket.StatusGoingAway, "write error")
+				h.removeSystem(conn)
+			}(e.conn)
+		}
+	}
+}